@@ -0,0 +1,18 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gateway/internal/config"
+)
+
+func main() {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(config.Schema()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write schema: %v\n", err)
+		os.Exit(1)
+	}
+}
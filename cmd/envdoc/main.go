@@ -39,6 +39,15 @@ func main() {
 	fmt.Println("export GATEWAY_GATEWAY_CORS_ALLOWEDORIGINS=https://example.com,https://app.example.com")
 	fmt.Println("export GATEWAY_GATEWAY_CORS_ALLOWCREDENTIALS=true")
 	fmt.Println()
+	fmt.Println("# Override the first route's service and add a route")
+	fmt.Println("export GATEWAY_GATEWAY_ROUTER_RULES_0_SERVICENAME=users-v2")
+	fmt.Println("export GATEWAY_GATEWAY_ROUTER_RULES_1_ID=orders")
+	fmt.Println("export GATEWAY_GATEWAY_ROUTER_RULES_1_PATH=/orders")
+	fmt.Println()
+	fmt.Println("# Set management users")
+	fmt.Println("export GATEWAY_GATEWAY_MANAGEMENT_AUTH_USERS='admin=secret;ops=other-secret'")
+	fmt.Println("export GATEWAY_GATEWAY_MANAGEMENT_AUTH_USERS_ci=ci-secret")
+	fmt.Println()
 	fmt.Println("# Run gateway with env vars")
 	fmt.Println("./gateway -config gateway.yaml")
 	fmt.Println("```")
@@ -2,31 +2,53 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"gateway/internal/app"
 	"gateway/internal/config"
+
+	"gopkg.in/yaml.v3"
 )
 
 var (
-	configFile = flag.String("config", "configs/gateway.yaml", "config file path")
-	logLevel   = flag.String("log-level", "info", "log level")
-	hotReload  = flag.Bool("hot-reload", false, "enable configuration hot reload")
+	configFile  = flag.String("config", "configs/gateway.yaml", "config file path")
+	profile     = flag.String("profile", os.Getenv(config.ProfileEnv), "config profile whose overlay is merged over the config file, e.g. prod for gateway.prod.yaml (default $"+config.ProfileEnv+")")
+	logLevel    = flag.String("log-level", "info", "log level")
+	hotReload   = flag.Bool("hot-reload", false, "enable configuration hot reload")
+	validate    = flag.Bool("validate", false, "validate the config file and exit")
+	printConfig = flag.Bool("print-config", false, "print the merged config, with secrets redacted, and exit")
 )
 
+// newLoader returns the loader of the config file at path and the profile
+func newLoader(path string) *config.Loader {
+	return config.NewLoader(path).WithProfile(*profile)
+}
+
 func main() {
 	flag.Parse()
 
 	// Setup logging
 	setupLogging(*logLevel)
 
+	if *validate {
+		os.Exit(validateConfig(*configFile))
+	}
+	if *printConfig {
+		os.Exit(printMergedConfig(*configFile))
+	}
+
 	// Load config or use default
-	cfg, err := config.NewLoader(*configFile).Load()
+	loader := newLoader(*configFile)
+	cfg, err := loader.Load()
 	if err != nil {
 		// If default config file doesn't exist, use built-in defaults
 		if *configFile == "configs/gateway.yaml" && os.IsNotExist(err) {
@@ -40,6 +62,8 @@ func main() {
 			slog.Error("failed to load config", "error", err)
 			os.Exit(1)
 		}
+	} else {
+		slog.Info("Configuration loaded", "profile", *profile, "files", loader.Files())
 	}
 
 	// Create server
@@ -50,42 +74,63 @@ func main() {
 	}
 
 	// Setup signal handling
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	// reload applies a new configuration. Handlers and routes are swapped
+	// in place; when the frontend listeners change the server is replaced
+	// instead. The new server takes over the old server's listeners, so
+	// ports stay bound, and the old server then drains its connections
+	// while new ones reach the new server.
+	var serverMu sync.Mutex
+	reload := func(newConfig *config.Config) error {
+		serverMu.Lock()
+		defer serverMu.Unlock()
+
+		err := server.Reload(newConfig)
+		if !errors.Is(err, app.ErrRestartRequired) {
+			return err
+		}
+		slog.Info("Frontend configuration changed, restarting server")
+
+		// Create new server with new config
+		newServer, err := app.NewServer(newConfig, slog.Default())
+		if err != nil {
+			return err
+		}
+
+		// Start new server on the inherited listeners. Should it fail, it
+		// has handed them back and the old server keeps serving.
+		newServer.InheritListeners(server)
+		if err := newServer.Start(ctx); err != nil {
+			return fmt.Errorf("starting new server, keeping the old one: %w", err)
+		}
+
+		// Drain and stop old server
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), server.DrainTimeout()+shutdownGrace)
+		defer stopCancel()
+		if err := server.Stop(stopCtx); err != nil {
+			slog.Error("failed to stop old server", "error", err)
+		}
+
+		// Replace server reference
+		server = newServer
+		slog.Info("Configuration reloaded successfully")
+		return nil
+	}
+
 	// Setup hot reload if enabled
 	var watcher *config.Watcher
 	if *hotReload && *configFile != "" {
 		watcherConfig := &config.WatcherConfig{
 			OnChange: func(newConfig *config.Config) error {
 				slog.Info("Configuration changed, reloading...")
-				
-				// Create new server with new config
-				newServer, err := app.NewServer(newConfig, slog.Default())
-				if err != nil {
-					return err
-				}
-				
-				// Start new server
-				if err := newServer.Start(ctx); err != nil {
-					return err
-				}
-				
-				// Stop old server gracefully
-				stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
-				defer stopCancel()
-				if err := server.Stop(stopCtx); err != nil {
-					slog.Error("failed to stop old server", "error", err)
-				}
-				
-				// Replace server reference
-				server = newServer
-				slog.Info("Configuration reloaded successfully")
-				return nil
+				return reload(newConfig)
 			},
 			OnError: func(err error) {
 				slog.Error("Configuration reload error", "error", err)
 			},
+			Profile: *profile,
 		}
 		
 		watcher, err = config.NewWatcher(*configFile, watcherConfig, slog.Default())
@@ -105,11 +150,36 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Reload on SIGHUP
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	defer signal.Stop(hupCh)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hupCh:
+				slog.Info("Received SIGHUP, reloading configuration", "config", *configFile)
+				newConfig, err := newLoader(*configFile).Load()
+				if err != nil {
+					slog.Error("Configuration reload error", "error", err)
+					continue
+				}
+				if err := reload(newConfig); err != nil {
+					slog.Error("Configuration reload error", "error", err)
+				}
+			}
+		}
+	}()
+
 	// Wait for shutdown signal
 	<-ctx.Done()
 
-	// Graceful shutdown
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Graceful shutdown, draining connections first
+	serverMu.Lock()
+	defer serverMu.Unlock()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), server.DrainTimeout()+shutdownGrace)
 	defer shutdownCancel()
 
 	if err := server.Stop(shutdownCtx); err != nil {
@@ -118,6 +188,10 @@ func main() {
 	}
 }
 
+// shutdownGrace is the time allowed after draining for the remaining
+// components to shut down
+const shutdownGrace = 10 * time.Second
+
 var logLevels = map[string]slog.Level{
 	"debug": slog.LevelDebug,
 	"info":  slog.LevelInfo,
@@ -125,6 +199,57 @@ var logLevels = map[string]slog.Level{
 	"error": slog.LevelError,
 }
 
+// validateConfig loads and validates the config file and builds the gateway
+// from it without binding listeners. It reports all problems found and
+// returns the process exit code.
+func validateConfig(path string) int {
+	cfg, err := newLoader(path).Load()
+	if err == nil {
+		err = app.Validate(cfg, slog.Default())
+	}
+	if err != nil {
+		var validationErr *config.ValidationError
+		if errors.As(err, &validationErr) {
+			fmt.Fprintf(os.Stderr, "%s: invalid configuration:\n", path)
+			for _, problem := range validationErr.Problems {
+				fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		}
+		return 1
+	}
+
+	fmt.Printf("%s: configuration is valid\n", path)
+	return 0
+}
+
+// printMergedConfig prints the config the gateway would run with, after
+// merging includes, the profile overlay and environment variables, with
+// secrets redacted. It returns the process exit code.
+func printMergedConfig(path string) int {
+	loader := newLoader(path)
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	redacted, hash, err := config.Redact(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+
+	fmt.Printf("# Profile: %s\n# Files: %s\n# Hash: %s\n", *profile, strings.Join(loader.Files(), ", "), hash)
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(redacted); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	return 0
+}
+
 func setupLogging(level string) {
 	lvl := logLevels[strings.ToLower(level)]
 	if lvl == 0 {
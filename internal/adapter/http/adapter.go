@@ -7,30 +7,62 @@ import (
 	"errors"
 	"fmt"
 	"gateway/internal/core"
+	"gateway/pkg/clientip"
 	gwerrors "gateway/pkg/errors"
 	"gateway/pkg/requestid"
 	"io"
 	"log/slog"
 	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/quic-go/quic-go/http3"
 )
 
 // Adapter handles HTTP requests
 type Adapter struct {
 	config         Config
 	server         *http.Server
+	http3Server    *http3.Server
+	http3Conn      net.PacketConn
+	acmeServer     *http.Server
 	handler        core.Handler
 	sseHandler     SSEHandler
 	healthHandler  HealthHandler
 	healthConfig   HealthConfig
 	metricsHandler http.Handler
 	corsHandler    http.Handler
+	accessLog      func(http.Handler) http.Handler
+	middleware     []func(http.Handler) http.Handler
+	clientIP       *clientip.Resolver
+	explainRoute   EchoExplainer
+	secretQuery    map[string]bool
 	reqNum         atomic.Uint64
 	logger         *slog.Logger
+	listen         ListenFunc
+	listenPacket   ListenPacketFunc
+
+	// Requests are served by the handlers of serving, which Swap replaces
+	// on reload; inflight counts requests served by this adapter's handlers
+	swapMu   sync.RWMutex
+	serving  *Adapter
+	inflight sync.WaitGroup
+
+	frontendOnce    sync.Once
+	frontendHandler http.Handler
 }
 
+// ListenFunc binds the listener the adapter serves on
+type ListenFunc func(network, address string) (net.Listener, error)
+
+// ListenPacketFunc binds the UDP socket HTTP/3 is served on
+type ListenPacketFunc func(network, address string) (net.PacketConn, error)
+
 // HealthHandler handles health check requests
 type HealthHandler interface {
 	Health(w http.ResponseWriter, r *http.Request)
@@ -43,6 +75,12 @@ type SSEHandler interface {
 	HandleSSE(w http.ResponseWriter, r *http.Request)
 }
 
+// StreamDrainer is implemented by SSE handlers that can end open streams
+// when the server shuts down
+type StreamDrainer interface {
+	Drain(ctx context.Context) error
+}
+
 // New creates a new HTTP adapter
 func New(cfg Config, handler core.Handler) *Adapter {
 	return &Adapter{
@@ -50,9 +88,23 @@ func New(cfg Config, handler core.Handler) *Adapter {
 		handler:      handler,
 		healthConfig: DefaultHealthConfig(),
 		logger:       slog.Default().With("component", "http"),
+		listen:       net.Listen,
+		listenPacket: net.ListenPacket,
 	}
 }
 
+// WithListenFunc sets how the adapter binds its listener
+func (a *Adapter) WithListenFunc(listen ListenFunc) *Adapter {
+	a.listen = listen
+	return a
+}
+
+// WithListenPacketFunc sets how the adapter binds its HTTP/3 socket
+func (a *Adapter) WithListenPacketFunc(listen ListenPacketFunc) *Adapter {
+	a.listenPacket = listen
+	return a
+}
+
 // WithSSEHandler sets the SSE handler
 func (a *Adapter) WithSSEHandler(handler SSEHandler) *Adapter {
 	a.sseHandler = handler
@@ -89,14 +141,53 @@ func (a *Adapter) WithCORSHandler(handler http.Handler) *Adapter {
 	return a
 }
 
+// WithAccessLog wraps request handling with an access log middleware
+func (a *Adapter) WithAccessLog(wrap func(http.Handler) http.Handler) *Adapter {
+	a.accessLog = wrap
+	return a
+}
+
+// WithHTTPMiddleware wraps request handling with middleware working on
+// http.Handler, such as API versioning. It runs after the access log and
+// before CORS; middleware added later runs first.
+func (a *Adapter) WithHTTPMiddleware(middleware func(http.Handler) http.Handler) *Adapter {
+	a.middleware = append(a.middleware, middleware)
+	return a
+}
+
+// WithEchoExplainer adds the routing decision for the echoed request to
+// /_gateway/echo responses
+func (a *Adapter) WithEchoExplainer(explain EchoExplainer) *Adapter {
+	a.explainRoute = explain
+	return a
+}
+
+// WithSecretQueryParams redacts the named query parameters, such as those
+// carrying tokens or API keys, from /_gateway/echo responses
+func (a *Adapter) WithSecretQueryParams(names ...string) *Adapter {
+	if a.secretQuery == nil {
+		a.secretQuery = make(map[string]bool, len(names))
+	}
+	for _, name := range names {
+		a.secretQuery[name] = true
+	}
+	return a
+}
+
+// WithClientIP resolves the client IP of requests arriving through trusted
+// proxies, replacing their remote address before any other handling
+func (a *Adapter) WithClientIP(resolver *clientip.Resolver) *Adapter {
+	a.clientIP = resolver
+	return a
+}
+
 // Start starts the HTTP server
 func (a *Adapter) Start(ctx context.Context) error {
 	addr := fmt.Sprintf("%s:%d", a.config.Host, a.config.Port)
 
-	// Use CORS handler if configured, otherwise use the adapter directly
-	var handler http.Handler = a
-	if a.corsHandler != nil {
-		handler = a.corsHandler
+	handler := a.root()
+	if a.config.HTTP3 != nil {
+		handler = a.advertiseHTTP3(handler)
 	}
 
 	a.server = &http.Server{
@@ -108,13 +199,33 @@ func (a *Adapter) Start(ctx context.Context) error {
 		BaseContext: func(net.Listener) context.Context {
 			return ctx
 		},
+		// The server stops reading headers over the limit; ServeHTTP
+		// checks the exact limits
+		MaxHeaderBytes: a.config.HeaderLimits.MaxBytes,
+	}
+	if a.config.HTTP2 {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+		a.server.Protocols = protocols
 	}
 
 	// Create listener to detect bind errors early
-	listener, err := net.Listen("tcp", addr)
+	network := "tcp"
+	if a.config.UnixSocket != "" {
+		network, addr = "unix", a.config.UnixSocket
+	}
+	listener, err := a.listen(network, addr)
 	if err != nil {
 		return fmt.Errorf("failed to bind to %s: %w", addr, err)
 	}
+	if network == "unix" && a.config.SocketMode != 0 {
+		if err := os.Chmod(addr, a.config.SocketMode); err != nil {
+			listener.Close()
+			return fmt.Errorf("failed to set permissions of %s: %w", addr, err)
+		}
+	}
 
 	// If TLS is enabled, wrap the listener
 	if a.config.TLS != nil && a.config.TLS.Enabled {
@@ -123,11 +234,44 @@ func (a *Adapter) Start(ctx context.Context) error {
 			return fmt.Errorf("TLS enabled but no TLS configuration provided")
 		}
 		a.logger.Info("starting TLS server", "addr", addr, "cert", a.config.TLS.CertFile)
-		listener = tls.NewListener(listener, a.config.TLSConfig)
+		tlsConfig := a.config.TLSConfig
+		if a.config.HTTP2 {
+			// Negotiate HTTP/2 through ALPN
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+		}
+		listener = tls.NewListener(listener, tlsConfig)
 	} else {
 		a.logger.Info("starting server", "addr", addr)
 	}
 
+	if a.config.Certificates != nil {
+		if err := a.config.Certificates.Start(); err != nil {
+			listener.Close()
+			return err
+		}
+	}
+
+	if a.config.ACME != nil {
+		if err := a.startACMEChallenge(); err != nil {
+			listener.Close()
+			return err
+		}
+	}
+
+	if a.config.HTTP3 != nil {
+		if err := a.startHTTP3(); err != nil {
+			listener.Close()
+			if a.acmeServer != nil {
+				a.acmeServer.Close()
+			}
+			if a.config.Certificates != nil {
+				a.config.Certificates.Close()
+			}
+			return err
+		}
+	}
+
 	// Start server in goroutine
 	go func() {
 		err := a.server.Serve(listener)
@@ -139,14 +283,217 @@ func (a *Adapter) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop gracefully stops the server
+// startHTTP3 serves the same handlers over QUIC on the HTTP/3 UDP port
+func (a *Adapter) startHTTP3() error {
+	if a.config.TLSConfig == nil {
+		return fmt.Errorf("HTTP/3 enabled but no TLS configuration provided")
+	}
+
+	addr := fmt.Sprintf("%s:%d", a.config.Host, a.config.HTTP3.Port)
+	conn, err := a.listenPacket("udp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to bind HTTP/3 to %s: %w", addr, err)
+	}
+
+	a.http3Conn = conn
+	a.http3Server = &http3.Server{
+		Handler:        a.root(),
+		TLSConfig:      http3.ConfigureTLSConfig(a.config.TLSConfig),
+		Logger:         a.logger,
+		MaxHeaderBytes: a.config.HeaderLimits.MaxBytes,
+	}
+	a.logger.Info("starting HTTP/3 server", "addr", addr)
+
+	go func() {
+		err := a.http3Server.Serve(conn)
+		if err != http.ErrServerClosed {
+			a.logger.Error("HTTP/3 server error", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// startACMEChallenge serves ACME HTTP-01 challenges on the challenge port
+func (a *Adapter) startACMEChallenge() error {
+	addr := fmt.Sprintf("%s:%d", a.config.Host, a.config.ACME.HTTPPort)
+	listener, err := a.listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to bind ACME challenge listener to %s: %w", addr, err)
+	}
+
+	a.acmeServer = &http.Server{
+		Handler:           a.config.ACME.ChallengeHandler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	a.logger.Info("starting ACME challenge server", "addr", addr)
+
+	go func() {
+		err := a.acmeServer.Serve(listener)
+		if err != http.ErrServerClosed {
+			a.logger.Error("ACME challenge server error", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// advertiseHTTP3 announces the HTTP/3 listener to HTTP/1.1 and HTTP/2
+// clients through the Alt-Svc header
+func (a *Adapter) advertiseHTTP3(next http.Handler) http.Handler {
+	altSvc := fmt.Sprintf(`h3=":%d"; ma=%d`, a.config.HTTP3.Port, int(a.config.HTTP3.AltSvcMaxAge.Seconds()))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Alt-Svc", altSvc)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Stop gracefully stops the server. New connections are refused while
+// in-flight requests complete and open SSE streams are flushed and ended;
+// connections still active when ctx expires are closed. A Unix socket file
+// is removed when its listener is closed.
 func (a *Adapter) Stop(ctx context.Context) error {
 	if a.server == nil {
 		return nil
 	}
 
 	a.logger.Info("stopping server", "requests", a.reqNum.Load())
-	return a.server.Shutdown(ctx)
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- a.server.Shutdown(ctx)
+	}()
+
+	if a.acmeServer != nil {
+		go a.acmeServer.Shutdown(ctx)
+	}
+
+	if a.config.Certificates != nil {
+		a.config.Certificates.Close()
+	}
+
+	// HTTP/3 clients are sent GOAWAY and their requests complete the same way
+	var http3Err chan error
+	if a.http3Server != nil {
+		http3Err = make(chan error, 1)
+		go func() {
+			err := a.http3Server.Shutdown(ctx)
+			a.http3Conn.Close()
+			http3Err <- err
+		}()
+	}
+
+	// SSE streams only end when closed, so Shutdown would otherwise wait
+	// for them until ctx expires
+	if drainer, ok := a.current().sseHandler.(StreamDrainer); ok {
+		if err := drainer.Drain(ctx); err != nil {
+			a.logger.Warn("SSE streams not drained", "error", err)
+		}
+	}
+
+	if http3Err != nil {
+		if err := <-http3Err; err != nil && !errors.Is(err, ctx.Err()) {
+			a.logger.Warn("HTTP/3 server not stopped cleanly", "error", err)
+		}
+	}
+
+	err := <-shutdownErr
+	if err != nil && errors.Is(err, ctx.Err()) {
+		a.logger.Warn("drain grace period expired, closing remaining connections")
+		return a.server.Close()
+	}
+	return err
+}
+
+// Swap serves new requests with the handlers of next, keeping the listener
+// and server of a. It returns the adapter whose handlers served requests
+// until now, which can be drained with Drain.
+func (a *Adapter) Swap(next *Adapter) *Adapter {
+	a.swapMu.Lock()
+	defer a.swapMu.Unlock()
+
+	previous := a.serving
+	if previous == nil {
+		previous = a
+	}
+	a.serving = next
+	return previous
+}
+
+// Drain waits for requests served by the adapter's handlers to finish after
+// it has been swapped out. Open SSE streams are flushed and ended first.
+func (a *Adapter) Drain(ctx context.Context) error {
+	if drainer, ok := a.sseHandler.(StreamDrainer); ok {
+		if err := drainer.Drain(ctx); err != nil {
+			return err
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		a.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// root returns the handler served on the listeners
+func (a *Adapter) root() http.Handler {
+	handler := http.Handler(http.HandlerFunc(a.dispatch))
+	if a.clientIP != nil {
+		handler = a.clientIP.Handler(handler)
+	}
+	return handler
+}
+
+// current returns the adapter whose handlers serve requests
+func (a *Adapter) current() *Adapter {
+	a.swapMu.RLock()
+	defer a.swapMu.RUnlock()
+
+	if a.serving == nil {
+		return a
+	}
+	return a.serving
+}
+
+// dispatch serves a request with the current handlers
+func (a *Adapter) dispatch(w http.ResponseWriter, r *http.Request) {
+	a.swapMu.RLock()
+	target := a.serving
+	if target == nil {
+		target = a
+	}
+	target.inflight.Add(1)
+	a.swapMu.RUnlock()
+	defer target.inflight.Done()
+
+	target.frontend().ServeHTTP(w, r)
+}
+
+// frontend returns the adapter wrapped by its CORS handler, HTTP middleware
+// and access log, in that order from the inside out
+func (a *Adapter) frontend() http.Handler {
+	a.frontendOnce.Do(func() {
+		var handler http.Handler = a
+		if a.corsHandler != nil {
+			handler = a.corsHandler
+		}
+		for _, middleware := range a.middleware {
+			handler = middleware(handler)
+		}
+		if a.accessLog != nil {
+			handler = a.accessLog(handler)
+		}
+		a.frontendHandler = handler
+	})
+	return a.frontendHandler
 }
 
 // ServeHTTP implements http.Handler
@@ -163,6 +510,10 @@ func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		a.handleGatewayEcho(w, r)
 		return
 	}
+	if a.explainRoute != nil && strings.HasPrefix(r.URL.Path, echoPrefix) {
+		a.handleGatewayEcho(w, r)
+		return
+	}
 
 	// Handle health check endpoints (no request ID needed)
 	if a.healthHandler != nil && a.healthConfig.Enabled {
@@ -191,6 +542,17 @@ func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	reqID := requestid.GenerateRequestID()
 
+	// Reject oversized headers before the gateway adds its own
+	if a.config.HeaderLimits.Exceeded(r.Header) {
+		a.logger.Warn("request headers too large",
+			"request_id", reqID,
+			"max_bytes", a.config.HeaderLimits.MaxBytes,
+			"max_count", a.config.HeaderLimits.MaxCount,
+		)
+		a.writeError(w, r, reqID, http.StatusRequestHeaderFieldsTooLarge, "Request header fields too large")
+		return
+	}
+
 	// Add request ID to headers for downstream handlers
 	r.Header.Set("X-Request-ID", reqID)
 
@@ -206,29 +568,50 @@ func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		headers[k] = v
 	}
 
-	// Apply request size limit if configured
-	if a.config.MaxRequestSize > 0 && r.ContentLength > a.config.MaxRequestSize {
+	// Apply request size limit if configured. gRPC requests are only
+	// checked as their body is read, since a gRPC passthrough route lifts
+	// the limit once the request is routed.
+	grpcCall := isGRPCRequest(r)
+	if a.config.MaxRequestSize > 0 && r.ContentLength > a.config.MaxRequestSize && !grpcCall {
 		a.logger.Warn("request body too large",
 			"request_id", reqID,
 			"content_length", r.ContentLength,
 			"max_size", a.config.MaxRequestSize,
 		)
-		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		a.writeError(w, r, reqID, http.StatusRequestEntityTooLarge, "Request body too large")
 		return
 	}
 
-	// Wrap body with size limiter if configured
+	// Wrap body with size limiter if configured. gRPC streams can carry any
+	// number of messages, whose size the backend limits, so the connector
+	// of a gRPC passthrough route may lift the limit.
+	var liftLimit func()
 	if a.config.MaxRequestSize > 0 && r.Body != nil {
-		r.Body = http.MaxBytesReader(w, r.Body, a.config.MaxRequestSize)
+		limited := http.MaxBytesReader(w, r.Body, a.config.MaxRequestSize)
+		if grpcCall {
+			body := &liftableBody{ReadCloser: r.Body, limited: limited}
+			liftLimit = body.lift
+			r.Body = body
+		} else {
+			r.Body = limited
+		}
+	}
+
+	// Interim responses of the backend are relayed until the handler returns
+	relay := newInformationalRelay(w, r)
+	ctx := core.WithInformational(r.Context(), relay.write)
+	if liftLimit != nil {
+		ctx = core.WithBodyLimitLift(ctx, liftLimit)
 	}
 
 	// Create request
 	req := newRequest(reqID, r)
 
 	// Handle request
-	resp, err := a.handler(r.Context(), req)
+	resp, err := a.handler(ctx, req)
+	relay.finish()
 	if err != nil {
-		a.handleError(w, reqID, err)
+		a.handleError(w, r, reqID, err)
 		return
 	}
 
@@ -239,6 +622,12 @@ func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Responses with trailers, such as gRPC calls, are streamed
+	if stream, ok := resp.(core.TrailerResponse); ok {
+		a.writeStream(w, reqID, stream)
+		return
+	}
+
 	w.WriteHeader(resp.StatusCode())
 
 	if body := resp.Body(); body != nil {
@@ -253,30 +642,54 @@ func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// writeStream writes a response body as it is read, flushing each chunk, and
+// then sends the response trailers. Headers are sent with the first chunk, so
+// a response without a body, such as a gRPC error, ends with its headers.
+func (a *Adapter) writeStream(w http.ResponseWriter, reqID string, resp core.TrailerResponse) {
+	rc := http.NewResponseController(w)
+	w.WriteHeader(resp.StatusCode())
+
+	if body := resp.Body(); body != nil {
+		defer body.Close()
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := body.Read(buf)
+			if n > 0 {
+				if _, writeErr := w.Write(buf[:n]); writeErr != nil {
+					a.logger.Error("failed to write response stream", "error", writeErr, "request_id", reqID)
+					return
+				}
+				rc.Flush()
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				// Clients end streams by cancelling them
+				if errors.Is(err, context.Canceled) {
+					a.logger.Debug("response stream cancelled", "request_id", reqID)
+					return
+				}
+				a.logger.Error("failed to read response stream", "error", err, "request_id", reqID)
+				return
+			}
+		}
+	}
+
+	for k, values := range resp.Trailers() {
+		for _, v := range values {
+			w.Header().Add(http.TrailerPrefix+k, v)
+		}
+	}
+}
+
 // errorTypeToHTTPStatus maps error types to HTTP status codes
 func errorTypeToHTTPStatus(errType gwerrors.ErrorType) int {
-	switch errType {
-	case gwerrors.ErrorTypeNotFound:
-		return http.StatusNotFound
-	case gwerrors.ErrorTypeBadRequest:
-		return http.StatusBadRequest
-	case gwerrors.ErrorTypeUnauthorized:
-		return http.StatusUnauthorized
-	case gwerrors.ErrorTypeForbidden:
-		return http.StatusForbidden
-	case gwerrors.ErrorTypeTimeout:
-		return http.StatusRequestTimeout
-	case gwerrors.ErrorTypeUnavailable:
-		return http.StatusServiceUnavailable
-	case gwerrors.ErrorTypeRateLimit:
-		return http.StatusTooManyRequests
-	default:
-		return http.StatusInternalServerError
-	}
+	return errType.HTTPStatus()
 }
 
 // handleError handles errors by mapping them to appropriate HTTP responses
-func (a *Adapter) handleError(w http.ResponseWriter, reqID string, err error) {
+func (a *Adapter) handleError(w http.ResponseWriter, r *http.Request, reqID string, err error) {
 	var gwErr *gwerrors.Error
 	var statusCode int
 	var message string
@@ -297,6 +710,32 @@ func (a *Adapter) handleError(w http.ResponseWriter, reqID string, err error) {
 		a.logger.Error("request failed", "id", reqID, "error", err)
 	}
 
+	var headerErr core.HeaderError
+	if errors.As(err, &headerErr) {
+		for name, values := range headerErr.ResponseHeaders() {
+			w.Header()[name] = values
+		}
+	}
+	a.writeError(w, r, reqID, statusCode, message)
+}
+
+// writeError writes the configured error page for statusCode, or message
+// as plain text
+func (a *Adapter) writeError(w http.ResponseWriter, r *http.Request, reqID string, statusCode int, message string) {
+	if a.config.ErrorPages != nil {
+		written, err := a.config.ErrorPages.Write(w, r, ErrorData{
+			Status:     statusCode,
+			StatusText: http.StatusText(statusCode),
+			Message:    message,
+			RequestID:  reqID,
+		})
+		if err != nil {
+			a.logger.Error("failed to render error page", "request_id", reqID, "status", statusCode, "error", err)
+		}
+		if written {
+			return
+		}
+	}
 	http.Error(w, message, statusCode)
 }
 
@@ -313,6 +752,31 @@ func isSSERequest(r *http.Request) bool {
 	return false
 }
 
+// liftableBody enforces the request size limit until it is lifted
+type liftableBody struct {
+	io.ReadCloser
+	limited io.ReadCloser
+	lifted  atomic.Bool
+}
+
+func (b *liftableBody) lift() {
+	b.lifted.Store(true)
+}
+
+func (b *liftableBody) Read(p []byte) (int, error) {
+	if b.lifted.Load() {
+		return b.ReadCloser.Read(p)
+	}
+	return b.limited.Read(p)
+}
+
+// isGRPCRequest checks if the request is a native or gRPC-Web call
+func isGRPCRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	return contentType == "application/grpc" || strings.HasPrefix(contentType, "application/grpc+") ||
+		strings.HasPrefix(contentType, "application/grpc-web")
+}
+
 // handleGatewayHealth returns the gateway's own health status
 func (a *Adapter) handleGatewayHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -321,6 +785,10 @@ func (a *Adapter) handleGatewayHealth(w http.ResponseWriter, r *http.Request) {
 		time.Now().Format(time.RFC3339))
 }
 
+// echoPrefix is the prefix of echo requests explaining the routing of the
+// path below it, e.g. /_gateway/echo/api/orders for /api/orders
+const echoPrefix = "/_gateway/echo/"
+
 // handleGatewayEcho echoes back request information
 func (a *Adapter) handleGatewayEcho(w http.ResponseWriter, r *http.Request) {
 	// Read body
@@ -335,12 +803,19 @@ func (a *Adapter) handleGatewayEcho(w http.ResponseWriter, r *http.Request) {
 	resp := map[string]interface{}{
 		"method": r.Method,
 		"path":   r.URL.Path,
-		"query":  r.URL.Query(),
-		"headers": r.Header,
+		"query":  a.redactQuery(r.URL.Query()),
+		"headers": redactHeaders(r.Header),
 		"body":   body,
 		"remote": r.RemoteAddr,
 		"timestamp": time.Now().Format(time.RFC3339),
 	}
+	// Explain how the path below the echo path would be routed
+	if a.explainRoute != nil && strings.HasPrefix(r.URL.Path, echoPrefix) {
+		routed := r.Clone(r.Context())
+		routed.URL.Path = "/" + strings.TrimPrefix(r.URL.Path, echoPrefix)
+		routed.URL.RawPath = ""
+		resp["routing"] = a.explainRoute(r.Context(), newRequest(requestid.GenerateRequestID(), routed))
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -349,3 +824,41 @@ func (a *Adapter) handleGatewayEcho(w http.ResponseWriter, r *http.Request) {
 	encoder.SetIndent("", "  ")
 	encoder.Encode(resp)
 }
+
+// secretHeaders are echoed as redacted
+var secretHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// redactHeaders copies headers, redacting credentials such as Authorization,
+// cookies and headers named like API keys, tokens or secrets
+func redactHeaders(headers http.Header) http.Header {
+	redacted := make(http.Header, len(headers))
+	for name, values := range headers {
+		lower := strings.ToLower(name)
+		if secretHeaders[http.CanonicalHeaderKey(name)] || strings.Contains(lower, "api-key") ||
+			strings.Contains(lower, "token") || strings.Contains(lower, "secret") {
+			values = []string{"[REDACTED]"}
+		}
+		redacted[name] = values
+	}
+	return redacted
+}
+
+// redactQuery copies query parameters, redacting those configured as secret
+// and those named like API keys, tokens, secrets or passwords
+func (a *Adapter) redactQuery(query url.Values) url.Values {
+	redacted := make(url.Values, len(query))
+	for name, values := range query {
+		lower := strings.ToLower(name)
+		if a.secretQuery[name] || strings.Contains(lower, "key") || strings.Contains(lower, "token") ||
+			strings.Contains(lower, "secret") || strings.Contains(lower, "password") {
+			values = []string{"[REDACTED]"}
+		}
+		redacted[name] = values
+	}
+	return redacted
+}
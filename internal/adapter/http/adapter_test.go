@@ -2,7 +2,10 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	httpConnector "gateway/internal/connector/http"
 	"gateway/internal/core"
 	"gateway/pkg/errors"
 	"gateway/pkg/requestid"
@@ -10,10 +13,13 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/quic-go/quic-go/http3"
 )
 
 // mockHandler for testing
@@ -299,6 +305,52 @@ func TestAdapterStreamingResponse(t *testing.T) {
 	}
 }
 
+func TestAdapterBackendTrailers(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum, X-Row-Count")
+		w.Header().Set("Content-Type", "text/csv")
+		io.WriteString(w, "a,b\n1,2\n")
+		w.Header().Set("X-Checksum", "abc123")
+		w.Header().Set("X-Row-Count", "2")
+	}))
+	defer backend.Close()
+	host, portStr, _ := net.SplitHostPort(strings.TrimPrefix(backend.URL, "http://"))
+	port, _ := strconv.Atoi(portStr)
+
+	connector := httpConnector.NewHTTPConnector(&http.Client{}, 10*time.Second)
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		return connector.Forward(ctx, req, &core.RouteResult{
+			Instance: &core.ServiceInstance{ID: "backend", Address: host, Port: port},
+		})
+	}
+	frontend := httptest.NewServer(New(Config{Host: "127.0.0.1"}, handler))
+	defer frontend.Close()
+
+	resp, err := http.Get(frontend.URL + "/export")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+	if string(body) != "a,b\n1,2\n" {
+		t.Errorf("Body = %q", body)
+	}
+	// Trailers are complete once the body has been read
+	if got := resp.Trailer.Get("X-Checksum"); got != "abc123" {
+		t.Errorf("Trailer X-Checksum = %q, want abc123", got)
+	}
+	if got := resp.Trailer.Get("X-Row-Count"); got != "2" {
+		t.Errorf("Trailer X-Row-Count = %q, want 2", got)
+	}
+	if resp.Header.Get("X-Checksum") != "" {
+		t.Error("Expected the trailer not to be sent as a header")
+	}
+}
+
 func TestAdapterRequestIDUniqueness(t *testing.T) {
 	// Generate multiple request IDs
 	ids := make(map[string]bool)
@@ -371,11 +423,12 @@ func TestAdapterBuiltinEndpoints(t *testing.T) {
 	}
 
 	cfg := Config{Host: "127.0.0.1", Port: 8080}
-	adapter := New(cfg, handler)
+	adapter := New(cfg, handler).WithSecretQueryParams("sig")
 
 	tests := []struct {
 		name           string
 		path           string
+		headers        map[string]string
 		expectedStatus int
 		checkBody      func(t *testing.T, body string)
 	}{
@@ -404,6 +457,41 @@ func TestAdapterBuiltinEndpoints(t *testing.T) {
 				if !strings.Contains(body, `"path": "/_gateway/echo"`) {
 					t.Errorf("Expected path in echo response, got: %s", body)
 				}
+				if strings.Contains(body, `"routing"`) {
+					t.Errorf("Expected no routing without a route explainer, got: %s", body)
+				}
+			},
+		},
+		{
+			name: "gateway echo redacts credentials",
+			path: "/_gateway/echo",
+			headers: map[string]string{
+				"Authorization": "Bearer secret-token",
+				"Cookie":        "session=secret-session",
+				"X-Api-Key":     "secret-key",
+				"X-Trace":       "visible",
+			},
+			expectedStatus: http.StatusOK,
+			checkBody: func(t *testing.T, body string) {
+				if strings.Contains(body, "secret-") {
+					t.Errorf("Expected credentials to be redacted, got: %s", body)
+				}
+				if !strings.Contains(body, `"visible"`) {
+					t.Errorf("Expected other headers to be echoed, got: %s", body)
+				}
+			},
+		},
+		{
+			name:           "gateway echo redacts query credentials",
+			path:           "/_gateway/echo?sig=secret-sig&access_token=secret-token&api_key=secret-key&page=visible",
+			expectedStatus: http.StatusOK,
+			checkBody: func(t *testing.T, body string) {
+				if strings.Contains(body, "secret-") {
+					t.Errorf("Expected query credentials to be redacted, got: %s", body)
+				}
+				if !strings.Contains(body, `"visible"`) {
+					t.Errorf("Expected other query parameters to be echoed, got: %s", body)
+				}
 			},
 		},
 	}
@@ -411,6 +499,9 @@ func TestAdapterBuiltinEndpoints(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			req := httptest.NewRequest("GET", tt.path, nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
 			recorder := httptest.NewRecorder()
 
 			adapter.ServeHTTP(recorder, req)
@@ -426,6 +517,61 @@ func TestAdapterBuiltinEndpoints(t *testing.T) {
 	}
 }
 
+type explainerFunc func(context.Context, core.Request) (*core.RouteRule, string, error)
+
+func (f explainerFunc) Explain(ctx context.Context, req core.Request) (*core.RouteRule, string, error) {
+	return f(ctx, req)
+}
+
+func TestAdapterEchoRouting(t *testing.T) {
+	router := explainerFunc(func(ctx context.Context, req core.Request) (*core.RouteRule, string, error) {
+		if req.Path() != "/api/orders" {
+			return nil, "", errors.NewError(errors.ErrorTypeNotFound, "route not found")
+		}
+		return &core.RouteRule{ID: "orders"}, "orders", nil
+	})
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		t.Error("Handler should not be called for echo requests")
+		return nil, nil
+	}
+	adapter := New(Config{Host: "127.0.0.1", Port: 8080}, handler).
+		WithEchoExplainer(NewEchoExplainer(router, []string{"auth", "router"}))
+
+	tests := []struct {
+		path string
+		want RouteDecision
+	}{
+		{"/_gateway/echo/api/orders", RouteDecision{
+			Rule: "orders", Service: "orders",
+			Middlewares: []string{"auth", "router"},
+		}},
+		{"/_gateway/echo/unknown", RouteDecision{
+			Middlewares: []string{"auth", "router"},
+			Error:       "not_found: route not found",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			adapter.ServeHTTP(recorder, httptest.NewRequest("GET", tt.path, nil))
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("Status = %d, want %d", recorder.Code, http.StatusOK)
+			}
+			var body struct {
+				Routing RouteDecision `json:"routing"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Invalid echo response: %v", err)
+			}
+			if !reflect.DeepEqual(body.Routing, tt.want) {
+				t.Errorf("Routing = %+v, want %+v", body.Routing, tt.want)
+			}
+		})
+	}
+}
+
 func TestAdapterMaxRequestSize(t *testing.T) {
 	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
 		// Should not be called for oversized requests
@@ -457,6 +603,118 @@ func TestAdapterMaxRequestSize(t *testing.T) {
 	}
 }
 
+func TestAdapterMaxRequestSizeGRPC(t *testing.T) {
+	// The handler lifts the limit as a gRPC passthrough route does
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		if req.Headers()["X-Passthrough"] != nil {
+			core.LiftBodyLimit(ctx)
+		}
+		status := http.StatusOK
+		if _, err := io.ReadAll(req.Body()); err != nil {
+			status = http.StatusRequestEntityTooLarge
+		}
+		return &mockResponse{statusCode: status, headers: map[string][]string{}}, nil
+	}
+	adapter := New(Config{Host: "127.0.0.1", MaxRequestSize: 1024}, handler)
+
+	tests := []struct {
+		name        string
+		contentType string
+		passthrough bool
+		want        int
+	}{
+		{"grpc on passthrough route", "application/grpc", true, http.StatusOK},
+		{"grpc-web on passthrough route", "application/grpc-web+proto", true, http.StatusOK},
+		{"grpc on other route", "application/grpc", false, http.StatusRequestEntityTooLarge},
+		{"lift ignored for other requests", "application/json", true, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/pkg.Service/Upload", strings.NewReader(strings.Repeat("x", 2048)))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.passthrough {
+				req.Header.Set("X-Passthrough", "1")
+			}
+
+			recorder := httptest.NewRecorder()
+			adapter.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.want {
+				t.Errorf("Status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdapterHeaderLimits(t *testing.T) {
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		return &mockResponse{
+			statusCode: http.StatusOK,
+			headers:    map[string][]string{},
+			body:       io.NopCloser(strings.NewReader("ok")),
+		}, nil
+	}
+
+	tests := []struct {
+		name   string
+		limits core.HeaderLimits
+		want   int
+	}{
+		{"within limits", core.HeaderLimits{MaxBytes: 1024, MaxCount: 10}, http.StatusOK},
+		{"too many fields", core.HeaderLimits{MaxCount: 3}, http.StatusRequestHeaderFieldsTooLarge},
+		{"too many bytes", core.HeaderLimits{MaxBytes: 100}, http.StatusRequestHeaderFieldsTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := New(Config{Host: "127.0.0.1", HeaderLimits: tt.limits}, handler)
+
+			req := httptest.NewRequest("GET", "/api/users", nil)
+			req.Header.Set("Authorization", "Bearer "+strings.Repeat("x", 64))
+			req.Header.Add("X-Tag", "a")
+			req.Header.Add("X-Tag", "b")
+			req.Header.Add("X-Tag", "c")
+
+			recorder := httptest.NewRecorder()
+			adapter.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.want {
+				t.Errorf("Status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+// headerError is an error carrying response headers
+type headerError struct {
+	err     error
+	headers map[string][]string
+}
+
+func (e headerError) Error() string                        { return e.err.Error() }
+func (e headerError) ResponseHeaders() map[string][]string { return e.headers }
+func (e headerError) Unwrap() error                        { return e.err }
+
+func TestAdapterErrorHeaders(t *testing.T) {
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		return nil, headerError{
+			err:     errors.NewError(errors.ErrorTypeForbidden, "Access denied"),
+			headers: map[string][]string{"X-Frame-Options": {"DENY"}},
+		}
+	}
+	adapter := New(Config{Host: "127.0.0.1", Port: 8080}, handler)
+
+	recorder := httptest.NewRecorder()
+	adapter.ServeHTTP(recorder, httptest.NewRequest("GET", "/api", nil))
+
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("Status = %d, want %d", recorder.Code, http.StatusForbidden)
+	}
+	if got := recorder.Header().Get("X-Frame-Options"); got != "DENY" {
+		t.Errorf("X-Frame-Options = %q, want DENY", got)
+	}
+}
+
 func TestAdapterHealthEndpoints(t *testing.T) {
 	// Create mock health handler
 	healthCalled := make(map[string]bool)
@@ -755,3 +1013,149 @@ func TestAdapterWithMethods(t *testing.T) {
 		t.Error("Metrics path not set correctly")
 	}
 }
+
+func TestAdapterHTTP3(t *testing.T) {
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		return &mockResponse{
+			statusCode: http.StatusOK,
+			headers:    make(map[string][]string),
+			body:       io.NopCloser(strings.NewReader("ok")),
+		}, nil
+	}
+
+	// Borrow the test certificate of an httptest TLS server
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer certServer.Close()
+	rootCAs := certServer.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	cfg := Config{
+		Host:      "127.0.0.1",
+		Port:      port,
+		TLS:       &TLSConfig{Enabled: true},
+		TLSConfig: &tls.Config{Certificates: certServer.TLS.Certificates},
+		HTTP3:     &HTTP3Config{Port: port, AltSvcMaxAge: time.Hour},
+	}
+	adapter := New(cfg, handler)
+
+	ctx := context.Background()
+	if err := adapter.Start(ctx); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		adapter.Stop(stopCtx)
+	}()
+
+	url := fmt.Sprintf("https://127.0.0.1:%d/test", port)
+
+	// HTTP/1.1 and HTTP/2 responses advertise the HTTP/3 listener
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs}}}
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Failed to connect over TLS: %v", err)
+	}
+	resp.Body.Close()
+	if want := fmt.Sprintf(`h3=":%d"; ma=3600`, port); resp.Header.Get("Alt-Svc") != want {
+		t.Errorf("Alt-Svc = %q, want %q", resp.Header.Get("Alt-Svc"), want)
+	}
+
+	// The same handler serves HTTP/3
+	transport := &http3.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs}}
+	defer transport.Close()
+	resp, err = (&http.Client{Transport: transport}).Get(url)
+	if err != nil {
+		t.Fatalf("Failed to connect over HTTP/3: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.ProtoMajor != 3 {
+		t.Errorf("Proto = %s, want HTTP/3", resp.Proto)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Errorf("Got %d %q, want 200 \"ok\"", resp.StatusCode, body)
+	}
+}
+
+func TestAdapterACMEChallenge(t *testing.T) {
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		return &mockResponse{statusCode: http.StatusOK, headers: make(map[string][]string)}, nil
+	}
+
+	ports := make([]int, 2)
+	for i := range ports {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ports[i] = listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+	}
+
+	cfg := Config{
+		Host: "127.0.0.1",
+		Port: ports[0],
+		ACME: &ACMEConfig{
+			HTTPPort: ports[1],
+			ChallengeHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "token:"+r.URL.Path)
+			}),
+		},
+	}
+	adapter := New(cfg, handler)
+
+	ctx := context.Background()
+	if err := adapter.Start(ctx); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/.well-known/acme-challenge/abc", ports[1]))
+	if err != nil {
+		t.Fatalf("Failed to reach challenge listener: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "token:/.well-known/acme-challenge/abc" {
+		t.Errorf("Challenge response = %q", body)
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := adapter.Stop(stopCtx); err != nil {
+		t.Fatalf("Failed to stop server: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/", ports[1])); err == nil {
+		t.Error("Challenge listener should be closed after stop")
+	}
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	tests := []struct {
+		port     int
+		method   string
+		target   string
+		status   int
+		location string
+	}{
+		{443, http.MethodGet, "http://example.com/path?q=1", http.StatusFound, "https://example.com/path?q=1"},
+		{8443, http.MethodGet, "http://example.com:80/path", http.StatusFound, "https://example.com:8443/path"},
+		{443, http.MethodPost, "http://example.com/path", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		redirectToHTTPS(tt.port).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		if rec.Code != tt.status || rec.Header().Get("Location") != tt.location {
+			t.Errorf("%s %s: got %d %q, want %d %q", tt.method, tt.target, rec.Code, rec.Header().Get("Location"), tt.status, tt.location)
+		}
+	}
+}
@@ -2,7 +2,12 @@ package http
 
 import (
 	"crypto/tls"
+	"net/http"
+	"os"
 	"time"
+
+	"gateway/internal/core"
+	tlsutil "gateway/pkg/tls"
 )
 
 // Config holds HTTP adapter configuration
@@ -13,8 +18,29 @@ type Config struct {
 	WriteTimeout   time.Duration
 	MaxRequestSize int64  // Maximum request body size in bytes (0 = no limit)
 	MetricsPath    string // Path for metrics endpoint
+	HTTP2          bool   // Accept HTTP/2 over TLS and cleartext (h2c)
 	TLS            *TLSConfig
-	TLSConfig      *tls.Config // Full TLS configuration
+	TLSConfig      *tls.Config        // Full TLS configuration
+	Certificates   *tlsutil.CertStore // Serves the certificates of TLSConfig by SNI, reloading changed files
+	HTTP3          *HTTP3Config       // Also serve HTTP/3 over QUIC (requires TLS)
+	UnixSocket     string             // Listen on this Unix socket path instead of Host:Port
+	SocketMode     os.FileMode        // Permissions of the Unix socket file (0 = leave as created)
+	ACME           *ACMEConfig        // Serve ACME HTTP-01 challenges for automatic certificates
+	ErrorPages     *ErrorPages        // Error responses replacing plain text errors
+	// Requests with header fields over these limits are rejected with 431
+	HeaderLimits core.HeaderLimits
+}
+
+// ACMEConfig holds the ACME challenge listener configuration
+type ACMEConfig struct {
+	HTTPPort         int          // Port the challenge handler listens on
+	ChallengeHandler http.Handler // Answers HTTP-01 challenges, redirecting other requests to HTTPS
+}
+
+// HTTP3Config holds HTTP/3 listener configuration
+type HTTP3Config struct {
+	Port         int           // UDP port
+	AltSvcMaxAge time.Duration // How long clients may remember the Alt-Svc advertisement
 }
 
 // TLSConfig holds TLS configuration
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"context"
+
+	"gateway/internal/core"
+)
+
+// EchoExplainer reports how a request is routed, for the echo endpoint
+type EchoExplainer func(ctx context.Context, req core.Request) any
+
+// RouteDecision is the routing of an echoed request
+type RouteDecision struct {
+	Rule        string   `json:"rule,omitempty"`
+	Service     string   `json:"service,omitempty"`
+	Middlewares []string `json:"middlewares,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// NewEchoExplainer explains the routing of requests with router, without
+// selecting an instance or recording metrics. middlewares names the request
+// middleware in the order requests pass them.
+func NewEchoExplainer(router interface {
+	Explain(ctx context.Context, req core.Request) (*core.RouteRule, string, error)
+}, middlewares []string) EchoExplainer {
+	return func(ctx context.Context, req core.Request) any {
+		decision := RouteDecision{Middlewares: middlewares}
+		rule, service, err := router.Explain(ctx, req)
+		if err != nil {
+			decision.Error = err.Error()
+			return decision
+		}
+		decision.Service = service
+		if rule != nil {
+			decision.Rule = rule.ID
+		}
+		return decision
+	}
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gateway/internal/config"
+)
+
+// ErrorData is what error page templates are executed with
+type ErrorData struct {
+	Status     int
+	StatusText string
+	Message    string
+	RequestID  string
+}
+
+// ErrorPages writes configured error responses by status code or class
+type ErrorPages struct {
+	pages map[string]errorPage
+}
+
+type errorPage struct {
+	html *errorBody
+	json *errorBody
+}
+
+type errorBody struct {
+	contentType string
+	body        []byte
+	template    interface {
+		Execute(w io.Writer, data any) error
+	}
+}
+
+// NewErrorPages loads error pages keyed by status code ("404") or class
+// ("4xx", "5xx"), parsing their templates
+func NewErrorPages(cfg map[string]config.ErrorPage) (*ErrorPages, error) {
+	p := &ErrorPages{pages: make(map[string]errorPage, len(cfg))}
+	for key, page := range cfg {
+		html, err := newErrorBody(page.HTML, "text/html; charset=utf-8", true)
+		if err != nil {
+			return nil, fmt.Errorf("error page %s html: %w", key, err)
+		}
+		json, err := newErrorBody(page.JSON, "application/json; charset=utf-8", false)
+		if err != nil {
+			return nil, fmt.Errorf("error page %s json: %w", key, err)
+		}
+		p.pages[key] = errorPage{html: html, json: json}
+	}
+	return p, nil
+}
+
+func newErrorBody(cfg *config.ErrorBody, contentType string, html bool) (*errorBody, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	b := &errorBody{contentType: contentType, body: []byte(cfg.Body)}
+	if cfg.ContentType != "" {
+		b.contentType = cfg.ContentType
+	}
+	if cfg.Template == "" {
+		return b, nil
+	}
+
+	data, err := os.ReadFile(cfg.Template)
+	if err != nil {
+		return nil, err
+	}
+	if html {
+		b.template, err = htmltemplate.New(cfg.Template).Parse(string(data))
+	} else {
+		b.template, err = template.New(cfg.Template).Funcs(template.FuncMap{"json": jsonValue}).Parse(string(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// jsonValue encodes v as a JSON value for JSON templates
+func jsonValue(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// Write writes the error page for data.Status in the variant r accepts. It
+// reports false, having written nothing, when there is no such page.
+func (p *ErrorPages) Write(w http.ResponseWriter, r *http.Request, data ErrorData) (bool, error) {
+	page, ok := p.pages[strconv.Itoa(data.Status)]
+	if !ok {
+		page, ok = p.pages[fmt.Sprintf("%dxx", data.Status/100)]
+	}
+	if !ok {
+		return false, nil
+	}
+
+	var body *errorBody
+	switch negotiate(r.Header.Get("Accept"), page.html != nil, page.json != nil) {
+	case "html":
+		body = page.html
+	case "json":
+		body = page.json
+	default:
+		return false, nil
+	}
+
+	content := body.body
+	if body.template != nil {
+		var buf bytes.Buffer
+		if err := body.template.Execute(&buf, data); err != nil {
+			return false, err
+		}
+		content = buf.Bytes()
+	}
+
+	w.Header().Set("Content-Type", body.contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(data.Status)
+	w.Write(content)
+	return true, nil
+}
+
+// negotiate picks "html" or "json" by the quality Accept gives each of the
+// available variants, preferring json on a tie. An empty Accept accepts
+// both.
+func negotiate(accept string, html, json bool) string {
+	htmlQ, jsonQ := -1.0, -1.0
+	if html {
+		htmlQ = acceptQuality(accept, "text", "html")
+	}
+	if json {
+		jsonQ = acceptQuality(accept, "application", "json")
+	}
+	switch {
+	case jsonQ > 0 && jsonQ >= htmlQ:
+		return "json"
+	case htmlQ > 0:
+		return "html"
+	}
+	return ""
+}
+
+// acceptQuality returns the quality of the most specific media range in
+// accept matching typ/subtype, or 0 when none does
+func acceptQuality(accept, typ, subtype string) float64 {
+	if strings.TrimSpace(accept) == "" {
+		return 1
+	}
+	quality, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		t, s, _ := strings.Cut(mediaType, "/")
+		var match int
+		switch {
+		case t == typ && s == subtype:
+			match = 2
+		case t == typ && s == "*":
+			match = 1
+		case t == "*" && s == "*":
+			match = 0
+		default:
+			continue
+		}
+		if match <= specificity {
+			continue
+		}
+		specificity = match
+		quality = 1
+		if q, ok := params["q"]; ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = v
+			}
+		}
+	}
+	return quality
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+	"gateway/pkg/errors"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept     string
+		html, json bool
+		want       string
+	}{
+		{"", true, true, "json"},
+		{"*/*", true, false, "html"},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", true, true, "html"},
+		{"application/json", true, true, "json"},
+		{"text/*;q=0.5, application/json;q=0.4", true, true, "html"},
+		{"application/json", true, false, ""},
+		{"text/html;q=0", true, false, ""},
+		{"image/png", true, true, ""},
+	}
+
+	for _, tt := range tests {
+		if got := negotiate(tt.accept, tt.html, tt.json); got != tt.want {
+			t.Errorf("negotiate(%q, %v, %v) = %q, want %q", tt.accept, tt.html, tt.json, got, tt.want)
+		}
+	}
+}
+
+func TestAdapterErrorPages(t *testing.T) {
+	dir := t.TempDir()
+	htmlTemplate := filepath.Join(dir, "error.html")
+	os.WriteFile(htmlTemplate, []byte(`<p>{{.Status}} {{.Message}} ({{.RequestID}})</p>`), 0644)
+	jsonTemplate := filepath.Join(dir, "error.json")
+	os.WriteFile(jsonTemplate, []byte(`{"error":{{json .Message}},"status":{{.Status}}}`), 0644)
+
+	pages, err := NewErrorPages(map[string]config.ErrorPage{
+		"5xx": {
+			HTML: &config.ErrorBody{Template: htmlTemplate},
+			JSON: &config.ErrorBody{Template: jsonTemplate},
+		},
+		"503": {HTML: &config.ErrorBody{Body: "maintenance", ContentType: "text/plain"}},
+	})
+	if err != nil {
+		t.Fatalf("NewErrorPages() error = %v", err)
+	}
+
+	message := `Bad "gateway" <b>`
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		switch req.Path() {
+		case "/unavailable":
+			return nil, errors.NewError(errors.ErrorTypeUnavailable, "down")
+		case "/forbidden":
+			return nil, errors.NewError(errors.ErrorTypeForbidden, "denied")
+		}
+		return nil, errors.NewError(errors.ErrorTypeInternal, message)
+	}
+	adapter := New(Config{Host: "127.0.0.1", Port: 8080, ErrorPages: pages}, handler)
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"html template", "/", "text/html", 500, "text/html; charset=utf-8", `<p>500 Bad &#34;gateway&#34; &lt;b&gt; (`},
+		{"json template", "/", "application/json", 500, "application/json; charset=utf-8", `{"error":"Bad \"gateway\" \u003cb\u003e","status":500}`},
+		{"status code before class", "/unavailable", "", 503, "text/plain", "maintenance"},
+		{"no acceptable variant", "/", "image/png", 500, "text/plain; charset=utf-8", message + "\n"},
+		{"no page", "/forbidden", "text/html", 403, "text/plain; charset=utf-8", "denied\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			recorder := httptest.NewRecorder()
+			adapter.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.status {
+				t.Errorf("Status = %d, want %d", recorder.Code, tt.status)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			body := recorder.Body.String()
+			if len(body) < len(tt.body) || body[:len(tt.body)] != tt.body {
+				t.Errorf("Body = %q, want prefix %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestNewErrorPages_InvalidTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "error.html")
+	os.WriteFile(path, []byte(`{{.Status`), 0644)
+
+	_, err := NewErrorPages(map[string]config.ErrorPage{"4xx": {HTML: &config.ErrorBody{Template: path}}})
+	if err == nil {
+		t.Error("Expected error for an invalid template")
+	}
+}
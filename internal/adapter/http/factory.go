@@ -4,8 +4,14 @@ import (
 	"crypto/tls"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
 	"time"
-	
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
 	"gateway/internal/config"
 	"gateway/internal/core"
 	"gateway/pkg/factory"
@@ -51,6 +57,19 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 		ReadTimeout:    time.Duration(httpConfig.ReadTimeout) * time.Second,
 		WriteTimeout:   time.Duration(httpConfig.WriteTimeout) * time.Second,
 		MaxRequestSize: httpConfig.MaxRequestSize,
+		HTTP2:          httpConfig.HTTP2,
+		UnixSocket:     httpConfig.UnixSocket,
+		HeaderLimits: core.HeaderLimits{
+			MaxBytes: httpConfig.MaxHeaderBytes,
+			MaxCount: httpConfig.MaxHeaderCount,
+		},
+	}
+	if c.config.UnixSocket != "" {
+		mode, err := config.ParseSocketMode(httpConfig.SocketMode)
+		if err != nil {
+			return fmt.Errorf("parse config: %w", err)
+		}
+		c.config.SocketMode = mode
 	}
 	
 	// Set defaults
@@ -83,8 +102,44 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 			KeyFile:    httpConfig.TLS.KeyFile,
 			MinVersion: httpConfig.TLS.MinVersion,
 		}
+
+		// Obtain and renew certificates through ACME
+		if acmeConfig := httpConfig.TLS.ACME; acmeConfig != nil && acmeConfig.Enabled {
+			manager := c.createACMEManager(acmeConfig)
+			tlsConfig.GetCertificate = manager.GetCertificate
+			c.config.Certificates = nil
+			c.config.ACME = &ACMEConfig{
+				HTTPPort:         acmeConfig.HTTPPort,
+				ChallengeHandler: manager.HTTPHandler(redirectToHTTPS(c.config.Port)),
+			}
+			if c.config.ACME.HTTPPort == 0 {
+				c.config.ACME.HTTPPort = 80
+			}
+		}
 	}
 	
+	// Serve HTTP/3 next to HTTP/1.1 and HTTP/2
+	if h3 := httpConfig.HTTP3; h3 != nil && h3.Enabled {
+		c.config.HTTP3 = &HTTP3Config{
+			Port:         h3.Port,
+			AltSvcMaxAge: time.Duration(h3.AltSvcMaxAge) * time.Second,
+		}
+		if c.config.HTTP3.Port == 0 {
+			c.config.HTTP3.Port = c.config.Port
+		}
+		if c.config.HTTP3.AltSvcMaxAge == 0 {
+			c.config.HTTP3.AltSvcMaxAge = 24 * time.Hour
+		}
+	}
+
+	if len(httpConfig.ErrorPages) > 0 {
+		pages, err := NewErrorPages(httpConfig.ErrorPages)
+		if err != nil {
+			return fmt.Errorf("load error pages: %w", err)
+		}
+		c.config.ErrorPages = pages
+	}
+
 	// Create adapter
 	c.adapter = New(c.config, c.handler)
 	if c.logger != nil {
@@ -101,9 +156,20 @@ func (c *Component) Validate() error {
 	}
 	
 	// Validate configuration
-	if c.config.Port <= 0 || c.config.Port > 65535 {
+	if c.config.UnixSocket == "" && (c.config.Port <= 0 || c.config.Port > 65535) {
 		return fmt.Errorf("invalid port number: %d", c.config.Port)
 	}
+	if c.config.HTTP3 != nil {
+		if c.config.UnixSocket != "" {
+			return fmt.Errorf("HTTP/3 cannot be served with a Unix socket listener")
+		}
+		if c.config.TLSConfig == nil {
+			return fmt.Errorf("HTTP/3 requires TLS to be enabled")
+		}
+		if c.config.HTTP3.Port <= 0 || c.config.HTTP3.Port > 65535 {
+			return fmt.Errorf("invalid HTTP/3 port number: %d", c.config.HTTP3.Port)
+		}
+	}
 	
 	return nil
 }
@@ -123,13 +189,15 @@ func (c *Component) createTLSConfig(cfg *config.TLS) (*tls.Config, error) {
 		MaxVersion: tls.VersionTLS13,
 	}
 	
-	// Load certificate and key
-	if cfg.CertFile != "" && cfg.KeyFile != "" {
-		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	// Load certificates, selected by SNI server name and reloaded when they
+	// are rotated on disk
+	if (cfg.CertFile != "" && cfg.KeyFile != "") || len(cfg.Certificates) > 0 {
+		store, err := tlsutil.NewCertStore(cfg.CertFile, cfg.KeyFile, sniCertificates(cfg.Certificates), c.logger)
 		if err != nil {
-			return nil, fmt.Errorf("load certificate: %w", err)
+			return nil, err
 		}
-		tlsConfig.Certificates = []tls.Certificate{cert}
+		tlsConfig.GetCertificate = store.GetCertificate
+		c.config.Certificates = store
 	}
 	
 	// Configure cipher suites if specified
@@ -147,5 +215,54 @@ func (c *Component) createTLSConfig(cfg *config.TLS) (*tls.Config, error) {
 	return tlsConfig, nil
 }
 
+// sniCertificates converts the certificates served by host
+func sniCertificates(certs []config.Certificate) []tlsutil.Certificate {
+	converted := make([]tlsutil.Certificate, 0, len(certs))
+	for _, cert := range certs {
+		converted = append(converted, tlsutil.Certificate{
+			Hosts:    cert.Hosts,
+			CertFile: cert.CertFile,
+			KeyFile:  cert.KeyFile,
+		})
+	}
+	return converted
+}
+
+// createACMEManager creates the manager obtaining certificates for the
+// configured domains. Certificates are renewed in the background before
+// they expire.
+func (c *Component) createACMEManager(cfg *config.ACME) *autocert.Manager {
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Email:      cfg.Email,
+		HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+	}
+	if cfg.CacheDir != "" {
+		manager.Cache = autocert.DirCache(cfg.CacheDir)
+	}
+	if cfg.DirectoryURL != "" {
+		manager.Client = &acme.Client{DirectoryURL: cfg.DirectoryURL}
+	}
+	return manager
+}
+
+// redirectToHTTPS redirects GET and HEAD requests to the TLS port
+func redirectToHTTPS(port int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Use HTTPS", http.StatusBadRequest)
+			return
+		}
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(port))
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusFound)
+	})
+}
+
 // Ensure Component implements factory.Component
 var _ factory.Component = (*Component)(nil)
\ No newline at end of file
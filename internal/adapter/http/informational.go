@@ -0,0 +1,87 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// informationalRelay writes the interim (1xx) responses of a backend to the
+// client. Writes are serialized with the 100 Continue the server sends on
+// the first read of a body sent with "Expect: 100-continue", and stop once
+// the handler returned, as the final response is then being written.
+type informationalRelay struct {
+	w    http.ResponseWriter
+	mu   sync.Mutex
+	done bool
+}
+
+// newInformationalRelay creates the relay for a request, wrapping its body
+// when the server may answer its first read with 100 Continue
+func newInformationalRelay(w http.ResponseWriter, r *http.Request) *informationalRelay {
+	relay := &informationalRelay{w: w}
+	// HTTP/1.0 clients do not expect interim responses
+	if !r.ProtoAtLeast(1, 1) {
+		relay.done = true
+		return relay
+	}
+	if r.Body != nil && r.Body != http.NoBody && strings.EqualFold(r.Header.Get("Expect"), "100-continue") {
+		r.Body = &continueBody{ReadCloser: r.Body, relay: relay}
+	}
+	return relay
+}
+
+// write sends an interim response with header. Headers of the final
+// response set so far are restored afterwards.
+func (r *informationalRelay) write(code int, header map[string][]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		return
+	}
+
+	h := r.w.Header()
+	saved := make(map[string][]string, len(header))
+	for key, values := range header {
+		key = http.CanonicalHeaderKey(key)
+		if current, ok := h[key]; ok {
+			saved[key] = current
+		}
+		h[key] = values
+	}
+	r.w.WriteHeader(code)
+	for key := range header {
+		key = http.CanonicalHeaderKey(key)
+		if current, ok := saved[key]; ok {
+			h[key] = current
+		} else {
+			delete(h, key)
+		}
+	}
+}
+
+// finish stops relaying
+func (r *informationalRelay) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.done = true
+}
+
+// continueBody holds the relay during the first read of a request body,
+// when the server writes its 100 Continue
+type continueBody struct {
+	io.ReadCloser
+	relay *informationalRelay
+	read  bool
+}
+
+func (b *continueBody) Read(p []byte) (int, error) {
+	if b.read {
+		return b.ReadCloser.Read(p)
+	}
+	b.read = true
+	b.relay.mu.Lock()
+	defer b.relay.mu.Unlock()
+	return b.ReadCloser.Read(p)
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	httpConnector "gateway/internal/connector/http"
+	"gateway/internal/core"
+)
+
+// countingBody counts the bytes read from it
+type countingBody struct {
+	io.Reader
+	read atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	b.read.Add(int64(n))
+	return n, err
+}
+
+func TestAdapterRelaysInformationalResponses(t *testing.T) {
+	const size = 4 << 20
+
+	// The backend sends Early Hints, then reads uploads to /upload and
+	// rejects others without reading them
+	var received atomic.Int64
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", "</style.css>; rel=preload")
+		w.WriteHeader(http.StatusEarlyHints)
+		w.Header().Del("Link")
+		if r.URL.Path != "/upload" {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		n, _ := io.Copy(io.Discard, r.Body)
+		received.Store(n)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+	backendURL, _ := url.Parse(backend.URL)
+	port, _ := strconv.Atoi(backendURL.Port())
+
+	backendClient := &http.Client{Transport: &http.Transport{ExpectContinueTimeout: 5 * time.Second}}
+	connector := httpConnector.NewHTTPConnector(backendClient, 10*time.Second)
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		return connector.Forward(ctx, req, &core.RouteResult{
+			Instance: &core.ServiceInstance{ID: "backend", Address: backendURL.Hostname(), Port: port},
+		})
+	}
+	frontend := httptest.NewServer(New(Config{Host: "127.0.0.1"}, handler))
+	defer frontend.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		wantStatus   int
+		wantReceived int64
+		wantInterim  []int
+	}{
+		{"accepted", "/upload", http.StatusCreated, size, []int{http.StatusEarlyHints, http.StatusContinue}},
+		{"rejected", "/reject", http.StatusRequestEntityTooLarge, 0, []int{http.StatusEarlyHints}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received.Store(0)
+
+			var mu sync.Mutex
+			var interim []int
+			var link string
+			ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+				Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+					mu.Lock()
+					defer mu.Unlock()
+					interim = append(interim, code)
+					if code == http.StatusEarlyHints {
+						link = header.Get("Link")
+					}
+					return nil
+				},
+			})
+
+			body := &countingBody{Reader: strings.NewReader(strings.Repeat("x", size))}
+			req, _ := http.NewRequestWithContext(ctx, "POST", frontend.URL+tt.path, body)
+			req.ContentLength = size
+			req.Header.Set("Expect", "100-continue")
+
+			client := &http.Client{Transport: &http.Transport{ExpectContinueTimeout: 5 * time.Second}}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if resp.Header.Get("Link") != "" {
+				t.Error("Expected the Early Hints headers not to be in the final response")
+			}
+			if received.Load() != tt.wantReceived {
+				t.Errorf("Backend received %d bytes, want %d", received.Load(), tt.wantReceived)
+			}
+			if tt.wantReceived == 0 && body.read.Load() != 0 {
+				t.Errorf("Expected the client not to send the body, %d bytes were read", body.read.Load())
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(interim) != len(tt.wantInterim) || link == "" {
+				t.Fatalf("Client got interim responses %v with Link %q, want %v", interim, link, tt.wantInterim)
+			}
+			for i, code := range tt.wantInterim {
+				if interim[i] != code {
+					t.Errorf("Client got interim responses %v, want %v", interim, tt.wantInterim)
+				}
+			}
+		})
+	}
+}
@@ -4,19 +4,42 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"gateway/internal/core"
+	"gateway/internal/middleware/auth/token"
 	"gateway/pkg/request"
 )
 
 // Config represents SSE adapter configuration
 type Config struct {
-	Enabled          bool `yaml:"enabled"`
-	WriteTimeout     int  `yaml:"writeTimeout"`     // Write timeout in seconds
-	KeepaliveTimeout int  `yaml:"keepaliveTimeout"` // Keepalive interval in seconds
+	Enabled          bool   `yaml:"enabled"`
+	WriteTimeout     int    `yaml:"writeTimeout"`     // Write timeout in seconds
+	KeepaliveTimeout int    `yaml:"keepaliveTimeout"` // Keepalive interval in seconds
+	HeartbeatFormat  string `yaml:"heartbeatFormat"`  // comment (default) or event
+	HeartbeatComment string `yaml:"heartbeatComment"` // Text of comment heartbeats (default: keepalive)
+	HeartbeatEvent   string `yaml:"heartbeatEvent"`   // Event type of event heartbeats (default: ping)
+	RetryInterval    int    `yaml:"retryInterval"`    // Client reconnect interval in milliseconds, sent when positive
+	FlushMode        string `yaml:"flushMode"`        // event (default) or manual
 }
 
+// Heartbeat formats
+const (
+	HeartbeatComment = "comment" // ": keepalive" comment lines
+	HeartbeatEvent   = "event"   // Events clients can listen for, such as "event: ping"
+)
+
+// Flush modes
+const (
+	// FlushEvent flushes every event to the client as it is written
+	FlushEvent = "event"
+	// FlushManual buffers events until the handler calls Flush, so bursts
+	// of events are sent together
+	FlushManual = "manual"
+)
+
 // TokenValidator interface for JWT token validation
 type TokenValidator interface {
 	ValidateConnection(ctx context.Context, connectionID string, token string, onExpired func()) error
@@ -29,6 +52,10 @@ func DefaultConfig() *Config {
 		Enabled:          false,
 		WriteTimeout:     60,
 		KeepaliveTimeout: 30,
+		HeartbeatFormat:  HeartbeatComment,
+		HeartbeatComment: "keepalive",
+		HeartbeatEvent:   "ping",
+		FlushMode:        FlushEvent,
 	}
 }
 
@@ -38,7 +65,14 @@ type Adapter struct {
 	handler        core.Handler
 	logger         *slog.Logger
 	tokenValidator TokenValidator
+	tokens         *token.Extractor
 	metrics        *SSEMetrics
+
+	// Open streams, ended by Drain on shutdown
+	streamsMu sync.Mutex
+	streams   map[*writer]context.CancelFunc
+	draining  bool
+	drained   chan struct{}
 }
 
 // NewAdapter creates a new SSE adapter
@@ -51,6 +85,8 @@ func NewAdapter(config *Config, handler core.Handler, logger *slog.Logger) *Adap
 		config:  config,
 		handler: handler,
 		logger:  logger,
+		tokens:  token.NewExtractor(nil),
+		streams: make(map[*writer]context.CancelFunc),
 	}
 }
 
@@ -60,6 +96,13 @@ func (a *Adapter) WithTokenValidator(validator TokenValidator) *Adapter {
 	return a
 }
 
+// WithTokenExtractor sets where the token validator reads tokens from
+// (default: Authorization bearer header)
+func (a *Adapter) WithTokenExtractor(tokens *token.Extractor) *Adapter {
+	a.tokens = tokens
+	return a
+}
+
 // WithMetrics sets the metrics for the adapter
 func (a *Adapter) WithMetrics(metrics *SSEMetrics) *Adapter {
 	a.metrics = metrics
@@ -83,6 +126,7 @@ func (a *Adapter) HandleSSE(w http.ResponseWriter, r *http.Request) {
 
 	// Create SSE writer with disconnect detection
 	sseWriter := newWriter(w, r.Context(), a.metrics)
+	sseWriter.autoFlush = a.config.FlushMode != FlushManual
 	defer func() {
 		if err := sseWriter.Close(); err != nil {
 			a.logger.Debug("SSE writer close error", "error", err)
@@ -110,6 +154,13 @@ func (a *Adapter) HandleSSE(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
 
+	// Register the stream so it can be drained on shutdown
+	if !a.trackStream(sseWriter, cancel) {
+		http.Error(w, "Server shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer a.untrackStream(sseWriter)
+
 	// Start keepalive goroutine
 	keepaliveCtx, cancelKeepalive := context.WithCancel(ctx)
 	defer cancelKeepalive()
@@ -121,10 +172,7 @@ func (a *Adapter) HandleSSE(w http.ResponseWriter, r *http.Request) {
 
 	// Start JWT validation if configured
 	if a.tokenValidator != nil {
-		// Extract token from Authorization header
-		authHeader := r.Header.Get("Authorization")
-		if authHeader != "" && len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			token := authHeader[7:]
+		if token, ok := a.tokens.FromHTTP(r); ok {
 			connectionID := r.Header.Get("X-Request-ID")
 			if connectionID == "" {
 				connectionID = r.RemoteAddr
@@ -164,6 +212,13 @@ func (a *Adapter) HandleSSE(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Tell the client how long to wait before reconnecting
+	if a.config.RetryInterval > 0 {
+		if err := sseWriter.WriteEvent(&core.SSEEvent{Retry: a.config.RetryInterval}); err == nil {
+			_ = sseWriter.Flush()
+		}
+	}
+
 	if a.config.KeepaliveTimeout > 0 {
 		go a.keepalive(keepaliveCtx, sseWriter)
 	}
@@ -195,7 +250,66 @@ func (a *Adapter) HandleSSE(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// keepalive sends periodic comments to keep connection alive
+// Drain ends open streams for shutdown. Each stream has its buffered events
+// flushed before it is closed, so clients can reconnect elsewhere without
+// losing data; new streams are refused. Drain returns once all stream
+// handlers have finished or ctx expires.
+func (a *Adapter) Drain(ctx context.Context) error {
+	a.streamsMu.Lock()
+	a.draining = true
+	if len(a.streams) == 0 {
+		a.streamsMu.Unlock()
+		return nil
+	}
+	a.drained = make(chan struct{})
+	drained := a.drained
+	streams := make(map[*writer]context.CancelFunc, len(a.streams))
+	for w, cancel := range a.streams {
+		streams[w] = cancel
+	}
+	a.streamsMu.Unlock()
+
+	a.logger.Info("Draining SSE streams", "streams", len(streams))
+	for w, cancel := range streams {
+		if err := w.Close(); err != nil {
+			a.logger.Debug("SSE writer close error", "error", err)
+		}
+		cancel()
+	}
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// trackStream registers an open stream, refusing it while draining
+func (a *Adapter) trackStream(w *writer, cancel context.CancelFunc) bool {
+	a.streamsMu.Lock()
+	defer a.streamsMu.Unlock()
+
+	if a.draining {
+		return false
+	}
+	a.streams[w] = cancel
+	return true
+}
+
+// untrackStream removes a finished stream
+func (a *Adapter) untrackStream(w *writer) {
+	a.streamsMu.Lock()
+	defer a.streamsMu.Unlock()
+
+	delete(a.streams, w)
+	if a.draining && len(a.streams) == 0 && a.drained != nil {
+		close(a.drained)
+		a.drained = nil
+	}
+}
+
+// keepalive sends periodic heartbeats to keep connection alive
 func (a *Adapter) keepalive(ctx context.Context, writer core.SSEWriter) {
 	ticker := time.NewTicker(time.Duration(a.config.KeepaliveTimeout) * time.Second)
 	defer ticker.Stop()
@@ -205,7 +319,7 @@ func (a *Adapter) keepalive(ctx context.Context, writer core.SSEWriter) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			if err := writer.WriteComment("keepalive"); err != nil {
+			if err := a.heartbeat(writer); err != nil {
 				a.logger.Debug("SSE keepalive failed, client likely disconnected", "error", err)
 				return
 			}
@@ -213,6 +327,33 @@ func (a *Adapter) keepalive(ctx context.Context, writer core.SSEWriter) {
 	}
 }
 
+// heartbeat writes a heartbeat in the configured format and flushes it.
+// Event heartbeats carry the Unix time as data, as events without data are
+// not dispatched to clients.
+func (a *Adapter) heartbeat(writer core.SSEWriter) error {
+	var err error
+	if a.config.HeartbeatFormat == HeartbeatEvent {
+		eventType := a.config.HeartbeatEvent
+		if eventType == "" {
+			eventType = "ping"
+		}
+		err = writer.WriteEvent(&core.SSEEvent{
+			Type: eventType,
+			Data: strconv.FormatInt(time.Now().Unix(), 10),
+		})
+	} else {
+		comment := a.config.HeartbeatComment
+		if comment == "" {
+			comment = "keepalive"
+		}
+		err = writer.WriteComment(comment)
+	}
+	if err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
 // monitorDisconnect monitors for client disconnection
 func (a *Adapter) monitorDisconnect(ctx context.Context, writer core.SSEWriter, remoteAddr string) {
 	// For now, just monitor context cancellation
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"gateway/internal/core"
+	"gateway/internal/middleware/auth/token"
 	"gateway/pkg/errors"
 	"gateway/pkg/request"
 	"log/slog"
@@ -296,6 +297,59 @@ func TestAdapter_HandleSSE_Keepalive(t *testing.T) {
 	}
 }
 
+func TestAdapter_HandleSSE_EventHeartbeat(t *testing.T) {
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		time.Sleep(1500 * time.Millisecond) // Wait to see at least one heartbeat
+		return nil, nil
+	}
+
+	adapter := NewAdapter(&Config{
+		KeepaliveTimeout: 1,
+		HeartbeatFormat:  HeartbeatEvent,
+		HeartbeatEvent:   "heartbeat",
+		RetryInterval:    3000,
+	}, handler, slog.Default())
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	adapter.HandleSSE(w, req)
+
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "retry: 3000\n\n") {
+		t.Errorf("Expected initial retry directive, got: %q", body)
+	}
+	if !strings.Contains(body, "event: heartbeat\ndata: ") {
+		t.Errorf("Expected heartbeat events in response, got: %q", body)
+	}
+	if strings.Contains(body, ": keepalive") {
+		t.Errorf("Expected no keepalive comments, got: %q", body)
+	}
+}
+
+func TestWriter_ManualFlush(t *testing.T) {
+	var buf strings.Builder
+	w := newWriter(&buf, context.Background(), nil)
+	w.autoFlush = false
+
+	if err := w.WriteEvent(&core.SSEEvent{Data: "one"}); err != nil {
+		t.Fatalf("WriteEvent failed: %v", err)
+	}
+	if err := w.WriteEvent(&core.SSEEvent{Data: "two"}); err != nil {
+		t.Fatalf("WriteEvent failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected events to be buffered until Flush, got %q", buf.String())
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if got := buf.String(); got != "data: one\n\ndata: two\n\n" {
+		t.Errorf("Expected both events after Flush, got %q", got)
+	}
+}
+
 func TestAdapter_HandleSSE_ContextCancellation(t *testing.T) {
 	logger := slog.Default()
 
@@ -563,6 +617,33 @@ func TestAdapter_HandleSSE_WithTokenValidator(t *testing.T) {
 		handler.called = false // Reset
 	})
 
+	t.Run("token from configured source", func(t *testing.T) {
+		adapter := NewAdapter(DefaultConfig(), handler.Handle, logger)
+
+		validator := newMockTokenValidator()
+		validator.validateFunc = func(ctx context.Context, connectionID string, token string, onExpired func()) error {
+			if token != "query-token" {
+				return fmt.Errorf("invalid token")
+			}
+			return nil
+		}
+		adapter.WithTokenValidator(validator)
+		adapter.WithTokenExtractor(token.NewExtractor([]token.Source{
+			{Type: token.SourceQuery, Name: "access_token"},
+		}))
+
+		req := httptest.NewRequest("GET", "/test?access_token=query-token", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Authorization", "Bearer ignored")
+
+		w := httptest.NewRecorder()
+		adapter.HandleSSE(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+	})
+
 	t.Run("no token with validator", func(t *testing.T) {
 		adapter := NewAdapter(DefaultConfig(), handler.Handle, logger)
 		validator := newMockTokenValidator()
@@ -683,3 +764,47 @@ type testResponseWriter struct {
 func (w *testResponseWriter) CloseNotify() <-chan bool {
 	return w.closeNotify
 }
+
+func TestAdapter_Drain(t *testing.T) {
+	logger := slog.Default()
+
+	handlerStarted := make(chan struct{})
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		sseReq := req.(*sseRequest)
+		_ = sseReq.writer.WriteEvent(&core.SSEEvent{Type: "message", Data: "pending"})
+		close(handlerStarted)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	adapter := NewAdapter(&Config{Enabled: true}, handler, logger)
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		adapter.HandleSSE(w, req)
+		close(done)
+	}()
+	<-handlerStarted
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := adapter.Drain(ctx); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	<-done
+
+	if !strings.Contains(w.Body.String(), "data: pending") {
+		t.Errorf("Expected pending event to be flushed, got %q", w.Body.String())
+	}
+
+	// New streams are refused while draining
+	w = httptest.NewRecorder()
+	adapter.HandleSSE(w, httptest.NewRequest("GET", "/test", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 after drain, got %d", w.Code)
+	}
+}
@@ -51,6 +51,11 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 		Enabled:          sseConfig.Enabled,
 		WriteTimeout:     sseConfig.WriteTimeout,
 		KeepaliveTimeout: sseConfig.KeepaliveTimeout,
+		HeartbeatFormat:  sseConfig.HeartbeatFormat,
+		HeartbeatComment: sseConfig.HeartbeatComment,
+		HeartbeatEvent:   sseConfig.HeartbeatEvent,
+		RetryInterval:    sseConfig.RetryInterval,
+		FlushMode:        sseConfig.FlushMode,
 	}
 	
 	// Set defaults
@@ -60,6 +65,18 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 	if c.config.KeepaliveTimeout == 0 {
 		c.config.KeepaliveTimeout = 30
 	}
+	if c.config.HeartbeatFormat == "" {
+		c.config.HeartbeatFormat = HeartbeatComment
+	}
+	if c.config.HeartbeatComment == "" {
+		c.config.HeartbeatComment = "keepalive"
+	}
+	if c.config.HeartbeatEvent == "" {
+		c.config.HeartbeatEvent = "ping"
+	}
+	if c.config.FlushMode == "" {
+		c.config.FlushMode = FlushEvent
+	}
 	
 	// Create adapter
 	c.adapter = NewAdapter(c.config, c.handler, c.logger)
@@ -23,17 +23,21 @@ type writer struct {
 	mu           sync.RWMutex
 	ctx          context.Context
 	metrics      *SSEMetrics
+	// Flush every event and comment as it is written; otherwise they are
+	// buffered until Flush
+	autoFlush bool
 }
 
 // newWriter creates a new SSE writer with disconnect detection
 func newWriter(w io.Writer, ctx context.Context, metrics *SSEMetrics) *writer {
 	flusher, _ := w.(http.Flusher)
 	return &writer{
-		w:       w,
-		flusher: flusher,
-		buf:     bufio.NewWriter(w),
-		ctx:     ctx,
-		metrics: metrics,
+		w:         w,
+		flusher:   flusher,
+		buf:       bufio.NewWriter(w),
+		ctx:       ctx,
+		metrics:   metrics,
+		autoFlush: true,
 	}
 }
 
@@ -104,8 +108,10 @@ func (w *writer) WriteEvent(event *core.SSEEvent) error {
 	}
 
 	// Flush and track event
-	if err := w.Flush(); err != nil {
-		return err
+	if w.autoFlush {
+		if err := w.flush(); err != nil {
+			return err
+		}
 	}
 
 	// Track sent event
@@ -138,11 +144,26 @@ func (w *writer) WriteComment(comment string) error {
 		return errors.NewError(errors.ErrorTypeInternal, "failed to write SSE comment").WithCause(err)
 	}
 
-	return w.Flush()
+	if !w.autoFlush {
+		return nil
+	}
+	return w.flush()
 }
 
-// Flush flushes any buffered data
+// Flush sends buffered events and comments to the client. With manual
+// flushing, handlers call it between events to force them out.
 func (w *writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed || w.disconnected {
+		return nil
+	}
+	return w.flush()
+}
+
+// flush flushes any buffered data; w.mu must be held
+func (w *writer) flush() error {
 	if err := w.buf.Flush(); err != nil {
 		w.handleWriteError(err)
 		return errors.NewError(errors.ErrorTypeInternal, "failed to flush SSE buffer").WithCause(err)
@@ -165,7 +186,7 @@ func (w *writer) Close() error {
 	}
 
 	w.closed = true
-	return w.Flush()
+	return w.flush()
 }
 
 // IsDisconnected returns true if the client has disconnected
@@ -188,9 +209,7 @@ func (w *writer) handleWriteError(err error) {
 	}
 }
 
-// markDisconnected marks the writer as disconnected
+// markDisconnected marks the writer as disconnected; w.mu must be held
 func (w *writer) markDisconnected() {
-	w.mu.Lock()
-	defer w.mu.Unlock()
 	w.disconnected = true
 }
@@ -0,0 +1,427 @@
+package tcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+)
+
+// DefaultConfig returns default TCP configuration
+func DefaultConfig() *Config {
+	return &Config{
+		Host:           "0.0.0.0",
+		Port:           9000,
+		IdleTimeout:    5 * time.Minute,
+		DialTimeout:    10 * time.Second,
+		MaxConnections: 1024,
+	}
+}
+
+// ListenFunc binds the listener the adapter serves on
+type ListenFunc func(network, address string) (net.Listener, error)
+
+// Adapter proxies raw TCP connections to instances of a single service.
+// There is nothing to route on, so every connection goes to an instance of
+// the configured service chosen by the load balancer.
+type Adapter struct {
+	config        *Config
+	logger        *slog.Logger
+	mu            sync.Mutex
+	running       bool
+	listener      net.Listener
+	served        chan struct{} // closed when the accept loop exits
+	connSemaphore chan struct{}
+	metrics       *TCPMetrics
+	listen        ListenFunc
+	dial          func(ctx context.Context, network, address string) (net.Conn, error)
+
+	// registry and balancer select the instance for new connections, see Swap
+	chainMu  sync.RWMutex
+	registry core.ServiceRegistry
+	balancer core.LoadBalancer
+
+	// Proxied connections are tracked to be drained on shutdown
+	connsMu sync.Mutex
+	conns   map[*proxy]struct{}
+	wg      sync.WaitGroup
+}
+
+// NewAdapter creates a new TCP adapter proxying to instances of
+// config.ServiceName in registry selected by balancer
+func NewAdapter(config *Config, registry core.ServiceRegistry, balancer core.LoadBalancer, logger *slog.Logger) *Adapter {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	// Initialize connection semaphore
+	maxConns := config.MaxConnections
+	if maxConns <= 0 {
+		maxConns = 1024 // Default max connections
+	}
+
+	dialer := &net.Dialer{Timeout: config.DialTimeout}
+	return &Adapter{
+		config:        config,
+		logger:        logger,
+		connSemaphore: make(chan struct{}, maxConns),
+		listen:        net.Listen,
+		dial:          dialer.DialContext,
+		registry:      registry,
+		balancer:      balancer,
+		conns:         make(map[*proxy]struct{}),
+	}
+}
+
+// WithMetrics sets the metrics for the adapter
+func (a *Adapter) WithMetrics(metrics *TCPMetrics) *Adapter {
+	a.metrics = metrics
+	return a
+}
+
+// WithListenFunc sets how the adapter binds its listener
+func (a *Adapter) WithListenFunc(listen ListenFunc) *Adapter {
+	a.listen = listen
+	return a
+}
+
+// Swap selects instances for new connections with the registry and balancer
+// of next. Open connections are unaffected.
+func (a *Adapter) Swap(next *Adapter) {
+	registry, balancer := next.chain()
+
+	a.chainMu.Lock()
+	defer a.chainMu.Unlock()
+	a.registry = registry
+	a.balancer = balancer
+}
+
+// chain returns the registry and balancer for new connections
+func (a *Adapter) chain() (core.ServiceRegistry, core.LoadBalancer) {
+	a.chainMu.RLock()
+	defer a.chainMu.RUnlock()
+	return a.registry, a.balancer
+}
+
+// Start starts the TCP adapter
+func (a *Adapter) Start(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.running {
+		return gwerrors.NewError(gwerrors.ErrorTypeInternal, "TCP adapter already running")
+	}
+
+	addr := net.JoinHostPort(a.config.Host, strconv.Itoa(a.config.Port))
+	listener, err := a.listen("tcp", addr)
+	if err != nil {
+		return gwerrors.NewError(gwerrors.ErrorTypeInternal, fmt.Sprintf("failed to bind TCP listener to %s", addr)).
+			WithCause(err)
+	}
+	a.listener = listener
+	a.served = make(chan struct{})
+	a.running = true
+
+	a.logger.Info("TCP adapter listening",
+		"address", listener.Addr().String(),
+		"service", a.config.ServiceName,
+	)
+
+	go a.serve(listener, a.served)
+
+	// Wait for context cancellation
+	go func() {
+		<-ctx.Done()
+		if err := a.Stop(context.Background()); err != nil {
+			a.logger.Error("Error stopping TCP adapter", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Addr returns the address the adapter listens on, or nil when stopped
+func (a *Adapter) Addr() net.Addr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.listener == nil {
+		return nil
+	}
+	return a.listener.Addr()
+}
+
+// Stop stops the TCP adapter. The listener is closed first, then open
+// connections are given until ctx expires to finish before they are closed.
+func (a *Adapter) Stop(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.running {
+		return nil
+	}
+
+	a.logger.Info("Stopping TCP adapter")
+
+	err := a.listener.Close()
+	<-a.served
+	a.listener = nil
+	a.running = false
+
+	a.connsMu.Lock()
+	remaining := len(a.conns)
+	a.connsMu.Unlock()
+	if remaining > 0 {
+		a.logger.Info("Draining TCP connections", "connections", remaining)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		a.connsMu.Lock()
+		a.logger.Warn("TCP drain deadline expired, closing connections", "connections", len(a.conns))
+		for p := range a.conns {
+			p.close()
+		}
+		a.connsMu.Unlock()
+		<-done
+	}
+
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		return gwerrors.NewError(gwerrors.ErrorTypeInternal, "failed to close TCP listener").WithCause(err)
+	}
+	return nil
+}
+
+// Type returns the adapter type
+func (a *Adapter) Type() string {
+	return "tcp"
+}
+
+// serve accepts connections until the listener is closed
+func (a *Adapter) serve(listener net.Listener, served chan struct{}) {
+	defer close(served)
+	for {
+		client, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			a.logger.Error("TCP accept error", "error", err)
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			a.handle(client)
+		}()
+	}
+}
+
+// handle proxies a client connection to a selected instance
+func (a *Adapter) handle(client net.Conn) {
+	defer client.Close()
+
+	// Check connection limit
+	select {
+	case a.connSemaphore <- struct{}{}:
+		// Acquired a slot, proceed
+		defer func() { <-a.connSemaphore }() // Release the slot when handler exits
+	default:
+		// No slots available
+		a.logger.Warn("Max TCP connections reached, rejecting new connection",
+			"remote", client.RemoteAddr().String(),
+			"maxConnections", a.config.MaxConnections,
+		)
+		a.countConnection("rejected")
+		return
+	}
+
+	registry, balancer := a.chain()
+	instance, err := a.selectInstance(registry, balancer)
+	if err != nil {
+		a.logger.Error("No TCP backend available",
+			"service", a.config.ServiceName,
+			"remote", client.RemoteAddr().String(),
+			"error", err,
+		)
+		a.countConnection("failed")
+		return
+	}
+	// Least-connections balancers count connections until they close
+	if tracker, ok := balancer.(interface{ DecrementConnections(string) }); ok {
+		defer tracker.DecrementConnections(instance.ID)
+	}
+
+	backendAddr := net.JoinHostPort(instance.Address, strconv.Itoa(instance.Port))
+	backend, err := a.dial(context.Background(), "tcp", backendAddr)
+	if err != nil {
+		a.logger.Error("Failed to connect to TCP backend",
+			"instance", instance.ID,
+			"address", backendAddr,
+			"error", err,
+		)
+		a.countConnection("failed")
+		return
+	}
+	defer backend.Close()
+
+	p := &proxy{client: client, backend: backend, idleTimeout: a.config.IdleTimeout}
+	a.connsMu.Lock()
+	a.conns[p] = struct{}{}
+	a.connsMu.Unlock()
+	defer func() {
+		a.connsMu.Lock()
+		delete(a.conns, p)
+		a.connsMu.Unlock()
+	}()
+
+	a.countConnection("established")
+	if a.metrics != nil && a.metrics.Connections != nil {
+		a.metrics.Connections.Inc()
+		defer a.metrics.Connections.Dec()
+	}
+
+	a.logger.Debug("TCP connection established",
+		"remote", client.RemoteAddr().String(),
+		"instance", instance.ID,
+	)
+
+	sent, received := p.run()
+	if a.metrics != nil {
+		if a.metrics.BytesSent != nil {
+			a.metrics.BytesSent.Add(float64(sent))
+		}
+		if a.metrics.BytesReceived != nil {
+			a.metrics.BytesReceived.Add(float64(received))
+		}
+	}
+
+	a.logger.Debug("TCP connection closed",
+		"remote", client.RemoteAddr().String(),
+		"instance", instance.ID,
+		"bytesSent", sent,
+		"bytesReceived", received,
+	)
+}
+
+// selectInstance selects an instance of the configured service
+func (a *Adapter) selectInstance(registry core.ServiceRegistry, balancer core.LoadBalancer) (*core.ServiceInstance, error) {
+	instances, err := registry.GetService(a.config.ServiceName)
+	if err != nil {
+		return nil, gwerrors.NewError(gwerrors.ErrorTypeNotFound, "service not found").
+			WithDetail("service", a.config.ServiceName).
+			WithCause(err)
+	}
+	if len(instances) == 0 {
+		return nil, gwerrors.NewError(gwerrors.ErrorTypeUnavailable, "no instances available").
+			WithDetail("service", a.config.ServiceName)
+	}
+	return balancer.Select(instances)
+}
+
+// countConnection counts a connection attempt by outcome
+func (a *Adapter) countConnection(status string) {
+	if a.metrics != nil && a.metrics.ConnectionsTotal != nil {
+		a.metrics.ConnectionsTotal.WithLabelValues(a.config.ServiceName, status).Inc()
+	}
+}
+
+// proxy copies bytes between a client and backend connection
+type proxy struct {
+	client      net.Conn
+	backend     net.Conn
+	idleTimeout time.Duration
+	// lastActive is the time of the last read in either direction, in
+	// nanoseconds, so a busy direction keeps an idle one open
+	lastActive atomic.Int64
+	closeOnce  sync.Once
+}
+
+// run copies in both directions until both are done, returning the bytes
+// sent to the client and received from it
+func (p *proxy) run() (sent, received int64) {
+	p.lastActive.Store(time.Now().UnixNano())
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		received = p.copy(p.backend, p.client)
+	}()
+	go func() {
+		defer wg.Done()
+		sent = p.copy(p.client, p.backend)
+	}()
+	wg.Wait()
+	return sent, received
+}
+
+// copy copies src to dst. When src ends, the write side of dst is closed so
+// the peer sees the end of stream; on errors both connections are closed.
+func (p *proxy) copy(dst, src net.Conn) int64 {
+	buf := make([]byte, 32*1024)
+	var total int64
+	for {
+		if p.idleTimeout > 0 {
+			src.SetReadDeadline(time.Now().Add(p.idleTimeout))
+		}
+		n, err := src.Read(buf)
+		if n > 0 {
+			p.lastActive.Store(time.Now().UnixNano())
+			written, writeErr := dst.Write(buf[:n])
+			total += int64(written)
+			if writeErr != nil {
+				p.close()
+				return total
+			}
+		}
+		if err == nil {
+			continue
+		}
+
+		// The other direction may still be active
+		if errors.Is(err, os.ErrDeadlineExceeded) && p.active() {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			if cw, ok := dst.(interface{ CloseWrite() error }); ok {
+				cw.CloseWrite()
+				return total
+			}
+		}
+		p.close()
+		return total
+	}
+}
+
+// active reports whether either direction read within the idle timeout
+func (p *proxy) active() bool {
+	return time.Since(time.Unix(0, p.lastActive.Load())) < p.idleTimeout
+}
+
+// close closes both connections
+func (p *proxy) close() {
+	p.closeOnce.Do(func() {
+		p.client.Close()
+		p.backend.Close()
+	})
+}
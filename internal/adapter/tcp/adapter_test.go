@@ -0,0 +1,236 @@
+package tcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/router"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type mockRegistry struct {
+	services map[string][]core.ServiceInstance
+}
+
+func (m *mockRegistry) GetService(name string) ([]core.ServiceInstance, error) {
+	instances, ok := m.services[name]
+	if !ok {
+		return nil, fmt.Errorf("service not found: %s", name)
+	}
+	return instances, nil
+}
+
+// startEcho starts a backend echoing everything it reads until the client
+// closes its write side
+func startEcho(t *testing.T) *net.TCPAddr {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+				conn.(*net.TCPConn).CloseWrite()
+			}()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr)
+}
+
+// startAdapter starts an adapter proxying to an echo backend
+func startAdapter(t *testing.T, config *Config) (*Adapter, *TCPMetrics) {
+	t.Helper()
+
+	backend := startEcho(t)
+	registry := &mockRegistry{services: map[string][]core.ServiceInstance{
+		"echo": {{ID: "echo-1", Name: "echo", Address: "127.0.0.1", Port: backend.Port, Healthy: true}},
+	}}
+
+	config.Host = "127.0.0.1"
+	config.Port = 0
+	if config.ServiceName == "" {
+		config.ServiceName = "echo"
+	}
+
+	metrics := NewTCPMetrics(
+		prometheus.NewGauge(prometheus.GaugeOpts{Name: "connections"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{Name: "connections_total"}, []string{"service", "status"}),
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "bytes_sent"}),
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "bytes_received"}),
+	)
+	adapter := NewAdapter(config, registry, router.NewRoundRobinBalancer(), slog.Default()).WithMetrics(metrics)
+	if err := adapter.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start adapter: %v", err)
+	}
+	t.Cleanup(func() { adapter.Stop(context.Background()) })
+	return adapter, metrics
+}
+
+func dial(t *testing.T, adapter *Adapter) *net.TCPConn {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", adapter.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial adapter: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return conn.(*net.TCPConn)
+}
+
+func TestDefaultConfig(t *testing.T) {
+	config := DefaultConfig()
+
+	if config.IdleTimeout != 5*time.Minute {
+		t.Errorf("Expected idle timeout 5m, got %v", config.IdleTimeout)
+	}
+	if config.MaxConnections != 1024 {
+		t.Errorf("Expected max connections 1024, got %d", config.MaxConnections)
+	}
+}
+
+func TestAdapter_Type(t *testing.T) {
+	adapter := NewAdapter(nil, nil, nil, slog.Default())
+	if adapter.Type() != "tcp" {
+		t.Errorf("Expected type tcp, got %s", adapter.Type())
+	}
+}
+
+func TestAdapter_Proxy(t *testing.T) {
+	adapter, metrics := startAdapter(t, &Config{})
+
+	conn := dial(t, adapter)
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	// The backend sees the end of stream and finishes its echo
+	conn.CloseWrite()
+
+	data, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("Expected echo of hello, got %q", data)
+	}
+
+	// Stop waits for the connection to be accounted for
+	if err := adapter.Stop(context.Background()); err != nil {
+		t.Fatalf("Failed to stop adapter: %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.ConnectionsTotal.WithLabelValues("echo", "established")); got != 1 {
+		t.Errorf("Expected 1 established connection, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.BytesReceived); got != 5 {
+		t.Errorf("Expected 5 bytes received, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.BytesSent); got != 5 {
+		t.Errorf("Expected 5 bytes sent, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.Connections); got != 0 {
+		t.Errorf("Expected no active connections, got %v", got)
+	}
+}
+
+func TestAdapter_Rejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		status string
+	}{
+		{
+			name:   "unknown service",
+			config: &Config{ServiceName: "missing"},
+			status: "failed",
+		},
+		{
+			name:   "max connections",
+			config: &Config{MaxConnections: 1},
+			status: "rejected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, metrics := startAdapter(t, tt.config)
+
+			if tt.config.MaxConnections == 1 {
+				// Hold the only slot with an established connection
+				held := dial(t, adapter)
+				held.Write([]byte("x"))
+				if _, err := io.ReadFull(held, make([]byte, 1)); err != nil {
+					t.Fatalf("Failed to establish connection: %v", err)
+				}
+			}
+
+			conn := dial(t, adapter)
+			if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+				t.Errorf("Expected connection to be closed, got %v", err)
+			}
+
+			deadline := time.Now().Add(5 * time.Second)
+			for testutil.ToFloat64(metrics.ConnectionsTotal.WithLabelValues("echo", tt.status))+
+				testutil.ToFloat64(metrics.ConnectionsTotal.WithLabelValues("missing", tt.status)) != 1 {
+				if time.Now().After(deadline) {
+					t.Fatalf("Expected a %s connection to be counted", tt.status)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestAdapter_IdleTimeout(t *testing.T) {
+	adapter, _ := startAdapter(t, &Config{IdleTimeout: 100 * time.Millisecond})
+
+	conn := dial(t, adapter)
+	start := time.Now()
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("Expected idle connection to be closed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected idle connection to be closed after the timeout, took %v", elapsed)
+	}
+}
+
+func TestAdapter_StopDrain(t *testing.T) {
+	adapter, _ := startAdapter(t, &Config{})
+
+	conn := dial(t, adapter)
+	conn.Write([]byte("x"))
+	if _, err := io.ReadFull(conn, make([]byte, 1)); err != nil {
+		t.Fatalf("Failed to establish connection: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := adapter.Stop(ctx); err != nil {
+		t.Fatalf("Failed to stop adapter: %v", err)
+	}
+
+	// The open connection is closed once the drain deadline expires
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Expected connection to be closed, got %v", err)
+	}
+	if _, err := net.Dial("tcp", conn.RemoteAddr().String()); err == nil {
+		t.Error("Expected listener to be closed")
+	}
+}
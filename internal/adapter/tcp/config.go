@@ -0,0 +1,13 @@
+package tcp
+
+import "time"
+
+// Config holds TCP adapter configuration
+type Config struct {
+	Host           string        `yaml:"host"`
+	Port           int           `yaml:"port"`
+	ServiceName    string        `yaml:"serviceName"`
+	IdleTimeout    time.Duration `yaml:"idleTimeout"`
+	DialTimeout    time.Duration `yaml:"dialTimeout"`
+	MaxConnections int           `yaml:"maxConnections"`
+}
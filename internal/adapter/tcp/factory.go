@@ -0,0 +1,105 @@
+package tcp
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+	"gateway/pkg/factory"
+)
+
+// ComponentName is the name used to register this component
+const ComponentName = "tcp-adapter"
+
+// Component implements factory.Component for TCP adapter
+type Component struct {
+	config   *Config
+	adapter  *Adapter
+	registry core.ServiceRegistry
+	balancer core.LoadBalancer
+	logger   *slog.Logger
+}
+
+// NewComponent creates a new TCP adapter component
+func NewComponent(registry core.ServiceRegistry, balancer core.LoadBalancer, logger *slog.Logger) factory.Component {
+	return &Component{
+		registry: registry,
+		balancer: balancer,
+		logger:   logger,
+	}
+}
+
+// Name returns the component name
+func (c *Component) Name() string {
+	return ComponentName
+}
+
+// Init initializes the component with configuration
+func (c *Component) Init(parser factory.ConfigParser) error {
+	// Parse the TCP configuration
+	var tcpConfig config.TCP
+	if err := parser(&tcpConfig); err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	// Skip if not enabled
+	if !tcpConfig.Enabled {
+		return fmt.Errorf("tcp adapter is not enabled")
+	}
+
+	// Convert to internal config
+	c.config = &Config{
+		Host:           tcpConfig.Host,
+		Port:           tcpConfig.Port,
+		ServiceName:    tcpConfig.ServiceName,
+		IdleTimeout:    time.Duration(tcpConfig.IdleTimeout) * time.Second,
+		DialTimeout:    time.Duration(tcpConfig.DialTimeout) * time.Second,
+		MaxConnections: tcpConfig.MaxConnections,
+	}
+
+	// Set defaults
+	if c.config.IdleTimeout == 0 {
+		c.config.IdleTimeout = 5 * time.Minute
+	}
+	if c.config.DialTimeout == 0 {
+		c.config.DialTimeout = 10 * time.Second
+	}
+	if c.config.MaxConnections == 0 {
+		c.config.MaxConnections = 1024
+	}
+
+	// Create adapter
+	c.adapter = NewAdapter(c.config, c.registry, c.balancer, c.logger)
+
+	return nil
+}
+
+// Validate validates the component state
+func (c *Component) Validate() error {
+	if c.adapter == nil {
+		return fmt.Errorf("TCP adapter not initialized")
+	}
+
+	// Validate configuration
+	if c.config.ServiceName == "" {
+		return fmt.Errorf("service name is required")
+	}
+	if c.registry == nil || c.balancer == nil {
+		return fmt.Errorf("registry and load balancer are required")
+	}
+
+	return nil
+}
+
+// Build returns the adapter
+func (c *Component) Build() *Adapter {
+	if c.adapter == nil {
+		panic("Component not initialized")
+	}
+	return c.adapter
+}
+
+// Ensure Component implements factory.Component
+var _ factory.Component = (*Component)(nil)
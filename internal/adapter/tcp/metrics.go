@@ -0,0 +1,24 @@
+package tcp
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TCPMetrics holds TCP-specific metrics
+type TCPMetrics struct {
+	Connections      prometheus.Gauge
+	ConnectionsTotal *prometheus.CounterVec
+	BytesSent        prometheus.Counter
+	BytesReceived    prometheus.Counter
+}
+
+// NewTCPMetrics creates new TCP metrics
+func NewTCPMetrics(connections prometheus.Gauge, connectionsTotal *prometheus.CounterVec,
+	bytesSent prometheus.Counter, bytesReceived prometheus.Counter) *TCPMetrics {
+	return &TCPMetrics{
+		Connections:      connections,
+		ConnectionsTotal: connectionsTotal,
+		BytesSent:        bytesSent,
+		BytesReceived:    bytesReceived,
+	}
+}
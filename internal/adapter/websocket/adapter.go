@@ -12,9 +12,12 @@ import (
 	"time"
 
 	"gateway/internal/core"
+	"gateway/internal/middleware/auth/token"
+	"gateway/pkg/clientip"
 	"gateway/pkg/errors"
 	"gateway/pkg/request"
 	"gateway/pkg/requestid"
+	tlsutil "gateway/pkg/tls"
 	"github.com/gorilla/websocket"
 )
 
@@ -49,18 +52,34 @@ type Adapter struct {
 	config         *Config
 	handler        core.Handler
 	upgrader       *websocket.Upgrader
+	subprotocol    func(r *http.Request) (string, bool)
 	server         *http.Server
 	logger         *slog.Logger
 	mu             sync.RWMutex
 	running        bool
 	listener       net.Listener
 	tokenValidator TokenValidator
+	tokens         *token.Extractor
 	serverCtx      context.Context
 	serverCancel   context.CancelFunc
 	connSemaphore  chan struct{}
 	metrics        *WebSocketMetrics
+	listen         ListenFunc
+	clientIP       *clientip.Resolver
+	chainMu        sync.RWMutex // guards handler, tokenValidator and tokens, see Swap
+	certStore      *tlsutil.CertStore
+
+	// Upgraded connections are hijacked from the HTTP server, so they are
+	// tracked here to be drained on shutdown
+	connsMu  sync.Mutex
+	conns    map[*conn]struct{}
+	draining bool
+	drained  chan struct{}
 }
 
+// ListenFunc binds the listener the adapter serves on
+type ListenFunc func(network, address string) (net.Listener, error)
+
 // NewAdapter creates a new WebSocket adapter
 func NewAdapter(config *Config, handler core.Handler, logger *slog.Logger) *Adapter {
 	if config == nil {
@@ -96,10 +115,14 @@ func NewAdapter(config *Config, handler core.Handler, logger *slog.Logger) *Adap
 		config:        config,
 		handler:       handler,
 		upgrader:      upgrader,
+		subprotocol:   makeSelectSubprotocol(config),
 		logger:        logger,
 		serverCtx:     ctx,
 		serverCancel:  cancel,
 		connSemaphore: make(chan struct{}, maxConns),
+		listen:        net.Listen,
+		tokens:        token.NewExtractor(nil),
+		conns:         make(map[*conn]struct{}),
 	}
 
 	return adapter
@@ -107,16 +130,60 @@ func NewAdapter(config *Config, handler core.Handler, logger *slog.Logger) *Adap
 
 // WithTokenValidator sets the token validator for the adapter
 func (a *Adapter) WithTokenValidator(validator TokenValidator) *Adapter {
+	a.chainMu.Lock()
+	defer a.chainMu.Unlock()
 	a.tokenValidator = validator
 	return a
 }
 
+// WithTokenExtractor sets where the token validator reads tokens from
+// (default: Authorization bearer header)
+func (a *Adapter) WithTokenExtractor(tokens *token.Extractor) *Adapter {
+	a.chainMu.Lock()
+	defer a.chainMu.Unlock()
+	a.tokens = tokens
+	return a
+}
+
+// Swap handles new connections with the handler and token validation of
+// next. Open connections are unaffected.
+func (a *Adapter) Swap(next *Adapter) {
+	handler, tokenValidator, tokens := next.chain()
+
+	a.chainMu.Lock()
+	defer a.chainMu.Unlock()
+	a.handler = handler
+	a.tokenValidator = tokenValidator
+	a.tokens = tokens
+}
+
+// chain returns the handler, token validator and token extractor for new
+// connections
+func (a *Adapter) chain() (core.Handler, TokenValidator, *token.Extractor) {
+	a.chainMu.RLock()
+	defer a.chainMu.RUnlock()
+	return a.handler, a.tokenValidator, a.tokens
+}
+
 // WithMetrics sets the metrics for the adapter
 func (a *Adapter) WithMetrics(metrics *WebSocketMetrics) *Adapter {
 	a.metrics = metrics
 	return a
 }
 
+// WithClientIP resolves the client IP of connections arriving through
+// trusted proxies, replacing their remote address before the upgrade
+func (a *Adapter) WithClientIP(resolver *clientip.Resolver) *Adapter {
+	a.clientIP = resolver
+	return a
+}
+
+// WithListenFunc sets how the adapter binds its listener
+func (a *Adapter) WithListenFunc(listen ListenFunc) *Adapter {
+	a.listen = listen
+	return a
+}
+
 // Start starts the WebSocket adapter
 func (a *Adapter) Start(ctx context.Context) error {
 	a.mu.Lock()
@@ -131,10 +198,14 @@ func (a *Adapter) Start(ctx context.Context) error {
 	// Create HTTP server
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", a.handleWebSocket)
+	handler := http.Handler(mux)
+	if a.clientIP != nil {
+		handler = a.clientIP.Handler(handler)
+	}
 
 	a.server = &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      handler,
 		ReadTimeout:  a.config.ReadTimeout,
 		WriteTimeout: a.config.WriteTimeout,
 		TLSConfig:    a.config.TLSConfig,
@@ -142,7 +213,7 @@ func (a *Adapter) Start(ctx context.Context) error {
 
 	// Setup listener - this will fail immediately if port is already in use
 	var err error
-	a.listener, err = net.Listen("tcp", addr)
+	a.listener, err = a.listen("tcp", addr)
 	if err != nil {
 		return errors.NewError(errors.ErrorTypeInternal, fmt.Sprintf("failed to bind WebSocket listener to %s", addr)).
 			WithCause(err)
@@ -154,15 +225,21 @@ func (a *Adapter) Start(ctx context.Context) error {
 		tlsConfig := a.config.TLSConfig
 		if tlsConfig == nil {
 			tlsConfig = &tls.Config{
-				MinVersion: tls.VersionTLS12,
+				MinVersion: tlsutil.ParseTLSVersion(a.config.TLS.MinVersion),
 			}
-			if a.config.TLS.CertFile != "" && a.config.TLS.KeyFile != "" {
-				cert, err := tls.LoadX509KeyPair(a.config.TLS.CertFile, a.config.TLS.KeyFile)
+			if (a.config.TLS.CertFile != "" && a.config.TLS.KeyFile != "") || len(a.config.TLS.Certificates) > 0 {
+				// Certificates are selected by SNI and those rotated on disk
+				// are served to new connections
+				store, err := tlsutil.NewCertStore(a.config.TLS.CertFile, a.config.TLS.KeyFile, a.config.TLS.Certificates, a.logger)
+				if err == nil {
+					err = store.Start()
+				}
 				if err != nil {
 					a.listener.Close()
 					return errors.NewError(errors.ErrorTypeInternal, "failed to load TLS certificates").WithCause(err)
 				}
-				tlsConfig.Certificates = []tls.Certificate{cert}
+				a.certStore = store
+				tlsConfig.GetCertificate = store.GetCertificate
 			}
 		}
 		a.listener = tls.NewListener(a.listener, tlsConfig)
@@ -171,6 +248,10 @@ func (a *Adapter) Start(ctx context.Context) error {
 		a.logger.Info("WebSocket adapter listening", "address", addr)
 	}
 
+	a.connsMu.Lock()
+	a.draining = false
+	a.connsMu.Unlock()
+
 	a.running = true
 
 	// Start server in goroutine
@@ -191,7 +272,10 @@ func (a *Adapter) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop stops the WebSocket adapter
+// Stop stops the WebSocket adapter. The listener is closed first, then open
+// connections are drained: each client is sent a going-away close frame and
+// given until ctx expires, or the close grace period elapses, to complete the
+// close handshake before its connection is closed.
 func (a *Adapter) Stop(ctx context.Context) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -202,19 +286,131 @@ func (a *Adapter) Stop(ctx context.Context) error {
 
 	a.logger.Info("Stopping WebSocket adapter")
 
-	// Cancel the server context to close all active connections
+	var shutdownErr error
+	if a.server != nil {
+		shutdownErr = a.server.Shutdown(ctx)
+	}
+
+	a.drain(ctx)
+
+	// Cancel the server context to release anything still bound to it
 	a.serverCancel()
 
-	if a.server != nil {
-		if err := a.server.Shutdown(ctx); err != nil {
-			return errors.NewError(errors.ErrorTypeInternal, "failed to shutdown WebSocket server").WithCause(err)
-		}
+	if a.certStore != nil {
+		a.certStore.Close()
+		a.certStore = nil
 	}
 
 	a.running = false
+	// An expired ctx only means connections were closed rather than drained
+	if shutdownErr != nil && ctx.Err() == nil {
+		return errors.NewError(errors.ErrorTypeInternal, "failed to shutdown WebSocket server").WithCause(shutdownErr)
+	}
 	return nil
 }
 
+// drain closes open connections with a going-away close frame, waiting for
+// the proxies to finish. Connections still open at the deadline are closed.
+func (a *Adapter) drain(ctx context.Context) {
+	a.connsMu.Lock()
+	a.draining = true
+	if len(a.conns) == 0 {
+		a.connsMu.Unlock()
+		return
+	}
+	a.drained = make(chan struct{})
+	drained := a.drained
+	conns := make([]*conn, 0, len(a.conns))
+	for c := range a.conns {
+		conns = append(conns, c)
+	}
+	a.connsMu.Unlock()
+
+	a.logger.Info("Draining WebSocket connections", "connections", len(conns))
+	for _, c := range conns {
+		if err := c.closeGoingAway("server shutting down", a.config.WriteDeadline); err != nil {
+			a.logger.Debug("Failed to write close message on shutdown", "error", err)
+		}
+	}
+
+	// Without a close grace period connections are closed right away
+	ctx, cancel := context.WithTimeout(ctx, max(a.config.CloseGracePeriod, 0))
+	defer cancel()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		a.connsMu.Lock()
+		remaining := len(a.conns)
+		a.connsMu.Unlock()
+		if remaining > 0 {
+			a.logger.Warn("WebSocket drain grace period expired, closing connections", "connections", remaining)
+		}
+		for _, c := range conns {
+			c.Close()
+		}
+	}
+}
+
+// keepAlive pings c every PingPeriod until it or the server closes. A
+// failed ping closes the connection.
+func (a *Adapter) keepAlive(ctx context.Context, c *conn) {
+	ticker := time.NewTicker(a.config.PingPeriod)
+	defer ticker.Stop()
+
+	timeout := a.config.WriteDeadline
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.ping(timeout); err != nil {
+				a.logger.Debug("Failed to send ping to client",
+					"remote", c.remote,
+					"error", err,
+				)
+				c.Close()
+				return
+			}
+		}
+	}
+}
+
+// trackConn registers an upgraded connection, refusing it while draining.
+// Connections are listed by their request ID; a connection reusing the ID
+// of an open one gets a generated ID.
+func (a *Adapter) trackConn(c *conn) bool {
+	a.connsMu.Lock()
+	defer a.connsMu.Unlock()
+
+	if a.draining {
+		return false
+	}
+	if c.id == "" || a.findConn(c.id) != nil {
+		c.id = requestid.GenerateRequestID()
+	}
+	a.conns[c] = struct{}{}
+	c.onClose = func() { a.untrackConn(c) }
+	return true
+}
+
+// untrackConn removes a closed connection
+func (a *Adapter) untrackConn(c *conn) {
+	a.connsMu.Lock()
+	defer a.connsMu.Unlock()
+
+	delete(a.conns, c)
+	if a.draining && len(a.conns) == 0 && a.drained != nil {
+		close(a.drained)
+		a.drained = nil
+	}
+}
+
 // Type returns the adapter type
 func (a *Adapter) Type() string {
 	return "websocket"
@@ -241,6 +437,8 @@ func (a *Adapter) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	handler, tokenValidator, tokens := a.chain()
+
 	// Generate request ID if not present
 	reqID := r.Header.Get("X-Request-ID")
 	if reqID == "" {
@@ -248,22 +446,21 @@ func (a *Adapter) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate JWT token before upgrade if validator is configured
-	if a.tokenValidator != nil {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" || len(authHeader) <= 7 || authHeader[:7] != "Bearer " {
-			// Missing or malformed Authorization header
-			a.logger.Warn("Missing or malformed Authorization header for WebSocket connection",
+	var token string
+	if tokenValidator != nil {
+		var ok bool
+		if token, ok = tokens.FromHTTP(r); !ok {
+			a.logger.Warn("Missing token for WebSocket connection",
 				"remote", r.RemoteAddr,
 			)
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
-		token := authHeader[7:]
 		connectionID := reqID
 
 		// Do a preliminary validation check
-		err := a.tokenValidator.ValidateConnection(r.Context(), connectionID, token, func() {})
+		err := tokenValidator.ValidateConnection(r.Context(), connectionID, token, func() {})
 		if err != nil {
 			// Initial validation failed - reject before upgrade
 			a.logger.Error("JWT validation failed for WebSocket connection",
@@ -274,11 +471,30 @@ func (a *Adapter) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		// Stop this preliminary validation
-		a.tokenValidator.StopValidation(connectionID)
+		tokenValidator.StopValidation(connectionID)
+	}
+
+	// Negotiate the subprotocol; the upgrader echoes it in the handshake
+	// response
+	subprotocol, ok := a.subprotocol(r)
+	if !ok {
+		a.logger.Warn("No supported WebSocket subprotocol offered",
+			"offered", websocket.Subprotocols(r),
+			"remote", r.RemoteAddr,
+		)
+		if a.metrics != nil && a.metrics.ConnectionsTotal != nil {
+			a.metrics.ConnectionsTotal.WithLabelValues("", "failed").Inc()
+		}
+		http.Error(w, "Unsupported WebSocket subprotocol", http.StatusBadRequest)
+		return
+	}
+	var responseHeader http.Header
+	if subprotocol != "" {
+		responseHeader = http.Header{"Sec-Websocket-Protocol": {subprotocol}}
 	}
 
 	// Upgrade HTTP connection to WebSocket
-	conn, err := a.upgrader.Upgrade(w, r, nil)
+	conn, err := a.upgrader.Upgrade(w, r, responseHeader)
 	if err != nil {
 		// Error already logged by upgrader.Error
 		// Track failed connection
@@ -332,55 +548,44 @@ func (a *Adapter) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	// Create WebSocket connection wrapper with server context (not request context)
 	// This ensures the connection remains valid after the HTTP handler returns
 	wsConn := newConnWithMetrics(conn, r.RemoteAddr, a.serverCtx, a.metrics)
+	// Backend messages are held to the same limit as client messages
+	wsConn.maxMessageSize = a.config.MaxMessageSize
+	wsConn.id = reqID
+	wsConn.path = r.URL.Path
+	wsConn.connectedAt = time.Now()
+	if !a.trackConn(wsConn) {
+		if err := wsConn.closeGoingAway("server shutting down", time.Second); err != nil {
+			a.logger.Debug("Failed to write close message on shutdown", "error", err)
+		}
+		wsConn.Close()
+		return
+	}
 
 	// Create request from HTTP upgrade request
 	req := &wsRequest{
 		BaseRequest: request.NewBase(reqID, r, "WEBSOCKET", "websocket"),
 		conn:        wsConn,
+		subprotocol: subprotocol,
 	}
 
 	// Handle the WebSocket connection through the handler chain
 	// Use server context for the WebSocket lifetime
 	ctx := a.serverCtx
 
-	// Start ping ticker for client connection if configured
+	// Ping the client until the connection closes. A client answering
+	// pings has its read deadline extended by the pong handler; one that
+	// does not is closed as idle once PongWait has passed.
 	if a.config.PingPeriod > 0 {
-		go func() {
-			ticker := time.NewTicker(a.config.PingPeriod)
-			defer ticker.Stop()
-
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case <-ticker.C:
-					// Set write deadline to prevent blocking forever
-					if err := conn.SetWriteDeadline(time.Now().Add(a.config.WriteDeadline)); err != nil {
-						a.logger.Debug("Failed to set write deadline", "error", err)
-					}
-					if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-						a.logger.Debug("Failed to send ping to client", 
-							"remote", r.RemoteAddr,
-							"error", err)
-						// Close the connection on ping failure
-						conn.Close()
-						return
-					}
-				}
-			}
-		}()
+		go a.keepAlive(ctx, wsConn)
 	}
 
 	// Start JWT validation if configured
-	if a.tokenValidator != nil {
-		// Extract token from Authorization header
-		authHeader := r.Header.Get("Authorization")
-		if authHeader != "" && len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			token := authHeader[7:]
+	if tokenValidator != nil {
+		if token != "" {
 			connectionID := reqID
 
 			// Start token validation
-			err := a.tokenValidator.ValidateConnection(ctx, connectionID, token, func() {
+			err := tokenValidator.ValidateConnection(ctx, connectionID, token, func() {
 				// Token expired, close the connection
 				a.logger.Info("JWT token expired, closing WebSocket connection",
 					"connectionID", connectionID,
@@ -406,15 +611,15 @@ func (a *Adapter) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 				if err := conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second)); err != nil {
 					a.logger.Debug("Failed to write close message on auth failure", "error", err)
 				}
-				conn.Close()
+				wsConn.Close()
 				return
 			}
 
 			// Stop validation when connection closes
-			defer a.tokenValidator.StopValidation(connectionID)
+			defer tokenValidator.StopValidation(connectionID)
 		}
 	}
-	resp, err := a.handler(ctx, req)
+	resp, err := handler(ctx, req)
 	if err != nil {
 		a.logger.Error("WebSocket handler error",
 			"error", err,
@@ -426,7 +631,7 @@ func (a *Adapter) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		if err := conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second)); err != nil {
 			a.logger.Debug("Failed to write close message on no token", "error", err)
 		}
-		conn.Close()
+		wsConn.Close()
 		return
 	}
 
@@ -443,7 +648,7 @@ func (a *Adapter) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			"path", r.URL.Path,
 			"status", resp.StatusCode(),
 		)
-		conn.Close()
+		wsConn.Close()
 	}
 }
 
@@ -530,8 +735,36 @@ func makeCheckOrigin(config *Config) func(r *http.Request) bool {
 	}
 }
 
+// makeSelectSubprotocol creates the function negotiating the subprotocol of
+// an upgrade request. It picks the first of the configured subprotocols the
+// client offers, and fails when subprotocols are configured and none is
+// offered. Without configured subprotocols none is selected.
+func makeSelectSubprotocol(config *Config) func(r *http.Request) (string, bool) {
+	if len(config.Subprotocols) == 0 {
+		return func(r *http.Request) (string, bool) { return "", true }
+	}
+
+	return func(r *http.Request) (string, bool) {
+		offered := websocket.Subprotocols(r)
+		for _, supported := range config.Subprotocols {
+			for _, protocol := range offered {
+				if protocol == supported {
+					return supported, true
+				}
+			}
+		}
+		return "", false
+	}
+}
+
 // wsRequest implements core.Request for WebSocket
 type wsRequest struct {
 	*request.BaseRequest
-	conn *conn
+	conn        *conn
+	subprotocol string // Negotiated subprotocol, if any
+}
+
+// Subprotocol returns the subprotocol negotiated with the client
+func (r *wsRequest) Subprotocol() string {
+	return r.subprotocol
 }
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -523,6 +524,72 @@ func TestMakeCheckOrigin(t *testing.T) {
 	}
 }
 
+func TestMakeSelectSubprotocol(t *testing.T) {
+	tests := []struct {
+		name      string
+		supported []string
+		offered   string
+		want      string
+		wantOK    bool
+	}{
+		{name: "not configured", offered: "chat", wantOK: true},
+		{name: "server preference", supported: []string{"graphql-transport-ws", "graphql-ws"}, offered: "graphql-ws, graphql-transport-ws", want: "graphql-transport-ws", wantOK: true},
+		{name: "no match", supported: []string{"mqtt"}, offered: "chat"},
+		{name: "none offered", supported: []string{"mqtt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/ws", nil)
+			if tt.offered != "" {
+				r.Header.Set("Sec-WebSocket-Protocol", tt.offered)
+			}
+			got, ok := makeSelectSubprotocol(&Config{Subprotocols: tt.supported})(r)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestAdapter_Subprotocol(t *testing.T) {
+	negotiated := make(chan string, 1)
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		negotiated <- req.(*wsRequest).Subprotocol()
+		return &mockResponse{statusCode: http.StatusSwitchingProtocols}, nil
+	}
+	config := DefaultConfig()
+	config.Subprotocols = []string{"v2.chat", "v1.chat"}
+	adapter := NewAdapter(config, handler, slog.Default())
+
+	server := httptest.NewServer(http.HandlerFunc(adapter.handleWebSocket))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	dialer := websocket.Dialer{Subprotocols: []string{"v1.chat", "v2.chat"}, HandshakeTimeout: 2 * time.Second}
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	if conn.Subprotocol() != "v2.chat" {
+		t.Errorf("Expected v2.chat in the handshake response, got %q", conn.Subprotocol())
+	}
+	select {
+	case got := <-negotiated:
+		if got != "v2.chat" {
+			t.Errorf("Expected handler to see v2.chat, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handler not called")
+	}
+
+	dialer.Subprotocols = []string{"v3.chat"}
+	if _, resp, err := dialer.Dial(url, nil); err == nil || resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected upgrade without a supported subprotocol to be rejected with 400, got %v", err)
+	}
+}
+
 func TestAdapter_Concurrent(t *testing.T) {
 	t.Skip("Skipping flaky test - needs investigation")
 	logger := slog.Default()
@@ -1225,3 +1292,132 @@ func TestAdapter_DoubleStartStop(t *testing.T) {
 		t.Error("Double stop should not error")
 	}
 }
+
+func TestAdapter_DrainOnStop(t *testing.T) {
+	logger := slog.Default()
+
+	// Read from the client until it closes, as the proxy does
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		wsConn := req.(*wsRequest).conn
+		go func() {
+			for {
+				if _, err := wsConn.ReadMessage(); err != nil {
+					wsConn.Close()
+					return
+				}
+			}
+		}()
+		return &mockResponse{statusCode: http.StatusSwitchingProtocols}, nil
+	}
+
+	adapter := NewAdapter(&Config{
+		Host:             "127.0.0.1",
+		Port:             0,
+		CloseGracePeriod: 5 * time.Second,
+		WriteDeadline:    time.Second,
+		PongWait:         10 * time.Second,
+	}, handler, logger)
+	if err := adapter.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s/test", adapter.listener.Addr()), nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	stopped := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		stopped <- adapter.Stop(context.Background())
+	}()
+
+	// The client is sent a going-away close frame and echoes it
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("Expected going away close, got %v", err)
+	}
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Stop failed: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed >= 5*time.Second {
+			t.Errorf("Stop waited for the grace period after the close handshake: %v", elapsed)
+		}
+	case <-time.After(6 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
+
+func TestAdapter_IdleTimeout(t *testing.T) {
+	// Read from the client until it closes, as the proxy does
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		wsConn := req.(*wsRequest).conn
+		go func() {
+			for {
+				if _, err := wsConn.ReadMessage(); err != nil {
+					wsConn.Close()
+					return
+				}
+			}
+		}()
+		return &mockResponse{statusCode: http.StatusSwitchingProtocols}, nil
+	}
+
+	adapter := NewAdapter(&Config{
+		Host:          "127.0.0.1",
+		Port:          0,
+		WriteDeadline: time.Second,
+		PongWait:      200 * time.Millisecond,
+		PingPeriod:    50 * time.Millisecond,
+	}, handler, slog.Default())
+	if err := adapter.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer adapter.Stop(context.Background())
+
+	dial := func(id string) *websocket.Conn {
+		headers := http.Header{}
+		headers.Set("X-Request-ID", id)
+		conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s/test", adapter.listener.Addr()), headers)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		return conn
+	}
+
+	// The active client reads, answering pings with pongs
+	active := dial("active")
+	defer active.Close()
+	activeErr := make(chan error, 1)
+	go func() {
+		_, _, err := active.ReadMessage()
+		activeErr <- err
+	}()
+
+	// The idle client reads nothing, so its pongs are never sent
+	idle := dial("idle")
+	defer idle.Close()
+	time.Sleep(600 * time.Millisecond)
+
+	idle.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var err error
+	for err == nil {
+		_, _, err = idle.ReadMessage()
+	}
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("Expected idle connection to be closed with going away, got %v", err)
+	}
+
+	select {
+	case err := <-activeErr:
+		t.Errorf("Expected active connection to stay open, got %v", err)
+	default:
+	}
+	if conns := adapter.Connections(); len(conns) != 1 || conns[0].ID != "active" {
+		t.Errorf("Expected only the active connection to be open, got %+v", conns)
+	}
+}
@@ -3,6 +3,8 @@ package websocket
 import (
 	"crypto/tls"
 	"time"
+
+	tlsutil "gateway/pkg/tls"
 )
 
 // Config holds WebSocket adapter configuration
@@ -35,4 +37,6 @@ type TLSConfig struct {
 	CertFile   string `yaml:"certFile"`
 	KeyFile    string `yaml:"keyFile"`
 	MinVersion string `yaml:"minVersion"`
+	// Certificates served by SNI server name next to CertFile and KeyFile
+	Certificates []tlsutil.Certificate `yaml:"certificates"`
 }
@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"context"
+	"net"
 	"sync"
 	"time"
 
@@ -18,6 +19,20 @@ type conn struct {
 	disconnected bool
 	mu           sync.RWMutex
 	metrics      *WebSocketMetrics
+	onClose      func()
+	closeOnce    sync.Once
+	done         chan struct{} // Closed by Close
+	// Largest data message written to the client; 0 means unlimited
+	maxMessageSize int64
+	// writeMu serializes writes, as the proxy and broadcasts write to the
+	// same connection
+	writeMu sync.Mutex
+
+	// Registry details, see Adapter.Connections
+	id          string
+	path        string
+	route       string // Guarded by mu, set once routed
+	connectedAt time.Time
 }
 
 // newConn creates a new WebSocket connection wrapper
@@ -26,6 +41,7 @@ func newConn(ws *websocket.Conn, remoteAddr string) *conn {
 		ws:     ws,
 		remote: remoteAddr,
 		ctx:    context.Background(),
+		done:   make(chan struct{}),
 	}
 }
 
@@ -37,6 +53,7 @@ func newConnWithMetrics(ws *websocket.Conn, remoteAddr string, ctx context.Conte
 		remote:  remoteAddr,
 		ctx:     ctx,
 		metrics: metrics,
+		done:    make(chan struct{}),
 	}
 }
 
@@ -59,6 +76,10 @@ func (c *conn) ReadMessage() (*core.WebSocketMessage, error) {
 
 	msgType, data, err := c.ws.ReadMessage()
 	if err != nil {
+		if isTimeout(err) {
+			// No pong within the read deadline: the client is idle
+			c.closeIdle()
+		}
 		c.handleError(err)
 		return nil, err
 	}
@@ -76,6 +97,11 @@ func (c *conn) ReadMessage() (*core.WebSocketMessage, error) {
 
 // WriteMessage writes a message to the connection
 func (c *conn) WriteMessage(msg *core.WebSocketMessage) error {
+	return c.write(msg, 0)
+}
+
+// write writes a message, giving up after timeout unless it is 0
+func (c *conn) write(msg *core.WebSocketMessage, timeout time.Duration) error {
 	// Check if context is done (client disconnected)
 	select {
 	case <-c.ctx.Done():
@@ -91,7 +117,20 @@ func (c *conn) WriteMessage(msg *core.WebSocketMessage) error {
 	}
 	c.mu.RUnlock()
 
+	if c.maxMessageSize > 0 && int64(len(msg.Data)) > c.maxMessageSize &&
+		(msg.Type == core.WebSocketTextMessage || msg.Type == core.WebSocketBinaryMessage) {
+		return core.ErrWebSocketMessageTooBig
+	}
+
+	c.writeMu.Lock()
+	if timeout > 0 {
+		c.ws.SetWriteDeadline(time.Now().Add(timeout))
+	}
 	err := c.ws.WriteMessage(mapMessageTypeReverse(msg.Type), msg.Data)
+	if timeout > 0 {
+		c.ws.SetWriteDeadline(time.Time{})
+	}
+	c.writeMu.Unlock()
 	if err != nil {
 		c.handleError(err)
 		return err
@@ -108,9 +147,36 @@ func (c *conn) WriteMessage(msg *core.WebSocketMessage) error {
 // Close closes the connection
 func (c *conn) Close() error {
 	c.markDisconnected()
+	c.closeOnce.Do(func() {
+		if c.onClose != nil {
+			c.onClose()
+		}
+		close(c.done)
+	})
 	return c.ws.Close()
 }
 
+// closeIdle closes a connection whose client stopped answering pings,
+// sending a going-away close frame first
+func (c *conn) closeIdle() {
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "idle timeout")
+	_ = c.ws.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+	c.Close()
+}
+
+// isTimeout reports whether err is a deadline expiry
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
+// closeGoingAway starts the close handshake with a going-away code. The
+// connection stays open until the client echoes the close frame.
+func (c *conn) closeGoingAway(reason string, deadline time.Duration) error {
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, reason)
+	return c.ws.WriteControl(websocket.CloseMessage, message, time.Now().Add(deadline))
+}
+
 // SetReadDeadline sets the read deadline
 func (c *conn) SetReadDeadline(t time.Time) error {
 	return c.ws.SetReadDeadline(t)
@@ -140,11 +206,13 @@ func (c *conn) WritePing() error {
 	}
 	c.mu.RUnlock()
 
-	// Set write deadline to prevent blocking forever
-	if err := c.ws.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
-		return err
-	}
-	err := c.ws.WriteMessage(websocket.PingMessage, nil)
+	return c.ping(10 * time.Second)
+}
+
+// ping writes a ping, giving up after timeout. Control frames are written
+// with their own deadline, leaving the one of data messages untouched.
+func (c *conn) ping(timeout time.Duration) error {
+	err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(timeout))
 	if err != nil {
 		c.handleError(err)
 		return err
@@ -165,6 +233,26 @@ func (c *conn) RemoteAddr() string {
 	return c.remote
 }
 
+// setRoute records the route the connection was proxied on
+func (c *conn) setRoute(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.route = id
+}
+
+// info describes the connection for the connection registry
+func (c *conn) info() ConnectionInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return ConnectionInfo{
+		ID:          c.id,
+		RemoteAddr:  c.remote,
+		Path:        c.path,
+		Route:       c.route,
+		ConnectedAt: c.connectedAt,
+	}
+}
+
 // mapMessageType maps gorilla websocket message types to core types
 func mapMessageType(t int) core.WebSocketMessageType {
 	switch t {
@@ -0,0 +1,103 @@
+package websocket
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/pkg/errors"
+	"github.com/gorilla/websocket"
+)
+
+// ConnectionInfo describes an open WebSocket connection
+type ConnectionInfo struct {
+	ID          string    `json:"id"`
+	RemoteAddr  string    `json:"remoteAddr"`
+	Path        string    `json:"path"`
+	Route       string    `json:"route,omitempty"` // Empty until routed
+	ConnectedAt time.Time `json:"connectedAt"`
+}
+
+// BroadcastResult counts the connections a broadcast was written to
+type BroadcastResult struct {
+	Sent   int `json:"sent"`
+	Failed int `json:"failed"`
+}
+
+// Connections returns the open connections, oldest first
+func (a *Adapter) Connections() []ConnectionInfo {
+	conns := a.openConns()
+	infos := make([]ConnectionInfo, 0, len(conns))
+	for _, c := range conns {
+		infos = append(infos, c.info())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ConnectedAt.Before(infos[j].ConnectedAt)
+	})
+	return infos
+}
+
+// CloseConnection closes the connection with the given ID after sending the
+// client a normal close frame with reason
+func (a *Adapter) CloseConnection(id, reason string) error {
+	a.connsMu.Lock()
+	c := a.findConn(id)
+	a.connsMu.Unlock()
+
+	if c == nil {
+		return errors.NewError(errors.ErrorTypeNotFound, fmt.Sprintf("connection %s not found", id))
+	}
+
+	message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason)
+	if err := c.ws.WriteControl(websocket.CloseMessage, message, time.Now().Add(a.config.WriteDeadline)); err != nil {
+		a.logger.Debug("Failed to write close message", "connection", id, "error", err)
+	}
+	c.Close()
+	return nil
+}
+
+// Broadcast writes msg to the open connections, or to those of route when
+// it is not empty. Each write gives up after the configured write deadline,
+// so slow clients do not hold up the others.
+func (a *Adapter) Broadcast(msg *core.WebSocketMessage, route string) BroadcastResult {
+	var result BroadcastResult
+	for _, c := range a.openConns() {
+		if route != "" && c.info().Route != route {
+			continue
+		}
+		if err := c.write(msg, a.config.WriteDeadline); err != nil {
+			a.logger.Debug("Failed to broadcast to WebSocket connection",
+				"connection", c.id,
+				"error", err,
+			)
+			result.Failed++
+			continue
+		}
+		result.Sent++
+	}
+	return result
+}
+
+// openConns returns a snapshot of the open connections
+func (a *Adapter) openConns() []*conn {
+	a.connsMu.Lock()
+	defer a.connsMu.Unlock()
+
+	conns := make([]*conn, 0, len(a.conns))
+	for c := range a.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+// findConn returns the open connection with the given ID, or nil; a.connsMu
+// must be held
+func (a *Adapter) findConn(id string) *conn {
+	for c := range a.conns {
+		if c.id == id {
+			return c
+		}
+	}
+	return nil
+}
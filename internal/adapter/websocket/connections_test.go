@@ -0,0 +1,92 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+	"github.com/gorilla/websocket"
+)
+
+func TestAdapter_Connections(t *testing.T) {
+	// Read from the client until it closes, as the proxy does
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		wsConn := req.(*wsRequest).conn
+		go func() {
+			for {
+				if _, err := wsConn.ReadMessage(); err != nil {
+					wsConn.Close()
+					return
+				}
+			}
+		}()
+		return &mockResponse{statusCode: http.StatusSwitchingProtocols}, nil
+	}
+
+	adapter := NewAdapter(&Config{
+		Host:          "127.0.0.1",
+		Port:          0,
+		WriteDeadline: time.Second,
+		PongWait:      10 * time.Second,
+	}, handler, slog.Default())
+	if err := adapter.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer adapter.Stop(context.Background())
+
+	dial := func(id string) *websocket.Conn {
+		headers := http.Header{}
+		headers.Set("X-Request-ID", id)
+		conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s/chat", adapter.listener.Addr()), headers)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		return conn
+	}
+	first := dial("conn-1")
+	defer first.Close()
+	second := dial("conn-2")
+	defer second.Close()
+
+	conns := adapter.Connections()
+	if len(conns) != 2 || conns[0].ID != "conn-1" || conns[1].ID != "conn-2" {
+		t.Fatalf("Expected both connections oldest first, got %+v", conns)
+	}
+	if conns[0].Path != "/chat" || conns[0].RemoteAddr == "" || conns[0].ConnectedAt.IsZero() {
+		t.Errorf("Expected path, remote address and connect time, got %+v", conns[0])
+	}
+
+	msg := &core.WebSocketMessage{Type: core.WebSocketTextMessage, Data: []byte("hello")}
+	if result := adapter.Broadcast(msg, ""); result.Sent != 2 || result.Failed != 0 {
+		t.Errorf("Expected broadcast to both connections, got %+v", result)
+	}
+	for _, conn := range []*websocket.Conn{first, second} {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if _, data, err := conn.ReadMessage(); err != nil || string(data) != "hello" {
+			t.Errorf("Expected broadcast message, got %q, %v", data, err)
+		}
+	}
+	if result := adapter.Broadcast(msg, "other"); result.Sent != 0 {
+		t.Errorf("Expected no connection on another route, got %+v", result)
+	}
+
+	if err := adapter.CloseConnection("missing", "bye"); gwerrors.HTTPStatus(err) != http.StatusNotFound {
+		t.Errorf("Expected not found for an unknown connection, got %v", err)
+	}
+	if err := adapter.CloseConnection("conn-1", "bye"); err != nil {
+		t.Fatalf("CloseConnection failed: %v", err)
+	}
+	first.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := first.ReadMessage()
+	if closeErr, ok := err.(*websocket.CloseError); !ok || closeErr.Code != websocket.CloseNormalClosure || closeErr.Text != "bye" {
+		t.Errorf("Expected normal close with reason, got %v", err)
+	}
+	if conns := adapter.Connections(); len(conns) != 1 || conns[0].ID != "conn-2" {
+		t.Errorf("Expected closed connection to be untracked, got %+v", conns)
+	}
+}
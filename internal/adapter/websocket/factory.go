@@ -8,6 +8,7 @@ import (
 	"gateway/internal/config"
 	"gateway/internal/core"
 	"gateway/pkg/factory"
+	tlsutil "gateway/pkg/tls"
 )
 
 // ComponentName is the name used to register this component
@@ -67,6 +68,21 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 		PingPeriod:        time.Duration(wsConfig.PingPeriod) * time.Second,
 		CloseGracePeriod:  time.Duration(wsConfig.CloseGracePeriod) * time.Second,
 	}
+	if wsConfig.TLS != nil && wsConfig.TLS.Enabled {
+		c.config.TLS = &TLSConfig{
+			Enabled:    true,
+			CertFile:   wsConfig.TLS.CertFile,
+			KeyFile:    wsConfig.TLS.KeyFile,
+			MinVersion: wsConfig.TLS.MinVersion,
+		}
+		for _, cert := range wsConfig.TLS.Certificates {
+			c.config.TLS.Certificates = append(c.config.TLS.Certificates, tlsutil.Certificate{
+				Hosts:    cert.Hosts,
+				CertFile: cert.CertFile,
+				KeyFile:  cert.KeyFile,
+			})
+		}
+	}
 	
 	// Set defaults
 	if c.config.ReadTimeout == 0 {
@@ -8,6 +8,7 @@ import (
 
 	wsConnector "gateway/internal/connector/websocket"
 	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
 	"gateway/pkg/errors"
 )
 
@@ -48,6 +49,10 @@ func (h *Handler) Handle(ctx context.Context, req core.Request) (core.Response,
 		return nil, err
 	}
 
+	if c, ok := wsConn.(*conn); ok && result.Rule != nil {
+		c.setRoute(result.Rule.ID)
+	}
+
 	// Connect to backend
 	headers := make(http.Header)
 	for k, v := range req.Headers() {
@@ -71,6 +76,11 @@ func (h *Handler) Handle(ctx context.Context, req core.Request) (core.Response,
 
 	headers.Set("X-Forwarded-Host", req.Headers()["Host"][0])
 
+	// Offer the backend only the subprotocol negotiated with the client
+	if wsReq, ok := req.(*wsRequest); ok && wsReq.subprotocol != "" {
+		headers.Set("Sec-WebSocket-Protocol", wsReq.subprotocol)
+	}
+
 	// Establish backend connection
 	backendConn, err := h.connector.Connect(ctx, result.Instance, req.Path(), headers)
 	if err != nil {
@@ -80,6 +90,24 @@ func (h *Handler) Handle(ctx context.Context, req core.Request) (core.Response,
 		)
 		return nil, err
 	}
+	if t := result.Rule.WebSocketTransform; t != nil {
+		vars := map[string]string{
+			"auth.subject": "",
+			"route.id":     result.Rule.ID,
+			"request.id":   req.ID(),
+		}
+		if info, ok := auth.GetAuthInfo(ctx); ok && info != nil {
+			vars["auth.subject"] = info.Subject
+		}
+		var inbound, outbound wsConnector.MessageTransform
+		if t.Inbound != nil {
+			inbound = wsConnector.NewMessageTransform(t.Inbound, vars)
+		}
+		if t.Outbound != nil {
+			outbound = wsConnector.NewMessageTransform(t.Outbound, vars)
+		}
+		backendConn.WithTransforms(inbound, outbound)
+	}
 
 	// Start proxying in a goroutine
 	go func() {
@@ -5,18 +5,31 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"time"
 
 	httpAdapter "gateway/internal/adapter/http"
+	tcpAdapter "gateway/internal/adapter/tcp"
 	wsAdapter "gateway/internal/adapter/websocket"
 	"gateway/internal/app/factory"
 	"gateway/internal/config"
+	wsConnector "gateway/internal/connector/websocket"
 	"gateway/internal/core"
 	"gateway/internal/health"
 	"gateway/internal/management"
 	"gateway/internal/metrics"
+	"gateway/internal/middleware/audit"
 	"gateway/internal/middleware/auth"
+	"gateway/internal/middleware/bodylimit"
+	"gateway/internal/middleware/cors"
+	"gateway/internal/middleware/fallback"
+	"gateway/internal/middleware/headers"
+	"gateway/internal/middleware/ipfilter"
+	"gateway/internal/middleware/maintenance"
+	"gateway/internal/registry"
 	"gateway/internal/registry/static"
+	"gateway/internal/telemetry"
+	pluginMiddleware "gateway/pkg/middleware"
 )
 
 // Builder builds the gateway application
@@ -52,37 +65,64 @@ func (b *Builder) Build() (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
+	if telemetryMetrics != nil {
+		providerFactory.WithAPIKeyMetrics(telemetryMetrics)
+	}
 
 	// Create service registry - use health-aware registry if health checks are enabled
-	var registry core.ServiceRegistry
+	var serviceRegistry core.ServiceRegistry
 	var backendMonitor *health.BackendMonitor
 	useHealthAware := b.config.Gateway.Health != nil && b.config.Gateway.Health.Enabled
 	
 	if useHealthAware {
-		registry, backendMonitor, err = registryFactory.CreateHealthAwareRegistry(&b.config.Gateway.Registry, b.config.Gateway.Health)
+		serviceRegistry, backendMonitor, err = registryFactory.CreateHealthAwareRegistry(&b.config.Gateway.Registry, b.config.Gateway.Health)
 		if err != nil {
 			return nil, fmt.Errorf("creating health-aware registry: %w", err)
 		}
 	} else {
-		registry, err = registryFactory.CreateRegistry(&b.config.Gateway.Registry)
+		serviceRegistry, err = registryFactory.CreateRegistry(&b.config.Gateway.Registry)
 		if err != nil {
 			return nil, fmt.Errorf("creating registry: %w", err)
 		}
 	}
 
+	// Drained and ejected instances are seen as unhealthy by the router
+	// while other components use the registry directly
+	drainRegistry := registry.NewDrainRegistry(serviceRegistry)
+	routerRegistry := core.ServiceRegistry(drainRegistry)
+	outlierDetector := middlewareFactory.CreateOutlierDetector(b.config.Gateway.CircuitBreaker)
+	if outlierDetector != nil {
+		if telemetryMetrics != nil {
+			outlierDetector.WithMetrics(telemetryMetrics)
+		}
+		routerRegistry = outlierDetector.WrapRegistry(drainRegistry)
+	}
+
 	// Create router
-	gatewayRouter, err := routerFactory.CreateRouter(&b.config.Gateway.Router, registry)
+	gatewayRouter, err := routerFactory.CreateRouter(&b.config.Gateway.Router, b.config.Gateway.Redis, routerRegistry)
 	if err != nil {
 		return nil, err
 	}
+	if telemetryMetrics != nil {
+		routerFactory.InstrumentRouter(gatewayRouter, telemetryMetrics)
+	}
+
+	// Create the OpenAPI route manager if configured
+	openAPIManager, err := routerFactory.CreateOpenAPIManager(b.config.Gateway.OpenAPI, gatewayRouter)
+	if err != nil {
+		return nil, fmt.Errorf("creating OpenAPI manager: %w", err)
+	}
 
 	// Create auth middleware if configured
 	var authMiddleware *auth.Middleware
 	if b.config.Gateway.Auth != nil {
-		authMiddleware, err = middlewareFactory.CreateAuthMiddleware(b.config.Gateway.Auth)
+		authMiddleware, err = middlewareFactory.CreateAuthMiddleware(b.config.Gateway.Auth, providerFactory)
 		if err != nil {
 			return nil, fmt.Errorf("creating auth middleware: %w", err)
 		}
+		if authMiddleware != nil && telemetryMetrics != nil {
+			authMiddleware.WithMetrics(telemetryMetrics)
+		}
 	}
 	
 	// Create OAuth2 middleware if configured
@@ -97,30 +137,151 @@ func (b *Builder) Build() (*Server, error) {
 		}
 	}
 
+	// Create the registered middleware placed in the chain by config
+	customMiddlewares, err := middlewareFactory.CreateCustomMiddlewares(b.config.Gateway.Middleware)
+	if err != nil {
+		return nil, fmt.Errorf("creating custom middleware: %w", err)
+	}
+	// Names of the middleware requests pass, innermost first, for the echo
+	// endpoint; httpChain is the part wrapping the HTTP adapter
+	var chain, httpChain []string
+	applyCustom := func(h core.Handler, position pluginMiddleware.Position) core.Handler {
+		if len(customMiddlewares[position]) > 0 {
+			chain = append(chain, "custom:"+string(position))
+		}
+		return pluginMiddleware.Chain(h, customMiddlewares[position]...)
+	}
+
 	// Create HTTP client and connector
 	httpClient, err := connectorFactory.CreateHTTPClient(b.config.Gateway.Backend.HTTP)
 	if err != nil {
 		return nil, fmt.Errorf("creating HTTP client: %w", err)
 	}
-	httpConnector := connectorFactory.CreateHTTPConnector(httpClient, b.config.Gateway.Backend.HTTP)
+	serviceClients, err := connectorFactory.CreateServiceHTTPClients(b.config.Gateway.Backend.HTTP)
+	if err != nil {
+		return nil, fmt.Errorf("creating service HTTP clients: %w", err)
+	}
+	if telemetryMetrics != nil {
+		connectorFactory.InstrumentHTTPClient(httpClient, telemetryMetrics)
+		for _, client := range serviceClients {
+			connectorFactory.InstrumentHTTPClient(client, telemetryMetrics)
+		}
+		connectorFactory.WithInformationalMetrics(telemetryMetrics).
+			WithBackendMetrics(telemetryMetrics)
+	}
+	httpConnector, err := connectorFactory.CreateHTTPConnector(httpClient, serviceClients, b.config.Gateway.Backend.HTTP, b.config.Gateway.Auth, telemetryFactory.Propagator(gatewayTelemetry))
+	if err != nil {
+		return nil, fmt.Errorf("creating HTTP connector: %w", err)
+	}
+	if outlierDetector != nil {
+		httpConnector = outlierDetector.WrapConnector(httpConnector)
+		b.logger.Info("Outlier detection enabled")
+	}
 
 	// Create gRPC connector
 	grpcConnector := connectorFactory.CreateGRPCConnector()
+	if telemetryMetrics != nil {
+		grpcConnector.WithMetrics(telemetryMetrics)
+	}
 
 	// Create base handler with multi-protocol support
 	baseHandler := handlerFactory.CreateMultiProtocolHandler(gatewayRouter, httpConnector, grpcConnector)
+	baseHandler = applyCustom(baseHandler, pluginMiddleware.Last)
+
+	// Log the bodies exchanged with the backend at debug level; this needs
+	// the route, so it runs inside the route-aware handler
+	if bodyLogMiddleware := middlewareFactory.CreateBodyLogMiddleware(&b.config.Gateway.Router); bodyLogMiddleware != nil {
+		baseHandler = bodyLogMiddleware.Handler(baseHandler)
+		chain = append(chain, "bodylog")
+		b.logger.Warn("Body logging enabled; request and response bodies are logged at debug level")
+	}
+
+	// Copy requests of mirrored routes to their shadow services; this needs
+	// the route, so it runs inside the route-aware handler
+	if mirrorMiddleware := middlewareFactory.CreateMirrorMiddleware(&b.config.Gateway.Router, routerRegistry, httpConnector); mirrorMiddleware != nil {
+		if telemetryMetrics != nil {
+			mirrorMiddleware.WithMetrics(telemetryMetrics)
+		}
+		baseHandler = mirrorMiddleware.Handler(baseHandler)
+		chain = append(chain, "mirror")
+		b.logger.Info("Request mirroring enabled")
+	}
+
+	// Add circuit breaker middleware if enabled; it runs after routing so
+	// breakers are keyed by route and service
+	cbMiddleware := middlewareFactory.CreateCircuitBreakerMiddleware(b.config.Gateway.CircuitBreaker)
+	if cbMiddleware != nil {
+		if telemetryMetrics != nil {
+			cbMiddleware.WithMetrics(telemetryMetrics)
+		}
+		baseHandler = cbMiddleware.Apply()(baseHandler)
+		chain = append(chain, "circuit-breaker")
+		b.logger.Info("Circuit breaker enabled")
+	}
+
+	// Replay responses for repeated idempotency keys; this needs the route
+	// and auth info, so it runs inside the route-aware handler
+	idempotencyMiddleware, err := middlewareFactory.CreateIdempotencyMiddleware(&b.config.Gateway)
+	if err != nil {
+		return nil, fmt.Errorf("creating idempotency middleware: %w", err)
+	}
+	if idempotencyMiddleware != nil {
+		baseHandler = idempotencyMiddleware.Handler(baseHandler)
+		chain = append(chain, "idempotency")
+		b.logger.Info("Idempotency keys enabled")
+	}
+
+	// Share responses between identical concurrent GET requests; this needs
+	// the route and auth info, so it runs inside the route-aware handler
+	if coalesceMiddleware := middlewareFactory.CreateCoalesceMiddleware(&b.config.Gateway.Router); coalesceMiddleware != nil {
+		if telemetryMetrics != nil {
+			coalesceMiddleware.WithMetrics(telemetryMetrics)
+		}
+		baseHandler = coalesceMiddleware.Handler(baseHandler)
+		chain = append(chain, "coalesce")
+		b.logger.Info("Request coalescing enabled")
+	}
 	
+	// Cap the requests in flight per route and service; this needs the
+	// route, so it runs inside the route-aware handler
+	if concurrencyMiddleware := middlewareFactory.CreateConcurrencyMiddleware(&b.config.Gateway); concurrencyMiddleware != nil {
+		if telemetryMetrics != nil {
+			concurrencyMiddleware.WithMetrics(telemetryMetrics)
+		}
+		baseHandler = concurrencyMiddleware.Handler(baseHandler)
+		chain = append(chain, "concurrency")
+		b.logger.Info("Concurrency limits enabled")
+	}
+
+	// Record the upstream instance and auth subject for access logs; this
+	// needs the route, so it runs inside the route-aware handler
+	accessLog := middlewareFactory.CreateAccessLogMiddleware(b.config.Gateway.Logging)
+	if accessLog != nil {
+		baseHandler = accessLog.Annotate(baseHandler)
+	}
+
+	// Record the route for latency budgets, checked by the telemetry
+	// middleware once the response is produced
+	var telemetryMiddleware *telemetry.Middleware
+	if gatewayTelemetry != nil && telemetryMetrics != nil {
+		telemetryMiddleware = middlewareFactory.CreateTelemetryMiddleware(gatewayTelemetry, telemetryMetrics)
+		baseHandler = telemetryMiddleware.Annotate(baseHandler)
+	}
+
 	// Wrap handler to add route context for middleware
+	baseHandler = applyCustom(baseHandler, pluginMiddleware.AfterRouting)
 	baseHandler = handlerFactory.CreateRouteAwareHandler(gatewayRouter, baseHandler)
+	chain = append(chain, "router")
+	baseHandler = applyCustom(baseHandler, pluginMiddleware.BeforeRouting)
 	
 	// Add tracking middleware for load balancers
 	trackingMiddleware := middlewareFactory.CreateTrackingMiddleware()
 	baseHandler = trackingMiddleware.WrapHandler("gateway.tracking", baseHandler)
 	
 	// Add telemetry middleware if enabled
-	if gatewayTelemetry != nil && telemetryMetrics != nil {
-		telemetryMiddleware := middlewareFactory.CreateTelemetryMiddleware(gatewayTelemetry, telemetryMetrics)
+	if telemetryMiddleware != nil {
 		baseHandler = telemetryMiddleware.WrapHandler("gateway.handler", baseHandler)
+		chain = append(chain, "telemetry")
 		b.logger.Info("Telemetry middleware enabled")
 	}
 
@@ -130,37 +291,87 @@ func (b *Builder) Build() (*Server, error) {
 		gatewayMetrics = telemetryFactory.CreateMetrics(b.config.Gateway.Metrics)
 		metricsMiddleware := middlewareFactory.CreateMetricsMiddleware(gatewayMetrics)
 		baseHandler = metricsMiddleware(baseHandler)
+		chain = append(chain, "metrics")
 		b.logger.Info("Metrics enabled", "path", b.config.Gateway.Metrics.Path)
 	}
 
-	// Add circuit breaker middleware if enabled
-	if cbMiddleware := middlewareFactory.CreateCircuitBreakerMiddleware(b.config.Gateway.CircuitBreaker); cbMiddleware != nil {
-		baseHandler = cbMiddleware.Apply()(baseHandler)
-		b.logger.Info("Circuit breaker enabled")
-	}
-
 	// Add retry middleware if enabled
 	if retryMiddleware := middlewareFactory.CreateRetryMiddleware(b.config.Gateway.Retry); retryMiddleware != nil {
+		if telemetryMetrics != nil {
+			retryMiddleware.WithMetrics(telemetryMetrics)
+		}
 		baseHandler = retryMiddleware.Apply()(baseHandler)
+		chain = append(chain, "retry")
 		b.logger.Info("Retry enabled")
 	}
 
+	// Serve route fallbacks for open breakers and exhausted retries; this
+	// runs outside retries so it sees their final error
+	fallbackMatcher, _ := gatewayRouter.(fallback.RouteMatcher)
+	if fallbackMiddleware := middlewareFactory.CreateFallbackMiddleware(&b.config.Gateway.Router, fallbackMatcher, routerRegistry, httpConnector); fallbackMiddleware != nil {
+		if telemetryMetrics != nil {
+			fallbackMiddleware.WithMetrics(telemetryMetrics)
+		}
+		baseHandler = fallbackMiddleware.Handler(baseHandler)
+		chain = append(chain, "fallback")
+		b.logger.Info("Route fallbacks enabled")
+	}
+
+	// Enforce route body limits before retries and fallbacks buffer the body
+	bodyLimitMatcher, _ := gatewayRouter.(bodylimit.RouteMatcher)
+	bodyLimit, err := middlewareFactory.CreateBodyLimitMiddleware(&b.config.Gateway.Router, bodyLimitMatcher)
+	if err != nil {
+		return nil, fmt.Errorf("creating body limit middleware: %w", err)
+	}
+	if bodyLimit != nil {
+		baseHandler = bodyLimit.Handler(baseHandler)
+		chain = append(chain, "body-limit")
+		b.logger.Info("Route body limits enabled")
+	}
+
+	// Record the principal for audit events; this needs auth info, so it
+	// runs inside the auth middleware
+	routeMatcher, _ := gatewayRouter.(audit.RouteMatcher)
+	auditMiddleware, err := middlewareFactory.CreateAuditMiddleware(&b.config.Gateway, routeMatcher)
+	if err != nil {
+		return nil, fmt.Errorf("creating audit middleware: %w", err)
+	}
+	if auditMiddleware != nil {
+		baseHandler = auditMiddleware.Annotate(baseHandler)
+	}
+
+	// Shed load while the gateway is overloaded; this runs inside auth so
+	// authenticated requests can be protected
+	if loadShedding := middlewareFactory.CreateLoadSheddingMiddleware(b.config.Gateway.LoadShedding); loadShedding != nil {
+		if telemetryMetrics != nil {
+			loadShedding.WithMetrics(telemetryMetrics)
+		}
+		baseHandler = loadShedding.Handler(baseHandler)
+		chain = append(chain, "load-shedding")
+		b.logger.Info("Load shedding enabled")
+	}
+
+	baseHandler = applyCustom(baseHandler, pluginMiddleware.AfterAuth)
+
 	// Apply base middleware (recovery, logging, auth)
 	var middlewares []core.Middleware
 	if authMiddleware != nil {
 		middlewares = append(middlewares, authMiddleware.Handler)
+		chain = append(chain, "auth")
 	}
 	baseHandler = handlerFactory.ApplyMiddleware(baseHandler, middlewares...)
 	
 	// Apply OAuth2 middleware if configured
 	if oauth2Middleware != nil {
 		baseHandler = oauth2Middleware(baseHandler)
+		chain = append(chain, "oauth2")
 		b.logger.Info("OAuth2 authentication enabled")
 	}
+	baseHandler = applyCustom(baseHandler, pluginMiddleware.BeforeAuth)
 	
 	// Apply authorization middlewares
 	if b.config.Gateway.Middleware != nil && b.config.Gateway.Middleware.Authz != nil {
-		authzMiddlewares, err := middlewareFactory.CreateAuthzMiddlewares(b.config.Gateway.Middleware.Authz)
+		authzMiddlewares, err := middlewareFactory.CreateAuthzMiddlewares(b.config.Gateway.Middleware.Authz, telemetryMetrics)
 		if err != nil {
 			return nil, fmt.Errorf("creating authorization middlewares: %w", err)
 		}
@@ -168,24 +379,97 @@ func (b *Builder) Build() (*Server, error) {
 			baseHandler = mw(baseHandler)
 		}
 		if len(authzMiddlewares) > 0 {
+			chain = append(chain, "authz")
 			b.logger.Info("Authorization middlewares enabled", "count", len(authzMiddlewares))
 		}
 	}
 
-	// TODO: Add versioning middleware at HTTP adapter level
-	// Versioning middleware works with http.Handler, not core.Handler
-
 	// Add rate limiting middleware after basic middleware but before business logic
 	if rateLimitMiddleware := middlewareFactory.CreateRateLimitMiddleware(&b.config.Gateway.Router, &b.config.Gateway); rateLimitMiddleware != nil {
 		baseHandler = rateLimitMiddleware(baseHandler)
+		chain = append(chain, "rate-limit")
 		b.logger.Info("Rate limiting enabled for configured routes")
 	}
 
+	// Reject denied client IPs before auth and rate limiting
+	ipFilterMatcher, _ := gatewayRouter.(ipfilter.RouteMatcher)
+	ipFilter, err := middlewareFactory.CreateIPFilterMiddleware(&b.config.Gateway, ipFilterMatcher)
+	if err != nil {
+		return nil, fmt.Errorf("creating IP filter middleware: %w", err)
+	}
+	if ipFilter != nil {
+		baseHandler = ipFilter.Handler(baseHandler)
+		chain = append(chain, "ip-filter")
+		b.logger.Info("IP filtering enabled")
+	}
+
+	// Audit outermost, so requests rejected by auth or rate limiting are
+	// audited too
+	if auditMiddleware != nil {
+		baseHandler = auditMiddleware.Handler(baseHandler)
+		chain = append(chain, "audit")
+		b.logger.Info("Audit logging enabled", "sink", b.config.Gateway.Audit.Sink.Type)
+	}
+
+	// Maintenance mode, switched through the management API, short-circuits
+	// requests before any other middleware
+	maintenanceSwitch := maintenance.NewSwitch()
+	maintenanceMatcher, _ := gatewayRouter.(maintenance.RouteMatcher)
+	maintenanceMiddleware := maintenance.New(maintenanceSwitch, maintenanceMatcher)
+	baseHandler = maintenanceMiddleware.Handler(baseHandler)
+	chain = append(chain, "maintenance")
+
+	// Response headers are edited last, on every response of the chain
+	headersMatcher, _ := gatewayRouter.(headers.RouteMatcher)
+	responseHeaders, err := middlewareFactory.CreateResponseHeadersMiddleware(&b.config.Gateway, headersMatcher)
+	if err != nil {
+		return nil, fmt.Errorf("creating response headers middleware: %w", err)
+	}
+	if responseHeaders != nil {
+		baseHandler = responseHeaders.Handler(baseHandler)
+		chain = append(chain, "response-headers")
+		b.logger.Info("Response header policies enabled")
+	}
+	baseHandler = applyCustom(baseHandler, pluginMiddleware.First)
+
 	// Create HTTP adapter
 	httpAdapterInstance, err := adapterFactory.CreateHTTPAdapter(b.config.Gateway.Frontend.HTTP, baseHandler)
 	if err != nil {
 		return nil, fmt.Errorf("creating HTTP adapter: %w", err)
 	}
+	// Resolve client IPs behind trusted proxies before anything reads the
+	// remote address
+	clientIP, err := adapterFactory.CreateClientIPResolver(&b.config.Gateway.Frontend)
+	if err != nil {
+		return nil, fmt.Errorf("creating client IP resolver: %w", err)
+	}
+	if clientIP != nil {
+		httpAdapterInstance.WithClientIP(clientIP)
+		b.logger.Info("Trusted proxies configured", "proxies", b.config.Gateway.Frontend.TrustedProxies)
+	}
+	// Add API versioning at the HTTP adapter level; it resolves the version
+	// and service override before the router sees the request
+	versioningMiddleware, err := middlewareFactory.CreateVersioningMiddleware(b.config.Gateway.Versioning)
+	if err != nil {
+		return nil, fmt.Errorf("creating versioning middleware: %w", err)
+	}
+	if versioningMiddleware != nil {
+		httpAdapterInstance.WithHTTPMiddleware(versioningMiddleware.Middleware)
+		httpChain = append(httpChain, "versioning")
+		b.logger.Info("API versioning enabled", "strategy", b.config.Gateway.Versioning.Strategy)
+	}
+	// Security headers at the HTTP adapter level cover built-in endpoints
+	// and error responses, and see whether the connection uses TLS
+	if securityHeaders := middlewareFactory.CreateSecurityHeadersMiddleware(b.config.Gateway.SecurityHeaders); securityHeaders != nil {
+		httpAdapterInstance.WithHTTPMiddleware(securityHeaders.Middleware)
+		httpChain = append(httpChain, "security-headers")
+		b.logger.Info("Security headers enabled")
+	}
+	if accessLog != nil {
+		httpAdapterInstance.WithAccessLog(accessLog.Handler)
+		httpChain = append(httpChain, "access-log")
+		b.logger.Info("Access logging enabled", "format", b.config.Gateway.Logging.Format)
+	}
 
 	// Add health check support if enabled
 	var healthHandler *health.Handler
@@ -195,17 +479,29 @@ func (b *Builder) Build() (*Server, error) {
 		version := "1.0.0" // Could be injected via build flags
 
 		// Create health handler
-		healthHandler, _, err = healthFactory.CreateHealthHandler(cfg, registry, version, serviceID)
+		healthHandler, _, err = healthFactory.CreateHealthHandler(cfg, serviceRegistry, version, serviceID)
 		if err != nil {
 			return nil, err
 		}
+		healthFactory.AddDependencyChecks(healthHandler.Checker(), &b.config.Gateway, serviceRegistry)
 		
 		// Get backend monitor if health-aware registry is used and we didn't get it earlier
 		if useHealthAware && backendMonitor != nil {
 			// Register health update callback if registry supports it
-			if healthRegistry, ok := registry.(*static.HealthAwareRegistry); ok {
+			if healthRegistry, ok := serviceRegistry.(*static.HealthAwareRegistry); ok {
 				backendMonitor.RegisterUpdateCallback(healthRegistry.RegisterHealthUpdateCallback())
 			}
+			if telemetryMetrics != nil {
+				if outlierDetector != nil {
+					// The outlier detector reports the instance counts,
+					// merging ejections into the health the checks found
+					backendMonitor.RegisterUpdateCallback(func(service string, _ *core.ServiceInstance, _ bool) {
+						routerRegistry.GetService(service)
+					})
+				} else {
+					backendMonitor.WithMetrics(telemetryMetrics)
+				}
+			}
 			
 			// Start backend monitoring
 			if err := backendMonitor.Start(context.Background()); err != nil {
@@ -226,61 +522,103 @@ func (b *Builder) Build() (*Server, error) {
 		)
 	}
 
-	// Add metrics endpoint if enabled
+	// Add metrics endpoint if enabled, telemetry metrics exported for
+	// Prometheus are served by the same handler
 	var metricsServer *http.Server
-	if telemetryFactory.ShouldEnableMetrics(b.config.Gateway.Metrics) {
-		metricsHandler := adapterFactory.CreateMetricsHandler(gatewayMetrics)
+	telemetryGatherer := telemetryFactory.PrometheusGatherer(gatewayTelemetry)
+	if telemetryFactory.ShouldEnableMetrics(b.config.Gateway.Metrics) || telemetryGatherer != nil {
+		metricsHandler := adapterFactory.CreateMetricsHandler(gatewayMetrics, telemetryGatherer)
+		metricsConfig := b.config.Gateway.Metrics
+		if metricsConfig == nil {
+			metricsConfig = &config.Metrics{}
+		}
 		
-		// Check if metrics should be on a separate port
-		if b.config.Gateway.Metrics.Port > 0 {
+		// Check if metrics should be on a separate port or Unix socket
+		if metricsConfig.Port > 0 || metricsConfig.UnixSocket != "" {
 			// Create separate metrics server with path routing
 			mux := http.NewServeMux()
-			metricsPath := b.config.Gateway.Metrics.Path
+			metricsPath := metricsConfig.Path
 			if metricsPath == "" {
 				metricsPath = "/metrics"
 			}
 			mux.Handle(metricsPath, metricsHandler)
 			
 			metricsServer = &http.Server{
-				Addr:    fmt.Sprintf(":%d", b.config.Gateway.Metrics.Port),
+				Addr:    fmt.Sprintf(":%d", metricsConfig.Port),
 				Handler: mux,
 			}
+			if metricsConfig.UnixSocket != "" {
+				metricsServer.Addr = metricsConfig.UnixSocket
+			}
 			b.logger.Info("Metrics server configured on separate port", 
-				"port", b.config.Gateway.Metrics.Port,
+				"address", metricsServer.Addr,
 				"path", metricsPath)
 		} else {
 			// Add metrics to main HTTP server
 			httpAdapterInstance.WithMetricsHandler(metricsHandler)
-			if b.config.Gateway.Metrics.Path != "" {
-				httpAdapterInstance.WithMetricsPath(b.config.Gateway.Metrics.Path)
+			if metricsConfig.Path != "" {
+				httpAdapterInstance.WithMetricsPath(metricsConfig.Path)
 			}
 			b.logger.Info("Metrics enabled on main server", 
-				"path", b.config.Gateway.Metrics.Path)
+				"path", metricsConfig.Path)
 		}
 	}
 
-	// Add CORS support if enabled
-	// TODO: Apply CORS at HTTP adapter level  
-	// CORS middleware works with http.Handler, not core.Handler
-	if b.config.Gateway.CORS != nil && b.config.Gateway.CORS.Enabled {
+	// Add CORS support if enabled globally or on any route
+	corsMatcher, _ := gatewayRouter.(cors.RouteMatcher)
+	if corsPolicies := middlewareFactory.CreateCORSPolicies(&b.config.Gateway, corsMatcher); corsPolicies != nil {
+		httpAdapterInstance.WithCORSHandler(corsPolicies.Handler(httpAdapterInstance))
+		httpChain = append([]string{"cors"}, httpChain...)
 		b.logger.Info("CORS enabled")
 	}
 
+	// Request middleware in the order requests pass them, for explaining
+	// routing decisions
+	middlewareNames := append(slices.Clone(chain), httpChain...)
+	slices.Reverse(middlewareNames)
+
+	// Report routing decisions from /_gateway/echo/<path> for debugging
+	httpAdapterInstance.WithSecretQueryParams(credentialQueryParams(&b.config.Gateway)...)
+	if explainer, ok := gatewayRouter.(management.RouteExplainer); ok && b.config.Gateway.Frontend.HTTP.EchoRouting {
+		httpAdapterInstance.WithEchoExplainer(httpAdapter.NewEchoExplainer(explainer, middlewareNames))
+		b.logger.Warn("Routing decisions are echoed by /_gateway/echo")
+	}
+
 	// Add SSE support if enabled
 	if cfg := b.config.Gateway.Frontend.SSE; cfg != nil && cfg.Enabled {
-		if err := b.addSSESupport(httpAdapterInstance, gatewayRouter, httpClient, authMiddleware, gatewayMetrics, connectorFactory, adapterFactory, middlewareFactory, handlerFactory, providerFactory); err != nil {
+		if err := b.addSSESupport(httpAdapterInstance, gatewayRouter, httpClient, authMiddleware, ipFilter, maintenanceMiddleware, gatewayMetrics, connectorFactory, adapterFactory, middlewareFactory, handlerFactory, providerFactory); err != nil {
 			return nil, fmt.Errorf("creating SSE adapter: %w", err)
 		}
 	}
 
 	// Create WebSocket adapter if enabled
 	var wsAdapter *wsAdapter.Adapter
+	var wsBackend interface{ Close() error }
 	if cfg := b.config.Gateway.Frontend.WebSocket; cfg != nil && cfg.Enabled {
+		wsConnector := connectorFactory.CreateWebSocketConnector(b.config.Gateway.Backend.WebSocket)
+		if telemetryMetrics != nil {
+			wsConnector.WithMetrics(telemetryMetrics)
+		}
+		wsBackend = wsConnector
+
 		var err error
-		wsAdapter, err = b.createWebSocketAdapter(gatewayRouter, authMiddleware, gatewayMetrics, connectorFactory, adapterFactory, middlewareFactory, handlerFactory, providerFactory)
+		wsAdapter, err = b.createWebSocketAdapter(gatewayRouter, authMiddleware, ipFilter, maintenanceMiddleware, gatewayMetrics, wsConnector, adapterFactory, middlewareFactory, handlerFactory, providerFactory)
 		if err != nil {
 			return nil, fmt.Errorf("creating WebSocket adapter: %w", err)
 		}
+		if clientIP != nil {
+			wsAdapter.WithClientIP(clientIP)
+		}
+	}
+
+	// Create TCP adapter if enabled; it selects instances from the same
+	// drain-aware registry as the router
+	var tcpAdapterInstance *tcpAdapter.Adapter
+	if cfg := b.config.Gateway.Frontend.TCP; cfg != nil && cfg.Enabled {
+		tcpAdapterInstance, err = adapterFactory.CreateTCPAdapter(cfg, routerRegistry, gatewayMetrics)
+		if err != nil {
+			return nil, fmt.Errorf("creating TCP adapter: %w", err)
+		}
 	}
 
 	// Create Management API if enabled
@@ -292,12 +630,28 @@ func (b *Builder) Build() (*Server, error) {
 		}
 		if managementAPI != nil {
 			// Connect managed components
-			managementAPI.SetRegistry(registry)
+			managementAPI.SetConfig(b.config)
+			managementAPI.SetRegistry(serviceRegistry)
+			managementAPI.SetInstanceDrainer(drainRegistry)
+			managementAPI.SetMaintenance(maintenanceSwitch)
+			if wsAdapter != nil {
+				managementAPI.SetWebSocketConnections(wsAdapter)
+			}
+			if cbMiddleware != nil {
+				managementAPI.SetCircuitBreaker(cbMiddleware)
+			}
 			
 			// Cast router to the expected interface
 			if r, ok := gatewayRouter.(interface{ GetRoutes() []core.RouteRule }); ok {
 				managementAPI.SetRouter(r)
 			}
+			if explainer, ok := gatewayRouter.(management.RouteExplainer); ok {
+				var versions management.VersionResolver
+				if versioningMiddleware != nil {
+					versions = versioningMiddleware
+				}
+				managementAPI.SetExplainer(explainer, versions, middlewareNames)
+			}
 			// TODO: Set other components as they implement the required interfaces
 		}
 	}
@@ -309,7 +663,7 @@ func (b *Builder) Build() (*Server, error) {
 	}
 	
 	var registryCloser interface{ Close() error }
-	if r, ok := registry.(interface{ Close() error }); ok {
+	if r, ok := serviceRegistry.(interface{ Close() error }); ok {
 		registryCloser = r
 	}
 	
@@ -325,6 +679,37 @@ func (b *Builder) Build() (*Server, error) {
 		telemetryInterface = gatewayTelemetry
 	}
 
+	// Only set audit sink interface if the concrete type is not nil
+	var auditSink interface{ Close() error }
+	if auditMiddleware != nil {
+		auditSink = auditMiddleware
+	}
+
+	// Only set OpenAPI manager interface if the concrete type is not nil.
+	// The manager is started last so no earlier failure leaves it running.
+	var openAPIInterface interface{ Stop() error }
+	if openAPIManager != nil {
+		if err := openAPIManager.Start(); err != nil {
+			openAPIManager.Stop()
+			return nil, fmt.Errorf("starting OpenAPI manager: %w", err)
+		}
+		openAPIInterface = openAPIManager
+	}
+
+	// Label routes go last, so configured and OpenAPI routes win conflicts
+	if err := routerFactory.SyncDockerRoutes(&b.config.Gateway.Registry, serviceRegistry, gatewayRouter); err != nil {
+		if openAPIManager != nil {
+			openAPIManager.Stop()
+		}
+		return nil, fmt.Errorf("syncing Docker label routes: %w", err)
+	}
+
+	// Only set idempotency interface if the concrete type is not nil
+	var idempotencyCloser interface{ Close() error }
+	if idempotencyMiddleware != nil {
+		idempotencyCloser = idempotencyMiddleware
+	}
+
 	// Only set managementAPI interface if the concrete type is not nil
 	var managementAPIInterface interface{ Start(context.Context) error; Stop(context.Context) error }
 	if managementAPI != nil {
@@ -335,12 +720,21 @@ func (b *Builder) Build() (*Server, error) {
 		config:         b.config,
 		httpAdapter:    httpAdapterInstance,
 		wsAdapter:      wsAdapter,
+		tcpAdapter:     tcpAdapterInstance,
 		metricsServer:  metricsServer,
 		managementAPI:  managementAPIInterface,
 		router:         routerCloser,
 		registry:       registryCloser,
 		telemetry:      telemetryInterface,
 		backendMonitor: backendMonitorInterface,
+		drainRegistry:  drainRegistry,
+		maintenance:    maintenanceSwitch,
+		auditSink:      auditSink,
+		openAPI:        openAPIInterface,
+		grpcConnector:  grpcConnector,
+		wsConnector:    wsBackend,
+		authProviders:  providerFactory,
+		idempotency:    idempotencyCloser,
 		logger:         b.logger,
 	}, nil
 }
@@ -351,6 +745,8 @@ func (b *Builder) addSSESupport(
 	router core.Router,
 	httpClient *http.Client,
 	authMiddleware *auth.Middleware,
+	ipFilter *ipfilter.Middleware,
+	maintenanceMiddleware *maintenance.Middleware,
 	metrics *metrics.Metrics,
 	connectorFactory *factory.ConnectorFactory,
 	adapterFactory *factory.AdapterFactory,
@@ -371,6 +767,13 @@ func (b *Builder) addSSESupport(
 		middlewares = append(middlewares, authMiddleware.Handler)
 	}
 	sseHandler = handlerFactory.ApplyMiddleware(sseHandler, middlewares...)
+
+	if ipFilter != nil {
+		sseHandler = ipFilter.Handler(sseHandler)
+	}
+	if maintenanceMiddleware != nil {
+		sseHandler = maintenanceMiddleware.Handler(sseHandler)
+	}
 	
 	return adapterFactory.CreateSSEAdapter(b.config.Gateway.Frontend.SSE, sseHandler, httpAdapterInstance, b.config.Gateway.Auth, metrics, providerFactory)
 }
@@ -379,14 +782,15 @@ func (b *Builder) addSSESupport(
 func (b *Builder) createWebSocketAdapter(
 	router core.Router,
 	authMiddleware *auth.Middleware,
+	ipFilter *ipfilter.Middleware,
+	maintenanceMiddleware *maintenance.Middleware,
 	metrics *metrics.Metrics,
-	connectorFactory *factory.ConnectorFactory,
+	wsConnector *wsConnector.Connector,
 	adapterFactory *factory.AdapterFactory,
 	middlewareFactory *factory.MiddlewareFactory,
 	handlerFactory *factory.HandlerFactory,
 	providerFactory *factory.ProviderFactory,
 ) (*wsAdapter.Adapter, error) {
-	wsConnector := connectorFactory.CreateWebSocketConnector(b.config.Gateway.Backend.WebSocket)
 	wsHandler := handlerFactory.CreateWebSocketHandler(router, wsConnector)
 
 	// Apply rate limiting if configured
@@ -399,6 +803,40 @@ func (b *Builder) createWebSocketAdapter(
 		middlewares = append(middlewares, authMiddleware.Handler)
 	}
 	wsHandler = handlerFactory.ApplyMiddleware(wsHandler, middlewares...)
+
+	if ipFilter != nil {
+		wsHandler = ipFilter.Handler(wsHandler)
+	}
+	if maintenanceMiddleware != nil {
+		wsHandler = maintenanceMiddleware.Handler(wsHandler)
+	}
 	
 	return adapterFactory.CreateWebSocketAdapter(b.config.Gateway.Frontend.WebSocket, wsHandler, b.config.Gateway.Auth, metrics, providerFactory)
-}
\ No newline at end of file
+}
+// credentialQueryParams returns the query parameters the configured JWT, API
+// key and OAuth2 authentication read credentials from
+func credentialQueryParams(cfg *config.Gateway) []string {
+	var names []string
+	querySources := func(sources []config.TokenSource) {
+		for _, source := range sources {
+			if source.Type == "query" && source.Name != "" {
+				names = append(names, source.Name)
+			}
+		}
+	}
+	if auth := cfg.Auth; auth != nil {
+		if auth.JWT != nil {
+			querySources(auth.JWT.TokenSources)
+		}
+		if auth.APIKey != nil && auth.APIKey.QueryParam != "" {
+			names = append(names, auth.APIKey.QueryParam)
+		}
+	}
+	if m := cfg.Middleware; m != nil && m.Auth != nil && m.Auth.OAuth2 != nil {
+		if m.Auth.OAuth2.TokenQuery != "" {
+			names = append(names, m.Auth.OAuth2.TokenQuery)
+		}
+		querySources(m.Auth.OAuth2.TokenSources)
+	}
+	return names
+}
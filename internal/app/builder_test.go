@@ -1,6 +1,7 @@
 package app
 
 import (
+	"reflect"
 	"testing"
 
 	"gateway/internal/config"
@@ -498,3 +499,28 @@ func TestBuilder_createWebSocketAdapter(t *testing.T) {
 		t.Error("Expected wsAdapter to be created")
 	}
 }
+
+func TestCredentialQueryParams(t *testing.T) {
+	cfg := &config.Gateway{
+		Auth: &config.Auth{
+			JWT: &config.JWTConfig{TokenSources: []config.TokenSource{
+				{Type: "header", Name: "Authorization"},
+				{Type: "query", Name: "jwt"},
+			}},
+			APIKey: &config.APIKeyConfig{QueryParam: "key"},
+		},
+		Middleware: &config.Middleware{Auth: &config.MiddlewareAuth{OAuth2: &config.OAuth2Config{
+			TokenQuery:   "access_token",
+			TokenSources: []config.TokenSource{{Type: "query", Name: "code"}},
+		}}},
+	}
+
+	got := credentialQueryParams(cfg)
+	want := []string{"jwt", "key", "access_token", "code"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("credentialQueryParams() = %v, want %v", got, want)
+	}
+	if got := credentialQueryParams(&config.Gateway{}); len(got) != 0 {
+		t.Errorf("Expected no parameters without auth, got %v", got)
+	}
+}
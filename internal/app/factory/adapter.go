@@ -1,17 +1,24 @@
 package factory
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	httpAdapter "gateway/internal/adapter/http"
 	sseAdapter "gateway/internal/adapter/sse"
+	tcpAdapter "gateway/internal/adapter/tcp"
 	wsAdapter "gateway/internal/adapter/websocket"
 	"gateway/internal/config"
 	"gateway/internal/core"
 	"gateway/internal/health"
 	"gateway/internal/metrics"
 	"gateway/internal/middleware/auth/jwt"
+	"gateway/internal/middleware/auth/token"
+	"gateway/internal/router"
+	"gateway/pkg/clientip"
 	"gateway/pkg/errors"
 )
 
@@ -45,6 +52,15 @@ func (f *AdapterFactory) CreateHTTPAdapter(cfg config.HTTP, handler core.Handler
 	return httpAdapterComp.Build(), nil
 }
 
+// CreateClientIPResolver creates the resolver finding client IPs behind the
+// trusted proxies, returning nil when no proxy is trusted
+func (f *AdapterFactory) CreateClientIPResolver(cfg *config.Frontend) (*clientip.Resolver, error) {
+	if len(cfg.TrustedProxies) == 0 {
+		return nil, nil
+	}
+	return clientip.NewResolver(cfg.TrustedProxies, cfg.XFFDepth, cfg.ForwardedHeader)
+}
+
 // CreateSSEAdapter creates an SSE adapter that integrates with HTTP
 func (f *AdapterFactory) CreateSSEAdapter(
 	cfg *config.SSE,
@@ -83,6 +99,7 @@ func (f *AdapterFactory) CreateSSEAdapter(
 		// Create token validator
 		tokenValidator := jwt.NewTokenValidator(jwtProvider, f.logger)
 		sseAdapterInstance.WithTokenValidator(tokenValidator)
+		sseAdapterInstance.WithTokenExtractor(token.NewExtractor(jwtTokenSources(authConfig.JWT)))
 		f.logger.Info("JWT token validation enabled for SSE connections")
 	}
 
@@ -127,17 +144,71 @@ func (f *AdapterFactory) CreateWebSocketAdapter(
 		// Create token validator
 		tokenValidator := jwt.NewTokenValidator(jwtProvider, f.logger)
 		adapter.WithTokenValidator(tokenValidator)
+		adapter.WithTokenExtractor(token.NewExtractor(jwtTokenSources(authConfig.JWT)))
 		f.logger.Info("JWT token validation enabled for WebSocket connections")
 	}
 
 	return adapter, nil
 }
 
-// CreateMetricsHandler creates a metrics handler
-func (f *AdapterFactory) CreateMetricsHandler(metricsInstance *metrics.Metrics) http.HandlerFunc {
+// CreateTCPAdapter creates a TCP frontend adapter proxying to instances of
+// the configured service in registry
+func (f *AdapterFactory) CreateTCPAdapter(
+	cfg *config.TCP,
+	registry core.ServiceRegistry,
+	metrics *metrics.Metrics,
+) (*tcpAdapter.Adapter, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	var balancer core.LoadBalancer
+	switch core.LoadBalanceStrategy(cfg.LoadBalance) {
+	case "", core.LoadBalanceRoundRobin:
+		balancer = router.NewRoundRobinBalancer()
+	case core.LoadBalanceLeastConnections:
+		balancer = router.NewLeastConnectionsBalancer()
+	default:
+		return nil, fmt.Errorf("unsupported TCP load balance strategy: %s", cfg.LoadBalance)
+	}
+
+	tcpAdapterComponent := tcpAdapter.NewComponent(registry, balancer, f.logger)
+	if err := tcpAdapterComponent.Init(func(v interface{}) error {
+		return f.ParseConfig(*cfg, v)
+	}); err != nil {
+		return nil, err
+	}
+	if err := tcpAdapterComponent.Validate(); err != nil {
+		return nil, err
+	}
+
+	tcpAdapterComp := tcpAdapterComponent.(*tcpAdapter.Component)
+	adapter := tcpAdapterComp.Build()
+
+	// Add metrics if provided
+	if metrics != nil {
+		adapter.WithMetrics(tcpAdapter.NewTCPMetrics(
+			metrics.TCPConnections.WithLabelValues(cfg.ServiceName),
+			metrics.TCPConnectionsTotal,
+			metrics.TCPBytesSent.WithLabelValues(cfg.ServiceName),
+			metrics.TCPBytesReceived.WithLabelValues(cfg.ServiceName),
+		))
+	}
+
+	return adapter, nil
+}
+
+// CreateMetricsHandler creates a metrics handler, also serving telemetry
+// metrics when a telemetry gatherer is given
+func (f *AdapterFactory) CreateMetricsHandler(metricsInstance *metrics.Metrics, telemetryGatherer prometheus.Gatherer) http.HandlerFunc {
+	handler := metrics.Handler()
+	if telemetryGatherer != nil {
+		handler = metrics.HandlerFor(telemetryGatherer)
+	}
+
 	// Return the Prometheus metrics handler
 	return func(w http.ResponseWriter, r *http.Request) {
-		metrics.Handler().ServeHTTP(w, r)
+		handler.ServeHTTP(w, r)
 	}
 }
 
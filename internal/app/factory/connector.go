@@ -2,17 +2,21 @@ package factory
 
 import (
 	"crypto/tls"
+	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
 	"time"
 
+	"go.opentelemetry.io/otel/propagation"
+
 	"gateway/internal/config"
 	"gateway/internal/connector"
 	grpcConnector "gateway/internal/connector/grpc"
 	httpConnector "gateway/internal/connector/http"
 	sseConnector "gateway/internal/connector/sse"
 	wsConnector "gateway/internal/connector/websocket"
+	"gateway/internal/core"
 	"gateway/pkg/errors"
 	tlsutil "gateway/pkg/tls"
 )
@@ -20,6 +24,8 @@ import (
 // ConnectorFactory creates backend connector instances
 type ConnectorFactory struct {
 	BaseComponentFactory
+	informationalMetrics httpConnector.InformationalMetricsRecorder
+	backendMetrics       httpConnector.BackendMetricsRecorder
 }
 
 // NewConnectorFactory creates a new connector factory
@@ -29,6 +35,20 @@ func NewConnectorFactory(logger *slog.Logger) *ConnectorFactory {
 	}
 }
 
+// WithInformationalMetrics sets the recorder of interim responses received
+// by created HTTP connectors
+func (f *ConnectorFactory) WithInformationalMetrics(metrics httpConnector.InformationalMetricsRecorder) *ConnectorFactory {
+	f.informationalMetrics = metrics
+	return f
+}
+
+// WithBackendMetrics sets the recorder of requests sent by created HTTP
+// connectors
+func (f *ConnectorFactory) WithBackendMetrics(metrics httpConnector.BackendMetricsRecorder) *ConnectorFactory {
+	f.backendMetrics = metrics
+	return f
+}
+
 // CreateHTTPClient creates an optimized HTTP client from configuration
 func (f *ConnectorFactory) CreateHTTPClient(cfg config.HTTPBackend) (*http.Client, error) {
 	// Create dialer with keep-alive settings
@@ -43,14 +63,24 @@ func (f *ConnectorFactory) CreateHTTPClient(cfg config.HTTPBackend) (*http.Clien
 	}
 
 	// Create transport with connection pooling
+	// The HTTP connector applies the connect and response header timeouts,
+	// so routes can override them
 	transport := &http.Transport{
-		DialContext:           dialer.DialContext,
-		MaxIdleConns:          cfg.MaxIdleConns,
-		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
-		IdleConnTimeout:       time.Duration(cfg.IdleConnTimeout) * time.Second,
-		ResponseHeaderTimeout: time.Duration(cfg.ResponseHeaderTimeout) * time.Second,
-		ForceAttemptHTTP2:     true,
-		DisableCompression:    false,
+		DialContext:         httpConnector.DialContext(dialer),
+		MaxIdleConns:        cfg.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     cfg.MaxConnsPerHost,
+		IdleConnTimeout:     time.Duration(cfg.IdleConnTimeout) * time.Second,
+		ForceAttemptHTTP2:   !cfg.DisableHTTP2,
+		DisableCompression:  cfg.DisableCompression,
+		// Requests with "Expect: 100-continue" wait for the backend to
+		// accept their body
+		ExpectContinueTimeout:  httpConnector.ExpectContinueTimeout(cfg.ExpectContinueTimeout),
+		MaxResponseHeaderBytes: int64(cfg.MaxResponseHeaderBytes),
+	}
+	if cfg.DisableHTTP2 {
+		// A non-nil empty map keeps TLS connections from negotiating h2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
 	}
 
 	// Configure TLS if enabled
@@ -69,14 +99,119 @@ func (f *ConnectorFactory) CreateHTTPClient(cfg config.HTTPBackend) (*http.Clien
 	}, nil
 }
 
-// CreateHTTPConnector creates an HTTP backend connector
-func (f *ConnectorFactory) CreateHTTPConnector(client *http.Client, cfg config.HTTPBackend) connector.Connector {
+// transportProfile holds the connection settings services may override;
+// services with the same profile share a client
+type transportProfile struct {
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	idleConnTimeout     int
+	keepAlive           bool
+	keepAliveTimeout    int
+	disableHTTP2        bool
+}
+
+func profileOf(cfg config.HTTPBackend) transportProfile {
+	return transportProfile{
+		maxIdleConns:        cfg.MaxIdleConns,
+		maxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		maxConnsPerHost:     cfg.MaxConnsPerHost,
+		idleConnTimeout:     cfg.IdleConnTimeout,
+		keepAlive:           cfg.KeepAlive,
+		keepAliveTimeout:    cfg.KeepAliveTimeout,
+		disableHTTP2:        cfg.DisableHTTP2,
+	}
+}
+
+// CreateServiceHTTPClients creates the HTTP clients of services overriding
+// the connection settings of cfg, by service name. A client is created per
+// distinct set of settings; services whose overrides match cfg are left
+// out and use the default client.
+func (f *ConnectorFactory) CreateServiceHTTPClients(cfg config.HTTPBackend) (map[string]*http.Client, error) {
+	clients := make(map[string]*http.Client, len(cfg.Services))
+	profiles := make(map[transportProfile]*http.Client)
+	defaultProfile := profileOf(cfg)
+	for name := range cfg.Services {
+		serviceCfg := cfg.ForService(name)
+		profile := profileOf(serviceCfg)
+		if profile == defaultProfile {
+			continue
+		}
+		client, ok := profiles[profile]
+		if !ok {
+			var err error
+			if client, err = f.CreateHTTPClient(serviceCfg); err != nil {
+				return nil, fmt.Errorf("service %s: %w", name, err)
+			}
+			profiles[profile] = client
+		}
+		clients[name] = client
+	}
+	return clients, nil
+}
+
+// InstrumentHTTPClient reports connection pool metrics for the client's
+// transport; clients already instrumented are left as they are
+func (f *ConnectorFactory) InstrumentHTTPClient(client *http.Client, recorder httpConnector.PoolMetricsRecorder) {
+	if _, ok := client.Transport.(*httpConnector.PoolTracker); ok {
+		return
+	}
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		f.logger.Warn("HTTP client transport cannot be instrumented for pool metrics")
+		return
+	}
+	client.Transport = httpConnector.NewPoolTracker(transport, recorder)
+}
+
+// CreateHTTPConnector creates an HTTP backend connector sending requests
+// through client, or the client of their service in serviceClients,
+// injecting trace context into backend requests when a propagator is given
+// and identity headers when configured in authCfg
+func (f *ConnectorFactory) CreateHTTPConnector(client *http.Client, serviceClients map[string]*http.Client, cfg config.HTTPBackend, authCfg *config.Auth, propagator propagation.TextMapPropagator) (connector.Connector, error) {
 	// Use response header timeout as default timeout, fallback to 30s
 	defaultTimeout := time.Duration(cfg.ResponseHeaderTimeout) * time.Second
 	if defaultTimeout == 0 {
 		defaultTimeout = 30 * time.Second
 	}
-	return httpConnector.NewHTTPConnector(client, defaultTimeout)
+	c := httpConnector.NewHTTPConnector(client, defaultTimeout).
+		WithTimeouts(core.BackendTimeouts{
+			Connect:        time.Duration(cfg.DialTimeout) * time.Second,
+			ResponseHeader: time.Duration(cfg.ResponseHeaderTimeout) * time.Second,
+			Idle:           time.Duration(cfg.ResponseIdleTimeout) * time.Second,
+		}).
+		WithResponseHeaderLimits(core.HeaderLimits{
+			MaxBytes: cfg.MaxResponseHeaderBytes,
+			MaxCount: cfg.MaxResponseHeaderCount,
+		}).
+		WithPoolWaitTimeout(time.Duration(cfg.PoolWaitTimeout) * time.Millisecond).
+		WithServiceClients(serviceClients).
+		WithPropagator(propagator)
+	if f.informationalMetrics != nil {
+		c.WithInformationalMetrics(f.informationalMetrics)
+	}
+	if f.backendMetrics != nil {
+		c.WithBackendMetrics(f.backendMetrics)
+	}
+	if authCfg != nil && authCfg.IdentityHeaders != nil {
+		h := authCfg.IdentityHeaders
+		identity := &httpConnector.IdentityHeaders{
+			Subject:            h.Subject,
+			Type:               h.Type,
+			Scopes:             h.Scopes,
+			Metadata:           h.Metadata,
+			StripAuthorization: h.StripAuthorization,
+		}
+		for _, claim := range h.Claims {
+			tmpl, err := httpConnector.NewHeaderTemplate(claim.Header, claim.Value, claim.Required)
+			if err != nil {
+				return nil, fmt.Errorf("identity header %s: %w", claim.Header, err)
+			}
+			identity.Templates = append(identity.Templates, tmpl)
+		}
+		c.WithIdentityHeaders(identity)
+	}
+	return c, nil
 }
 
 // CreateSSEConnector creates an SSE backend connector
@@ -85,12 +220,21 @@ func (f *ConnectorFactory) CreateSSEConnector(cfg *config.SSEBackend, client *ht
 		DialTimeout:      10 * time.Second,
 		ResponseTimeout:  30 * time.Second,
 		KeepaliveTimeout: 30 * time.Second,
+		MaxEventSize:     sseConnector.DefaultMaxEventSize,
+		RetryInterval:    sseConnector.DefaultRetryInterval,
 	}
 
 	if cfg != nil {
 		if cfg.ConnectTimeout > 0 {
 			sseConfig.DialTimeout = time.Duration(cfg.ConnectTimeout) * time.Second
 		}
+		if cfg.MaxEventSize > 0 {
+			sseConfig.MaxEventSize = cfg.MaxEventSize
+		}
+		if cfg.RetryInterval > 0 {
+			sseConfig.RetryInterval = time.Duration(cfg.RetryInterval) * time.Second
+		}
+		sseConfig.MaxRetries = cfg.MaxRetries
 	}
 
 	return sseConnector.NewConnector(sseConfig, client, f.logger)
@@ -114,6 +258,14 @@ func (f *ConnectorFactory) CreateWebSocketConnector(cfg *config.WebSocketBackend
 		if cfg.WriteBufferSize > 0 {
 			wsConfig.WriteBufferSize = cfg.WriteBufferSize
 		}
+		if cfg.ReuseConnections {
+			wsConfig.ReuseConnections = true
+			wsConfig.MaxConnections = cfg.MaxConnections
+			if wsConfig.MaxConnections <= 0 {
+				wsConfig.MaxConnections = wsConnector.DefaultConfig().MaxConnections
+			}
+			wsConfig.IdleConnectionTimeout = time.Duration(cfg.IdleConnectionTimeout) * time.Second
+		}
 	}
 
 	return wsConnector.NewConnector(wsConfig, f.logger)
@@ -125,10 +277,10 @@ func (f *ConnectorFactory) CreateGRPCConnector() *grpcConnector.Connector {
 		MaxConcurrentStreams:  100,
 		InitialConnWindowSize: 1024 * 1024,
 		InitialWindowSize:     1024 * 1024,
-		KeepAliveTime:         30,
-		KeepAliveTimeout:      10,
+		KeepAliveTime:         30 * time.Second,
+		KeepAliveTimeout:      10 * time.Second,
 		MaxRetryAttempts:      3,
-		RetryTimeout:          5,
+		RetryTimeout:          5 * time.Second,
 		TLS:                   false,
 	}
 
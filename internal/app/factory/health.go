@@ -1,14 +1,24 @@
 package factory
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
+	"time"
 
 	"gateway/internal/config"
 	"gateway/internal/core"
 	"gateway/internal/health"
 )
 
+// Defaults for gateway.health.dependencies
+const (
+	DefaultDependencyInterval = 10 * time.Second
+	DefaultDependencyTimeout  = 5 * time.Second
+)
+
 // HealthFactory creates health check components
 type HealthFactory struct {
 	BaseComponentFactory
@@ -50,4 +60,81 @@ func (f *HealthFactory) CreateHealthHandler(
 	backendMonitor := healthComp.GetBackendMonitor()
 	
 	return healthHandler, backendMonitor, nil
-}
\ No newline at end of file
+}
+// AddDependencyChecks registers the checks of gateway.health.dependencies
+// with checker
+func (f *HealthFactory) AddDependencyChecks(checker *health.Checker, gatewayCfg *config.Gateway, registry core.ServiceRegistry) {
+	deps := gatewayCfg.Health.Dependencies
+	if deps == nil {
+		return
+	}
+
+	interval := DefaultDependencyInterval
+	if deps.Interval > 0 {
+		interval = time.Duration(deps.Interval) * time.Second
+	}
+	timeout := DefaultDependencyTimeout
+	if deps.Timeout > 0 {
+		timeout = time.Duration(deps.Timeout) * time.Second
+	}
+	register := func(name string, check health.Check) {
+		checker.RegisterCheckWithOptions(name, withTimeout(check, timeout), health.CheckOptions{
+			Interval: interval,
+			Critical: !slices.Contains(deps.Optional, name),
+		})
+		f.logger.Info("Dependency health check enabled", "name", name)
+	}
+
+	if deps.Redis && gatewayCfg.Redis != nil {
+		register("redis", redisCheck(gatewayCfg.Redis))
+	}
+	if deps.Backends && registry != nil {
+		register("backends", health.BackendsCheck(registry, routedServices(&gatewayCfg.Router)))
+	}
+	if a := gatewayCfg.Auth; deps.JWKS && a != nil && a.JWT != nil && a.JWT.JWKSEndpoint != "" {
+		register("jwks", health.HTTPCheck(a.JWT.JWKSEndpoint, timeout))
+	}
+}
+
+// withTimeout bounds a check to timeout
+func withTimeout(check health.Check, timeout time.Duration) health.Check {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return check(ctx)
+	}
+}
+
+// redisCheck pings Redis over a connection opened for the check, so no
+// client outlives the health handler
+func redisCheck(cfg *config.Redis) health.Check {
+	return func(ctx context.Context) error {
+		client, err := newRedisClient(cfg)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		return client.Ping(ctx).Err()
+	}
+}
+
+// routedServices returns the services router rules send requests to
+func routedServices(cfg *config.Router) []string {
+	seen := make(map[string]bool)
+	for _, rule := range cfg.Rules {
+		if rule.ServiceName != "" {
+			seen[rule.ServiceName] = true
+		}
+		if rule.TrafficSplit != nil {
+			for _, s := range rule.TrafficSplit.Services {
+				seen[s.ServiceName] = true
+			}
+		}
+	}
+	services := make([]string, 0, len(seen))
+	for service := range seen {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services
+}
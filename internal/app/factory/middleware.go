@@ -3,21 +3,38 @@ package factory
 import (
 	"fmt"
 	"log/slog"
+	"os"
 	"time"
 
 	"gateway/internal/config"
 	"gateway/internal/core"
 	"gateway/internal/metrics"
+	"gateway/internal/middleware/accesslog"
+	"gateway/internal/middleware/audit"
 	"gateway/internal/middleware/auth"
 	"gateway/internal/middleware/auth/oauth2"
+	"gateway/internal/middleware/cors"
 	"gateway/internal/middleware/authz/rbac"
+	"gateway/internal/middleware/bodylimit"
+	"gateway/internal/middleware/bodylog"
 	"gateway/internal/middleware/circuitbreaker"
+	"gateway/internal/middleware/coalesce"
+	"gateway/internal/middleware/concurrency"
+	"gateway/internal/middleware/fallback"
+	"gateway/internal/middleware/headers"
+	"gateway/internal/middleware/idempotency"
+	"gateway/internal/middleware/ipfilter"
+	"gateway/internal/middleware/loadshed"
 	metricsMiddleware "gateway/internal/middleware/metrics"
+	"gateway/internal/middleware/mirror"
 	"gateway/internal/middleware/ratelimit"
 	"gateway/internal/middleware/retry"
 	"gateway/internal/middleware/tracking"
+	"gateway/internal/middleware/versioning"
 	"gateway/internal/telemetry"
 	pkgCircuitbreaker "gateway/pkg/circuitbreaker"
+	"gateway/pkg/jsonpath"
+	pluginMiddleware "gateway/pkg/middleware"
 	pkgRetry "gateway/pkg/retry"
 )
 
@@ -33,8 +50,9 @@ func NewMiddlewareFactory(logger *slog.Logger) *MiddlewareFactory {
 	}
 }
 
-// CreateAuthMiddleware creates authentication middleware from config
-func (f *MiddlewareFactory) CreateAuthMiddleware(cfg *config.Auth) (*auth.Middleware, error) {
+// CreateAuthMiddleware creates authentication middleware from config, with
+// the providers it lists
+func (f *MiddlewareFactory) CreateAuthMiddleware(cfg *config.Auth, providers *ProviderFactory) (*auth.Middleware, error) {
 	if cfg == nil || len(cfg.Providers) == 0 {
 		return nil, nil
 	}
@@ -46,11 +64,16 @@ func (f *MiddlewareFactory) CreateAuthMiddleware(cfg *config.Auth) (*auth.Middle
 		return nil, err
 	}
 	
-	if authComp, ok := authComponent.(*auth.Component); ok {
-		return authComp.GetMiddleware(), nil
+	authComp, ok := authComponent.(*auth.Component)
+	if !ok {
+		return nil, fmt.Errorf("failed to create auth middleware")
 	}
-	
-	return nil, fmt.Errorf("failed to create auth middleware")
+	authComp.Build()
+	middleware := authComp.GetMiddleware()
+	if err := providers.AddAuthProviders(middleware, cfg); err != nil {
+		return nil, err
+	}
+	return middleware, nil
 }
 
 // CreateOAuth2Middleware creates OAuth2/OIDC authentication middleware
@@ -75,20 +98,27 @@ func (f *MiddlewareFactory) CreateOAuth2Middleware(cfg *config.OAuth2Config) (*o
 	return nil, nil
 }
 
-// CreateAuthzMiddlewares creates authorization middlewares from config
-func (f *MiddlewareFactory) CreateAuthzMiddlewares(cfg *config.MiddlewareAuthz) ([]core.Middleware, error) {
+// CreateAuthzMiddlewares creates authorization middlewares from config.
+// metrics may be nil.
+func (f *MiddlewareFactory) CreateAuthzMiddlewares(cfg *config.MiddlewareAuthz, metrics *telemetry.Metrics) ([]core.Middleware, error) {
 	var middlewares []core.Middleware
 	
 	// Add RBAC middleware if configured
 	if cfg.RBAC != nil && cfg.RBAC.Enabled {
 		rbacComponent := rbac.NewComponent(f.logger)
+		// Ignoring errors would leave routes unprotected, so errors such as
+		// invalid permissions stop the gateway from starting
 		if err := rbacComponent.Init(func(v interface{}) error {
 			return f.ParseConfig(*cfg.RBAC, v)
-		}); err == nil {
-			if rbacComp, ok := rbacComponent.(*rbac.Component); ok {
-				if mw := rbacComp.Build(); mw != nil {
-					middlewares = append(middlewares, mw)
-				}
+		}); err != nil {
+			return nil, fmt.Errorf("rbac: %w", err)
+		}
+		if rbacComp, ok := rbacComponent.(*rbac.Component); ok {
+			if metrics != nil && rbacComp.GetRBAC() != nil {
+				rbacComp.GetRBAC().WithMetrics(metrics)
+			}
+			if mw := rbacComp.Build(); mw != nil {
+				middlewares = append(middlewares, mw)
 			}
 		}
 	}
@@ -140,6 +170,22 @@ func (f *MiddlewareFactory) CreateCircuitBreakerMiddleware(cfg *config.CircuitBr
 	return circuitbreaker.New(cbConfig, f.logger)
 }
 
+// CreateOutlierDetector creates a per-instance outlier detector from config
+func (f *MiddlewareFactory) CreateOutlierDetector(cfg *config.CircuitBreaker) *circuitbreaker.OutlierDetector {
+	if cfg == nil || !cfg.Enabled || cfg.OutlierDetection == nil || !cfg.OutlierDetection.Enabled {
+		return nil
+	}
+
+	od := cfg.OutlierDetection
+	return circuitbreaker.NewOutlierDetector(circuitbreaker.OutlierConfig{
+		EjectionThreshold:  od.EjectionThreshold,
+		MinRequests:        od.MinRequests,
+		MaxEjectionPercent: od.MaxEjectionPercent,
+		BaseEjectionTime:   time.Duration(od.BaseEjectionTime) * time.Second,
+		Interval:           time.Duration(od.Interval) * time.Second,
+	}, f.logger)
+}
+
 // CreateRetryMiddleware creates retry middleware from config
 func (f *MiddlewareFactory) CreateRetryMiddleware(cfg *config.Retry) *retry.Middleware {
 	if cfg == nil || !cfg.Enabled {
@@ -157,6 +203,13 @@ func (f *MiddlewareFactory) CreateRetryMiddleware(cfg *config.Retry) *retry.Midd
 		},
 		Routes:   make(map[string]pkgRetry.Config),
 		Services: make(map[string]pkgRetry.Config),
+		DefaultPolicy: retry.Policy{
+			RetryOn:          cfg.Default.RetryOn,
+			RetryableMethods: cfg.Default.RetryableMethods,
+		},
+		RoutePolicies:       make(map[string]retry.Policy),
+		ServicePolicies:     make(map[string]retry.Policy),
+		ServiceBudgetRatios: make(map[string]float64),
 	}
 
 	// Add per-route configurations if any
@@ -168,6 +221,12 @@ func (f *MiddlewareFactory) CreateRetryMiddleware(cfg *config.Retry) *retry.Midd
 			Multiplier:   routeCfg.Multiplier,
 			Jitter:       routeCfg.Jitter,
 		}
+		if len(routeCfg.RetryOn) > 0 || len(routeCfg.RetryableMethods) > 0 {
+			retryConfig.RoutePolicies[route] = retry.Policy{
+				RetryOn:          routeCfg.RetryOn,
+				RetryableMethods: routeCfg.RetryableMethods,
+			}
+		}
 	}
 
 	// Add per-service configurations if any
@@ -179,6 +238,15 @@ func (f *MiddlewareFactory) CreateRetryMiddleware(cfg *config.Retry) *retry.Midd
 			Multiplier:   serviceCfg.Multiplier,
 			Jitter:       serviceCfg.Jitter,
 		}
+		if len(serviceCfg.RetryOn) > 0 || len(serviceCfg.RetryableMethods) > 0 {
+			retryConfig.ServicePolicies[service] = retry.Policy{
+				RetryOn:          serviceCfg.RetryOn,
+				RetryableMethods: serviceCfg.RetryableMethods,
+			}
+		}
+		if serviceCfg.BudgetRatio > 0 {
+			retryConfig.ServiceBudgetRatios[service] = serviceCfg.BudgetRatio
+		}
 	}
 
 	// Create middleware with custom budget ratio if specified
@@ -223,6 +291,265 @@ func (f *MiddlewareFactory) CreateRateLimitMiddleware(routerCfg *config.Router,
 	return ratelimit.PerRoute(routeConfigs)
 }
 
+// CreateMirrorMiddleware creates middleware copying requests of mirrored
+// routes to their shadow services, returning nil when no route is mirrored
+func (f *MiddlewareFactory) CreateMirrorMiddleware(routerCfg *config.Router, registry core.ServiceRegistry, connector mirror.Connector) *mirror.Middleware {
+	routes := make(map[string]mirror.RouteConfig)
+	for _, rule := range routerCfg.Rules {
+		if m := rule.Mirror; m != nil {
+			routes[rule.ID] = mirror.RouteConfig{
+				ServiceName: m.ServiceName,
+				Percentage:  m.Percentage,
+				Header:      m.Header,
+				Cookie:      m.Cookie,
+				Timeout:     time.Duration(m.Timeout) * time.Second,
+			}
+		}
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+
+	return mirror.New(mirror.Config{Routes: routes}, registry, connector, f.logger)
+}
+
+// CreateFallbackMiddleware creates middleware serving route fallbacks for
+// open breakers and exhausted retries, returning nil when no route has one
+func (f *MiddlewareFactory) CreateFallbackMiddleware(routerCfg *config.Router, matcher fallback.RouteMatcher, registry core.ServiceRegistry, connector fallback.Connector) *fallback.Middleware {
+	routes := make(map[string]fallback.RouteConfig)
+	for _, rule := range routerCfg.Rules {
+		fb := rule.Fallback
+		if fb == nil {
+			continue
+		}
+		cfg := fallback.RouteConfig{
+			ServiceName: fb.ServiceName,
+			StatusCode:  fb.Status,
+			Headers:     make(map[string][]string),
+			On:          fb.On,
+		}
+		if fb.ServiceName == "" {
+			cfg.Body = []byte(fb.Body)
+			contentType := fb.ContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			cfg.Headers["Content-Type"] = []string{contentType}
+			for k, v := range fb.Headers {
+				cfg.Headers[k] = []string{v}
+			}
+		}
+		routes[rule.ID] = cfg
+	}
+	if len(routes) == 0 || matcher == nil {
+		return nil
+	}
+
+	return fallback.New(fallback.Config{Routes: routes}, matcher, registry, connector, f.logger)
+}
+
+// CreateCoalesceMiddleware creates middleware coalescing identical GET
+// requests, returning nil when no route enables it
+func (f *MiddlewareFactory) CreateCoalesceMiddleware(routerCfg *config.Router) *coalesce.Middleware {
+	routes := make(map[string]coalesce.RouteConfig)
+	for _, rule := range routerCfg.Rules {
+		if c := rule.Coalesce; c != nil {
+			routes[rule.ID] = coalesce.RouteConfig{
+				VaryHeaders: c.VaryHeaders,
+				MaxWait:     time.Duration(c.MaxWait) * time.Millisecond,
+			}
+		}
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+
+	return coalesce.New(coalesce.Config{Routes: routes}, f.logger)
+}
+
+// CreateBodyLogMiddleware creates middleware logging the request and
+// response bodies of routes at debug level, returning nil when no route
+// enables it
+func (f *MiddlewareFactory) CreateBodyLogMiddleware(routerCfg *config.Router) *bodylog.Middleware {
+	routes := make(map[string]bodylog.RouteConfig)
+	for _, rule := range routerCfg.Rules {
+		if b := rule.BodyLogging; b != nil && b.Enabled {
+			cfg := bodylog.RouteConfig{MaxBytes: b.MaxBytes}
+			for _, path := range b.Redact {
+				if segments := jsonpath.Parse(path); segments != nil {
+					cfg.Redact = append(cfg.Redact, segments)
+				}
+			}
+			routes[rule.ID] = cfg
+		}
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+
+	return bodylog.New(bodylog.Config{Routes: routes}, f.logger)
+}
+
+// CreateConcurrencyMiddleware creates middleware capping the requests in
+// flight, returning nil when no limit is configured
+func (f *MiddlewareFactory) CreateConcurrencyMiddleware(gatewayCfg *config.Gateway) *concurrency.Middleware {
+	var limits concurrency.Config
+	if c := gatewayCfg.Concurrency; c != nil {
+		if c.MaxInFlight > 0 {
+			limits.Global = &concurrency.Limit{
+				MaxInFlight:  c.MaxInFlight,
+				MaxQueue:     c.MaxQueue,
+				QueueTimeout: time.Duration(c.QueueTimeout) * time.Millisecond,
+			}
+		}
+		if len(c.Services) > 0 {
+			limits.Services = make(map[string]concurrency.Limit, len(c.Services))
+			for name, limit := range c.Services {
+				limits.Services[name] = concurrencyLimit(limit)
+			}
+		}
+		for _, class := range c.PriorityClasses {
+			limits.Classes = append(limits.Classes, concurrency.PriorityClass{
+				Name:         class.Name,
+				Weight:       class.Weight,
+				SubjectTypes: class.SubjectTypes,
+				Scopes:       class.Scopes,
+				Headers:      class.Headers,
+			})
+		}
+	}
+	for _, rule := range gatewayCfg.Router.Rules {
+		if c := rule.Concurrency; c != nil {
+			if limits.Routes == nil {
+				limits.Routes = make(map[string]concurrency.Limit)
+			}
+			limits.Routes[rule.ID] = concurrencyLimit(*c)
+		}
+	}
+	if limits.Global == nil && len(limits.Services) == 0 && len(limits.Routes) == 0 {
+		return nil
+	}
+
+	return concurrency.New(limits, f.logger)
+}
+
+// CreateLoadSheddingMiddleware creates middleware shedding requests while
+// the gateway is overloaded, returning nil when it is not enabled
+func (f *MiddlewareFactory) CreateLoadSheddingMiddleware(cfg *config.LoadShedding) *loadshed.Middleware {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	return loadshed.New(loadshed.Config{
+		Interval:             time.Duration(cfg.Interval) * time.Millisecond,
+		MaxCPU:               cfg.MaxCPU,
+		MaxGCFraction:        cfg.MaxGCFraction,
+		MaxHeapBytes:         uint64(cfg.MaxHeapMB) << 20,
+		MaxGoroutines:        uint64(cfg.MaxGoroutines),
+		Step:                 cfg.Step,
+		MaxRate:              cfg.MaxRate,
+		ExemptPaths:          cfg.ExemptPaths,
+		ProtectAuthenticated: cfg.ProtectAuthenticated,
+	}, f.logger)
+}
+
+// CreateCustomMiddlewares creates the registered middleware referenced by
+// cfg, grouped by position in the order listed
+func (f *MiddlewareFactory) CreateCustomMiddlewares(cfg *config.Middleware) (map[pluginMiddleware.Position][]core.Middleware, error) {
+	if cfg == nil || len(cfg.Custom) == 0 {
+		return nil, nil
+	}
+
+	middlewares := make(map[pluginMiddleware.Position][]core.Middleware)
+	for _, custom := range cfg.Custom {
+		factory, ok := pluginMiddleware.Lookup(custom.Name)
+		if !ok {
+			return nil, fmt.Errorf("unknown custom middleware %q", custom.Name)
+		}
+		mw, err := factory(custom.Config)
+		if err != nil {
+			return nil, fmt.Errorf("create custom middleware %s: %w", custom.Name, err)
+		}
+		if mw == nil {
+			continue
+		}
+		position := pluginMiddleware.Position(custom.Position)
+		if position == "" {
+			position = pluginMiddleware.DefaultPosition
+		}
+		middlewares[position] = append(middlewares[position], mw)
+	}
+	return middlewares, nil
+}
+
+// concurrencyLimit converts a concurrency cap
+func concurrencyLimit(cfg config.ConcurrencyLimit) concurrency.Limit {
+	limit := concurrency.Limit{
+		MaxInFlight:  cfg.MaxInFlight,
+		MaxQueue:     cfg.MaxQueue,
+		QueueTimeout: time.Duration(cfg.QueueTimeout) * time.Millisecond,
+	}
+	if a := cfg.Adaptive; a != nil {
+		limit.Adaptive = &concurrency.Adaptive{
+			MinLimit:  a.MinLimit,
+			MaxLimit:  a.MaxLimit,
+			Tolerance: a.Tolerance,
+			Smoothing: a.Smoothing,
+		}
+	}
+	return limit
+}
+
+// CreateIdempotencyMiddleware creates middleware replaying responses for
+// repeated idempotency keys, returning nil when no route uses them
+func (f *MiddlewareFactory) CreateIdempotencyMiddleware(gatewayCfg *config.Gateway) (*idempotency.Middleware, error) {
+	routes := make(map[string]idempotency.RouteConfig)
+	for _, rule := range gatewayCfg.Router.Rules {
+		if i := rule.Idempotency; i != nil {
+			routes[rule.ID] = idempotency.RouteConfig{
+				Header:  i.Header,
+				Methods: i.Methods,
+				TTL:     time.Duration(i.TTL) * time.Second,
+			}
+		}
+	}
+	if len(routes) == 0 {
+		return nil, nil
+	}
+
+	cfg := gatewayCfg.Idempotency
+	if cfg == nil {
+		cfg = &config.Idempotency{}
+	}
+
+	var store idempotency.Store
+	switch cfg.Storage {
+	case "", "memory":
+		store = idempotency.NewMemoryStore(cfg.MaxEntries)
+	case "redis":
+		if gatewayCfg.Redis == nil {
+			return nil, fmt.Errorf("idempotency redis storage requires redis configuration")
+		}
+		client, err := newRedisClient(gatewayCfg.Redis)
+		if err != nil {
+			return nil, err
+		}
+		prefix := cfg.KeyPrefix
+		if prefix == "" {
+			prefix = "gateway:idempotency:"
+		}
+		store = idempotency.NewRedisStore(client, prefix)
+	default:
+		return nil, fmt.Errorf("unknown idempotency storage %q", cfg.Storage)
+	}
+
+	return idempotency.New(idempotency.Config{
+		Routes:      routes,
+		LockTimeout: time.Duration(cfg.LockTimeout) * time.Second,
+		MaxBodySize: cfg.MaxBodySize,
+	}, store, f.logger), nil
+}
+
 // CreateMetricsMiddleware creates metrics middleware
 func (f *MiddlewareFactory) CreateMetricsMiddleware(metricsInstance *metrics.Metrics) core.Middleware {
 	return metricsMiddleware.Middleware(metricsInstance)
@@ -235,5 +562,239 @@ func (f *MiddlewareFactory) CreateTrackingMiddleware() *tracking.Middleware {
 
 // CreateTelemetryMiddleware creates telemetry middleware
 func (f *MiddlewareFactory) CreateTelemetryMiddleware(telemetryInstance *telemetry.Telemetry, metrics *telemetry.Metrics) *telemetry.Middleware {
-	return telemetry.NewMiddleware(telemetryInstance, metrics)
-}
\ No newline at end of file
+	return telemetry.NewMiddleware(telemetryInstance, metrics, f.logger)
+}
+// CreateAccessLogMiddleware creates access log middleware from config
+func (f *MiddlewareFactory) CreateAccessLogMiddleware(cfg *config.Logging) *accesslog.Middleware {
+	if cfg == nil || !cfg.AccessLog {
+		return nil
+	}
+
+	logger := f.logger
+	switch cfg.Format {
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	case "text":
+		logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+	}
+
+	return accesslog.New(accesslog.Config{
+		Fields:     cfg.Fields,
+		SampleRate: cfg.SampleRate,
+	}, logger)
+}
+
+// CreateAuditMiddleware creates audit middleware for the routes with audit
+// enabled, returning nil when no route is audited
+func (f *MiddlewareFactory) CreateAuditMiddleware(gatewayCfg *config.Gateway, matcher audit.RouteMatcher) (*audit.Middleware, error) {
+	routes := make(map[string]audit.RouteConfig)
+	for _, rule := range gatewayCfg.Router.Rules {
+		if rule.Audit || rule.MustAudit {
+			routes[rule.ID] = audit.RouteConfig{MustAudit: rule.MustAudit}
+		}
+	}
+	if len(routes) == 0 {
+		return nil, nil
+	}
+
+	cfg := gatewayCfg.Audit
+	if cfg == nil {
+		return nil, fmt.Errorf("audit enabled on routes but no audit sink configured")
+	}
+	if matcher == nil {
+		return nil, fmt.Errorf("router does not support audit route matching")
+	}
+
+	var sink audit.Sink
+	switch cfg.Sink.Type {
+	case "stdout":
+		sink = audit.NewWriterSink(os.Stdout)
+	case "file":
+		fileSink, err := audit.NewFileSink(cfg.Sink.Path)
+		if err != nil {
+			return nil, err
+		}
+		sink = fileSink
+	case "webhook":
+		timeout := 5 * time.Second
+		if cfg.Sink.Timeout > 0 {
+			timeout = time.Duration(cfg.Sink.Timeout) * time.Second
+		}
+		sink = audit.NewWebhookSink(cfg.Sink.URL, cfg.Sink.Headers, timeout)
+	default:
+		return nil, fmt.Errorf("unknown audit sink type: %s", cfg.Sink.Type)
+	}
+
+	auditConfig := audit.Config{
+		Routes:        routes,
+		RedactHeaders: cfg.RedactHeaders,
+	}
+	if cfg.Bodies != nil && cfg.Bodies.Enabled {
+		auditConfig.IncludeBodies = true
+		auditConfig.MaxBodySize = cfg.Bodies.MaxSize
+		auditConfig.RedactFields = cfg.Bodies.RedactFields
+	}
+
+	return audit.New(auditConfig, matcher, sink, f.logger), nil
+}
+
+// CreateCORSPolicies creates CORS handling from the global policy and the
+// per-route overrides, returning nil when CORS is not configured anywhere
+func (f *MiddlewareFactory) CreateCORSPolicies(gatewayCfg *config.Gateway, matcher cors.RouteMatcher) *cors.RoutePolicies {
+	var fallback *cors.CORS
+	if cfg := gatewayCfg.CORS; cfg != nil && cfg.Enabled {
+		fallback = cors.New(corsConfig(cfg))
+	}
+
+	routes := make(map[string]*cors.CORS)
+	for _, rule := range gatewayCfg.Router.Rules {
+		if rule.CORS == nil {
+			continue
+		}
+		if rule.CORS.Enabled {
+			routes[rule.ID] = cors.New(corsConfig(rule.CORS))
+		} else {
+			routes[rule.ID] = nil
+		}
+	}
+
+	if fallback == nil && len(routes) == 0 {
+		return nil
+	}
+	return cors.NewRoutePolicies(fallback, routes, matcher)
+}
+
+// CreateIPFilterMiddleware creates IP filtering from the global policy and
+// the per-route policies, returning nil when no policy is configured
+func (f *MiddlewareFactory) CreateIPFilterMiddleware(gatewayCfg *config.Gateway, matcher ipfilter.RouteMatcher) (*ipfilter.Middleware, error) {
+	var filterConfig ipfilter.Config
+	if cfg := gatewayCfg.IPFilter; cfg != nil {
+		filterConfig.Global = ipfilter.Policy{AllowCIDRs: cfg.AllowCIDRs, DenyCIDRs: cfg.DenyCIDRs}
+	}
+
+	routes := make(map[string]ipfilter.Policy)
+	for _, rule := range gatewayCfg.Router.Rules {
+		if rule.IPFilter != nil {
+			routes[rule.ID] = ipfilter.Policy{AllowCIDRs: rule.IPFilter.AllowCIDRs, DenyCIDRs: rule.IPFilter.DenyCIDRs}
+		}
+	}
+	if gatewayCfg.IPFilter == nil && len(routes) == 0 {
+		return nil, nil
+	}
+	filterConfig.Routes = routes
+
+	return ipfilter.New(filterConfig, matcher, f.logger)
+}
+
+// CreateBodyLimitMiddleware creates enforcement of the route request body
+// limits, returning nil when no route has one
+func (f *MiddlewareFactory) CreateBodyLimitMiddleware(routerCfg *config.Router, matcher bodylimit.RouteMatcher) (*bodylimit.Middleware, error) {
+	routes := make(map[string]bodylimit.Limit)
+	for _, rule := range routerCfg.Rules {
+		if rule.MaxBodySize > 0 || len(rule.AllowedContentTypes) > 0 {
+			routes[rule.ID] = bodylimit.Limit{
+				MaxBodySize:         rule.MaxBodySize,
+				AllowedContentTypes: rule.AllowedContentTypes,
+			}
+		}
+	}
+	if len(routes) == 0 {
+		return nil, nil
+	}
+
+	return bodylimit.New(bodylimit.Config{Routes: routes}, matcher, f.logger)
+}
+
+// CreateResponseHeadersMiddleware creates response header editing from the
+// global policy and the per-route policies, returning nil when no policy is
+// configured
+func (f *MiddlewareFactory) CreateResponseHeadersMiddleware(gatewayCfg *config.Gateway, matcher headers.RouteMatcher) (*headers.Middleware, error) {
+	var headersConfig headers.Config
+	if cfg := gatewayCfg.ResponseHeaders; cfg != nil {
+		headersConfig.Global = headersPolicy(cfg)
+	}
+
+	routes := make(map[string]headers.Policy)
+	for _, rule := range gatewayCfg.Router.Rules {
+		if rule.ResponseHeaders != nil {
+			routes[rule.ID] = headersPolicy(rule.ResponseHeaders)
+		}
+	}
+	if gatewayCfg.ResponseHeaders == nil && len(routes) == 0 {
+		return nil, nil
+	}
+	headersConfig.Routes = routes
+
+	return headers.New(headersConfig, matcher)
+}
+
+// CreateSecurityHeadersMiddleware creates security header middleware,
+// returning nil when it is not enabled
+func (f *MiddlewareFactory) CreateSecurityHeadersMiddleware(cfg *config.SecurityHeaders) *headers.Security {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	securityConfig := headers.SecurityConfig{
+		ContentTypeOptions:    cfg.ContentTypeOptions,
+		FrameOptions:          cfg.FrameOptions,
+		ReferrerPolicy:        cfg.ReferrerPolicy,
+		ContentSecurityPolicy: cfg.ContentSecurityPolicy,
+		Disable:               cfg.Disable,
+	}
+	if cfg.HSTS != nil {
+		securityConfig.HSTSMaxAge = cfg.HSTS.MaxAge
+		securityConfig.HSTSIncludeSubDomains = cfg.HSTS.IncludeSubDomains
+	}
+	return headers.NewSecurity(securityConfig)
+}
+
+// headersPolicy converts a response header policy
+func headersPolicy(cfg *config.ResponseHeaders) headers.Policy {
+	return headers.Policy{Add: cfg.Add, Defaults: cfg.Defaults, Remove: cfg.Remove}
+}
+
+// corsConfig converts a CORS policy, keeping defaults for unset fields
+func corsConfig(cfg *config.CORS) cors.Config {
+	corsCfg := cors.DefaultConfig()
+	if len(cfg.AllowedOrigins) > 0 {
+		corsCfg.AllowedOrigins = cfg.AllowedOrigins
+	}
+	if len(cfg.AllowedMethods) > 0 {
+		corsCfg.AllowedMethods = cfg.AllowedMethods
+	}
+	if len(cfg.AllowedHeaders) > 0 {
+		corsCfg.AllowedHeaders = cfg.AllowedHeaders
+	}
+	if len(cfg.ExposedHeaders) > 0 {
+		corsCfg.ExposedHeaders = cfg.ExposedHeaders
+	}
+	if cfg.MaxAge > 0 {
+		corsCfg.MaxAge = cfg.MaxAge
+	}
+	if cfg.OptionsSuccessStatus > 0 {
+		corsCfg.OptionsSuccessStatus = cfg.OptionsSuccessStatus
+	}
+	corsCfg.AllowCredentials = cfg.AllowCredentials
+	corsCfg.OptionsPassthrough = cfg.OptionsPassthrough
+	return corsCfg
+}
+
+// CreateVersioningMiddleware creates API versioning middleware from config
+func (f *MiddlewareFactory) CreateVersioningMiddleware(cfg *config.VersioningConfig) (*versioning.VersioningMiddleware, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	versioningComponent := versioning.NewComponent(f.logger)
+	if err := versioningComponent.Init(func(v interface{}) error {
+		return f.ParseConfig(*cfg, v)
+	}); err != nil {
+		return nil, err
+	}
+	if err := versioningComponent.Validate(); err != nil {
+		return nil, err
+	}
+
+	return versioningComponent.(*versioning.Component).Build(), nil
+}
@@ -1,12 +1,15 @@
 package factory
 
 import (
+	"fmt"
 	"log/slog"
 	"time"
 
 	"gateway/internal/config"
+	"gateway/internal/middleware/auth"
 	"gateway/internal/middleware/auth/apikey"
 	"gateway/internal/middleware/auth/jwt"
+	"gateway/internal/middleware/auth/token"
 	"gateway/pkg/errors"
 )
 
@@ -15,6 +18,7 @@ type ProviderFactory struct {
 	BaseComponentFactory
 	jwtProvider    *jwt.Provider
 	apiKeyProvider *apikey.Provider
+	apiKeyMetrics  apikey.MetricsRecorder
 }
 
 // NewProviderFactory creates a new provider factory
@@ -24,6 +28,20 @@ func NewProviderFactory(logger *slog.Logger) *ProviderFactory {
 	}
 }
 
+// WithAPIKeyMetrics sets the recorder of API key authentications
+func (f *ProviderFactory) WithAPIKeyMetrics(metrics apikey.MetricsRecorder) *ProviderFactory {
+	f.apiKeyMetrics = metrics
+	return f
+}
+
+// Close stops background work of the created providers
+func (f *ProviderFactory) Close() error {
+	if f.apiKeyProvider != nil {
+		return f.apiKeyProvider.Close()
+	}
+	return nil
+}
+
 // GetJWTProvider returns the JWT provider, creating it if necessary
 func (f *ProviderFactory) GetJWTProvider(cfg *config.JWTConfig) (*jwt.Provider, error) {
 	if f.jwtProvider != nil {
@@ -64,19 +82,60 @@ func (f *ProviderFactory) GetAPIKeyProvider(cfg *config.APIKeyConfig) (*apikey.P
 	return provider, nil
 }
 
+// AddAuthProviders adds the enabled providers cfg lists to m, along with
+// the extractors of their credentials
+func (f *ProviderFactory) AddAuthProviders(m *auth.Middleware, cfg *config.Auth) error {
+	for _, name := range cfg.Providers {
+		switch name {
+		case "jwt":
+			if cfg.JWT == nil || !cfg.JWT.Enabled {
+				continue
+			}
+			provider, err := f.GetJWTProvider(cfg.JWT)
+			if err != nil {
+				return err
+			}
+			m.AddProvider(provider)
+			m.AddExtractor(jwt.NewExtractor(jwtTokenSources(cfg.JWT)...))
+		case "apikey":
+			if cfg.APIKey == nil || !cfg.APIKey.Enabled {
+				continue
+			}
+			provider, err := f.GetAPIKeyProvider(cfg.APIKey)
+			if err != nil {
+				return err
+			}
+			extractor := apikey.NewExtractor()
+			if cfg.APIKey.HeaderName != "" {
+				extractor.HeaderName = cfg.APIKey.HeaderName
+			}
+			extractor.QueryParam = cfg.APIKey.QueryParam
+			extractor.Scheme = cfg.APIKey.Scheme
+			m.AddProvider(provider)
+			m.AddExtractor(extractor)
+		}
+	}
+	return nil
+}
+
 // createJWTProvider creates a JWT provider from configuration
 func (f *ProviderFactory) createJWTProvider(cfg *config.JWTConfig) (*jwt.Provider, error) {
 	jwtConfig := &jwt.Config{
-		Issuer:            cfg.Issuer,
-		Audience:          cfg.Audience,
-		SigningMethod:     cfg.SigningMethod,
-		PublicKey:         cfg.PublicKey,
-		Secret:            cfg.Secret,
-		JWKSEndpoint:      cfg.JWKSEndpoint,
-		JWKSCacheDuration: time.Duration(cfg.JWKSCacheDuration) * time.Second,
-		ClaimsMapping:     cfg.ClaimsMapping,
-		ScopeClaim:        cfg.ScopeClaim,
-		SubjectClaim:      cfg.SubjectClaim,
+		Issuer:              cfg.Issuer,
+		Audience:            cfg.Audience,
+		SigningMethod:       cfg.SigningMethod,
+		PublicKey:           cfg.PublicKey,
+		Secret:              cfg.Secret,
+		JWKSEndpoint:        cfg.JWKSEndpoint,
+		JWKSCacheDuration:   time.Duration(cfg.JWKSCacheDuration) * time.Second,
+		ClaimsMapping:       cfg.ClaimsMapping,
+		ScopeClaim:          cfg.ScopeClaim,
+		SubjectClaim:        cfg.SubjectClaim,
+		CacheSize:           cfg.CacheSize,
+		CacheMaxTTL:         time.Duration(cfg.CacheMaxTTL) * time.Second,
+		DecryptionKey:       cfg.DecryptionKey,
+		DecryptionSecret:    cfg.DecryptionSecret,
+		DecryptionAlgorithm: cfg.DecryptionAlgorithm,
 	}
 
 	// Set defaults
@@ -87,6 +146,23 @@ func (f *ProviderFactory) createJWTProvider(cfg *config.JWTConfig) (*jwt.Provide
 	return jwt.NewProvider(jwtConfig, f.logger)
 }
 
+// jwtTokenSources returns where JWTs are read from: the configured token
+// sources, or the header and cookie fields
+func jwtTokenSources(cfg *config.JWTConfig) []token.Source {
+	if len(cfg.TokenSources) > 0 {
+		return token.SourcesFromConfig(cfg.TokenSources)
+	}
+	header := token.Source{Type: token.SourceHeader, Name: "Authorization", Scheme: "Bearer"}
+	if cfg.HeaderName != "" {
+		header.Name = cfg.HeaderName
+	}
+	sources := []token.Source{header}
+	if cfg.CookieName != "" {
+		sources = append(sources, token.Source{Type: token.SourceCookie, Name: cfg.CookieName})
+	}
+	return sources
+}
+
 // createAPIKeyProvider creates an API key provider from configuration
 func (f *ProviderFactory) createAPIKeyProvider(cfg *config.APIKeyConfig) (*apikey.Provider, error) {
 	// Convert config keys
@@ -113,11 +189,72 @@ func (f *ProviderFactory) createAPIKeyProvider(cfg *config.APIKeyConfig) (*apike
 		keys[id] = keyConfig
 	}
 
+	store, err := f.createAPIKeyStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	expiryWarning := apikey.DefaultExpiryWarning
+	if cfg.ExpiryWarningDays < 0 {
+		expiryWarning = 0
+	} else if cfg.ExpiryWarningDays > 0 {
+		expiryWarning = time.Duration(cfg.ExpiryWarningDays) * 24 * time.Hour
+	}
+
 	apiKeyConfig := &apikey.Config{
-		Keys:          keys,
-		HashKeys:      cfg.HashKeys,
-		DefaultScopes: cfg.DefaultScopes,
+		Keys:            keys,
+		HashKeys:        cfg.HashKeys,
+		DefaultScopes:   cfg.DefaultScopes,
+		Store:           store,
+		RefreshInterval: time.Duration(cfg.RefreshInterval) * time.Second,
+		ExpiryWarning:   expiryWarning,
 	}
 
-	return apikey.NewProvider(apiKeyConfig, f.logger)
+	provider, err := apikey.NewProvider(apiKeyConfig, f.logger)
+	if err != nil {
+		return nil, err
+	}
+	if f.apiKeyMetrics != nil {
+		provider.WithMetrics(f.apiKeyMetrics)
+	}
+	return provider, nil
+}
+
+// createAPIKeyStore creates the store API keys are loaded from, nil for
+// static keys only
+func (f *ProviderFactory) createAPIKeyStore(cfg *config.APIKeyConfig) (apikey.Store, error) {
+	timeout := func(seconds int) time.Duration {
+		if seconds <= 0 {
+			return 10 * time.Second
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	switch cfg.Source {
+	case "", "static":
+		return nil, nil
+	case "vault":
+		if cfg.Vault == nil {
+			return nil, errors.NewError(errors.ErrorTypeInternal, "API key source vault requires vault configuration")
+		}
+		vaultConfig := apikey.VaultConfig{
+			Address:   cfg.Vault.Address,
+			Path:      cfg.Vault.Path,
+			Namespace: cfg.Vault.Namespace,
+			Token:     cfg.Vault.Token,
+			TokenFile: cfg.Vault.TokenFile,
+			Timeout:   timeout(cfg.Vault.Timeout),
+		}
+		if role := cfg.Vault.AppRole; role != nil {
+			vaultConfig.AppRole = &apikey.VaultAppRole{RoleID: role.RoleID, SecretID: role.SecretID, Mount: role.Mount}
+		}
+		return apikey.NewVaultStore(vaultConfig)
+	case "http":
+		if cfg.HTTP == nil || cfg.HTTP.URL == "" {
+			return nil, errors.NewError(errors.ErrorTypeInternal, "API key source http requires a URL")
+		}
+		return apikey.NewHTTPStore(cfg.HTTP.URL, cfg.HTTP.Headers, timeout(cfg.HTTP.Timeout)), nil
+	default:
+		return nil, errors.NewError(errors.ErrorTypeInternal, fmt.Sprintf("unknown API key source %q", cfg.Source))
+	}
 }
\ No newline at end of file
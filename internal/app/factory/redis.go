@@ -0,0 +1,77 @@
+package factory
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gateway/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// newRedisClient creates a Redis client from configuration. Cluster and
+// Sentinel settings select the matching client.
+func newRedisClient(cfg *config.Redis) (redis.UniversalClient, error) {
+	seconds := func(s int) time.Duration { return time.Duration(s) * time.Second }
+
+	opts := &redis.UniversalOptions{
+		Password:        cfg.Password,
+		DB:              cfg.DB,
+		PoolSize:        cfg.MaxActive,
+		MaxIdleConns:    cfg.MaxIdle,
+		ConnMaxIdleTime: seconds(cfg.IdleTimeout),
+		DialTimeout:     seconds(cfg.ConnectTimeout),
+		ReadTimeout:     seconds(cfg.ReadTimeout),
+		WriteTimeout:    seconds(cfg.WriteTimeout),
+	}
+	switch {
+	case cfg.Cluster:
+		opts.Addrs = cfg.ClusterNodes
+		opts.IsClusterMode = true
+	case cfg.Sentinel:
+		opts.Addrs = cfg.SentinelNodes
+		opts.MasterName = cfg.MasterName
+	default:
+		host := cfg.Host
+		if host == "" {
+			host = "localhost"
+		}
+		port := cfg.Port
+		if port == 0 {
+			port = 6379
+		}
+		opts.Addrs = []string{host + ":" + strconv.Itoa(port)}
+	}
+
+	if t := cfg.TLS; t != nil && t.Enabled {
+		tlsConfig := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: t.InsecureSkipVerify,
+		}
+		if t.CertFile != "" && t.KeyFile != "" {
+			cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("loading redis client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		if t.CAFile != "" {
+			ca, err := os.ReadFile(t.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading redis CA file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("no certificates found in redis CA file %s", t.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	return redis.NewUniversalClient(opts), nil
+}
@@ -8,6 +8,7 @@ import (
 	"gateway/internal/core"
 	"gateway/internal/health"
 	"gateway/internal/registry"
+	"gateway/internal/registry/static"
 )
 
 // RegistryFactory creates service registry instances
@@ -39,20 +40,37 @@ func (f *RegistryFactory) CreateRegistry(cfg *config.Registry) (core.ServiceRegi
 	return registryComp.Build(), nil
 }
 
-// CreateHealthAwareRegistry creates a health-aware service registry
+// CreateHealthAwareRegistry creates a health-aware service registry. When
+// backend health checks are configured for a static registry, instances are
+// actively probed and the returned monitor updates their health.
 func (f *RegistryFactory) CreateHealthAwareRegistry(cfg *config.Registry, healthCfg *config.Health) (core.ServiceRegistry, *health.BackendMonitor, error) {
-	// First create the base registry
-	baseRegistry, err := f.CreateRegistry(cfg)
-	if err != nil {
-		return nil, nil, err
+	// Without backend checks there is nothing to monitor
+	if healthCfg == nil || !healthCfg.Enabled || len(healthCfg.Backends) == 0 {
+		registry, err := f.CreateRegistry(cfg)
+		return registry, nil, err
 	}
 	
-	// If health is not enabled, just return the base registry
-	if healthCfg == nil || !healthCfg.Enabled {
-		return baseRegistry, nil, nil
+	// Other registries report instance health themselves
+	if cfg.Type != "" && cfg.Type != "static" {
+		f.logger.Warn("Backend health checks require the static registry, skipping",
+			"registry", cfg.Type,
+		)
+		registry, err := f.CreateRegistry(cfg)
+		return registry, nil, err
 	}
 	
-	// For now, just return the base registry without health monitoring
-	// Health monitoring is handled by the health component separately
-	return baseRegistry, nil, nil
-}
\ No newline at end of file
+	component := static.NewHealthAwareComponent(f.logger)
+	if err := component.Init(func(v interface{}) error {
+		return f.ParseConfig(cfg.Static, v)
+	}); err != nil {
+		return nil, nil, fmt.Errorf("initializing health-aware registry: %w", err)
+	}
+	if err := component.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("validating health-aware registry: %w", err)
+	}
+	
+	registry := component.(*static.HealthAwareComponent).GetHealthAwareRegistry()
+	monitor := health.NewBackendMonitor(registry, healthCfg, f.logger)
+	
+	return registry, monitor, nil
+}
@@ -3,9 +3,12 @@ package factory
 import (
 	"fmt"
 	"log/slog"
+	"time"
 
 	"gateway/internal/config"
 	"gateway/internal/core"
+	"gateway/internal/openapi"
+	"gateway/internal/registry/docker"
 	"gateway/internal/router"
 )
 
@@ -21,9 +24,17 @@ func NewRouterFactory(logger *slog.Logger) *RouterFactory {
 	}
 }
 
-// CreateRouter creates a router based on configuration
-func (f *RouterFactory) CreateRouter(cfg *config.Router, registry core.ServiceRegistry) (core.Router, error) {
+// CreateRouter creates a router based on configuration. Routes keeping
+// session mappings in Redis use redisCfg.
+func (f *RouterFactory) CreateRouter(cfg *config.Router, redisCfg *config.Redis, registry core.ServiceRegistry) (core.Router, error) {
 	routerComponent := router.NewComponent(registry, f.logger)
+	if redisCfg != nil && usesRedisSessions(cfg) {
+		client, err := newRedisClient(redisCfg)
+		if err != nil {
+			return nil, fmt.Errorf("creating session affinity redis client: %w", err)
+		}
+		routerComponent.(*router.Component).WithSessionRedis(client)
+	}
 	if err := routerComponent.Init(func(v interface{}) error {
 		return f.ParseConfig(*cfg, v)
 	}); err != nil {
@@ -36,4 +47,90 @@ func (f *RouterFactory) CreateRouter(cfg *config.Router, registry core.ServiceRe
 	
 	routerComp := routerComponent.(*router.Component)
 	return routerComp.Build(), nil
-}
\ No newline at end of file
+}
+
+// usesRedisSessions reports whether any route keeps its session mappings in
+// Redis
+func usesRedisSessions(cfg *config.Router) bool {
+	for _, rule := range cfg.Rules {
+		if a := rule.SessionAffinityConfig; a != nil && a.Enabled && a.Storage == core.SessionStorageRedis {
+			return true
+		}
+	}
+	return false
+}
+
+// InstrumentRouter records traffic split decisions of gatewayRouter
+func (f *RouterFactory) InstrumentRouter(gatewayRouter core.Router, metrics router.SplitMetricsRecorder) {
+	if r, ok := gatewayRouter.(*router.Router); ok {
+		r.WithMetrics(metrics)
+	}
+}
+
+// CreateOpenAPIManager creates a manager generating routes on gatewayRouter
+// from OpenAPI specs, returning nil when OpenAPI routing is not enabled. The
+// manager is not started.
+func (f *RouterFactory) CreateOpenAPIManager(cfg *config.OpenAPIConfig, gatewayRouter core.Router) (*openapi.Manager, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	store, ok := gatewayRouter.(openapi.RuleStore)
+	if !ok {
+		return nil, fmt.Errorf("router does not support dynamic routes")
+	}
+
+	managerCfg := &openapi.Config{
+		Enabled:         cfg.Enabled,
+		SpecsDirectory:  cfg.SpecsDirectory,
+		SpecURLs:        cfg.SpecURLs,
+		DefaultService:  cfg.DefaultService,
+		ReloadInterval:  time.Duration(cfg.ReloadInterval) * time.Second,
+		WatchFiles:      cfg.WatchFiles,
+		ServiceMappings: cfg.ServiceMappings,
+		RouteGeneration: openapi.RouteGeneration{
+			IncludeCORS:          true,
+			PathStyle:            openapi.PathStyleExact,
+			OperationIDAsRouteID: true,
+		},
+	}
+	if m := cfg.Manager; m != nil {
+		managerCfg.UpdateStrategy = m.UpdateStrategy
+		managerCfg.ConflictResolution = m.ConflictResolution
+		managerCfg.RoutePrefix = m.RoutePrefix
+		if g := m.RouteGeneration; g != nil {
+			managerCfg.RouteGeneration = openapi.RouteGeneration{
+				IncludeOptions:       g.IncludeOptions,
+				IncludeCORS:          g.IncludeCORS,
+				PathStyle:            g.PathStyle,
+				OperationIDAsRouteID: g.OperationIDAsRouteID,
+			}
+			if managerCfg.RouteGeneration.PathStyle == "" {
+				managerCfg.RouteGeneration.PathStyle = openapi.PathStyleExact
+			}
+		}
+	}
+
+	return openapi.NewManager(managerCfg, store, f.logger)
+}
+
+// SyncDockerRoutes keeps routes described by container labels on
+// gatewayRouter when the Docker registry reads route labels. Label routes
+// are added after the routes already on the router, which take precedence.
+func (f *RouterFactory) SyncDockerRoutes(cfg *config.Registry, registry core.ServiceRegistry, gatewayRouter core.Router) error {
+	if cfg.Type != "docker" || cfg.Docker == nil || !cfg.Docker.RouteLabels {
+		return nil
+	}
+
+	dockerRegistry, ok := registry.(*docker.Registry)
+	if !ok {
+		return fmt.Errorf("registry does not support route labels")
+	}
+	store, ok := gatewayRouter.(docker.RuleStore)
+	if !ok {
+		return fmt.Errorf("router does not support dynamic routes")
+	}
+
+	dockerRegistry.OnRoutesChange(docker.NewRouteSync(store, f.logger).Apply)
+	return nil
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/propagation"
+
 	"gateway/internal/config"
 	"gateway/internal/metrics"
 	"gateway/internal/telemetry"
@@ -34,14 +37,23 @@ func (f *TelemetryFactory) CreateTelemetry(cfg *config.Telemetry) (*telemetry.Te
 		Version: cfg.Version,
 		Tracing: telemetry.TracingConfig{
 			Enabled:      cfg.Tracing.Enabled,
+			Exporter:     cfg.Tracing.Exporter,
 			Endpoint:     cfg.Tracing.Endpoint,
 			Headers:      cfg.Tracing.Headers,
 			SampleRate:   cfg.Tracing.SampleRate,
 			MaxBatchSize: cfg.Tracing.MaxBatchSize,
 			BatchTimeout: cfg.Tracing.BatchTimeout,
+
+			RouteSampleRates: cfg.Tracing.RouteSampleRates,
+			SampleErrors:     cfg.Tracing.SampleErrors,
 		},
 		Metrics: telemetry.MetricsConfig{
-			Enabled: cfg.Metrics.Enabled,
+			Enabled:    cfg.Metrics.Enabled || cfg.Metrics.Prometheus,
+			Prometheus: cfg.Metrics.Prometheus,
+		},
+		Propagation: telemetry.PropagationConfig{
+			Formats: cfg.Propagation.Formats,
+			Headers: cfg.Propagation.Headers,
 		},
 	}
 	
@@ -52,17 +64,39 @@ func (f *TelemetryFactory) CreateTelemetry(cfg *config.Telemetry) (*telemetry.Te
 	
 	// Create telemetry metrics if enabled
 	var telemetryMetrics *telemetry.Metrics
-	if cfg.Metrics.Enabled {
+	if telemetryConfig.Metrics.Enabled {
 		telemetryMetrics, err = gatewayTelemetry.NewMetrics()
 		if err != nil {
 			return nil, nil, fmt.Errorf("creating telemetry metrics: %w", err)
 		}
+		// Register observable gauge callbacks (circuit breaker state, service instances)
+		if err := telemetryMetrics.RegisterCallbacks(gatewayTelemetry.Meter()); err != nil {
+			return nil, nil, fmt.Errorf("registering telemetry metric callbacks: %w", err)
+		}
 	}
 	
 	f.logger.Info("Telemetry enabled", "service", cfg.Service, "version", cfg.Version)
 	return gatewayTelemetry, telemetryMetrics, nil
 }
 
+// PrometheusGatherer returns the registry to scrape telemetry metrics from,
+// or nil when the Prometheus option is disabled
+func (f *TelemetryFactory) PrometheusGatherer(gatewayTelemetry *telemetry.Telemetry) prometheus.Gatherer {
+	if gatewayTelemetry == nil {
+		return nil
+	}
+	return gatewayTelemetry.Gatherer()
+}
+
+// Propagator returns the propagator injecting context into backend requests,
+// or nil when telemetry is disabled
+func (f *TelemetryFactory) Propagator(gatewayTelemetry *telemetry.Telemetry) propagation.TextMapPropagator {
+	if gatewayTelemetry == nil {
+		return nil
+	}
+	return gatewayTelemetry.Propagator()
+}
+
 // CreateMetrics creates metrics instance from configuration
 func (f *TelemetryFactory) CreateMetrics(cfg *config.Metrics) *metrics.Metrics {
 	if cfg == nil || !cfg.Enabled {
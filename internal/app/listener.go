@@ -0,0 +1,345 @@
+package app
+
+import (
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// sharedListener lets several servers accept from one bound socket. On
+// reload the new server serves through its own view of the old server's
+// listener, so the port stays bound while the old server drains. The socket
+// is closed when the last view is closed.
+type sharedListener struct {
+	net.Listener
+	conns  chan net.Conn
+	closed chan struct{}
+	err    error
+	mu     sync.Mutex
+	refs   int
+	once   sync.Once
+}
+
+// newSharedListener starts accepting connections from l
+func newSharedListener(l net.Listener) *sharedListener {
+	s := &sharedListener{
+		Listener: l,
+		conns:    make(chan net.Conn),
+		closed:   make(chan struct{}),
+	}
+	go s.acceptLoop()
+	return s
+}
+
+// acceptLoop hands accepted connections to whichever view accepts next
+func (s *sharedListener) acceptLoop() {
+	for {
+		conn, err := s.Listener.Accept()
+		if err != nil {
+			s.close(err)
+			return
+		}
+		select {
+		case s.conns <- conn:
+		case <-s.closed:
+			conn.Close()
+			return
+		}
+	}
+}
+
+// view returns a listener that accepts from the shared socket
+func (s *sharedListener) view() (net.Listener, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.closed:
+		return nil, false
+	default:
+	}
+	s.refs++
+	return &listenerView{shared: s, done: make(chan struct{})}, true
+}
+
+// release drops a view and closes the socket after the last one
+func (s *sharedListener) release() {
+	s.mu.Lock()
+	s.refs--
+	last := s.refs == 0
+	s.mu.Unlock()
+
+	if last {
+		s.close(net.ErrClosed)
+	}
+}
+
+// close closes the socket, failing pending and future accepts with err
+func (s *sharedListener) close(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.closed)
+		s.Listener.Close()
+	})
+}
+
+// listenerView is one server's handle on a shared listener
+type listenerView struct {
+	shared *sharedListener
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Accept waits for the next connection on the shared socket
+func (v *listenerView) Accept() (net.Conn, error) {
+	select {
+	case <-v.done:
+		return nil, net.ErrClosed
+	default:
+	}
+
+	select {
+	case conn := <-v.shared.conns:
+		return conn, nil
+	case <-v.done:
+		return nil, net.ErrClosed
+	case <-v.shared.closed:
+		return nil, v.shared.err
+	}
+}
+
+// Close stops this view; the socket stays open for other views
+func (v *listenerView) Close() error {
+	v.once.Do(func() {
+		close(v.done)
+		v.shared.release()
+	})
+	return nil
+}
+
+// Addr returns the shared socket address
+func (v *listenerView) Addr() net.Addr {
+	return v.shared.Addr()
+}
+
+// maxPacketSize is the largest UDP datagram
+const maxPacketSize = 65535
+
+// packet is a datagram read from a shared packet socket
+type packet struct {
+	data []byte
+	addr net.Addr
+}
+
+// sharedPacketConn keeps a UDP socket bound across reloads. QUIC packets of
+// a connection cannot be split between servers, so one view reads at a time:
+// a new view takes the socket over and the previous one stops receiving, but
+// can still write while its server shuts down. Should the new view be closed
+// first, as when its server fails to start, the socket goes back to the
+// previous view. The socket is closed when the last view is closed.
+type sharedPacketConn struct {
+	net.PacketConn
+	packets chan packet
+	closed  chan struct{}
+	err     error
+	mu      sync.Mutex
+	views   []*packetConnView // Open views, the last one reads
+	once    sync.Once
+}
+
+// newSharedPacketConn starts reading packets from conn
+func newSharedPacketConn(conn net.PacketConn) *sharedPacketConn {
+	s := &sharedPacketConn{
+		PacketConn: conn,
+		packets:    make(chan packet),
+		closed:     make(chan struct{}),
+	}
+	go s.readLoop()
+	return s
+}
+
+// readLoop hands packets to the current view
+func (s *sharedPacketConn) readLoop() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := s.PacketConn.ReadFrom(buf)
+		if err != nil {
+			s.close(err)
+			return
+		}
+		select {
+		case s.packets <- packet{data: append([]byte(nil), buf[:n]...), addr: addr}:
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// view returns a packet conn that takes the socket over from the previous
+// view
+func (s *sharedPacketConn) view() (net.PacketConn, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.closed:
+		return nil, false
+	default:
+	}
+	if len(s.views) > 0 {
+		s.views[len(s.views)-1].setSuperseded(true)
+	}
+	view := &packetConnView{
+		shared:  s,
+		done:    make(chan struct{}),
+		changed: make(chan struct{}),
+	}
+	s.views = append(s.views, view)
+	return view, true
+}
+
+// release drops a view, handing the socket back to the previous view if
+// it was reading, and closes the socket after the last one
+func (s *sharedPacketConn) release(view *packetConnView) {
+	s.mu.Lock()
+	reading := s.views[len(s.views)-1] == view
+	s.views = slices.DeleteFunc(s.views, func(v *packetConnView) bool { return v == view })
+	last := len(s.views) == 0
+	if reading && !last {
+		s.views[len(s.views)-1].setSuperseded(false)
+	}
+	s.mu.Unlock()
+
+	if last {
+		s.close(net.ErrClosed)
+	}
+}
+
+// close closes the socket, failing pending and future reads with err
+func (s *sharedPacketConn) close(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.closed)
+		s.PacketConn.Close()
+	})
+}
+
+// packetConnView is one server's handle on a shared packet socket. Read
+// deadlines are kept per view, so they do not disturb the shared reads.
+type packetConnView struct {
+	shared *sharedPacketConn
+	done   chan struct{}
+	once   sync.Once
+
+	mu         sync.Mutex
+	deadline   time.Time
+	superseded bool
+	changed    chan struct{} // Closed when the deadline or superseded changes
+}
+
+// setSuperseded stops or resumes receiving packets on the view
+func (v *packetConnView) setSuperseded(superseded bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.superseded = superseded
+	close(v.changed)
+	v.changed = make(chan struct{})
+}
+
+// ReadFrom waits for the next packet while the view holds the socket
+func (v *packetConnView) ReadFrom(p []byte) (int, net.Addr, error) {
+	for {
+		v.mu.Lock()
+		deadline, superseded, changed := v.deadline, v.superseded, v.changed
+		v.mu.Unlock()
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if !deadline.IsZero() {
+			wait := time.Until(deadline)
+			if wait <= 0 {
+				return 0, nil, os.ErrDeadlineExceeded
+			}
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+
+		// A superseded view waits to be closed or to get the socket back
+		packets := v.shared.packets
+		if superseded {
+			packets = nil
+		}
+
+		var (
+			n    int
+			addr net.Addr
+			err  error
+			done = true
+		)
+		select {
+		case pkt := <-packets:
+			n, addr = copy(p, pkt.data), pkt.addr
+		case <-v.done:
+			err = net.ErrClosed
+		case <-v.shared.closed:
+			err = v.shared.err
+		case <-timeout:
+			err = os.ErrDeadlineExceeded
+		case <-changed:
+			done = false
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if done {
+			return n, addr, err
+		}
+	}
+}
+
+// WriteTo writes through the shared socket
+func (v *packetConnView) WriteTo(p []byte, addr net.Addr) (int, error) {
+	select {
+	case <-v.done:
+		return 0, net.ErrClosed
+	default:
+	}
+	return v.shared.PacketConn.WriteTo(p, addr)
+}
+
+// Close stops this view; the socket stays open for other views
+func (v *packetConnView) Close() error {
+	v.once.Do(func() {
+		close(v.done)
+		v.shared.release(v)
+	})
+	return nil
+}
+
+// LocalAddr returns the shared socket address
+func (v *packetConnView) LocalAddr() net.Addr {
+	return v.shared.LocalAddr()
+}
+
+// SetDeadline sets the read deadline of the view; write deadlines would
+// apply to every view, so they are not supported
+func (v *packetConnView) SetDeadline(t time.Time) error {
+	return v.SetReadDeadline(t)
+}
+
+// SetReadDeadline sets the read deadline of the view
+func (v *packetConnView) SetReadDeadline(t time.Time) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.deadline = t
+	close(v.changed)
+	v.changed = make(chan struct{})
+	return nil
+}
+
+// SetWriteDeadline is a no-op, see SetDeadline
+func (v *packetConnView) SetWriteDeadline(t time.Time) error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSharedPacketConnHandover(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := newSharedPacketConn(conn)
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	read := func(view net.PacketConn) (string, error) {
+		view.SetReadDeadline(time.Now().Add(2 * time.Second))
+		buf := make([]byte, 64)
+		n, _, err := view.ReadFrom(buf)
+		return string(buf[:n]), err
+	}
+
+	old, _ := shared.view()
+	client.Write([]byte("first"))
+	if got, err := read(old); err != nil || got != "first" {
+		t.Fatalf("Old view read %q, %v", got, err)
+	}
+
+	// The new view takes the socket over; the old one stops receiving
+	next, _ := shared.view()
+	oldErr := make(chan error, 1)
+	go func() {
+		old.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+		_, _, err := old.ReadFrom(make([]byte, 64))
+		oldErr <- err
+	}()
+	client.Write([]byte("second"))
+	if got, err := read(next); err != nil || got != "second" {
+		t.Fatalf("New view read %q, %v", got, err)
+	}
+	if err := <-oldErr; !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Expected the old view to time out, got %v", err)
+	}
+
+	// Closing the old view keeps the socket bound for the new one
+	old.Close()
+	client.Write([]byte("third"))
+	if got, err := read(next); err != nil || got != "third" {
+		t.Fatalf("New view read %q after the old view closed, %v", got, err)
+	}
+
+	next.Close()
+	rebound, err := net.ListenPacket("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Expected the socket to be closed with the last view: %v", err)
+	}
+	rebound.Close()
+}
+
+func TestSharedPacketConnHandBack(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := newSharedPacketConn(conn)
+	defer shared.close(net.ErrClosed)
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// A view closed before the one it superseded, as by a server that
+	// failed to start, hands the socket back
+	old, _ := shared.view()
+	next, _ := shared.view()
+	received := make(chan string, 1)
+	go func() {
+		old.SetReadDeadline(time.Now().Add(2 * time.Second))
+		buf := make([]byte, 64)
+		n, _, _ := old.ReadFrom(buf)
+		received <- string(buf[:n])
+	}()
+	next.Close()
+	client.Write([]byte("back"))
+	if got := <-received; got != "back" {
+		t.Fatalf("Old view read %q, want the packet sent after the new view closed", got)
+	}
+}
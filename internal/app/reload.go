@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"gateway/internal/config"
+	"gateway/internal/management"
+)
+
+// ErrRestartRequired is returned by Reload when the new configuration
+// changes the frontend listeners, which needs a new server
+var ErrRestartRequired = errors.New("frontend configuration changed, restart required")
+
+// Reload applies cfg to the running server without rebinding its frontend
+// listeners. The handler chains, router and registry are rebuilt from cfg
+// and swapped in behind the running adapters. Requests in flight on the
+// previous handlers complete before the previous components are closed; SSE
+// streams they serve are flushed and ended, open WebSocket and TCP
+// connections are kept. The metrics server and management API are handed
+// over on their listeners.
+//
+// When the HTTP, WebSocket or TCP frontend settings change, Reload returns
+// ErrRestartRequired and leaves the server unchanged. Reload must not run
+// concurrently with Start or Stop.
+func (s *Server) Reload(cfg *config.Config) error {
+	if !sameFrontend(s.config.Gateway.Frontend, cfg.Gateway.Frontend) {
+		return ErrRestartRequired
+	}
+
+	next, err := NewServer(cfg, s.logger)
+	if err != nil {
+		return err
+	}
+
+	// Hand the auxiliary servers and the drain and maintenance state over
+	// before serving with the new handlers
+	next.InheritListeners(s)
+	if err := next.startAuxiliary(s.runCtx); err != nil {
+		stopCtx, cancel := context.WithTimeout(context.Background(), next.DrainTimeout())
+		defer cancel()
+		if stopErr := next.Stop(stopCtx); stopErr != nil {
+			s.logger.Error("Failed to stop server after reload error", "error", stopErr)
+		}
+		next.releaseListeners()
+		return err
+	}
+
+	previousHandlers := s.httpAdapter.Swap(next.httpAdapter)
+	if s.wsAdapter != nil {
+		s.wsAdapter.Swap(next.wsAdapter)
+		// Open connections stay on the running adapter
+		if api, ok := next.managementAPI.(*management.API); ok {
+			api.SetWebSocketConnections(s.wsAdapter)
+		}
+	}
+	if s.tcpAdapter != nil {
+		s.tcpAdapter.Swap(next.tcpAdapter)
+	}
+
+	previous := &Server{
+		config:         s.config,
+		metricsServer:  s.metricsServer,
+		managementAPI:  s.managementAPI,
+		router:         s.router,
+		registry:       s.registry,
+		telemetry:      s.telemetry,
+		backendMonitor: s.backendMonitor,
+		auditSink:      s.auditSink,
+		openAPI:        s.openAPI,
+		grpcConnector:  s.grpcConnector,
+		wsConnector:    s.wsConnector,
+		authProviders:  s.authProviders,
+		idempotency:    s.idempotency,
+		logger:         s.logger,
+	}
+
+	s.listenersMu.Lock()
+	s.config = cfg
+	s.metricsServer = next.metricsServer
+	s.managementAPI = next.managementAPI
+	s.router = next.router
+	s.registry = next.registry
+	s.telemetry = next.telemetry
+	s.backendMonitor = next.backendMonitor
+	s.drainRegistry = next.drainRegistry
+	s.maintenance = next.maintenance
+	s.auditSink = next.auditSink
+	s.openAPI = next.openAPI
+	s.grpcConnector = next.grpcConnector
+	s.wsConnector = next.wsConnector
+	s.authProviders = next.authProviders
+	s.idempotency = next.idempotency
+	for address, listener := range next.listeners {
+		s.listeners[address] = listener
+	}
+	s.listenersMu.Unlock()
+
+	// Drain the previous handlers and auxiliary servers, then close the
+	// components they used
+	drainCtx, cancel := context.WithTimeout(context.Background(), s.DrainTimeout())
+	defer cancel()
+	if err := previousHandlers.Drain(drainCtx); err != nil {
+		s.logger.Warn("Previous handlers not drained", "error", err)
+	}
+	if previous.metricsServer != nil {
+		if err := previous.metricsServer.Shutdown(drainCtx); err != nil {
+			s.logger.Warn("Previous metrics server not drained", "error", err)
+		}
+	}
+	if previous.managementAPI != nil {
+		if err := previous.managementAPI.Stop(drainCtx); err != nil {
+			s.logger.Warn("Previous management API not drained", "error", err)
+		}
+	}
+	for _, err := range previous.closeComponents(context.Background()) {
+		s.logger.Error("Failed to close previous component", "error", err)
+	}
+
+	s.logger.Info("Gateway reloaded")
+	return nil
+}
+
+// startAuxiliary starts the metrics server and management API of a server
+// replacing the handlers of a running one
+func (s *Server) startAuxiliary(ctx context.Context) error {
+	s.useListenFunc()
+
+	if s.metricsServer != nil {
+		listener, err := s.listenMetrics()
+		if err != nil {
+			return fmt.Errorf("metrics server: %w", err)
+		}
+		go func() {
+			if err := s.metricsServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+				s.logger.Error("Metrics server error", "error", err)
+			}
+		}()
+	}
+
+	if s.managementAPI != nil {
+		if err := s.managementAPI.Start(ctx); err != nil {
+			return fmt.Errorf("management API: %w", err)
+		}
+	}
+	return nil
+}
+
+// sameFrontend reports whether two frontend configurations bind the same
+// listeners with the same settings
+func sameFrontend(a, b config.Frontend) bool {
+	return reflect.DeepEqual(a.HTTP, b.HTTP) &&
+		reflect.DeepEqual(a.WebSocket, b.WebSocket) &&
+		reflect.DeepEqual(a.TCP, b.TCP) &&
+		a.ReusePort == b.ReusePort &&
+		slices.Equal(a.TrustedProxies, b.TrustedProxies) &&
+		a.XFFDepth == b.XFFDepth &&
+		a.ForwardedHeader == b.ForwardedHeader
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gateway/internal/config"
+	"gateway/internal/middleware/maintenance"
+)
+
+func TestServer_Reload(t *testing.T) {
+	release := make(chan struct{})
+	oldBackend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte("old"))
+	}))
+	defer oldBackend.Close()
+	newBackend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("new"))
+	}))
+	defer newBackend.Close()
+
+	httpPort := findAvailablePort(t)
+	url := fmt.Sprintf("http://localhost:%d/api/test", httpPort)
+
+	server, err := NewServer(proxyConfig(t, httpPort, oldBackend), slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop(context.Background())
+
+	// Request in flight on the previous handlers during the reload
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	inFlight := make(chan result, 1)
+	go func() {
+		status, body, err := get(url)
+		inFlight <- result{status, body, err}
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	previousProviders := server.authProviders
+	reloaded := make(chan error, 1)
+	go func() {
+		reloaded <- server.Reload(proxyConfig(t, httpPort, newBackend))
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// New requests reach the new routes while the previous handlers drain
+	status, body, err := get(url)
+	if err != nil || status != http.StatusOK || body != "new" {
+		t.Errorf("Expected new route response, got %d %q %v", status, body, err)
+	}
+
+	select {
+	case err := <-reloaded:
+		t.Fatalf("Reload returned before in-flight request completed: %v", err)
+	default:
+	}
+
+	close(release)
+	r := <-inFlight
+	if r.err != nil || r.status != http.StatusOK || r.body != "old" {
+		t.Errorf("Expected in-flight request to complete, got %d %q %v", r.status, r.body, r.err)
+	}
+	if err := <-reloaded; err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if server.authProviders == previousProviders {
+		t.Error("Expected the auth providers to be replaced")
+	}
+
+	status, body, err = get(url)
+	if err != nil || body != "new" {
+		t.Errorf("Expected new route response after reload, got %d %q %v", status, body, err)
+	}
+
+	// Maintenance mode survives reloads
+	server.maintenance.Enable(maintenance.State{})
+	cfg := proxyConfig(t, httpPort, newBackend)
+	cfg.Gateway.Router.Rules[0].Idempotency = &config.RouteIdempotency{}
+	if err := server.Reload(cfg); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if server.idempotency == nil {
+		t.Error("Expected the idempotency store of the new configuration")
+	}
+	if status, _, err := get(url); err != nil || status != http.StatusServiceUnavailable {
+		t.Errorf("Expected maintenance response after reload, got %d %v", status, err)
+	}
+	server.maintenance.Disable()
+	if status, _, err := get(url); err != nil || status != http.StatusOK {
+		t.Errorf("Expected response after leaving maintenance, got %d %v", status, err)
+	}
+}
+
+func TestServer_ReloadRestartRequired(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	httpPort := findAvailablePort(t)
+	server, err := NewServer(proxyConfig(t, httpPort, backend), slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop(context.Background())
+
+	err = server.Reload(proxyConfig(t, findAvailablePort(t), backend))
+	if !errors.Is(err, ErrRestartRequired) {
+		t.Fatalf("Expected ErrRestartRequired, got %v", err)
+	}
+
+	// The server keeps serving the previous configuration
+	status, body, err := get(fmt.Sprintf("http://localhost:%d/api/test", httpPort))
+	if err != nil || status != http.StatusOK || body != "ok" {
+		t.Errorf("Expected response from unchanged server, got %d %q %v", status, body, err)
+	}
+}
+
+func TestServer_InheritListenersKeepsManagementState(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer backend.Close()
+
+	previous, err := NewServer(proxyConfig(t, findAvailablePort(t), backend), slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	previous.maintenance.Enable(maintenance.State{})
+	if err := previous.drainRegistry.Drain("test-1"); err != nil {
+		t.Fatalf("Failed to drain instance: %v", err)
+	}
+
+	// A server replacing previous after a frontend change keeps its state
+	next, err := NewServer(proxyConfig(t, findAvailablePort(t), backend), slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	next.InheritListeners(previous)
+	if next.maintenance.State() == nil {
+		t.Error("Expected maintenance mode to be kept")
+	}
+	if !next.drainRegistry.IsDrained("test-1") {
+		t.Error("Expected the drained instance to stay drained")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// reusePortControl reports that SO_REUSEPORT is not available
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	return fmt.Errorf("reusePort: %w on this platform", errors.ErrUnsupported)
+}
@@ -3,14 +3,20 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"os"
 	"sync"
 	"time"
 
 	httpAdapter "gateway/internal/adapter/http"
+	tcpAdapter "gateway/internal/adapter/tcp"
 	wsAdapter "gateway/internal/adapter/websocket"
 	"gateway/internal/config"
+	"gateway/internal/middleware/maintenance"
+	"gateway/internal/registry"
 )
 
 // Server represents the gateway server
@@ -18,21 +24,63 @@ type Server struct {
 	config         *config.Config
 	httpAdapter    *httpAdapter.Adapter
 	wsAdapter      *wsAdapter.Adapter
+	tcpAdapter     *tcpAdapter.Adapter
 	metricsServer  *http.Server
 	managementAPI  interface{ Start(context.Context) error; Stop(context.Context) error } // Management API
 	router         interface{ Close() error } // Router with Close method
 	registry       interface{ Close() error } // Registry with Close method
 	telemetry      interface{ Shutdown(context.Context) error } // Telemetry with Shutdown method
 	backendMonitor interface{ Stop() error } // Backend monitor with Stop method
+	drainRegistry  *registry.DrainRegistry   // Administratively drained instances
+	maintenance    *maintenance.Switch        // Maintenance mode
+	auditSink      interface{ Close() error } // Audit event sink
+	openAPI        interface{ Stop() error }  // OpenAPI route manager
+	grpcConnector  interface{ Close() error } // gRPC backend connections
+	wsConnector    interface{ Close() error } // Pooled WebSocket backend connections
+	authProviders  interface{ Close() error } // Authentication providers
+	idempotency    interface{ Close() error } // Idempotency response store
 	logger         *slog.Logger
+
+	// Listeners bound by this server and those inherited from the server
+	// it replaces on reload
+	listenersMu sync.Mutex
+	listeners   map[string]*sharedListener
+	inherited   map[string]*sharedListener
+	runCtx      context.Context
+	cancelRun   context.CancelFunc
+
+	// UDP sockets, such as HTTP/3's, handed over like the listeners
+	packetConns          map[string]*sharedPacketConn
+	inheritedPacketConns map[string]*sharedPacketConn
+
+	// Views handed to the adapters, closed by releaseListeners
+	views    []io.Closer
+	released bool
 }
 
+// DefaultDrainTimeout is the default grace period for draining connections
+const DefaultDrainTimeout = 30 * time.Second
+
 // NewServer creates a new gateway server
 func NewServer(cfg *config.Config, logger *slog.Logger) (*Server, error) {
 	builder := NewBuilder(cfg, logger)
 	return builder.Build()
 }
 
+// Validate builds a server for cfg without binding any listeners and
+// releases it again, reporting configuration errors that only show when the
+// components are constructed
+func Validate(cfg *config.Config, logger *slog.Logger) error {
+	server, err := NewServer(cfg, logger)
+	if err != nil {
+		return err
+	}
+	for _, err := range server.closeComponents(context.Background()) {
+		logger.Warn("Failed to release component after validation", "error", err)
+	}
+	return nil
+}
+
 // Start starts the gateway server
 //
 // This method is non-blocking and returns after all adapters have been successfully started.
@@ -69,10 +117,17 @@ func (s *Server) Start(ctx context.Context) error {
 	startupCtx, cancelStartup := context.WithCancel(ctx)
 	// DO NOT defer cancelStartup() here - it should only be called on error paths
 
+	// Adapters run with a context that outlives startup and ctx cancellation;
+	// it is canceled by Stop once connections have drained
+	runCtx, cancelRun := context.WithCancel(context.WithoutCancel(ctx))
+	s.runCtx, s.cancelRun = runCtx, cancelRun
+
+	s.useListenFunc()
+
 	// Channel to collect startup errors
-	errCh := make(chan error, 3)
+	errCh := make(chan error, 5)
 	// Channel to signal successful starts
-	startedCh := make(chan struct{}, 3)
+	startedCh := make(chan struct{}, 5)
 	expectedStarts := 1 // HTTP adapter always starts
 
 	// Start HTTP adapter
@@ -81,7 +136,7 @@ func (s *Server) Start(ctx context.Context) error {
 			"host", s.config.Gateway.Frontend.HTTP.Host,
 			"port", s.config.Gateway.Frontend.HTTP.Port,
 		)
-		if err := s.httpAdapter.Start(startupCtx); err != nil {
+		if err := s.httpAdapter.Start(runCtx); err != nil {
 			errCh <- fmt.Errorf("HTTP server: %w", err)
 		} else {
 			startedCh <- struct{}{}
@@ -96,7 +151,7 @@ func (s *Server) Start(ctx context.Context) error {
 				"host", s.config.Gateway.Frontend.WebSocket.Host,
 				"port", s.config.Gateway.Frontend.WebSocket.Port,
 			)
-			if err := s.wsAdapter.Start(startupCtx); err != nil {
+			if err := s.wsAdapter.Start(runCtx); err != nil {
 				errCh <- fmt.Errorf("WebSocket server: %w", err)
 			} else {
 				startedCh <- struct{}{}
@@ -104,6 +159,22 @@ func (s *Server) Start(ctx context.Context) error {
 		}()
 	}
 
+	// Start TCP adapter if enabled
+	if s.tcpAdapter != nil {
+		expectedStarts++
+		go func() {
+			s.logger.Info("Starting TCP server",
+				"host", s.config.Gateway.Frontend.TCP.Host,
+				"port", s.config.Gateway.Frontend.TCP.Port,
+			)
+			if err := s.tcpAdapter.Start(runCtx); err != nil {
+				errCh <- fmt.Errorf("TCP server: %w", err)
+			} else {
+				startedCh <- struct{}{}
+			}
+		}()
+	}
+
 	// Start metrics server if enabled on separate port
 	if s.metricsServer != nil {
 		expectedStarts++
@@ -111,10 +182,15 @@ func (s *Server) Start(ctx context.Context) error {
 			s.logger.Info("Starting metrics server",
 				"address", s.metricsServer.Addr,
 			)
-			// Signal that we're starting (since ListenAndServe blocks)
+			listener, err := s.listenMetrics()
+			if err != nil {
+				errCh <- fmt.Errorf("metrics server: %w", err)
+				return
+			}
+			// Signal that we're starting (since Serve blocks)
 			startedCh <- struct{}{}
-			// ListenAndServe blocks until shutdown
-			if err := s.metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			// Serve blocks until shutdown
+			if err := s.metricsServer.Serve(listener); err != nil && err != http.ErrServerClosed {
 				// Only report real errors, not expected server closed
 				select {
 				case errCh <- fmt.Errorf("metrics server: %w", err):
@@ -130,7 +206,7 @@ func (s *Server) Start(ctx context.Context) error {
 		expectedStarts++
 		go func() {
 			s.logger.Info("Starting management API")
-			if err := s.managementAPI.Start(startupCtx); err != nil {
+			if err := s.managementAPI.Start(runCtx); err != nil {
 				errCh <- fmt.Errorf("management API: %w", err)
 			} else {
 				startedCh <- struct{}{}
@@ -156,6 +232,7 @@ func (s *Server) Start(ctx context.Context) error {
 			if stopErr := s.Stop(stopCtx); stopErr != nil {
 				s.logger.Error("Failed to stop server after startup error", "error", stopErr)
 			}
+			s.releaseListeners()
 
 			return err
 		case <-startedCh:
@@ -170,10 +247,13 @@ func (s *Server) Start(ctx context.Context) error {
 			if stopErr := s.Stop(stopCtx); stopErr != nil {
 				s.logger.Error("Failed to stop server after timeout", "error", stopErr)
 			}
+			s.releaseListeners()
 
 			return fmt.Errorf("timeout waiting for adapters to start")
 		case <-ctx.Done():
 			cancelStartup()
+			cancelRun()
+			s.releaseListeners()
 			return ctx.Err()
 		}
 	}
@@ -187,16 +267,25 @@ func (s *Server) Start(ctx context.Context) error {
 }
 
 // Stop stops the gateway server
+//
+// Frontends are drained first: listeners stop accepting, in-flight requests
+// complete, SSE streams are flushed and ended, WebSocket clients are sent a
+// going-away close frame, and proxied TCP connections are left to finish.
+// Connections still open after the drain timeout are closed. Routers, registries and telemetry are shut down afterwards so
+// draining connections keep their backends.
 func (s *Server) Stop(ctx context.Context) error {
 	var wg sync.WaitGroup
 	var errs []error
 	errMu := sync.Mutex{}
 
+	drainCtx, cancelDrain := context.WithTimeout(ctx, s.DrainTimeout())
+	defer cancelDrain()
+
 	// Stop HTTP adapter
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := s.httpAdapter.Stop(ctx); err != nil {
+		if err := s.httpAdapter.Stop(drainCtx); err != nil {
 			errMu.Lock()
 			errs = append(errs, fmt.Errorf("stopping HTTP server: %w", err))
 			errMu.Unlock()
@@ -208,7 +297,7 @@ func (s *Server) Stop(ctx context.Context) error {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := s.wsAdapter.Stop(ctx); err != nil {
+			if err := s.wsAdapter.Stop(drainCtx); err != nil {
 				errMu.Lock()
 				errs = append(errs, fmt.Errorf("stopping WebSocket server: %w", err))
 				errMu.Unlock()
@@ -216,12 +305,25 @@ func (s *Server) Stop(ctx context.Context) error {
 		}()
 	}
 
+	// Stop TCP adapter if running
+	if s.tcpAdapter != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.tcpAdapter.Stop(drainCtx); err != nil {
+				errMu.Lock()
+				errs = append(errs, fmt.Errorf("stopping TCP server: %w", err))
+				errMu.Unlock()
+			}
+		}()
+	}
+
 	// Stop metrics server if running
 	if s.metricsServer != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := s.metricsServer.Shutdown(ctx); err != nil {
+			if err := s.metricsServer.Shutdown(drainCtx); err != nil {
 				errMu.Lock()
 				errs = append(errs, fmt.Errorf("stopping metrics server: %w", err))
 				errMu.Unlock()
@@ -234,7 +336,7 @@ func (s *Server) Stop(ctx context.Context) error {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := s.managementAPI.Stop(ctx); err != nil {
+			if err := s.managementAPI.Stop(drainCtx); err != nil {
 				errMu.Lock()
 				errs = append(errs, fmt.Errorf("stopping management API: %w", err))
 				errMu.Unlock()
@@ -242,6 +344,41 @@ func (s *Server) Stop(ctx context.Context) error {
 		}()
 	}
 
+	wg.Wait()
+
+	// Release everything bound to the server lifetime
+	if s.cancelRun != nil {
+		s.cancelRun()
+	}
+
+	errs = append(errs, s.closeComponents(ctx)...)
+
+	if len(errs) > 0 {
+		// Wrap at least one error for better error chain
+		if len(errs) == 1 {
+			return errs[0]
+		}
+		return fmt.Errorf("multiple errors during shutdown: %v", errs)
+	}
+
+	s.logger.Info("Gateway stopped successfully")
+	return nil
+}
+
+// closeComponents closes the router, registry and gRPC backend connections,
+// shuts down telemetry and stops the backend monitor
+func (s *Server) closeComponents(ctx context.Context) []error {
+	var wg sync.WaitGroup
+	var errs []error
+	errMu := sync.Mutex{}
+
+	// Stop generating routes before the router is closed
+	if s.openAPI != nil {
+		if err := s.openAPI.Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("stopping OpenAPI manager: %w", err))
+		}
+	}
+
 	// Close router if it has a Close method
 	if s.router != nil {
 		wg.Add(1)
@@ -284,6 +421,71 @@ func (s *Server) Stop(ctx context.Context) error {
 		}
 	}
 
+	// Close gRPC backend connections
+	if s.grpcConnector != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.grpcConnector.Close(); err != nil {
+				errMu.Lock()
+				errs = append(errs, fmt.Errorf("closing gRPC connector: %w", err))
+				errMu.Unlock()
+			}
+		}()
+	}
+
+	// Close idle WebSocket backend connections
+	if s.wsConnector != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.wsConnector.Close(); err != nil {
+				errMu.Lock()
+				errs = append(errs, fmt.Errorf("closing WebSocket connector: %w", err))
+				errMu.Unlock()
+			}
+		}()
+	}
+
+	// Stop background work of authentication providers
+	if s.authProviders != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.authProviders.Close(); err != nil {
+				errMu.Lock()
+				errs = append(errs, fmt.Errorf("closing auth providers: %w", err))
+				errMu.Unlock()
+			}
+		}()
+	}
+
+	// Close the idempotency store if it exists
+	if s.idempotency != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.idempotency.Close(); err != nil {
+				errMu.Lock()
+				errs = append(errs, fmt.Errorf("closing idempotency store: %w", err))
+				errMu.Unlock()
+			}
+		}()
+	}
+
+	// Close audit sink if it exists
+	if s.auditSink != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.auditSink.Close(); err != nil {
+				errMu.Lock()
+				errs = append(errs, fmt.Errorf("closing audit sink: %w", err))
+				errMu.Unlock()
+			}
+		}()
+	}
+
 	// Stop backend monitor if it exists
 	if s.backendMonitor != nil {
 		// Type assert outside goroutine to avoid nil interface dereference
@@ -301,15 +503,197 @@ func (s *Server) Stop(ctx context.Context) error {
 	}
 
 	wg.Wait()
+	return errs
+}
 
-	if len(errs) > 0 {
-		// Wrap at least one error for better error chain
-		if len(errs) == 1 {
-			return errs[0]
+// DrainTimeout returns the grace period for draining connections on Stop
+func (s *Server) DrainTimeout() time.Duration {
+	if s.config.Gateway.Frontend.DrainTimeout > 0 {
+		return time.Duration(s.config.Gateway.Frontend.DrainTimeout) * time.Second
+	}
+	return DefaultDrainTimeout
+}
+
+// InheritListeners lets the server take over the listeners of the server it
+// replaces. Addresses bound by previous are served through the same sockets,
+// so a reload never unbinds a port; previous stops accepting on them when it
+// is stopped. Instances drained and maintenance mode switched on through the
+// management API stay so. It must be called before Start.
+func (s *Server) InheritListeners(previous *Server) {
+	if previous.drainRegistry != nil && s.drainRegistry != nil {
+		s.drainRegistry.Restore(previous.drainRegistry.Drained())
+	}
+	if previous.maintenance != nil && s.maintenance != nil {
+		s.maintenance.Restore(previous.maintenance)
+	}
+
+	previous.listenersMu.Lock()
+	defer previous.listenersMu.Unlock()
+
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.inherited = make(map[string]*sharedListener, len(previous.listeners))
+	for address, listener := range previous.listeners {
+		s.inherited[address] = listener
+	}
+	s.inheritedPacketConns = make(map[string]*sharedPacketConn, len(previous.packetConns))
+	for address, conn := range previous.packetConns {
+		s.inheritedPacketConns[address] = conn
+	}
+}
+
+// useListenFunc makes the adapters bind through the server so listeners can
+// be handed over on reload
+func (s *Server) useListenFunc() {
+	s.httpAdapter.WithListenFunc(s.listen).WithListenPacketFunc(s.listenPacket)
+	if s.wsAdapter != nil {
+		s.wsAdapter.WithListenFunc(s.listen)
+	}
+	if s.tcpAdapter != nil {
+		s.tcpAdapter.WithListenFunc(s.listen)
+	}
+	if api, ok := s.managementAPI.(interface {
+		SetListenFunc(func(network, address string) (net.Listener, error))
+	}); ok {
+		api.SetListenFunc(s.listen)
+	}
+}
+
+// listen binds address, reusing an inherited listener when there is one
+func (s *Server) listen(network, address string) (net.Listener, error) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+
+	if s.released {
+		return nil, net.ErrClosed
+	}
+	if s.listeners == nil {
+		s.listeners = make(map[string]*sharedListener)
+	}
+
+	if shared, ok := s.inherited[address]; ok {
+		delete(s.inherited, address)
+		if view, ok := shared.view(); ok {
+			s.listeners[address] = shared
+			s.views = append(s.views, view)
+			s.logger.Info("Serving on inherited listener", "address", address)
+			return view, nil
 		}
-		return fmt.Errorf("multiple errors during shutdown: %v", errs)
 	}
 
-	s.logger.Info("Gateway stopped successfully")
-	return nil
+	if network == "unix" {
+		removeStaleSocket(address)
+	}
+
+	var lc net.ListenConfig
+	if s.config.Gateway.Frontend.ReusePort && network != "unix" {
+		lc.Control = reusePortControl
+	}
+	listener, err := lc.Listen(context.Background(), network, address)
+	if err != nil {
+		return nil, err
+	}
+	shared := newSharedListener(listener)
+	view, _ := shared.view()
+	s.views = append(s.views, view)
+
+	// Ephemeral ports are not handed over, a replacement binds its own
+	if _, port, err := net.SplitHostPort(address); network == "unix" || (err == nil && port != "0") {
+		s.listeners[address] = shared
+	}
+	return view, nil
+}
+
+// listenPacket binds a UDP address, taking over an inherited socket when
+// there is one. The server it was inherited from stops receiving on it.
+func (s *Server) listenPacket(network, address string) (net.PacketConn, error) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+
+	if s.released {
+		return nil, net.ErrClosed
+	}
+	if s.packetConns == nil {
+		s.packetConns = make(map[string]*sharedPacketConn)
+	}
+
+	if shared, ok := s.inheritedPacketConns[address]; ok {
+		delete(s.inheritedPacketConns, address)
+		if view, ok := shared.view(); ok {
+			s.packetConns[address] = shared
+			s.views = append(s.views, view)
+			s.logger.Info("Serving on inherited packet socket", "address", address)
+			return view, nil
+		}
+	}
+
+	var lc net.ListenConfig
+	if s.config.Gateway.Frontend.ReusePort {
+		lc.Control = reusePortControl
+	}
+	conn, err := lc.ListenPacket(context.Background(), network, address)
+	if err != nil {
+		return nil, err
+	}
+	shared := newSharedPacketConn(conn)
+	view, _ := shared.view()
+	s.views = append(s.views, view)
+
+	// Ephemeral ports are not handed over, a replacement binds its own
+	if _, port, err := net.SplitHostPort(address); err == nil && port != "0" {
+		s.packetConns[address] = shared
+	}
+	return view, nil
+}
+
+// releaseListeners closes every listener and packet socket the server took,
+// for a server that failed to start. Sockets inherited on reload go back to
+// the server it was to replace, its own are unbound, and adapters still
+// starting can no longer bind.
+func (s *Server) releaseListeners() {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+
+	s.released = true
+	for _, view := range s.views {
+		view.Close()
+	}
+	s.views = nil
+}
+
+// listenMetrics binds the metrics server, on a Unix socket if configured
+func (s *Server) listenMetrics() (net.Listener, error) {
+	m := s.config.Gateway.Metrics
+	if m == nil || m.UnixSocket == "" {
+		return s.listen("tcp", s.metricsServer.Addr)
+	}
+
+	mode, err := config.ParseSocketMode(m.SocketMode)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := s.listen("unix", m.UnixSocket)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(m.UnixSocket, mode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set permissions of %s: %w", m.UnixSocket, err)
+	}
+	return listener, nil
+}
+
+// removeStaleSocket removes a Unix socket file left behind by a process
+// that exited without closing its listener. Sockets something still
+// accepts on are kept, so binding them fails.
+func removeStaleSocket(path string) {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return
+	}
+	os.Remove(path)
 }
@@ -2,12 +2,23 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"gateway/internal/config"
+	"gateway/internal/core"
+	pluginMiddleware "gateway/pkg/middleware"
 	"log/slog"
 )
 
@@ -412,7 +423,6 @@ func TestServer_StartupFailureRollback(t *testing.T) {
 }
 
 func TestServer_LifecycleIntegration(t *testing.T) {
-	t.Skip("Skipping lifecycle integration test - flaky WebSocket server in test environment")
 	// Find available ports
 	httpPort := findAvailablePort(t)
 	wsPort := findAvailablePort(t)
@@ -538,6 +548,331 @@ func TestServer_LifecycleIntegration(t *testing.T) {
 	}
 }
 
+// proxyConfig routes /api/* on httpPort to the backend server
+func proxyConfig(t *testing.T, httpPort int, backend *httptest.Server) *config.Config {
+	t.Helper()
+	host, portStr, _ := net.SplitHostPort(backend.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	return &config.Config{
+		Gateway: config.Gateway{
+			Frontend: config.Frontend{
+				HTTP: config.HTTP{
+					Host:         "localhost",
+					Port:         httpPort,
+					ReadTimeout:  5,
+					WriteTimeout: 5,
+				},
+				DrainTimeout: 5,
+			},
+			Backend: config.Backend{
+				HTTP: config.HTTPBackend{
+					MaxIdleConns: 10,
+				},
+			},
+			Registry: config.Registry{
+				Type: "static",
+				Static: &config.StaticRegistry{
+					Services: []config.Service{
+						{
+							Name: "test-service",
+							Instances: []config.Instance{
+								{
+									ID:      "test-1",
+									Address: host,
+									Port:    port,
+									Health:  "healthy",
+								},
+							},
+						},
+					},
+				},
+			},
+			Router: config.Router{
+				Rules: []config.RouteRule{
+					{
+						ID:          "test-route",
+						Path:        "/api/*",
+						ServiceName: "test-service",
+					},
+				},
+			},
+		},
+	}
+}
+
+// get fetches url and returns the status and body
+func get(url string) (int, string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), err
+}
+
+func TestServer_ProxiesAfterStartup(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	httpPort := findAvailablePort(t)
+	server, err := NewServer(proxyConfig(t, httpPort, backend), slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	// Requests must not inherit the startup context, which ends with Start
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := server.Start(ctx); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop(context.Background())
+
+	status, body, err := get(fmt.Sprintf("http://localhost:%d/api/test", httpPort))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if status != http.StatusOK || body != "ok" {
+		t.Errorf("Expected 200 ok, got %d %q", status, body)
+	}
+}
+
+func TestServer_InheritListeners(t *testing.T) {
+	release := make(chan struct{})
+	oldBackend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte("old"))
+	}))
+	defer oldBackend.Close()
+	newBackend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("new"))
+	}))
+	defer newBackend.Close()
+
+	httpPort := findAvailablePort(t)
+	url := fmt.Sprintf("http://localhost:%d/api/test", httpPort)
+
+	oldServer, err := NewServer(proxyConfig(t, httpPort, oldBackend), slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := oldServer.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	// Request in flight on the old server during the reload
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	inFlight := make(chan result, 1)
+	go func() {
+		status, body, err := get(url)
+		inFlight <- result{status, body, err}
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// The new server binds the same port while the old one still serves it
+	newServer, err := NewServer(proxyConfig(t, httpPort, newBackend), slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	newServer.InheritListeners(oldServer)
+	if err := newServer.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start new server on inherited listener: %v", err)
+	}
+	defer newServer.Stop(context.Background())
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- oldServer.Stop(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// The old server drains while new requests reach the new server
+	status, body, err := get(url)
+	if err != nil || status != http.StatusOK || body != "new" {
+		t.Errorf("Expected new server response, got %d %q %v", status, body, err)
+	}
+
+	close(release)
+	r := <-inFlight
+	if r.err != nil || r.status != http.StatusOK || r.body != "old" {
+		t.Errorf("Expected in-flight request to complete, got %d %q %v", r.status, r.body, r.err)
+	}
+	if err := <-stopped; err != nil {
+		t.Errorf("Failed to stop old server: %v", err)
+	}
+
+	// The port stays bound after the old server has stopped
+	status, body, err = get(url)
+	if err != nil || body != "new" {
+		t.Errorf("Expected new server response after drain, got %d %q %v", status, body, err)
+	}
+}
+func TestServer_ReleaseListenersAfterFailedStart(t *testing.T) {
+	address := fmt.Sprintf("127.0.0.1:%d", findAvailablePort(t))
+	previous := &Server{config: &config.Config{}, logger: slog.Default()}
+	listener, err := previous.listen("tcp", address)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	// A replacement takes a view of the listener, then fails to start
+	next := &Server{config: &config.Config{}, logger: slog.Default()}
+	next.InheritListeners(previous)
+	if _, err := next.listen("tcp", address); err != nil {
+		t.Fatalf("Failed to inherit listener: %v", err)
+	}
+	next.releaseListeners()
+	if _, err := next.listen("tcp", "127.0.0.1:0"); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Expected a released server not to bind, got %v", err)
+	}
+
+	// The previous server keeps accepting
+	go func() {
+		if conn, err := net.Dial("tcp", address); err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Expected the previous server to keep accepting: %v", err)
+	}
+	conn.Close()
+
+	// And the port is unbound with its last view
+	listener.Close()
+	rebound, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Fatalf("Expected the port to be released: %v", err)
+	}
+	rebound.Close()
+}
+
+func TestServer_ReusePort(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	// Two processes binding the same port, as during a restart
+	httpPort := findAvailablePort(t)
+	var servers []*Server
+	for i := 0; i < 2; i++ {
+		cfg := proxyConfig(t, httpPort, backend)
+		cfg.Gateway.Frontend.ReusePort = true
+		server, err := NewServer(cfg, slog.Default())
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+		if err := server.Start(context.Background()); err != nil {
+			if errors.Is(err, errors.ErrUnsupported) {
+				t.Skip("SO_REUSEPORT not supported")
+			}
+			t.Fatalf("Failed to start server %d: %v", i, err)
+		}
+		servers = append(servers, server)
+	}
+
+	// The port keeps serving once the first server has stopped
+	if err := servers[0].Stop(context.Background()); err != nil {
+		t.Errorf("Failed to stop server: %v", err)
+	}
+	defer servers[1].Stop(context.Background())
+	time.Sleep(100 * time.Millisecond)
+
+	status, body, err := get(fmt.Sprintf("http://localhost:%d/api/test", httpPort))
+	if err != nil || status != http.StatusOK || body != "ok" {
+		t.Errorf("Expected 200 ok from second server, got %d %q %v", status, body, err)
+	}
+}
+
+func TestServer_UnixSocket(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	dir := t.TempDir()
+	httpSocket := filepath.Join(dir, "gateway.sock")
+	metricsSocket := filepath.Join(dir, "metrics.sock")
+
+	// A socket file left behind by a gateway that did not shut down
+	stale, err := net.Listen("unix", httpSocket)
+	if err != nil {
+		t.Fatalf("Failed to create stale socket: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	cfg := proxyConfig(t, 0, backend)
+	cfg.Gateway.Frontend.HTTP.UnixSocket = httpSocket
+	cfg.Gateway.Frontend.HTTP.SocketMode = "0600"
+	cfg.Gateway.Metrics = &config.Metrics{Enabled: true, UnixSocket: metricsSocket}
+
+	server, err := NewServer(cfg, slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	unixClient := func(path string) *http.Client {
+		return &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}}
+	}
+
+	resp, err := unixClient(httpSocket).Get("http://gateway/api/test")
+	if err != nil {
+		t.Fatalf("Request over Unix socket failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Errorf("Expected 200 ok, got %d %q", resp.StatusCode, body)
+	}
+
+	resp, err = unixClient(metricsSocket).Get("http://gateway/metrics")
+	if err != nil {
+		t.Fatalf("Metrics request over Unix socket failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected metrics status 200, got %d", resp.StatusCode)
+	}
+
+	for path, want := range map[string]os.FileMode{httpSocket: 0600, metricsSocket: config.DefaultSocketMode} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", path, err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("Expected mode %v for %s, got %v", want, path, info.Mode().Perm())
+		}
+	}
+
+	if err := server.Stop(context.Background()); err != nil {
+		t.Fatalf("Failed to stop server: %v", err)
+	}
+
+	// Socket files are removed on stop
+	for _, path := range []string{httpSocket, metricsSocket} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, got %v", path, err)
+		}
+	}
+}
+
 // Helper function to find an available port
 func findAvailablePort(t *testing.T) int {
 	listener, err := net.Listen("tcp", "localhost:0")
@@ -549,3 +884,124 @@ func findAvailablePort(t *testing.T) int {
 	addr := listener.Addr().(*net.TCPAddr)
 	return addr.Port
 }
+
+func TestServer_CustomMiddleware(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	if err := pluginMiddleware.Register("test_recorder", func(cfg map[string]any) (pluginMiddleware.Middleware, error) {
+		label, _ := cfg["label"].(string)
+		return func(next core.Handler) core.Handler {
+			return func(ctx context.Context, req core.Request) (core.Response, error) {
+				mu.Lock()
+				calls = append(calls, label)
+				mu.Unlock()
+				return next(ctx, req)
+			}
+		}, nil
+	}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	httpPort := findAvailablePort(t)
+	cfg := proxyConfig(t, httpPort, backend)
+	cfg.Gateway.Middleware = &config.Middleware{Custom: []config.CustomMiddleware{
+		{Name: "test_recorder", Position: "last", Config: map[string]any{"label": "last"}},
+		{Name: "test_recorder", Config: map[string]any{"label": "afterAuth"}},
+		{Name: "test_recorder", Position: "first", Config: map[string]any{"label": "first"}},
+		{Name: "test_recorder", Position: "afterRouting", Config: map[string]any{"label": "afterRouting"}},
+		{Name: "test_recorder", Position: "first", Config: map[string]any{"label": "first-2"}},
+	}}
+	server, err := NewServer(cfg, slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop(context.Background())
+
+	status, body, err := get(fmt.Sprintf("http://localhost:%d/api/test", httpPort))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if status != http.StatusOK || body != "ok" {
+		t.Errorf("Expected 200 ok, got %d %q", status, body)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"first", "first-2", "afterAuth", "afterRouting", "last"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected custom middleware in order %v, got %v", want, calls)
+	}
+}
+
+func TestServer_ResponseAndSecurityHeaders(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "backend/1.0")
+		w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+		w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	httpPort := findAvailablePort(t)
+	cfg := proxyConfig(t, httpPort, backend)
+	cfg.Gateway.ResponseHeaders = &config.ResponseHeaders{
+		Add:    map[string]string{"X-Gateway": "edge"},
+		Remove: []string{"Server"},
+	}
+	cfg.Gateway.SecurityHeaders = &config.SecurityHeaders{
+		Enabled:               true,
+		ContentSecurityPolicy: "default-src 'none'",
+		Disable:               []string{"Referrer-Policy"},
+	}
+	server, err := NewServer(cfg, slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop(context.Background())
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/api/test", httpPort))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	want := map[string]string{
+		"Server":                    "",
+		"X-Gateway":                 "edge",
+		"X-Frame-Options":           "SAMEORIGIN", // Kept from the backend
+		"X-Content-Type-Options":    "nosniff",
+		"Content-Security-Policy":   "default-src 'none'",
+		"Referrer-Policy":           "",
+		"Strict-Transport-Security": "", // Plain HTTP
+	}
+	for name, value := range want {
+		if got := resp.Header.Get(name); got != value {
+			t.Errorf("Expected %s %q, got %q", name, value, got)
+		}
+	}
+
+	// Gateway error responses get both
+	resp, err = http.Get(fmt.Sprintf("http://localhost:%d/unrouted", httpPort))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("X-Gateway"); got != "edge" {
+		t.Errorf("Expected X-Gateway on error response, got %q", got)
+	}
+	if got := resp.Header.Get("X-Frame-Options"); got != "DENY" {
+		t.Errorf("Expected X-Frame-Options DENY on error response, got %q", got)
+	}
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"os"
+	"strconv"
 	"time"
 
 	"gateway/internal/core"
@@ -8,7 +11,10 @@ import (
 
 // Config holds gateway configuration
 type Config struct {
-	Gateway Gateway `yaml:"gateway"`
+	// Files merged under this one, relative to it; glob patterns allowed.
+	// The loader resolves and clears it.
+	Include []string `yaml:"include,omitempty"`
+	Gateway Gateway  `yaml:"gateway"`
 }
 
 // Gateway configuration
@@ -20,9 +26,14 @@ type Gateway struct {
 	Auth             *Auth             `yaml:"auth,omitempty"`
 	Health           *Health           `yaml:"health,omitempty"`
 	Metrics          *Metrics          `yaml:"metrics,omitempty"`
+	Logging          *Logging          `yaml:"logging,omitempty"`
+	Audit            *Audit            `yaml:"audit,omitempty"`
 	CircuitBreaker   *CircuitBreaker   `yaml:"circuitBreaker,omitempty"`
 	Retry            *Retry            `yaml:"retry,omitempty"`
 	CORS             *CORS             `yaml:"cors,omitempty"`
+	IPFilter         *IPFilter         `yaml:"ipFilter,omitempty"`
+	Concurrency      *Concurrency      `yaml:"concurrency,omitempty"`
+	LoadShedding     *LoadShedding     `yaml:"loadShedding,omitempty"`
 	Redis            *Redis            `yaml:"redis,omitempty"`
 	RateLimitStorage *RateLimitStorage `yaml:"rateLimitStorage,omitempty"`
 	Telemetry        *Telemetry        `yaml:"telemetry,omitempty"`
@@ -30,6 +41,9 @@ type Gateway struct {
 	Middleware       *Middleware       `yaml:"middleware,omitempty"`
 	OpenAPI          *OpenAPIConfig    `yaml:"openapi,omitempty"`
 	Versioning       *VersioningConfig `yaml:"versioning,omitempty"`
+	Idempotency      *Idempotency      `yaml:"idempotency,omitempty"`
+	ResponseHeaders  *ResponseHeaders  `yaml:"responseHeaders,omitempty"`
+	SecurityHeaders  *SecurityHeaders  `yaml:"securityHeaders,omitempty"`
 }
 
 // Frontend configuration
@@ -37,6 +51,17 @@ type Frontend struct {
 	HTTP      HTTP       `yaml:"http"`
 	WebSocket *WebSocket `yaml:"websocket,omitempty"`
 	SSE       *SSE       `yaml:"sse,omitempty"`
+	TCP       *TCP       `yaml:"tcp,omitempty"`
+
+	DrainTimeout int  `yaml:"drainTimeout"` // Grace period in seconds for draining connections on shutdown (default: 30)
+	ReusePort    bool `yaml:"reusePort"`    // Bind with SO_REUSEPORT so a restarted gateway can bind while the old one drains
+
+	// Proxies whose forwarding header is believed when resolving the client IP
+	TrustedProxies []string `yaml:"trustedProxies"`
+	XFFDepth       int      `yaml:"xffDepth"` // Forwarded hops followed through trusted proxies (default: 1)
+	// Header the trusted proxies record client addresses in: X-Forwarded-For
+	// (default), Forwarded or X-Real-IP. No other header is read.
+	ForwardedHeader string `yaml:"forwardedHeader"`
 }
 
 // HTTP configuration
@@ -46,7 +71,63 @@ type HTTP struct {
 	ReadTimeout    int    `yaml:"readTimeout"`
 	WriteTimeout   int    `yaml:"writeTimeout"`
 	MaxRequestSize int64  `yaml:"maxRequestSize"` // Maximum request body size in bytes (0 = no limit)
+	HTTP2          bool   `yaml:"http2"`          // Accept HTTP/2, over TLS or cleartext (h2c), e.g. for native gRPC clients
 	TLS            *TLS   `yaml:"tls,omitempty"`
+	HTTP3          *HTTP3 `yaml:"http3,omitempty"`
+	UnixSocket     string `yaml:"unixSocket"` // Listen on this Unix socket path instead of host:port
+	SocketMode     string `yaml:"socketMode"` // Octal permissions of the Unix socket file (default: 0660)
+	// Add the routing decision to /_gateway/echo/<path> responses, for
+	// debugging routing (default: false)
+	EchoRouting bool `yaml:"echoRouting"`
+	// Error responses keyed by status code ("404") or class ("4xx", "5xx");
+	// a code takes precedence over its class
+	ErrorPages map[string]ErrorPage `yaml:"errorPages,omitempty"`
+	// Limits of the request header fields; requests over them are rejected
+	// with 431 Request Header Fields Too Large (default: no limit beyond
+	// the server's 1MB)
+	MaxHeaderBytes int `yaml:"maxHeaderBytes"`
+	MaxHeaderCount int `yaml:"maxHeaderCount"`
+}
+
+// ErrorPage is the error response for a status code or class. The variant
+// is chosen by the request's Accept header; without an acceptable variant
+// the plain text error is written.
+type ErrorPage struct {
+	HTML *ErrorBody `yaml:"html,omitempty"`
+	JSON *ErrorBody `yaml:"json,omitempty"`
+}
+
+// ErrorBody is a static body or a template file. Templates are executed
+// with .Status, .StatusText, .Message and .RequestID; HTML templates escape
+// them and JSON templates can quote them with the json function.
+type ErrorBody struct {
+	Body        string `yaml:"body"`
+	Template    string `yaml:"template"`    // Template file path
+	ContentType string `yaml:"contentType"` // Default: text/html or application/json with charset=utf-8
+}
+
+// DefaultSocketMode is the default permission of Unix socket files
+const DefaultSocketMode os.FileMode = 0660
+
+// ParseSocketMode parses octal Unix socket file permissions such as "0660",
+// returning DefaultSocketMode when mode is empty
+func ParseSocketMode(mode string) (os.FileMode, error) {
+	if mode == "" {
+		return DefaultSocketMode, nil
+	}
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || perm > 0777 {
+		return 0, fmt.Errorf("invalid socket mode %q", mode)
+	}
+	return os.FileMode(perm), nil
+}
+
+// HTTP3 configuration for serving HTTP/3 over QUIC next to HTTP/1.1 and
+// HTTP/2. QUIC always uses TLS, so the HTTP TLS configuration is required.
+type HTTP3 struct {
+	Enabled      bool `yaml:"enabled"`
+	Port         int  `yaml:"port"`         // UDP port (default: the HTTP port)
+	AltSvcMaxAge int  `yaml:"altSvcMaxAge"` // Seconds clients may remember the Alt-Svc advertisement (default: 86400)
 }
 
 // TLS configuration
@@ -58,6 +139,28 @@ type TLS struct {
 	MaxVersion         string `yaml:"maxVersion"`
 	CipherSuites       []int  `yaml:"cipherSuites"`
 	PreferServerCipher bool   `yaml:"preferServerCipher"`
+	ACME               *ACME  `yaml:"acme,omitempty"` // Obtain and renew certificates automatically
+	// Certificates served by SNI server name; certFile and keyFile are
+	// served to clients asking for none of their hosts
+	Certificates []Certificate `yaml:"certificates,omitempty"`
+}
+
+// Certificate is a certificate and key pair served for its hosts
+type Certificate struct {
+	Hosts    []string `yaml:"hosts"` // Server names, e.g. api.example.com or *.example.com
+	CertFile string   `yaml:"certFile"`
+	KeyFile  string   `yaml:"keyFile"`
+}
+
+// ACME configuration for automatic certificate provisioning, e.g. from
+// Let's Encrypt. Certificate and key files are optional when enabled.
+type ACME struct {
+	Enabled      bool     `yaml:"enabled"`
+	Email        string   `yaml:"email"`        // Contact address for the ACME account
+	Domains      []string `yaml:"domains"`      // Hostnames certificates are obtained for
+	CacheDir     string   `yaml:"cacheDir"`     // Directory certificates and the account key are stored in
+	DirectoryURL string   `yaml:"directoryURL"` // ACME directory (default: Let's Encrypt production)
+	HTTPPort     int      `yaml:"httpPort"`     // Port serving HTTP-01 challenges (default: 80)
 }
 
 // Backend configuration
@@ -74,6 +177,7 @@ type HTTPBackend struct {
 	MaxIdleConnsPerHost int `yaml:"maxIdleConnsPerHost"`
 	MaxConnsPerHost     int `yaml:"maxConnsPerHost"`
 	IdleConnTimeout     int `yaml:"idleConnTimeout"`
+	PoolWaitTimeout     int `yaml:"poolWaitTimeout"` // Milliseconds a request waits for a connection when maxConnsPerHost are busy before failing with 503 (default: no limit)
 
 	// Connection settings
 	KeepAlive          bool `yaml:"keepAlive"`
@@ -84,13 +188,66 @@ type HTTPBackend struct {
 	// Timeout settings
 	DialTimeout           int `yaml:"dialTimeout"`
 	ResponseHeaderTimeout int `yaml:"responseHeaderTimeout"`
+	ResponseIdleTimeout   int `yaml:"responseIdleTimeout"` // Seconds without response body data before the response is aborted (default: none)
 	ExpectContinueTimeout int `yaml:"expectContinueTimeout"`
 	TLSHandshakeTimeout   int `yaml:"tlsHandshakeTimeout"`
 
+	// Response header limits; responses over them fail with 502 Bad
+	// Gateway (default: no limit beyond the transport's 10MB)
+	MaxResponseHeaderBytes int `yaml:"maxResponseHeaderBytes"`
+	MaxResponseHeaderCount int `yaml:"maxResponseHeaderCount"`
+
+	// Connection settings of services overriding the above, by service name
+	Services map[string]HTTPBackendService `yaml:"services,omitempty"`
+
 	// TLS settings
 	TLS *BackendTLS `yaml:"tls,omitempty"`
 }
 
+// HTTPBackendService overrides the connection settings of the HTTP backend
+// for one service, e.g. to disable HTTP/2 for an upstream that mishandles
+// it. Zero and unset fields keep the backend's settings.
+type HTTPBackendService struct {
+	MaxIdleConns        int   `yaml:"maxIdleConns"`
+	MaxIdleConnsPerHost int   `yaml:"maxIdleConnsPerHost"`
+	MaxConnsPerHost     int   `yaml:"maxConnsPerHost"`
+	IdleConnTimeout     int   `yaml:"idleConnTimeout"`
+	KeepAlive           *bool `yaml:"keepAlive,omitempty"`
+	KeepAliveTimeout    int   `yaml:"keepAliveTimeout"`
+	DisableHTTP2        *bool `yaml:"disableHTTP2,omitempty"`
+}
+
+// ForService returns the settings of the HTTP backend with the overrides of
+// the named service applied
+func (b HTTPBackend) ForService(name string) HTTPBackend {
+	s, ok := b.Services[name]
+	if !ok {
+		return b
+	}
+	if s.MaxIdleConns > 0 {
+		b.MaxIdleConns = s.MaxIdleConns
+	}
+	if s.MaxIdleConnsPerHost > 0 {
+		b.MaxIdleConnsPerHost = s.MaxIdleConnsPerHost
+	}
+	if s.MaxConnsPerHost > 0 {
+		b.MaxConnsPerHost = s.MaxConnsPerHost
+	}
+	if s.IdleConnTimeout > 0 {
+		b.IdleConnTimeout = s.IdleConnTimeout
+	}
+	if s.KeepAlive != nil {
+		b.KeepAlive = *s.KeepAlive
+	}
+	if s.KeepAliveTimeout > 0 {
+		b.KeepAliveTimeout = s.KeepAliveTimeout
+	}
+	if s.DisableHTTP2 != nil {
+		b.DisableHTTP2 = *s.DisableHTTP2
+	}
+	return b
+}
+
 // BackendTLS configuration
 type BackendTLS struct {
 	Enabled            bool   `yaml:"enabled"`
@@ -128,6 +285,7 @@ type WebSocket struct {
 	// Token validation for long-lived connections
 	TokenValidation    bool `yaml:"tokenValidation"`    // Enable token validation
 	TokenCheckInterval int  `yaml:"tokenCheckInterval"` // Check interval in seconds (default: 60)
+	TLS                *TLS `yaml:"tls,omitempty"`      // Serve wss:// on the WebSocket port
 }
 
 // WebSocketBackend configuration
@@ -145,9 +303,10 @@ type WebSocketBackend struct {
 	MaxMessageSize int64 `yaml:"maxMessageSize"`
 
 	// Connection pool settings
-	MaxConnections        int `yaml:"maxConnections"`
-	ConnectionTimeout     int `yaml:"connectionTimeout"`
-	IdleConnectionTimeout int `yaml:"idleConnectionTimeout"`
+	MaxConnections        int  `yaml:"maxConnections"` // Idle connections kept per instance when reused
+	ConnectionTimeout     int  `yaml:"connectionTimeout"`
+	IdleConnectionTimeout int  `yaml:"idleConnectionTimeout"` // Seconds an idle connection is kept (default: 90)
+	ReuseConnections      bool `yaml:"reuseConnections"`      // Hand connections of departed clients to new ones (default: one per client)
 
 	// Keepalive settings
 	PingInterval int `yaml:"pingInterval"`
@@ -164,11 +323,31 @@ type SSE struct {
 	Enabled          bool `yaml:"enabled"`
 	WriteTimeout     int  `yaml:"writeTimeout"`
 	KeepaliveTimeout int  `yaml:"keepaliveTimeout"`
+	// Heartbeats sent every keepaliveTimeout seconds
+	HeartbeatFormat  string `yaml:"heartbeatFormat"`  // comment (default) or event
+	HeartbeatComment string `yaml:"heartbeatComment"` // Text of comment heartbeats (default: keepalive)
+	HeartbeatEvent   string `yaml:"heartbeatEvent"`   // Event type of event heartbeats (default: ping)
+	// Reconnect interval in milliseconds sent to clients in an initial retry: directive
+	RetryInterval int `yaml:"retryInterval"`
+	// event (default) flushes every event; manual leaves flushing to the handler
+	FlushMode string `yaml:"flushMode"`
 	// Token validation for long-lived connections
 	TokenValidation    bool `yaml:"tokenValidation"`    // Enable token validation
 	TokenCheckInterval int  `yaml:"tokenCheckInterval"` // Check interval in seconds (default: 60)
 }
 
+// TCP configuration for proxying raw TCP connections to a single service
+type TCP struct {
+	Enabled        bool   `yaml:"enabled"`
+	Host           string `yaml:"host"`
+	Port           int    `yaml:"port"`
+	ServiceName    string `yaml:"serviceName"`    // Service every connection is proxied to
+	LoadBalance    string `yaml:"loadBalance"`    // round_robin (default) or least_connections
+	IdleTimeout    int    `yaml:"idleTimeout"`    // Seconds without traffic before a connection is closed (default: 300)
+	DialTimeout    int    `yaml:"dialTimeout"`    // Seconds to connect to a backend instance (default: 10)
+	MaxConnections int    `yaml:"maxConnections"` // Maximum concurrent connections (default: 1024)
+}
+
 // SSEBackend configuration
 type SSEBackend struct {
 	// Connection settings
@@ -179,8 +358,8 @@ type SSEBackend struct {
 	BufferSize int `yaml:"bufferSize"`
 
 	// Retry settings
-	RetryInterval int `yaml:"retryInterval"`
-	MaxRetries    int `yaml:"maxRetries"`
+	RetryInterval int `yaml:"retryInterval"` // Seconds before reconnecting a dropped stream, doubled per attempt (default: 1)
+	MaxRetries    int `yaml:"maxRetries"`    // Reconnects before the client gets an error (default: 0, no reconnects)
 
 	// Event settings
 	MaxEventSize int `yaml:"maxEventSize"`
@@ -192,11 +371,17 @@ type Registry struct {
 	Static        *StaticRegistry          `yaml:"static,omitempty"`
 	Docker        *DockerRegistry          `yaml:"docker,omitempty"`
 	DockerCompose *DockerComposeRegistry   `yaml:"dockerCompose,omitempty"`
+	DNS           *DNSRegistry             `yaml:"dns,omitempty"`
+	Swarm         *SwarmRegistry           `yaml:"swarm,omitempty"`
+	// Catalog polled over HTTP
+	HTTP *HTTPRegistry `yaml:"http,omitempty"`
 }
 
 // StaticRegistry configuration
 type StaticRegistry struct {
 	Services []Service `yaml:"services"`
+	// File listing the services instead, reloaded whenever it changes
+	File string `yaml:"file"`
 }
 
 // Service represents a service definition
@@ -213,6 +398,8 @@ type Instance struct {
 	Weight  int      `yaml:"weight"`
 	Health  string   `yaml:"health"`
 	Tags    []string `yaml:"tags"`
+	// Metadata is matched by route instance selectors
+	Metadata map[string]string `yaml:"metadata,omitempty"`
 }
 
 // DockerRegistry configuration
@@ -226,6 +413,7 @@ type DockerRegistry struct {
 	LabelPrefix     string `yaml:"labelPrefix"`     // Label prefix for gateway config
 	Network         string `yaml:"network"`         // Docker network to use
 	RefreshInterval int    `yaml:"refreshInterval"` // Service refresh interval in seconds
+	RouteLabels     bool   `yaml:"routeLabels"`     // Read routes from <prefix>.route.* labels
 }
 
 // DockerComposeRegistry configuration
@@ -241,9 +429,77 @@ type DockerComposeRegistry struct {
 	APIVersion string `yaml:"apiVersion"`
 }
 
+// SwarmRegistry configuration
+type SwarmRegistry struct {
+	// Docker connection settings, pointing at a manager node
+	Host    string `yaml:"host"`    // Docker daemon host
+	Version string `yaml:"version"` // Docker API version
+
+	// Service discovery settings
+	LabelPrefix     string `yaml:"labelPrefix"`     // Label prefix for gateway config
+	Network         string `yaml:"network"`         // Overlay network to reach services on
+	Resolve         string `yaml:"resolve"`         // "vip" (default) or "tasks"
+	RefreshInterval int    `yaml:"refreshInterval"` // Service refresh interval in seconds
+}
+
+// DNSRegistry configuration
+type DNSRegistry struct {
+	Services []DNSService `yaml:"services"`
+	// DNS server address (host:port), defaults to the system resolver
+	Resolver   string `yaml:"resolver"`
+	MinRefresh int    `yaml:"minRefresh"` // Minimum refresh interval in seconds
+	MaxRefresh int    `yaml:"maxRefresh"` // Maximum refresh interval in seconds
+	Timeout    int    `yaml:"timeout"`    // Query timeout in seconds
+}
+
+// DNSService maps a service to the SRV name its instances are published under
+type DNSService struct {
+	Name   string `yaml:"name"`
+	Query  string `yaml:"query"`  // SRV name, e.g. _http._tcp.users.example.com
+	Scheme string `yaml:"scheme"` // Instance scheme, defaults to http
+}
+
+// HTTPRegistry configuration
+type HTTPRegistry struct {
+	URL             string            `yaml:"url"`             // Catalog endpoint returning services as JSON
+	Headers         map[string]string `yaml:"headers"`         // Request headers, e.g. Authorization
+	RefreshInterval int               `yaml:"refreshInterval"` // Poll interval in seconds (default 10)
+	Timeout         int               `yaml:"timeout"`         // Request timeout in seconds (default 5)
+	// Where services and instance fields are found in the response
+	Mapping HTTPRegistryMapping `yaml:"mapping"`
+}
+
+// HTTPRegistryMapping locates services and instance fields in a catalog
+// response. Paths are dot-separated object keys with an optional "$."
+// prefix; "$" is the whole response.
+type HTTPRegistryMapping struct {
+	Services  string `yaml:"services"`  // Array of services, or object keyed by service name (default "services")
+	Name      string `yaml:"name"`      // Service name within a service (default "name")
+	Instances string `yaml:"instances"` // Array of instances within a service (default "instances")
+	ID        string `yaml:"id"`        // Instance ID (default "id", falls back to address:port)
+	Address   string `yaml:"address"`   // Instance address, may include the port (default "address")
+	Port      string `yaml:"port"`      // Instance port (default "port")
+	Scheme    string `yaml:"scheme"`    // Instance scheme (default "scheme", falls back to http)
+	Healthy   string `yaml:"healthy"`   // Instance health (default "healthy", instances are healthy without it)
+	Weight    string `yaml:"weight"`    // Instance weight (default "weight")
+	Metadata  string `yaml:"metadata"`  // Object of instance metadata (default "metadata")
+}
+
 // Router configuration
 type Router struct {
 	Rules []RouteRule `yaml:"rules"`
+	// Signed header pinning requests to an instance, for debugging
+	InstanceOverride *InstanceOverride `yaml:"instanceOverride,omitempty"`
+}
+
+// InstanceOverride lets requests carrying a header signed with Secret skip
+// the balancer and go to the instance named in the header, if it is healthy.
+// Header values are "<instance id>;<unix expiry>;<signature>", where the
+// signature is the hex HMAC-SHA256 of "<instance id>;<unix expiry>".
+type InstanceOverride struct {
+	Enabled bool   `yaml:"enabled"`
+	Header  string `yaml:"header"` // Default X-Gateway-Route-Instance
+	Secret  string `yaml:"secret"` // HMAC key, at least 32 bytes
 }
 
 // RouteRule represents a single routing rule
@@ -263,8 +519,174 @@ type RouteRule struct {
 	RateLimitBurst      int    `yaml:"rateLimitBurst"`
 	RateLimitExpiration int    `yaml:"rateLimitExpiration"`
 	RateLimitStorage    string `yaml:"rateLimitStorage"` // Storage name to use
+	// Audit logging
+	Audit     bool `yaml:"audit"`     // Write audit events for this route
+	MustAudit bool `yaml:"mustAudit"` // Fail requests whose audit event cannot be written (implies audit)
+	// CORS policy overriding the global one; enabled: false turns CORS off for the route
+	CORS *CORS `yaml:"cors,omitempty"`
+	// Client CIDRs allowed or denied in addition to gateway.ipFilter
+	IPFilter *RouteIPFilter `yaml:"ipFilter,omitempty"`
+	// Cap on requests of the route in flight
+	Concurrency *ConcurrencyLimit `yaml:"concurrency,omitempty"`
 	// gRPC configuration
 	GRPC *GRPCConfig `yaml:"grpc,omitempty"`
+	// Weighted split over several services; serviceName is optional then
+	TrafficSplit *TrafficSplit `yaml:"trafficSplit,omitempty"`
+	// Copy requests to a shadow service, discarding its responses
+	Mirror *Mirror `yaml:"mirror,omitempty"`
+	// Replay the first response for requests repeating an idempotency key
+	Idempotency *RouteIdempotency `yaml:"idempotency,omitempty"`
+	// Response served instead of an open-breaker or exhausted-retries error
+	Fallback *Fallback `yaml:"fallback,omitempty"`
+	// Share one backend response between identical concurrent GET requests
+	Coalesce *Coalesce `yaml:"coalesce,omitempty"`
+	// Connect, response header and idle timeouts overriding gateway.backend.http
+	BackendTimeouts *BackendTimeouts `yaml:"backendTimeouts,omitempty"`
+	// Accept-Encoding sent to the backend, whatever the client accepts
+	BackendCompression *BackendCompression `yaml:"backendCompression,omitempty"`
+	// Ramp up the weight of instances that have just become healthy
+	SlowStart *SlowStart `yaml:"slowStart,omitempty"`
+	// Only send requests to instances whose tags or metadata match every
+	// predicate, e.g. "tag=beta", "region=eu" or "version>=2"
+	InstanceSelector []string `yaml:"instanceSelector,omitempty"`
+	// Rewrite or drop messages of websocket routes
+	WebSocketTransform *WebSocketTransform `yaml:"websocketTransform,omitempty"`
+	// Headers edited on responses, after gateway.responseHeaders
+	ResponseHeaders *ResponseHeaders `yaml:"responseHeaders,omitempty"`
+	// Request body limits, rejected with 413 and 415 respectively
+	MaxBodySize         int64    `yaml:"maxBodySize"`         // Maximum request body size in bytes (0 = no limit)
+	AllowedContentTypes []string `yaml:"allowedContentTypes"` // Media types accepted for bodies, e.g. application/json or image/*
+	// Milliseconds after which a request is logged and counted as slow,
+	// without otherwise affecting it (requires gateway.telemetry)
+	LatencyBudget int `yaml:"latencyBudget"`
+	// Log request and response bodies at debug level, for debugging
+	BodyLogging *BodyLogging `yaml:"bodyLogging,omitempty"`
+}
+
+// WebSocketTransform rewrites the messages of a websocket route
+type WebSocketTransform struct {
+	Inbound  *WebSocketMessageRules `yaml:"inbound"`  // Client to backend
+	Outbound *WebSocketMessageRules `yaml:"outbound"` // Backend to client
+}
+
+// WebSocketMessageRules apply to the messages in one direction
+type WebSocketMessageRules struct {
+	DropTypes []string `yaml:"dropTypes"` // text or binary
+	// Fields set on JSON object text messages; values may reference
+	// ${auth.subject}, ${route.id} and ${request.id}
+	InjectFields map[string]string `yaml:"injectFields"`
+}
+
+// websocketMessageTypes maps the message types of dropTypes
+var websocketMessageTypes = map[string]core.WebSocketMessageType{
+	"text":   core.WebSocketTextMessage,
+	"binary": core.WebSocketBinaryMessage,
+}
+
+// toCore converts the rules, skipping unknown message types
+func (r *WebSocketMessageRules) toCore() *core.WebSocketMessageRules {
+	if r == nil {
+		return nil
+	}
+	rules := &core.WebSocketMessageRules{InjectFields: r.InjectFields}
+	for _, name := range r.DropTypes {
+		if t, ok := websocketMessageTypes[name]; ok {
+			rules.DropTypes = append(rules.DropTypes, t)
+		}
+	}
+	return rules
+}
+
+// SlowStart gradually raises the share of traffic of an instance after it
+// becomes healthy; only weighted load balancing strategies support it
+type SlowStart struct {
+	Window           int `yaml:"window"`           // Seconds until an instance gets its full weight
+	MinWeightPercent int `yaml:"minWeightPercent"` // Share of its weight an instance starts with (default: 10)
+}
+
+// BackendTimeouts override the HTTP backend timeouts for a route, in seconds
+type BackendTimeouts struct {
+	Connect        int `yaml:"connect"`        // Overrides dialTimeout
+	ResponseHeader int `yaml:"responseHeader"` // Overrides responseHeaderTimeout
+	Idle           int `yaml:"idle"`           // Overrides responseIdleTimeout
+}
+
+// BackendCompression sets the content encodings negotiated with the backend
+// of a route. Responses in an encoding the client does not accept are
+// decoded by the gateway.
+type BackendCompression struct {
+	// strip removes the client's Accept-Encoding; identity, gzip, deflate
+	// or a list of them replaces it (default: the client's is forwarded)
+	AcceptEncoding string `yaml:"acceptEncoding"`
+}
+
+// BackendAcceptEncodings are the encodings backendCompression.acceptEncoding
+// may list, those the gateway can decode for clients not accepting them
+var BackendAcceptEncodings = []string{"identity", "gzip", "deflate"}
+
+// Coalesce lets identical concurrent GET requests of a route wait for the
+// first one and share its response
+type Coalesce struct {
+	VaryHeaders []string `yaml:"varyHeaders"` // Headers distinguishing responses, besides Authorization and Cookie
+	MaxWait     int      `yaml:"maxWait"`     // Milliseconds a request waits before going to the backend itself (default: 5000)
+}
+
+// BodyLogging logs the request and response bodies of a route at debug
+// level. Bodies are logged only while the gateway logs at debug level.
+type BodyLogging struct {
+	Enabled  bool `yaml:"enabled"`
+	MaxBytes int  `yaml:"maxBytes"` // Bytes logged per body (default: 4096)
+	// JSONPath expressions of JSON body fields whose values are redacted,
+	// e.g. $.password, $.items[*].token or $..secret
+	Redact []string `yaml:"redact,omitempty"`
+}
+
+// Fallback answers requests whose backend is unavailable, with either a
+// static response or a secondary service
+type Fallback struct {
+	ServiceName string            `yaml:"serviceName"` // Secondary service; the static response is used when empty
+	Status      int               `yaml:"status"`      // Static response status (default: 200)
+	Body        string            `yaml:"body"`        // Static response body
+	ContentType string            `yaml:"contentType"` // Static response content type (default: application/json)
+	Headers     map[string]string `yaml:"headers"`     // Additional static response headers
+	On          []string          `yaml:"on"`          // circuit_open and/or retries_exhausted (default: both)
+}
+
+// RouteIdempotency enables idempotency keys on a route
+type RouteIdempotency struct {
+	Header  string   `yaml:"header"`  // Header carrying the key (default: Idempotency-Key)
+	Methods []string `yaml:"methods"` // Methods with keys (default: POST)
+	TTL     int      `yaml:"ttl"`     // Seconds responses are replayed (default: 86400)
+}
+
+// Idempotency configures where responses for idempotency keys are stored
+type Idempotency struct {
+	Storage     string `yaml:"storage"`     // memory (default) or redis, using gateway.redis
+	KeyPrefix   string `yaml:"keyPrefix"`   // Prefix of Redis keys (default: gateway:idempotency:)
+	MaxEntries  int    `yaml:"maxEntries"`  // Keys held by memory storage (default: 10000)
+	LockTimeout int    `yaml:"lockTimeout"` // Seconds a key stays locked by a request in progress (default: 30)
+	MaxBodySize int64  `yaml:"maxBodySize"` // Largest response body stored in bytes (default: 1MB)
+}
+
+// Mirror sends a copy of a route's requests to another service
+type Mirror struct {
+	ServiceName string  `yaml:"serviceName"`
+	Percentage  float64 `yaml:"percentage"` // Share of requests mirrored, 0-100
+	Header      string  `yaml:"header"`     // Only mirror requests carrying this header
+	Cookie      string  `yaml:"cookie"`     // Only mirror requests carrying this cookie
+	Timeout     int     `yaml:"timeout"`    // Seconds (default: 10)
+}
+
+// TrafficSplit sends a share of a route's requests to each service
+type TrafficSplit struct {
+	Services     []WeightedService `yaml:"services"`
+	StickyHeader string            `yaml:"stickyHeader"` // Requests with the same value of this header go to the same service
+}
+
+// WeightedService is a service and its share of a traffic split
+type WeightedService struct {
+	ServiceName string `yaml:"serviceName"`
+	Weight      int    `yaml:"weight"`
 }
 
 // SessionAffinityConfig represents session affinity configuration
@@ -276,16 +698,44 @@ type SessionAffinityConfig struct {
 	HeaderName string `yaml:"headerName"` // for header source
 	QueryParam string `yaml:"queryParam"` // for query source
 	MaxEntries int    `yaml:"maxEntries"` // max number of sessions to track
+	Storage    string `yaml:"storage"`    // memory (default) or redis, using gateway.redis
 }
 
 // Auth configuration
 type Auth struct {
 	Required       bool          `yaml:"required"`
 	Providers      []string      `yaml:"providers"`
-	SkipPaths      []string      `yaml:"skipPaths"`
+	SkipPaths      []string      `yaml:"skipPaths"` // "[METHODS] PATH" patterns, e.g. "GET /health", "/public/", "GET /docs/**"
 	RequiredScopes []string      `yaml:"requiredScopes"`
 	JWT            *JWTConfig    `yaml:"jwt,omitempty"`
 	APIKey         *APIKeyConfig `yaml:"apikey,omitempty"`
+	// enforce (default), shadow to only log and record the requests that
+	// would be denied while letting them through, or disabled
+	EnforcementMode string `yaml:"enforcementMode"`
+	// Headers passing the verified identity to HTTP backends
+	IdentityHeaders *IdentityHeaders `yaml:"identityHeaders,omitempty"`
+}
+
+// IdentityHeaders names the backend request headers set from the identity
+// of authenticated requests. Clients cannot supply these headers themselves.
+type IdentityHeaders struct {
+	Subject            string            `yaml:"subject"`            // e.g. X-Auth-Subject
+	Type               string            `yaml:"type"`               // Subject type: user, service or device
+	Scopes             string            `yaml:"scopes"`             // Space-separated scopes, e.g. X-Auth-Scopes
+	Metadata           map[string]string `yaml:"metadata"`           // Header names by auth metadata key
+	Claims             []ClaimHeader     `yaml:"claims"`             // Headers rendered from JWT claims
+	StripAuthorization bool              `yaml:"stripAuthorization"` // Remove the Authorization header once verified
+}
+
+// ClaimHeader renders a backend request header from the token claims
+type ClaimHeader struct {
+	Header string `yaml:"header"`
+	// Go template seeing .claims, .metadata, .subject and .scopes, e.g.
+	// {{ .claims.tenant_id }}
+	Value string `yaml:"value"`
+	// Fail requests whose claims do not render a value, instead of
+	// omitting the header
+	Required bool `yaml:"required"`
 }
 
 // JWTConfig represents JWT authentication configuration
@@ -303,6 +753,22 @@ type JWTConfig struct {
 	SubjectClaim      string            `yaml:"subjectClaim"`
 	HeaderName        string            `yaml:"headerName"`
 	CookieName        string            `yaml:"cookieName"`
+	// Token sources tried in order, replacing headerName and cookieName when set
+	TokenSources []TokenSource `yaml:"tokenSources"`
+	// Validated tokens are cached so repeat requests skip signature checks
+	CacheSize   int `yaml:"cacheSize"`   // Tokens cached, least recently used evicted first (0 = no caching)
+	CacheMaxTTL int `yaml:"cacheMaxTTL"` // Seconds a token stays cached at most, below its expiry (default: 300)
+	// Encrypted tokens (JWE) are decrypted before validation
+	DecryptionKey       string `yaml:"decryptionKey"`       // PEM private key for RSA-OAEP and ECDH-ES
+	DecryptionSecret    string `yaml:"decryptionSecret"`    // Shared key for AES key wrap and dir
+	DecryptionAlgorithm string `yaml:"decryptionAlgorithm"` // JWE key management algorithm (default: RSA-OAEP-256)
+}
+
+// TokenSource is a place a bearer token is read from
+type TokenSource struct {
+	Type   string `yaml:"type"`   // header, cookie or query
+	Name   string `yaml:"name"`   // Header, cookie or query parameter name
+	Scheme string `yaml:"scheme"` // Prefix a header value must carry, e.g. Bearer (empty takes the whole value)
 }
 
 // APIKeyConfig represents API key authentication configuration
@@ -314,6 +780,41 @@ type APIKeyConfig struct {
 	HeaderName    string                    `yaml:"headerName"`
 	QueryParam    string                    `yaml:"queryParam"`
 	Scheme        string                    `yaml:"scheme"`
+	// Keys loaded from an external store in addition to the static keys
+	Source          string       `yaml:"source"`          // static (default), vault or http
+	Vault           *APIKeyVault `yaml:"vault,omitempty"` // Used when source is vault
+	HTTP            *APIKeyHTTP  `yaml:"http,omitempty"`  // Used when source is http
+	RefreshInterval int          `yaml:"refreshInterval"` // Seconds between store reloads (default: 60)
+	// Keys expiring within this many days are logged hourly (default: 7, -1 disables)
+	ExpiryWarningDays int `yaml:"expiryWarningDays"`
+}
+
+// APIKeyVault reads API keys from a HashiCorp Vault secret. Each field of
+// the secret is a key ID whose value is an object with the fields of
+// APIKeyDetails.
+type APIKeyVault struct {
+	Address   string        `yaml:"address"`   // e.g. https://vault.example.com:8200
+	Path      string        `yaml:"path"`      // API path of the secret, e.g. secret/data/gateway/api-keys
+	Namespace string        `yaml:"namespace"` // Vault Enterprise namespace
+	Token     string        `yaml:"token"`
+	TokenFile string        `yaml:"tokenFile"`
+	AppRole   *VaultAppRole `yaml:"appRole,omitempty"`
+	Timeout   int           `yaml:"timeout"` // Seconds (default: 10)
+}
+
+// VaultAppRole logs in to Vault with the AppRole auth method
+type VaultAppRole struct {
+	RoleID   string `yaml:"roleId"`
+	SecretID string `yaml:"secretId"`
+	Mount    string `yaml:"mount"` // Auth mount path (default: approle)
+}
+
+// APIKeyHTTP reads API keys from an HTTP endpoint returning a JSON object
+// of key IDs to APIKeyDetails objects
+type APIKeyHTTP struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	Timeout int               `yaml:"timeout"` // Seconds (default: 10)
 }
 
 // APIKeyDetails represents configuration for a single API key
@@ -335,8 +836,24 @@ func (i *Instance) ToServiceInstance(name string) core.ServiceInstance {
 		Address:  i.Address,
 		Port:     i.Port,
 		Healthy:  i.Health == "healthy",
-		Metadata: nil, // Static registry doesn't have metadata yet
+		Metadata: i.metadata(),
+	}
+}
+
+// metadata returns the tags and metadata of the instance, or nil when it
+// has neither
+func (i *Instance) metadata() map[string]any {
+	if len(i.Tags) == 0 && len(i.Metadata) == 0 {
+		return nil
 	}
+	metadata := make(map[string]any, len(i.Metadata)+1)
+	for k, v := range i.Metadata {
+		metadata[k] = v
+	}
+	if len(i.Tags) > 0 {
+		metadata["tags"] = i.Tags
+	}
+	return metadata
 }
 
 // ToRouteRule converts to core.RouteRule
@@ -367,6 +884,57 @@ func (r *RouteRule) ToRouteRule() core.RouteRule {
 			HeaderName: r.SessionAffinityConfig.HeaderName,
 			QueryParam: r.SessionAffinityConfig.QueryParam,
 			MaxEntries: r.SessionAffinityConfig.MaxEntries,
+			Storage:    r.SessionAffinityConfig.Storage,
+		}
+	}
+
+	// Convert traffic split
+	if r.TrafficSplit != nil {
+		rule.TrafficSplit = &core.TrafficSplit{StickyHeader: r.TrafficSplit.StickyHeader}
+		for _, service := range r.TrafficSplit.Services {
+			rule.TrafficSplit.Services = append(rule.TrafficSplit.Services, core.WeightedService{
+				ServiceName: service.ServiceName,
+				Weight:      service.Weight,
+			})
+		}
+	}
+
+	if t := r.BackendTimeouts; t != nil {
+		rule.BackendTimeouts = &core.BackendTimeouts{
+			Connect:        time.Duration(t.Connect) * time.Second,
+			ResponseHeader: time.Duration(t.ResponseHeader) * time.Second,
+			Idle:           time.Duration(t.Idle) * time.Second,
+		}
+	}
+
+	if c := r.BackendCompression; c != nil && c.AcceptEncoding != "" {
+		if c.AcceptEncoding == "strip" {
+			rule.BackendCompression = &core.BackendCompression{Strip: true}
+		} else {
+			rule.BackendCompression = &core.BackendCompression{AcceptEncoding: c.AcceptEncoding}
+		}
+	}
+
+	if t := r.WebSocketTransform; t != nil {
+		rule.WebSocketTransform = &core.WebSocketTransform{
+			Inbound:  t.Inbound.toCore(),
+			Outbound: t.Outbound.toCore(),
+		}
+	}
+
+	if len(r.InstanceSelector) > 0 {
+		// Selectors are checked when the configuration is validated
+		rule.InstanceSelector, _ = core.ParseInstanceSelector(r.InstanceSelector)
+	}
+
+	if r.LatencyBudget > 0 {
+		rule.LatencyBudget = time.Duration(r.LatencyBudget) * time.Millisecond
+	}
+
+	if s := r.SlowStart; s != nil {
+		rule.SlowStart = &core.SlowStartConfig{
+			Window:    time.Duration(s.Window) * time.Second,
+			MinWeight: float64(s.MinWeightPercent) / 100,
 		}
 	}
 
@@ -403,14 +971,37 @@ type Health struct {
 	ReadyPath  string           `yaml:"readyPath"`
 	LivePath   string           `yaml:"livePath"`
 	Checks     map[string]Check `yaml:"checks"`
+	Backends   map[string]Check `yaml:"backends"` // Active instance checks keyed by service name
+	// Checks of the gateway's own dependencies, reported by the ready path
+	Dependencies *HealthDependencies `yaml:"dependencies,omitempty"`
+	// Seconds the ready path waits for the registry's first discovery
+	// before reporting ready anyway (default: 60)
+	DiscoveryTimeout int `yaml:"discoveryTimeout"`
+}
+
+// HealthDependencies adds health checks named after the dependencies the
+// gateway is configured with
+type HealthDependencies struct {
+	Redis    bool     `yaml:"redis"`    // gateway.redis answers PING
+	Backends bool     `yaml:"backends"` // Every service routed to has a healthy instance
+	JWKS     bool     `yaml:"jwks"`     // gateway.auth.jwt.jwksEndpoint answers
+	Interval int      `yaml:"interval"` // Seconds a result is reused between probes (default: 10)
+	Timeout  int      `yaml:"timeout"`  // Seconds each check may take (default: 5)
+	Optional []string `yaml:"optional"` // Dependencies reported without failing readiness, e.g. [jwks]
 }
 
 // Check represents a health check configuration
 type Check struct {
 	Type     string            `yaml:"type"`     // http, tcp, exec, grpc
-	Interval int               `yaml:"interval"` // Check interval in seconds
+	Interval int               `yaml:"interval"` // Check interval in seconds, results are reused in between
 	Timeout  int               `yaml:"timeout"`  // Timeout in seconds
 	Config   map[string]string `yaml:"config"`   // Check-specific configuration
+	Optional bool              `yaml:"optional"` // Reported without failing readiness
+
+	// Backend instance checks only
+	Path               string `yaml:"path"`               // HTTP health path, defaults to /health
+	HealthyThreshold   int    `yaml:"healthyThreshold"`   // Consecutive successes to mark healthy
+	UnhealthyThreshold int    `yaml:"unhealthyThreshold"` // Consecutive failures to mark unhealthy
 }
 
 // Metrics configuration
@@ -418,6 +1009,40 @@ type Metrics struct {
 	Enabled bool   `yaml:"enabled"`
 	Path    string `yaml:"path"` // Path to expose metrics (e.g., /metrics)
 	Port    int    `yaml:"port"` // Port to expose metrics (0 = same as main port)
+
+	UnixSocket string `yaml:"unixSocket"` // Serve metrics on this Unix socket path instead of a port
+	SocketMode string `yaml:"socketMode"` // Octal permissions of the Unix socket file (default: 0660)
+}
+
+// Logging configuration
+type Logging struct {
+	AccessLog  bool     `yaml:"accessLog"`  // Log one record per request
+	Format     string   `yaml:"format"`     // json or text; empty uses the gateway logger
+	Fields     []string `yaml:"fields"`     // Fields to include (empty = all)
+	SampleRate float64  `yaml:"sampleRate"` // Fraction of successful requests logged (0 = all)
+}
+
+// Audit configuration for routes with audit enabled
+type Audit struct {
+	Sink          AuditSink    `yaml:"sink"`
+	Bodies        *AuditBodies `yaml:"bodies,omitempty"`
+	RedactHeaders []string     `yaml:"redactHeaders"` // Headers to redact in addition to Authorization, Cookie and Set-Cookie
+}
+
+// AuditSink configures where audit events are written
+type AuditSink struct {
+	Type    string            `yaml:"type"`    // file, stdout, webhook
+	Path    string            `yaml:"path"`    // File to append events to (file sink)
+	URL     string            `yaml:"url"`     // URL events are posted to (webhook sink)
+	Headers map[string]string `yaml:"headers"` // Extra request headers (webhook sink)
+	Timeout int               `yaml:"timeout"` // Webhook timeout in seconds (default: 5)
+}
+
+// AuditBodies configures capture of request and response bodies
+type AuditBodies struct {
+	Enabled      bool     `yaml:"enabled"`
+	MaxSize      int      `yaml:"maxSize"`      // Maximum bytes captured per body (default: 4096)
+	RedactFields []string `yaml:"redactFields"` // JSON fields whose values are redacted
 }
 
 // CircuitBreaker configuration
@@ -426,6 +1051,18 @@ type CircuitBreaker struct {
 	Default  CircuitBreakerConfig            `yaml:"default"`
 	Routes   map[string]CircuitBreakerConfig `yaml:"routes,omitempty"`
 	Services map[string]CircuitBreakerConfig `yaml:"services,omitempty"`
+	// Per-instance outlier detection
+	OutlierDetection *OutlierDetection `yaml:"outlierDetection,omitempty"`
+}
+
+// OutlierDetection configures per-instance ejection of failing backends
+type OutlierDetection struct {
+	Enabled            bool    `yaml:"enabled"`
+	EjectionThreshold  float64 `yaml:"ejectionThreshold"`  // Instance error rate that triggers ejection (0-1)
+	MinRequests        int     `yaml:"minRequests"`        // Minimum requests per interval before the error rate is evaluated
+	MaxEjectionPercent int     `yaml:"maxEjectionPercent"` // Maximum percentage of a service's instances ejected at once
+	BaseEjectionTime   int     `yaml:"baseEjectionTime"`   // Base ejection time in seconds, multiplied by consecutive ejections
+	Interval           int     `yaml:"interval"`           // Stats reset interval in seconds
 }
 
 // CircuitBreakerConfig holds circuit breaker settings
@@ -453,6 +1090,10 @@ type RetryConfig struct {
 	Multiplier   float64 `yaml:"multiplier"`   // Backoff multiplier
 	Jitter       bool    `yaml:"jitter"`       // Add jitter to delays
 	BudgetRatio  float64 `yaml:"budgetRatio"`  // Retry budget ratio (0-1, default 0.1)
+	// RetryOn lists retry conditions: 5xx, gateway-error, connect-failure, timeout, reset or status codes
+	RetryOn []string `yaml:"retryOn,omitempty"`
+	// RetryableMethods lists methods that may be retried (default: idempotent methods, "*" for all)
+	RetryableMethods []string `yaml:"retryableMethods,omitempty"`
 }
 
 // CORS configuration
@@ -468,6 +1109,106 @@ type CORS struct {
 	OptionsSuccessStatus int      `yaml:"optionsSuccessStatus"`
 }
 
+// IPFilter configures the client IPs allowed through the gateway
+type IPFilter struct {
+	AllowCIDRs []string `yaml:"allowCIDRs"` // If set, only clients in these CIDRs are allowed
+	DenyCIDRs  []string `yaml:"denyCIDRs"`  // Clients in these CIDRs are rejected, even if allowed
+}
+
+// ResponseHeaders edits the headers of responses written to clients,
+// gateway error responses included. Remove is applied first, then add,
+// then defaults.
+type ResponseHeaders struct {
+	Add      map[string]string `yaml:"add"`      // Set, replacing the values of the response
+	Defaults map[string]string `yaml:"defaults"` // Set only when the response does not have the header
+	Remove   []string          `yaml:"remove"`
+}
+
+// SecurityHeaders adds security headers to every response that does not
+// already have them. Empty values take the defaults.
+type SecurityHeaders struct {
+	Enabled               bool   `yaml:"enabled"`
+	HSTS                  *HSTS  `yaml:"hsts,omitempty"`
+	ContentTypeOptions    string `yaml:"contentTypeOptions"`    // X-Content-Type-Options (default: nosniff)
+	FrameOptions          string `yaml:"frameOptions"`          // X-Frame-Options (default: DENY)
+	ReferrerPolicy        string `yaml:"referrerPolicy"`        // Referrer-Policy (default: strict-origin-when-cross-origin)
+	ContentSecurityPolicy string `yaml:"contentSecurityPolicy"` // Content-Security-Policy, only sent when set
+	// Security headers never sent, such as X-Frame-Options for pages
+	// embedded by other sites
+	Disable []string `yaml:"disable"`
+}
+
+// HSTS configures the Strict-Transport-Security header, sent over TLS only
+type HSTS struct {
+	MaxAge            int  `yaml:"maxAge"` // Seconds (default: one year)
+	IncludeSubDomains bool `yaml:"includeSubDomains"`
+}
+
+// RouteIPFilter configures the client IPs allowed on a route
+type RouteIPFilter struct {
+	AllowCIDRs []string `yaml:"allowCIDRs"`
+	DenyCIDRs  []string `yaml:"denyCIDRs"`
+}
+
+// Concurrency caps the requests in flight through the gateway and per
+// service; routes set their own cap
+type Concurrency struct {
+	MaxInFlight  int                         `yaml:"maxInFlight"`  // Gateway-wide cap; 0 means none
+	MaxQueue     int                         `yaml:"maxQueue"`     // Requests waiting for a gateway-wide slot
+	QueueTimeout int                         `yaml:"queueTimeout"` // Milliseconds a request waits for a slot (default: 1000)
+	Services     map[string]ConcurrencyLimit `yaml:"services"`     // Caps by service name
+	// Queued requests of higher-priority classes are admitted first and
+	// shed last
+	PriorityClasses []PriorityClass `yaml:"priorityClasses"`
+}
+
+// LoadShedding rejects a share of requests with 503 while the gateway
+// process is overloaded. Thresholds left at 0 are not checked.
+type LoadShedding struct {
+	Enabled       bool    `yaml:"enabled"`
+	Interval      int     `yaml:"interval"`      // Milliseconds between load samples (default: 1000)
+	MaxCPU        float64 `yaml:"maxCPU"`        // Share of GOMAXPROCS busy, between 0 and 1
+	MaxGCFraction float64 `yaml:"maxGCFraction"` // Share of CPU time spent in garbage collection, between 0 and 1
+	MaxHeapMB     int     `yaml:"maxHeapMB"`     // Heap size in megabytes
+	MaxGoroutines int     `yaml:"maxGoroutines"`
+	Step          float64 `yaml:"step"`    // Shed rate change per sample (default: 0.1)
+	MaxRate       float64 `yaml:"maxRate"` // Highest share of requests shed (default: 0.9)
+	// Path prefixes never shed, such as health endpoints proxied to backends
+	ExemptPaths []string `yaml:"exemptPaths"`
+	// Shed only requests without an authenticated subject
+	ProtectAuthenticated bool `yaml:"protectAuthenticated"`
+}
+
+// PriorityClass assigns a priority to the requests meeting all of its
+// criteria; requests matching no class have weight 0
+type PriorityClass struct {
+	Name         string            `yaml:"name"`
+	Weight       int               `yaml:"weight"`       // Higher weights are admitted first
+	SubjectTypes []string          `yaml:"subjectTypes"` // Authenticated subject types: user, service or device
+	Scopes       []string          `yaml:"scopes"`       // Any of these scopes
+	Headers      map[string]string `yaml:"headers"`      // Header values
+}
+
+// ConcurrencyLimit caps the requests in flight. Requests over the cap wait
+// in a bounded queue; requests finding the queue full or timing out in it
+// are rejected with 503.
+type ConcurrencyLimit struct {
+	MaxInFlight  int `yaml:"maxInFlight"`  // Initial cap of adaptive limits
+	MaxQueue     int `yaml:"maxQueue"`     // Requests waiting for a slot (default: 0, reject at once)
+	QueueTimeout int `yaml:"queueTimeout"` // Milliseconds a request waits for a slot (default: 1000)
+	// Adjust the cap to the observed backend latency
+	Adaptive *AdaptiveConcurrency `yaml:"adaptive,omitempty"`
+}
+
+// AdaptiveConcurrency bounds a cap that shrinks while backend latency
+// inflates and grows while the backend keeps up
+type AdaptiveConcurrency struct {
+	MinLimit  int     `yaml:"minLimit"` // Default: 1
+	MaxLimit  int     `yaml:"maxLimit"`
+	Tolerance float64 `yaml:"tolerance"` // Latency inflation tolerated, as a ratio of the long-term latency (default: 1.5)
+	Smoothing float64 `yaml:"smoothing"` // Weight of each new estimate, between 0 and 1 (default: 0.2)
+}
+
 // GRPCConfig holds gRPC-specific configuration for a route
 type GRPCConfig struct {
 	// ProtoDescriptor is the path to the proto descriptor file
@@ -478,6 +1219,8 @@ type GRPCConfig struct {
 	Service string `yaml:"service"`
 	// EnableTranscoding enables HTTP to gRPC transcoding
 	EnableTranscoding bool `yaml:"enableTranscoding"`
+	// EnableGRPCWeb translates gRPC-Web calls from browsers to native gRPC
+	EnableGRPCWeb bool `yaml:"enableGrpcWeb"`
 	// TranscodingRules defines custom transcoding rules
 	TranscodingRules map[string]string `yaml:"transcodingRules"`
 	// DynamicDescriptors configuration for loading .desc files
@@ -551,26 +1294,39 @@ type RateLimitStore struct {
 
 // Telemetry configuration
 type Telemetry struct {
-	Enabled bool            `yaml:"enabled"`
-	Service string          `yaml:"service"` // Service name for telemetry
-	Version string          `yaml:"version"` // Service version
-	Tracing TracingConfig   `yaml:"tracing"`
-	Metrics TelemetryMetrics `yaml:"metrics"`
+	Enabled     bool              `yaml:"enabled"`
+	Service     string            `yaml:"service"` // Service name for telemetry
+	Version     string            `yaml:"version"` // Service version
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Metrics     TelemetryMetrics  `yaml:"metrics"`
+	Propagation PropagationConfig `yaml:"propagation"`
+}
+
+// PropagationConfig holds context propagation settings for backend requests
+type PropagationConfig struct {
+	Formats []string `yaml:"formats"` // tracecontext, baggage (default both)
+	Headers []string `yaml:"headers"` // Additional request headers forwarded to backends
 }
 
 // TracingConfig holds tracing configuration
 type TracingConfig struct {
 	Enabled      bool              `yaml:"enabled"`
-	Endpoint     string            `yaml:"endpoint"`     // OTLP endpoint (e.g., localhost:4318)
-	Headers      map[string]string `yaml:"headers"`      // Additional headers for OTLP
+	Exporter     string            `yaml:"exporter"`     // otlp (default), zipkin, jaeger, stdout
+	Endpoint     string            `yaml:"endpoint"`     // Exporter endpoint (e.g., localhost:4318)
+	Headers      map[string]string `yaml:"headers"`      // Additional headers sent by the exporter
 	SampleRate   float64           `yaml:"sampleRate"`   // Sampling rate (0-1)
 	MaxBatchSize int               `yaml:"maxBatchSize"` // Max batch size for export
 	BatchTimeout int               `yaml:"batchTimeout"` // Batch timeout in seconds
+
+	// Per-path sampling rates keyed by path prefix, longest prefix wins
+	RouteSampleRates map[string]float64 `yaml:"routeSampleRates"`
+	SampleErrors     bool               `yaml:"sampleErrors"` // Always export spans ending with an error
 }
 
 // TelemetryMetrics configuration (for OpenTelemetry metrics)
 type TelemetryMetrics struct {
-	Enabled bool `yaml:"enabled"`
+	Enabled    bool `yaml:"enabled"`
+	Prometheus bool `yaml:"prometheus"` // Serve metrics for Prometheus scraping, implies enabled
 	// Additional OTEL metrics configuration can be added here
 }
 
@@ -595,6 +1351,17 @@ type Middleware struct {
 	Auth      *MiddlewareAuth      `yaml:"auth,omitempty"`
 	Authz     *MiddlewareAuthz     `yaml:"authz,omitempty"`
 	Transform *TransformConfig     `yaml:"transform,omitempty"`
+	// Registered middleware inserted into the HTTP handler chain
+	Custom []CustomMiddleware `yaml:"custom,omitempty"`
+}
+
+// CustomMiddleware places a middleware registered with the
+// gateway/pkg/middleware package in the handler chain. Entries at the same
+// position run in the order listed.
+type CustomMiddleware struct {
+	Name     string         `yaml:"name"`
+	Position string         `yaml:"position"` // first, beforeAuth, afterAuth (default), beforeRouting, afterRouting or last
+	Config   map[string]any `yaml:"config"`   // Passed to the middleware factory
 }
 
 // MiddlewareAuth configuration
@@ -614,6 +1381,8 @@ type OAuth2Config struct {
 	TokenQuery      string             `yaml:"tokenQuery"`
 	TokenCookie     string             `yaml:"tokenCookie"`
 	BearerPrefix    string             `yaml:"bearerPrefix"`
+	TokenSources    []TokenSource      `yaml:"tokenSources"` // Tried in order, replacing tokenHeader, tokenQuery and tokenCookie when set
+	SkipPaths       []string           `yaml:"skipPaths"`    // "[METHODS] PATH" patterns that don't require a token
 	RequireScopes   []string           `yaml:"requireScopes"`
 	RequireAudience []string           `yaml:"requireAudience"`
 	ClaimsKey       string             `yaml:"claimsKey"`
@@ -664,7 +1433,7 @@ type RBACPolicy struct {
 type RBACRole struct {
 	Name        string            `yaml:"name"`
 	Description string            `yaml:"description"`
-	Permissions []string          `yaml:"permissions"`
+	Permissions []string          `yaml:"permissions"` // resource:action patterns, e.g. orders:read:{id}; a leading ! denies
 	Inherits    []string          `yaml:"inherits"`
 	Metadata    map[string]string `yaml:"metadata"`
 }
@@ -696,8 +1465,8 @@ type OpenAPIDescriptorConfig struct {
 
 // OpenAPIManagerConfig holds configuration for the OpenAPI manager
 type OpenAPIManagerConfig struct {
-	UpdateStrategy     string            `yaml:"updateStrategy"`     // merge, replace, append
-	ConflictResolution string            `yaml:"conflictResolution"` // newest, error, skip
+	UpdateStrategy     string            `yaml:"updateStrategy"`     // replace (default), merge, append
+	ConflictResolution string            `yaml:"conflictResolution"` // error (default), newest, skip
 	RoutePrefix        string            `yaml:"routePrefix"`
 	RouteGeneration    *RouteGeneration  `yaml:"routeGeneration"`
 }
@@ -738,16 +1507,19 @@ type HeaderTransform struct {
 type BodyTransform struct {
 	Operations []TransformOperation `yaml:"operations"`
 	Format     string               `yaml:"format"`
+	MaxSize    int64                `yaml:"maxSize"` // bytes, 0 means unlimited
 }
 
 // TransformOperation represents a transformation operation
 type TransformOperation struct {
-	Type   string      `yaml:"type"`
-	Path   string      `yaml:"path"`
-	Value  interface{} `yaml:"value"`
-	From   string      `yaml:"from"`
-	To     string      `yaml:"to"`
-	Script string      `yaml:"script"`
+	Type    string      `yaml:"type"`
+	Path    string      `yaml:"path"`
+	Value   interface{} `yaml:"value"`
+	From    string      `yaml:"from"`
+	To      string      `yaml:"to"`
+	Script  string      `yaml:"script"`
+	Include []string    `yaml:"include"`
+	Exclude []string    `yaml:"exclude"`
 }
 
 // TransformCondition represents a transformation condition
@@ -766,6 +1538,8 @@ type VersioningConfig struct {
 	VersionHeader      string                             `yaml:"versionHeader"`      // For header strategy
 	VersionQuery       string                             `yaml:"versionQuery"`       // For query strategy
 	AcceptPattern      string                             `yaml:"acceptPattern"`      // For accept strategy
+	SupportedVersions  []string                           `yaml:"supportedVersions"`  // Versions served in addition to mapped and deprecated ones
+	Strict             bool                               `yaml:"strict"`             // Reject unknown versions with 400 instead of using the default version
 	DeprecatedVersions map[string]*DeprecationInfo        `yaml:"deprecatedVersions"`
 	VersionMappings    map[string]*VersionMapping         `yaml:"versionMappings"`
 }
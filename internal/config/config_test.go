@@ -520,6 +520,44 @@ func TestInstance_ToServiceInstance(t *testing.T) {
 	}
 }
 
+func TestInstance_ToServiceInstanceMetadata(t *testing.T) {
+	instance := Instance{
+		ID:       "instance-1",
+		Tags:     []string{"beta"},
+		Metadata: map[string]string{"region": "eu"},
+	}
+	got := instance.ToServiceInstance("test-service")
+	if got.Metadata["region"] != "eu" {
+		t.Errorf("Expected region metadata, got %v", got.Metadata)
+	}
+	if tags, ok := got.Metadata["tags"].([]string); !ok || len(tags) != 1 || tags[0] != "beta" {
+		t.Errorf("Expected tags metadata, got %v", got.Metadata["tags"])
+	}
+}
+
+func TestHTTPBackend_ForService(t *testing.T) {
+	disable := true
+	backend := HTTPBackend{
+		MaxIdleConnsPerHost: 10,
+		MaxConnsPerHost:     50,
+		KeepAlive:           true,
+		Services: map[string]HTTPBackendService{
+			"legacy": {MaxConnsPerHost: 5, DisableHTTP2: &disable},
+		},
+	}
+
+	legacy := backend.ForService("legacy")
+	if legacy.MaxConnsPerHost != 5 || !legacy.DisableHTTP2 {
+		t.Errorf("Expected the overrides to apply, got %+v", legacy)
+	}
+	if legacy.MaxIdleConnsPerHost != 10 || !legacy.KeepAlive {
+		t.Errorf("Expected the other settings to be kept, got %+v", legacy)
+	}
+	if other := backend.ForService("users"); other.MaxConnsPerHost != 50 || other.DisableHTTP2 {
+		t.Errorf("Expected the backend settings for other services, got %+v", other)
+	}
+}
+
 func TestRouteRule_ToRouteRule(t *testing.T) {
 	tests := []struct {
 		name string
@@ -4,15 +4,26 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// LoadEnv loads configuration from environment variables
+// LoadEnv loads configuration from environment variables. Variables take
+// precedence over values from the configuration file:
+//   - scalars are replaced
+//   - lists are replaced by a comma-separated variable, then single
+//     elements are replaced or appended by indexed variables (PREFIX_0, ...)
+//   - maps get the entries of a "key=value;..." variable and of variables
+//     named after the key (PREFIX_<KEY>), replacing entries with the same key
+//   - optional blocks are created when a variable sets one of their fields
 func LoadEnv(cfg *Config) error {
 	return loadEnvStruct(reflect.ValueOf(cfg).Elem(), "GATEWAY")
 }
 
+var durationType = reflect.TypeOf(time.Duration(0))
+
 // loadEnvStruct recursively loads environment variables into a struct
 func loadEnvStruct(v reflect.Value, prefix string) error {
 	t := v.Type()
@@ -26,93 +37,271 @@ func loadEnvStruct(v reflect.Value, prefix string) error {
 			continue
 		}
 
-		// Get the YAML tag to use as the env var name
-		yamlTag := fieldType.Tag.Get("yaml")
-		if yamlTag == "" || yamlTag == "-" {
+		envName, ok := envFieldName(fieldType)
+		if !ok {
 			continue
 		}
+		if err := loadEnvValue(field, fmt.Sprintf("%s_%s", prefix, envName)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// envFieldName returns the uppercase YAML name of a field
+func envFieldName(field reflect.StructField) (string, bool) {
+	yamlTag := field.Tag.Get("yaml")
+	if yamlTag == "" || yamlTag == "-" {
+		return "", false
+	}
+	// Remove omitempty and other options
+	return strings.ToUpper(strings.Split(yamlTag, ",")[0]), true
+}
+
+// loadEnvValue loads the variable envKey, or the variables prefixed by it,
+// into v
+func loadEnvValue(v reflect.Value, envKey string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		return loadEnvStruct(v, envKey)
+
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return loadEnvValue(v.Elem(), envKey)
+		}
+		// Only create optional blocks that variables set
+		if os.Getenv(envKey) == "" && !hasEnvVarsWithPrefix(envKey) {
+			return nil
+		}
+		elem := reflect.New(v.Type().Elem())
+		if err := loadEnvValue(elem.Elem(), envKey); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+
+	case reflect.Slice:
+		return loadEnvSlice(v, envKey)
+
+	case reflect.Map:
+		return loadEnvMap(v, envKey)
+	}
+
+	if val := os.Getenv(envKey); val != "" {
+		return setEnvValue(v, envKey, val)
+	}
+	return nil
+}
 
-		// Remove omitempty and other options
-		envName := strings.Split(yamlTag, ",")[0]
-		envKey := fmt.Sprintf("%s_%s", prefix, strings.ToUpper(envName))
+// loadEnvSlice replaces a list with the comma-separated values of envKey,
+// then replaces or appends the elements of indexed variables. Elements are
+// appended up to the first index past the end without variables.
+func loadEnvSlice(v reflect.Value, envKey string) error {
+	if val := os.Getenv(envKey); val != "" {
+		if err := setEnvValue(v, envKey, val); err != nil {
+			return err
+		}
+	}
 
-		// Handle different field types
-		switch field.Kind() {
-		case reflect.String:
-			if val := os.Getenv(envKey); val != "" {
-				field.SetString(val)
+	for i := 0; ; i++ {
+		elemKey := fmt.Sprintf("%s_%d", envKey, i)
+		if i >= v.Len() {
+			if os.Getenv(elemKey) == "" && !hasEnvVarsWithPrefix(elemKey) {
+				return nil
 			}
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		}
+		if err := loadEnvValue(v.Index(i), elemKey); err != nil {
+			return err
+		}
+	}
+}
+
+// loadEnvMap adds the "key=value;..." entries of envKey, then the entries
+// of variables named after their key, to a map with string keys
+func loadEnvMap(v reflect.Value, envKey string) error {
+	t := v.Type()
+	if t.Key().Kind() != reflect.String {
+		return nil
+	}
 
-		case reflect.Int, reflect.Int64:
-			if val := os.Getenv(envKey); val != "" {
-				intVal, err := strconv.ParseInt(val, 10, 64)
-				if err != nil {
-					return fmt.Errorf("invalid int value for %s: %v", envKey, err)
-				}
-				field.SetInt(intVal)
+	if val := os.Getenv(envKey); val != "" {
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+		for _, entry := range strings.Split(val, ";") {
+			if strings.TrimSpace(entry) == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(entry, "=")
+			if !ok {
+				return fmt.Errorf("invalid map entry %q for %s: expected key=value", entry, envKey)
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if err := setEnvValue(elem, envKey, strings.TrimSpace(value)); err != nil {
+				return err
 			}
+			v.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)).Convert(t.Key()), elem)
+		}
+	}
 
-		case reflect.Float64:
-			if val := os.Getenv(envKey); val != "" {
-				floatVal, err := strconv.ParseFloat(val, 64)
-				if err != nil {
-					return fmt.Errorf("invalid float value for %s: %v", envKey, err)
-				}
-				field.SetFloat(floatVal)
+	for _, key := range envMapKeys(v, envKey) {
+		mapKey := reflect.ValueOf(key).Convert(t.Key())
+		// Map values cannot be set in place, so update a copy
+		elem := reflect.New(t.Elem()).Elem()
+		if !v.IsNil() {
+			if existing := v.MapIndex(mapKey); existing.IsValid() {
+				elem.Set(existing)
 			}
+		}
+		if err := loadEnvValue(elem, envKey+"_"+key); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+		v.SetMapIndex(mapKey, elem)
+	}
+	return nil
+}
 
-		case reflect.Bool:
-			if val := os.Getenv(envKey); val != "" {
-				boolVal, err := strconv.ParseBool(val)
-				if err != nil {
-					return fmt.Errorf("invalid bool value for %s: %v", envKey, err)
-				}
-				field.SetBool(boolVal)
+// envMapKeys returns the map keys named by variables prefixed by envKey.
+// Keys are case-sensitive. The key of a struct value ends before the first
+// of its field names, unless it is an existing key.
+func envMapKeys(v reflect.Value, envKey string) []string {
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	var existing, fields []string
+	if elemType.Kind() == reflect.Struct {
+		for _, k := range v.MapKeys() {
+			existing = append(existing, k.String())
+		}
+		for i := 0; i < elemType.NumField(); i++ {
+			if name, ok := envFieldName(elemType.Field(i)); ok {
+				fields = append(fields, name)
 			}
+		}
+	}
 
-		case reflect.Slice:
-			if val := os.Getenv(envKey); val != "" {
-				// Handle string slices (comma-separated)
-				if field.Type().Elem().Kind() == reflect.String {
-					parts := strings.Split(val, ",")
-					slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
-					for i, part := range parts {
-						slice.Index(i).SetString(strings.TrimSpace(part))
-					}
-					field.Set(slice)
-				}
+	seen := make(map[string]bool)
+	var keys []string
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		rest, ok := strings.CutPrefix(name, envKey+"_")
+		if !ok || rest == "" || value == "" {
+			continue
+		}
+		key := rest
+		if elemType.Kind() == reflect.Struct {
+			if key, ok = envStructMapKey(rest, existing, fields); !ok {
+				continue
 			}
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
 
-		case reflect.Struct:
-			// Recursively handle nested structs
-			if err := loadEnvStruct(field, envKey); err != nil {
-				return err
+// envStructMapKey splits the map key off rest, a variable name below a map
+// of structs such as "users_ENABLED"
+func envStructMapKey(rest string, existing, fields []string) (string, bool) {
+	key := ""
+	for _, k := range existing {
+		if strings.HasPrefix(rest, k+"_") && len(k) > len(key) {
+			key = k
+		}
+	}
+	if key != "" {
+		return key, true
+	}
+
+	for i := 1; i < len(rest); i++ {
+		if rest[i] != '_' {
+			continue
+		}
+		for _, field := range fields {
+			if after := rest[i+1:]; after == field || strings.HasPrefix(after, field+"_") {
+				return rest[:i], true
 			}
+		}
+	}
+	return "", false
+}
+
+// setEnvValue sets v from the value of variable envKey. Lists are
+// comma-separated.
+func setEnvValue(v reflect.Value, envKey, val string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(val)
 
-		case reflect.Ptr:
-			// Handle pointer fields
-			if field.IsNil() {
-				// Check if any env vars exist for this prefix
-				if hasEnvVarsWithPrefix(envKey) {
-					// Create a new instance
-					field.Set(reflect.New(field.Type().Elem()))
-				} else {
-					continue
-				}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(val)
+			if err != nil {
+				return fmt.Errorf("invalid duration value for %s: %v", envKey, err)
 			}
+			v.SetInt(int64(d))
+			break
+		}
+		intVal, err := strconv.ParseInt(val, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid int value for %s: %v", envKey, err)
+		}
+		v.SetInt(intVal)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintVal, err := strconv.ParseUint(val, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid uint value for %s: %v", envKey, err)
+		}
+		v.SetUint(uintVal)
 
-			if !field.IsNil() {
-				if err := loadEnvStruct(field.Elem(), envKey); err != nil {
-					return err
-				}
+	case reflect.Float32, reflect.Float64:
+		floatVal, err := strconv.ParseFloat(val, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid float value for %s: %v", envKey, err)
+		}
+		v.SetFloat(floatVal)
+
+	case reflect.Bool:
+		boolVal, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid bool value for %s: %v", envKey, err)
+		}
+		v.SetBool(boolVal)
+
+	case reflect.Slice:
+		parts := strings.Split(val, ",")
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setEnvValue(slice.Index(i), envKey, strings.TrimSpace(part)); err != nil {
+				return err
 			}
+		}
+		v.Set(slice)
 
-		case reflect.Map:
-			// Skip maps for now (complex to handle via env vars)
-			continue
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setEnvValue(elem.Elem(), envKey, val); err != nil {
+			return err
 		}
-	}
+		v.Set(elem)
+
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(val))
 
+	default:
+		return fmt.Errorf("%s cannot be set from a single value, set its fields instead", envKey)
+	}
 	return nil
 }
 
@@ -137,44 +326,64 @@ func EnvExample(cfg *Config) []string {
 // generateEnvExamples recursively generates example environment variables
 func generateEnvExamples(t reflect.Type, prefix string, examples *[]string) {
 	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-
-		// Get the YAML tag
-		yamlTag := field.Tag.Get("yaml")
-		if yamlTag == "" || yamlTag == "-" {
+		envName, ok := envFieldName(t.Field(i))
+		if !ok {
 			continue
 		}
+		generateEnvExample(t.Field(i).Type, fmt.Sprintf("%s_%s", prefix, envName), examples)
+	}
+}
 
-		// Remove omitempty and other options
-		envName := strings.Split(yamlTag, ",")[0]
-		envKey := fmt.Sprintf("%s_%s", prefix, strings.ToUpper(envName))
-
-		// Generate examples based on field type
-		switch field.Type.Kind() {
-		case reflect.String:
-			*examples = append(*examples, fmt.Sprintf("%s=value", envKey))
-
-		case reflect.Int, reflect.Int64:
-			*examples = append(*examples, fmt.Sprintf("%s=123", envKey))
-
-		case reflect.Float64:
-			*examples = append(*examples, fmt.Sprintf("%s=1.5", envKey))
+// generateEnvExample generates example environment variables for a field
+// of type t
+func generateEnvExample(t reflect.Type, envKey string, examples *[]string) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
 
-		case reflect.Bool:
-			*examples = append(*examples, fmt.Sprintf("%s=true", envKey))
+	switch t.Kind() {
+	case reflect.Struct:
+		generateEnvExamples(t, envKey, examples)
 
-		case reflect.Slice:
-			if field.Type.Elem().Kind() == reflect.String {
-				*examples = append(*examples, fmt.Sprintf("%s=value1,value2,value3", envKey))
-			}
+	case reflect.Slice:
+		if elem, ok := envExampleValue(t.Elem()); ok {
+			*examples = append(*examples, fmt.Sprintf("%s=%s,%s", envKey, elem, elem))
+		} else {
+			generateEnvExample(t.Elem(), envKey+"_0", examples)
+		}
 
-		case reflect.Struct:
-			generateEnvExamples(field.Type, envKey, examples)
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return
+		}
+		if elem, ok := envExampleValue(t.Elem()); ok {
+			*examples = append(*examples, fmt.Sprintf("%s=key1=%s;key2=%s", envKey, elem, elem))
+		} else {
+			generateEnvExample(t.Elem(), envKey+"_KEY", examples)
+		}
 
-		case reflect.Ptr:
-			if field.Type.Elem().Kind() == reflect.Struct {
-				generateEnvExamples(field.Type.Elem(), envKey, examples)
-			}
+	default:
+		if value, ok := envExampleValue(t); ok {
+			*examples = append(*examples, fmt.Sprintf("%s=%s", envKey, value))
 		}
 	}
 }
+
+// envExampleValue returns an example value of scalar type t
+func envExampleValue(t reflect.Type) (string, bool) {
+	if t == durationType {
+		return "30s", true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Interface:
+		return "value", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "123", true
+	case reflect.Float32, reflect.Float64:
+		return "1.5", true
+	case reflect.Bool:
+		return "true", true
+	}
+	return "", false
+}
@@ -5,6 +5,8 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestLoadEnv(t *testing.T) {
@@ -130,6 +132,24 @@ func TestLoadEnv_InvalidValues(t *testing.T) {
 			value:   "not-a-float",
 			wantErr: true,
 		},
+		{
+			name:    "Invalid map entry",
+			envVar:  "GATEWAY_GATEWAY_MANAGEMENT_AUTH_USERS",
+			value:   "admin",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid indexed element",
+			envVar:  "GATEWAY_GATEWAY_ROUTER_RULES_0_TIMEOUT",
+			value:   "soon",
+			wantErr: true,
+		},
+		{
+			name:    "List of structs as a single value",
+			envVar:  "GATEWAY_GATEWAY_ROUTER_RULES",
+			value:   "users,orders",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -147,6 +167,120 @@ func TestLoadEnv_InvalidValues(t *testing.T) {
 	}
 }
 
+func TestLoadEnv_Collections(t *testing.T) {
+	var cfg Config
+	file := `
+gateway:
+  cors:
+    allowedOrigins: [https://old.example.com]
+    allowedMethods: [GET, HEAD]
+  router:
+    rules:
+      - id: users
+        path: /users
+        serviceName: users
+  health:
+    checks:
+      db:
+        type: tcp
+        timeout: 5
+  management:
+    auth:
+      type: basic
+      users:
+        admin: old-password
+        viewer: viewer-password
+`
+	if err := yaml.Unmarshal([]byte(file), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, v := range map[string]string{
+		// Comma-separated lists replace the file's list
+		"GATEWAY_GATEWAY_CORS_ALLOWEDORIGINS": "https://a.example.com, https://b.example.com",
+		// Indexed variables replace single elements
+		"GATEWAY_GATEWAY_CORS_ALLOWEDMETHODS_1":      "POST",
+		"GATEWAY_GATEWAY_ROUTER_RULES_0_SERVICENAME": "users-v2",
+		// and append elements past the end
+		"GATEWAY_GATEWAY_ROUTER_RULES_1_ID":   "orders",
+		"GATEWAY_GATEWAY_ROUTER_RULES_1_PATH": "/orders",
+		// Map values are updated by key, keys may hold underscores
+		"GATEWAY_GATEWAY_HEALTH_CHECKS_db_TIMEOUT":      "10",
+		"GATEWAY_GATEWAY_HEALTH_CHECKS_cache_db_TYPE":   "http",
+		"GATEWAY_GATEWAY_HEALTH_CHECKS_cache_db_CONFIG": "url=http://cache/health;method=GET",
+		"GATEWAY_GATEWAY_MANAGEMENT_AUTH_USERS":         "admin=new-password; ops=ops-password",
+		"GATEWAY_GATEWAY_MANAGEMENT_AUTH_USERS_ci":      "ci-password",
+		// Optional blocks are created by their variables
+		"GATEWAY_GATEWAY_TELEMETRY_TRACING_ROUTESAMPLERATES": "users=0.5",
+	} {
+		t.Setenv(k, v)
+	}
+
+	if err := LoadEnv(&cfg); err != nil {
+		t.Fatalf("LoadEnv failed: %v", err)
+	}
+
+	gw := cfg.Gateway
+	if want := []string{"https://a.example.com", "https://b.example.com"}; !reflect.DeepEqual(gw.CORS.AllowedOrigins, want) {
+		t.Errorf("AllowedOrigins = %v, want %v", gw.CORS.AllowedOrigins, want)
+	}
+	if want := []string{"GET", "POST"}; !reflect.DeepEqual(gw.CORS.AllowedMethods, want) {
+		t.Errorf("AllowedMethods = %v, want %v", gw.CORS.AllowedMethods, want)
+	}
+
+	wantRules := []RouteRule{
+		{ID: "users", Path: "/users", ServiceName: "users-v2"},
+		{ID: "orders", Path: "/orders"},
+	}
+	if !reflect.DeepEqual(gw.Router.Rules, wantRules) {
+		t.Errorf("Rules = %+v, want %+v", gw.Router.Rules, wantRules)
+	}
+
+	wantChecks := map[string]Check{
+		"db":       {Type: "tcp", Timeout: 10},
+		"cache_db": {Type: "http", Config: map[string]string{"url": "http://cache/health", "method": "GET"}},
+	}
+	if !reflect.DeepEqual(gw.Health.Checks, wantChecks) {
+		t.Errorf("Checks = %+v, want %+v", gw.Health.Checks, wantChecks)
+	}
+
+	wantUsers := map[string]string{
+		"admin":  "new-password",
+		"viewer": "viewer-password",
+		"ops":    "ops-password",
+		"ci":     "ci-password",
+	}
+	if !reflect.DeepEqual(gw.Management.Auth.Users, wantUsers) {
+		t.Errorf("Users = %v, want %v", gw.Management.Auth.Users, wantUsers)
+	}
+
+	if gw.Telemetry == nil || gw.Telemetry.Tracing.RouteSampleRates["users"] != 0.5 {
+		t.Errorf("Telemetry = %+v, want the users sample rate", gw.Telemetry)
+	}
+}
+
+// TestLoadEnv_EnvExample loads every documented example variable
+func TestLoadEnv_EnvExample(t *testing.T) {
+	for _, example := range EnvExample(&Config{}) {
+		key, value, _ := strings.Cut(example, "=")
+		t.Setenv(key, value)
+	}
+
+	var cfg Config
+	if err := LoadEnv(&cfg); err != nil {
+		t.Fatalf("LoadEnv failed on the examples: %v", err)
+	}
+	if cfg.Gateway.Frontend.HTTP.Port != 123 {
+		t.Errorf("Port = %d, want 123", cfg.Gateway.Frontend.HTTP.Port)
+	}
+	if len(cfg.Gateway.Router.Rules) != 1 || cfg.Gateway.Router.Rules[0].ID != "value" {
+		t.Errorf("Rules = %+v, want one example rule", cfg.Gateway.Router.Rules)
+	}
+	if _, ok := cfg.Gateway.Health.Checks["KEY"]; !ok {
+		t.Errorf("Checks = %+v, want the example check", cfg.Gateway.Health.Checks)
+	}
+}
+
 func TestEnvExample(t *testing.T) {
 	cfg := &Config{}
 	examples := EnvExample(cfg)
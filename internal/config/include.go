@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeKey is the top-level key listing the files a configuration file
+// includes
+const includeKey = "include"
+
+// readTree reads the YAML file at path merged over the files it includes.
+// stack holds the files including it, to detect cycles; files collects the
+// files read.
+func readTree(path string, stack []string, files *[]string) (map[string]any, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(stack, abs) {
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(append(stack, abs), " -> "))
+	}
+	stack = append(stack, abs)
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(*files, abs) {
+		*files = append(*files, abs)
+	}
+
+	var tree map[string]any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if tree == nil {
+		tree = make(map[string]any)
+	}
+
+	includes, err := includePaths(abs, tree[includeKey])
+	if err != nil {
+		return nil, err
+	}
+	delete(tree, includeKey)
+
+	// Included files come first, so the including file overrides them
+	merged := make(map[string]any)
+	for _, include := range includes {
+		included, err := readTree(include, stack, files)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeTrees(merged, included).(map[string]any)
+	}
+	return mergeTrees(merged, tree).(map[string]any), nil
+}
+
+// includePaths returns the files an include directive of the file at path
+// names, relative to its directory. Glob patterns expand to their matches
+// in lexical order and may match nothing; other paths must exist.
+func includePaths(path string, value any) ([]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: include must be a list of file paths", path)
+	}
+	var patterns []string
+	for _, item := range list {
+		pattern, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: include must be a list of file paths", path)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	var paths []string
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		if !strings.ContainsAny(pattern, "*?[") {
+			paths = append(paths, pattern)
+			continue
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid include pattern %q: %w", path, pattern, err)
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// mergeTrees merges src over dst. Mappings are merged key by key and
+// lists of mappings identified by id or name are merged item by item,
+// appending new items; src replaces any other value, empty lists included.
+func mergeTrees(dst, src any) any {
+	if srcMap, ok := stringMap(src); ok {
+		dstMap, ok := stringMap(dst)
+		if !ok {
+			return srcMap
+		}
+		for key, value := range srcMap {
+			if existing, ok := dstMap[key]; ok {
+				dstMap[key] = mergeTrees(existing, value)
+			} else {
+				dstMap[key] = value
+			}
+		}
+		return dstMap
+	}
+
+	srcList, ok := src.([]any)
+	if !ok {
+		return src
+	}
+	dstList, ok := dst.([]any)
+	if !ok || len(srcList) == 0 || !keyedList(dstList) || !keyedList(srcList) {
+		return srcList
+	}
+	for _, item := range srcList {
+		key := itemKey(item)
+		i := slices.IndexFunc(dstList, func(existing any) bool { return itemKey(existing) == key })
+		if i < 0 {
+			dstList = append(dstList, item)
+		} else {
+			dstList[i] = mergeTrees(dstList[i], item)
+		}
+	}
+	return dstList
+}
+
+// stringMap returns v as a mapping with string keys. Mappings with other
+// keys, such as status codes, have them formatted.
+func stringMap(v any) (map[string]any, bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		return v, true
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = value
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+// keyedList reports whether every item of list is identified by itemKey
+func keyedList(list []any) bool {
+	for _, item := range list {
+		if itemKey(item) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// itemKey returns the id, or else the name, of a list item
+func itemKey(item any) string {
+	m, ok := stringMap(item)
+	if !ok {
+		return ""
+	}
+	for _, field := range []string{"id", "name"} {
+		if key, ok := m[field].(string); ok && key != "" {
+			return field + ":" + key
+		}
+	}
+	return ""
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfigFiles writes files, by path relative to a temporary
+// directory, and returns the directory
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoader_Include(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"gateway.yaml": `
+include:
+  - base.yaml
+  - routes/*.yaml
+gateway:
+  frontend:
+    http:
+      port: 9090
+  router:
+    rules:
+      # Overrides the timeout of a rule from routes/users.yaml
+      - id: users
+        timeout: 5
+  cors:
+    enabled: true
+    allowedOrigins: [https://app.example.com]
+`,
+		"base.yaml": `
+gateway:
+  frontend:
+    http:
+      host: 0.0.0.0
+      port: 8080
+  registry:
+    type: static
+    static:
+      services:
+        - name: users
+          instances:
+            - {id: users-1, address: 127.0.0.1, port: 3000, health: healthy}
+        - name: orders
+          instances:
+            - {id: orders-1, address: 127.0.0.1, port: 3001, health: healthy}
+  cors:
+    allowedOrigins: [https://old.example.com, https://other.example.com]
+`,
+		"routes/orders.yaml": `
+gateway:
+  router:
+    rules:
+      - {id: orders, path: /orders/*, serviceName: orders, loadBalance: round_robin}
+`,
+		"routes/users.yaml": `
+gateway:
+  router:
+    rules:
+      - {id: users, path: /users/*, serviceName: users, loadBalance: round_robin, timeout: 30}
+`,
+	})
+
+	loader := NewLoader(filepath.Join(dir, "gateway.yaml")).WithEnvVars(false)
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	http := cfg.Gateway.Frontend.HTTP
+	if http.Host != "0.0.0.0" || http.Port != 9090 {
+		t.Errorf("Expected the including file to override included ones, got %s:%d", http.Host, http.Port)
+	}
+
+	// Rules are merged by id in file order: included files in glob order,
+	// then the including file
+	rules := cfg.Gateway.Router.Rules
+	if len(rules) != 2 || rules[0].ID != "orders" || rules[1].ID != "users" {
+		t.Fatalf("Expected the orders and users rules, got %+v", rules)
+	}
+	if rules[1].Timeout != 5 || rules[1].Path != "/users/*" {
+		t.Errorf("Expected the users rule to be merged, got %+v", rules[1])
+	}
+
+	// Lists of values are replaced
+	if want := []string{"https://app.example.com"}; !reflect.DeepEqual(cfg.Gateway.CORS.AllowedOrigins, want) {
+		t.Errorf("AllowedOrigins = %v, want %v", cfg.Gateway.CORS.AllowedOrigins, want)
+	}
+	if len(cfg.Gateway.Registry.Static.Services) != 2 {
+		t.Errorf("Expected the included services, got %+v", cfg.Gateway.Registry.Static.Services)
+	}
+	if cfg.Include != nil {
+		t.Errorf("Expected the include directive to be cleared, got %v", cfg.Include)
+	}
+
+	files := loader.Files()
+	for _, name := range []string{"gateway.yaml", "base.yaml", "routes/orders.yaml", "routes/users.yaml"} {
+		want := filepath.Join(dir, name)
+		found := false
+		for _, file := range files {
+			found = found || file == want
+		}
+		if !found {
+			t.Errorf("Expected %s in Files() = %v", want, files)
+		}
+	}
+}
+
+func TestLoader_IncludeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"gateway.yaml": "include: [a.yaml]\n",
+				"a.yaml":       "include: [b.yaml]\n",
+				"b.yaml":       "include: [a.yaml]\n",
+			},
+			want: "include cycle",
+		},
+		{
+			name:  "missing file",
+			files: map[string]string{"gateway.yaml": "include: [missing.yaml]\n"},
+			want:  "missing.yaml",
+		},
+		{
+			name: "not a list",
+			files: map[string]string{
+				"gateway.yaml": "include: [a.yaml]\n",
+				"a.yaml":       "include: b.yaml\n",
+			},
+			want: "list of file paths",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, tt.files)
+			_, err := NewLoader(filepath.Join(dir, "gateway.yaml")).WithEnvVars(false).Load()
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestMergeTrees(t *testing.T) {
+	dst := map[string]any{
+		"keep":   "a",
+		"scalar": 1,
+		"values": []any{"x", "y"},
+		"named":  []any{map[string]any{"name": "a", "port": 1}, map[string]any{"name": "b"}},
+		"codes":  map[any]any{404: "old"},
+	}
+	src := map[string]any{
+		"scalar": 2,
+		"values": []any{"z"},
+		"named":  []any{map[string]any{"name": "a", "port": 2}, map[string]any{"name": "c"}},
+		"codes":  map[any]any{500: "new"},
+	}
+
+	got := mergeTrees(dst, src)
+	want := map[string]any{
+		"keep":   "a",
+		"scalar": 2,
+		"values": []any{"z"},
+		"named": []any{
+			map[string]any{"name": "a", "port": 2},
+			map[string]any{"name": "b"},
+			map[string]any{"name": "c"},
+		},
+		"codes": map[string]any{"404": "old", "500": "new"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeTrees() = %v, want %v", got, want)
+	}
+}
+
+func TestLoader_Profile(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"gateway.yaml": `
+gateway:
+  frontend:
+    http:
+      port: 8080
+  registry:
+    type: static
+    static:
+      services:
+        - name: users
+          instances:
+            - {id: users-1, address: 127.0.0.1, port: 3000, health: healthy}
+  router:
+    rules:
+      - {id: users, path: /users/*, serviceName: users, loadBalance: round_robin, timeout: 30}
+  cors:
+    enabled: true
+    allowedOrigins: [http://localhost:3000]
+`,
+		"gateway.prod.yaml": `
+include: [prod-routes.yaml]
+gateway:
+  frontend:
+    http:
+      port: 443
+  router:
+    rules:
+      - id: users
+        timeout: 10
+  cors:
+    allowedOrigins: [https://app.example.com]
+`,
+		"prod-routes.yaml": `
+gateway:
+  router:
+    rules:
+      - {id: admin, path: /admin/*, serviceName: users, loadBalance: round_robin}
+`,
+	})
+	path := filepath.Join(dir, "gateway.yaml")
+
+	if got := ProfilePath(path, "prod"); got != filepath.Join(dir, "gateway.prod.yaml") {
+		t.Errorf("ProfilePath() = %s", got)
+	}
+
+	loader := NewLoader(path).WithEnvVars(false).WithProfile("prod")
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Gateway.Frontend.HTTP.Port != 443 {
+		t.Errorf("Expected the profile's port, got %d", cfg.Gateway.Frontend.HTTP.Port)
+	}
+	rules := cfg.Gateway.Router.Rules
+	if len(rules) != 2 || rules[0].ID != "users" || rules[0].Timeout != 10 || rules[0].Path != "/users/*" || rules[1].ID != "admin" {
+		t.Errorf("Expected the users rule merged and the admin rule appended, got %+v", rules)
+	}
+	if want := []string{"https://app.example.com"}; !reflect.DeepEqual(cfg.Gateway.CORS.AllowedOrigins, want) {
+		t.Errorf("AllowedOrigins = %v, want %v", cfg.Gateway.CORS.AllowedOrigins, want)
+	}
+	if files := loader.Files(); len(files) != 3 {
+		t.Errorf("Expected the base, overlay and included files, got %v", files)
+	}
+
+	// The base configuration is used as it is without a profile
+	cfg, err = NewLoader(path).WithEnvVars(false).Load()
+	if err != nil || cfg.Gateway.Frontend.HTTP.Port != 8080 {
+		t.Errorf("Load() = %v, %v; want the base port", cfg, err)
+	}
+
+	for _, profile := range []string{"staging", "../gateway", "a/b"} {
+		if _, err := NewLoader(path).WithEnvVars(false).WithProfile(profile).Load(); err == nil {
+			t.Errorf("Expected profile %q to fail", profile)
+		}
+	}
+}
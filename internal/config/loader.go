@@ -3,15 +3,23 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"gateway/pkg/errors"
 	"gopkg.in/yaml.v3"
 )
 
+// ProfileEnv is the environment variable selecting the profile when none
+// is given
+const ProfileEnv = "GATEWAY_PROFILE"
+
 // Loader loads configuration from file
 type Loader struct {
 	path       string
 	envEnabled bool
+	profile    string
+	files      []string
 }
 
 // NewLoader creates a config loader
@@ -28,8 +36,23 @@ func (l *Loader) WithEnvVars(enabled bool) *Loader {
 	return l
 }
 
+// WithProfile merges the overlay of a profile, such as prod, over the
+// configuration; see ProfilePath
+func (l *Loader) WithProfile(profile string) *Loader {
+	l.profile = profile
+	return l
+}
+
+// ProfilePath returns the overlay file of a profile for the configuration
+// file at path: gateway.yaml has gateway.prod.yaml for the prod profile
+func ProfilePath(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
 // Load loads the configuration
 func (l *Loader) Load() (*Config, error) {
+	l.files = nil
 	data, err := os.ReadFile(l.path)
 	if err != nil {
 		return nil, errors.NewError(errors.ErrorTypeInternal, "failed to read config file").WithCause(err)
@@ -40,6 +63,38 @@ func (l *Loader) Load() (*Config, error) {
 		return nil, errors.NewError(errors.ErrorTypeInternal, "failed to parse config").WithCause(err)
 	}
 
+	// Merge included files and the profile overlay; files without either
+	// are parsed as they are so errors keep their line numbers
+	abs, err := filepath.Abs(l.path)
+	if err != nil {
+		return nil, errors.NewError(errors.ErrorTypeInternal, "failed to read config file").WithCause(err)
+	}
+	l.files = []string{abs}
+	if len(cfg.Include) > 0 || l.profile != "" {
+		l.files = nil
+		tree, err := readTree(l.path, nil, &l.files)
+		if err != nil {
+			return nil, errors.NewError(errors.ErrorTypeInternal, "failed to include config").WithCause(err)
+		}
+		if l.profile != "" {
+			overlay, err := l.readProfile()
+			if err != nil {
+				return nil, errors.NewError(errors.ErrorTypeInternal, "failed to read profile config").
+					WithDetail("profile", l.profile).
+					WithCause(err)
+			}
+			tree = mergeTrees(tree, overlay).(map[string]any)
+		}
+		if data, err = yaml.Marshal(tree); err == nil {
+			cfg = Config{}
+			err = yaml.Unmarshal(data, &cfg)
+		}
+		if err != nil {
+			return nil, errors.NewError(errors.ErrorTypeInternal, "failed to parse config").WithCause(err)
+		}
+	}
+	cfg.Include = nil
+
 	// Override with environment variables if enabled
 	if l.envEnabled {
 		if err := LoadEnv(&cfg); err != nil {
@@ -48,56 +103,26 @@ func (l *Loader) Load() (*Config, error) {
 	}
 
 	// Validate configuration
-	if err := l.validate(&cfg); err != nil {
+	if err := cfg.Validate(); err != nil {
 		return nil, errors.NewError(errors.ErrorTypeBadRequest, "invalid configuration").WithCause(err)
 	}
 
 	return &cfg, nil
 }
 
-// validate validates the configuration
-func (l *Loader) validate(cfg *Config) error {
-	// Validate required fields
-	if cfg.Gateway.Frontend.HTTP.Port == 0 {
-		return errors.NewError(errors.ErrorTypeBadRequest, "frontend HTTP port is required")
-	}
-
-	if cfg.Gateway.Registry.Type == "" {
-		return errors.NewError(errors.ErrorTypeBadRequest, "registry type is required")
-	}
-
-	// Validate registry
-	switch cfg.Gateway.Registry.Type {
-	case "static":
-		if cfg.Gateway.Registry.Static == nil {
-			return fmt.Errorf("static registry configuration is required")
-		}
-	case "docker":
-		if cfg.Gateway.Registry.Docker == nil {
-			return fmt.Errorf("docker registry configuration is required")
-		}
-	default:
-		return fmt.Errorf("unknown registry type: %s", cfg.Gateway.Registry.Type)
-	}
-
-	// Validate routes
-	if len(cfg.Gateway.Router.Rules) == 0 {
-		return fmt.Errorf("at least one route rule is required")
-	}
-
-	for i, rule := range cfg.Gateway.Router.Rules {
-		if rule.ID == "" {
-			return fmt.Errorf("route rule %d: ID is required", i)
-		}
-		if rule.Path == "" {
-			return fmt.Errorf("route rule %d: path is required", i)
-		}
-		if rule.ServiceName == "" {
-			return fmt.Errorf("route rule %d: service name is required", i)
-		}
+// readProfile reads the overlay of the profile and the files it includes
+func (l *Loader) readProfile() (map[string]any, error) {
+	if strings.ContainsAny(l.profile, `/\`) || strings.HasPrefix(l.profile, ".") {
+		return nil, fmt.Errorf("invalid profile name %q", l.profile)
 	}
+	return readTree(ProfilePath(l.path, l.profile), nil, &l.files)
+}
 
-	return nil
+// Files returns the absolute paths of the files the last Load read: the
+// configuration file, its profile overlay and the files they include,
+// including those read by a Load that failed
+func (l *Loader) Files() []string {
+	return l.files
 }
 
 // Load is a convenience function that loads configuration from a file
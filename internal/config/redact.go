@@ -0,0 +1,87 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted replaces secret values in redacted configuration
+const Redacted = "[REDACTED]"
+
+// secretFields are fields, by lowercase YAML name, holding secrets
+var secretFields = map[string]bool{
+	"secret":           true,
+	"clientsecret":     true,
+	"decryptionkey":    true,
+	"decryptionsecret": true,
+	"password":         true,
+	"token":            true,
+	"secretid":         true,
+	"key":              true,
+}
+
+// secretMaps are maps, by lowercase YAML name, whose values are secrets
+var secretMaps = map[string]bool{
+	"users": true, // Management basic auth passwords
+}
+
+// Redact returns c as a tree of YAML field names to values with secrets
+// such as JWT secrets, API keys, passwords and client secrets replaced by
+// Redacted, along with a hash of the unredacted configuration. Equal hashes
+// mean equal effective configuration.
+func Redact(c *Config) (map[string]any, string, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(data)
+
+	var tree map[string]any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, "", err
+	}
+	redactTree(tree)
+	return tree, hex.EncodeToString(sum[:]), nil
+}
+
+func redactTree(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for name, value := range v {
+			lower := strings.ToLower(name)
+			switch {
+			case secretFields[lower] || isSecretHeader(lower):
+				if value != nil && value != "" {
+					v[name] = Redacted
+				}
+			case secretMaps[lower]:
+				if m, ok := value.(map[string]any); ok {
+					for key := range m {
+						m[key] = Redacted
+					}
+				}
+			default:
+				redactTree(value)
+			}
+		}
+	case []any:
+		for _, value := range v {
+			redactTree(value)
+		}
+	}
+}
+
+// isSecretHeader reports whether a header name, such as a key of the
+// headers sent to an API key store, carries credentials. Field names are
+// camel case, so only hyphenated names are taken for headers.
+func isSecretHeader(lower string) bool {
+	switch lower {
+	case "authorization", "proxy-authorization", "cookie":
+		return true
+	}
+	return strings.Contains(lower, "-") &&
+		(strings.Contains(lower, "api-key") || strings.Contains(lower, "token") || strings.Contains(lower, "secret"))
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	cfg := &Config{}
+	cfg.Gateway.Redis = &Redis{Host: "redis", Password: "redis-password"}
+	cfg.Gateway.Auth = &Auth{
+		JWT: &JWTConfig{Enabled: true, Secret: "jwt-secret", Issuer: "issuer"},
+		APIKey: &APIKeyConfig{
+			Enabled: true,
+			Keys:    map[string]*APIKeyDetails{"client": {Key: "api-key-value", Subject: "client"}},
+			HTTP: &APIKeyHTTP{
+				URL:     "https://keys.example.com",
+				Headers: map[string]string{"Authorization": "Bearer store-token", "X-Tenant": "acme"},
+			},
+		},
+	}
+	cfg.Gateway.Management = &Management{
+		Auth: &ManagementAuth{Type: "basic", Users: map[string]string{"admin": "admin-password"}},
+	}
+
+	redacted, hash, err := Redact(cfg)
+	if err != nil {
+		t.Fatalf("Redact() error = %v", err)
+	}
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatalf("Redacted config is not JSON: %v", err)
+	}
+	body := string(data)
+
+	for _, secret := range []string{"redis-password", "jwt-secret", "api-key-value", "store-token", "admin-password"} {
+		if strings.Contains(body, secret) {
+			t.Errorf("Expected %q to be redacted, got %s", secret, body)
+		}
+	}
+	for _, value := range []string{`"issuer"`, `"acme"`, `"admin"`, `"https://keys.example.com"`} {
+		if !strings.Contains(body, value) {
+			t.Errorf("Expected %s to be kept, got %s", value, body)
+		}
+	}
+
+	// The hash changes with secrets although the redacted config does not
+	cfg.Gateway.Redis.Password = "other-password"
+	if _, other, _ := Redact(cfg); other == hash {
+		t.Error("Expected the hash to change with the configuration")
+	}
+}
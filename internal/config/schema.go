@@ -0,0 +1,116 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"gateway/pkg/balancer"
+)
+
+// SchemaURI is the JSON Schema dialect of Schema
+const SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaEnums returns the values of string fields with a fixed set of
+// values, keyed by struct and field name
+func schemaEnums() map[string][]string {
+	return map[string][]string{
+		"RouteRule.LoadBalance":               balancer.Available(),
+		"TCP.LoadBalance":                     {"round_robin", "least_connections"},
+		"Auth.EnforcementMode":                {"enforce", "shadow", "disabled"},
+		"Registry.Type":                       {"static", "docker", "docker-compose", "dns", "swarm", "http"},
+		"VersioningConfig.Strategy":           {"path", "header", "query", "accept"},
+		"Check.Type":                          {"http", "tcp", "exec", "grpc"},
+		"Logging.Format":                      {"json", "text"},
+		"TokenSource.Type":                    {"header", "cookie", "query"},
+		"APIKeyConfig.Source":                 {"static", "vault", "http"},
+		"AuditSink.Type":                      {"file", "stdout", "webhook"},
+		"ManagementAuth.Type":                 {"basic", "token"},
+		"Idempotency.Storage":                 {"memory", "redis"},
+		"SessionAffinityConfig.Storage":       {"memory", "redis"},
+		"RateLimitStore.Type":                 {"memory", "redis"},
+		"SSE.HeartbeatFormat":                 {"comment", "event"},
+		"OpenAPIManagerConfig.UpdateStrategy": {"replace", "merge", "append"},
+	}
+}
+
+// Schema returns a JSON Schema of Config, derived from its fields and YAML
+// tags, for validating and completing configuration files in editors. Each
+// struct is a definition under $defs; fields are optional and unknown
+// fields are rejected.
+func Schema() map[string]any {
+	g := &schemaGenerator{defs: make(map[string]any), enums: schemaEnums()}
+	root := g.object(reflect.TypeOf(Config{}))
+	root["$schema"] = SchemaURI
+	root["title"] = "Gateway configuration"
+	root["$defs"] = g.defs
+	return root
+}
+
+type schemaGenerator struct {
+	defs  map[string]any
+	enums map[string][]string
+}
+
+// schema returns the schema of t. field is the struct and field name t is
+// the type of, used to look up enums.
+func (g *schemaGenerator) schema(t reflect.Type, field string) map[string]any {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem(), field)
+	case reflect.String:
+		s := map[string]any{"type": "string"}
+		if values, ok := g.enums[field]; ok {
+			s["enum"] = values
+		}
+		return s
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem(), field)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem(), field)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, ok := g.defs[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate
+			g.defs[t.Name()] = nil
+			g.defs[t.Name()] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	}
+	// Interfaces take any value
+	return map[string]any{}
+}
+
+// object returns the schema of the YAML fields of struct t
+func (g *schemaGenerator) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.ToLower(field.Name)
+		if tag := field.Tag.Get("yaml"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		properties[name] = g.schema(field.Type, t.Name()+"."+field.Name)
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
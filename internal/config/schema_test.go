@@ -0,0 +1,114 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	if schema["$schema"] != SchemaURI {
+		t.Errorf("$schema = %v, want %s", schema["$schema"], SchemaURI)
+	}
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("Schema is not JSON: %v", err)
+	}
+
+	defs := schema["$defs"].(map[string]any)
+	for _, name := range []string{"Gateway", "RouteRule", "JWTConfig", "Check"} {
+		if _, ok := defs[name]; !ok {
+			t.Errorf("Expected definition %s", name)
+		}
+	}
+
+	property := func(def, name string) map[string]any {
+		t.Helper()
+		properties := defs[def].(map[string]any)["properties"].(map[string]any)
+		p, ok := properties[name].(map[string]any)
+		if !ok {
+			t.Fatalf("%s has no property %s", def, name)
+		}
+		return p
+	}
+
+	// Optional blocks reference their definitions
+	if ref := property("Gateway", "health")["$ref"]; ref != "#/$defs/Health" {
+		t.Errorf("gateway.health $ref = %v", ref)
+	}
+	// Maps and slices describe their values
+	checks := property("Health", "checks")
+	if checks["additionalProperties"].(map[string]any)["$ref"] != "#/$defs/Check" {
+		t.Errorf("health.checks = %v", checks)
+	}
+	if items := property("Router", "rules")["items"].(map[string]any); items["$ref"] != "#/$defs/RouteRule" {
+		t.Errorf("router.rules items = %v", items)
+	}
+
+	for _, tt := range []struct{ def, field, value string }{
+		{"RouteRule", "loadBalance", "least_connections"},
+		{"VersioningConfig", "strategy", "header"},
+		{"Check", "type", "grpc"},
+	} {
+		enum, _ := property(tt.def, tt.field)["enum"].([]string)
+		if !slices.Contains(enum, tt.value) {
+			t.Errorf("%s.%s enum = %v, want %s in it", tt.def, tt.field, enum, tt.value)
+		}
+	}
+}
+
+// TestSchema_ExampleConfigs checks that the top-level configurations only
+// use fields the schema knows
+func TestSchema_ExampleConfigs(t *testing.T) {
+	schema := Schema()
+	defs := schema["$defs"].(map[string]any)
+
+	files, _ := filepath.Glob("../../configs/*.yaml")
+	if len(files) == 0 {
+		t.Skip("no example configurations")
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			continue
+		}
+		checkSchemaFields(t, file, "", doc, schema, defs)
+	}
+}
+
+func checkSchemaFields(t *testing.T, file, path string, value any, schema map[string]any, defs map[string]any) {
+	t.Helper()
+	if ref, ok := schema["$ref"].(string); ok {
+		schema = defs[ref[len("#/$defs/"):]].(map[string]any)
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		for key, child := range v {
+			if properties != nil {
+				p, ok := properties[key].(map[string]any)
+				if !ok {
+					t.Errorf("%s: unknown field %s.%s", file, path, key)
+					continue
+				}
+				checkSchemaFields(t, file, path+"."+key, child, p, defs)
+			} else if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+				checkSchemaFields(t, file, path+"."+key, child, additional, defs)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for _, child := range v {
+				checkSchemaFields(t, file, path+"[]", child, items, defs)
+			}
+		}
+	}
+}
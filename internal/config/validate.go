@@ -0,0 +1,1140 @@
+package config
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/pkg/balancer"
+	"gateway/pkg/clientip"
+	"gateway/pkg/jsonpath"
+	"gateway/pkg/middleware"
+	"gateway/pkg/routing"
+)
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+// Error returns the problems, one per line
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0]
+	}
+	return fmt.Sprintf("%d problems:\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate checks the configuration for mistakes that would otherwise only
+// surface at startup or at request time, such as unknown load balancing
+// strategies or missing TLS files. All problems are reported together in a
+// *ValidationError.
+func (c *Config) Validate() error {
+	v := &validator{}
+	g := &c.Gateway
+
+	// Frontend
+	if g.Frontend.HTTP.UnixSocket == "" {
+		v.port("gateway.frontend.http.port", g.Frontend.HTTP.Port)
+	} else {
+		v.socketMode("gateway.frontend.http.socketMode", g.Frontend.HTTP.SocketMode)
+	}
+	if tls := g.Frontend.HTTP.TLS; tls != nil && tls.Enabled {
+		if acme := tls.ACME; acme != nil && acme.Enabled {
+			v.optionalFile("gateway.frontend.http.tls.certFile", tls.CertFile)
+			v.optionalFile("gateway.frontend.http.tls.keyFile", tls.KeyFile)
+			if len(acme.Domains) == 0 {
+				v.add("gateway.frontend.http.tls.acme.domains: at least one domain is required")
+			}
+			if acme.CacheDir == "" {
+				v.add("gateway.frontend.http.tls.acme.cacheDir: is required")
+			}
+			if acme.HTTPPort != 0 {
+				v.port("gateway.frontend.http.tls.acme.httpPort", acme.HTTPPort)
+			}
+		} else {
+			v.certificates("gateway.frontend.http.tls", tls)
+		}
+	}
+	if h3 := g.Frontend.HTTP.HTTP3; h3 != nil && h3.Enabled {
+		if tls := g.Frontend.HTTP.TLS; tls == nil || !tls.Enabled {
+			v.add("gateway.frontend.http.http3: requires gateway.frontend.http.tls to be enabled")
+		}
+		if g.Frontend.HTTP.UnixSocket != "" {
+			v.add("gateway.frontend.http.http3: cannot be used with a Unix socket listener")
+		}
+		if h3.Port != 0 {
+			v.port("gateway.frontend.http.http3.port", h3.Port)
+		}
+		if h3.AltSvcMaxAge < 0 {
+			v.add("gateway.frontend.http.http3.altSvcMaxAge: must not be negative")
+		}
+	}
+	v.errorPages("gateway.frontend.http.errorPages", g.Frontend.HTTP.ErrorPages)
+	if g.Frontend.HTTP.MaxHeaderBytes < 0 || g.Frontend.HTTP.MaxHeaderCount < 0 {
+		v.add("gateway.frontend.http: maxHeaderBytes and maxHeaderCount must not be negative")
+	}
+	if ws := g.Frontend.WebSocket; ws != nil && ws.Enabled {
+		v.port("gateway.frontend.websocket.port", ws.Port)
+		pongWait := ws.PongWait
+		if pongWait == 0 {
+			pongWait = defaultWebSocketPongWait
+		}
+		if ws.PongWait < 0 || ws.PingPeriod < 0 {
+			v.add("gateway.frontend.websocket: pongWait and pingPeriod must not be negative")
+		} else if ws.PingPeriod >= pongWait {
+			v.add("gateway.frontend.websocket.pingPeriod: must be shorter than pongWait (%ds)", pongWait)
+		}
+		if tls := ws.TLS; tls != nil && tls.Enabled {
+			v.certificates("gateway.frontend.websocket.tls", tls)
+			if tls.ACME != nil && tls.ACME.Enabled {
+				v.add("gateway.frontend.websocket.tls.acme: is only supported by the HTTP frontend")
+			}
+		}
+	}
+	v.cidrs("gateway.frontend.trustedProxies", g.Frontend.TrustedProxies)
+	if g.Frontend.XFFDepth < 0 {
+		v.add("gateway.frontend.xffDepth: must not be negative")
+	}
+	if _, err := clientip.ParseHeader(g.Frontend.ForwardedHeader); err != nil {
+		v.add("gateway.frontend.forwardedHeader: %v", err)
+	}
+
+	// Backend
+	if b := g.Backend.HTTP; b.MaxResponseHeaderBytes < 0 || b.MaxResponseHeaderCount < 0 {
+		v.add("gateway.backend.http: maxResponseHeaderBytes and maxResponseHeaderCount must not be negative")
+	}
+	if g.Backend.HTTP.PoolWaitTimeout < 0 {
+		v.add("gateway.backend.http.poolWaitTimeout: must not be negative")
+	}
+	for name, s := range g.Backend.HTTP.Services {
+		field := fmt.Sprintf("gateway.backend.http.services[%s]", name)
+		if name == "" {
+			v.add("%s: service name is required", field)
+		}
+		if s.MaxIdleConns < 0 || s.MaxIdleConnsPerHost < 0 || s.MaxConnsPerHost < 0 || s.IdleConnTimeout < 0 || s.KeepAliveTimeout < 0 {
+			v.add("%s: connection settings must not be negative", field)
+		}
+	}
+	if tls := g.Backend.HTTP.TLS; tls != nil {
+		v.optionalFile("gateway.backend.http.tls.clientCertFile", tls.ClientCertFile)
+		v.optionalFile("gateway.backend.http.tls.clientKeyFile", tls.ClientKeyFile)
+		v.optionalFile("gateway.backend.http.tls.rootCAFile", tls.RootCAFile)
+	}
+	if s := g.Backend.SSE; s != nil && (s.RetryInterval < 0 || s.MaxRetries < 0) {
+		v.add("gateway.backend.sse: retryInterval and maxRetries must not be negative")
+	}
+
+	// Registry
+	services := v.registry(&g.Registry)
+
+	// TCP frontend
+	if t := g.Frontend.TCP; t != nil && t.Enabled {
+		v.port("gateway.frontend.tcp.port", t.Port)
+		if t.ServiceName == "" {
+			v.add("gateway.frontend.tcp.serviceName: is required")
+		} else if services != nil && !services[t.ServiceName] {
+			v.add("gateway.frontend.tcp.serviceName: unknown service %q", t.ServiceName)
+		}
+		switch core.LoadBalanceStrategy(t.LoadBalance) {
+		case "", core.LoadBalanceRoundRobin, core.LoadBalanceLeastConnections:
+		default:
+			v.add("gateway.frontend.tcp.loadBalance: unsupported strategy %q", t.LoadBalance)
+		}
+		if t.IdleTimeout < 0 || t.DialTimeout < 0 {
+			v.add("gateway.frontend.tcp: timeouts must not be negative")
+		}
+	}
+
+	// SSE frontend
+	if s := g.Frontend.SSE; s != nil && s.Enabled {
+		switch s.HeartbeatFormat {
+		case "", "comment", "event":
+		default:
+			v.add("gateway.frontend.sse.heartbeatFormat: unknown format %q", s.HeartbeatFormat)
+		}
+		switch s.FlushMode {
+		case "", "event", "manual":
+		default:
+			v.add("gateway.frontend.sse.flushMode: unknown mode %q", s.FlushMode)
+		}
+		if s.KeepaliveTimeout < 0 || s.RetryInterval < 0 {
+			v.add("gateway.frontend.sse: keepaliveTimeout and retryInterval must not be negative")
+		}
+	}
+
+	// Routes
+	if o := g.Router.InstanceOverride; o != nil && o.Enabled {
+		if len(o.Secret) < 32 {
+			v.add("gateway.router.instanceOverride.secret: must be at least 32 bytes")
+		}
+		v.headerName("gateway.router.instanceOverride.header", o.Header)
+	}
+	if len(g.Router.Rules) == 0 {
+		v.add("gateway.router.rules: at least one route rule is required")
+	}
+	ids := make(map[string]bool, len(g.Router.Rules))
+	audited := false
+	for i, rule := range g.Router.Rules {
+		field := fmt.Sprintf("gateway.router.rules[%d]", i)
+		if rule.ID == "" {
+			v.add("%s.id: is required", field)
+		} else if ids[rule.ID] {
+			v.add("%s.id: duplicate route ID %q", field, rule.ID)
+		}
+		ids[rule.ID] = true
+
+		if rule.Path == "" {
+			v.add("%s.path: is required", field)
+		}
+		if rule.ServiceName == "" {
+			if rule.TrafficSplit == nil {
+				v.add("%s.serviceName: is required", field)
+			}
+		} else if services != nil && !services[rule.ServiceName] {
+			v.add("%s.serviceName: unknown service %q", field, rule.ServiceName)
+		}
+		if rule.TrafficSplit != nil {
+			v.trafficSplit(field+".trafficSplit", rule.TrafficSplit, services)
+		}
+		if a := rule.SessionAffinityConfig; a != nil && a.Enabled {
+			switch a.Storage {
+			case "", "memory":
+			case "redis":
+				if g.Redis == nil {
+					v.add("%s.sessionAffinity.storage: redis storage requires gateway.redis", field)
+				}
+			default:
+				v.add("%s.sessionAffinity.storage: unknown storage %q", field, a.Storage)
+			}
+		}
+		if m := rule.Mirror; m != nil {
+			if m.ServiceName == "" {
+				v.add("%s.mirror.serviceName: is required", field)
+			} else if services != nil && !services[m.ServiceName] {
+				v.add("%s.mirror.serviceName: unknown service %q", field, m.ServiceName)
+			}
+			if m.Percentage <= 0 || m.Percentage > 100 {
+				v.add("%s.mirror.percentage: must be greater than 0 and at most 100, got %v", field, m.Percentage)
+			}
+			if m.Timeout < 0 {
+				v.add("%s.mirror.timeout: must not be negative", field)
+			}
+		}
+		if i := rule.Idempotency; i != nil {
+			switch rule.Protocol {
+			case "", "http":
+			default:
+				v.add("%s.idempotency: not supported for protocol %q", field, rule.Protocol)
+			}
+			if i.TTL < 0 {
+				v.add("%s.idempotency.ttl: must not be negative", field)
+			}
+		}
+		if t := rule.BackendTimeouts; t != nil && (t.Connect < 0 || t.ResponseHeader < 0 || t.Idle < 0) {
+			v.add("%s.backendTimeouts: connect, responseHeader and idle must not be negative", field)
+		}
+		if c := rule.BackendCompression; c != nil && c.AcceptEncoding != "" && c.AcceptEncoding != "strip" {
+			for _, coding := range strings.Split(c.AcceptEncoding, ",") {
+				name, _, _ := strings.Cut(coding, ";")
+				if !slices.Contains(BackendAcceptEncodings, strings.ToLower(strings.TrimSpace(name))) {
+					v.add("%s.backendCompression.acceptEncoding: unsupported encoding %q", field, strings.TrimSpace(name))
+				}
+			}
+		}
+		if c := rule.Coalesce; c != nil {
+			switch rule.Protocol {
+			case "", "http":
+			default:
+				v.add("%s.coalesce: not supported for protocol %q", field, rule.Protocol)
+			}
+			if c.MaxWait < 0 {
+				v.add("%s.coalesce.maxWait: must not be negative", field)
+			}
+		}
+		if b := rule.BodyLogging; b != nil && b.Enabled {
+			switch rule.Protocol {
+			case "", "http":
+			default:
+				v.add("%s.bodyLogging: not supported for protocol %q", field, rule.Protocol)
+			}
+			if b.MaxBytes < 0 {
+				v.add("%s.bodyLogging.maxBytes: must not be negative", field)
+			}
+			v.jsonPaths(field+".bodyLogging.redact", b.Redact)
+		}
+		if f := rule.Fallback; f != nil {
+			if f.ServiceName != "" && services != nil && !services[f.ServiceName] {
+				v.add("%s.fallback.serviceName: unknown service %q", field, f.ServiceName)
+			}
+			if f.Status != 0 && (f.Status < 100 || f.Status > 599) {
+				v.add("%s.fallback.status: invalid HTTP status %d", field, f.Status)
+			}
+			for _, on := range f.On {
+				if on != "circuit_open" && on != "retries_exhausted" {
+					v.add("%s.fallback.on: unknown trigger %q", field, on)
+				}
+			}
+		}
+		if f := rule.IPFilter; f != nil {
+			v.cidrs(field+".ipFilter.allowCIDRs", f.AllowCIDRs)
+			v.cidrs(field+".ipFilter.denyCIDRs", f.DenyCIDRs)
+		}
+		if c := rule.Concurrency; c != nil {
+			v.concurrencyLimit(field+".concurrency", *c)
+		}
+		if h := rule.ResponseHeaders; h != nil {
+			v.responseHeaders(field+".responseHeaders", h)
+		}
+		if rule.MaxBodySize < 0 {
+			v.add("%s.maxBodySize: must not be negative", field)
+		}
+		if rule.LatencyBudget < 0 {
+			v.add("%s.latencyBudget: must not be negative", field)
+		}
+		for j, t := range rule.AllowedContentTypes {
+			if _, _, err := mime.ParseMediaType(t); err != nil || !strings.Contains(t, "/") || strings.Contains(t, ";") {
+				v.add("%s.allowedContentTypes[%d]: invalid media type %q", field, j, t)
+			}
+		}
+		if rule.LoadBalance != "" && !balancer.Known(rule.LoadBalance) {
+			v.add("%s.loadBalance: unknown strategy %q (available: %s)", field, rule.LoadBalance, strings.Join(balancer.Available(), ", "))
+		}
+		if t := rule.WebSocketTransform; t != nil {
+			if rule.Protocol != "websocket" {
+				v.add("%s.websocketTransform: only supported for protocol \"websocket\"", field)
+			}
+			v.websocketMessageRules(field+".websocketTransform.inbound", t.Inbound)
+			v.websocketMessageRules(field+".websocketTransform.outbound", t.Outbound)
+		}
+		if s := rule.SlowStart; s != nil {
+			switch core.LoadBalanceStrategy(rule.LoadBalance) {
+			case core.LoadBalanceWeightedRoundRobin, core.LoadBalanceWeightedRandom:
+			default:
+				v.add("%s.slowStart: requires loadBalance weighted_round_robin or weighted_random", field)
+			}
+			if s.Window <= 0 {
+				v.add("%s.slowStart.window: must be positive", field)
+			}
+			if s.MinWeightPercent < 0 || s.MinWeightPercent > 100 {
+				v.add("%s.slowStart.minWeightPercent: must be between 0 and 100", field)
+			}
+		}
+		for j, expr := range rule.InstanceSelector {
+			if _, err := core.ParseInstanceSelector([]string{expr}); err != nil {
+				v.add("%s.instanceSelector[%d]: %v", field, j, err)
+			}
+		}
+		switch rule.Protocol {
+		case "", "http", "grpc", "websocket", "sse":
+		default:
+			v.add("%s.protocol: unknown protocol %q", field, rule.Protocol)
+		}
+		if g := rule.GRPC; g != nil && g.EnableGRPCWeb && g.EnableTranscoding {
+			v.add("%s.grpc.enableGrpcWeb: not supported with enableTranscoding", field)
+		}
+		audited = audited || rule.Audit || rule.MustAudit
+	}
+
+	// Audit
+	if a := g.Audit; a != nil {
+		switch a.Sink.Type {
+		case "stdout":
+		case "file":
+			if a.Sink.Path == "" {
+				v.add("gateway.audit.sink.path: is required for file sink")
+			}
+		case "webhook":
+			if a.Sink.URL == "" {
+				v.add("gateway.audit.sink.url: is required for webhook sink")
+			}
+		default:
+			v.add("gateway.audit.sink.type: unknown type %q", a.Sink.Type)
+		}
+		if a.Bodies != nil && a.Bodies.MaxSize < 0 {
+			v.add("gateway.audit.bodies.maxSize: must not be negative")
+		}
+	} else if audited {
+		v.add("gateway.audit: is required when routes enable audit")
+	}
+
+	// IP filter
+	if f := g.IPFilter; f != nil {
+		v.cidrs("gateway.ipFilter.allowCIDRs", f.AllowCIDRs)
+		v.cidrs("gateway.ipFilter.denyCIDRs", f.DenyCIDRs)
+	}
+
+	// Response headers
+	if h := g.ResponseHeaders; h != nil {
+		v.responseHeaders("gateway.responseHeaders", h)
+	}
+
+	// Security headers
+	if s := g.SecurityHeaders; s != nil && s.Enabled {
+		if s.HSTS != nil && s.HSTS.MaxAge < 0 {
+			v.add("gateway.securityHeaders.hsts.maxAge: must not be negative")
+		}
+		for i, name := range s.Disable {
+			if !securityHeaderNames[http.CanonicalHeaderKey(name)] {
+				v.add("gateway.securityHeaders.disable[%d]: unknown security header %q", i, name)
+			}
+		}
+	}
+
+	// Load shedding
+	if l := g.LoadShedding; l != nil && l.Enabled {
+		if l.Interval < 0 || l.MaxHeapMB < 0 || l.MaxGoroutines < 0 {
+			v.add("gateway.loadShedding: interval, maxHeapMB and maxGoroutines must not be negative")
+		}
+		v.share("gateway.loadShedding.maxCPU", l.MaxCPU)
+		v.share("gateway.loadShedding.maxGCFraction", l.MaxGCFraction)
+		v.share("gateway.loadShedding.step", l.Step)
+		v.share("gateway.loadShedding.maxRate", l.MaxRate)
+		if l.MaxCPU == 0 && l.MaxGCFraction == 0 && l.MaxHeapMB == 0 && l.MaxGoroutines == 0 {
+			v.add("gateway.loadShedding: requires maxCPU, maxGCFraction, maxHeapMB or maxGoroutines")
+		}
+		for i, path := range l.ExemptPaths {
+			if !strings.HasPrefix(path, "/") {
+				v.add("gateway.loadShedding.exemptPaths[%d]: must start with /", i)
+			}
+		}
+	}
+
+	// Concurrency limits
+	if c := g.Concurrency; c != nil {
+		if c.MaxInFlight < 0 || c.MaxQueue < 0 || c.QueueTimeout < 0 {
+			v.add("gateway.concurrency: maxInFlight, maxQueue and queueTimeout must not be negative")
+		}
+		names := make([]string, 0, len(c.Services))
+		for name := range c.Services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			field := fmt.Sprintf("gateway.concurrency.services[%s]", name)
+			if services != nil && !services[name] {
+				v.add("%s: unknown service", field)
+			}
+			v.concurrencyLimit(field, c.Services[name])
+		}
+		classes := make(map[string]bool, len(c.PriorityClasses))
+		for i, class := range c.PriorityClasses {
+			field := fmt.Sprintf("gateway.concurrency.priorityClasses[%d]", i)
+			switch {
+			case class.Name == "":
+				v.add("%s.name: is required", field)
+			case class.Name == "default":
+				v.add("%s.name: \"default\" is reserved for requests matching no class", field)
+			case classes[class.Name]:
+				v.add("%s.name: duplicate class %q", field, class.Name)
+			}
+			classes[class.Name] = true
+			if class.Weight <= 0 {
+				v.add("%s.weight: must be positive", field)
+			}
+			if len(class.SubjectTypes) == 0 && len(class.Scopes) == 0 && len(class.Headers) == 0 {
+				v.add("%s: requires subjectTypes, scopes or headers", field)
+			}
+			for j, t := range class.SubjectTypes {
+				switch t {
+				case "user", "service", "device":
+				default:
+					v.add("%s.subjectTypes[%d]: unknown subject type %q", field, j, t)
+				}
+			}
+			for header := range class.Headers {
+				v.headerName(field+".headers", header)
+			}
+		}
+	}
+
+	// Custom middleware
+	if m := g.Middleware; m != nil {
+		for i, custom := range m.Custom {
+			field := fmt.Sprintf("gateway.middleware.custom[%d]", i)
+			if custom.Name == "" {
+				v.add("%s.name: is required", field)
+			} else if _, ok := middleware.Lookup(custom.Name); !ok {
+				v.add("%s.name: unknown middleware %q (registered: %s)", field, custom.Name, strings.Join(middleware.Names(), ", "))
+			}
+			if !middleware.ValidPosition(custom.Position) {
+				v.add("%s.position: unknown position %q", field, custom.Position)
+			}
+		}
+	}
+	if m := g.Middleware; m != nil && m.Transform != nil && m.Transform.Enabled {
+		t := m.Transform
+		for pattern, rule := range t.RequestTransforms {
+			v.transformRule(fmt.Sprintf("gateway.middleware.transform.request[%s]", pattern), &rule)
+		}
+		for pattern, rule := range t.ResponseTransforms {
+			v.transformRule(fmt.Sprintf("gateway.middleware.transform.response[%s]", pattern), &rule)
+		}
+		v.transformRule("gateway.middleware.transform.globalRequest", t.GlobalRequest)
+		v.transformRule("gateway.middleware.transform.globalResponse", t.GlobalResponse)
+	}
+
+	// Logging
+	if l := g.Logging; l != nil && l.AccessLog {
+		switch l.Format {
+		case "", "json", "text":
+		default:
+			v.add("gateway.logging.format: unknown format %q", l.Format)
+		}
+		for i, field := range l.Fields {
+			if !validAccessLogField(field) {
+				v.add("gateway.logging.fields[%d]: unknown field %q", i, field)
+			}
+		}
+		if l.SampleRate < 0 || l.SampleRate > 1 {
+			v.add("gateway.logging.sampleRate: must be between 0 and 1, got %v", l.SampleRate)
+		}
+	}
+
+	// Versioning
+	if ver := g.Versioning; ver != nil && ver.Enabled {
+		switch ver.Strategy {
+		case "path", "header", "query", "accept":
+		default:
+			v.add("gateway.versioning.strategy: unknown strategy %q", ver.Strategy)
+		}
+		if ver.DefaultVersion == "" {
+			v.add("gateway.versioning.defaultVersion: is required")
+		}
+		if ver.AcceptPattern != "" {
+			if _, err := regexp.Compile(ver.AcceptPattern); err != nil {
+				v.add("gateway.versioning.acceptPattern: %v", err)
+			}
+		}
+		for version, info := range ver.DeprecatedVersions {
+			if info == nil || info.SunsetDate == "" {
+				continue
+			}
+			if _, err := time.Parse(time.RFC3339, info.SunsetDate); err != nil {
+				v.add("gateway.versioning.deprecatedVersions[%s].sunsetDate: must be an RFC 3339 time", version)
+			}
+		}
+	}
+
+	// OpenAPI
+	if o := g.OpenAPI; o != nil && o.Enabled {
+		if o.SpecsDirectory == "" && len(o.SpecURLs) == 0 {
+			v.add("gateway.openapi: specsDirectory or specUrls is required")
+		}
+		if o.ReloadInterval < 0 {
+			v.add("gateway.openapi.reloadInterval: must not be negative")
+		}
+		if m := o.Manager; m != nil {
+			switch m.UpdateStrategy {
+			case "", "replace", "merge", "append":
+			default:
+				v.add("gateway.openapi.manager.updateStrategy: unknown strategy %q", m.UpdateStrategy)
+			}
+			switch m.ConflictResolution {
+			case "", "error", "newest", "skip":
+			default:
+				v.add("gateway.openapi.manager.conflictResolution: unknown resolution %q", m.ConflictResolution)
+			}
+			if rg := m.RouteGeneration; rg != nil {
+				switch rg.PathStyle {
+				case "", "exact", "prefix", "wildcard":
+				default:
+					v.add("gateway.openapi.manager.routeGeneration.pathStyle: unknown style %q", rg.PathStyle)
+				}
+			}
+		}
+	}
+
+	// Idempotency
+	if i := g.Idempotency; i != nil {
+		switch i.Storage {
+		case "", "memory":
+		case "redis":
+			if g.Redis == nil {
+				v.add("gateway.idempotency.storage: redis storage requires gateway.redis")
+			}
+		default:
+			v.add("gateway.idempotency.storage: unknown storage %q", i.Storage)
+		}
+		if i.LockTimeout < 0 || i.MaxEntries < 0 || i.MaxBodySize < 0 {
+			v.add("gateway.idempotency: lockTimeout, maxEntries and maxBodySize must not be negative")
+		}
+	}
+
+	// Health
+	if h := g.Health; h != nil && h.DiscoveryTimeout < 0 {
+		v.add("gateway.health.discoveryTimeout: must not be negative")
+	}
+	if h := g.Health; h != nil && h.Enabled && h.Dependencies != nil {
+		d := h.Dependencies
+		if d.Interval < 0 || d.Timeout < 0 {
+			v.add("gateway.health.dependencies: interval and timeout must not be negative")
+		}
+		if d.Redis && g.Redis == nil {
+			v.add("gateway.health.dependencies.redis: requires gateway.redis")
+		}
+		if d.JWKS && (g.Auth == nil || g.Auth.JWT == nil || g.Auth.JWT.JWKSEndpoint == "") {
+			v.add("gateway.health.dependencies.jwks: requires gateway.auth.jwt.jwksEndpoint")
+		}
+		enabled := map[string]bool{"redis": d.Redis, "backends": d.Backends, "jwks": d.JWKS}
+		for i, name := range d.Optional {
+			if _, ok := enabled[name]; !ok {
+				v.add("gateway.health.dependencies.optional[%d]: unknown dependency %q (redis, backends or jwks)", i, name)
+			}
+		}
+		for _, name := range []string{"redis", "backends", "jwks"} {
+			if _, ok := h.Checks[name]; ok && enabled[name] {
+				v.add("gateway.health.checks.%s: name is taken by the %s dependency check", name, name)
+			}
+		}
+	}
+
+	// Auth
+	if a := g.Auth; a != nil {
+		switch a.EnforcementMode {
+		case "", "enforce", "shadow", "disabled":
+		default:
+			v.add("gateway.auth.enforcementMode: unknown mode %q", a.EnforcementMode)
+		}
+		v.skipPaths("gateway.auth.skipPaths", a.SkipPaths)
+	}
+	if a := g.Auth; a != nil && a.APIKey != nil && a.APIKey.Enabled {
+		v.apiKeySource("gateway.auth.apikey", a.APIKey)
+	}
+	if a := g.Auth; a != nil && a.JWT != nil && a.JWT.Enabled {
+		if a.JWT.CacheSize < 0 || a.JWT.CacheMaxTTL < 0 {
+			v.add("gateway.auth.jwt: cacheSize and cacheMaxTTL must not be negative")
+		}
+		// Decryption is off without a key or secret, unless an algorithm asks
+		// for one
+		alg := a.JWT.DecryptionAlgorithm
+		if alg == "" && (a.JWT.DecryptionKey != "" || a.JWT.DecryptionSecret != "") {
+			alg = defaultJWEAlgorithm
+		}
+		if alg != "" {
+			if private, ok := jweAlgorithms[alg]; !ok {
+				v.add("gateway.auth.jwt.decryptionAlgorithm: unknown algorithm %q", alg)
+			} else if private && a.JWT.DecryptionKey == "" {
+				v.add("gateway.auth.jwt.decryptionKey: is required for %s", alg)
+			} else if !private && a.JWT.DecryptionSecret == "" {
+				v.add("gateway.auth.jwt.decryptionSecret: is required for %s", alg)
+			}
+		}
+		v.tokenSources("gateway.auth.jwt.tokenSources", a.JWT.TokenSources)
+	}
+	if m := g.Middleware; m != nil && m.Auth != nil && m.Auth.OAuth2 != nil && m.Auth.OAuth2.Enabled {
+		v.tokenSources("gateway.middleware.auth.oauth2.tokenSources", m.Auth.OAuth2.TokenSources)
+		v.skipPaths("gateway.middleware.auth.oauth2.skipPaths", m.Auth.OAuth2.SkipPaths)
+	}
+	if m := g.Middleware; m != nil && m.Authz != nil && m.Authz.RBAC != nil && m.Authz.RBAC.Enabled {
+		v.skipPaths("gateway.middleware.authz.rbac.skipPaths", m.Authz.RBAC.SkipPaths)
+	}
+	if a := g.Auth; a != nil && a.IdentityHeaders != nil {
+		h := a.IdentityHeaders
+		v.headerName("gateway.auth.identityHeaders.subject", h.Subject)
+		v.headerName("gateway.auth.identityHeaders.type", h.Type)
+		v.headerName("gateway.auth.identityHeaders.scopes", h.Scopes)
+		keys := make([]string, 0, len(h.Metadata))
+		for key := range h.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			name := h.Metadata[key]
+			if name == "" {
+				v.add("gateway.auth.identityHeaders.metadata[%s]: header name is required", key)
+			}
+			v.headerName(fmt.Sprintf("gateway.auth.identityHeaders.metadata[%s]", key), name)
+		}
+		for i, c := range h.Claims {
+			field := fmt.Sprintf("gateway.auth.identityHeaders.claims[%d]", i)
+			if c.Header == "" {
+				v.add("%s.header: is required", field)
+			}
+			v.headerName(field+".header", c.Header)
+			if _, err := template.New(c.Header).Parse(c.Value); err != nil {
+				v.add("%s.value: %v", field, err)
+			}
+		}
+	}
+
+	// Metrics
+	if m := g.Metrics; m != nil && m.UnixSocket != "" {
+		v.socketMode("gateway.metrics.socketMode", m.SocketMode)
+	}
+
+	// Management
+	if m := g.Management; m != nil && m.Enabled {
+		v.port("gateway.management.port", m.Port)
+		if auth := m.Auth; auth != nil {
+			switch auth.Type {
+			case "token":
+				if auth.Token == "" {
+					v.add("gateway.management.auth.token: is required for token auth")
+				}
+			case "basic":
+				if len(auth.Users) == 0 {
+					v.add("gateway.management.auth.users: at least one user is required for basic auth")
+				}
+			default:
+				v.add("gateway.management.auth.type: unknown type %q", auth.Type)
+			}
+		}
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// websocketMessageRules checks the message types of WebSocket transform
+// rules
+func (v *validator) websocketMessageRules(field string, rules *WebSocketMessageRules) {
+	if rules == nil {
+		return
+	}
+	for _, name := range rules.DropTypes {
+		if _, ok := websocketMessageTypes[name]; !ok {
+			v.add("%s.dropTypes: unknown message type %q", field, name)
+		}
+	}
+}
+
+// transformRule checks the JSONPath expressions of project operations
+func (v *validator) transformRule(field string, rule *TransformRule) {
+	if rule == nil || rule.Body == nil {
+		return
+	}
+	for i, op := range rule.Body.Operations {
+		if op.Type != "project" {
+			continue
+		}
+		opField := fmt.Sprintf("%s.body.operations[%d]", field, i)
+		v.jsonPaths(opField+".include", op.Include)
+		v.jsonPaths(opField+".exclude", op.Exclude)
+	}
+}
+
+// jsonPaths checks that each expression is a supported JSONPath
+func (v *validator) jsonPaths(field string, paths []string) {
+	for i, path := range paths {
+		if err := jsonpath.Validate(path); err != nil {
+			v.add("%s[%d]: %v", field, i, err)
+		}
+	}
+}
+
+// headerName checks that a configured header name, if set, is a valid
+// HTTP field name
+func (v *validator) headerName(field, name string) {
+	if name != "" && !headerNamePattern.MatchString(name) {
+		v.add("%s: invalid header name %q", field, name)
+	}
+}
+
+// responseHeaders checks the header names of a response header policy
+func (v *validator) responseHeaders(field string, h *ResponseHeaders) {
+	for _, set := range []struct {
+		field   string
+		headers map[string]string
+	}{{field + ".add", h.Add}, {field + ".defaults", h.Defaults}} {
+		names := make([]string, 0, len(set.headers))
+		for name := range set.headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			v.requiredHeaderName(set.field, name)
+		}
+	}
+	for i, name := range h.Remove {
+		v.requiredHeaderName(fmt.Sprintf("%s.remove[%d]", field, i), name)
+	}
+}
+
+// requiredHeaderName checks that a header name is set and valid
+func (v *validator) requiredHeaderName(field, name string) {
+	if name == "" {
+		v.add("%s: header name is required", field)
+		return
+	}
+	v.headerName(field, name)
+}
+
+// skipPaths checks "[METHODS] PATH" patterns of routing.PathMatcher
+func (v *validator) skipPaths(field string, patterns []string) {
+	for i, pattern := range patterns {
+		if _, err := routing.NewPathMatcher([]string{pattern}); err != nil {
+			v.add("%s[%d]: %v", field, i, err)
+		}
+	}
+}
+
+// errorPages checks that error pages are keyed by an error status code or
+// class and that each variant has either a body or a readable template
+func (v *validator) errorPages(field string, pages map[string]ErrorPage) {
+	keys := make([]string, 0, len(pages))
+	for key := range pages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		f := fmt.Sprintf("%s.%s", field, key)
+		if code, err := strconv.Atoi(key); key != "4xx" && key != "5xx" && (err != nil || code < 400 || code > 599) {
+			v.add("%s: key must be a status code from 400 to 599, 4xx or 5xx", f)
+		}
+		page := pages[key]
+		if page.HTML == nil && page.JSON == nil {
+			v.add("%s: html or json is required", f)
+		}
+		variants := []struct {
+			name string
+			body *ErrorBody
+		}{{"html", page.HTML}, {"json", page.JSON}}
+		for _, variant := range variants {
+			body := variant.body
+			if body == nil {
+				continue
+			}
+			bf := f + "." + variant.name
+			switch {
+			case body.Body != "" && body.Template != "":
+				v.add("%s: body and template are mutually exclusive", bf)
+			case body.Template != "":
+				v.optionalFile(bf+".template", body.Template)
+			}
+			if body.ContentType != "" {
+				if _, _, err := mime.ParseMediaType(body.ContentType); err != nil {
+					v.add("%s.contentType: %v", bf, err)
+				}
+			}
+		}
+	}
+}
+
+// tokenSources checks the places a bearer token is read from
+func (v *validator) tokenSources(field string, sources []TokenSource) {
+	for i, s := range sources {
+		f := fmt.Sprintf("%s[%d]", field, i)
+		switch s.Type {
+		case "header":
+			v.requiredHeaderName(f+".name", s.Name)
+		case "cookie", "query":
+			if s.Name == "" {
+				v.add("%s.name: is required", f)
+			}
+			if s.Scheme != "" {
+				v.add("%s.scheme: only applies to header sources", f)
+			}
+		default:
+			v.add("%s.type: unknown type %q (header, cookie or query)", f, s.Type)
+		}
+	}
+}
+
+// jweAlgorithms are the JWE key management algorithms of
+// gateway.auth.jwt.decryptionAlgorithm, and whether they take a private key
+// rather than a shared secret
+var jweAlgorithms = map[string]bool{
+	"RSA-OAEP": true, "RSA-OAEP-256": true,
+	"ECDH-ES": true, "ECDH-ES+A128KW": true, "ECDH-ES+A192KW": true, "ECDH-ES+A256KW": true,
+	"A128KW": false, "A192KW": false, "A256KW": false,
+	"A128GCMKW": false, "A192GCMKW": false, "A256GCMKW": false,
+	"dir": false,
+}
+
+// defaultWebSocketPongWait is the pongWait in seconds used when it is not
+// set, as in the WebSocket adapter
+const defaultWebSocketPongWait = 60
+
+// defaultJWEAlgorithm is the algorithm used when decryptionAlgorithm is not
+// set, as jwt.DefaultDecryptionAlgorithm
+const defaultJWEAlgorithm = "RSA-OAEP-256"
+
+// securityHeaderNames are the headers gateway.securityHeaders sends
+var securityHeaderNames = map[string]bool{
+	"Strict-Transport-Security": true,
+	"X-Content-Type-Options":    true,
+	"X-Frame-Options":           true,
+	"Referrer-Policy":           true,
+	"Content-Security-Policy":   true,
+}
+
+// headerNamePattern matches HTTP field names, which are RFC 9110 tokens
+var headerNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// cidrs checks that every entry is a CIDR or an IP address
+func (v *validator) cidrs(field string, cidrs []string) {
+	for i, cidr := range cidrs {
+		if _, err := clientip.ParseCIDRs([]string{cidr}); err != nil {
+			v.add("%s[%d]: %v", field, i, err)
+		}
+	}
+}
+
+// share checks that a ratio is between 0 and 1
+func (v *validator) share(field string, value float64) {
+	if value < 0 || value > 1 {
+		v.add("%s: must be between 0 and 1", field)
+	}
+}
+
+// concurrencyLimit checks a concurrency cap
+func (v *validator) concurrencyLimit(field string, c ConcurrencyLimit) {
+	if c.MaxInFlight <= 0 {
+		v.add("%s.maxInFlight: must be positive", field)
+	}
+	if c.MaxQueue < 0 || c.QueueTimeout < 0 {
+		v.add("%s: maxQueue and queueTimeout must not be negative", field)
+	}
+	if a := c.Adaptive; a != nil {
+		minLimit := max(a.MinLimit, 1)
+		if a.MinLimit < 0 {
+			v.add("%s.adaptive.minLimit: must not be negative", field)
+		}
+		if a.MaxLimit < minLimit {
+			v.add("%s.adaptive.maxLimit: must be at least minLimit", field)
+		} else if c.MaxInFlight > 0 && (c.MaxInFlight < minLimit || c.MaxInFlight > a.MaxLimit) {
+			v.add("%s.maxInFlight: must be between adaptive.minLimit and adaptive.maxLimit", field)
+		}
+		if a.Tolerance < 0 {
+			v.add("%s.adaptive.tolerance: must not be negative", field)
+		}
+		if a.Smoothing < 0 || a.Smoothing > 1 {
+			v.add("%s.adaptive.smoothing: must be between 0 and 1", field)
+		}
+	}
+}
+
+// validAccessLogField reports whether field names an access log field
+func validAccessLogField(field string) bool {
+	switch field {
+	case "method", "path", "status", "duration", "bytes", "request_id", "client_ip", "instance", "subject":
+		return true
+	}
+	return false
+}
+
+// validator collects configuration problems
+type validator struct {
+	problems []string
+}
+
+// add records a problem
+func (v *validator) add(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// port checks that a listen port is in range
+func (v *validator) port(field string, port int) {
+	if port <= 0 || port > 65535 {
+		v.add("%s: invalid port %d", field, port)
+	}
+}
+
+// socketMode checks that Unix socket permissions are valid octal
+func (v *validator) socketMode(field, mode string) {
+	if _, err := ParseSocketMode(mode); err != nil {
+		v.add("%s: %v", field, err)
+	}
+}
+
+// trafficSplit checks that a traffic split has known services and a
+// positive total weight
+func (v *validator) trafficSplit(field string, split *TrafficSplit, services map[string]bool) {
+	if len(split.Services) == 0 {
+		v.add("%s.services: at least one service is required", field)
+		return
+	}
+	total := 0
+	for i, service := range split.Services {
+		if service.ServiceName == "" {
+			v.add("%s.services[%d].serviceName: is required", field, i)
+		} else if services != nil && !services[service.ServiceName] {
+			v.add("%s.services[%d].serviceName: unknown service %q", field, i, service.ServiceName)
+		}
+		if service.Weight < 0 {
+			v.add("%s.services[%d].weight: must not be negative", field, i)
+		}
+		total += service.Weight
+	}
+	if total <= 0 {
+		v.add("%s.services: total weight must be positive", field)
+	}
+}
+
+// apiKeySource checks the store API keys are loaded from
+func (v *validator) apiKeySource(field string, cfg *APIKeyConfig) {
+	switch cfg.Source {
+	case "", "static":
+	case "vault":
+		vault := cfg.Vault
+		if vault == nil {
+			v.add("%s.vault: is required for source vault", field)
+			break
+		}
+		if vault.Address == "" {
+			v.add("%s.vault.address: is required", field)
+		}
+		if vault.Path == "" {
+			v.add("%s.vault.path: is required", field)
+		}
+		if vault.Token == "" && vault.TokenFile == "" && vault.AppRole == nil {
+			v.add("%s.vault: one of token, tokenFile or appRole is required", field)
+		}
+		v.optionalFile(field+".vault.tokenFile", vault.TokenFile)
+		if role := vault.AppRole; role != nil && (role.RoleID == "" || role.SecretID == "") {
+			v.add("%s.vault.appRole: roleId and secretId are required", field)
+		}
+	case "http":
+		if cfg.HTTP == nil || cfg.HTTP.URL == "" {
+			v.add("%s.http.url: is required for source http", field)
+		}
+	default:
+		v.add("%s.source: unknown source %q", field, cfg.Source)
+	}
+	if cfg.ExpiryWarningDays < -1 {
+		v.add("%s.expiryWarningDays: must be -1 or more", field)
+	}
+	ids := make([]string, 0, len(cfg.Keys))
+	for id := range cfg.Keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		key := cfg.Keys[id]
+		if key == nil || key.ExpiresAt == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, key.ExpiresAt); err != nil {
+			v.add("%s.keys.%s.expiresAt: must be an RFC 3339 time, got %q", field, id, key.ExpiresAt)
+		}
+	}
+	if cfg.RefreshInterval < 0 {
+		v.add("%s.refreshInterval: must not be negative", field)
+	}
+}
+
+// certificates checks the certificate files of a frontend TLS config. The
+// default certificate is optional when certificates are served by host.
+func (v *validator) certificates(field string, tls *TLS) {
+	if len(tls.Certificates) == 0 {
+		v.file(field+".certFile", tls.CertFile)
+		v.file(field+".keyFile", tls.KeyFile)
+		return
+	}
+	v.optionalFile(field+".certFile", tls.CertFile)
+	v.optionalFile(field+".keyFile", tls.KeyFile)
+	for i, cert := range tls.Certificates {
+		prefix := fmt.Sprintf("%s.certificates[%d]", field, i)
+		if len(cert.Hosts) == 0 {
+			v.add("%s.hosts: at least one host is required", prefix)
+		}
+		v.file(prefix+".certFile", cert.CertFile)
+		v.file(prefix+".keyFile", cert.KeyFile)
+	}
+}
+
+// file checks that a required file is set and readable
+func (v *validator) file(field, path string) {
+	if path == "" {
+		v.add("%s: is required", field)
+		return
+	}
+	v.optionalFile(field, path)
+}
+
+// optionalFile checks that a file, if set, is readable
+func (v *validator) optionalFile(field, path string) {
+	if path == "" {
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		v.add("%s: %v", field, err)
+		return
+	}
+	f.Close()
+}
+
+// registry checks the registry configuration. For static registries it
+// returns the configured service names so routes can be checked against them.
+func (v *validator) registry(r *Registry) map[string]bool {
+	switch r.Type {
+	case "":
+		v.add("gateway.registry.type: is required")
+	case "static":
+		if r.Static == nil {
+			v.add("gateway.registry.static: is required for static registry")
+			return nil
+		}
+		if r.Static.File != "" {
+			if len(r.Static.Services) > 0 {
+				v.add("gateway.registry.static: services and file are mutually exclusive")
+			}
+			v.optionalFile("gateway.registry.static.file", r.Static.File)
+			// Services may be added to the file at any time
+			return nil
+		}
+		services := make(map[string]bool, len(r.Static.Services))
+		for i, svc := range r.Static.Services {
+			if svc.Name == "" {
+				v.add("gateway.registry.static.services[%d].name: is required", i)
+				continue
+			}
+			if services[svc.Name] {
+				v.add("gateway.registry.static.services[%d].name: duplicate service %q", i, svc.Name)
+			}
+			services[svc.Name] = true
+		}
+		return services
+	case "docker":
+		if r.Docker == nil {
+			v.add("gateway.registry.docker: is required for docker registry")
+		}
+	case "docker-compose":
+		if r.DockerCompose == nil {
+			v.add("gateway.registry.dockerCompose: is required for docker-compose registry")
+		}
+	case "dns":
+		if r.DNS == nil {
+			v.add("gateway.registry.dns: is required for dns registry")
+		}
+	case "swarm":
+		if r.Swarm == nil {
+			v.add("gateway.registry.swarm: is required for swarm registry")
+			break
+		}
+		switch r.Swarm.Resolve {
+		case "", "vip", "tasks":
+		default:
+			v.add("gateway.registry.swarm.resolve: must be vip or tasks, got %q", r.Swarm.Resolve)
+		}
+	case "http":
+		if r.HTTP == nil {
+			v.add("gateway.registry.http: is required for http registry")
+			break
+		}
+		if u, err := url.Parse(r.HTTP.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.add("gateway.registry.http.url: must be an http or https URL, got %q", r.HTTP.URL)
+		}
+		if r.HTTP.RefreshInterval < 0 || r.HTTP.Timeout < 0 {
+			v.add("gateway.registry.http: refreshInterval and timeout must not be negative")
+		}
+	default:
+		v.add("gateway.registry.type: unknown type %q", r.Type)
+	}
+	return nil
+}
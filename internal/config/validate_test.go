@@ -0,0 +1,774 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gateway/pkg/middleware"
+)
+
+// validConfig returns a minimal configuration that passes validation
+func validConfig() *Config {
+	return &Config{Gateway: Gateway{
+		Frontend: Frontend{HTTP: HTTP{Port: 8080}},
+		Registry: Registry{
+			Type: "static",
+			Static: &StaticRegistry{Services: []Service{
+				{Name: "users", Instances: []Instance{{ID: "users-1", Address: "127.0.0.1", Port: 9000}}},
+			}},
+		},
+		Router: Router{Rules: []RouteRule{
+			{ID: "users", Path: "/users/*", ServiceName: "users"},
+		}},
+	}}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	certFile := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(certFile, []byte("cert"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Registered once per process, so the error of repeated runs is ignored
+	_ = middleware.Register("header_signer", func(map[string]any) (middleware.Middleware, error) {
+		return nil, nil
+	})
+
+	tests := []struct {
+		name     string
+		modify   func(*Config)
+		problems []string
+	}{
+		{
+			name:   "valid",
+			modify: func(*Config) {},
+		},
+		{
+			name: "invalid port",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.HTTP.Port = 70000
+			},
+			problems: []string{"gateway.frontend.http.port"},
+		},
+		{
+			name: "unknown load balance strategy",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].LoadBalance = "least_conn"
+			},
+			problems: []string{`gateway.router.rules[0].loadBalance: unknown strategy "least_conn" (available: adaptive, consistent_hash, least_connections, response_time, round_robin, sticky_session, weighted_random, weighted_round_robin)`},
+		},
+		{
+			name: "missing TLS key file",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.HTTP.TLS = &TLS{Enabled: true, CertFile: certFile, KeyFile: certFile + ".missing"}
+			},
+			problems: []string{"gateway.frontend.http.tls.keyFile"},
+		},
+		{
+			name: "unknown service and duplicate route",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules = append(c.Gateway.Router.Rules,
+					RouteRule{ID: "users", Path: "/orders/*", ServiceName: "orders"})
+			},
+			problems: []string{
+				`gateway.router.rules[1].id: duplicate route ID "users"`,
+				`gateway.router.rules[1].serviceName: unknown service "orders"`,
+			},
+		},
+		{
+			name: "unknown registry type",
+			modify: func(c *Config) {
+				c.Gateway.Registry.Type = "consul"
+			},
+			problems: []string{`gateway.registry.type: unknown type "consul"`},
+		},
+		{
+			name: "dns registry",
+			modify: func(c *Config) {
+				c.Gateway.Registry = Registry{Type: "dns", DNS: &DNSRegistry{}}
+			},
+		},
+		{
+			name: "swarm registry with unknown resolve mode",
+			modify: func(c *Config) {
+				c.Gateway.Registry = Registry{Type: "swarm", Swarm: &SwarmRegistry{Resolve: "dns"}}
+			},
+			problems: []string{`gateway.registry.swarm.resolve: must be vip or tasks, got "dns"`},
+		},
+		{
+			name: "static registry with both services and a file",
+			modify: func(c *Config) {
+				c.Gateway.Registry.Static.File = "/nonexistent/services.yaml"
+			},
+			problems: []string{
+				"gateway.registry.static: services and file are mutually exclusive",
+				"gateway.registry.static.file: open /nonexistent/services.yaml: no such file or directory",
+			},
+		},
+		{
+			name: "http registry without a URL",
+			modify: func(c *Config) {
+				c.Gateway.Registry = Registry{Type: "http", HTTP: &HTTPRegistry{RefreshInterval: -1}}
+			},
+			problems: []string{
+				`gateway.registry.http.url: must be an http or https URL, got ""`,
+				"gateway.registry.http: refreshInterval and timeout must not be negative",
+			},
+		},
+		{
+			name: "invalid instance selector",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].InstanceSelector = []string{"region=eu", "version>two"}
+			},
+			problems: []string{`gateway.router.rules[0].instanceSelector[1]: selector "version>two": > needs a number or version`},
+		},
+		{
+			name: "audit without sink",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].MustAudit = true
+			},
+			problems: []string{"gateway.audit: is required when routes enable audit"},
+		},
+		{
+			name: "audit webhook without url",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].Audit = true
+				c.Gateway.Audit = &Audit{Sink: AuditSink{Type: "webhook"}}
+			},
+			problems: []string{"gateway.audit.sink.url: is required for webhook sink"},
+		},
+		{
+			name: "versioning",
+			modify: func(c *Config) {
+				c.Gateway.Versioning = &VersioningConfig{
+					Enabled:  true,
+					Strategy: "cookie",
+					DeprecatedVersions: map[string]*DeprecationInfo{
+						"1": {SunsetDate: "next year"},
+					},
+				}
+			},
+			problems: []string{
+				`gateway.versioning.strategy: unknown strategy "cookie"`,
+				"gateway.versioning.defaultVersion: is required",
+				"gateway.versioning.deprecatedVersions[1].sunsetDate",
+			},
+		},
+		{
+			name: "acme without certificate files",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.HTTP.TLS = &TLS{Enabled: true, ACME: &ACME{Enabled: true, Domains: []string{"api.example.com"}}}
+			},
+			problems: []string{"gateway.frontend.http.tls.acme.cacheDir: is required"},
+		},
+		{
+			name: "http3 without TLS",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.HTTP.HTTP3 = &HTTP3{Enabled: true, Port: 70000}
+			},
+			problems: []string{
+				"gateway.frontend.http.http3: requires gateway.frontend.http.tls to be enabled",
+				"gateway.frontend.http.http3.port",
+			},
+		},
+		{
+			name: "websocket tls",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.WebSocket = &WebSocket{Enabled: true, Port: 8081, TLS: &TLS{Enabled: true, CertFile: certFile}}
+			},
+			problems: []string{"gateway.frontend.websocket.tls.keyFile: is required"},
+		},
+		{
+			name: "custom middleware",
+			modify: func(c *Config) {
+				c.Gateway.Middleware = &Middleware{Custom: []CustomMiddleware{
+					{Name: "header_signer", Position: "afterRouting"},
+					{Name: "missing", Position: "middle"},
+				}}
+			},
+			problems: []string{
+				`gateway.middleware.custom[1].name: unknown middleware "missing" (registered: header_signer)`,
+				`gateway.middleware.custom[1].position: unknown position "middle"`,
+			},
+		},
+		{
+			name: "load shedding",
+			modify: func(c *Config) {
+				c.Gateway.LoadShedding = &LoadShedding{Enabled: true, MaxRate: 1.5, ExemptPaths: []string{"health"}}
+			},
+			problems: []string{
+				"gateway.loadShedding.maxRate: must be between 0 and 1",
+				"gateway.loadShedding: requires maxCPU, maxGCFraction, maxHeapMB or maxGoroutines",
+				"gateway.loadShedding.exemptPaths[0]: must start with /",
+			},
+		},
+		{
+			name: "websocket ping period",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.WebSocket = &WebSocket{Enabled: true, Port: 8081, PingPeriod: 60, PongWait: 30}
+			},
+			problems: []string{"gateway.frontend.websocket.pingPeriod: must be shorter than pongWait (30s)"},
+		},
+		{
+			name: "websocket ping period over default pong wait",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.WebSocket = &WebSocket{Enabled: true, Port: 8081, PingPeriod: 90}
+			},
+			problems: []string{"gateway.frontend.websocket.pingPeriod: must be shorter than pongWait (60s)"},
+		},
+		{
+			name: "sni certificates",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.HTTP.TLS = &TLS{Enabled: true, Certificates: []Certificate{
+					{Hosts: []string{"api.foo.com"}, CertFile: certFile, KeyFile: certFile},
+					{CertFile: certFile},
+				}}
+			},
+			problems: []string{
+				"gateway.frontend.http.tls.certificates[1].hosts: at least one host is required",
+				"gateway.frontend.http.tls.certificates[1].keyFile: is required",
+			},
+		},
+		{
+			name: "traffic split",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].ServiceName = ""
+				c.Gateway.Router.Rules[0].TrafficSplit = &TrafficSplit{Services: []WeightedService{
+					{ServiceName: "users", Weight: 0},
+					{ServiceName: "users-canary", Weight: -5},
+				}}
+			},
+			problems: []string{
+				`gateway.router.rules[0].trafficSplit.services[1].serviceName: unknown service "users-canary"`,
+				"gateway.router.rules[0].trafficSplit.services[1].weight: must not be negative",
+				"gateway.router.rules[0].trafficSplit.services: total weight must be positive",
+			},
+		},
+		{
+			name: "api key vault source",
+			modify: func(c *Config) {
+				c.Gateway.Auth = &Auth{APIKey: &APIKeyConfig{
+					Enabled: true,
+					Source:  "vault",
+					Vault:   &APIKeyVault{Address: "https://vault:8200", AppRole: &VaultAppRole{RoleID: "gateway"}},
+				}}
+			},
+			problems: []string{
+				"gateway.auth.apikey.vault.path: is required",
+				"gateway.auth.apikey.vault.appRole: roleId and secretId are required",
+			},
+		},
+		{
+			name: "api key expiry",
+			modify: func(c *Config) {
+				c.Gateway.Auth = &Auth{APIKey: &APIKeyConfig{
+					Enabled:           true,
+					Keys:              map[string]*APIKeyDetails{"billing": {Key: "k", ExpiresAt: "2026-01-01"}},
+					ExpiryWarningDays: -2,
+				}}
+			},
+			problems: []string{
+				"gateway.auth.apikey.expiryWarningDays: must be -1 or more",
+				`gateway.auth.apikey.keys.billing.expiresAt: must be an RFC 3339 time, got "2026-01-01"`,
+			},
+		},
+		{
+			name: "idempotency",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].Protocol = "websocket"
+				c.Gateway.Router.Rules[0].Idempotency = &RouteIdempotency{TTL: -1}
+				c.Gateway.Idempotency = &Idempotency{Storage: "redis"}
+			},
+			problems: []string{
+				`gateway.router.rules[0].idempotency: not supported for protocol "websocket"`,
+				"gateway.router.rules[0].idempotency.ttl: must not be negative",
+				"gateway.idempotency.storage: redis storage requires gateway.redis",
+			},
+		},
+		{
+			name: "backend timeouts",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].BackendTimeouts = &BackendTimeouts{Connect: 2, Idle: -1}
+			},
+			problems: []string{
+				"gateway.router.rules[0].backendTimeouts: connect, responseHeader and idle must not be negative",
+			},
+		},
+		{
+			name: "backend compression",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].BackendCompression = &BackendCompression{AcceptEncoding: "gzip;q=1, br"}
+			},
+			problems: []string{
+				`gateway.router.rules[0].backendCompression.acceptEncoding: unsupported encoding "br"`,
+			},
+		},
+		{
+			name: "session affinity storage",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].SessionAffinityConfig = &SessionAffinityConfig{Enabled: true, Storage: "redis"}
+			},
+			problems: []string{
+				"gateway.router.rules[0].sessionAffinity.storage: redis storage requires gateway.redis",
+			},
+		},
+		{
+			name: "websocket transform",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].WebSocketTransform = &WebSocketTransform{
+					Outbound: &WebSocketMessageRules{DropTypes: []string{"ping"}},
+				}
+			},
+			problems: []string{
+				`gateway.router.rules[0].websocketTransform: only supported for protocol "websocket"`,
+				`gateway.router.rules[0].websocketTransform.outbound.dropTypes: unknown message type "ping"`,
+			},
+		},
+		{
+			name: "ip filter",
+			modify: func(c *Config) {
+				c.Gateway.IPFilter = &IPFilter{DenyCIDRs: []string{"10.0.0.0/8", "fd00::/8", "bogus"}}
+				c.Gateway.Router.Rules[0].IPFilter = &RouteIPFilter{AllowCIDRs: []string{"192.168.1.1", "10.0.0.1/"}}
+			},
+			problems: []string{
+				`gateway.router.rules[0].ipFilter.allowCIDRs[1]: invalid CIDR "10.0.0.1/": invalid CIDR address: 10.0.0.1/`,
+				`gateway.ipFilter.denyCIDRs[2]: invalid IP address or CIDR "bogus"`,
+			},
+		},
+		{
+			name: "response headers",
+			modify: func(c *Config) {
+				c.Gateway.ResponseHeaders = &ResponseHeaders{
+					Add:    map[string]string{"X-Frame-Options": "DENY", "Bad Header": "x"},
+					Remove: []string{"Server", ""},
+				}
+				c.Gateway.Router.Rules[0].ResponseHeaders = &ResponseHeaders{Defaults: map[string]string{"Cache-Control:": "no-store"}}
+			},
+			problems: []string{
+				`gateway.router.rules[0].responseHeaders.defaults: invalid header name "Cache-Control:"`,
+				`gateway.responseHeaders.add: invalid header name "Bad Header"`,
+				`gateway.responseHeaders.remove[1]: header name is required`,
+			},
+		},
+		{
+			name: "jwt decryption",
+			modify: func(c *Config) {
+				c.Gateway.Auth = &Auth{JWT: &JWTConfig{Enabled: true, Secret: "secret", DecryptionAlgorithm: "RSA-OAEP-256"}}
+			},
+			problems: []string{
+				`gateway.auth.jwt.decryptionKey: is required for RSA-OAEP-256`,
+			},
+		},
+		{
+			name: "jwt decryption secret with default algorithm",
+			modify: func(c *Config) {
+				c.Gateway.Auth = &Auth{JWT: &JWTConfig{Enabled: true, Secret: "secret", DecryptionSecret: "0123456789abcdef"}}
+			},
+			problems: []string{
+				`gateway.auth.jwt.decryptionKey: is required for RSA-OAEP-256`,
+			},
+		},
+		{
+			name: "health dependencies",
+			modify: func(c *Config) {
+				c.Gateway.Health = &Health{
+					Enabled:          true,
+					DiscoveryTimeout: -1,
+					Checks:           map[string]Check{"backends": {Type: "http"}},
+					Dependencies: &HealthDependencies{
+						Redis: true, Backends: true, JWKS: true,
+						Interval: -1,
+						Optional: []string{"jwks", "database"},
+					},
+				}
+			},
+			problems: []string{
+				`gateway.health.discoveryTimeout: must not be negative`,
+				`gateway.health.dependencies: interval and timeout must not be negative`,
+				`gateway.health.dependencies.redis: requires gateway.redis`,
+				`gateway.health.dependencies.jwks: requires gateway.auth.jwt.jwksEndpoint`,
+				`gateway.health.dependencies.optional[1]: unknown dependency "database" (redis, backends or jwks)`,
+				`gateway.health.checks.backends: name is taken by the backends dependency check`,
+			},
+		},
+		{
+			name: "error pages",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.HTTP.ErrorPages = map[string]ErrorPage{
+					"404": {HTML: &ErrorBody{Body: "<h1>Not found</h1>"}},
+					"5xx": {
+						HTML: &ErrorBody{Body: "<h1>Oops</h1>", Template: "error.html"},
+						JSON: &ErrorBody{Template: "/nonexistent/error.json", ContentType: "application/"},
+					},
+					"302": {},
+				}
+			},
+			problems: []string{
+				`gateway.frontend.http.errorPages.302: key must be a status code from 400 to 599, 4xx or 5xx`,
+				`gateway.frontend.http.errorPages.302: html or json is required`,
+				`gateway.frontend.http.errorPages.5xx.html: body and template are mutually exclusive`,
+				`gateway.frontend.http.errorPages.5xx.json.template: open /nonexistent/error.json: no such file or directory`,
+				`gateway.frontend.http.errorPages.5xx.json.contentType: mime: expected token after slash`,
+			},
+		},
+		{
+			name: "auth enforcement mode",
+			modify: func(c *Config) {
+				c.Gateway.Auth = &Auth{EnforcementMode: "permissive"}
+			},
+			problems: []string{`gateway.auth.enforcementMode: unknown mode "permissive"`},
+		},
+		{
+			name: "skip paths",
+			modify: func(c *Config) {
+				c.Gateway.Auth = &Auth{SkipPaths: []string{"/public/", "GET,HEAD /health", "get /status", "^/v[0-9/x"}}
+			},
+			problems: []string{
+				`gateway.auth.skipPaths[2]: pattern "get /status": invalid method "get"`,
+				"gateway.auth.skipPaths[3]: pattern \"^/v[0-9/x\": error parsing regexp: missing closing ]: `[0-9/x`",
+			},
+		},
+		{
+			name: "token sources",
+			modify: func(c *Config) {
+				c.Gateway.Auth = &Auth{JWT: &JWTConfig{Enabled: true, Secret: "secret", TokenSources: []TokenSource{
+					{Type: "header", Name: "Authorization", Scheme: "Bearer"},
+					{Type: "cookie", Name: "session", Scheme: "Bearer"},
+					{Type: "query"},
+					{Type: "body", Name: "token"},
+				}}}
+			},
+			problems: []string{
+				`gateway.auth.jwt.tokenSources[1].scheme: only applies to header sources`,
+				`gateway.auth.jwt.tokenSources[2].name: is required`,
+				`gateway.auth.jwt.tokenSources[3].type: unknown type "body" (header, cookie or query)`,
+			},
+		},
+		{
+			name: "body limits",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].MaxBodySize = -1
+				c.Gateway.Router.Rules[0].AllowedContentTypes = []string{"application/json", "text/plain; charset=utf-8", "bogus"}
+			},
+			problems: []string{
+				`gateway.router.rules[0].maxBodySize: must not be negative`,
+				`gateway.router.rules[0].allowedContentTypes[1]: invalid media type "text/plain; charset=utf-8"`,
+				`gateway.router.rules[0].allowedContentTypes[2]: invalid media type "bogus"`,
+			},
+		},
+		{
+			name: "body logging",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].BodyLogging = &BodyLogging{
+					Enabled:  true,
+					MaxBytes: -1,
+					Redact:   []string{"$.password", "password", "$..", "$['token']", "$.items[*].secret"},
+				}
+			},
+			problems: []string{
+				`gateway.router.rules[0].bodyLogging.maxBytes: must not be negative`,
+				`gateway.router.rules[0].bodyLogging.redact[1]: invalid JSONPath "password"`,
+				`gateway.router.rules[0].bodyLogging.redact[2]: invalid JSONPath "$.."`,
+				`gateway.router.rules[0].bodyLogging.redact[3]: invalid JSONPath "$['token']"`,
+			},
+		},
+		{
+			name: "transform projection paths",
+			modify: func(c *Config) {
+				c.Gateway.Middleware = &Middleware{Transform: &TransformConfig{
+					Enabled: true,
+					ResponseTransforms: map[string]TransformRule{
+						"/users/*": {Body: &BodyTransform{Operations: []TransformOperation{
+							{Type: "project", Include: []string{"$.id", "$.items[0].x"}, Exclude: []string{"$['k']"}},
+						}}},
+					},
+					GlobalRequest: &TransformRule{Body: &BodyTransform{Operations: []TransformOperation{
+						{Type: "project", Exclude: []string{"$..password", "user.name"}},
+					}}},
+				}}
+			},
+			problems: []string{
+				`gateway.middleware.transform.response[/users/*].body.operations[0].include[1]: invalid JSONPath "$.items[0].x"`,
+				`gateway.middleware.transform.response[/users/*].body.operations[0].exclude[0]: invalid JSONPath "$['k']"`,
+				`gateway.middleware.transform.globalRequest.body.operations[0].exclude[1]: invalid JSONPath "user.name"`,
+			},
+		},
+		{
+			name: "latency budget",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].LatencyBudget = -1
+			},
+			problems: []string{
+				`gateway.router.rules[0].latencyBudget: must not be negative`,
+			},
+		},
+		{
+			name: "instance override",
+			modify: func(c *Config) {
+				c.Gateway.Router.InstanceOverride = &InstanceOverride{Enabled: true, Header: "X Instance", Secret: "short"}
+			},
+			problems: []string{
+				`gateway.router.instanceOverride.secret: must be at least 32 bytes`,
+				`gateway.router.instanceOverride.header: `,
+			},
+		},
+		{
+			name: "security headers",
+			modify: func(c *Config) {
+				c.Gateway.SecurityHeaders = &SecurityHeaders{
+					Enabled: true,
+					HSTS:    &HSTS{MaxAge: -1},
+					Disable: []string{"x-frame-options", "X-XSS-Protection"},
+				}
+			},
+			problems: []string{
+				`gateway.securityHeaders.hsts.maxAge: must not be negative`,
+				`gateway.securityHeaders.disable[1]: unknown security header "X-XSS-Protection"`,
+			},
+		},
+		{
+			name: "identity headers",
+			modify: func(c *Config) {
+				c.Gateway.Auth = &Auth{IdentityHeaders: &IdentityHeaders{
+					Subject:  "X-Auth-Subject",
+					Scopes:   "X Auth Scopes",
+					Metadata: map[string]string{"email": "X-Auth-Email", "tenant": ""},
+					Claims: []ClaimHeader{
+						{Header: "X-Tenant", Value: "{{ .claims.tenant_id }}"},
+						{Header: "X-Org", Value: "{{ .claims.org"},
+					},
+				}}
+			},
+			problems: []string{
+				`gateway.auth.identityHeaders.scopes: invalid header name "X Auth Scopes"`,
+				"gateway.auth.identityHeaders.metadata[tenant]: header name is required",
+				`gateway.auth.identityHeaders.claims[1].value: template: X-Org:1: unclosed action`,
+			},
+		},
+		{
+			name: "trusted proxies",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.TrustedProxies = []string{"10.0.0.0/8", "proxy.internal"}
+				c.Gateway.Frontend.XFFDepth = -1
+				c.Gateway.Frontend.ForwardedHeader = "X-Client-IP"
+			},
+			problems: []string{
+				`gateway.frontend.trustedProxies[1]: invalid IP address or CIDR "proxy.internal"`,
+				"gateway.frontend.xffDepth: must not be negative",
+				`gateway.frontend.forwardedHeader: unsupported forwarding header "X-Client-IP"`,
+			},
+		},
+		{
+			name: "concurrency limits",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].Concurrency = &ConcurrencyLimit{MaxQueue: -1}
+				c.Gateway.Concurrency = &Concurrency{
+					MaxInFlight: 100,
+					PriorityClasses: []PriorityClass{
+						{Name: "premium", Weight: 100, Scopes: []string{"premium"}},
+						{Name: "premium", SubjectTypes: []string{"robot"}},
+					},
+					Services: map[string]ConcurrencyLimit{
+						"missing": {MaxInFlight: 10},
+						"users": {
+							MaxInFlight: 200,
+							Adaptive:    &AdaptiveConcurrency{MinLimit: 10, MaxLimit: 100, Smoothing: 2},
+						},
+					},
+				}
+			},
+			problems: []string{
+				"gateway.router.rules[0].concurrency.maxInFlight: must be positive",
+				"gateway.router.rules[0].concurrency: maxQueue and queueTimeout must not be negative",
+				"gateway.concurrency.services[missing]: unknown service",
+				"gateway.concurrency.services[users].maxInFlight: must be between adaptive.minLimit and adaptive.maxLimit",
+				"gateway.concurrency.services[users].adaptive.smoothing: must be between 0 and 1",
+				`gateway.concurrency.priorityClasses[1].name: duplicate class "premium"`,
+				"gateway.concurrency.priorityClasses[1].weight: must be positive",
+				`gateway.concurrency.priorityClasses[1].subjectTypes[0]: unknown subject type "robot"`,
+			},
+		},
+		{
+			name: "slow start",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].SlowStart = &SlowStart{MinWeightPercent: 150}
+			},
+			problems: []string{
+				"gateway.router.rules[0].slowStart: requires loadBalance weighted_round_robin or weighted_random",
+				"gateway.router.rules[0].slowStart.window: must be positive",
+				"gateway.router.rules[0].slowStart.minWeightPercent: must be between 0 and 100",
+			},
+		},
+		{
+			name: "grpc web",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].GRPC = &GRPCConfig{EnableTranscoding: true, EnableGRPCWeb: true}
+			},
+			problems: []string{
+				"gateway.router.rules[0].grpc.enableGrpcWeb: not supported with enableTranscoding",
+			},
+		},
+		{
+			name: "coalesce",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].Protocol = "grpc"
+				c.Gateway.Router.Rules[0].Coalesce = &Coalesce{MaxWait: -1}
+			},
+			problems: []string{
+				`gateway.router.rules[0].coalesce: not supported for protocol "grpc"`,
+				"gateway.router.rules[0].coalesce.maxWait: must not be negative",
+			},
+		},
+		{
+			name: "fallback",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].Fallback = &Fallback{ServiceName: "users-cache", Status: 1000, On: []string{"timeout"}}
+			},
+			problems: []string{
+				`gateway.router.rules[0].fallback.serviceName: unknown service "users-cache"`,
+				"gateway.router.rules[0].fallback.status: invalid HTTP status 1000",
+				`gateway.router.rules[0].fallback.on: unknown trigger "timeout"`,
+			},
+		},
+		{
+			name: "mirror",
+			modify: func(c *Config) {
+				c.Gateway.Router.Rules[0].Mirror = &Mirror{ServiceName: "users-shadow", Percentage: 150}
+			},
+			problems: []string{
+				`gateway.router.rules[0].mirror.serviceName: unknown service "users-shadow"`,
+				"gateway.router.rules[0].mirror.percentage",
+			},
+		},
+		{
+			name: "unix socket",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.HTTP.Port = 0
+				c.Gateway.Frontend.HTTP.UnixSocket = "/run/gateway.sock"
+				c.Gateway.Frontend.HTTP.SocketMode = "0999"
+				c.Gateway.Metrics = &Metrics{Enabled: true, UnixSocket: "/run/metrics.sock", SocketMode: "0600"}
+			},
+			problems: []string{`gateway.frontend.http.socketMode: invalid socket mode "0999"`},
+		},
+		{
+			name: "tcp frontend",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.TCP = &TCP{Enabled: true, Port: 9000, ServiceName: "postgres", LoadBalance: "consistent_hash"}
+			},
+			problems: []string{
+				`gateway.frontend.tcp.serviceName: unknown service "postgres"`,
+				`gateway.frontend.tcp.loadBalance: unsupported strategy "consistent_hash"`,
+			},
+		},
+		{
+			name: "sse frontend",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.SSE = &SSE{Enabled: true, HeartbeatFormat: "json", RetryInterval: -1}
+			},
+			problems: []string{
+				`gateway.frontend.sse.heartbeatFormat: unknown format "json"`,
+				"gateway.frontend.sse: keepaliveTimeout and retryInterval must not be negative",
+			},
+		},
+		{
+			name: "header limits",
+			modify: func(c *Config) {
+				c.Gateway.Frontend.HTTP.MaxHeaderCount = -1
+				c.Gateway.Backend.HTTP.MaxResponseHeaderBytes = -1
+			},
+			problems: []string{
+				"gateway.frontend.http: maxHeaderBytes and maxHeaderCount must not be negative",
+				"gateway.backend.http: maxResponseHeaderBytes and maxResponseHeaderCount must not be negative",
+			},
+		},
+		{
+			name: "pool wait timeout",
+			modify: func(c *Config) {
+				c.Gateway.Backend.HTTP.PoolWaitTimeout = -1
+			},
+			problems: []string{
+				"gateway.backend.http.poolWaitTimeout: must not be negative",
+			},
+		},
+		{
+			name: "backend services",
+			modify: func(c *Config) {
+				c.Gateway.Backend.HTTP.Services = map[string]HTTPBackendService{
+					"users":  {MaxConnsPerHost: -1},
+					"orders": {MaxIdleConns: 10},
+				}
+			},
+			problems: []string{
+				"gateway.backend.http.services[users]: connection settings must not be negative",
+			},
+		},
+		{
+			name: "sse backend",
+			modify: func(c *Config) {
+				c.Gateway.Backend.SSE = &SSEBackend{MaxRetries: -1}
+			},
+			problems: []string{
+				"gateway.backend.sse: retryInterval and maxRetries must not be negative",
+			},
+		},
+		{
+			name: "openapi",
+			modify: func(c *Config) {
+				c.Gateway.OpenAPI = &OpenAPIConfig{
+					Enabled: true,
+					Manager: &OpenAPIManagerConfig{
+						UpdateStrategy:     "overwrite",
+						ConflictResolution: "skip",
+						RouteGeneration:    &RouteGeneration{PathStyle: "regex"},
+					},
+				}
+			},
+			problems: []string{
+				"gateway.openapi: specsDirectory or specUrls is required",
+				`gateway.openapi.manager.updateStrategy: unknown strategy "overwrite"`,
+				`gateway.openapi.manager.routeGeneration.pathStyle: unknown style "regex"`,
+			},
+		},
+		{
+			name: "access log",
+			modify: func(c *Config) {
+				c.Gateway.Logging = &Logging{AccessLog: true, Format: "xml", Fields: []string{"status", "latency"}, SampleRate: 2}
+			},
+			problems: []string{
+				`gateway.logging.format: unknown format "xml"`,
+				`gateway.logging.fields[1]: unknown field "latency"`,
+				"gateway.logging.sampleRate",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if len(tt.problems) == 0 {
+				if err != nil {
+					t.Fatalf("Expected valid config, got %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected ValidationError, got %v", err)
+			}
+			if len(validationErr.Problems) != len(tt.problems) {
+				t.Fatalf("Expected %d problems, got %v", len(tt.problems), validationErr.Problems)
+			}
+			for i, want := range tt.problems {
+				if !strings.HasPrefix(validationErr.Problems[i], want) {
+					t.Errorf("Expected problem %q, got %q", want, validationErr.Problems[i])
+				}
+			}
+		})
+	}
+}
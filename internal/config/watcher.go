@@ -18,6 +18,8 @@ type WatcherConfig struct {
 	OnChange func(newConfig *Config) error
 	// Callback function when reload fails
 	OnError func(error)
+	// Profile whose overlay is merged over the configuration
+	Profile string
 }
 
 // DefaultWatcherConfig returns default watcher configuration
@@ -39,6 +41,8 @@ type Watcher struct {
 	stopCh     chan struct{}
 	wg         sync.WaitGroup
 	debouncer  *time.Timer
+	// Files of the configuration, the included ones as of the last load
+	files map[string]bool
 }
 
 // NewWatcher creates a new configuration watcher
@@ -65,6 +69,7 @@ func NewWatcher(configPath string, config *WatcherConfig, logger *slog.Logger) (
 		watcher:    watcher,
 		logger:     logger.With("component", "config-watcher"),
 		stopCh:     make(chan struct{}),
+		files:      make(map[string]bool),
 	}
 
 	// Add config file to watcher
@@ -72,17 +77,46 @@ func NewWatcher(configPath string, config *WatcherConfig, logger *slog.Logger) (
 		watcher.Close()
 		return nil, fmt.Errorf("failed to watch config file: %w", err)
 	}
+	w.watchFiles([]string{absPath})
 
-	// Also watch the directory for atomic writes
-	dir := filepath.Dir(absPath)
-	if err := watcher.Add(dir); err != nil {
-		// Non-fatal: some editors use atomic writes
-		w.logger.Warn("Failed to watch config directory", "dir", dir, "error", err)
-	}
+	// Watch the files it includes and its profile overlay too, as far as
+	// they can be read
+	loader := NewLoader(absPath).WithProfile(config.Profile)
+	loader.Load()
+	w.watchFiles(loader.Files())
 
 	return w, nil
 }
 
+// watchFiles adds configuration files, and their directories for atomic
+// writes, to the watcher
+func (w *Watcher) watchFiles(files []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, file := range files {
+		if w.files[file] {
+			continue
+		}
+		w.files[file] = true
+		if err := w.watcher.Add(file); err != nil {
+			w.logger.Warn("Failed to watch config file", "file", file, "error", err)
+		}
+		dir := filepath.Dir(file)
+		if err := w.watcher.Add(dir); err != nil {
+			// Non-fatal: some editors use atomic writes
+			w.logger.Warn("Failed to watch config directory", "dir", dir, "error", err)
+		}
+	}
+}
+
+// isConfigFile reports whether name is one of the configuration files
+func (w *Watcher) isConfigFile(name string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.files[name]
+}
+
 // Start begins watching for configuration changes
 func (w *Watcher) Start() {
 	w.wg.Add(1)
@@ -134,8 +168,8 @@ func (w *Watcher) watchLoop() {
 
 // handleEvent processes file system events
 func (w *Watcher) handleEvent(event fsnotify.Event) {
-	// Check if event is for our config file
-	if event.Name != w.configPath {
+	// Check if event is for one of our config files
+	if !w.isConfigFile(event.Name) {
 		// Could be a directory event or temp file
 		return
 	}
@@ -158,7 +192,7 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 	case event.Op&fsnotify.Rename == fsnotify.Rename:
 		w.logger.Debug("Config file renamed", "file", event.Name)
 		// Re-add watcher for atomic writes
-		w.watcher.Add(w.configPath)
+		w.watcher.Add(event.Name)
 		w.scheduleReload()
 	}
 }
@@ -188,17 +222,14 @@ func (w *Watcher) scheduleReload() {
 func (w *Watcher) reload() error {
 	w.logger.Info("Reloading configuration", "file", w.configPath)
 
-	// Load new configuration
-	newConfig, err := Load(w.configPath)
+	// Load new configuration, watching newly included files
+	loader := NewLoader(w.configPath).WithProfile(w.config.Profile)
+	newConfig, err := loader.Load()
+	w.watchFiles(loader.Files())
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Validate new configuration
-	if err := w.validateConfig(newConfig); err != nil {
-		return fmt.Errorf("invalid configuration: %w", err)
-	}
-
 	// Apply new configuration
 	if w.config.OnChange != nil {
 		if err := w.config.OnChange(newConfig); err != nil {
@@ -210,33 +241,6 @@ func (w *Watcher) reload() error {
 	return nil
 }
 
-// validateConfig performs basic validation on new configuration
-func (w *Watcher) validateConfig(cfg *Config) error {
-	// Basic validation
-	if cfg.Gateway.Frontend.HTTP.Port <= 0 || cfg.Gateway.Frontend.HTTP.Port > 65535 {
-		return fmt.Errorf("invalid HTTP port: %d", cfg.Gateway.Frontend.HTTP.Port)
-	}
-
-	// Validate at least one service is configured
-	if cfg.Gateway.Registry.Type == "static" && len(cfg.Gateway.Registry.Static.Services) == 0 {
-		return fmt.Errorf("no services configured in static registry")
-	}
-
-	// Validate routes reference existing services
-	serviceMap := make(map[string]bool)
-	for _, svc := range cfg.Gateway.Registry.Static.Services {
-		serviceMap[svc.Name] = true
-	}
-
-	for _, rule := range cfg.Gateway.Router.Rules {
-		if rule.ServiceName != "" && !serviceMap[rule.ServiceName] {
-			return fmt.Errorf("route %s references unknown service: %s", rule.ID, rule.ServiceName)
-		}
-	}
-
-	return nil
-}
-
 // GetCurrentConfig returns the current configuration path
 func (w *Watcher) GetCurrentConfig() string {
 	return w.configPath
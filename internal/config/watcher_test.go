@@ -262,4 +262,66 @@ gateway:
 	if errorCount == 0 {
 		t.Error("Expected validation error")
 	}
-}
\ No newline at end of file
+}
+
+func TestWatcherIncludes(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"gateway.yaml": `
+include: [routes.yaml]
+gateway:
+  frontend:
+    http:
+      port: 8080
+  registry:
+    type: static
+    static:
+      services:
+        - name: test-service
+          instances:
+            - {id: test-1, address: 127.0.0.1, port: 3000, health: healthy}
+`,
+		"routes.yaml": `
+gateway:
+  router:
+    rules:
+      - {id: test-route, path: /test/*, serviceName: test-service, loadBalance: round_robin}
+`,
+	})
+
+	changes := make(chan *Config, 1)
+	watcher, err := NewWatcher(filepath.Join(dir, "gateway.yaml"), &WatcherConfig{
+		DebounceDuration: 50 * time.Millisecond,
+		OnChange: func(cfg *Config) error {
+			changes <- cfg
+			return nil
+		},
+		OnError: func(err error) {
+			t.Errorf("Watcher error: %v", err)
+		},
+	}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher.Start()
+	defer watcher.Stop()
+
+	// Changing an included file reloads the configuration
+	routes := `
+gateway:
+  router:
+    rules:
+      - {id: test-route, path: /other/*, serviceName: test-service, loadBalance: round_robin}
+`
+	if err := os.WriteFile(filepath.Join(dir, "routes.yaml"), []byte(routes), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case cfg := <-changes:
+		if path := cfg.Gateway.Router.Rules[0].Path; path != "/other/*" {
+			t.Errorf("Expected the included rule to change, got %s", path)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a reload after the included file changed")
+	}
+}
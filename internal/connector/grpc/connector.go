@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"sync"
 	"time"
@@ -50,6 +51,8 @@ type Connector struct {
 	clientsMu          sync.RWMutex
 	transcoder         *Transcoder
 	descriptorManager  *DescriptorManager
+	passthrough        *http.Client
+	metrics            BytesRecorder
 }
 
 // New creates a new gRPC connector
@@ -66,9 +69,18 @@ func New(cfg *Config, logger *slog.Logger) *Connector {
 		logger:     logger,
 		clients:    make(map[string]*grpc.ClientConn),
 		transcoder: NewTranscoder(logger),
+		passthrough: &http.Client{
+			Transport: newPassthroughTransport(cfg),
+		},
 	}
 }
 
+// WithMetrics records bytes proxied for passthrough calls
+func (c *Connector) WithMetrics(metrics BytesRecorder) *Connector {
+	c.metrics = metrics
+	return c
+}
+
 // WithTranscoder sets a custom transcoder
 func (c *Connector) WithTranscoder(transcoder *Transcoder) *Connector {
 	c.transcoder = transcoder
@@ -109,6 +121,16 @@ func (c *Connector) Forward(ctx context.Context, req core.Request, route *core.R
 		)
 	}
 
+	// Native gRPC calls are proxied as they are; gRPC-Web calls are
+	// translated on routes enabling it
+	if IsPassthrough(route.Rule) {
+		core.LiftBodyLimit(ctx)
+		if grpcWebEnabled(route.Rule) && isGRPCWebRequest(req) {
+			return c.forwardGRPCWeb(ctx, req, route)
+		}
+		return c.forwardPassthrough(ctx, req, route)
+	}
+
 	// Check if route has gRPC configuration with transcoding enabled
 	if route.Rule != nil && route.Rule.Metadata != nil {
 		if grpcConfig, ok := route.Rule.Metadata["grpc"]; ok {
@@ -329,6 +351,7 @@ func (c *Connector) Close() error {
 	}
 
 	c.clients = make(map[string]*grpc.ClientConn)
+	c.passthrough.CloseIdleConnections()
 	return nil
 }
 
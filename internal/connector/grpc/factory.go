@@ -3,6 +3,7 @@ package grpc
 import (
 	"fmt"
 	"log/slog"
+	"time"
 	
 	"gateway/internal/connector"
 	"gateway/pkg/factory"
@@ -39,10 +40,10 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 		MaxConcurrentStreams:  100,
 		InitialConnWindowSize: 1024 * 1024,
 		InitialWindowSize:     1024 * 1024,
-		KeepAliveTime:         30,
-		KeepAliveTimeout:      10,
+		KeepAliveTime:         30 * time.Second,
+		KeepAliveTimeout:      10 * time.Second,
 		MaxRetryAttempts:      3,
-		RetryTimeout:          5,
+		RetryTimeout:          5 * time.Second,
 		TLS:                   false,
 	}
 	c.connector = New(grpcConfig, c.logger)
@@ -0,0 +1,257 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+)
+
+// gRPC-Web content types; either may carry a +proto or +json suffix
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+)
+
+// grpcWebTrailerFlag marks the frame carrying the trailers at the end of a
+// gRPC-Web response body
+const grpcWebTrailerFlag = 0x80
+
+// isGRPCWebRequest reports whether req is a gRPC-Web call
+func isGRPCWebRequest(req core.Request) bool {
+	contentType := http.Header(req.Headers()).Get("Content-Type")
+	return strings.HasPrefix(contentType, grpcWebContentType)
+}
+
+// grpcWebEnabled reports whether rule translates gRPC-Web calls for its
+// backend
+func grpcWebEnabled(rule *core.RouteRule) bool {
+	if rule == nil {
+		return false
+	}
+	cfg, ok := rule.Metadata["grpc"].(*config.GRPCConfig)
+	return ok && cfg.EnableGRPCWeb
+}
+
+// forwardGRPCWeb translates a gRPC-Web call to a native gRPC call to the
+// backend. Base64 text bodies are decoded, and the backend's trailers are
+// returned in a final frame of the response body, where browsers can read
+// them. Unary and server streaming calls are supported.
+func (c *Connector) forwardGRPCWeb(ctx context.Context, req core.Request, route *core.RouteResult) (core.Response, error) {
+	contentType := http.Header(req.Headers()).Get("Content-Type")
+	text := strings.HasPrefix(contentType, grpcWebTextContentType)
+	subtype := strings.TrimPrefix(strings.TrimPrefix(contentType, grpcWebTextContentType), grpcWebContentType)
+
+	headers := make(http.Header, len(req.Headers()))
+	for key, values := range req.Headers() {
+		headers[key] = values
+	}
+	headers.Set("Content-Type", "application/grpc"+subtype)
+	headers.Del("Content-Length")
+	headers.Del("X-Grpc-Web")
+
+	body := req.Body()
+	if text && body != nil {
+		body = &base64Reader{source: body}
+	}
+
+	resp, err := c.forwardPassthrough(ctx, &grpcWebRequest{Request: req, headers: headers, body: body}, route)
+	if err != nil {
+		return nil, err
+	}
+	// Only gRPC responses are translated; others, such as a 404 from a
+	// backend that is not a gRPC server, are returned as they are
+	if resp.StatusCode() != http.StatusOK {
+		return resp, nil
+	}
+	return newGRPCWebResponse(resp.(*passthroughResponse), contentType, text), nil
+}
+
+// grpcWebRequest replaces the headers and body of a gRPC-Web request
+type grpcWebRequest struct {
+	core.Request
+	headers http.Header
+	body    io.ReadCloser
+}
+
+func (r *grpcWebRequest) Headers() map[string][]string { return r.headers }
+func (r *grpcWebRequest) Body() io.ReadCloser          { return r.body }
+
+// grpcWebResponse is a backend gRPC response translated for a gRPC-Web
+// client. It implements core.TrailerResponse so server streaming messages
+// are flushed as they arrive; it has no HTTP trailers, as they travel in
+// the body.
+type grpcWebResponse struct {
+	headers map[string][]string
+	body    *grpcWebBody
+}
+
+func newGRPCWebResponse(resp *passthroughResponse, contentType string, text bool) *grpcWebResponse {
+	headers := make(map[string][]string, len(resp.headers))
+	var status http.Header
+	for key, values := range resp.headers {
+		switch strings.ToLower(key) {
+		case "content-length", "content-type":
+		case "grpc-status", "grpc-message", "grpc-status-details-bin":
+			// Trailers-only responses carry the status in the headers
+			if status == nil {
+				status = make(http.Header)
+			}
+			status[key] = values
+		default:
+			headers[key] = values
+		}
+	}
+	headers["Content-Type"] = []string{contentType}
+
+	return &grpcWebResponse{
+		headers: headers,
+		body:    &grpcWebBody{source: resp.body, resp: resp, status: status, text: text},
+	}
+}
+
+func (r *grpcWebResponse) StatusCode() int               { return http.StatusOK }
+func (r *grpcWebResponse) Headers() map[string][]string  { return r.headers }
+func (r *grpcWebResponse) Body() io.ReadCloser           { return r.body }
+func (r *grpcWebResponse) Trailers() map[string][]string { return nil }
+
+// grpcWebBody returns the backend's message frames followed by a trailer
+// frame, base64 encoded for text clients
+type grpcWebBody struct {
+	source  io.ReadCloser
+	resp    *passthroughResponse
+	status  http.Header // Status sent in the headers of a trailers-only response
+	text    bool
+	pending []byte
+	buf     []byte
+	done    bool
+}
+
+func (b *grpcWebBody) Read(p []byte) (int, error) {
+	for len(b.pending) == 0 {
+		if b.done {
+			return 0, io.EOF
+		}
+		if b.buf == nil {
+			b.buf = make([]byte, 32*1024)
+		}
+		n, err := b.source.Read(b.buf)
+		if n > 0 {
+			b.pending = b.encode(b.buf[:n])
+		}
+		if err == io.EOF {
+			b.pending = append(b.pending, b.encode(b.trailerFrame())...)
+			b.done = true
+		} else if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+func (b *grpcWebBody) Close() error {
+	return b.source.Close()
+}
+
+// encode returns data as it is sent to the client. Text chunks are encoded
+// separately, with padding, as gRPC-Web clients decode them.
+func (b *grpcWebBody) encode(data []byte) []byte {
+	if !b.text {
+		return append([]byte(nil), data...)
+	}
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(data)))
+	base64.StdEncoding.Encode(encoded, data)
+	return encoded
+}
+
+// trailerFrame builds the frame carrying the backend's trailers, or the
+// status of a trailers-only response
+func (b *grpcWebBody) trailerFrame() []byte {
+	trailers := http.Header(b.resp.Trailers())
+	if trailers.Get("Grpc-Status") == "" {
+		trailers = b.status
+	}
+
+	keys := make([]string, 0, len(trailers))
+	for key := range trailers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var block strings.Builder
+	for _, key := range keys {
+		for _, value := range trailers[key] {
+			block.WriteString(strings.ToLower(key))
+			block.WriteString(": ")
+			block.WriteString(value)
+			block.WriteString("\r\n")
+		}
+	}
+
+	frame := make([]byte, 5, 5+block.Len())
+	frame[0] = grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(block.Len()))
+	return append(frame, block.String()...)
+}
+
+// base64Reader decodes a gRPC-Web text request body. Clients may encode
+// each message separately, so padding can occur mid-stream and every
+// four-character quantum is decoded on its own.
+type base64Reader struct {
+	source  io.ReadCloser
+	quantum []byte
+	pending []byte
+	buf     []byte
+	err     error
+}
+
+func (r *base64Reader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			if r.err == io.EOF && len(r.quantum) > 0 {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, r.err
+		}
+		if r.buf == nil {
+			r.buf = make([]byte, 4*1024)
+		}
+
+		var n int
+		n, r.err = r.source.Read(r.buf)
+		for _, c := range r.buf[:n] {
+			if c == '\r' || c == '\n' || c == ' ' || c == '\t' {
+				continue
+			}
+			r.quantum = append(r.quantum, c)
+			if len(r.quantum) < 4 {
+				continue
+			}
+			var decoded [3]byte
+			m, err := base64.StdEncoding.Decode(decoded[:], r.quantum)
+			if err != nil {
+				r.err = err
+				break
+			}
+			r.pending = append(r.pending, decoded[:m]...)
+			r.quantum = r.quantum[:0]
+		}
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *base64Reader) Close() error {
+	return r.source.Close()
+}
@@ -0,0 +1,147 @@
+package grpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// startGRPCWebGateway starts a health service backend behind a route with
+// gRPC-Web enabled, returning the URL of the Check method
+func startGRPCWebGateway(t *testing.T) string {
+	t.Helper()
+	rule := &core.RouteRule{
+		ID:       "health",
+		Path:     "/grpc.health.v1.Health/*",
+		Protocol: "grpc",
+		Metadata: map[string]interface{}{"grpc": &config.GRPCConfig{EnableGRPCWeb: true}},
+	}
+	addr, _ := startHealthGateway(t, rule, nil)
+	return "http://" + addr + "/grpc.health.v1.Health/"
+}
+
+// grpcWebFrame frames msg as a gRPC message
+func grpcWebFrame(t *testing.T, msg proto.Message) []byte {
+	t.Helper()
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	frame := make([]byte, 5, 5+len(data))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	return append(frame, data...)
+}
+
+// readGRPCWebFrame reads the next frame of a gRPC-Web response
+func readGRPCWebFrame(t *testing.T, r io.Reader) (byte, []byte) {
+	t.Helper()
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		t.Fatalf("Failed to read frame header: %v", err)
+	}
+	data := make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		t.Fatalf("Failed to read frame: %v", err)
+	}
+	return header[0], data
+}
+
+func postGRPCWeb(t *testing.T, url, contentType string, body []byte) *http.Response {
+	t.Helper()
+	resp, err := http.Post(url, contentType, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != contentType {
+		t.Fatalf("Unexpected response %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return resp
+}
+
+func TestConnector_GRPCWeb(t *testing.T) {
+	url := startGRPCWebGateway(t)
+
+	resp := postGRPCWeb(t, url+"Check", "application/grpc-web+proto", grpcWebFrame(t, &healthpb.HealthCheckRequest{}))
+
+	flag, data := readGRPCWebFrame(t, resp.Body)
+	var msg healthpb.HealthCheckResponse
+	if flag != 0 || proto.Unmarshal(data, &msg) != nil || msg.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING message, got flag %x %v", flag, &msg)
+	}
+	flag, data = readGRPCWebFrame(t, resp.Body)
+	if flag != grpcWebTrailerFlag || !strings.Contains(string(data), "grpc-status: 0\r\n") {
+		t.Errorf("Expected OK trailers, got flag %x %q", flag, data)
+	}
+}
+
+func TestConnector_GRPCWebText(t *testing.T) {
+	url := startGRPCWebGateway(t)
+
+	// The status of a trailers-only error response is sent in the body
+	body := base64.StdEncoding.EncodeToString(grpcWebFrame(t, &healthpb.HealthCheckRequest{Service: "unknown"}))
+	resp := postGRPCWeb(t, url+"Check", "application/grpc-web-text", []byte(body))
+
+	flag, data := readGRPCWebFrame(t, &base64Reader{source: resp.Body})
+	if flag != grpcWebTrailerFlag || !strings.Contains(string(data), "grpc-status: 5\r\n") {
+		t.Errorf("Expected NotFound trailers, got flag %x %q", flag, data)
+	}
+	if resp.Header.Get("Grpc-Status") != "" {
+		t.Error("Expected status not to be sent in the headers")
+	}
+}
+
+func TestConnector_GRPCWebServerStream(t *testing.T) {
+	url := startGRPCWebGateway(t)
+
+	resp := postGRPCWeb(t, url+"Watch", "application/grpc-web+proto", grpcWebFrame(t, &healthpb.HealthCheckRequest{}))
+
+	// The first message arrives while the call is still open
+	received := make(chan *healthpb.HealthCheckResponse, 1)
+	go func() {
+		var header [5]byte
+		r := bufio.NewReader(resp.Body)
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			close(received)
+			return
+		}
+		data := make([]byte, binary.BigEndian.Uint32(header[1:]))
+		io.ReadFull(r, data)
+		var msg healthpb.HealthCheckResponse
+		proto.Unmarshal(data, &msg)
+		received <- &msg
+	}()
+
+	select {
+	case msg := <-received:
+		if msg == nil || msg.Status != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("Expected SERVING message, got %v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected streamed message before the call ends")
+	}
+}
+
+func TestBase64Reader(t *testing.T) {
+	// Messages encoded separately are padded mid-stream
+	encoded := base64.StdEncoding.EncodeToString([]byte("ab")) + "\r\n" + base64.StdEncoding.EncodeToString([]byte("cde"))
+	data, err := io.ReadAll(&base64Reader{source: io.NopCloser(strings.NewReader(encoded))})
+	if err != nil || string(data) != "abcde" {
+		t.Errorf("Expected %q, got %q (%v)", "abcde", data, err)
+	}
+
+	if _, err := io.ReadAll(&base64Reader{source: io.NopCloser(strings.NewReader("YWJj!"))}); err == nil {
+		t.Error("Expected invalid base64 to fail")
+	}
+}
@@ -0,0 +1,189 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+	"gateway/pkg/errors"
+)
+
+// BytesRecorder records bytes proxied for passthrough gRPC calls. Direction
+// is "received" for bytes from the client and "sent" for bytes to it.
+type BytesRecorder interface {
+	RecordGRPCBytes(ctx context.Context, service, direction string, size int64)
+}
+
+// IsPassthrough reports whether requests matching rule are native gRPC calls
+// proxied to the backend as they are, rather than transcoded from HTTP/JSON
+func IsPassthrough(rule *core.RouteRule) bool {
+	if rule == nil || rule.Protocol != "grpc" {
+		return false
+	}
+	if cfg, ok := rule.Metadata["grpc"].(*config.GRPCConfig); ok && cfg.EnableTranscoding {
+		return false
+	}
+	return true
+}
+
+// newPassthroughTransport creates the HTTP/2 transport for passthrough calls
+func newPassthroughTransport(cfg *Config) *http.Transport {
+	protocols := new(http.Protocols)
+	if cfg.TLS {
+		protocols.SetHTTP2(true)
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+
+	transport := &http.Transport{
+		Protocols: protocols,
+		HTTP2: &http.HTTP2Config{
+			MaxConcurrentStreams:          cfg.MaxConcurrentStreams,
+			MaxReceiveBufferPerConnection: int(cfg.InitialConnWindowSize),
+			MaxReceiveBufferPerStream:     int(cfg.InitialWindowSize),
+			SendPingTimeout:               cfg.KeepAliveTime,
+			PingTimeout:                   cfg.KeepAliveTimeout,
+		},
+	}
+	if cfg.TLS {
+		transport.TLSClientConfig = cfg.TLSConfig
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+	}
+	return transport
+}
+
+// forwardPassthrough proxies a native gRPC call to the selected instance over
+// HTTP/2. Request and response messages are streamed as they arrive, so
+// client, server and bidirectional streaming calls work unchanged; the
+// backend's status is returned in the response trailers.
+func (c *Connector) forwardPassthrough(ctx context.Context, req core.Request, route *core.RouteResult) (core.Response, error) {
+	scheme := "http"
+	if c.config.TLS {
+		scheme = "https"
+	}
+	target := fmt.Sprintf("%s://%s:%d%s", scheme, route.Instance.Address, route.Instance.Port, req.Path())
+
+	var body io.Reader = http.NoBody
+	if reqBody := req.Body(); reqBody != nil {
+		body = &countingReader{ReadCloser: reqBody, record: c.recordBytes(ctx, route, "received")}
+	}
+
+	// Deadlines travel in the grpc-timeout header, so the call is bound to
+	// the client request rather than the route timeout
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, target, body)
+	if err != nil {
+		return nil, errors.NewError(errors.ErrorTypeBadRequest, "failed to create gRPC backend request").WithCause(err)
+	}
+	for key, values := range req.Headers() {
+		if isHopByHopHeader(key) {
+			continue
+		}
+		for _, value := range values {
+			httpReq.Header.Add(key, value)
+		}
+	}
+	httpReq.Header.Set("Te", "trailers")
+	httpReq.Header.Set("X-Forwarded-For", req.RemoteAddr())
+
+	resp, err := c.passthrough.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, errors.NewError(errors.ErrorTypeTimeout, "gRPC backend call cancelled").WithCause(err)
+		}
+		return nil, errors.NewError(errors.ErrorTypeUnavailable, "failed to call gRPC backend").
+			WithCause(err).
+			WithDetail("target", target)
+	}
+
+	headers := make(map[string][]string, len(resp.Header))
+	for key, values := range resp.Header {
+		if !isHopByHopHeader(key) {
+			headers[key] = values
+		}
+	}
+
+	return &passthroughResponse{
+		statusCode: resp.StatusCode,
+		headers:    headers,
+		body:       &countingReader{ReadCloser: resp.Body, record: c.recordBytes(ctx, route, "sent")},
+		trailer:    resp,
+	}, nil
+}
+
+// recordBytes returns a function recording bytes proxied in direction
+func (c *Connector) recordBytes(ctx context.Context, route *core.RouteResult, direction string) func(int) {
+	if c.metrics == nil {
+		return nil
+	}
+	service := route.ServiceName
+	if service == "" && route.Rule != nil {
+		service = route.Rule.ServiceName
+	}
+	return func(n int) {
+		c.metrics.RecordGRPCBytes(ctx, service, direction, int64(n))
+	}
+}
+
+// hopByHopHeaders lists headers not forwarded to or from the backend
+var hopByHopHeaders = map[string]struct{}{
+	"connection":          {},
+	"keep-alive":          {},
+	"proxy-authenticate":  {},
+	"proxy-authorization": {},
+	"te":                  {},
+	"trailer":             {},
+	"transfer-encoding":   {},
+	"upgrade":             {},
+}
+
+func isHopByHopHeader(header string) bool {
+	_, ok := hopByHopHeaders[strings.ToLower(header)]
+	return ok
+}
+
+// countingReader reports the bytes read through it
+type countingReader struct {
+	io.ReadCloser
+	record func(int)
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 && r.record != nil {
+		r.record(n)
+	}
+	return n, err
+}
+
+// passthroughResponse implements core.TrailerResponse for passthrough calls
+type passthroughResponse struct {
+	statusCode int
+	headers    map[string][]string
+	body       io.ReadCloser
+	trailer    *http.Response
+}
+
+func (r *passthroughResponse) StatusCode() int {
+	return r.statusCode
+}
+
+func (r *passthroughResponse) Headers() map[string][]string {
+	return r.headers
+}
+
+func (r *passthroughResponse) Body() io.ReadCloser {
+	return r.body
+}
+
+// Trailers returns the backend's trailers, such as grpc-status, once the
+// body has been read
+func (r *passthroughResponse) Trailers() map[string][]string {
+	return r.trailer.Trailer
+}
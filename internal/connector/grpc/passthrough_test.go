@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	httpAdapter "gateway/internal/adapter/http"
+	"gateway/internal/config"
+	"gateway/internal/core"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type mockBytesRecorder struct {
+	mu    sync.Mutex
+	bytes map[string]int64
+}
+
+func (m *mockBytesRecorder) RecordGRPCBytes(ctx context.Context, service, direction string, size int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bytes[service+"/"+direction] += size
+}
+
+func (m *mockBytesRecorder) get(key string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.bytes[key]
+}
+
+func TestIsPassthrough(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     *core.RouteRule
+		expected bool
+	}{
+		{"no rule", nil, false},
+		{"http route", &core.RouteRule{Protocol: "http"}, false},
+		{"grpc route", &core.RouteRule{Protocol: "grpc"}, true},
+		{
+			name:     "transcoding disabled",
+			rule:     &core.RouteRule{Protocol: "grpc", Metadata: map[string]interface{}{"grpc": &config.GRPCConfig{Service: "svc"}}},
+			expected: true,
+		},
+		{
+			name:     "transcoding enabled",
+			rule:     &core.RouteRule{Protocol: "grpc", Metadata: map[string]interface{}{"grpc": &config.GRPCConfig{EnableTranscoding: true}}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPassthrough(tt.rule); got != tt.expected {
+				t.Errorf("IsPassthrough() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// startPassthroughGateway starts a health service backend and an HTTP/2
+// frontend proxying to it, returning a client connection to the frontend
+func startPassthroughGateway(t *testing.T, recorder BytesRecorder) (*grpc.ClientConn, *health.Server) {
+	t.Helper()
+
+	frontendAddr, healthServer := startHealthGateway(t, &core.RouteRule{ID: "health", Path: "/grpc.health.v1.Health/*", Protocol: "grpc"}, recorder)
+	conn, err := grpc.NewClient(frontendAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, healthServer
+}
+
+// startHealthGateway starts a health service backend and an HTTP/2 frontend
+// proxying to it through rule, returning the frontend address
+func startHealthGateway(t *testing.T, rule *core.RouteRule, recorder BytesRecorder) (string, *health.Server) {
+	t.Helper()
+
+	backendListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	backend := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(backend, healthServer)
+	go backend.Serve(backendListener)
+	t.Cleanup(backend.Stop)
+
+	connector := New(&Config{}, slog.Default()).WithMetrics(recorder)
+	t.Cleanup(func() { connector.Close() })
+
+	backendAddr := backendListener.Addr().(*net.TCPAddr)
+	route := &core.RouteResult{
+		Instance:    &core.ServiceInstance{ID: "health-1", Address: "127.0.0.1", Port: backendAddr.Port, Healthy: true},
+		Rule:        rule,
+		ServiceName: "health",
+	}
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		return connector.Forward(ctx, req, route)
+	}
+
+	var frontendAddr net.Addr
+	adapter := httpAdapter.New(httpAdapter.Config{HTTP2: true, MaxRequestSize: 1}, handler).
+		WithListenFunc(func(network, address string) (net.Listener, error) {
+			listener, err := net.Listen(network, "127.0.0.1:0")
+			if err == nil {
+				frontendAddr = listener.Addr()
+			}
+			return listener, err
+		})
+	if err := adapter.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start adapter: %v", err)
+	}
+	t.Cleanup(func() { adapter.Stop(context.Background()) })
+
+	return frontendAddr.String(), healthServer
+}
+
+func TestConnector_Passthrough(t *testing.T) {
+	recorder := &mockBytesRecorder{bytes: make(map[string]int64)}
+	conn, _ := startPassthroughGateway(t, recorder)
+	client := healthpb.NewHealthClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING, got %v", resp.Status)
+	}
+
+	// The backend's status is passed through in the trailers
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+
+	if recorder.get("health/received") == 0 || recorder.get("health/sent") == 0 {
+		t.Errorf("Expected bytes to be recorded, got %v", recorder.bytes)
+	}
+}
+
+func TestConnector_PassthroughStream(t *testing.T) {
+	conn, healthServer := startPassthroughGateway(t, nil)
+	client := healthpb.NewHealthClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_SERVING)
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "orders"})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING, got %v", resp.Status)
+	}
+
+	// Messages sent while the call is open reach the client as they are sent
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	resp, err = stream.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive update: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Expected NOT_SERVING, got %v", resp.Status)
+	}
+}
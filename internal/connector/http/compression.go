@@ -0,0 +1,107 @@
+package http
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gateway/internal/core"
+)
+
+// applyAcceptEncoding sets the Accept-Encoding of a backend request as the
+// route's backend compression settings require
+func applyAcceptEncoding(header http.Header, compression *core.BackendCompression) {
+	if compression == nil {
+		return
+	}
+	switch {
+	case compression.Strip:
+		header.Del("Accept-Encoding")
+	case compression.AcceptEncoding != "":
+		header.Set("Accept-Encoding", compression.AcceptEncoding)
+	}
+}
+
+// decodeForClient decodes a gzip or deflate response body the client did
+// not accept, when the route asked the backend for that encoding. Other
+// responses are returned as they are.
+func decodeForClient(resp *http.Response, clientAccepts []string, compression *core.BackendCompression) {
+	if compression == nil || compression.AcceptEncoding == "" {
+		return
+	}
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding != "gzip" && encoding != "deflate" {
+		return
+	}
+	if acceptsEncoding(clientAccepts, encoding) {
+		return
+	}
+
+	resp.Body = &decodingBody{body: resp.Body, encoding: encoding}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+}
+
+// acceptsEncoding reports whether Accept-Encoding header values accept
+// encoding, by name or through *, with a non-zero quality
+func acceptsEncoding(values []string, encoding string) bool {
+	accepted := false
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(item, ";")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != encoding && name != "*" {
+				continue
+			}
+			q := 1.0
+			if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(key) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+			// An explicit entry for the encoding overrides *
+			if name == encoding {
+				return q > 0
+			}
+			accepted = q > 0
+		}
+	}
+	return accepted
+}
+
+// decodingBody decodes a compressed response body. The decoder is created
+// on the first read, so waiting for the compressed stream's header counts
+// against the body's idle timeout rather than the response headers.
+type decodingBody struct {
+	body     io.ReadCloser
+	encoding string
+	decoder  io.Reader
+	err      error
+}
+
+func (b *decodingBody) Read(p []byte) (int, error) {
+	if b.decoder == nil && b.err == nil {
+		if b.encoding == "gzip" {
+			b.decoder, b.err = gzip.NewReader(b.body)
+		} else {
+			b.decoder, b.err = zlib.NewReader(b.body)
+		}
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	n, err := b.decoder.Read(p)
+	if err == io.EOF {
+		// Read the body to its end, so trailers sent after it arrive
+		_, _ = io.Copy(io.Discard, b.body)
+	}
+	return n, err
+}
+
+func (b *decodingBody) Close() error {
+	return b.body.Close()
+}
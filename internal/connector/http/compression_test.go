@@ -0,0 +1,137 @@
+package http
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+)
+
+func TestHTTPConnectorBackendCompression(t *testing.T) {
+	// The backend reports the Accept-Encoding it received and gzips its
+	// response when asked to
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			io.WriteString(w, "hello")
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		io.WriteString(gz, "hello")
+		gz.Close()
+	}))
+	defer backend.Close()
+	backendURL, _ := url.Parse(backend.URL)
+
+	tests := []struct {
+		name          string
+		clientAccepts string
+		compression   *core.BackendCompression
+		wantSent      string
+		wantEncoding  string
+	}{
+		{
+			name:          "client header forwarded",
+			clientAccepts: "br",
+			wantSent:      "br",
+		},
+		{
+			name:          "stripped",
+			clientAccepts: "gzip, br",
+			compression:   &core.BackendCompression{Strip: true},
+			wantSent:      "",
+		},
+		{
+			name:          "identity forced",
+			clientAccepts: "gzip",
+			compression:   &core.BackendCompression{AcceptEncoding: "identity"},
+			wantSent:      "identity",
+		},
+		{
+			name:        "gzip decoded for the client",
+			compression: &core.BackendCompression{AcceptEncoding: "gzip"},
+			wantSent:    "gzip",
+		},
+		{
+			name:          "gzip relayed to a client accepting it",
+			clientAccepts: "br, gzip;q=0.5",
+			compression:   &core.BackendCompression{AcceptEncoding: "gzip"},
+			wantSent:      "gzip",
+			wantEncoding:  "gzip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without transport compression, the Accept-Encoding sent is
+			// exactly the one the connector set
+			client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+			connector := NewHTTPConnector(client, 10*time.Second)
+
+			headers := map[string][]string{}
+			if tt.clientAccepts != "" {
+				headers["Accept-Encoding"] = []string{tt.clientAccepts}
+			}
+			req := &mockRequest{
+				method:  "GET",
+				path:    "/",
+				url:     "/",
+				headers: headers,
+				body:    io.NopCloser(strings.NewReader("")),
+			}
+			route := &core.RouteResult{
+				Instance: &core.ServiceInstance{ID: "backend", Address: backendURL.Hostname(), Port: parsePort(backendURL.Port())},
+				Rule:     &core.RouteRule{BackendCompression: tt.compression},
+			}
+
+			resp, err := connector.Forward(context.Background(), req, route)
+			if err != nil {
+				t.Fatalf("Forward() failed: %v", err)
+			}
+			defer resp.Body().Close()
+
+			got := http.Header(resp.Headers())
+			if sent := got.Get("X-Accept-Encoding"); sent != tt.wantSent {
+				t.Errorf("Backend received Accept-Encoding %q, want %q", sent, tt.wantSent)
+			}
+			if encoding := got.Get("Content-Encoding"); encoding != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", encoding, tt.wantEncoding)
+			}
+			body, err := io.ReadAll(resp.Body())
+			if err != nil {
+				t.Fatalf("Failed to read body: %v", err)
+			}
+			if tt.wantEncoding == "" && string(body) != "hello" {
+				t.Errorf("Body = %q, want it decoded", body)
+			}
+		})
+	}
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   bool
+	}{
+		{nil, false},
+		{[]string{"gzip"}, true},
+		{[]string{"br", "GZIP;q=0.8"}, true},
+		{[]string{"gzip;q=0"}, false},
+		{[]string{"*"}, true},
+		{[]string{"*, gzip;q=0"}, false},
+		{[]string{"deflate, br"}, false},
+	}
+	for _, tt := range tests {
+		if got := acceptsEncoding(tt.values, "gzip"); got != tt.want {
+			t.Errorf("acceptsEncoding(%q, gzip) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
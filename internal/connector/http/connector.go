@@ -6,16 +6,31 @@ import (
 	"gateway/internal/core"
 	"gateway/pkg/errors"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/propagation"
 )
 
 // HTTPConnector implements Connector for HTTP backend services
 type HTTPConnector struct {
 	client         *http.Client
 	defaultTimeout time.Duration
+	timeouts       core.BackendTimeouts
+	propagator     propagation.TextMapPropagator
+	identity       *IdentityHeaders
+	informational  InformationalMetricsRecorder
+	metrics        BackendMetricsRecorder
+	headerLimits   core.HeaderLimits
+	// Longest wait for a connection of a saturated pool, if bounded
+	poolWaitTimeout time.Duration
+	// Clients of services with their own connection settings
+	serviceClients map[string]*http.Client
 }
 
 // NewHTTPConnector creates a new HTTP connector with provided client
@@ -26,8 +41,41 @@ func NewHTTPConnector(client *http.Client, defaultTimeout time.Duration) *HTTPCo
 	}
 }
 
-// Forward implements the Connector interface for HTTP backends
+// WithPropagator injects the request context, such as the active span and
+// baggage, into backend request headers
+func (c *HTTPConnector) WithPropagator(propagator propagation.TextMapPropagator) *HTTPConnector {
+	c.propagator = propagator
+	return c
+}
+
+// WithTimeouts sets the connect, response header and idle timeouts of
+// routes that do not override them. The connect timeout only applies to
+// clients dialing with DialContext.
+func (c *HTTPConnector) WithTimeouts(timeouts core.BackendTimeouts) *HTTPConnector {
+	c.timeouts = timeouts
+	return c
+}
+
+// WithServiceClients sends requests for the listed services, by service
+// name, through their own client rather than the default one
+func (c *HTTPConnector) WithServiceClients(clients map[string]*http.Client) *HTTPConnector {
+	c.serviceClients = clients
+	return c
+}
+
+// Forward implements the Connector interface for HTTP backends. The route
+// timeout bounds the request until the response headers arrive; the body
+// is then streamed for as long as it keeps receiving data within the idle
+// timeout.
 func (c *HTTPConnector) Forward(ctx context.Context, req core.Request, route *core.RouteResult) (core.Response, error) {
+	if c.metrics != nil {
+		return c.measure(ctx, req, route)
+	}
+	return c.forward(ctx, req, route)
+}
+
+// forward sends a request to the backend instance of route
+func (c *HTTPConnector) forward(ctx context.Context, req core.Request, route *core.RouteResult) (core.Response, error) {
 	instance := route.Instance
 
 	// Apply route-specific timeout if configured
@@ -35,21 +83,43 @@ func (c *HTTPConnector) Forward(ctx context.Context, req core.Request, route *co
 	if route.Rule != nil && route.Rule.Timeout > 0 {
 		timeout = route.Rule.Timeout
 	}
+	timeouts := effectiveTimeouts(c.timeouts, route.Rule)
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+	// The request lives until its response body is closed
+	ctx, cancel := context.WithCancelCause(ctx)
+	requestTimer := time.AfterFunc(timeout, func() { cancel(errRequestTimeout) })
+	headerTimer := &phaseTimer{cancel: cancel}
+	defer headerTimer.stop()
+	if timeouts.Connect > 0 {
+		ctx = context.WithValue(ctx, connectTimeoutKey{}, timeouts.Connect)
+	}
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			headerTimer.start(timeouts.ResponseHeader, ErrResponseHeaderTimeout)
+		},
+		Got1xxResponse: c.gotInformational(ctx, net.JoinHostPort(instance.Address, strconv.Itoa(instance.Port))),
+	})
+	if c.poolWaitTimeout > 0 {
+		poolTimer := &phaseTimer{cancel: cancel}
+		defer poolTimer.stop()
+		ctx = httptrace.WithClientTrace(ctx, poolWaitTrace(poolTimer, c.poolWaitTimeout))
+	}
+	fail := func(err error) (core.Response, error) {
+		requestTimer.Stop()
+		cancel(nil)
+		return nil, err
+	}
 
 	// Build backend URL
 	backendURL, err := c.buildBackendURL(req, instance)
 	if err != nil {
-		return nil, errors.NewError(errors.ErrorTypeBadRequest, "failed to build backend URL").WithCause(err)
+		return fail(errors.NewError(errors.ErrorTypeBadRequest, "failed to build backend URL").WithCause(err))
 	}
 
 	// Create HTTP request with context
 	httpReq, err := http.NewRequestWithContext(ctx, req.Method(), backendURL, req.Body())
 	if err != nil {
-		return nil, errors.NewError(errors.ErrorTypeBadRequest, "failed to create backend request").WithCause(err)
+		return fail(errors.NewError(errors.ErrorTypeBadRequest, "failed to create backend request").WithCause(err))
 	}
 
 	// Copy headers from original request
@@ -63,6 +133,24 @@ func (c *HTTPConnector) Forward(ctx context.Context, req core.Request, route *co
 		}
 	}
 
+	var compression *core.BackendCompression
+	if route.Rule != nil {
+		compression = route.Rule.BackendCompression
+	}
+	applyAcceptEncoding(httpReq.Header, compression)
+
+	if c.identity != nil {
+		if err := c.identity.apply(ctx, httpReq.Header); err != nil {
+			return fail(err)
+		}
+	}
+
+	// Inject the gateway span so the backend joins the trace, starting one
+	// even when the incoming request carried no trace headers
+	if c.propagator != nil {
+		c.propagator.Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
+	}
+
 	// Set X-Forwarded headers
 	httpReq.Header.Set("X-Forwarded-For", req.RemoteAddr())
 
@@ -86,21 +174,58 @@ func (c *HTTPConnector) Forward(ctx context.Context, req core.Request, route *co
 	}
 
 	// Send request to backend
-	resp, err := c.client.Do(httpReq)
+	client := c.client
+	if serviceClient, ok := c.serviceClients[route.ServiceName]; ok {
+		client = serviceClient
+	}
+	resp, err := client.Do(httpReq)
 	if err != nil {
+		// A connect timeout is a connect failure rather than a timeout, so
+		// it can be retried on another instance
+		if isDialTimeout(err) {
+			return fail(errors.NewError(errors.ErrorTypeUnavailable, "backend connect timed out").
+				WithCause(fmt.Errorf("%w: %v", ErrConnectTimeout, err)))
+		}
+		// A saturated pool is reported as unavailable, so the request can
+		// be retried on another instance
+		if context.Cause(ctx) == ErrPoolSaturated {
+			return fail(errors.NewError(errors.ErrorTypeUnavailable, "backend connection pool saturated").
+				WithCause(ErrPoolSaturated))
+		}
 		// Check for timeout or context cancellation
+		if cause := context.Cause(ctx); cause == errRequestTimeout || cause == ErrResponseHeaderTimeout {
+			return fail(errors.NewError(errors.ErrorTypeTimeout, "backend request timed out").WithCause(cause))
+		}
 		if ctx.Err() != nil {
-			return nil, errors.NewError(errors.ErrorTypeTimeout, "backend request timed out").WithCause(err)
+			return fail(errors.NewError(errors.ErrorTypeTimeout, "backend request timed out").WithCause(err))
 		}
-		return nil, errors.NewError(errors.ErrorTypeUnavailable, "failed to send request to backend").WithCause(err)
+		if isResponseHeaderLimitError(err) {
+			return fail(errors.NewError(errors.ErrorTypeBadGateway, "backend response headers too large").
+				WithCause(fmt.Errorf("%w: %v", ErrResponseHeadersTooLarge, err)))
+		}
+		return fail(errors.NewError(errors.ErrorTypeUnavailable, "failed to send request to backend").WithCause(err))
+	}
+	if c.headerLimits.Exceeded(resp.Header) {
+		resp.Body.Close()
+		return fail(errors.NewError(errors.ErrorTypeBadGateway, "backend response headers too large").
+			WithCause(ErrResponseHeadersTooLarge))
 	}
+	requestTimer.Stop()
+	decodeForClient(resp, req.Headers()["Accept-Encoding"], compression)
 
 	// Create and return streaming response
-	return &httpResponse{
+	response := &httpResponse{
 		statusCode: resp.StatusCode,
 		headers:    resp.Header,
-		body:       resp.Body,
-	}, nil
+		body:       newIdleTimeoutBody(resp.Body, ctx, cancel, timeouts.Idle),
+	}
+	if len(resp.Trailer) > 0 {
+		// Trailers follow a chunked body, whatever length the backend
+		// declared over HTTP/2
+		resp.Header.Del("Content-Length")
+		return &trailerResponse{httpResponse: response, resp: resp}, nil
+	}
+	return response, nil
 }
 
 func (c *HTTPConnector) buildBackendURL(req core.Request, instance *core.ServiceInstance) (string, error) {
@@ -155,3 +280,15 @@ func (r *httpResponse) Headers() map[string][]string {
 func (r *httpResponse) Body() io.ReadCloser {
 	return r.body
 }
+
+// trailerResponse implements core.TrailerResponse for responses declaring
+// trailers, so the frontend streams the body and then sends the trailers
+type trailerResponse struct {
+	*httpResponse
+	resp *http.Response
+}
+
+// Trailers returns the backend's trailers once the body has been read
+func (r *trailerResponse) Trailers() map[string][]string {
+	return r.resp.Trailer
+}
@@ -1,16 +1,25 @@
 package http
 
 import (
+	"compress/gzip"
 	"context"
+	stderrors "errors"
+	"fmt"
 	"gateway/internal/core"
 	"gateway/pkg/errors"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // mockRequest implements core.Request
@@ -39,7 +48,6 @@ func (m *mockRequest) Context() context.Context {
 	return context.Background()
 }
 
-
 func TestHTTPConnectorForward(t *testing.T) {
 	// Create a test backend server
 	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -290,6 +298,61 @@ func TestHTTPConnectorHeaderFiltering(t *testing.T) {
 	}
 }
 
+func TestHTTPConnectorPropagation(t *testing.T) {
+	var received http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	connector := NewHTTPConnector(&http.Client{}, 10*time.Second).WithPropagator(
+		propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	)
+
+	// Gateway span started without any incoming trace headers
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanCtx)
+	member, _ := baggage.NewMember("tenant", "acme")
+	bag, _ := baggage.New(member)
+	ctx = baggage.ContextWithBaggage(ctx, bag)
+
+	req := &mockRequest{
+		id:         "propagation-test",
+		method:     "GET",
+		path:       "/trace",
+		url:        "/trace",
+		remoteAddr: "192.168.1.6:12350",
+		headers:    map[string][]string{},
+		body:       io.NopCloser(strings.NewReader("")),
+	}
+	route := &core.RouteResult{
+		Instance: &core.ServiceInstance{
+			ID:      "trace-backend",
+			Address: backendURL.Hostname(),
+			Port:    parsePort(backendURL.Port()),
+			Scheme:  backendURL.Scheme,
+		},
+		Rule: &core.RouteRule{},
+	}
+
+	if _, err := connector.Forward(ctx, req, route); err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+
+	if traceparent := received.Get("Traceparent"); !strings.Contains(traceparent, spanCtx.TraceID().String()) {
+		t.Errorf("Expected traceparent with trace id %s, got %q", spanCtx.TraceID(), traceparent)
+	}
+	if got := received.Get("Baggage"); got != "tenant=acme" {
+		t.Errorf("Expected baggage tenant=acme, got %q", got)
+	}
+}
+
 func TestHTTPConnectorStreaming(t *testing.T) {
 	// Create backend that returns a simple response
 	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -348,6 +411,67 @@ func TestHTTPConnectorStreaming(t *testing.T) {
 	}
 }
 
+func TestHTTPConnectorTrailers(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum")
+		if r.Header.Get("Accept-Encoding") == "gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			io.WriteString(gz, "hello")
+			gz.Close()
+		} else {
+			io.WriteString(w, "hello")
+		}
+		w.Header().Set("X-Checksum", "abc123")
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name string
+		rule *core.RouteRule
+	}{
+		{"plain", &core.RouteRule{}},
+		// The gateway decodes the body for the client
+		{"decoded", &core.RouteRule{BackendCompression: &core.BackendCompression{AcceptEncoding: "gzip"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+			resp, err := forwardTo(NewHTTPConnector(client, 10*time.Second), backend, tt.rule)
+			if err != nil {
+				t.Fatalf("Forward() failed: %v", err)
+			}
+			defer resp.Body().Close()
+
+			trailerResp, ok := resp.(core.TrailerResponse)
+			if !ok {
+				t.Fatal("Expected a response with trailers")
+			}
+			body, err := io.ReadAll(resp.Body())
+			if err != nil || string(body) != "hello" {
+				t.Fatalf("Body = %q, %v", body, err)
+			}
+			if got := http.Header(trailerResp.Trailers()).Get("X-Checksum"); got != "abc123" {
+				t.Errorf("Trailer X-Checksum = %q, want abc123", got)
+			}
+		})
+	}
+
+	// Responses without trailers are not streamed as such
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	}))
+	defer plain.Close()
+	resp, err := forwardTo(NewHTTPConnector(&http.Client{}, 10*time.Second), plain, &core.RouteRule{})
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+	defer resp.Body().Close()
+	if _, ok := resp.(core.TrailerResponse); ok {
+		t.Error("Expected a response without trailers")
+	}
+}
+
 // Helper to parse port from string
 func parsePort(portStr string) int {
 	if portStr == "" {
@@ -364,3 +488,228 @@ func parsePort(portStr string) int {
 	}
 	return port
 }
+
+// forwardTo sends a GET request through connector to backend with rule
+func forwardTo(connector *HTTPConnector, backend *httptest.Server, rule *core.RouteRule) (core.Response, error) {
+	backendURL, _ := url.Parse(backend.URL)
+	req := &mockRequest{
+		id:      "timeouts-test",
+		method:  "GET",
+		path:    "/",
+		url:     "/",
+		headers: make(map[string][]string),
+		body:    http.NoBody,
+	}
+	route := &core.RouteResult{
+		Instance: &core.ServiceInstance{ID: "backend-1", Address: backendURL.Hostname(), Port: parsePort(backendURL.Port())},
+		Rule:     rule,
+	}
+	return connector.Forward(context.Background(), req, route)
+}
+
+func TestHTTPConnectorConnectTimeout(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer backend.Close()
+
+	// Connecting blocks until the dial is cancelled
+	dialer := &net.Dialer{
+		Timeout: time.Minute,
+		ControlContext: func(ctx context.Context, network, address string, c syscall.RawConn) error {
+			<-ctx.Done()
+			return nil
+		},
+	}
+	client := &http.Client{Transport: &http.Transport{DialContext: DialContext(dialer)}}
+	connector := NewHTTPConnector(client, 10*time.Second)
+
+	start := time.Now()
+	_, err := forwardTo(connector, backend, &core.RouteRule{BackendTimeouts: &core.BackendTimeouts{Connect: 50 * time.Millisecond}})
+	var gwErr *errors.Error
+	if !errors.As(err, &gwErr) || gwErr.Type != errors.ErrorTypeUnavailable || !stderrors.Is(err, ErrConnectTimeout) {
+		t.Fatalf("Expected connect timeout reported as unavailable, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected route connect timeout to apply, took %v", elapsed)
+	}
+}
+
+func TestHTTPConnectorResponseHeaderTimeout(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	connector := NewHTTPConnector(&http.Client{}, 10*time.Second).
+		WithTimeouts(core.BackendTimeouts{ResponseHeader: 50 * time.Millisecond})
+
+	_, err := forwardTo(connector, backend, &core.RouteRule{})
+	var gwErr *errors.Error
+	if !errors.As(err, &gwErr) || gwErr.Type != errors.ErrorTypeTimeout || !stderrors.Is(err, ErrResponseHeaderTimeout) {
+		t.Errorf("Expected response header timeout, got %v", err)
+	}
+
+	// The route overrides the connector's default
+	resp, err := forwardTo(connector, backend, &core.RouteRule{BackendTimeouts: &core.BackendTimeouts{ResponseHeader: time.Second}})
+	if err != nil {
+		t.Fatalf("Expected route response header timeout to apply, got %v", err)
+	}
+	resp.Body().Close()
+}
+
+// streamingBackend writes five chunks, pausing after each
+func streamingBackend(pause time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 5; i++ {
+			w.Write([]byte("chunk\n"))
+			w.(http.Flusher).Flush()
+			select {
+			case <-time.After(pause):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}))
+}
+
+func TestHTTPConnectorIdleTimeout(t *testing.T) {
+	connector := NewHTTPConnector(&http.Client{}, 100*time.Millisecond).
+		WithTimeouts(core.BackendTimeouts{Idle: 200 * time.Millisecond})
+
+	// A body streaming past the route timeout is not cut while data keeps
+	// arriving within the idle timeout
+	backend := streamingBackend(50 * time.Millisecond)
+	defer backend.Close()
+	resp, err := forwardTo(connector, backend, &core.RouteRule{})
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body())
+	resp.Body().Close()
+	if err != nil || strings.Count(string(body), "chunk") != 5 {
+		t.Errorf("Expected full streamed body, got %q (%v)", body, err)
+	}
+
+	stalled := streamingBackend(time.Second)
+	defer stalled.Close()
+	resp, err = forwardTo(connector, stalled, &core.RouteRule{})
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+	defer resp.Body().Close()
+	if _, err := io.ReadAll(resp.Body()); !stderrors.Is(err, ErrIdleTimeout) {
+		t.Errorf("Expected idle timeout, got %v", err)
+	}
+}
+
+func TestHTTPConnectorResponseHeaderLimits(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 20; i++ {
+			w.Header().Add("X-Tag", strings.Repeat("x", 100))
+		}
+		io.WriteString(w, "hello")
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name      string
+		transport *http.Transport
+		limits    core.HeaderLimits
+		wantErr   bool
+	}{
+		{"within limits", &http.Transport{}, core.HeaderLimits{MaxBytes: 4096, MaxCount: 30}, false},
+		{"too many fields", &http.Transport{}, core.HeaderLimits{MaxCount: 10}, true},
+		{"too many bytes", &http.Transport{}, core.HeaderLimits{MaxBytes: 1024}, true},
+		// The transport stops reading the headers
+		{"transport limit", &http.Transport{MaxResponseHeaderBytes: 1024}, core.HeaderLimits{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := NewHTTPConnector(&http.Client{Transport: tt.transport}, 10*time.Second).
+				WithResponseHeaderLimits(tt.limits)
+			resp, err := forwardTo(connector, backend, &core.RouteRule{})
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Forward() failed: %v", err)
+				}
+				resp.Body().Close()
+				return
+			}
+
+			if err == nil {
+				resp.Body().Close()
+				t.Fatal("Expected the response to be rejected")
+			}
+			if !stderrors.Is(err, ErrResponseHeadersTooLarge) {
+				t.Errorf("Error = %v, want ErrResponseHeadersTooLarge", err)
+			}
+			if status := errors.HTTPStatus(err); status != http.StatusBadGateway {
+				t.Errorf("Status = %d, want %d", status, http.StatusBadGateway)
+			}
+		})
+	}
+}
+
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	http.RoundTripper
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return t.RoundTripper.RoundTrip(req)
+}
+
+func TestHTTPConnectorServiceClients(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	}))
+	defer backend.Close()
+	backendURL, _ := url.Parse(backend.URL)
+
+	defaultTransport := &countingTransport{RoundTripper: &http.Transport{}}
+	legacyTransport := &countingTransport{RoundTripper: &http.Transport{}}
+	connector := NewHTTPConnector(&http.Client{Transport: defaultTransport}, 10*time.Second).
+		WithServiceClients(map[string]*http.Client{"legacy": {Transport: legacyTransport}})
+
+	for _, service := range []string{"legacy", "users", "legacy"} {
+		req := &mockRequest{method: "GET", path: "/", url: "/", headers: make(map[string][]string), body: http.NoBody}
+		route := &core.RouteResult{
+			Instance:    &core.ServiceInstance{ID: "backend-1", Address: backendURL.Hostname(), Port: parsePort(backendURL.Port())},
+			ServiceName: service,
+		}
+		resp, err := connector.Forward(context.Background(), req, route)
+		if err != nil {
+			t.Fatalf("Forward() to %s failed: %v", service, err)
+		}
+		resp.Body().Close()
+	}
+
+	if legacyTransport.requests != 2 || defaultTransport.requests != 1 {
+		t.Errorf("Expected 2 requests through the legacy client and 1 through the default, got %d and %d",
+			legacyTransport.requests, defaultTransport.requests)
+	}
+}
+
+func TestIsResponseHeaderLimitError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		// The messages of net/http's HTTP/1 transport and of the HTTP/2 transport
+		{"http1", fmt.Errorf("net/http: server response headers exceeded %d bytes; aborted", 1024), true},
+		{"http2", stderrors.New("http2: response header list larger than advertised limit"), true},
+		{"wrapped", &url.Error{Op: "Get", URL: "http://backend", Err: stderrors.New("http2: response header list larger than advertised limit")}, true},
+		{"other", stderrors.New("connection reset by peer"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isResponseHeaderLimitError(tt.err); got != tt.want {
+				t.Errorf("isResponseHeaderLimitError(%q) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	
 	"gateway/internal/config"
 	"gateway/internal/connector"
+	"gateway/internal/core"
 	"gateway/pkg/errors"
 	"gateway/pkg/factory"
 	tlsutil "gateway/pkg/tls"
@@ -58,7 +59,14 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 		defaultTimeout = 30 * time.Second
 	}
 	
-	c.connector = NewHTTPConnector(client, defaultTimeout)
+	c.connector = NewHTTPConnector(client, defaultTimeout).WithTimeouts(core.BackendTimeouts{
+		Connect:        time.Duration(c.config.DialTimeout) * time.Second,
+		ResponseHeader: time.Duration(c.config.ResponseHeaderTimeout) * time.Second,
+		Idle:           time.Duration(c.config.ResponseIdleTimeout) * time.Second,
+	}).WithResponseHeaderLimits(core.HeaderLimits{
+		MaxBytes: c.config.MaxResponseHeaderBytes,
+		MaxCount: c.config.MaxResponseHeaderCount,
+	})
 	
 	return nil
 }
@@ -110,14 +118,19 @@ func (c *Component) createHTTPClient() (*http.Client, error) {
 	}
 	
 	// Create transport with connection pooling
+	// The connector applies the connect and response header timeouts, so
+	// routes can override them
 	transport := &http.Transport{
-		DialContext:           dialer.DialContext,
-		MaxIdleConns:          c.config.MaxIdleConns,
-		MaxIdleConnsPerHost:   c.config.MaxIdleConnsPerHost,
-		IdleConnTimeout:       time.Duration(c.config.IdleConnTimeout) * time.Second,
-		ResponseHeaderTimeout: time.Duration(c.config.ResponseHeaderTimeout) * time.Second,
-		ForceAttemptHTTP2:     true,
-		DisableCompression:    false,
+		DialContext:         DialContext(dialer),
+		MaxIdleConns:        c.config.MaxIdleConns,
+		MaxIdleConnsPerHost: c.config.MaxIdleConnsPerHost,
+		IdleConnTimeout:     time.Duration(c.config.IdleConnTimeout) * time.Second,
+		ForceAttemptHTTP2:   true,
+		DisableCompression:  c.config.DisableCompression,
+		// Requests with "Expect: 100-continue" wait for the backend to
+		// accept their body
+		ExpectContinueTimeout:  ExpectContinueTimeout(c.config.ExpectContinueTimeout),
+		MaxResponseHeaderBytes: int64(c.config.MaxResponseHeaderBytes),
 	}
 	
 	// Configure TLS if enabled
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"errors"
+	"strings"
+
+	"gateway/internal/core"
+)
+
+// ErrResponseHeadersTooLarge is the cause of requests whose backend sent
+// response headers over the configured limits
+var ErrResponseHeadersTooLarge = errors.New("backend response headers too large")
+
+// WithResponseHeaderLimits fails requests whose backend response headers
+// are over limits with 502 Bad Gateway. The transport's
+// MaxResponseHeaderBytes stops reading oversized headers; the connector
+// checks the exact limits of the headers it received.
+func (c *HTTPConnector) WithResponseHeaderLimits(limits core.HeaderLimits) *HTTPConnector {
+	c.headerLimits = limits
+	return c
+}
+
+// isResponseHeaderLimitError reports whether the transport aborted a
+// response because its headers were over MaxResponseHeaderBytes
+func isResponseHeaderLimitError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "server response headers exceeded") ||
+		strings.Contains(msg, "response header list larger than advertised limit")
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"gateway/internal/middleware/auth"
+	"gateway/pkg/errors"
+)
+
+// IdentityHeaders names the backend request headers carrying the verified
+// identity of authenticated requests, so backends need not validate tokens
+// again. Empty names are not set.
+type IdentityHeaders struct {
+	Subject string
+	Type    string
+	// Scopes is set to the space-separated scopes
+	Scopes string
+	// Metadata maps auth metadata keys to header names
+	Metadata map[string]string
+	// Templates render headers from the token claims
+	Templates []HeaderTemplate
+	// StripAuthorization removes the Authorization header of authenticated
+	// requests once their identity is passed on
+	StripAuthorization bool
+}
+
+// HeaderTemplate renders a header from the identity of a request. Templates
+// see .claims, .metadata, .subject and .scopes.
+type HeaderTemplate struct {
+	Header   string
+	template *template.Template
+	// Required fails requests for which the template cannot be rendered,
+	// such as when a claim is missing, instead of omitting the header
+	Required bool
+}
+
+// NewHeaderTemplate parses the template of a header, such as
+// {{ .claims.tenant_id }}
+func NewHeaderTemplate(header, text string, required bool) (HeaderTemplate, error) {
+	tmpl, err := template.New(header).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return HeaderTemplate{}, err
+	}
+	return HeaderTemplate{Header: header, template: tmpl, Required: required}, nil
+}
+
+// render returns the header value for info, or an empty string when it
+// cannot be rendered
+func (t *HeaderTemplate) render(info *auth.AuthInfo) string {
+	data := map[string]interface{}{
+		"claims":   info.Claims,
+		"metadata": info.Metadata,
+		"subject":  info.Subject,
+		"scopes":   info.Scopes,
+	}
+	var buf bytes.Buffer
+	if err := t.template.Execute(&buf, data); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// WithIdentityHeaders passes the identity of authenticated requests to
+// backends in headers
+func (c *HTTPConnector) WithIdentityHeaders(identity *IdentityHeaders) *HTTPConnector {
+	c.identity = identity
+	return c
+}
+
+// apply sets the identity headers of a backend request. Client-supplied
+// values of the headers are always removed, so unauthenticated requests
+// cannot pose as an identity. It fails when a required template renders
+// no value.
+func (h *IdentityHeaders) apply(ctx context.Context, header http.Header) error {
+	names := h.names()
+	for _, name := range names {
+		header.Del(name)
+	}
+
+	info, ok := auth.GetAuthInfo(ctx)
+	if !ok || info == nil {
+		return nil
+	}
+
+	set := func(name, value string) {
+		if name != "" && value != "" {
+			header.Set(name, sanitizeHeaderValue(value))
+		}
+	}
+	set(h.Subject, info.Subject)
+	set(h.Type, string(info.Type))
+	set(h.Scopes, strings.Join(info.Scopes, " "))
+	for key, name := range h.Metadata {
+		if value, ok := info.Metadata[key]; ok && value != nil {
+			set(name, fmt.Sprint(value))
+		}
+	}
+	for i := range h.Templates {
+		t := &h.Templates[i]
+		value := t.render(info)
+		if value == "" && t.Required {
+			return errors.NewError(errors.ErrorTypeForbidden, "missing required identity claim").
+				WithDetail("header", t.Header)
+		}
+		set(t.Header, value)
+	}
+
+	if h.StripAuthorization {
+		header.Del("Authorization")
+	}
+	return nil
+}
+
+// names returns the configured header names
+func (h *IdentityHeaders) names() []string {
+	names := []string{h.Subject, h.Type, h.Scopes}
+	for _, name := range h.Metadata {
+		names = append(names, name)
+	}
+	for _, t := range h.Templates {
+		names = append(names, t.Header)
+	}
+	return names
+}
+
+// sanitizeHeaderValue drops control characters, such as CR and LF, which
+// could otherwise inject further headers
+func sanitizeHeaderValue(value string) string {
+	return strings.Map(func(r rune) rune {
+		if (r < ' ' && r != '\t') || r == 0x7f {
+			return -1
+		}
+		return r
+	}, value)
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
+	gwerrors "gateway/pkg/errors"
+)
+
+func TestHTTPConnector_IdentityHeaders(t *testing.T) {
+	var received http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	connector := NewHTTPConnector(&http.Client{}, 10*time.Second).WithIdentityHeaders(&IdentityHeaders{
+		Subject:            "X-Auth-Subject",
+		Scopes:             "X-Auth-Scopes",
+		Metadata:           map[string]string{"email": "X-Auth-Email"},
+		StripAuthorization: true,
+	})
+	route := &core.RouteResult{
+		Instance: &core.ServiceInstance{
+			ID:      "backend",
+			Address: backendURL.Hostname(),
+			Port:    parsePort(backendURL.Port()),
+			Scheme:  backendURL.Scheme,
+		},
+		Rule: &core.RouteRule{},
+	}
+	forward := func(ctx context.Context) {
+		t.Helper()
+		req := &mockRequest{
+			method: "GET",
+			path:   "/",
+			url:    "/",
+			headers: map[string][]string{
+				"Authorization":  {"Bearer token"},
+				"X-Auth-Subject": {"admin"},
+			},
+			body: io.NopCloser(strings.NewReader("")),
+		}
+		if _, err := connector.Forward(ctx, req, route); err != nil {
+			t.Fatalf("Forward() failed: %v", err)
+		}
+	}
+
+	// Spoofed identity headers are removed from unauthenticated requests
+	forward(context.Background())
+	if got := received.Get("X-Auth-Subject"); got != "" {
+		t.Errorf("Expected spoofed subject to be removed, got %q", got)
+	}
+	if got := received.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Expected Authorization of unauthenticated request to be kept, got %q", got)
+	}
+
+	forward(auth.WithAuthInfo(context.Background(), &auth.AuthInfo{
+		Subject:  "alice\r\nX-Admin: true",
+		Scopes:   []string{"read", "write"},
+		Metadata: map[string]interface{}{"email": "alice@example.com"},
+	}))
+	if got := received.Get("X-Auth-Subject"); got != "aliceX-Admin: true" {
+		t.Errorf("Expected sanitized subject, got %q", got)
+	}
+	if got := received.Get("X-Admin"); got != "" {
+		t.Errorf("Expected no injected header, got %q", got)
+	}
+	if got := received.Get("X-Auth-Scopes"); got != "read write" {
+		t.Errorf("Expected scopes, got %q", got)
+	}
+	if got := received.Get("X-Auth-Email"); got != "alice@example.com" {
+		t.Errorf("Expected email, got %q", got)
+	}
+	if got := received.Get("Authorization"); got != "" {
+		t.Errorf("Expected Authorization to be stripped, got %q", got)
+	}
+}
+
+func TestIdentityHeaders_Templates(t *testing.T) {
+	tenant, err := NewHeaderTemplate("X-Tenant", "{{ .claims.tenant_id }}", true)
+	if err != nil {
+		t.Fatalf("NewHeaderTemplate failed: %v", err)
+	}
+	region, err := NewHeaderTemplate("X-Region", "{{ .claims.org.region }}", false)
+	if err != nil {
+		t.Fatalf("NewHeaderTemplate failed: %v", err)
+	}
+	identity := &IdentityHeaders{Templates: []HeaderTemplate{tenant, region}}
+
+	// Nested claims render; optional headers without a claim are omitted
+	header := http.Header{"X-Region": {"spoofed"}}
+	ctx := auth.WithAuthInfo(context.Background(), &auth.AuthInfo{
+		Claims: map[string]interface{}{"tenant_id": "acme", "org": map[string]interface{}{}},
+	})
+	if err := identity.apply(ctx, header); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	if got := header.Get("X-Tenant"); got != "acme" {
+		t.Errorf("Expected tenant acme, got %q", got)
+	}
+	if got := header.Values("X-Region"); len(got) != 0 {
+		t.Errorf("Expected region to be omitted, got %q", got)
+	}
+
+	header = http.Header{}
+	ctx = auth.WithAuthInfo(context.Background(), &auth.AuthInfo{
+		Claims: map[string]interface{}{"org": map[string]interface{}{"region": "eu"}},
+	})
+	err = identity.apply(ctx, header)
+	if err == nil || gwerrors.HTTPStatus(err) != http.StatusForbidden {
+		t.Errorf("Expected 403 for missing required claim, got %v", err)
+	}
+
+	// Unauthenticated requests get no headers and do not fail
+	if err := identity.apply(context.Background(), http.Header{}); err != nil {
+		t.Errorf("Expected unauthenticated request to pass, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/textproto"
+	"time"
+
+	"gateway/internal/core"
+)
+
+// DefaultExpectContinueTimeout is how long a request sent with "Expect:
+// 100-continue" waits for the backend's 100 Continue before its body is
+// sent anyway
+const DefaultExpectContinueTimeout = time.Second
+
+// ExpectContinueTimeout returns the configured wait for 100 Continue, in
+// seconds, or the default when unset
+func ExpectContinueTimeout(seconds int) time.Duration {
+	if seconds <= 0 {
+		return DefaultExpectContinueTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// InformationalMetricsRecorder counts the interim (1xx) responses received
+// from backends
+type InformationalMetricsRecorder interface {
+	RecordBackendInformational(ctx context.Context, host string, status int)
+}
+
+// WithInformationalMetrics sets the recorder of interim responses
+func (c *HTTPConnector) WithInformationalMetrics(recorder InformationalMetricsRecorder) *HTTPConnector {
+	c.informational = recorder
+	return c
+}
+
+// gotInformational returns the trace hook counting the interim responses
+// of a backend and relaying them to the client. The backend's 100 Continue
+// is not relayed: it lets the transport send the request body, and reading
+// that body makes the frontend send its own 100 Continue to the client.
+func (c *HTTPConnector) gotInformational(ctx context.Context, host string) func(int, textproto.MIMEHeader) error {
+	relay := core.InformationalFromContext(ctx)
+	return func(code int, header textproto.MIMEHeader) error {
+		if c.informational != nil {
+			c.informational.RecordBackendInformational(ctx, host, code)
+		}
+		if relay == nil || code == http.StatusContinue {
+			return nil
+		}
+		relayed := make(map[string][]string, len(header))
+		for key, values := range header {
+			if !isHopByHopHeader(key) {
+				relayed[key] = values
+			}
+		}
+		relay(code, relayed)
+		return nil
+	}
+}
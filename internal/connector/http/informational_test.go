@@ -0,0 +1,143 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+)
+
+// informationalRecorder records interim response metrics
+type informationalRecorder struct {
+	mu       sync.Mutex
+	statuses []int
+}
+
+func (r *informationalRecorder) RecordBackendInformational(ctx context.Context, host string, status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, status)
+}
+
+// countingReader counts the bytes read from it
+type countingReader struct {
+	io.Reader
+	read atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read.Add(int64(n))
+	return n, err
+}
+
+func (r *countingReader) Close() error { return nil }
+
+func TestHTTPConnectorExpectContinue(t *testing.T) {
+	const size = 8 << 20
+
+	// The backend sends Early Hints, then accepts uploads to /upload, which
+	// makes the server send 100 Continue as the body is read
+	var received atomic.Int64
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", "</style.css>; rel=preload")
+		w.WriteHeader(http.StatusEarlyHints)
+		w.Header().Del("Link")
+		if r.URL.Path != "/upload" {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		n, _ := io.Copy(io.Discard, r.Body)
+		received.Store(n)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+	backendURL, _ := url.Parse(backend.URL)
+
+	tests := []struct {
+		name         string
+		path         string
+		wantStatus   int
+		wantReceived int64
+		wantMetrics  []int
+	}{
+		{"accepted", "/upload", http.StatusCreated, size, []int{http.StatusEarlyHints, http.StatusContinue}},
+		{"rejected", "/reject", http.StatusRequestEntityTooLarge, 0, []int{http.StatusEarlyHints}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received.Store(0)
+			client := &http.Client{Transport: &http.Transport{ExpectContinueTimeout: 5 * time.Second}}
+			recorder := &informationalRecorder{}
+			connector := NewHTTPConnector(client, 10*time.Second).WithInformationalMetrics(recorder)
+
+			var relayed []int
+			var link string
+			ctx := core.WithInformational(context.Background(), func(code int, header map[string][]string) {
+				relayed = append(relayed, code)
+				link = http.Header(header).Get("Link")
+			})
+
+			body := &countingReader{Reader: strings.NewReader(strings.Repeat("x", size))}
+			req := &mockRequest{
+				method:  "POST",
+				path:    tt.path,
+				url:     tt.path,
+				headers: map[string][]string{"Expect": {"100-continue"}},
+				body:    body,
+			}
+			route := &core.RouteResult{
+				Instance: &core.ServiceInstance{ID: "backend", Address: backendURL.Hostname(), Port: parsePort(backendURL.Port())},
+			}
+
+			resp, err := connector.Forward(ctx, req, route)
+			if err != nil {
+				t.Fatalf("Forward() failed: %v", err)
+			}
+			resp.Body().Close()
+
+			if resp.StatusCode() != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", resp.StatusCode(), tt.wantStatus)
+			}
+			if received.Load() != tt.wantReceived {
+				t.Errorf("Backend received %d bytes, want %d", received.Load(), tt.wantReceived)
+			}
+			if tt.wantReceived == 0 && body.read.Load() != 0 {
+				t.Errorf("Expected the body not to be sent, %d bytes were read", body.read.Load())
+			}
+
+			// Only the Early Hints are relayed, with their headers
+			if len(relayed) != 1 || relayed[0] != http.StatusEarlyHints || link == "" {
+				t.Errorf("Relayed %v with Link %q, want the Early Hints", relayed, link)
+			}
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+			if len(recorder.statuses) != len(tt.wantMetrics) {
+				t.Fatalf("Recorded %v, want %v", recorder.statuses, tt.wantMetrics)
+			}
+			for i, status := range tt.wantMetrics {
+				if recorder.statuses[i] != status {
+					t.Errorf("Recorded %v, want %v", recorder.statuses, tt.wantMetrics)
+				}
+			}
+		})
+	}
+}
+
+func TestExpectContinueTimeout(t *testing.T) {
+	if got := ExpectContinueTimeout(0); got != DefaultExpectContinueTimeout {
+		t.Errorf("ExpectContinueTimeout(0) = %v, want the default", got)
+	}
+	if got := ExpectContinueTimeout(3); got != 3*time.Second {
+		t.Errorf("ExpectContinueTimeout(3) = %v, want 3s", got)
+	}
+}
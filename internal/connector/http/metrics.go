@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gateway/internal/core"
+)
+
+// BackendMetricsRecorder records backend requests, their sizes and failures
+type BackendMetricsRecorder interface {
+	RecordBackendRequest(ctx context.Context, service, instance string, statusCode int, duration time.Duration)
+	RecordBackendError(ctx context.Context, service, instance string, err error)
+	RecordBackendRequestSize(ctx context.Context, service, instance string, size int64)
+	RecordBackendResponseSize(ctx context.Context, service, instance string, size int64)
+	RecordBackendActiveRequest(ctx context.Context, delta int64)
+}
+
+// WithBackendMetrics sets the recorder of backend requests. A request is
+// active and its duration measured until its response body is closed;
+// request sizes are recorded for requests declaring a Content-Length.
+func (c *HTTPConnector) WithBackendMetrics(recorder BackendMetricsRecorder) *HTTPConnector {
+	c.metrics = recorder
+	return c
+}
+
+// measure forwards a request, recording it with the backend metrics
+func (c *HTTPConnector) measure(ctx context.Context, req core.Request, route *core.RouteResult) (core.Response, error) {
+	service, instance := route.ServiceName, route.Instance.ID
+	start := time.Now()
+	c.metrics.RecordBackendActiveRequest(ctx, 1)
+	if size, err := strconv.ParseInt(http.Header(req.Headers()).Get("Content-Length"), 10, 64); err == nil {
+		c.metrics.RecordBackendRequestSize(ctx, service, instance, size)
+	}
+
+	resp, err := c.forward(ctx, req, route)
+	if err != nil {
+		c.metrics.RecordBackendError(ctx, service, instance, err)
+		c.metrics.RecordBackendActiveRequest(ctx, -1)
+		return nil, err
+	}
+
+	var response *httpResponse
+	switch r := resp.(type) {
+	case *httpResponse:
+		response = r
+	case *trailerResponse:
+		response = r.httpResponse
+	default:
+		c.metrics.RecordBackendRequest(ctx, service, instance, resp.StatusCode(), time.Since(start))
+		c.metrics.RecordBackendActiveRequest(ctx, -1)
+		return resp, nil
+	}
+	response.body = &measuredBody{ReadCloser: response.body, done: func(size int64) {
+		c.metrics.RecordBackendResponseSize(ctx, service, instance, size)
+		c.metrics.RecordBackendRequest(ctx, service, instance, response.statusCode, time.Since(start))
+		c.metrics.RecordBackendActiveRequest(ctx, -1)
+	}}
+	return resp, nil
+}
+
+// measuredBody counts the bytes read from a response body, reporting them
+// when the body is first closed
+type measuredBody struct {
+	io.ReadCloser
+	size int64
+	done func(size int64)
+	once sync.Once
+}
+
+func (b *measuredBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	return n, err
+}
+
+func (b *measuredBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.size) })
+	return err
+}
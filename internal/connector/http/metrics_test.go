@@ -0,0 +1,158 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+)
+
+// backendMetrics records the backend metrics of a connector
+type backendMetrics struct {
+	mu            sync.Mutex
+	statuses      []int
+	errors        []error
+	requestSizes  []int64
+	responseSizes []int64
+	active        int64
+}
+
+func (m *backendMetrics) RecordBackendRequest(ctx context.Context, service, instance string, statusCode int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statuses = append(m.statuses, statusCode)
+}
+
+func (m *backendMetrics) RecordBackendError(ctx context.Context, service, instance string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors = append(m.errors, err)
+}
+
+func (m *backendMetrics) RecordBackendRequestSize(ctx context.Context, service, instance string, size int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requestSizes = append(m.requestSizes, size)
+}
+
+func (m *backendMetrics) RecordBackendResponseSize(ctx context.Context, service, instance string, size int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responseSizes = append(m.responseSizes, size)
+}
+
+func (m *backendMetrics) RecordBackendActiveRequest(ctx context.Context, delta int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.active += delta
+}
+
+func TestHTTPConnectorBackendMetrics(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("upstream failed"))
+	}))
+	defer backend.Close()
+	backendURL, _ := url.Parse(backend.URL)
+
+	metrics := &backendMetrics{}
+	connector := NewHTTPConnector(&http.Client{}, 10*time.Second).WithBackendMetrics(metrics)
+	route := &core.RouteResult{
+		ServiceName: "users",
+		Rule:        &core.RouteRule{},
+		Instance:    &core.ServiceInstance{ID: "users-1", Address: backendURL.Hostname(), Port: parsePort(backendURL.Port())},
+	}
+	req := &mockRequest{
+		method:  "POST",
+		path:    "/users",
+		headers: map[string][]string{"Content-Length": {"5"}},
+		body:    io.NopCloser(strings.NewReader("hello")),
+	}
+
+	resp, err := connector.Forward(context.Background(), req, route)
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+	if metrics.active != 1 {
+		t.Errorf("Expected 1 active request until the body is closed, got %d", metrics.active)
+	}
+	io.Copy(io.Discard, resp.Body())
+	resp.Body().Close()
+	resp.Body().Close()
+
+	if metrics.active != 0 {
+		t.Errorf("Expected no active requests, got %d", metrics.active)
+	}
+	if len(metrics.statuses) != 1 || metrics.statuses[0] != http.StatusBadGateway {
+		t.Errorf("Expected one request with status 502, got %v", metrics.statuses)
+	}
+	if len(metrics.requestSizes) != 1 || metrics.requestSizes[0] != 5 {
+		t.Errorf("Expected a request size of 5, got %v", metrics.requestSizes)
+	}
+	if len(metrics.responseSizes) != 1 || metrics.responseSizes[0] != int64(len("upstream failed")) {
+		t.Errorf("Expected a response size of %d, got %v", len("upstream failed"), metrics.responseSizes)
+	}
+
+	// Requests failing before a response are recorded as errors
+	backend.Close()
+	req.body = io.NopCloser(strings.NewReader("hello"))
+	if _, err := connector.Forward(context.Background(), req, route); err == nil {
+		t.Fatal("Expected an error from a closed backend")
+	}
+	if len(metrics.errors) != 1 || !gwerrors.IsConnectFailure(metrics.errors[0]) {
+		t.Errorf("Expected one connect failure, got %v", metrics.errors)
+	}
+	if metrics.active != 0 {
+		t.Errorf("Expected no active requests after the failure, got %d", metrics.active)
+	}
+}
+
+func TestHTTPConnectorBackendMetricsWithTrailers(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.Write([]byte("payload"))
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	defer backend.Close()
+	backendURL, _ := url.Parse(backend.URL)
+
+	metrics := &backendMetrics{}
+	connector := NewHTTPConnector(&http.Client{}, 10*time.Second).WithBackendMetrics(metrics)
+	route := &core.RouteResult{
+		ServiceName: "users",
+		Rule:        &core.RouteRule{},
+		Instance:    &core.ServiceInstance{ID: "users-1", Address: backendURL.Hostname(), Port: parsePort(backendURL.Port())},
+	}
+	resp, err := connector.Forward(context.Background(), &mockRequest{method: "GET", path: "/users"}, route)
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+	trailers, ok := resp.(core.TrailerResponse)
+	if !ok {
+		t.Fatal("Expected a response with trailers")
+	}
+	io.Copy(io.Discard, resp.Body())
+	resp.Body().Close()
+
+	if got := trailers.Trailers()["Grpc-Status"]; len(got) != 1 || got[0] != "0" {
+		t.Errorf("Expected the Grpc-Status trailer, got %v", got)
+	}
+	if len(metrics.statuses) != 1 || metrics.statuses[0] != http.StatusOK {
+		t.Errorf("Expected one request with status 200, got %v", metrics.statuses)
+	}
+	if len(metrics.responseSizes) != 1 || metrics.responseSizes[0] != int64(len("payload")) {
+		t.Errorf("Expected a response size of %d, got %v", len("payload"), metrics.responseSizes)
+	}
+	if metrics.active != 0 {
+		t.Errorf("Expected no active requests, got %d", metrics.active)
+	}
+}
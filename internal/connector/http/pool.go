@@ -0,0 +1,241 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// ErrPoolSaturated is the cause of requests that got no backend connection
+// within the pool wait timeout because the host's connections were all busy
+var ErrPoolSaturated = errors.New("backend connection pool saturated")
+
+// WithPoolWaitTimeout bounds the time a request waits for a connection when
+// the transport has MaxConnsPerHost connections to the backend; requests
+// waiting longer fail with ErrPoolSaturated. Dialing a new connection ends
+// the wait, so the connect timeout still applies to it.
+func (c *HTTPConnector) WithPoolWaitTimeout(timeout time.Duration) *HTTPConnector {
+	c.poolWaitTimeout = timeout
+	return c
+}
+
+// poolWaitTrace times the wait of a request for a pooled connection, which
+// ends once the request gets a connection or starts dialing one
+func poolWaitTrace(timer *phaseTimer, timeout time.Duration) *httptrace.ClientTrace {
+	end := func() { timer.stop() }
+	return &httptrace.ClientTrace{
+		GetConn:      func(string) { timer.start(timeout, ErrPoolSaturated) },
+		DNSStart:     func(httptrace.DNSStartInfo) { end() },
+		ConnectStart: func(string, string) { end() },
+		GotConn:      func(httptrace.GotConnInfo) { end() },
+	}
+}
+
+// PoolMetricsRecorder receives connection pool metrics per backend host
+type PoolMetricsRecorder interface {
+	RecordPoolConnections(ctx context.Context, host string, activeDelta, idleDelta int64)
+	RecordPoolWait(ctx context.Context, host string, wait time.Duration)
+}
+
+// PoolTracker is a RoundTripper reporting active and idle connections and
+// the time spent waiting for a connection from the transport pool. The wait
+// ends when the request gets a connection or starts dialing one, or fails.
+type PoolTracker struct {
+	transport *http.Transport
+	recorder  PoolMetricsRecorder
+}
+
+// NewPoolTracker instruments transport, wrapping its dialer so the lifetime
+// of each pooled connection can be followed
+func NewPoolTracker(transport *http.Transport, recorder PoolMetricsRecorder) *PoolTracker {
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &trackedConn{Conn: conn, host: addr, recorder: recorder}, nil
+	}
+
+	return &PoolTracker{
+		transport: transport,
+		recorder:  recorder,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (p *PoolTracker) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		host      string
+		waitStart time.Time
+		waitOnce  sync.Once
+		conn      *trackedConn
+	)
+
+	ctx := req.Context()
+	// Dials run on their own goroutine, so the wait may end on either
+	endWait := func() {
+		waitOnce.Do(func() {
+			if !waitStart.IsZero() {
+				p.recorder.RecordPoolWait(ctx, host, time.Since(waitStart))
+			}
+		})
+	}
+	trace := &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			host = hostPort
+			waitStart = time.Now()
+		},
+		DNSStart:     func(httptrace.DNSStartInfo) { endWait() },
+		ConnectStart: func(string, string) { endWait() },
+		GotConn: func(info httptrace.GotConnInfo) {
+			endWait()
+			if conn = unwrapTrackedConn(info.Conn); conn != nil {
+				conn.acquire()
+			}
+		},
+	}
+
+	resp, err := p.transport.RoundTrip(req.WithContext(httptrace.WithClientTrace(ctx, trace)))
+	if conn == nil {
+		// Requests that gave up waiting are counted too
+		if err != nil {
+			endWait()
+		}
+		return resp, err
+	}
+	if err != nil {
+		conn.release()
+		return nil, err
+	}
+
+	// Upgraded connections are handed to the caller and leave the pool
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		conn.detach()
+		return resp, nil
+	}
+
+	// The connection stays in use until the body is closed
+	resp.Body = &releaseBody{ReadCloser: resp.Body, conn: conn}
+	return resp, nil
+}
+
+// CloseIdleConnections closes idle connections of the underlying transport
+func (p *PoolTracker) CloseIdleConnections() {
+	p.transport.CloseIdleConnections()
+}
+
+// trackedConn follows a pooled connection between the active and idle states
+type trackedConn struct {
+	net.Conn
+	host     string
+	recorder PoolMetricsRecorder
+
+	mu     sync.Mutex
+	uses   int // HTTP/2 connections serve several requests at once
+	idle   bool
+	closed bool
+}
+
+// acquire marks the connection as serving a request
+func (c *trackedConn) acquire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.uses++
+	if c.uses > 1 {
+		return
+	}
+
+	var idleDelta int64
+	if c.idle {
+		c.idle = false
+		idleDelta = -1
+	}
+	c.recorder.RecordPoolConnections(context.Background(), c.host, 1, idleDelta)
+}
+
+// release marks a request on the connection as finished
+func (c *trackedConn) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.uses == 0 {
+		return
+	}
+	c.uses--
+	if c.uses > 0 {
+		return
+	}
+
+	if c.closed {
+		c.recorder.RecordPoolConnections(context.Background(), c.host, -1, 0)
+		return
+	}
+	c.idle = true
+	c.recorder.RecordPoolConnections(context.Background(), c.host, -1, 1)
+}
+
+// detach stops tracking a connection that no longer belongs to the pool
+func (c *trackedConn) detach() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.uses > 0 {
+		c.uses = 0
+		c.recorder.RecordPoolConnections(context.Background(), c.host, -1, 0)
+	}
+	c.closed = true
+}
+
+// Close closes the connection, removing it from the idle count
+func (c *trackedConn) Close() error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		if c.idle {
+			c.idle = false
+			c.recorder.RecordPoolConnections(context.Background(), c.host, 0, -1)
+		}
+	}
+	c.mu.Unlock()
+
+	return c.Conn.Close()
+}
+
+// unwrapTrackedConn finds the tracked connection beneath TLS wrappers
+func unwrapTrackedConn(conn net.Conn) *trackedConn {
+	for conn != nil {
+		if tracked, ok := conn.(*trackedConn); ok {
+			return tracked
+		}
+		wrapper, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			return nil
+		}
+		conn = wrapper.NetConn()
+	}
+	return nil
+}
+
+// releaseBody releases the connection once the response body is closed
+type releaseBody struct {
+	io.ReadCloser
+	conn *trackedConn
+	once sync.Once
+}
+
+// Close closes the body and releases the connection
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.conn.release)
+	return err
+}
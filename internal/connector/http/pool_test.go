@@ -0,0 +1,199 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+)
+
+// poolRecorder records pool metrics per host
+type poolRecorder struct {
+	mu     sync.Mutex
+	active map[string]int64
+	idle   map[string]int64
+	waits  int
+}
+
+func newPoolRecorder() *poolRecorder {
+	return &poolRecorder{
+		active: make(map[string]int64),
+		idle:   make(map[string]int64),
+	}
+}
+
+func (r *poolRecorder) RecordPoolConnections(ctx context.Context, host string, activeDelta, idleDelta int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active[host] += activeDelta
+	r.idle[host] += idleDelta
+}
+
+func (r *poolRecorder) RecordPoolWait(ctx context.Context, host string, wait time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.waits++
+}
+
+func (r *poolRecorder) counts(host string) (int64, int64, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.active[host], r.idle[host], r.waits
+}
+
+func TestPoolTracker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	host := serverURL.Host
+
+	recorder := newPoolRecorder()
+	transport := &http.Transport{}
+	client := &http.Client{Transport: NewPoolTracker(transport, recorder)}
+	defer transport.CloseIdleConnections()
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	// Connection is active until the body is closed
+	if active, idle, waits := recorder.counts(host); active != 1 || idle != 0 || waits != 1 {
+		t.Errorf("Expected 1 active, 0 idle, 1 wait, got %d, %d, %d", active, idle, waits)
+	}
+
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if active, idle, _ := recorder.counts(host); active != 0 || idle != 1 {
+		t.Errorf("Expected 0 active, 1 idle after close, got %d, %d", active, idle)
+	}
+
+	// The idle connection is reused
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if active, idle, waits := recorder.counts(host); active != 1 || idle != 0 || waits != 2 {
+		t.Errorf("Expected reused connection to be active, got %d active, %d idle, %d waits", active, idle, waits)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// Closing idle connections removes them from the pool
+	transport.CloseIdleConnections()
+	if active, idle, _ := recorder.counts(host); active != 0 || idle != 0 {
+		t.Errorf("Expected empty pool, got %d active, %d idle", active, idle)
+	}
+}
+
+func TestPoolTracker_RequestError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hijack and close so the request fails after the connection is acquired
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	recorder := newPoolRecorder()
+	client := &http.Client{Transport: NewPoolTracker(&http.Transport{}, recorder)}
+
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("Expected request to fail")
+	}
+	if active, _, _ := recorder.counts(serverURL.Host); active != 0 {
+		t.Errorf("Expected no active connections after error, got %d", active)
+	}
+}
+
+func TestHTTPConnectorPoolWaitTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	// Dials take longer than the pool wait timeout, which they are not
+	// subject to
+	dialer := DialContext(&net.Dialer{})
+	transport := &http.Transport{
+		MaxConnsPerHost: 1,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer(ctx, network, addr)
+			time.Sleep(200 * time.Millisecond)
+			return conn, err
+		},
+	}
+	defer transport.CloseIdleConnections()
+	recorder := newPoolRecorder()
+	client := &http.Client{Transport: NewPoolTracker(transport, recorder)}
+	connector := NewHTTPConnector(client, 10*time.Second).WithPoolWaitTimeout(50 * time.Millisecond)
+
+	route := &core.RouteResult{
+		Instance: &core.ServiceInstance{ID: "backend", Address: serverURL.Hostname(), Port: parsePort(serverURL.Port())},
+	}
+	forward := func(path string) (core.Response, error) {
+		return connector.Forward(context.Background(), &mockRequest{method: "GET", path: path, url: path, headers: map[string][]string{}}, route)
+	}
+
+	// The slow request holds the only connection
+	slow := make(chan error, 1)
+	go func() {
+		resp, err := forward("/slow")
+		if err == nil {
+			io.ReadAll(resp.Body())
+			resp.Body().Close()
+		}
+		slow <- err
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if active, _, _ := recorder.counts(serverURL.Host); active == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the slow request to get a connection")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	start := time.Now()
+	_, err := forward("/fast")
+	if !errors.Is(err, ErrPoolSaturated) || gwerrors.HTTPStatus(err) != http.StatusServiceUnavailable {
+		t.Fatalf("Expected a 503 caused by ErrPoolSaturated, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the request to fail after the pool wait timeout, took %v", elapsed)
+	}
+	if _, _, waits := recorder.counts(serverURL.Host); waits != 2 {
+		t.Errorf("Expected the failed wait to be recorded, got %d waits", waits)
+	}
+
+	// Once the connection is free, requests get it again
+	close(release)
+	if err := <-slow; err != nil {
+		t.Fatalf("Slow request failed: %v", err)
+	}
+	resp, err := forward("/fast")
+	if err != nil {
+		t.Fatalf("Request after release failed: %v", err)
+	}
+	resp.Body().Close()
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"gateway/internal/core"
+)
+
+var (
+	// ErrConnectTimeout is the cause of requests whose backend could not be
+	// connected to within the connect timeout. It is reported as a connect
+	// failure, so the retry middleware's connect-failure condition covers it.
+	ErrConnectTimeout = errors.New("backend connect timeout")
+	// ErrResponseHeaderTimeout is the cause of requests whose backend sent
+	// no response headers within the response header timeout
+	ErrResponseHeaderTimeout = fmt.Errorf("backend response header timeout: %w", context.DeadlineExceeded)
+	// ErrIdleTimeout is returned by response bodies that received no data
+	// within the idle timeout
+	ErrIdleTimeout = fmt.Errorf("backend response idle timeout: %w", context.DeadlineExceeded)
+
+	// errRequestTimeout is the cause of requests without response headers
+	// within the route timeout
+	errRequestTimeout = fmt.Errorf("backend request timeout: %w", context.DeadlineExceeded)
+)
+
+// connectTimeoutKey carries the connect timeout of a backend request to the
+// dialer
+type connectTimeoutKey struct{}
+
+// DialContext returns a dial function applying the connect timeout of the
+// request it dials for, falling back to the dialer's own timeout. The HTTP
+// connector sets the timeout, so routes can raise or lower it.
+func DialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if timeout, ok := ctx.Value(connectTimeoutKey{}).(time.Duration); ok && timeout > 0 {
+			d := *dialer
+			d.Timeout = timeout
+			return d.DialContext(ctx, network, addr)
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
+// effectiveTimeouts returns the defaults overridden by the route's own
+// backend timeouts
+func effectiveTimeouts(defaults core.BackendTimeouts, rule *core.RouteRule) core.BackendTimeouts {
+	timeouts := defaults
+	if rule == nil || rule.BackendTimeouts == nil {
+		return timeouts
+	}
+	if rule.BackendTimeouts.Connect > 0 {
+		timeouts.Connect = rule.BackendTimeouts.Connect
+	}
+	if rule.BackendTimeouts.ResponseHeader > 0 {
+		timeouts.ResponseHeader = rule.BackendTimeouts.ResponseHeader
+	}
+	if rule.BackendTimeouts.Idle > 0 {
+		timeouts.Idle = rule.BackendTimeouts.Idle
+	}
+	return timeouts
+}
+
+// isDialTimeout reports whether err is a backend dial that timed out
+func isDialTimeout(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial" && opErr.Timeout()
+}
+
+// phaseTimer cancels a request with a cause when a phase, such as waiting
+// for response headers, takes too long
+type phaseTimer struct {
+	mu     sync.Mutex
+	timer  *time.Timer
+	cancel context.CancelCauseFunc
+}
+
+// start starts timing a phase, replacing any phase being timed
+func (t *phaseTimer) start(timeout time.Duration, cause error) {
+	if timeout <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.timer = time.AfterFunc(timeout, func() { t.cancel(cause) })
+}
+
+// stop stops timing the current phase
+func (t *phaseTimer) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// idleTimeoutBody aborts a response body that receives no data within the
+// idle timeout, and ends the backend request once the body is closed
+type idleTimeoutBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	timeout time.Duration
+	timer   *time.Timer
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, ctx context.Context, cancel context.CancelCauseFunc, timeout time.Duration) *idleTimeoutBody {
+	b := &idleTimeoutBody{ReadCloser: body, ctx: ctx, cancel: cancel, timeout: timeout}
+	if timeout > 0 {
+		b.timer = time.AfterFunc(timeout, func() { cancel(ErrIdleTimeout) })
+	}
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && errors.Is(context.Cause(b.ctx), ErrIdleTimeout) {
+		return n, ErrIdleTimeout
+	}
+	if n > 0 && b.timer != nil {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	err := b.ReadCloser.Close()
+	b.cancel(nil)
+	return err
+}
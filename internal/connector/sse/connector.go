@@ -2,6 +2,7 @@ package sse
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -13,11 +14,25 @@ import (
 	"gateway/pkg/errors"
 )
 
+// DefaultMaxEventSize is the largest backend event relayed when no limit is
+// set
+const DefaultMaxEventSize = 1024 * 1024 // 1MB
+
+// DefaultRetryInterval is the delay before the first reconnect to a
+// dropped backend stream when none is configured
+const DefaultRetryInterval = time.Second
+
+// maxRetryInterval caps the delay between reconnects as it doubles
+const maxRetryInterval = 30 * time.Second
+
 // Config represents SSE backend configuration
 type Config struct {
 	DialTimeout      time.Duration
 	ResponseTimeout  time.Duration
 	KeepaliveTimeout time.Duration
+	MaxEventSize     int           // Largest event read from a backend in bytes; 0 means unlimited
+	RetryInterval    time.Duration // Delay before the first reconnect, doubled on each further attempt
+	MaxRetries       int           // Reconnects to a dropped backend stream; 0 disables reconnecting
 }
 
 // DefaultConfig returns default configuration
@@ -26,6 +41,8 @@ func DefaultConfig() *Config {
 		DialTimeout:      10 * time.Second,
 		ResponseTimeout:  30 * time.Second,
 		KeepaliveTimeout: 30 * time.Second,
+		MaxEventSize:     DefaultMaxEventSize,
+		RetryInterval:    DefaultRetryInterval,
 	}
 }
 
@@ -62,6 +79,24 @@ func NewConnector(config *Config, client *http.Client, logger *slog.Logger) *Con
 
 // Connect establishes an SSE connection to a backend service
 func (c *Connector) Connect(ctx context.Context, instance *core.ServiceInstance, path string, headers http.Header) (*Connection, error) {
+	resp, err := c.open(ctx, instance, path, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Connection{
+		resp:      resp,
+		reader:    newReader(resp.Body, c.config.MaxEventSize),
+		instance:  instance,
+		logger:    c.logger,
+		connector: c,
+		path:      path,
+		headers:   headers,
+	}, nil
+}
+
+// open requests the event stream of path from a backend instance
+func (c *Connector) open(ctx context.Context, instance *core.ServiceInstance, path string, headers http.Header) (*http.Response, error) {
 	// Build URL
 	scheme := instance.Scheme
 	if scheme == "" {
@@ -119,12 +154,7 @@ func (c *Connector) Connect(ctx context.Context, instance *core.ServiceInstance,
 		)
 	}
 
-	return &Connection{
-		resp:     resp,
-		reader:   newReader(resp.Body),
-		instance: instance,
-		logger:   c.logger,
-	}, nil
+	return resp, nil
 }
 
 // Connection represents an SSE connection to a backend service
@@ -134,6 +164,13 @@ type Connection struct {
 	instance *core.ServiceInstance
 	logger   *slog.Logger
 	closed   bool
+
+	// Used by Proxy to reopen the stream when it drops
+	connector   *Connector
+	path        string
+	headers     http.Header
+	lastEventID string        // ID of the last event read, resent as Last-Event-ID
+	retry       time.Duration // Reconnect delay requested by the backend
 }
 
 // ReadEvent reads the next event from the backend
@@ -141,7 +178,16 @@ func (c *Connection) ReadEvent() (*core.SSEEvent, error) {
 	if c.closed {
 		return nil, errors.NewError(errors.ErrorTypeInternal, "SSE connection closed")
 	}
-	return c.reader.ReadEvent()
+	event, err := c.reader.ReadEvent()
+	if err == nil {
+		if event.ID != "" {
+			c.lastEventID = event.ID
+		}
+		if event.Retry > 0 {
+			c.retry = time.Duration(event.Retry) * time.Millisecond
+		}
+	}
+	return event, err
 }
 
 // Close closes the connection
@@ -154,10 +200,13 @@ func (c *Connection) Close() error {
 	return c.resp.Body.Close()
 }
 
-// Proxy proxies events from backend to client
+// Proxy proxies events from backend to client. When the backend stream
+// drops, it is reopened up to MaxRetries times with Last-Event-ID set to the
+// last event relayed, while the client stays connected.
 func (c *Connection) Proxy(ctx context.Context, clientWriter core.SSEWriter) error {
 	// Start proxying events
 	eventCount := 0
+	attempts := 0 // Reconnects since the last event
 
 	for {
 		select {
@@ -171,6 +220,21 @@ func (c *Connection) Proxy(ctx context.Context, clientWriter core.SSEWriter) err
 
 		default:
 			event, err := c.ReadEvent()
+			for err != nil && c.reconnectable(ctx, err, attempts) {
+				attempts++
+				err = c.reconnect(ctx, err, attempts)
+			}
+			if event == nil && err == nil {
+				// The stream was reopened
+				continue
+			}
+			if err != nil && attempts > 0 {
+				c.logger.Error("SSE backend stream not reopened",
+					"instance", c.instance.ID,
+					"attempts", attempts,
+					"error", err,
+				)
+			}
 			if err != nil {
 				if err == io.EOF {
 					c.logger.Info("SSE backend closed connection gracefully",
@@ -209,6 +273,7 @@ func (c *Connection) Proxy(ctx context.Context, clientWriter core.SSEWriter) err
 				)
 				return errors.NewError(errors.ErrorTypeInternal, "failed to read SSE event").WithCause(err)
 			}
+			attempts = 0
 
 			// Forward event to client
 			if err := clientWriter.WriteEvent(event); err != nil {
@@ -230,6 +295,14 @@ func (c *Connection) Proxy(ctx context.Context, clientWriter core.SSEWriter) err
 				return errors.NewError(errors.ErrorTypeInternal, "failed to write SSE event to client").WithCause(err)
 			}
 
+			// Force out buffered events once no more are waiting, so a
+			// burst of events is flushed together
+			if b, ok := c.reader.(interface{ Buffered() int }); !ok || b.Buffered() == 0 {
+				if err := clientWriter.Flush(); err != nil {
+					return errors.NewError(errors.ErrorTypeInternal, "failed to flush SSE events to client").WithCause(err)
+				}
+			}
+
 			eventCount++
 
 			// Log periodic stats
@@ -242,3 +315,68 @@ func (c *Connection) Proxy(ctx context.Context, clientWriter core.SSEWriter) err
 		}
 	}
 }
+
+// reconnectable reports whether the backend stream may be reopened after
+// failing with err. Events over the size limit would fail again.
+func (c *Connection) reconnectable(ctx context.Context, err error, attempts int) bool {
+	return c.connector != nil && ctx.Err() == nil && !stderrors.Is(err, errEventTooLarge) &&
+		attempts < c.connector.config.MaxRetries
+}
+
+// reconnect reopens the backend stream after it failed with cause, once
+// the retry interval, doubled for each attempt but the first, has passed
+func (c *Connection) reconnect(ctx context.Context, cause error, attempt int) error {
+	delay := c.retryDelay(attempt)
+	c.logger.Warn("SSE backend stream dropped, reconnecting",
+		"instance", c.instance.ID,
+		"attempt", attempt,
+		"delay", delay,
+		"last_event_id", c.lastEventID,
+		"error", cause,
+	)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return errors.NewError(errors.ErrorTypeTimeout, "SSE proxy context cancelled").WithCause(ctx.Err())
+	case <-timer.C:
+	}
+
+	headers := c.headers.Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	if c.lastEventID != "" {
+		headers.Set("Last-Event-ID", c.lastEventID)
+	}
+	resp, err := c.connector.open(ctx, c.instance, c.path, headers)
+	if err != nil {
+		return err
+	}
+
+	c.resp.Body.Close()
+	c.resp = resp
+	c.reader = newReader(resp.Body, c.connector.config.MaxEventSize)
+	c.logger.Info("SSE backend stream reopened",
+		"instance", c.instance.ID,
+		"attempt", attempt,
+	)
+	return nil
+}
+
+// retryDelay returns the delay before reconnect attempt, starting from the
+// interval requested by the backend, if any
+func (c *Connection) retryDelay(attempt int) time.Duration {
+	delay := c.retry
+	if delay <= 0 {
+		delay = c.connector.config.RetryInterval
+	}
+	if delay <= 0 {
+		delay = DefaultRetryInterval
+	}
+	for i := 1; i < attempt && delay < maxRetryInterval; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryInterval)
+}
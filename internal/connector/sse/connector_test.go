@@ -526,3 +526,118 @@ func (m *mockSSEWriter) Flush() error {
 func (m *mockSSEWriter) Close() error {
 	return nil
 }
+
+func TestReader_MaxEventSize(t *testing.T) {
+	// "data: 12345\n" and the blank line ending the event are 13 bytes
+	event := "data: 12345\n\n"
+
+	tests := []struct {
+		name         string
+		stream       string
+		maxEventSize int
+		events       int
+		expectError  bool
+	}{
+		{name: "at limit", stream: event + event, maxEventSize: 13, events: 2},
+		{name: "over limit", stream: event, maxEventSize: 12, expectError: true},
+		{name: "unlimited", stream: "data: " + strings.Repeat("x", 1<<16) + "\n\n", events: 1},
+		{name: "long line over limit", stream: "data: " + strings.Repeat("x", 1<<16) + "\n\n", maxEventSize: 1024, expectError: true},
+		{name: "blank lines between events", stream: "\n\n\n" + event + "\n\n" + event, maxEventSize: 13, events: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReader(strings.NewReader(tt.stream), tt.maxEventSize)
+			for i := 0; i < tt.events; i++ {
+				if _, err := r.ReadEvent(); err != nil {
+					t.Fatalf("Failed to read event %d: %v", i, err)
+				}
+			}
+			if !tt.expectError {
+				return
+			}
+
+			_, err := r.ReadEvent()
+			var gwErr *gwerrors.Error
+			if !errors.As(err, &gwErr) || !strings.Contains(gwErr.Message, "exceeds maximum size") {
+				t.Errorf("Expected event size error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestConnection_ProxyReconnect(t *testing.T) {
+	// The backend drops the stream after the first event, then resumes
+	// after the Last-Event-ID it is sent
+	var requests []string
+	server := createMockSSEServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Header.Get("Last-Event-ID"))
+		switch r.Header.Get("Last-Event-ID") {
+		case "":
+			fmt.Fprint(w, "id: 1\ndata: first\n\n")
+		case "1":
+			fmt.Fprint(w, "id: 2\ndata: second\n\nevent: end\ndata: done\n\n")
+		}
+		w.(http.Flusher).Flush()
+	})
+	defer server.Close()
+
+	_, portStr, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	var port int
+	_, _ = fmt.Sscanf(portStr, "%d", &port)
+	instance := &core.ServiceInstance{ID: "backend", Address: "127.0.0.1", Port: port}
+
+	config := DefaultConfig()
+	config.RetryInterval = 10 * time.Millisecond
+	config.MaxRetries = 2
+	conn, err := NewConnector(config, nil, slog.Default()).Connect(context.Background(), instance, "/", http.Header{"X-Test": {"kept"}})
+	if err != nil {
+		t.Fatalf("Failed to connect to backend: %v", err)
+	}
+	defer conn.Close()
+
+	writer := &mockSSEWriter{w: httptest.NewRecorder()}
+	err = conn.Proxy(context.Background(), writer)
+
+	// Once the second stream ends, reconnects fail to resume it
+	var gwErr *gwerrors.Error
+	if !errors.As(err, &gwErr) {
+		t.Fatalf("Expected an error once retries are exhausted, got %v", err)
+	}
+	var data []string
+	for _, event := range writer.events {
+		data = append(data, event.Data)
+	}
+	if got := strings.Join(data, ","); got != "first,second,done" {
+		t.Errorf("Expected the events of both streams, got %s", got)
+	}
+	if got := strings.Join(requests, ","); got != ",1,2,2" {
+		t.Errorf("Expected Last-Event-ID to be resent on each reconnect, got %q", got)
+	}
+}
+
+func TestConnection_ProxyNoReconnect(t *testing.T) {
+	requests := 0
+	server := createMockSSEServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, "id: 1\ndata: first\n\n")
+	})
+	defer server.Close()
+
+	_, portStr, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	var port int
+	_, _ = fmt.Sscanf(portStr, "%d", &port)
+
+	conn, err := NewConnector(DefaultConfig(), nil, slog.Default()).Connect(context.Background(), &core.ServiceInstance{ID: "backend", Address: "127.0.0.1", Port: port}, "/", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to backend: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.Proxy(context.Background(), &mockSSEWriter{w: httptest.NewRecorder()}); err == nil {
+		t.Error("Expected the dropped stream to end the proxy")
+	}
+	if requests != 1 {
+		t.Errorf("Expected no reconnect by default, got %d requests", requests)
+	}
+}
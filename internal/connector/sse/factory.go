@@ -47,6 +47,9 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 		DialTimeout:      time.Duration(sseConfig.ConnectTimeout) * time.Second,
 		ResponseTimeout:  time.Duration(sseConfig.ReadTimeout) * time.Second,
 		KeepaliveTimeout: 30 * time.Second, // Default keepalive
+		MaxEventSize:     sseConfig.MaxEventSize,
+		RetryInterval:    time.Duration(sseConfig.RetryInterval) * time.Second,
+		MaxRetries:       sseConfig.MaxRetries,
 	}
 	
 	// Set defaults if not configured
@@ -59,6 +62,12 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 	if c.config.KeepaliveTimeout == 0 {
 		c.config.KeepaliveTimeout = 30 * time.Second
 	}
+	if c.config.MaxEventSize == 0 {
+		c.config.MaxEventSize = DefaultMaxEventSize
+	}
+	if c.config.RetryInterval == 0 {
+		c.config.RetryInterval = DefaultRetryInterval
+	}
 	
 	// Create connector
 	c.connector = NewConnector(c.config, c.client, c.logger)
@@ -2,6 +2,8 @@ package sse
 
 import (
 	"bufio"
+	stderrors "errors"
+	"fmt"
 	"io"
 	"strconv"
 	"strings"
@@ -10,19 +12,32 @@ import (
 	"gateway/pkg/errors"
 )
 
+// errEventTooLarge is the cause of errors reading events over the size limit
+var errEventTooLarge = stderrors.New("SSE event too large")
+
 // reader implements core.SSEReader
 type reader struct {
-	r      *bufio.Reader
-	closed bool
+	r            *bufio.Reader
+	closed       bool
+	maxEventSize int // 0 means unlimited
+	size         int // Bytes read for the current event
 }
 
-// newReader creates a new SSE reader
-func newReader(r io.Reader) *reader {
+// newReader creates a new SSE reader failing on events larger than
+// maxEventSize bytes
+func newReader(r io.Reader, maxEventSize int) *reader {
 	return &reader{
-		r: bufio.NewReader(r),
+		r:            bufio.NewReader(r),
+		maxEventSize: maxEventSize,
 	}
 }
 
+// Buffered returns the number of bytes read from the backend but not yet
+// returned as events
+func (r *reader) Buffered() int {
+	return r.r.Buffered()
+}
+
 // ReadEvent reads the next SSE event
 func (r *reader) ReadEvent() (*core.SSEEvent, error) {
 	if r.closed {
@@ -31,10 +46,14 @@ func (r *reader) ReadEvent() (*core.SSEEvent, error) {
 
 	event := &core.SSEEvent{}
 	var dataLines []string
+	r.size = 0
 
 	for {
-		line, err := r.r.ReadString('\n')
+		line, err := r.readLine()
 		if err != nil {
+			if sizeErr, ok := err.(*errors.Error); ok {
+				return nil, sizeErr
+			}
 			if err == io.EOF && len(dataLines) > 0 {
 				// Process any remaining data
 				event.Data = strings.Join(dataLines, "\n")
@@ -61,6 +80,7 @@ func (r *reader) ReadEvent() (*core.SSEEvent, error) {
 				return event, nil
 			}
 			// Skip empty lines between events
+			r.size = 0
 			continue
 		}
 
@@ -100,6 +120,27 @@ func (r *reader) ReadEvent() (*core.SSEEvent, error) {
 	}
 }
 
+// readLine reads the next line of the current event, failing as soon as the
+// event grows past the maximum size so an oversized line is never buffered
+// whole
+func (r *reader) readLine() (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.r.ReadSlice('\n')
+		r.size += len(chunk)
+		if r.maxEventSize > 0 && r.size > r.maxEventSize {
+			return "", errors.NewError(
+				errors.ErrorTypeInternal,
+				fmt.Sprintf("SSE event exceeds maximum size of %d bytes", r.maxEventSize),
+			).WithCause(errEventTooLarge)
+		}
+		line = append(line, chunk...)
+		if err != bufio.ErrBufferFull {
+			return string(line), err
+		}
+	}
+}
+
 // Close closes the reader
 func (r *reader) Close() error {
 	r.closed = true
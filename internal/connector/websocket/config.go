@@ -16,10 +16,13 @@ type Config struct {
 	// Message settings
 	MaxMessageSize int64 `yaml:"maxMessageSize"`
 
-	// Connection pool settings
+	// Connection pool settings. Backend connections are dialed for each
+	// client unless ReuseConnections is set, in which case up to
+	// MaxConnections per instance are kept idle for later clients.
 	MaxConnections        int           `yaml:"maxConnections"`
 	ConnectionTimeout     time.Duration `yaml:"connectionTimeout"`
 	IdleConnectionTimeout time.Duration `yaml:"idleConnectionTimeout"`
+	ReuseConnections      bool          `yaml:"reuseConnections"`
 
 	// Keepalive settings
 	PingInterval time.Duration `yaml:"pingInterval"`
@@ -2,12 +2,14 @@ package websocket
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"gateway/internal/core"
@@ -18,17 +20,18 @@ import (
 // DefaultConfig returns default configuration
 func DefaultConfig() *Config {
 	return &Config{
-		HandshakeTimeout:  10 * time.Second,
-		ReadTimeout:       60 * time.Second,
-		WriteTimeout:      60 * time.Second,
-		ReadBufferSize:    4096,
-		WriteBufferSize:   4096,
-		MaxMessageSize:    1024 * 1024, // 1MB
-		MaxConnections:    10,
-		ConnectionTimeout: 10 * time.Second,
-		PingInterval:      30 * time.Second,
-		PongTimeout:       10 * time.Second,
-		CloseTimeout:      5 * time.Second,
+		HandshakeTimeout:      10 * time.Second,
+		ReadTimeout:           60 * time.Second,
+		WriteTimeout:          60 * time.Second,
+		ReadBufferSize:        4096,
+		WriteBufferSize:       4096,
+		MaxMessageSize:        1024 * 1024, // 1MB
+		MaxConnections:        10,
+		ConnectionTimeout:     10 * time.Second,
+		IdleConnectionTimeout: DefaultIdleConnectionTimeout,
+		PingInterval:          30 * time.Second,
+		PongTimeout:           10 * time.Second,
+		CloseTimeout:          5 * time.Second,
 	}
 }
 
@@ -36,6 +39,7 @@ func DefaultConfig() *Config {
 type Connector struct {
 	config *Config
 	dialer *websocket.Dialer
+	pool   *connPool // Set when backend connections are reused
 	logger *slog.Logger
 }
 
@@ -54,11 +58,33 @@ func NewConnector(config *Config, logger *slog.Logger) *Connector {
 		}).Dial,
 	}
 
-	return &Connector{
+	connector := &Connector{
 		config: config,
 		dialer: dialer,
 		logger: logger,
 	}
+	if config.ReuseConnections && config.MaxConnections > 0 {
+		connector.pool = newConnPool(config.MaxConnections, config.IdleConnectionTimeout, logger)
+	}
+	return connector
+}
+
+// WithMetrics sets the recorder of backend connection pool metrics; it has
+// no effect unless connections are reused
+func (c *Connector) WithMetrics(metrics PoolMetricsRecorder) *Connector {
+	if c.pool != nil {
+		c.pool.metrics = metrics
+	}
+	return c
+}
+
+// Close closes the idle backend connections. Connections in use are
+// closed, rather than pooled, once their client goes away.
+func (c *Connector) Close() error {
+	if c.pool != nil {
+		c.pool.close()
+	}
+	return nil
 }
 
 // Connect establishes a WebSocket connection to a backend service
@@ -75,6 +101,19 @@ func (c *Connector) Connect(ctx context.Context, instance *core.ServiceInstance,
 		Path:   path,
 	}
 
+	var key string
+	if c.pool != nil {
+		key = poolKey(instance.ID, u.String(), headers)
+		if conn := c.pool.get(key); conn != nil {
+			c.logger.Debug("Reusing WebSocket backend connection",
+				"url", u.String(),
+				"instance", instance.ID,
+			)
+			c.pool.activate(conn, true)
+			return conn, nil
+		}
+	}
+
 	c.logger.Debug("Connecting to WebSocket backend",
 		"url", u.String(),
 		"instance", instance.ID,
@@ -109,12 +148,17 @@ func (c *Connector) Connect(ctx context.Context, instance *core.ServiceInstance,
 	// Set max message size
 	conn.SetReadLimit(c.config.MaxMessageSize)
 
-	return &Connection{
+	connection := &Connection{
 		conn:     conn,
 		instance: instance,
 		logger:   c.logger,
 		config:   c.config,
-	}, nil
+	}
+	if c.pool != nil {
+		connection.pooled(c.pool, key, u.Host)
+		c.pool.activate(connection, false)
+	}
+	return connection, nil
 }
 
 // Connection represents a WebSocket connection to a backend service
@@ -124,10 +168,62 @@ type Connection struct {
 	logger   *slog.Logger
 	config   *Config
 	mu       sync.Mutex
+
+	// Applied by Proxy to client and backend messages
+	inbound  MessageTransform
+	outbound MessageTransform
+
+	// Set for connections reused by later clients; see pooled
+	pool      *connPool
+	poolKey   string
+	host      string
+	reads     chan readResult
+	leaseMu   sync.Mutex
+	lease     chan struct{} // Closed when the client releases the connection
+	closed    chan struct{}
+	closeOnce sync.Once
+	broken    atomic.Bool
+	active    atomic.Bool
+	idleSince time.Time
+}
+
+// WithTransforms sets the transforms Proxy applies to messages from the
+// client (inbound) and from the backend (outbound); either may be nil.
+// Each runs on the goroutine of its direction, so a slow transform holds
+// up only its own direction.
+func (c *Connection) WithTransforms(inbound, outbound MessageTransform) *Connection {
+	c.inbound = inbound
+	c.outbound = outbound
+	return c
+}
+
+// transform applies t to msg, returning nil when the message is dropped
+func (c *Connection) transform(t MessageTransform, msg *core.WebSocketMessage, direction string) *core.WebSocketMessage {
+	if t == nil {
+		return msg
+	}
+	transformed, err := t(msg)
+	if err != nil {
+		c.logger.Debug("Dropping WebSocket message that failed to transform",
+			"error", err,
+			"instance", c.instance.ID,
+			"direction", direction,
+		)
+		return nil
+	}
+	return transformed
 }
 
 // ReadMessage reads a message from the backend
 func (c *Connection) ReadMessage() (*core.WebSocketMessage, error) {
+	if c.pool != nil {
+		return c.readLeased(c.currentLease())
+	}
+	return c.readMessage()
+}
+
+// readMessage reads a message from the backend connection
+func (c *Connection) readMessage() (*core.WebSocketMessage, error) {
 	msgType, data, err := c.conn.ReadMessage()
 	if err != nil {
 		if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
@@ -147,6 +243,10 @@ func (c *Connection) WriteMessage(msg *core.WebSocketMessage) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.config.MaxMessageSize > 0 && int64(len(msg.Data)) > c.config.MaxMessageSize &&
+		(msg.Type == core.WebSocketTextMessage || msg.Type == core.WebSocketBinaryMessage) {
+		return core.ErrWebSocketMessageTooBig
+	}
 	if err := c.conn.WriteMessage(mapMessageTypeReverse(msg.Type), msg.Data); err != nil {
 		return errors.NewError(errors.ErrorTypeInternal, "failed to write WebSocket message").WithCause(err)
 	}
@@ -155,6 +255,10 @@ func (c *Connection) WriteMessage(msg *core.WebSocketMessage) error {
 
 // Close closes the connection
 func (c *Connection) Close() error {
+	if c.pool != nil {
+		c.closeOnce.Do(func() { close(c.closed) })
+		c.pool.deactivate(c)
+	}
 	if err := c.conn.Close(); err != nil {
 		return errors.NewError(errors.ErrorTypeInternal, "failed to close WebSocket connection").WithCause(err)
 	}
@@ -215,13 +319,28 @@ func (c *Connection) RemoteAddr() string {
 	return ""
 }
 
-// Proxy bidirectionally proxies messages between client and backend
+// Proxy bidirectionally proxies messages between client and backend. A
+// reusable connection is released to the pool when the client goes away,
+// rather than closed.
 func (c *Connection) Proxy(ctx context.Context, clientConn core.WebSocketConn) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Error channel to coordinate goroutines
 	errChan := make(chan error, 3) // Increased for ping goroutine
 
-	// Track message counts
-	var clientToBackend, backendToClient int
+	// Track message counts; the final log reads them while the other
+	// direction may still be running
+	var clientToBackend, backendToClient atomic.Int64
+
+	// Reads of a reusable connection end once this client releases it
+	readBackend := c.readMessage
+	if c.pool != nil {
+		lease := c.currentLease()
+		readBackend = func() (*core.WebSocketMessage, error) { return c.readLeased(lease) }
+	}
+	var clientGone atomic.Bool
+	clientDone := make(chan struct{})
 
 	// Setup ping/pong handlers if configured
 	if c.config.PingInterval > 0 && c.config.PongTimeout > 0 {
@@ -230,13 +349,16 @@ func (c *Connection) Proxy(ctx context.Context, clientConn core.WebSocketConn) e
 			errChan <- errors.NewError(errors.ErrorTypeInternal, "failed to set initial read deadline").WithCause(err)
 			return err
 		}
-		c.conn.SetPongHandler(func(string) error {
-			if err := c.conn.SetReadDeadline(time.Now().Add(c.config.PongTimeout)); err != nil {
-				// Log error but don't fail the pong handler
+		// Reusable connections have theirs set once, before reading starts
+		if c.pool == nil {
+			c.conn.SetPongHandler(func(string) error {
+				if err := c.conn.SetReadDeadline(time.Now().Add(c.config.PongTimeout)); err != nil {
+					// Log error but don't fail the pong handler
+					return nil
+				}
 				return nil
-			}
-			return nil
-		})
+			})
+		}
 
 		// Start ping ticker for backend connection
 		go func() {
@@ -260,28 +382,30 @@ func (c *Connection) Proxy(ctx context.Context, clientConn core.WebSocketConn) e
 
 	// Client to backend
 	go func() {
+		defer close(clientDone)
 		for {
 			select {
 			case <-ctx.Done():
 				c.logger.Debug("Client to backend proxy cancelled",
 					"instance", c.instance.ID,
-					"messages", clientToBackend,
+					"messages", clientToBackend.Load(),
 				)
 				errChan <- ctx.Err()
 				return
 			default:
 				msg, err := clientConn.ReadMessage()
 				if err != nil {
+					clientGone.Store(true)
 					// Check if it's a normal disconnect
 					if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
 						c.logger.Info("Client closed connection normally",
 							"instance", c.instance.ID,
-							"messages_sent", clientToBackend,
+							"messages_sent", clientToBackend.Load(),
 						)
 					} else if err.Error() == "client disconnected" || err.Error() == "connection is disconnected" {
 						c.logger.Info("Client disconnected",
 							"instance", c.instance.ID,
-							"messages_sent", clientToBackend,
+							"messages_sent", clientToBackend.Load(),
 						)
 					} else {
 						c.logger.Error("Error reading from client",
@@ -293,6 +417,11 @@ func (c *Connection) Proxy(ctx context.Context, clientConn core.WebSocketConn) e
 					return
 				}
 
+				msg = c.transform(c.inbound, msg, "inbound")
+				if msg == nil {
+					continue
+				}
+
 				if err := c.WriteMessage(msg); err != nil {
 					c.logger.Error("Error writing to backend",
 						"error", err,
@@ -302,7 +431,7 @@ func (c *Connection) Proxy(ctx context.Context, clientConn core.WebSocketConn) e
 					errChan <- err
 					return
 				}
-				clientToBackend++
+				clientToBackend.Add(1)
 			}
 		}
 	}()
@@ -314,18 +443,18 @@ func (c *Connection) Proxy(ctx context.Context, clientConn core.WebSocketConn) e
 			case <-ctx.Done():
 				c.logger.Debug("Backend to client proxy cancelled",
 					"instance", c.instance.ID,
-					"messages", backendToClient,
+					"messages", backendToClient.Load(),
 				)
 				errChan <- ctx.Err()
 				return
 			default:
-				msg, err := c.ReadMessage()
+				msg, err := readBackend()
 				if err != nil {
 					// Check if it's a normal disconnect
 					if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
 						c.logger.Info("Backend closed connection normally",
 							"instance", c.instance.ID,
-							"messages_sent", backendToClient,
+							"messages_sent", backendToClient.Load(),
 						)
 					} else {
 						c.logger.Error("Error reading from backend",
@@ -337,12 +466,18 @@ func (c *Connection) Proxy(ctx context.Context, clientConn core.WebSocketConn) e
 					return
 				}
 
+				msg = c.transform(c.outbound, msg, "outbound")
+				if msg == nil {
+					continue
+				}
+
 				if err := clientConn.WriteMessage(msg); err != nil {
+					clientGone.Store(true)
 					// Check if client disconnected
 					if err.Error() == "client disconnected" || err.Error() == "connection is disconnected" {
 						c.logger.Info("Client disconnected during proxy",
 							"instance", c.instance.ID,
-							"messages_sent", backendToClient,
+							"messages_sent", backendToClient.Load(),
 						)
 					} else {
 						c.logger.Error("Error writing to client",
@@ -354,7 +489,7 @@ func (c *Connection) Proxy(ctx context.Context, clientConn core.WebSocketConn) e
 					errChan <- err
 					return
 				}
-				backendToClient++
+				backendToClient.Add(1)
 			}
 		}
 	}()
@@ -365,15 +500,34 @@ func (c *Connection) Proxy(ctx context.Context, clientConn core.WebSocketConn) e
 	// Log final statistics
 	c.logger.Info("WebSocket proxy completed",
 		"instance", c.instance.ID,
-		"client_to_backend", clientToBackend,
-		"backend_to_client", backendToClient,
+		"client_to_backend", clientToBackend.Load(),
+		"backend_to_client", backendToClient.Load(),
 		"error", err,
 	)
 
-	// Send close frames to both sides
-	closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "proxy ended")
-	if err := c.conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second)); err != nil {
-		c.logger.Debug("Failed to write close message to backend", "error", err)
+	if c.pool != nil && clientGone.Load() && !isMessageTooBig(err) && c.releaseFrom(cancel, clientConn, clientDone) {
+		c.logger.Debug("WebSocket backend connection released to the pool",
+			"instance", c.instance.ID,
+		)
+		return nil
+	}
+
+	// Send close frames to both sides. A message over the size limit in
+	// either direction closes both with 1009 (message too big); the side
+	// whose read limit was hit may already have been sent one.
+	if isMessageTooBig(err) {
+		closeMsg := websocket.FormatCloseMessage(websocket.CloseMessageTooBig, "message too big")
+		if err := c.conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second)); err != nil {
+			c.logger.Debug("Failed to write close message to backend", "error", err)
+		}
+		if err := clientConn.WriteMessage(&core.WebSocketMessage{Type: core.WebSocketCloseMessage, Data: closeMsg}); err != nil {
+			c.logger.Debug("Failed to write close message to client", "error", err)
+		}
+	} else {
+		closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "proxy ended")
+		if err := c.conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second)); err != nil {
+			c.logger.Debug("Failed to write close message to backend", "error", err)
+		}
 	}
 
 	// Close both connections
@@ -391,6 +545,12 @@ func (c *Connection) Proxy(ctx context.Context, clientConn core.WebSocketConn) e
 	return err
 }
 
+// isMessageTooBig reports whether err comes from a message over the size
+// limit of either connection
+func isMessageTooBig(err error) bool {
+	return stderrors.Is(err, websocket.ErrReadLimit) || stderrors.Is(err, core.ErrWebSocketMessageTooBig)
+}
+
 // mapMessageType maps gorilla websocket message types to core types
 func mapMessageType(t int) core.WebSocketMessageType {
 	switch t {
@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -699,3 +700,150 @@ func (m *mockWebSocketConn) RemoteAddr() string {
 	}
 	return ""
 }
+
+// proxySizeLimit proxies between a dialed client and a backend running
+// backendHandler through a connector limited to 100-byte messages. It
+// returns the client side and the close code the backend received.
+func proxySizeLimit(t *testing.T, backendHandler func(*websocket.Conn)) (*websocket.Conn, chan int) {
+	t.Helper()
+
+	backendClose := make(chan int, 1)
+	backendServer := createMockWebSocketServer(t, func(conn *websocket.Conn) {
+		go backendHandler(conn)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				var closeErr *websocket.CloseError
+				if errors.As(err, &closeErr) {
+					backendClose <- closeErr.Code
+				}
+				close(backendClose)
+				return
+			}
+		}
+	})
+	t.Cleanup(backendServer.Close)
+
+	serverURL := strings.TrimPrefix(backendServer.URL, "http://")
+	host, portStr, _ := net.SplitHostPort(serverURL)
+	port := 0
+	_, _ = fmt.Sscanf(portStr, "%d", &port)
+
+	config := DefaultConfig()
+	config.MaxMessageSize = 100
+	config.PingInterval = 0
+	backendConn, err := NewConnector(config, slog.Default()).Connect(context.Background(), &core.ServiceInstance{ID: "backend", Address: host, Port: port}, "/", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to backend: %v", err)
+	}
+
+	clientServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		go backendConn.Proxy(context.Background(), &mockWebSocketConn{conn: conn})
+	}))
+	t.Cleanup(clientServer.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial(strings.Replace(clientServer.URL, "http", "ws", 1), nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to client server: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, backendClose
+}
+
+// expectClose reads from conn until it is closed, returning the close code
+func expectClose(t *testing.T, conn *websocket.Conn) int {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) {
+			t.Fatalf("Expected close frame, got %v", err)
+		}
+		return closeErr.Code
+	}
+}
+
+func TestConnection_Proxy_MessageSizeLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend func(*websocket.Conn)
+		client  func(*websocket.Conn)
+	}{
+		{
+			name: "backend message over limit",
+			backend: func(conn *websocket.Conn) {
+				conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("b", 100)))
+				conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("b", 101)))
+			},
+		},
+		{
+			name:    "client message over limit",
+			backend: func(conn *websocket.Conn) {},
+			client: func(conn *websocket.Conn) {
+				conn.WriteMessage(websocket.BinaryMessage, []byte(strings.Repeat("c", 101)))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, backendClose := proxySizeLimit(t, tt.backend)
+			if tt.client != nil {
+				tt.client(client)
+			}
+
+			if code := expectClose(t, client); code != websocket.CloseMessageTooBig {
+				t.Errorf("Expected client close code %d, got %d", websocket.CloseMessageTooBig, code)
+			}
+			select {
+			case code := <-backendClose:
+				if code != websocket.CloseMessageTooBig {
+					t.Errorf("Expected backend close code %d, got %d", websocket.CloseMessageTooBig, code)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Backend was not closed")
+			}
+		})
+	}
+}
+
+func TestConnection_WriteMessage_SizeLimit(t *testing.T) {
+	server := createMockWebSocketServer(t, func(conn *websocket.Conn) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	serverURL := strings.TrimPrefix(server.URL, "http://")
+	host, portStr, _ := net.SplitHostPort(serverURL)
+	port := 0
+	_, _ = fmt.Sscanf(portStr, "%d", &port)
+
+	config := DefaultConfig()
+	config.MaxMessageSize = 10
+	conn, err := NewConnector(config, slog.Default()).Connect(context.Background(), &core.ServiceInstance{ID: "backend", Address: host, Port: port}, "/", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteMessage(&core.WebSocketMessage{Type: core.WebSocketTextMessage, Data: []byte("0123456789")}); err != nil {
+		t.Errorf("Expected message at the limit to be written, got %v", err)
+	}
+	err = conn.WriteMessage(&core.WebSocketMessage{Type: core.WebSocketTextMessage, Data: []byte("0123456789a")})
+	if !errors.Is(err, core.ErrWebSocketMessageTooBig) {
+		t.Errorf("Expected ErrWebSocketMessageTooBig, got %v", err)
+	}
+}
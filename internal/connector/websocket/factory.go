@@ -41,19 +41,21 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 	
 	// Convert to internal config
 	c.config = &Config{
-		HandshakeTimeout:  time.Duration(wsConfig.HandshakeTimeout) * time.Second,
-		ReadTimeout:       time.Duration(wsConfig.ReadTimeout) * time.Second,
-		WriteTimeout:      time.Duration(wsConfig.WriteTimeout) * time.Second,
-		ReadBufferSize:    wsConfig.ReadBufferSize,
-		WriteBufferSize:   wsConfig.WriteBufferSize,
-		MaxMessageSize:    wsConfig.MaxMessageSize,
-		MaxConnections:    wsConfig.MaxConnections,
-		ConnectionTimeout: time.Duration(wsConfig.ConnectionTimeout) * time.Second,
-		PingInterval:      time.Duration(wsConfig.PingInterval) * time.Second,
-		PongTimeout:       time.Duration(wsConfig.PongTimeout) * time.Second,
-		CloseTimeout:      time.Duration(wsConfig.CloseTimeout) * time.Second,
-		EnableCompression: wsConfig.EnableCompression,
-		CompressionLevel:  wsConfig.CompressionLevel,
+		HandshakeTimeout:      time.Duration(wsConfig.HandshakeTimeout) * time.Second,
+		ReadTimeout:           time.Duration(wsConfig.ReadTimeout) * time.Second,
+		WriteTimeout:          time.Duration(wsConfig.WriteTimeout) * time.Second,
+		ReadBufferSize:        wsConfig.ReadBufferSize,
+		WriteBufferSize:       wsConfig.WriteBufferSize,
+		MaxMessageSize:        wsConfig.MaxMessageSize,
+		MaxConnections:        wsConfig.MaxConnections,
+		ConnectionTimeout:     time.Duration(wsConfig.ConnectionTimeout) * time.Second,
+		IdleConnectionTimeout: time.Duration(wsConfig.IdleConnectionTimeout) * time.Second,
+		ReuseConnections:      wsConfig.ReuseConnections,
+		PingInterval:          time.Duration(wsConfig.PingInterval) * time.Second,
+		PongTimeout:           time.Duration(wsConfig.PongTimeout) * time.Second,
+		CloseTimeout:          time.Duration(wsConfig.CloseTimeout) * time.Second,
+		EnableCompression:     wsConfig.EnableCompression,
+		CompressionLevel:      wsConfig.CompressionLevel,
 	}
 	
 	// Set defaults if not configured
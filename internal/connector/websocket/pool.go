@@ -0,0 +1,389 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/pkg/errors"
+	"github.com/gorilla/websocket"
+)
+
+// DefaultIdleConnectionTimeout is how long a reusable backend connection
+// is kept idle unless configured
+const DefaultIdleConnectionTimeout = 90 * time.Second
+
+// perClientHeaders vary between clients sharing a backend connection, so
+// they are left out of the pool key; the backend sees those of the client
+// the connection was dialed for
+var perClientHeaders = map[string]bool{
+	"X-Forwarded-For": true,
+	"X-Real-Ip":       true,
+	"X-Request-Id":    true,
+}
+
+// errReleased is returned by reads of a connection handed back to the pool
+var errReleased = errors.NewError(errors.ErrorTypeInternal, "WebSocket connection released to the pool")
+
+// PoolMetricsRecorder receives backend connection pool metrics per backend
+// host. Active connections are those in use by a client.
+type PoolMetricsRecorder interface {
+	RecordPoolConnections(ctx context.Context, host string, activeDelta, idleDelta int64)
+	RecordWebSocketPoolAcquire(ctx context.Context, host string, reused bool)
+}
+
+// readResult is a message, or the error, read by a pooled connection
+type readResult struct {
+	msg *core.WebSocketMessage
+	err error
+}
+
+// connPool keeps backend connections whose client went away, for later
+// clients of the same instance, URL and handshake headers. Connections
+// idle past the idle timeout are closed.
+type connPool struct {
+	mu          sync.Mutex
+	maxIdle     int // Per instance
+	idleTimeout time.Duration
+	idle        map[string][]*Connection // By pool key, most recently released last
+	idleCount   map[string]int           // By instance ID
+	closed      bool
+	stop        chan struct{}
+	metrics     PoolMetricsRecorder
+	logger      *slog.Logger
+}
+
+func newConnPool(maxIdle int, idleTimeout time.Duration, logger *slog.Logger) *connPool {
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultIdleConnectionTimeout
+	}
+	p := &connPool{
+		maxIdle:     maxIdle,
+		idleTimeout: idleTimeout,
+		idle:        make(map[string][]*Connection),
+		idleCount:   make(map[string]int),
+		stop:        make(chan struct{}),
+		logger:      logger,
+	}
+	go p.evictLoop()
+	return p
+}
+
+// poolKey identifies the connections a client may reuse: those to the same
+// instance and URL, dialed with the same handshake headers, credentials
+// included, but for perClientHeaders
+func poolKey(instanceID, target string, headers http.Header) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		if !perClientHeaders[http.CanonicalHeaderKey(name)] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(instanceID)
+	b.WriteString("\n")
+	b.WriteString(target)
+	for _, name := range names {
+		b.WriteString("\n")
+		b.WriteString(http.CanonicalHeaderKey(name))
+		b.WriteString(": ")
+		b.WriteString(strings.Join(headers[name], ", "))
+	}
+	return b.String()
+}
+
+// get takes the most recently released connection for key, or returns nil
+func (p *connPool) get(key string) *Connection {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	conns := p.idle[key]
+	for len(conns) > 0 {
+		c := conns[len(conns)-1]
+		conns = conns[:len(conns)-1]
+		p.removeLocked(key, conns, c)
+		if !c.broken.Load() && time.Since(c.idleSince) < p.idleTimeout {
+			c.acquire()
+			return c
+		}
+		go c.closeIdle("idle timeout")
+	}
+	return nil
+}
+
+// put keeps a released connection, reporting false when the pool is closed
+// or holds maxIdle connections of the instance already
+func (p *connPool) put(c *Connection) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || p.idleCount[c.instance.ID] >= p.maxIdle {
+		return false
+	}
+	c.idleSince = time.Now()
+	p.idle[c.poolKey] = append(p.idle[c.poolKey], c)
+	p.idleCount[c.instance.ID]++
+	p.recordConnections(c, 0, 1)
+	return true
+}
+
+// remove drops a connection that failed while idle
+func (p *connPool) remove(c *Connection) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	conns := p.idle[c.poolKey]
+	for i, idle := range conns {
+		if idle == c {
+			p.removeLocked(c.poolKey, append(conns[:i:i], conns[i+1:]...), c)
+			go c.Close()
+			return
+		}
+	}
+}
+
+// removeLocked stores the idle connections left for key once c is taken
+func (p *connPool) removeLocked(key string, conns []*Connection, c *Connection) {
+	if len(conns) == 0 {
+		delete(p.idle, key)
+	} else {
+		p.idle[key] = conns
+	}
+	if p.idleCount[c.instance.ID]--; p.idleCount[c.instance.ID] <= 0 {
+		delete(p.idleCount, c.instance.ID)
+	}
+	p.recordConnections(c, 0, -1)
+}
+
+// evictLoop closes connections idle past the idle timeout until the pool
+// is closed
+func (p *connPool) evictLoop() {
+	ticker := time.NewTicker(p.idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.evictExpired()
+		}
+	}
+}
+
+// evictExpired closes the connections idle past the idle timeout, or
+// found broken
+func (p *connPool) evictExpired() {
+	var expired []*Connection
+
+	p.mu.Lock()
+	for key, conns := range p.idle {
+		var kept []*Connection
+		for _, c := range conns {
+			if !c.broken.Load() && time.Since(c.idleSince) < p.idleTimeout {
+				kept = append(kept, c)
+				continue
+			}
+			expired = append(expired, c)
+			if p.idleCount[c.instance.ID]--; p.idleCount[c.instance.ID] <= 0 {
+				delete(p.idleCount, c.instance.ID)
+			}
+			p.recordConnections(c, 0, -1)
+		}
+		if len(kept) == 0 {
+			delete(p.idle, key)
+		} else {
+			p.idle[key] = kept
+		}
+	}
+	p.mu.Unlock()
+
+	for _, c := range expired {
+		c.closeIdle("idle timeout")
+	}
+}
+
+// close closes the idle connections and stops pooling released ones
+func (p *connPool) close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.stop)
+	idle := p.idle
+	p.idle = make(map[string][]*Connection)
+	p.idleCount = make(map[string]int)
+	for _, conns := range idle {
+		for _, c := range conns {
+			p.recordConnections(c, 0, -1)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, conns := range idle {
+		for _, c := range conns {
+			c.closeIdle("gateway shutting down")
+		}
+	}
+}
+
+// activate records a connection handed to a client, reused from the pool
+// or newly dialed
+func (p *connPool) activate(c *Connection, reused bool) {
+	c.active.Store(true)
+	p.recordConnections(c, 1, 0)
+	if p.metrics != nil {
+		p.metrics.RecordWebSocketPoolAcquire(context.Background(), c.host, reused)
+	}
+}
+
+// deactivate records a connection no longer in use by its client
+func (p *connPool) deactivate(c *Connection) {
+	if c.active.Swap(false) {
+		p.recordConnections(c, -1, 0)
+	}
+}
+
+func (p *connPool) recordConnections(c *Connection, activeDelta, idleDelta int64) {
+	if p.metrics != nil {
+		p.metrics.RecordPoolConnections(context.Background(), c.host, activeDelta, idleDelta)
+	}
+}
+
+// pooled prepares a newly dialed connection for reuse: a single goroutine
+// reads the backend for the successive clients, and the pong handler is
+// installed once since it cannot change while reading
+func (c *Connection) pooled(pool *connPool, key, host string) {
+	c.pool = pool
+	c.host = host
+	c.poolKey = key
+	c.reads = make(chan readResult)
+	c.lease = make(chan struct{})
+	c.closed = make(chan struct{})
+
+	c.conn.SetPongHandler(func(string) error {
+		if c.config.PongTimeout > 0 && c.leased() {
+			_ = c.conn.SetReadDeadline(time.Now().Add(c.config.PongTimeout))
+		}
+		return nil
+	})
+	go c.readLoop()
+}
+
+// readLoop reads the backend, handing messages to the client the
+// connection is leased to. Messages arriving while idle are dropped; a
+// read error ends the connection.
+func (c *Connection) readLoop() {
+	for {
+		msg, err := c.readMessage()
+		if err != nil {
+			// Before handing over the error, so the client cannot release
+			// the connection to the pool
+			c.broken.Store(true)
+		}
+		select {
+		case c.reads <- readResult{msg: msg, err: err}:
+		case <-c.currentLease():
+			if err == nil {
+				c.logger.Debug("Dropping message from idle WebSocket backend connection",
+					"instance", c.instance.ID,
+				)
+			}
+		case <-c.closed:
+			return
+		}
+		if err != nil {
+			c.pool.remove(c)
+			return
+		}
+	}
+}
+
+// readLeased returns the next message read by readLoop for the client
+// holding lease, failing once the client released it
+func (c *Connection) readLeased(lease chan struct{}) (*core.WebSocketMessage, error) {
+	select {
+	case r := <-c.reads:
+		return r.msg, r.err
+	case <-lease:
+		return nil, errReleased
+	case <-c.closed:
+		return nil, errors.NewError(errors.ErrorTypeInternal, "WebSocket connection closed")
+	}
+}
+
+// currentLease returns the channel closed when the current client releases
+// the connection. A client keeps the channel it started with, so it cannot
+// read messages meant for the next one.
+func (c *Connection) currentLease() chan struct{} {
+	c.leaseMu.Lock()
+	defer c.leaseMu.Unlock()
+	return c.lease
+}
+
+// leased reports whether a client uses the connection
+func (c *Connection) leased() bool {
+	select {
+	case <-c.currentLease():
+		return false
+	default:
+		return true
+	}
+}
+
+// acquire leases an idle connection to a new client
+func (c *Connection) acquire() {
+	c.leaseMu.Lock()
+	c.lease = make(chan struct{})
+	c.leaseMu.Unlock()
+}
+
+// releaseFrom hands the connection back to the pool once its client went
+// away, reporting false when it cannot be reused and must be closed. The
+// proxy is stopped and the client connection closed first, so that nothing
+// the client sent reaches the backend afterwards.
+func (c *Connection) releaseFrom(stop context.CancelFunc, clientConn core.WebSocketConn, clientDone <-chan struct{}) bool {
+	stop()
+	clientConn.Close()
+	timeout := c.config.CloseTimeout
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	select {
+	case <-clientDone:
+	case <-time.After(timeout):
+		return false
+	}
+
+	if c.broken.Load() {
+		return false
+	}
+	if err := c.conn.SetReadDeadline(time.Time{}); err != nil {
+		return false
+	}
+	c.leaseMu.Lock()
+	close(c.lease)
+	c.leaseMu.Unlock()
+
+	c.pool.deactivate(c)
+	c.inbound, c.outbound = nil, nil
+	return c.pool.put(c)
+}
+
+// closeIdle sends a close frame with reason and closes an idle connection
+func (c *Connection) closeIdle(reason string) {
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseGoingAway, reason)
+	if err := c.conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second)); err != nil {
+		c.logger.Debug("Failed to write close message to backend", "error", err)
+	}
+	c.Close()
+}
@@ -0,0 +1,231 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	"github.com/gorilla/websocket"
+)
+
+// echoBackend starts a backend echoing messages, counting its handshakes
+func echoBackend(t *testing.T) (*core.ServiceInstance, *atomic.Int32) {
+	t.Helper()
+
+	var handshakes atomic.Int32
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handshakes.Add(1)
+		for {
+			msgType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(msgType, data); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	host, portStr, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	port := 0
+	_, _ = fmt.Sscanf(portStr, "%d", &port)
+	return &core.ServiceInstance{ID: "backend", Address: host, Port: port}, &handshakes
+}
+
+// proxySession proxies a client through backendConn, checks a message is
+// echoed and disconnects the client, returning once the proxy ended
+func proxySession(t *testing.T, backendConn *Connection, text string) {
+	t.Helper()
+
+	proxyDone := make(chan error, 1)
+	clientServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		go func() { proxyDone <- backendConn.Proxy(context.Background(), &mockWebSocketConn{conn: conn}) }()
+	}))
+	defer clientServer.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial(strings.Replace(clientServer.URL, "http", "ws", 1), nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to client server: %v", err)
+	}
+	if err := client.WriteMessage(websocket.TextMessage, []byte(text)); err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	_ = client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, data, err := client.ReadMessage(); err != nil || string(data) != text {
+		t.Fatalf("Expected %q echoed, got %q, %v", text, data, err)
+	}
+	client.Close()
+
+	select {
+	case <-proxyDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Proxy did not end after the client disconnected")
+	}
+}
+
+// poolRecorder records pool metrics
+type poolRecorder struct {
+	mu             sync.Mutex
+	active, idle   int64
+	reused, dialed int
+}
+
+func (r *poolRecorder) RecordPoolConnections(ctx context.Context, host string, activeDelta, idleDelta int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active += activeDelta
+	r.idle += idleDelta
+}
+
+func (r *poolRecorder) RecordWebSocketPoolAcquire(ctx context.Context, host string, reused bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if reused {
+		r.reused++
+	} else {
+		r.dialed++
+	}
+}
+
+func poolConfig() *Config {
+	config := DefaultConfig()
+	config.PingInterval = 20 * time.Millisecond
+	config.PongTimeout = time.Second
+	config.ReuseConnections = true
+	return config
+}
+
+// idleConnections returns the idle connections of the pool
+func idleConnections(c *Connector) int {
+	c.pool.mu.Lock()
+	defer c.pool.mu.Unlock()
+	total := 0
+	for _, count := range c.pool.idleCount {
+		total += count
+	}
+	return total
+}
+
+func TestConnector_ReuseConnections(t *testing.T) {
+	instance, handshakes := echoBackend(t)
+	recorder := &poolRecorder{}
+	connector := NewConnector(poolConfig(), slog.Default()).WithMetrics(recorder)
+	defer connector.Close()
+
+	headers := http.Header{"Authorization": {"Bearer a"}, "X-Forwarded-For": {"10.0.0.1"}}
+	first, err := connector.Connect(context.Background(), instance, "/", headers)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	proxySession(t, first, "first")
+	if idleConnections(connector) != 1 {
+		t.Fatalf("Expected the connection to be pooled, got %d idle", idleConnections(connector))
+	}
+
+	// Another client with the same credentials reuses the connection
+	headers = http.Header{"Authorization": {"Bearer a"}, "X-Forwarded-For": {"10.0.0.2"}}
+	second, err := connector.Connect(context.Background(), instance, "/", headers)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if second != first || handshakes.Load() != 1 {
+		t.Fatalf("Expected the pooled connection, got %d handshakes", handshakes.Load())
+	}
+	proxySession(t, second, "second")
+
+	// Other credentials get a connection of their own
+	third, err := connector.Connect(context.Background(), instance, "/", http.Header{"Authorization": {"Bearer b"}})
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if third == first || handshakes.Load() != 2 {
+		t.Errorf("Expected a new connection, got %d handshakes", handshakes.Load())
+	}
+	proxySession(t, third, "third")
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if recorder.dialed != 2 || recorder.reused != 1 || recorder.active != 0 || recorder.idle != 2 {
+		t.Errorf("Unexpected pool metrics %+v", recorder)
+	}
+}
+
+func TestConnector_ReuseConnectionsLimits(t *testing.T) {
+	instance, handshakes := echoBackend(t)
+	config := poolConfig()
+	config.MaxConnections = 1
+	config.IdleConnectionTimeout = 200 * time.Millisecond
+	connector := NewConnector(config, slog.Default())
+	defer connector.Close()
+
+	// Only MaxConnections connections are kept idle
+	first, err := connector.Connect(context.Background(), instance, "/", nil)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	second, err := connector.Connect(context.Background(), instance, "/", nil)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	proxySession(t, first, "first")
+	proxySession(t, second, "second")
+	if idle := idleConnections(connector); idle != 1 {
+		t.Errorf("Expected 1 idle connection, got %d", idle)
+	}
+
+	// Idle connections are closed past the idle timeout
+	deadline := time.Now().Add(2 * time.Second)
+	for idleConnections(connector) != 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if idle := idleConnections(connector); idle != 0 {
+		t.Fatalf("Expected idle connections to be evicted, got %d", idle)
+	}
+	third, err := connector.Connect(context.Background(), instance, "/", nil)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer third.Close()
+	if handshakes.Load() != 3 {
+		t.Errorf("Expected a new connection after eviction, got %d handshakes", handshakes.Load())
+	}
+}
+
+func TestConnector_NoReuseByDefault(t *testing.T) {
+	instance, handshakes := echoBackend(t)
+	config := DefaultConfig()
+	config.PingInterval = 0
+	connector := NewConnector(config, slog.Default())
+
+	for _, text := range []string{"first", "second"} {
+		conn, err := connector.Connect(context.Background(), instance, "/", nil)
+		if err != nil {
+			t.Fatalf("Connect() error = %v", err)
+		}
+		proxySession(t, conn, text)
+	}
+	if handshakes.Load() != 2 {
+		t.Errorf("Expected a connection per client, got %d handshakes", handshakes.Load())
+	}
+}
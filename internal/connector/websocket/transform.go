@@ -0,0 +1,73 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"gateway/internal/core"
+)
+
+// MessageTransform rewrites a message passing through the proxy. Returning
+// a nil message drops it; returning an error drops it and logs the error.
+type MessageTransform func(msg *core.WebSocketMessage) (*core.WebSocketMessage, error)
+
+// NewMessageTransform creates the transform applying rules, with vars
+// expanding the ${name} references of injected field values
+func NewMessageTransform(rules *core.WebSocketMessageRules, vars map[string]string) MessageTransform {
+	drop := make(map[core.WebSocketMessageType]bool, len(rules.DropTypes))
+	for _, t := range rules.DropTypes {
+		drop[t] = true
+	}
+
+	var fields map[string]string
+	if len(rules.InjectFields) > 0 {
+		oldnew := make([]string, 0, 2*len(vars))
+		for name, value := range vars {
+			oldnew = append(oldnew, "${"+name+"}", value)
+		}
+		replacer := strings.NewReplacer(oldnew...)
+
+		fields = make(map[string]string, len(rules.InjectFields))
+		for name, value := range rules.InjectFields {
+			fields[name] = replacer.Replace(value)
+		}
+	}
+
+	return func(msg *core.WebSocketMessage) (*core.WebSocketMessage, error) {
+		if drop[msg.Type] {
+			return nil, nil
+		}
+		if len(fields) == 0 || msg.Type != core.WebSocketTextMessage {
+			return msg, nil
+		}
+		return injectFields(msg, fields)
+	}
+}
+
+// injectFields sets fields on a text message holding a JSON object. Other
+// text messages are passed on unchanged.
+func injectFields(msg *core.WebSocketMessage, fields map[string]string) (*core.WebSocketMessage, error) {
+	data := bytes.TrimSpace(msg.Data)
+	if len(data) == 0 || data[0] != '{' {
+		return msg, nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return msg, nil
+	}
+	for name, value := range fields {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		object[name] = encoded
+	}
+
+	rewritten, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	return &core.WebSocketMessage{Type: msg.Type, Data: rewritten}, nil
+}
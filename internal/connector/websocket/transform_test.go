@@ -0,0 +1,119 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	"github.com/gorilla/websocket"
+)
+
+func TestNewMessageTransform(t *testing.T) {
+	transform := NewMessageTransform(&core.WebSocketMessageRules{
+		DropTypes:    []core.WebSocketMessageType{core.WebSocketBinaryMessage},
+		InjectFields: map[string]string{"user": "${auth.subject}", "source": "gateway"},
+	}, map[string]string{"auth.subject": "alice"})
+
+	tests := []struct {
+		name string
+		msg  *core.WebSocketMessage
+		want string // Empty when dropped
+	}{
+		{name: "json object", msg: &core.WebSocketMessage{Type: core.WebSocketTextMessage, Data: []byte(`{"user":"mallory","n":1}`)}, want: `{"n":1,"source":"gateway","user":"alice"}`},
+		{name: "json array", msg: &core.WebSocketMessage{Type: core.WebSocketTextMessage, Data: []byte(`[1,2]`)}, want: `[1,2]`},
+		{name: "plain text", msg: &core.WebSocketMessage{Type: core.WebSocketTextMessage, Data: []byte(`{not json`)}, want: `{not json`},
+		{name: "binary dropped", msg: &core.WebSocketMessage{Type: core.WebSocketBinaryMessage, Data: []byte(`{}`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := transform(tt.msg)
+			if err != nil {
+				t.Fatalf("Transform failed: %v", err)
+			}
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("Expected message to be dropped, got %q", got.Data)
+				}
+				return
+			}
+			if got == nil || string(got.Data) != tt.want {
+				t.Errorf("Expected %q, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// Binary messages pass through untouched unless dropped
+	passthrough := NewMessageTransform(&core.WebSocketMessageRules{InjectFields: map[string]string{"a": "b"}}, nil)
+	binary := &core.WebSocketMessage{Type: core.WebSocketBinaryMessage, Data: []byte(`{"x":1}`)}
+	if got, _ := passthrough(binary); got != binary {
+		t.Errorf("Expected binary message to pass through untouched, got %v", got)
+	}
+}
+
+func TestConnection_Proxy_Transforms(t *testing.T) {
+	// The backend echoes every message
+	backendServer := createMockWebSocketServer(t, func(conn *websocket.Conn) {
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	})
+	defer backendServer.Close()
+
+	host, portStr, _ := net.SplitHostPort(strings.TrimPrefix(backendServer.URL, "http://"))
+	port := 0
+	_, _ = fmt.Sscanf(portStr, "%d", &port)
+
+	config := DefaultConfig()
+	config.PingInterval = 0
+	backendConn, err := NewConnector(config, slog.Default()).Connect(context.Background(), &core.ServiceInstance{ID: "backend", Address: host, Port: port}, "/", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to backend: %v", err)
+	}
+	backendConn.WithTransforms(
+		NewMessageTransform(&core.WebSocketMessageRules{DropTypes: []core.WebSocketMessageType{core.WebSocketBinaryMessage}}, nil),
+		NewMessageTransform(&core.WebSocketMessageRules{InjectFields: map[string]string{"via": "gateway"}}, nil),
+	)
+
+	clientServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		go backendConn.Proxy(context.Background(), &mockWebSocketConn{conn: conn})
+	}))
+	defer clientServer.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial(strings.Replace(clientServer.URL, "http", "ws", 1), nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to client server: %v", err)
+	}
+	defer client.Close()
+
+	// The dropped binary message must not hold up the messages after it
+	client.WriteMessage(websocket.BinaryMessage, []byte("dropped"))
+	client.WriteMessage(websocket.TextMessage, []byte(`{"n":1}`))
+
+	_ = client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	messageType, data, err := client.ReadMessage()
+	if err != nil {
+		t.Fatalf("Failed to read message: %v", err)
+	}
+	if messageType != websocket.TextMessage || string(data) != `{"n":1,"via":"gateway"}` {
+		t.Errorf("Expected transformed echo, got %d %q", messageType, data)
+	}
+}
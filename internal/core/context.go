@@ -0,0 +1,57 @@
+package core
+
+import "context"
+
+// routeResultKey is the context key for the route chosen for a request
+type routeResultKey struct{}
+
+// WithRouteResult returns a context carrying the route chosen for a request
+func WithRouteResult(ctx context.Context, route *RouteResult) context.Context {
+	return context.WithValue(ctx, routeResultKey{}, route)
+}
+
+// RouteResultFromContext returns the route stored by WithRouteResult, or nil
+func RouteResultFromContext(ctx context.Context) *RouteResult {
+	route, _ := ctx.Value(routeResultKey{}).(*RouteResult)
+	return route
+}
+
+// InformationalFunc relays an interim (1xx) response of a backend to the
+// client ahead of the final response
+type InformationalFunc func(code int, header map[string][]string)
+
+// informationalKey is the context key for relaying interim responses
+type informationalKey struct{}
+
+// WithInformational returns a context carrying the function relaying
+// interim responses to the client. A nil function stops relaying, as for
+// requests whose responses do not go to the client.
+func WithInformational(ctx context.Context, relay InformationalFunc) context.Context {
+	return context.WithValue(ctx, informationalKey{}, relay)
+}
+
+// InformationalFromContext returns the function stored by WithInformational,
+// or nil
+func InformationalFromContext(ctx context.Context) InformationalFunc {
+	relay, _ := ctx.Value(informationalKey{}).(InformationalFunc)
+	return relay
+}
+
+// bodyLimitKey is the context key for lifting the request body size limit
+type bodyLimitKey struct{}
+
+// WithBodyLimitLift returns a context carrying the function lifting the
+// frontend size limit of the request body. The frontend only offers it for
+// gRPC requests, whose streams can carry any number of messages.
+func WithBodyLimitLift(ctx context.Context, lift func()) context.Context {
+	return context.WithValue(ctx, bodyLimitKey{}, lift)
+}
+
+// LiftBodyLimit lifts the request body size limit when the frontend allows
+// it. Connectors call it once a request is routed to a gRPC passthrough
+// route; limits of other requests stay in place.
+func LiftBodyLimit(ctx context.Context) {
+	if lift, _ := ctx.Value(bodyLimitKey{}).(func()); lift != nil {
+		lift()
+	}
+}
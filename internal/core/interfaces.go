@@ -25,6 +25,21 @@ type Response interface {
 	Body() io.ReadCloser
 }
 
+// TrailerResponse is implemented by responses that end with trailers, such
+// as proxied gRPC calls. The body is flushed to the client as it is read,
+// and the trailers are complete once it has been read to the end.
+type TrailerResponse interface {
+	Response
+	Trailers() map[string][]string
+}
+
+// HeaderError is implemented by errors carrying headers for the error
+// response the frontend writes for them
+type HeaderError interface {
+	error
+	ResponseHeaders() map[string][]string
+}
+
 // Handler processes requests
 type Handler func(context.Context, Request) (Response, error)
 
@@ -54,6 +69,13 @@ type ServiceRegistry interface {
 	GetService(name string) ([]ServiceInstance, error)
 }
 
+// ReadyRegistry is a ServiceRegistry that discovers services asynchronously.
+// Ready is closed after its first successful discovery cycle.
+type ReadyRegistry interface {
+	ServiceRegistry
+	Ready() <-chan struct{}
+}
+
 // RouteResult contains the result of routing
 type RouteResult struct {
 	Instance    *ServiceInstance
@@ -89,6 +111,75 @@ type RouteRule struct {
 	Protocol        string                 // Protocol hint: http, grpc, websocket, sse
 	Metadata        map[string]interface{} // Additional protocol-specific configuration
 	Balancer        LoadBalancer           // Route-specific load balancer instance
+	TrafficSplit    *TrafficSplit          // Spread requests over several services instead of ServiceName
+	BackendTimeouts *BackendTimeouts       // Overrides of the backend connector's timeouts
+	SlowStart       *SlowStartConfig       // Ramp up instances that have just become healthy
+	// Only send requests to instances matching every predicate
+	InstanceSelector InstanceSelector
+	// Rewrite or drop messages of proxied WebSocket connections
+	WebSocketTransform *WebSocketTransform
+	// Accept-Encoding sent to the backend, whatever the client accepts
+	BackendCompression *BackendCompression
+	// Requests taking longer are logged and counted as over budget
+	LatencyBudget time.Duration
+}
+
+// SlowStartConfig ramps up the share of traffic of an instance over Window
+// after it becomes healthy, starting from MinWeight (0-1) of its weight
+type SlowStartConfig struct {
+	Window    time.Duration
+	MinWeight float64
+}
+
+// BackendTimeouts bound the phases of a backend request separately from
+// the route timeout. Zero values leave the connector's defaults in place.
+type BackendTimeouts struct {
+	Connect        time.Duration // Establishing the backend connection
+	ResponseHeader time.Duration // Waiting for response headers once the request is sent
+	Idle           time.Duration // Waiting for more of the response body
+}
+
+// BackendCompression sets the Accept-Encoding sent to the backend of a
+// route, independently of the encodings the client accepts
+type BackendCompression struct {
+	AcceptEncoding string // Replaces the client's header, e.g. identity or gzip
+	Strip          bool   // Removes the client's header instead
+}
+
+// HeaderLimits bound the header fields of a request or response. A field
+// is counted once per value and its size is that of its "Name: value"
+// line. Zero values disable a limit.
+type HeaderLimits struct {
+	MaxBytes int // Total size of the header fields
+	MaxCount int // Number of header fields
+}
+
+// Exceeded reports whether header is over one of the limits
+func (l HeaderLimits) Exceeded(header map[string][]string) bool {
+	if l.MaxBytes <= 0 && l.MaxCount <= 0 {
+		return false
+	}
+	count, size := 0, 0
+	for name, values := range header {
+		for _, value := range values {
+			count++
+			size += len(name) + len(value) + len(": \r\n")
+		}
+	}
+	return (l.MaxCount > 0 && count > l.MaxCount) || (l.MaxBytes > 0 && size > l.MaxBytes)
+}
+
+// TrafficSplit spreads the requests of a route over several services by
+// weight, e.g. to send a small share to a canary
+type TrafficSplit struct {
+	Services     []WeightedService
+	StickyHeader string // Requests with the same value of this header go to the same service
+}
+
+// WeightedService is a service and its share of a traffic split
+type WeightedService struct {
+	ServiceName string
+	Weight      int
 }
 
 // LoadBalanceStrategy defines load balancing algorithm
@@ -105,6 +196,19 @@ const (
 	LoadBalanceConsistentHash    LoadBalanceStrategy = "consistent_hash"
 )
 
+// BuiltinLoadBalanceStrategies lists the strategies the router implements;
+// others are registered through the balancer package
+var BuiltinLoadBalanceStrategies = []LoadBalanceStrategy{
+	LoadBalanceRoundRobin,
+	LoadBalanceStickySession,
+	LoadBalanceWeightedRoundRobin,
+	LoadBalanceWeightedRandom,
+	LoadBalanceLeastConnections,
+	LoadBalanceResponseTime,
+	LoadBalanceAdaptive,
+	LoadBalanceConsistentHash,
+}
+
 // SessionSource defines where to extract session ID from
 type SessionSource string
 
@@ -123,4 +227,9 @@ type SessionAffinityConfig struct {
 	HeaderName string        `yaml:"headerName,omitempty"`
 	QueryParam string        `yaml:"queryParam,omitempty"`
 	MaxEntries int           `yaml:"maxEntries,omitempty"` // Maximum number of sessions to track
+	Storage    string        `yaml:"storage,omitempty"`    // memory (default) or redis
 }
+
+// SessionStorageRedis keeps session mappings in Redis, shared between
+// gateway replicas
+const SessionStorageRedis = "redis"
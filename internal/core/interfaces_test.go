@@ -386,4 +386,28 @@ func TestMiddleware(t *testing.T) {
 	if resp.Headers()["X-Middleware"][0] != "applied" {
 		t.Error("Expected middleware to add header")
 	}
-}
\ No newline at end of file
+}
+
+func TestHeaderLimits(t *testing.T) {
+	// "A: bb\r\n" and "A: cc\r\n" are 7 bytes, "Bc: dd\r\n" is 8
+	header := map[string][]string{"A": {"bb", "cc"}, "Bc": {"dd"}}
+
+	tests := []struct {
+		name   string
+		limits core.HeaderLimits
+		want   bool
+	}{
+		{"no limits", core.HeaderLimits{}, false},
+		{"at count", core.HeaderLimits{MaxCount: 3}, false},
+		{"over count", core.HeaderLimits{MaxCount: 2}, true},
+		{"at bytes", core.HeaderLimits{MaxBytes: 22}, false},
+		{"over bytes", core.HeaderLimits{MaxBytes: 21}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.Exceeded(header); got != tt.want {
+				t.Errorf("Exceeded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
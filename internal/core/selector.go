@@ -0,0 +1,178 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TagSelectorKey is the selector key matching instance tags, kept in the
+// "tags" metadata entry, rather than a metadata value
+const TagSelectorKey = "tag"
+
+// InstanceSelector limits the instances of a route to those matching every
+// predicate
+type InstanceSelector []InstancePredicate
+
+// InstancePredicate compares a metadata value of an instance, or its tags
+// for the tag key. Ordering operators compare numbers and dotted versions
+// part by part.
+type InstancePredicate struct {
+	Key   string
+	Op    string // =, !=, >, >=, <, <=
+	Value string
+}
+
+// ParseInstanceSelector parses predicates of the form key=value,
+// key!=value, key>value, key>=value, key<value and key<=value. The tag key
+// only supports = and !=, and ordering operators need a number or version.
+func ParseInstanceSelector(exprs []string) (InstanceSelector, error) {
+	selector := make(InstanceSelector, 0, len(exprs))
+	for _, expr := range exprs {
+		predicate, err := parsePredicate(expr)
+		if err != nil {
+			return nil, err
+		}
+		selector = append(selector, predicate)
+	}
+	return selector, nil
+}
+
+func parsePredicate(expr string) (InstancePredicate, error) {
+	i := strings.IndexAny(expr, "!=<>")
+	if i < 0 {
+		return InstancePredicate{}, fmt.Errorf("selector %q: missing operator", expr)
+	}
+	op := expr[i : i+1]
+	if i+1 < len(expr) && expr[i+1] == '=' && op != "=" {
+		op += "="
+	}
+	if op == "!" {
+		return InstancePredicate{}, fmt.Errorf("selector %q: unknown operator", expr)
+	}
+
+	p := InstancePredicate{
+		Key:   strings.TrimSpace(expr[:i]),
+		Op:    op,
+		Value: strings.TrimSpace(expr[i+len(op):]),
+	}
+	if p.Key == "" {
+		return p, fmt.Errorf("selector %q: missing key", expr)
+	}
+	switch op {
+	case "=", "!=":
+	default:
+		if p.Key == TagSelectorKey {
+			return p, fmt.Errorf("selector %q: tags only support = and !=", expr)
+		}
+		if _, ok := parseVersion(p.Value); !ok {
+			return p, fmt.Errorf("selector %q: %s needs a number or version", expr, op)
+		}
+	}
+	return p, nil
+}
+
+// Matches reports whether instance matches every predicate
+func (s InstanceSelector) Matches(instance ServiceInstance) bool {
+	for _, p := range s {
+		if !p.Matches(instance) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether instance matches the predicate. Instances
+// without the key only match !=; ordering operators only match numbers
+// and versions.
+func (p InstancePredicate) Matches(instance ServiceInstance) bool {
+	if p.Key == TagSelectorKey {
+		return hasTag(instance.Metadata["tags"], p.Value) == (p.Op == "=")
+	}
+
+	raw, ok := instance.Metadata[p.Key]
+	if !ok || raw == nil {
+		return p.Op == "!="
+	}
+	value := fmt.Sprint(raw)
+	switch p.Op {
+	case "=":
+		return value == p.Value
+	case "!=":
+		return value != p.Value
+	}
+
+	have, ok := parseVersion(value)
+	if !ok {
+		return false
+	}
+	want, _ := parseVersion(p.Value)
+	cmp := compareVersions(have, want)
+	switch p.Op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp <= 0
+	}
+}
+
+// hasTag reports whether tags, a list or comma-separated string, holds tag
+func hasTag(tags any, tag string) bool {
+	var list []string
+	switch t := tags.(type) {
+	case []string:
+		list = t
+	case []any:
+		for _, v := range t {
+			list = append(list, fmt.Sprint(v))
+		}
+	case string:
+		list = strings.Split(t, ",")
+	}
+	for _, v := range list {
+		if strings.TrimSpace(v) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVersion parses a number or a dotted version such as 2.1.0, with an
+// optional v prefix
+func parseVersion(s string) ([]uint64, bool) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	version := make([]uint64, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		version[i] = n
+	}
+	return version, true
+}
+
+// compareVersions compares versions part by part, missing parts counting
+// as 0, so 2.10 is above 2.9 and 2 equals 2.0
+func compareVersions(a, b []uint64) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y uint64
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
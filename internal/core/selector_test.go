@@ -0,0 +1,74 @@
+package core
+
+import "testing"
+
+func TestParseInstanceSelector(t *testing.T) {
+	selector, err := ParseInstanceSelector([]string{"region = eu", "version>=2", "tag!=canary", "weight<10"})
+	if err != nil {
+		t.Fatalf("ParseInstanceSelector failed: %v", err)
+	}
+	want := InstanceSelector{
+		{Key: "region", Op: "=", Value: "eu"},
+		{Key: "version", Op: ">=", Value: "2"},
+		{Key: "tag", Op: "!=", Value: "canary"},
+		{Key: "weight", Op: "<", Value: "10"},
+	}
+	for i := range want {
+		if selector[i] != want[i] {
+			t.Errorf("predicate %d: expected %+v, got %+v", i, want[i], selector[i])
+		}
+	}
+
+	for _, expr := range []string{"region", "=eu", "version>two", "tag>=2", "region!eu"} {
+		if _, err := ParseInstanceSelector([]string{expr}); err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+}
+
+func TestInstanceSelector_Matches(t *testing.T) {
+	instance := ServiceInstance{Metadata: map[string]any{
+		"region":  "eu",
+		"version": "2.10.1",
+		"weight":  5,
+		"tags":    []string{"beta", "arm"},
+	}}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"region=eu", true},
+		{"region!=eu", false},
+		{"zone=a", false},
+		{"zone!=a", true},
+		{"version>=2", true},
+		{"version>2.9", true},
+		{"version<2.10", false},
+		{"version<=2.10.1", true},
+		{"weight>4", true},
+		{"region>1", false},
+		{"zone>1", false},
+		{"tag=beta", true},
+		{"tag=canary", false},
+		{"tag!=canary", true},
+	}
+	for _, tt := range tests {
+		selector, err := ParseInstanceSelector([]string{tt.expr})
+		if err != nil {
+			t.Fatalf("ParseInstanceSelector(%q) failed: %v", tt.expr, err)
+		}
+		if got := selector.Matches(instance); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.expr, tt.want, got)
+		}
+	}
+
+	// Tags may be a comma-separated label value
+	labelled := ServiceInstance{Metadata: map[string]any{"tags": "beta, arm"}}
+	if !(InstanceSelector{{Key: "tag", Op: "=", Value: "arm"}}).Matches(labelled) {
+		t.Error("Expected tags from a comma-separated value")
+	}
+	if !(InstanceSelector{}).Matches(ServiceInstance{}) {
+		t.Error("Expected an empty selector to match every instance")
+	}
+}
@@ -19,7 +19,8 @@ type SSEWriter interface {
 	WriteEvent(event *SSEEvent) error
 	// WriteComment writes a comment (keepalive)
 	WriteComment(comment string) error
-	// Flush flushes any buffered data
+	// Flush flushes any buffered data; writers may buffer events until it
+	// is called
 	Flush() error
 	// Close closes the writer
 	Close() error
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"errors"
 	"io"
 	"time"
 )
@@ -28,6 +29,25 @@ type WebSocketMessage struct {
 	Data []byte
 }
 
+// WebSocketTransform rewrites the messages of a proxied WebSocket connection
+type WebSocketTransform struct {
+	Inbound  *WebSocketMessageRules // Client to backend
+	Outbound *WebSocketMessageRules // Backend to client
+}
+
+// WebSocketMessageRules apply to the messages in one direction. Binary
+// messages are only ever dropped, never rewritten.
+type WebSocketMessageRules struct {
+	DropTypes []WebSocketMessageType // Message types not forwarded
+	// Fields set on text messages holding a JSON object. Values may
+	// reference ${auth.subject}, ${route.id} and ${request.id}.
+	InjectFields map[string]string
+}
+
+// ErrWebSocketMessageTooBig is returned when writing a data message larger
+// than the maximum message size of the connection
+var ErrWebSocketMessageTooBig = errors.New("websocket message exceeds maximum size")
+
 // WebSocketConn represents a WebSocket connection
 type WebSocketConn interface {
 	// ReadMessage reads a message from the connection
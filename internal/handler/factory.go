@@ -103,8 +103,12 @@ func (c *Component) CreateMultiProtocolHandler() core.Handler {
 			}
 		}
 
-		// For now, we only support HTTP through the standard connector
-		// gRPC support would require protocol detection from request headers
+		// Native gRPC calls are proxied by the gRPC connector; everything
+		// else, including HTTP/JSON transcoded to gRPC, goes through the
+		// standard connector
+		if c.grpcConnector != nil && grpcConnector.IsPassthrough(route.Rule) {
+			return c.grpcConnector.Forward(ctx, req, route)
+		}
 		return c.httpConnector.Forward(ctx, req, route)
 	}
 }
@@ -180,20 +184,14 @@ func ApplyMiddleware(handler core.Handler, logger *slog.Logger, middlewares ...c
 	return handler
 }
 
-// routeContextKey is the context key for storing route results
-type routeContextKey struct{}
-
 // setRouteInContext stores the route result in the context
 func setRouteInContext(ctx context.Context, route *core.RouteResult) context.Context {
-	return context.WithValue(ctx, routeContextKey{}, route)
+	return core.WithRouteResult(ctx, route)
 }
 
 // getRouteFromContext retrieves the route result from the context
 func getRouteFromContext(ctx context.Context) *core.RouteResult {
-	if route, ok := ctx.Value(routeContextKey{}).(*core.RouteResult); ok {
-		return route
-	}
-	return nil
+	return core.RouteResultFromContext(ctx)
 }
 
 // Ensure Component implements factory.Component
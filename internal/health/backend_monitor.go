@@ -6,12 +6,15 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"gateway/internal/config"
 	"gateway/internal/core"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
@@ -21,6 +24,7 @@ type BackendMonitor struct {
 	config          *config.Health
 	healthCheckers  map[string]BackendChecker
 	updateCallbacks []func(service string, instance *core.ServiceInstance, healthy bool)
+	metrics         MetricsRecorder
 	logger          *slog.Logger
 	
 	mu       sync.RWMutex
@@ -31,13 +35,25 @@ type BackendMonitor struct {
 	wg     sync.WaitGroup
 }
 
+// Default consecutive check results required to change instance health
+const (
+	DefaultHealthyThreshold   = 2
+	DefaultUnhealthyThreshold = 3
+)
+
 // InstanceHealth holds health status for an instance
 type InstanceHealth struct {
-	Instance         *core.ServiceInstance
-	Healthy          bool
-	LastCheck        time.Time
-	ConsecutiveFails int
-	LastError        error
+	Instance             *core.ServiceInstance
+	Healthy              bool
+	LastCheck            time.Time
+	ConsecutiveFails     int
+	ConsecutiveSuccesses int
+	LastError            error
+}
+
+// MetricsRecorder receives instance counts after each round of checks
+type MetricsRecorder interface {
+	RecordServiceInstances(ctx context.Context, service string, total, healthy int64)
 }
 
 // BackendChecker checks health of a specific backend type
@@ -63,6 +79,14 @@ func (m *BackendMonitor) RegisterChecker(checkType string, checker BackendChecke
 	m.healthCheckers[checkType] = checker
 }
 
+// WithMetrics reports total and healthy instance counts per service
+func (m *BackendMonitor) WithMetrics(metrics MetricsRecorder) *BackendMonitor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = metrics
+	return m
+}
+
 // RegisterUpdateCallback registers a callback for health status updates
 func (m *BackendMonitor) RegisterUpdateCallback(callback func(service string, instance *core.ServiceInstance, healthy bool)) {
 	m.mu.Lock()
@@ -84,15 +108,21 @@ func (m *BackendMonitor) Start(ctx context.Context) error {
 	// Register default checkers
 	m.registerDefaultCheckers()
 	
-	// Start monitoring configured checks
-	for name, check := range m.config.Checks {
-		if checkType, ok := check.Config["type"]; ok {
-			m.wg.Add(1)
-			go m.monitorService(name, checkType, check)
+	// Start monitoring configured backend services
+	for service, check := range m.config.Backends {
+		checker, err := m.backendChecker(check)
+		if err != nil {
+			m.logger.Error("Skipping backend health check",
+				"service", service,
+				"error", err,
+			)
+			continue
 		}
+		m.wg.Add(1)
+		go m.monitorService(service, checker, check)
 	}
 	
-	m.logger.Info("Backend monitor started", "checks", len(m.config.Checks))
+	m.logger.Info("Backend monitor started", "services", len(m.config.Backends))
 	return nil
 }
 
@@ -144,8 +174,40 @@ func (m *BackendMonitor) GetServiceHealth(service string) map[string]*InstanceHe
 	return nil
 }
 
+// backendChecker returns the checker for a backend check configuration.
+// Built-in types read their options from the check's Config map: "port"
+// probes another port than the instance's and, for grpc, "service" names
+// the service passed to the health Check RPC.
+func (m *BackendMonitor) backendChecker(check config.Check) (BackendChecker, error) {
+	var port int
+	if value := check.Config["port"]; value != "" {
+		p, err := strconv.Atoi(value)
+		if err != nil || p <= 0 || p > 65535 {
+			return nil, fmt.Errorf("invalid probe port: %s", value)
+		}
+		port = p
+	}
+	
+	switch check.Type {
+	case "http":
+		return &HTTPHealthChecker{Path: check.Path, Port: port}, nil
+	case "tcp":
+		return &TCPHealthChecker{Port: port}, nil
+	case "grpc":
+		return &GRPCHealthChecker{Service: check.Config["service"], Port: port}, nil
+	}
+	
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	checker, ok := m.healthCheckers[check.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown check type: %s", check.Type)
+	}
+	return checker, nil
+}
+
 // monitorService monitors health of a service
-func (m *BackendMonitor) monitorService(name, checkType string, check config.Check) {
+func (m *BackendMonitor) monitorService(name string, checker BackendChecker, check config.Check) {
 	defer m.wg.Done()
 	
 	interval := time.Duration(check.Interval) * time.Second
@@ -162,20 +224,20 @@ func (m *BackendMonitor) monitorService(name, checkType string, check config.Che
 	defer ticker.Stop()
 	
 	// Initial check
-	m.checkService(name, checkType, timeout)
+	m.checkService(name, checker, check, timeout)
 	
 	for {
 		select {
 		case <-m.ctx.Done():
 			return
 		case <-ticker.C:
-			m.checkService(name, checkType, timeout)
+			m.checkService(name, checker, check, timeout)
 		}
 	}
 }
 
 // checkService performs health checks on all instances of a service
-func (m *BackendMonitor) checkService(serviceName, checkType string, timeout time.Duration) {
+func (m *BackendMonitor) checkService(serviceName string, checker BackendChecker, check config.Check, timeout time.Duration) {
 	// Get instances from registry
 	instances, err := m.registry.GetService(serviceName)
 	if err != nil {
@@ -193,41 +255,56 @@ func (m *BackendMonitor) checkService(serviceName, checkType string, timeout tim
 		wg.Add(1)
 		go func(inst core.ServiceInstance) {
 			defer wg.Done()
-			m.checkInstance(serviceName, &inst, checkType, timeout)
+			m.checkInstance(serviceName, &inst, checker, check, timeout)
 		}(instance)
 	}
 	
 	wg.Wait()
+	
+	m.recordServiceInstances(serviceName, instances)
 }
 
-// checkInstance checks health of a single instance
-func (m *BackendMonitor) checkInstance(serviceName string, instance *core.ServiceInstance, checkType string, timeout time.Duration) {
-	ctx, cancel := context.WithTimeout(m.ctx, timeout)
-	defer cancel()
-	
-	// Get the checker
+// recordServiceInstances reports how many of the instances are healthy
+func (m *BackendMonitor) recordServiceInstances(serviceName string, instances []core.ServiceInstance) {
 	m.mu.RLock()
-	checker, ok := m.healthCheckers[checkType]
+	metrics := m.metrics
+	var healthy int64
+	for _, instance := range instances {
+		if status, ok := m.statuses[serviceName][instance.ID]; ok && status.Healthy {
+			healthy++
+		}
+	}
 	m.mu.RUnlock()
 	
-	if !ok {
-		m.logger.Error("Unknown check type",
-			"service", serviceName,
-			"instance", instance.ID,
-			"checkType", checkType,
-		)
-		return
+	if metrics != nil {
+		metrics.RecordServiceInstances(m.ctx, serviceName, int64(len(instances)), healthy)
 	}
+}
+
+// checkInstance checks health of a single instance
+func (m *BackendMonitor) checkInstance(serviceName string, instance *core.ServiceInstance, checker BackendChecker, check config.Check, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(m.ctx, timeout)
+	defer cancel()
 	
 	// Perform the check
 	err := checker.Check(ctx, instance)
 	
 	// Update status
-	m.updateInstanceHealth(serviceName, instance, err)
+	m.updateInstanceHealth(serviceName, instance, check, err)
 }
 
-// updateInstanceHealth updates the health status of an instance
-func (m *BackendMonitor) updateInstanceHealth(serviceName string, instance *core.ServiceInstance, checkErr error) {
+// updateInstanceHealth updates the health status of an instance once the
+// configured number of consecutive checks agree
+func (m *BackendMonitor) updateInstanceHealth(serviceName string, instance *core.ServiceInstance, check config.Check, checkErr error) {
+	healthyThreshold := check.HealthyThreshold
+	if healthyThreshold <= 0 {
+		healthyThreshold = DefaultHealthyThreshold
+	}
+	unhealthyThreshold := check.UnhealthyThreshold
+	if unhealthyThreshold <= 0 {
+		unhealthyThreshold = DefaultUnhealthyThreshold
+	}
+	
 	m.mu.Lock()
 	
 	// Initialize maps if needed
@@ -235,11 +312,12 @@ func (m *BackendMonitor) updateInstanceHealth(serviceName string, instance *core
 		m.statuses[serviceName] = make(map[string]*InstanceHealth)
 	}
 	
-	// Get or create health status
+	// Get or create health status, starting from the registry's view
 	health, exists := m.statuses[serviceName][instance.ID]
 	if !exists {
 		health = &InstanceHealth{
 			Instance: instance,
+			Healthy:  instance.Healthy,
 		}
 		m.statuses[serviceName][instance.ID] = health
 	}
@@ -252,17 +330,26 @@ func (m *BackendMonitor) updateInstanceHealth(serviceName string, instance *core
 	
 	if checkErr != nil {
 		health.ConsecutiveFails++
-		health.Healthy = false
+		health.ConsecutiveSuccesses = 0
 		m.logger.Debug("Health check failed",
 			"service", serviceName,
 			"instance", instance.ID,
 			"consecutiveFails", health.ConsecutiveFails,
 			"error", checkErr,
 		)
+		if previouslyHealthy && health.ConsecutiveFails >= unhealthyThreshold {
+			health.Healthy = false
+			m.logger.Warn("Instance became unhealthy",
+				"service", serviceName,
+				"instance", instance.ID,
+				"error", checkErr,
+			)
+		}
 	} else {
 		health.ConsecutiveFails = 0
-		health.Healthy = true
-		if !previouslyHealthy {
+		health.ConsecutiveSuccesses++
+		if !previouslyHealthy && health.ConsecutiveSuccesses >= healthyThreshold {
+			health.Healthy = true
 			m.logger.Info("Instance became healthy",
 				"service", serviceName,
 				"instance", instance.ID,
@@ -273,13 +360,14 @@ func (m *BackendMonitor) updateInstanceHealth(serviceName string, instance *core
 	// Get callbacks while holding the lock
 	callbacks := make([]func(string, *core.ServiceInstance, bool), len(m.updateCallbacks))
 	copy(callbacks, m.updateCallbacks)
+	healthy := health.Healthy
 	
 	m.mu.Unlock()
 	
 	// Notify callbacks if health changed
-	if health.Healthy != previouslyHealthy {
+	if healthy != previouslyHealthy {
 		for _, callback := range callbacks {
-			callback(serviceName, instance, health.Healthy)
+			callback(serviceName, instance, healthy)
 		}
 	}
 }
@@ -297,7 +385,10 @@ func (m *BackendMonitor) registerDefaultCheckers() {
 }
 
 // HTTPHealthChecker checks HTTP endpoints
-type HTTPHealthChecker struct{}
+type HTTPHealthChecker struct {
+	Path string // Health endpoint path, defaults to /health
+	Port int    // Probe port, defaults to the instance port
+}
 
 func (h *HTTPHealthChecker) Check(ctx context.Context, instance *core.ServiceInstance) error {
 	// Build health check URL
@@ -306,7 +397,14 @@ func (h *HTTPHealthChecker) Check(ctx context.Context, instance *core.ServiceIns
 		scheme = "http"
 	}
 	
-	url := fmt.Sprintf("%s://%s:%d/health", scheme, instance.Address, instance.Port)
+	path := h.Path
+	if path == "" {
+		path = "/health"
+	} else if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	
+	url := fmt.Sprintf("%s://%s%s", scheme, probeAddress(instance, h.Port), path)
 	
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -335,13 +433,15 @@ func (h *HTTPHealthChecker) Check(ctx context.Context, instance *core.ServiceIns
 	return nil
 }
 
-// TCPHealthChecker checks TCP connectivity
-type TCPHealthChecker struct{}
+// TCPHealthChecker checks that a TCP connection can be established
+type TCPHealthChecker struct {
+	Port int // Probe port, defaults to the instance port
+}
 
 func (t *TCPHealthChecker) Check(ctx context.Context, instance *core.ServiceInstance) error {
-	addr := fmt.Sprintf("%s:%d", instance.Address, instance.Port)
+	addr := probeAddress(instance, t.Port)
 	
-	// Try to connect
+	// Try to connect within the check timeout
 	dialer := &net.Dialer{}
 	conn, err := dialer.DialContext(ctx, "tcp", addr)
 	if err != nil {
@@ -356,19 +456,21 @@ func (t *TCPHealthChecker) Check(ctx context.Context, instance *core.ServiceInst
 	return nil
 }
 
-// GRPCHealthChecker checks gRPC health
-type GRPCHealthChecker struct{}
+// GRPCHealthChecker checks health using the gRPC health checking protocol
+type GRPCHealthChecker struct {
+	Service string // Service name to check, empty checks the whole server
+	Port    int    // Probe port, defaults to the instance port
+}
 
 func (g *GRPCHealthChecker) Check(ctx context.Context, instance *core.ServiceInstance) error {
-	addr := fmt.Sprintf("%s:%d", instance.Address, instance.Port)
+	addr := probeAddress(instance, g.Port)
 	
-	// Create gRPC connection
-	conn, err := grpc.DialContext(ctx, addr,
-		grpc.WithInsecure(),
-		grpc.WithBlock(),
+	// Create gRPC client; the connection is established by the first call
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
 	)
 	if err != nil {
-		return fmt.Errorf("grpc dial failed: %w", err)
+		return fmt.Errorf("grpc client creation failed: %w", err)
 	}
 	defer conn.Close()
 	
@@ -376,7 +478,9 @@ func (g *GRPCHealthChecker) Check(ctx context.Context, instance *core.ServiceIns
 	client := grpc_health_v1.NewHealthClient(conn)
 	
 	// Check health
-	resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{
+		Service: g.Service,
+	})
 	if err != nil {
 		return fmt.Errorf("health check failed: %w", err)
 	}
@@ -386,4 +490,12 @@ func (g *GRPCHealthChecker) Check(ctx context.Context, instance *core.ServiceIns
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+
+// probeAddress returns the address to probe, optionally on another port
+func probeAddress(instance *core.ServiceInstance, port int) string {
+	if port == 0 {
+		port = instance.Port
+	}
+	return net.JoinHostPort(instance.Address, strconv.Itoa(port))
+}
@@ -0,0 +1,211 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// instanceRegistry returns a fixed set of instances
+type instanceRegistry struct {
+	instances []core.ServiceInstance
+}
+
+func (r *instanceRegistry) GetService(name string) ([]core.ServiceInstance, error) {
+	return r.instances, nil
+}
+
+// instanceMetrics records the last reported instance counts
+type instanceMetrics struct {
+	mu             sync.Mutex
+	total, healthy int64
+}
+
+func (m *instanceMetrics) RecordServiceInstances(ctx context.Context, service string, total, healthy int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.total, m.healthy = total, healthy
+}
+
+func TestBackendMonitor_Thresholds(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ready" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	registry := &instanceRegistry{instances: []core.ServiceInstance{
+		{ID: "users-1", Name: "users", Address: host, Port: port, Healthy: true},
+	}}
+
+	check := config.Check{Type: "http", Path: "/ready", HealthyThreshold: 2, UnhealthyThreshold: 3}
+	metrics := &instanceMetrics{}
+	monitor := NewBackendMonitor(registry, &config.Health{
+		Backends: map[string]config.Check{"users": check},
+	}, slog.Default()).WithMetrics(metrics)
+	monitor.ctx = context.Background()
+
+	var updates []bool
+	monitor.RegisterUpdateCallback(func(service string, instance *core.ServiceInstance, healthy bool) {
+		updates = append(updates, healthy)
+	})
+
+	checker, err := monitor.backendChecker(check)
+	if err != nil {
+		t.Fatalf("backendChecker failed: %v", err)
+	}
+	healthy := func() bool {
+		h, _ := monitor.GetHealth("users", "users-1")
+		return h.Healthy
+	}
+
+	// Failures below the unhealthy threshold keep the instance healthy
+	monitor.checkService("users", checker, check, time.Second)
+	monitor.checkService("users", checker, check, time.Second)
+	if !healthy() || len(updates) != 0 {
+		t.Fatalf("Expected instance to stay healthy after 2 failures, updates %v", updates)
+	}
+
+	monitor.checkService("users", checker, check, time.Second)
+	if healthy() || len(updates) != 1 || updates[0] {
+		t.Fatalf("Expected instance unhealthy after 3 failures, updates %v", updates)
+	}
+	if metrics.total != 1 || metrics.healthy != 0 {
+		t.Errorf("Expected 1 total, 0 healthy instances, got %d, %d", metrics.total, metrics.healthy)
+	}
+
+	// Recovery needs the healthy threshold of consecutive successes
+	status.Store(http.StatusOK)
+	monitor.checkService("users", checker, check, time.Second)
+	if healthy() {
+		t.Fatal("Expected instance to stay unhealthy after 1 success")
+	}
+	monitor.checkService("users", checker, check, time.Second)
+	if !healthy() || len(updates) != 2 || !updates[1] {
+		t.Fatalf("Expected instance healthy after 2 successes, updates %v", updates)
+	}
+	if metrics.healthy != 1 {
+		t.Errorf("Expected 1 healthy instance, got %d", metrics.healthy)
+	}
+}
+
+func TestBackendMonitor_UnknownCheckType(t *testing.T) {
+	monitor := NewBackendMonitor(&instanceRegistry{}, &config.Health{}, slog.Default())
+	monitor.registerDefaultCheckers()
+
+	if _, err := monitor.backendChecker(config.Check{Type: "exec"}); err == nil {
+		t.Error("Expected error for unknown check type")
+	}
+	if _, err := monitor.backendChecker(config.Check{Type: "tcp"}); err != nil {
+		t.Errorf("Expected tcp checker, got %v", err)
+	}
+}
+
+// testInstance returns an instance for the listener address
+func testInstance(t *testing.T, addr net.Addr) *core.ServiceInstance {
+	t.Helper()
+	host, portStr, _ := net.SplitHostPort(addr.String())
+	port, _ := strconv.Atoi(portStr)
+	return &core.ServiceInstance{ID: "instance-1", Address: host, Port: port}
+}
+
+func TestTCPHealthChecker(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	instance := testInstance(t, listener.Addr())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	checker := &TCPHealthChecker{}
+	if err := checker.Check(ctx, instance); err != nil {
+		t.Errorf("Expected open port to pass, got %v", err)
+	}
+
+	listener.Close()
+	if err := checker.Check(ctx, instance); err == nil {
+		t.Error("Expected closed port to fail")
+	}
+}
+
+func TestGRPCHealthChecker(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	healthServer := grpchealth.NewServer()
+	healthServer.SetServingStatus("users", grpc_health_v1.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("orders", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	// Probe through a different registered port than the instance's
+	instance := testInstance(t, listener.Addr())
+	probePort := instance.Port
+	instance.Port = 1
+
+	monitor := NewBackendMonitor(&instanceRegistry{}, &config.Health{}, slog.Default())
+	tests := []struct {
+		service string
+		wantErr bool
+	}{
+		{"", false},
+		{"users", false},
+		{"orders", true},
+		{"unknown", true},
+	}
+
+	for _, tt := range tests {
+		t.Run("service "+tt.service, func(t *testing.T) {
+			checker, err := monitor.backendChecker(config.Check{
+				Type:   "grpc",
+				Config: map[string]string{"service": tt.service, "port": strconv.Itoa(probePort)},
+			})
+			if err != nil {
+				t.Fatalf("backendChecker failed: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			err = checker.Check(ctx, instance)
+			if tt.wantErr && err == nil {
+				t.Error("Expected check to fail")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected check to pass, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBackendMonitor_InvalidProbePort(t *testing.T) {
+	monitor := NewBackendMonitor(&instanceRegistry{}, &config.Health{}, slog.Default())
+	if _, err := monitor.backendChecker(config.Check{Type: "tcp", Config: map[string]string{"port": "http"}}); err == nil {
+		t.Error("Expected error for invalid probe port")
+	}
+}
@@ -3,7 +3,10 @@ package health
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"gateway/internal/core"
@@ -23,6 +26,57 @@ func RegistryCheck(registry core.ServiceRegistry) Check {
 	}
 }
 
+// DiscoveryCheck creates a health check failing until ready is closed by the
+// registry's first discovery, or until timeout has passed since its creation.
+// A zero timeout waits indefinitely.
+func DiscoveryCheck(ready <-chan struct{}, timeout time.Duration, logger *slog.Logger) Check {
+	deadline := time.Now().Add(timeout)
+	var warned sync.Once
+	return func(ctx context.Context) error {
+		select {
+		case <-ready:
+			return nil
+		default:
+		}
+		if timeout > 0 && time.Now().After(deadline) {
+			warned.Do(func() {
+				logger.Warn("Service discovery has not completed, reporting ready anyway",
+					"timeout", timeout,
+				)
+			})
+			return nil
+		}
+		return fmt.Errorf("waiting for first service discovery")
+	}
+}
+
+// BackendsCheck creates a health check failing while any of services has
+// no healthy instance
+func BackendsCheck(registry core.ServiceRegistry, services []string) Check {
+	return func(ctx context.Context) error {
+		var down []string
+		for _, service := range services {
+			instances, err := registry.GetService(service)
+			if err != nil || !anyHealthy(instances) {
+				down = append(down, service)
+			}
+		}
+		if len(down) > 0 {
+			return fmt.Errorf("no healthy instances: %s", strings.Join(down, ", "))
+		}
+		return nil
+	}
+}
+
+func anyHealthy(instances []core.ServiceInstance) bool {
+	for _, instance := range instances {
+		if instance.Healthy {
+			return true
+		}
+	}
+	return false
+}
+
 // HTTPCheck creates a health check for an HTTP endpoint
 func HTTPCheck(url string, timeout time.Duration) Check {
 	return func(ctx context.Context) error {
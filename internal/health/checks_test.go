@@ -3,6 +3,7 @@ package health
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -179,3 +180,49 @@ func TestTCPCheck(t *testing.T) {
 		t.Error("Expected error for invalid address")
 	}
 }
+
+// instancesRegistry returns fixed instances per service
+type instancesRegistry map[string][]core.ServiceInstance
+
+func (r instancesRegistry) GetService(name string) ([]core.ServiceInstance, error) {
+	instances, ok := r[name]
+	if !ok {
+		return nil, errors.New("service not found")
+	}
+	return instances, nil
+}
+
+func TestDiscoveryCheck(t *testing.T) {
+	ready := make(chan struct{})
+	check := DiscoveryCheck(ready, time.Hour, slog.Default())
+	if err := check(context.Background()); err == nil {
+		t.Error("Expected discovery check to fail before the first discovery")
+	}
+	close(ready)
+	if err := check(context.Background()); err != nil {
+		t.Errorf("Expected discovery check to pass, got %v", err)
+	}
+
+	// Readiness is reported once the timeout has passed
+	check = DiscoveryCheck(make(chan struct{}), time.Millisecond, slog.Default())
+	time.Sleep(5 * time.Millisecond)
+	if err := check(context.Background()); err != nil {
+		t.Errorf("Expected discovery check to pass after the timeout, got %v", err)
+	}
+}
+
+func TestBackendsCheck(t *testing.T) {
+	registry := instancesRegistry{
+		"users":  {{ID: "u1", Healthy: false}, {ID: "u2", Healthy: true}},
+		"orders": {{ID: "o1", Healthy: false}},
+	}
+
+	if err := BackendsCheck(registry, []string{"users"})(context.Background()); err != nil {
+		t.Errorf("Expected users to be healthy, got %v", err)
+	}
+
+	err := BackendsCheck(registry, []string{"users", "orders", "billing"})(context.Background())
+	if err == nil || err.Error() != "no healthy instances: orders, billing" {
+		t.Errorf("Expected orders and billing down, got %v", err)
+	}
+}
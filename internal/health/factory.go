@@ -19,6 +19,10 @@ import (
 // ComponentName is the name used to register this component
 const ComponentName = "health"
 
+// DefaultDiscoveryTimeout is how long the ready path waits for the
+// registry's first discovery
+const DefaultDiscoveryTimeout = 60 * time.Second
+
 // Component implements factory.Component for health checking
 type Component struct {
 	config          *config.Health
@@ -138,6 +142,15 @@ func (c *Component) createHealthChecker() (*Checker, error) {
 	// Register registry check
 	if c.registry != nil {
 		checker.RegisterCheck("registry", RegistryCheck(c.registry))
+
+		// Hold readiness until the first discovery cycle completes
+		if registry, ok := c.registry.(core.ReadyRegistry); ok {
+			timeout := DefaultDiscoveryTimeout
+			if c.config != nil && c.config.DiscoveryTimeout > 0 {
+				timeout = time.Duration(c.config.DiscoveryTimeout) * time.Second
+			}
+			checker.RegisterCheck("discovery", DiscoveryCheck(registry.Ready(), timeout, c.logger))
+		}
 	}
 
 	// Register configured checks
@@ -152,7 +165,10 @@ func (c *Component) createHealthChecker() (*Checker, error) {
 				)
 				continue
 			}
-			checker.RegisterCheck(name, check)
+			checker.RegisterCheckWithOptions(name, check, CheckOptions{
+				Interval: time.Duration(checkCfg.Interval) * time.Second,
+				Critical: !checkCfg.Optional,
+			})
 		}
 	}
 
@@ -207,12 +223,8 @@ func (c *Component) createCheck(cfg config.Check) (Check, error) {
 		if addr == "" {
 			return nil, fmt.Errorf("grpc check requires 'address' in config")
 		}
-		service := cfg.Config["service"]
-		// Default to standard health service if not specified
-		if service == "" {
-			service = "grpc.health.v1.Health"
-		}
-		return grpcCheck(addr, service, timeout), nil
+		// An empty service name checks the overall server health
+		return grpcCheck(addr, cfg.Config["service"], timeout), nil
 
 	case "exec":
 		commandKey := cfg.Config["command"]
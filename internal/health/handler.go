@@ -20,30 +20,55 @@ const (
 // Check represents a health check function
 type Check func(ctx context.Context) error
 
+// CheckOptions configure how a registered check runs
+type CheckOptions struct {
+	// Interval is how long a result is reused before the check runs
+	// again; 0 runs the check on every probe
+	Interval time.Duration
+	// Critical checks make the gateway unready when they fail
+	Critical bool
+}
+
 // Checker manages health checks
 type Checker struct {
-	checks map[string]Check
+	checks map[string]*registeredCheck
 	mu     sync.RWMutex
 }
 
+// registeredCheck is a check with its options and last result
+type registeredCheck struct {
+	check Check
+	opts  CheckOptions
+
+	mu        sync.Mutex // held while the check runs, so probes share a run
+	result    CheckResult
+	checkedAt time.Time
+}
+
 // NewChecker creates a new health checker
 func NewChecker() *Checker {
 	return &Checker{
-		checks: make(map[string]Check),
+		checks: make(map[string]*registeredCheck),
 	}
 }
 
-// RegisterCheck registers a health check
+// RegisterCheck registers a critical health check run on every probe
 func (c *Checker) RegisterCheck(name string, check Check) {
+	c.RegisterCheckWithOptions(name, check, CheckOptions{Critical: true})
+}
+
+// RegisterCheckWithOptions registers a health check
+func (c *Checker) RegisterCheckWithOptions(name string, check Check, opts CheckOptions) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.checks[name] = check
+	c.checks[name] = &registeredCheck{check: check, opts: opts}
 }
 
-// CheckHealth runs all health checks
+// CheckHealth runs all health checks, reusing results younger than their
+// interval
 func (c *Checker) CheckHealth(ctx context.Context) map[string]CheckResult {
 	c.mu.RLock()
-	checks := make(map[string]Check, len(c.checks))
+	checks := make(map[string]*registeredCheck, len(c.checks))
 	for name, check := range c.checks {
 		checks[name] = check
 	}
@@ -55,22 +80,10 @@ func (c *Checker) CheckHealth(ctx context.Context) map[string]CheckResult {
 
 	for name, check := range checks {
 		wg.Add(1)
-		go func(name string, check Check) {
+		go func(name string, check *registeredCheck) {
 			defer wg.Done()
 
-			start := time.Now()
-			err := check(ctx)
-			duration := time.Since(start)
-
-			result := CheckResult{
-				Status:   StatusHealthy,
-				Duration: duration,
-			}
-
-			if err != nil {
-				result.Status = StatusUnhealthy
-				result.Error = err.Error()
-			}
+			result := check.run(ctx)
 
 			resultsMu.Lock()
 			results[name] = result
@@ -82,11 +95,48 @@ func (c *Checker) CheckHealth(ctx context.Context) map[string]CheckResult {
 	return results
 }
 
+// run returns the cached result while it is younger than the interval, and
+// runs the check otherwise
+func (rc *registeredCheck) run(ctx context.Context) CheckResult {
+	if rc.opts.Interval <= 0 {
+		return rc.execute(ctx)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if !rc.checkedAt.IsZero() && time.Since(rc.checkedAt) < rc.opts.Interval {
+		return rc.result
+	}
+	result := rc.execute(ctx)
+	// A probe that gave up says nothing about the dependency
+	if ctx.Err() == nil {
+		rc.result, rc.checkedAt = result, time.Now()
+	}
+	return result
+}
+
+func (rc *registeredCheck) execute(ctx context.Context) CheckResult {
+	start := time.Now()
+	err := rc.check(ctx)
+
+	result := CheckResult{
+		Status:   StatusHealthy,
+		Duration: time.Since(start),
+		Critical: rc.opts.Critical,
+	}
+	if err != nil {
+		result.Status = StatusUnhealthy
+		result.Error = err.Error()
+	}
+	return result
+}
+
 // CheckResult represents the result of a health check
 type CheckResult struct {
 	Status   Status        `json:"status"`
 	Error    string        `json:"error,omitempty"`
 	Duration time.Duration `json:"duration"`
+	Critical bool          `json:"critical"`
 }
 
 // HealthResponse represents the overall health response
@@ -114,6 +164,11 @@ func NewHandler(checker *Checker, version, serviceID string) *Handler {
 	}
 }
 
+// Checker returns the checker whose results the handler reports
+func (h *Handler) Checker() *Checker {
+	return h.checker
+}
+
 // Health handles the /health endpoint
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
@@ -121,13 +176,13 @@ func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 
 	results := h.checker.CheckHealth(ctx)
 
-	// Determine overall status
+	// Determine overall status, failing non-critical checks only degrade it
 	status := StatusHealthy
 	for _, result := range results {
-		if result.Status == StatusUnhealthy {
+		if result.Status == StatusUnhealthy && result.Critical {
 			status = StatusUnhealthy
 			break
-		} else if result.Status == StatusDegraded {
+		} else if result.Status != StatusHealthy {
 			status = StatusDegraded
 		}
 	}
@@ -151,20 +206,17 @@ func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(response)
 }
 
-// Ready handles the /ready endpoint
+// Ready handles the /ready endpoint. The gateway is ready while all
+// critical checks pass; the response lists every check.
 func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
-	// For readiness, we only check critical dependencies
-	// This is a simplified version - you might want to check specific services
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
 	results := h.checker.CheckHealth(ctx)
 
-	// Check if all critical checks pass
 	ready := true
 	for _, result := range results {
-		// You might want to filter only critical checks here
-		if result.Status == StatusUnhealthy {
+		if result.Critical && result.Status == StatusUnhealthy {
 			ready = false
 			break
 		}
@@ -173,6 +225,7 @@ func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
 	response := map[string]interface{}{
 		"ready":     ready,
 		"timestamp": time.Now(),
+		"checks":    results,
 	}
 
 	statusCode := http.StatusOK
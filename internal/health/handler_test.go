@@ -275,3 +275,80 @@ func TestChecker_ConcurrentCheckHealth(t *testing.T) {
 		t.Errorf("Expected %d successful checks, got %d", concurrency, successCount)
 	}
 }
+
+func TestChecker_IntervalCachesResults(t *testing.T) {
+	checker := NewChecker()
+
+	var runs int
+	checker.RegisterCheckWithOptions("redis", func(ctx context.Context) error {
+		runs++
+		return nil
+	}, CheckOptions{Interval: time.Hour, Critical: true})
+
+	for i := 0; i < 3; i++ {
+		checker.CheckHealth(context.Background())
+	}
+	if runs != 1 {
+		t.Errorf("Expected the check to run once within its interval, ran %d times", runs)
+	}
+
+	// A cancelled probe is not cached
+	checker.RegisterCheckWithOptions("jwks", func(ctx context.Context) error {
+		return ctx.Err()
+	}, CheckOptions{Interval: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	checker.CheckHealth(ctx)
+	if result := checker.CheckHealth(context.Background())["jwks"]; result.Status != StatusHealthy {
+		t.Errorf("Expected the cancelled result to be discarded, got %+v", result)
+	}
+}
+
+func TestHandler_ReadyCriticalChecks(t *testing.T) {
+	checker := NewChecker()
+	checker.RegisterCheck("backends", func(ctx context.Context) error {
+		return nil
+	})
+	checker.RegisterCheckWithOptions("jwks", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}, CheckOptions{})
+
+	handler := NewHandler(checker, "1.0.0", "test-service")
+
+	// A failing non-critical check is reported but keeps the gateway ready
+	w := httptest.NewRecorder()
+	handler.Ready(w, httptest.NewRequest("GET", "/ready", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	var readyResp struct {
+		Ready  bool                   `json:"ready"`
+		Checks map[string]CheckResult `json:"checks"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&readyResp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if jwks := readyResp.Checks["jwks"]; jwks.Status != StatusUnhealthy || jwks.Critical || jwks.Error != "connection refused" {
+		t.Errorf("Unexpected jwks result %+v", jwks)
+	}
+
+	w = httptest.NewRecorder()
+	handler.Health(w, httptest.NewRequest("GET", "/health", nil))
+	var healthResp HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&healthResp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusOK || healthResp.Status != StatusDegraded {
+		t.Errorf("Expected degraded health with status 200, got %s with %d", healthResp.Status, w.Code)
+	}
+
+	// A failing critical check makes it unready
+	checker.RegisterCheck("backends", func(ctx context.Context) error {
+		return errors.New("no healthy instances: users")
+	})
+	w = httptest.NewRecorder()
+	handler.Ready(w, httptest.NewRequest("GET", "/ready", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+}
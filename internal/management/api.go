@@ -5,12 +5,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	wsadapter "gateway/internal/adapter/websocket"
 	"gateway/internal/config"
 	"gateway/internal/core"
+	"gateway/internal/middleware/circuitbreaker"
+	"gateway/internal/middleware/maintenance"
+	"gateway/internal/middleware/versioning"
+	"gateway/pkg/errors"
 )
 
 // API provides runtime management endpoints
@@ -19,14 +27,23 @@ type API struct {
 	logger       *slog.Logger
 	server       *http.Server
 	mux          *http.ServeMux
+	handler      http.Handler
 	mu           sync.RWMutex
+	listen       func(network, address string) (net.Listener, error)
 	
 	// References to managed components
 	registry     core.ServiceRegistry
 	router       interface{ GetRoutes() []core.RouteRule }
 	healthChecker interface{ GetHealthStatus() map[string]bool }
-	circuitBreaker interface{ GetStatus() map[string]string }
+	circuitBreaker CircuitBreakerManager
 	rateLimiter   interface{ GetStats() map[string]interface{} }
+	drainer       InstanceDrainer
+	maintenance   MaintenanceSwitch
+	websockets    WebSocketConnections
+	gatewayConfig *config.Config
+	explainer     RouteExplainer
+	versions      VersionResolver
+	middlewares   []string
 	
 	// Stats
 	startTime    time.Time
@@ -49,6 +66,7 @@ func NewAPI(cfg *config.Management, logger *slog.Logger) *API {
 		logger:    logger.With("component", "management-api"),
 		mux:       http.NewServeMux(),
 		startTime: time.Now(),
+		listen:    net.Listen,
 	}
 
 	// Setup routes
@@ -57,6 +75,20 @@ func NewAPI(cfg *config.Management, logger *slog.Logger) *API {
 	return api
 }
 
+// ServiceLister is implemented by registries that can enumerate their
+// services
+type ServiceLister interface {
+	ListServices() ([]*core.Service, error)
+}
+
+// InstanceDrainer takes instances out of load balancing independent of
+// their health
+type InstanceDrainer interface {
+	Drain(id string) error
+	Undrain(id string) error
+	IsDrained(id string) bool
+}
+
 // SetRegistry sets the service registry reference
 func (api *API) SetRegistry(registry core.ServiceRegistry) {
 	api.mu.Lock()
@@ -64,6 +96,11 @@ func (api *API) SetRegistry(registry core.ServiceRegistry) {
 	api.registry = registry
 }
 
+// SetListenFunc sets how the API binds its listener
+func (api *API) SetListenFunc(listen func(network, address string) (net.Listener, error)) {
+	api.listen = listen
+}
+
 // SetRouter sets the router reference
 func (api *API) SetRouter(router interface{ GetRoutes() []core.RouteRule }) {
 	api.mu.Lock()
@@ -78,13 +115,84 @@ func (api *API) SetHealthChecker(hc interface{ GetHealthStatus() map[string]bool
 	api.healthChecker = hc
 }
 
+// CircuitBreakerManager lists and resets circuit breakers
+type CircuitBreakerManager interface {
+	Status() []circuitbreaker.BreakerStatus
+	ResetService(service string) bool
+}
+
 // SetCircuitBreaker sets the circuit breaker reference
-func (api *API) SetCircuitBreaker(cb interface{ GetStatus() map[string]string }) {
+func (api *API) SetCircuitBreaker(cb CircuitBreakerManager) {
 	api.mu.Lock()
 	defer api.mu.Unlock()
 	api.circuitBreaker = cb
 }
 
+// SetInstanceDrainer sets the instance drainer reference
+func (api *API) SetInstanceDrainer(drainer InstanceDrainer) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.drainer = drainer
+}
+
+// MaintenanceSwitch turns maintenance mode on and off
+type MaintenanceSwitch interface {
+	Enable(state maintenance.State)
+	Disable()
+	State() *maintenance.State
+}
+
+// SetMaintenance sets the maintenance switch reference
+func (api *API) SetMaintenance(sw MaintenanceSwitch) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.maintenance = sw
+}
+
+// WebSocketConnections lists, closes and messages open WebSocket
+// connections
+type WebSocketConnections interface {
+	Connections() []wsadapter.ConnectionInfo
+	CloseConnection(id, reason string) error
+	Broadcast(msg *core.WebSocketMessage, route string) wsadapter.BroadcastResult
+}
+
+// SetWebSocketConnections sets the WebSocket connection registry reference
+func (api *API) SetWebSocketConnections(conns WebSocketConnections) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.websockets = conns
+}
+
+// SetConfig sets the effective configuration the gateway runs with
+func (api *API) SetConfig(cfg *config.Config) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.gatewayConfig = cfg
+}
+
+// RouteExplainer reports the rule and service that would serve a request
+// without routing it
+type RouteExplainer interface {
+	Explain(ctx context.Context, req core.Request) (*core.RouteRule, string, error)
+}
+
+// VersionResolver resolves the API version of requests
+type VersionResolver interface {
+	Resolve(r *http.Request) (versioning.Resolution, error)
+}
+
+// SetExplainer sets what the explain endpoint routes requests with.
+// versions is nil without API versioning; middlewares names the request
+// middleware in the order requests pass them.
+func (api *API) SetExplainer(router RouteExplainer, versions VersionResolver, middlewares []string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.explainer = router
+	api.versions = versions
+	api.middlewares = middlewares
+}
+
 // SetRateLimiter sets the rate limiter reference
 func (api *API) SetRateLimiter(rl interface{ GetStats() map[string]interface{} }) {
 	api.mu.Lock()
@@ -100,9 +208,9 @@ func (api *API) setupRoutes() {
 	}
 
 	// Apply auth middleware if configured
-	handler := http.Handler(api.mux)
+	api.handler = api.mux
 	if api.config.Auth != nil {
-		handler = api.authMiddleware(handler)
+		api.handler = api.authMiddleware(api.mux)
 	}
 
 	// Health endpoints
@@ -118,13 +226,27 @@ func (api *API) setupRoutes() {
 	api.mux.HandleFunc(basePath+"/services", api.handleServices)
 	api.mux.HandleFunc(basePath+"/services/", api.handleServiceDetail)
 	
+	// Instance management
+	api.mux.HandleFunc(basePath+"/instances/{id}/drain", api.handleInstanceDrain)
+	api.mux.HandleFunc(basePath+"/instances/{id}/undrain", api.handleInstanceUndrain)
+	
+	// Maintenance mode
+	api.mux.HandleFunc(basePath+"/maintenance", api.handleMaintenance)
+
+	// WebSocket connections
+	api.mux.HandleFunc(basePath+"/websocket/connections", api.handleWebSocketConnections)
+	api.mux.HandleFunc(basePath+"/websocket/connections/{id}/close", api.handleWebSocketClose)
+	api.mux.HandleFunc(basePath+"/websocket/broadcast", api.handleWebSocketBroadcast)
+	
 	// Route management
 	api.mux.HandleFunc(basePath+"/routes", api.handleRoutes)
 	api.mux.HandleFunc(basePath+"/routes/reload", api.handleRouteReload)
+	api.mux.HandleFunc(basePath+"/explain", api.handleExplain)
 	
 	// Circuit breaker management
+	api.mux.HandleFunc(basePath+"/circuitbreakers", api.handleCircuitBreakers)
+	api.mux.HandleFunc(basePath+"/circuitbreakers/{service}/reset", api.handleCircuitBreakerReset)
 	api.mux.HandleFunc(basePath+"/circuit-breakers", api.handleCircuitBreakers)
-	api.mux.HandleFunc(basePath+"/circuit-breakers/reset", api.handleCircuitBreakerReset)
 	
 	// Rate limiter management
 	api.mux.HandleFunc(basePath+"/rate-limits", api.handleRateLimits)
@@ -143,12 +265,17 @@ func (api *API) Start(ctx context.Context) error {
 	addr := fmt.Sprintf("%s:%d", api.config.Host, api.config.Port)
 	api.server = &http.Server{
 		Addr:    addr,
-		Handler: api.mux,
+		Handler: api.handler,
+	}
+
+	listener, err := api.listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to bind management API to %s: %w", addr, err)
 	}
 
 	go func() {
 		api.logger.Info("Starting management API", "address", addr)
-		if err := api.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := api.server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			api.logger.Error("Management API error", "error", err)
 		}
 	}()
@@ -232,12 +359,112 @@ type StatsResponse struct {
 }
 
 type ServiceResponse struct {
-	Name      string                 `json:"name"`
-	Instances []core.ServiceInstance `json:"instances"`
+	Name      string             `json:"name"`
+	Instances []InstanceResponse `json:"instances"`
+}
+
+type InstanceResponse struct {
+	ID       string         `json:"id"`
+	Address  string         `json:"address"`
+	Port     int            `json:"port"`
+	Scheme   string         `json:"scheme,omitempty"`
+	Healthy  bool           `json:"healthy"`
+	Drained  bool           `json:"drained"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+type InstanceDrainResponse struct {
+	ID      string `json:"id"`
+	Drained bool   `json:"drained"`
+}
+
+// MaintenanceRequest switches maintenance mode on or off. The state fields
+// are only used when enabling.
+type MaintenanceRequest struct {
+	Enabled bool `json:"enabled"`
+	maintenance.State
+}
+
+type MaintenanceResponse struct {
+	Enabled bool               `json:"enabled"`
+	State   *maintenance.State `json:"state,omitempty"`
+}
+
+type WebSocketConnectionsResponse struct {
+	Connections []wsadapter.ConnectionInfo `json:"connections"`
+	Count       int                        `json:"count"`
+}
+
+// WebSocketCloseRequest optionally gives the reason sent in the close frame
+type WebSocketCloseRequest struct {
+	Reason string `json:"reason"`
+}
+
+type WebSocketCloseResponse struct {
+	ID     string `json:"id"`
+	Closed bool   `json:"closed"`
+}
+
+// WebSocketBroadcastRequest sends a text message to every open connection,
+// or to those of a route
+type WebSocketBroadcastRequest struct {
+	Message string `json:"message"`
+	Route   string `json:"route,omitempty"`
+}
+
+type CircuitBreakerResponse struct {
+	Breakers []circuitbreaker.BreakerStatus `json:"breakers"`
+}
+
+type CircuitBreakerResetResponse struct {
+	Service string `json:"service"`
+	State   string `json:"state"`
 }
 
 type RouteResponse struct {
-	Routes []core.RouteRule `json:"routes"`
+	Routes []RouteInfo `json:"routes"`
+}
+
+type RouteInfo struct {
+	ID              string                 `json:"id"`
+	Path            string                 `json:"path"`
+	Methods         []string               `json:"methods,omitempty"`
+	ServiceName     string                 `json:"serviceName"`
+	LoadBalance     string                 `json:"loadBalance,omitempty"`
+	Timeout         string                 `json:"timeout,omitempty"`
+	Protocol        string                 `json:"protocol,omitempty"`
+	SessionAffinity *SessionAffinityInfo   `json:"sessionAffinity,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ExplainRequest is a synthetic request to explain the routing of
+type ExplainRequest struct {
+	Method  string            `json:"method"` // Default: GET
+	Path    string            `json:"path"`   // May carry a query string
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// ExplainResponse is how a request would be routed. TrafficSplit holds the
+// weights by service of a split route for requests whose service is picked
+// at random.
+type ExplainResponse struct {
+	Method       string                 `json:"method"`
+	Path         string                 `json:"path"` // Path routed, after versioning
+	Version      *versioning.Resolution `json:"version,omitempty"`
+	Route        *RouteInfo             `json:"route,omitempty"`
+	Service      string                 `json:"service,omitempty"`
+	TrafficSplit map[string]int         `json:"trafficSplit,omitempty"`
+	Middlewares  []string               `json:"middlewares"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+type SessionAffinityInfo struct {
+	Enabled    bool   `json:"enabled"`
+	TTL        string `json:"ttl,omitempty"`
+	Source     string `json:"source,omitempty"`
+	CookieName string `json:"cookieName,omitempty"`
+	HeaderName string `json:"headerName,omitempty"`
+	QueryParam string `json:"queryParam,omitempty"`
 }
 
 // Handler implementations
@@ -324,9 +551,10 @@ func (api *API) handleStats(w http.ResponseWriter, r *http.Request) {
 	}
 
 	serviceCount := 0
-	if api.registry != nil {
-		// Count services (implementation depends on registry interface)
-		serviceCount = 10 // Placeholder
+	if lister, ok := api.registry.(ServiceLister); ok {
+		if services, err := lister.ListServices(); err == nil {
+			serviceCount = len(services)
+		}
 	}
 
 	routeCount := 0
@@ -353,13 +581,52 @@ func (api *API) handleServices(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if api.registry == nil {
+	api.mu.RLock()
+	registry := api.registry
+	drainer := api.drainer
+	api.mu.RUnlock()
+
+	if registry == nil {
 		api.writeError(w, http.StatusServiceUnavailable, "Registry not available")
 		return
 	}
 
-	// TODO: Implement service listing
-	services := []ServiceResponse{}
+	lister, ok := registry.(ServiceLister)
+	if !ok {
+		api.writeError(w, http.StatusNotImplemented, "Registry does not support listing services")
+		return
+	}
+
+	list, err := lister.ListServices()
+	if err != nil {
+		api.logger.Error("Failed to list services", "error", err)
+		api.writeError(w, http.StatusInternalServerError, "Failed to list services")
+		return
+	}
+
+	services := make([]ServiceResponse, 0, len(list))
+	for _, svc := range list {
+		resp := ServiceResponse{
+			Name:      svc.Name,
+			Instances: make([]InstanceResponse, 0, len(svc.Instances)),
+		}
+		for _, inst := range svc.Instances {
+			resp.Instances = append(resp.Instances, InstanceResponse{
+				ID:       inst.ID,
+				Address:  inst.Address,
+				Port:     inst.Port,
+				Scheme:   inst.Scheme,
+				Healthy:  inst.Healthy,
+				Drained:  drainer != nil && drainer.IsDrained(inst.ID),
+				Metadata: inst.Metadata,
+			})
+		}
+		services = append(services, resp)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+
 	api.writeJSON(w, http.StatusOK, services)
 }
 
@@ -368,24 +635,314 @@ func (api *API) handleServiceDetail(w http.ResponseWriter, r *http.Request) {
 	api.writeError(w, http.StatusNotImplemented, "Not implemented")
 }
 
+func (api *API) handleInstanceDrain(w http.ResponseWriter, r *http.Request) {
+	api.setInstanceDrained(w, r, true)
+}
+
+func (api *API) handleInstanceUndrain(w http.ResponseWriter, r *http.Request) {
+	api.setInstanceDrained(w, r, false)
+}
+
+// setInstanceDrained drains or undrains the instance named in the path
+func (api *API) setInstanceDrained(w http.ResponseWriter, r *http.Request, drained bool) {
+	if r.Method != http.MethodPost {
+		api.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	api.mu.RLock()
+	drainer := api.drainer
+	api.mu.RUnlock()
+
+	if drainer == nil {
+		api.writeError(w, http.StatusServiceUnavailable, "Instance drainer not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	change := drainer.Undrain
+	if drained {
+		change = drainer.Drain
+	}
+	if err := change(id); err != nil {
+		message := err.Error()
+		var gwErr *errors.Error
+		if errors.As(err, &gwErr) {
+			message = gwErr.Message
+		}
+		api.writeError(w, errors.HTTPStatus(err), message)
+		return
+	}
+
+	api.logger.Info("Instance drain state changed", "instance", id, "drained", drained)
+	api.writeJSON(w, http.StatusOK, InstanceDrainResponse{ID: id, Drained: drained})
+}
+
+func (api *API) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		api.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	api.mu.RLock()
+	sw := api.maintenance
+	api.mu.RUnlock()
+
+	if sw == nil {
+		api.writeError(w, http.StatusServiceUnavailable, "Maintenance mode not available")
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		var req MaintenanceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			api.writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.StatusCode != 0 && (req.StatusCode < 200 || req.StatusCode > 599) {
+			api.writeError(w, http.StatusBadRequest, "status must be between 200 and 599")
+			return
+		}
+		if req.RetryAfter < 0 {
+			api.writeError(w, http.StatusBadRequest, "retryAfter must not be negative")
+			return
+		}
+
+		if req.Enabled {
+			sw.Enable(req.State)
+		} else {
+			sw.Disable()
+		}
+		api.logger.Info("Maintenance mode changed", "enabled", req.Enabled, "routes", req.Routes)
+	}
+
+	state := sw.State()
+	api.writeJSON(w, http.StatusOK, MaintenanceResponse{Enabled: state != nil, State: state})
+}
+
+// webSockets returns the WebSocket connection registry, answering 503
+// when the WebSocket adapter is not running
+func (api *API) webSockets(w http.ResponseWriter) WebSocketConnections {
+	api.mu.RLock()
+	conns := api.websockets
+	api.mu.RUnlock()
+
+	if conns == nil {
+		api.writeError(w, http.StatusServiceUnavailable, "WebSocket connections not available")
+	}
+	return conns
+}
+
+func (api *API) handleWebSocketConnections(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	conns := api.webSockets(w)
+	if conns == nil {
+		return
+	}
+
+	list := conns.Connections()
+	api.writeJSON(w, http.StatusOK, WebSocketConnectionsResponse{Connections: list, Count: len(list)})
+}
+
+func (api *API) handleWebSocketClose(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		api.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	conns := api.webSockets(w)
+	if conns == nil {
+		return
+	}
+
+	// The body is optional
+	var req WebSocketCloseRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			api.writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	if req.Reason == "" {
+		req.Reason = "closed by administrator"
+	}
+
+	id := r.PathValue("id")
+	if err := conns.CloseConnection(id, req.Reason); err != nil {
+		message := err.Error()
+		var gwErr *errors.Error
+		if errors.As(err, &gwErr) {
+			message = gwErr.Message
+		}
+		api.writeError(w, errors.HTTPStatus(err), message)
+		return
+	}
+
+	api.logger.Info("WebSocket connection closed", "connection", id, "reason", req.Reason)
+	api.writeJSON(w, http.StatusOK, WebSocketCloseResponse{ID: id, Closed: true})
+}
+
+func (api *API) handleWebSocketBroadcast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		api.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	conns := api.webSockets(w)
+	if conns == nil {
+		return
+	}
+
+	var req WebSocketBroadcastRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Message == "" {
+		api.writeError(w, http.StatusBadRequest, "message is required")
+		return
+	}
+
+	result := conns.Broadcast(&core.WebSocketMessage{
+		Type: core.WebSocketTextMessage,
+		Data: []byte(req.Message),
+	}, req.Route)
+	api.logger.Info("WebSocket message broadcast", "route", req.Route, "sent", result.Sent, "failed", result.Failed)
+	api.writeJSON(w, http.StatusOK, result)
+}
+
 func (api *API) handleRoutes(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		api.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	if api.router == nil {
+	api.mu.RLock()
+	router := api.router
+	api.mu.RUnlock()
+
+	if router == nil {
 		api.writeError(w, http.StatusServiceUnavailable, "Router not available")
 		return
 	}
 
+	rules := router.GetRoutes()
 	resp := RouteResponse{
-		Routes: api.router.GetRoutes(),
+		Routes: make([]RouteInfo, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		resp.Routes = append(resp.Routes, routeInfo(rule))
 	}
 
 	api.writeJSON(w, http.StatusOK, resp)
 }
 
+// routeInfo converts a route rule into its JSON representation
+func routeInfo(rule core.RouteRule) RouteInfo {
+	info := RouteInfo{
+		ID:          rule.ID,
+		Path:        rule.Path,
+		Methods:     rule.Methods,
+		ServiceName: rule.ServiceName,
+		LoadBalance: string(rule.LoadBalance),
+		Protocol:    rule.Protocol,
+		Metadata:    rule.Metadata,
+	}
+	if rule.Timeout > 0 {
+		info.Timeout = rule.Timeout.String()
+	}
+	if sa := rule.SessionAffinity; sa != nil {
+		info.SessionAffinity = &SessionAffinityInfo{
+			Enabled:    sa.Enabled,
+			Source:     string(sa.Source),
+			CookieName: sa.CookieName,
+			HeaderName: sa.HeaderName,
+			QueryParam: sa.QueryParam,
+		}
+		if sa.TTL > 0 {
+			info.SessionAffinity.TTL = sa.TTL.String()
+		}
+	}
+	return info
+}
+
+// handleExplain reports how a synthetic request would be routed, without
+// forwarding it or changing balancer state
+func (api *API) handleExplain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		api.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	api.mu.RLock()
+	router, versions, middlewares := api.explainer, api.versions, api.middlewares
+	api.mu.RUnlock()
+
+	if router == nil {
+		api.writeError(w, http.StatusServiceUnavailable, "Route explainer not available")
+		return
+	}
+
+	var req ExplainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Method == "" {
+		req.Method = http.MethodGet
+	}
+	if !strings.HasPrefix(req.Path, "/") {
+		api.writeError(w, http.StatusBadRequest, "Path must start with /")
+		return
+	}
+	httpReq, err := http.NewRequestWithContext(r.Context(), strings.ToUpper(req.Method), req.Path, nil)
+	if err != nil {
+		api.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err))
+		return
+	}
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	resp := ExplainResponse{
+		Method:      httpReq.Method,
+		Path:        httpReq.URL.Path,
+		Middlewares: middlewares,
+	}
+	ctx := r.Context()
+	if versions != nil {
+		resolution, err := versions.Resolve(httpReq)
+		if err != nil {
+			resp.Error = fmt.Sprintf("%v: %s", err, resolution.Version)
+			api.writeJSON(w, http.StatusOK, resp)
+			return
+		}
+		resp.Version = &resolution
+		resp.Path = resolution.Path
+		ctx = resolution.WithContext(ctx)
+	}
+
+	coreReq := core.NewRequest("explain", resp.Method, resp.Path, httpReq.URL.String(), r.RemoteAddr, httpReq.Header, nil, ctx)
+	rule, service, err := router.Explain(ctx, coreReq)
+	if err != nil {
+		resp.Error = err.Error()
+		api.writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	info := routeInfo(*rule)
+	resp.Route = &info
+	resp.Service = service
+	if service == "" && rule.TrafficSplit != nil {
+		resp.TrafficSplit = make(map[string]int, len(rule.TrafficSplit.Services))
+		for _, split := range rule.TrafficSplit.Services {
+			resp.TrafficSplit[split.ServiceName] = split.Weight
+		}
+	}
+	api.writeJSON(w, http.StatusOK, resp)
+}
+
 func (api *API) handleRouteReload(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		api.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -402,13 +959,22 @@ func (api *API) handleCircuitBreakers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if api.circuitBreaker == nil {
+	api.mu.RLock()
+	cb := api.circuitBreaker
+	api.mu.RUnlock()
+
+	if cb == nil {
 		api.writeError(w, http.StatusServiceUnavailable, "Circuit breaker not available")
 		return
 	}
 
-	status := api.circuitBreaker.GetStatus()
-	api.writeJSON(w, http.StatusOK, status)
+	resp := CircuitBreakerResponse{
+		Breakers: cb.Status(),
+	}
+	if resp.Breakers == nil {
+		resp.Breakers = []circuitbreaker.BreakerStatus{}
+	}
+	api.writeJSON(w, http.StatusOK, resp)
 }
 
 func (api *API) handleCircuitBreakerReset(w http.ResponseWriter, r *http.Request) {
@@ -417,8 +983,22 @@ func (api *API) handleCircuitBreakerReset(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// TODO: Implement circuit breaker reset
-	api.writeError(w, http.StatusNotImplemented, "Circuit breaker reset not implemented")
+	api.mu.RLock()
+	cb := api.circuitBreaker
+	api.mu.RUnlock()
+
+	if cb == nil {
+		api.writeError(w, http.StatusServiceUnavailable, "Circuit breaker not available")
+		return
+	}
+
+	service := r.PathValue("service")
+	if !cb.ResetService(service) {
+		api.writeError(w, http.StatusNotFound, fmt.Sprintf("No circuit breaker for service %s", service))
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, CircuitBreakerResetResponse{Service: service, State: "closed"})
 }
 
 func (api *API) handleRateLimits(w http.ResponseWriter, r *http.Request) {
@@ -442,8 +1022,25 @@ func (api *API) handleConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Return current configuration (sanitized)
-	api.writeError(w, http.StatusNotImplemented, "Config endpoint not implemented")
+	api.mu.RLock()
+	cfg := api.gatewayConfig
+	api.mu.RUnlock()
+	if cfg == nil {
+		api.writeError(w, http.StatusServiceUnavailable, "Configuration not available")
+		return
+	}
+
+	redacted, hash, err := config.Redact(cfg)
+	if err != nil {
+		api.logger.Error("Failed to redact configuration", "error", err)
+		api.writeError(w, http.StatusInternalServerError, "Failed to render configuration")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"hash":   hash,
+		"config": redacted,
+	})
 }
 
 func (api *API) handleConfigReload(w http.ResponseWriter, r *http.Request) {
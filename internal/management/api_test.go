@@ -7,11 +7,19 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	wsadapter "gateway/internal/adapter/websocket"
 	"gateway/internal/config"
 	"gateway/internal/core"
+	"gateway/internal/middleware/circuitbreaker"
+	"gateway/internal/middleware/maintenance"
+	"gateway/internal/middleware/versioning"
+	"gateway/internal/router"
+	"gateway/pkg/errors"
 )
 
 // Mock implementations
@@ -25,6 +33,22 @@ func (m *mockRegistry) GetService(name string) ([]core.ServiceInstance, error) {
 
 func (m *mockRegistry) Close() error { return nil }
 
+type mockListingRegistry struct {
+	mockRegistry
+}
+
+func (m *mockListingRegistry) ListServices() ([]*core.Service, error) {
+	return []*core.Service{
+		{Name: "users", Instances: []*core.ServiceInstance{
+			{ID: "users-1", Name: "users", Address: "10.0.0.1", Port: 8080, Healthy: true},
+			{ID: "users-2", Name: "users", Address: "10.0.0.2", Port: 8080, Healthy: false},
+		}},
+		{Name: "orders", Instances: []*core.ServiceInstance{
+			{ID: "orders-1", Name: "orders", Address: "10.0.0.3", Port: 9090, Healthy: true},
+		}},
+	}, nil
+}
+
 type mockRouter struct{}
 
 func (m *mockRouter) GetRoutes() []core.RouteRule {
@@ -84,6 +108,465 @@ func TestManagementAPI_Routes(t *testing.T) {
 	}
 }
 
+func TestManagementAPI_Config(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	api := NewAPI(nil, logger)
+
+	w := httptest.NewRecorder()
+	api.handleConfig(w, httptest.NewRequest(http.MethodGet, "/management/config", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d without config, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	cfg := &config.Config{}
+	cfg.Gateway.Redis = &config.Redis{Host: "redis", Password: "hunter2"}
+	api.SetConfig(cfg)
+
+	w = httptest.NewRecorder()
+	api.handleConfig(w, httptest.NewRequest(http.MethodGet, "/management/config", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if strings.Contains(w.Body.String(), "hunter2") {
+		t.Errorf("Expected the Redis password to be redacted, got %s", w.Body.String())
+	}
+
+	var resp struct {
+		Hash   string `json:"hash"`
+		Config struct {
+			Gateway struct {
+				Redis map[string]any `json:"redis"`
+			} `json:"gateway"`
+		} `json:"config"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Hash == "" {
+		t.Error("Expected a config hash")
+	}
+	if resp.Config.Gateway.Redis["host"] != "redis" {
+		t.Errorf("Expected Redis host in config, got %v", resp.Config.Gateway.Redis)
+	}
+}
+
+func TestManagementAPI_Explain(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	api := NewAPI(nil, logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/management/explain", strings.NewReader(`{"path": "/users"}`))
+	w := httptest.NewRecorder()
+	api.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d without explainer, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	r := router.NewRouter(&mockRegistry{}, logger)
+	for _, rule := range []core.RouteRule{
+		{ID: "users", Path: "/api/users/*", ServiceName: "users", LoadBalance: core.LoadBalanceRoundRobin},
+		{ID: "orders", Path: "/api/orders/*", Methods: []string{"POST"}, LoadBalance: core.LoadBalanceRoundRobin,
+			TrafficSplit: &core.TrafficSplit{Services: []core.WeightedService{
+				{ServiceName: "orders", Weight: 90},
+				{ServiceName: "orders-canary", Weight: 10},
+			}}},
+	} {
+		if err := r.AddRule(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	versions := versioning.NewVersioningMiddleware(&config.VersioningConfig{
+		Enabled:        true,
+		Strategy:       "header",
+		DefaultVersion: "1",
+		VersionMappings: map[string]*config.VersionMapping{
+			"1": {PathPrefix: "/api"},
+			"2": {PathPrefix: "/api", Service: "users-v2"},
+		},
+	}, logger)
+	api.SetExplainer(r, versions, []string{"cors", "auth", "router"})
+
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		route   string
+		service string
+		path    string
+		split   map[string]int
+		err     bool
+	}{
+		{name: "default version", body: `{"path": "/users/42"}`, status: http.StatusOK, route: "users", service: "users", path: "/api/users/42"},
+		{name: "version mapping", body: `{"method": "get", "path": "/users/42", "headers": {"X-API-Version": "2"}}`, status: http.StatusOK, route: "users", service: "users-v2", path: "/api/users/42"},
+		{name: "traffic split", body: `{"method": "POST", "path": "/orders/1"}`, status: http.StatusOK, route: "orders", path: "/api/orders/1",
+			split: map[string]int{"orders": 90, "orders-canary": 10}},
+		{name: "no route", body: `{"method": "DELETE", "path": "/orders/1"}`, status: http.StatusOK, path: "/api/orders/1", err: true},
+		{name: "relative path", body: `{"path": "users"}`, status: http.StatusBadRequest},
+		{name: "invalid body", body: `{`, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/management/explain", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			api.mux.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var resp ExplainResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if (resp.Error != "") != tt.err {
+				t.Errorf("Expected error %v, got %q", tt.err, resp.Error)
+			}
+			if resp.Route != nil && resp.Route.ID != tt.route || resp.Route == nil && tt.route != "" {
+				t.Errorf("Expected route %q, got %+v", tt.route, resp.Route)
+			}
+			if resp.Service != tt.service || resp.Path != tt.path {
+				t.Errorf("Expected service %q and path %q, got %q and %q", tt.service, tt.path, resp.Service, resp.Path)
+			}
+			if len(tt.split) > 0 && !reflect.DeepEqual(resp.TrafficSplit, tt.split) {
+				t.Errorf("Expected split %v, got %v", tt.split, resp.TrafficSplit)
+			}
+			if len(resp.Middlewares) != 3 {
+				t.Errorf("Expected the middleware chain, got %v", resp.Middlewares)
+			}
+		})
+	}
+}
+
+func TestManagementAPI_Services(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	api := NewAPI(nil, logger)
+
+	// Registry without listing support
+	api.SetRegistry(&mockRegistry{})
+	w := httptest.NewRecorder()
+	api.handleServices(w, httptest.NewRequest(http.MethodGet, "/management/services", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status %d, got %d", http.StatusNotImplemented, w.Code)
+	}
+
+	api.SetRegistry(&mockListingRegistry{})
+	w = httptest.NewRecorder()
+	api.handleServices(w, httptest.NewRequest(http.MethodGet, "/management/services", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp []ServiceResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+
+	// Services are sorted by name
+	if len(resp) != 2 || resp[0].Name != "orders" || resp[1].Name != "users" {
+		t.Fatalf("Expected services orders and users, got %+v", resp)
+	}
+	if len(resp[1].Instances) != 2 {
+		t.Fatalf("Expected 2 users instances, got %d", len(resp[1].Instances))
+	}
+	if !resp[1].Instances[0].Healthy || resp[1].Instances[1].Healthy {
+		t.Errorf("Expected instance health to be reported, got %+v", resp[1].Instances)
+	}
+}
+
+type mockDrainer struct {
+	drained map[string]bool
+}
+
+func (m *mockDrainer) Drain(id string) error {
+	if id != "users-1" && id != "users-2" {
+		return errors.NewError(errors.ErrorTypeNotFound, "instance "+id+" not found")
+	}
+	m.drained[id] = true
+	return nil
+}
+
+func (m *mockDrainer) Undrain(id string) error {
+	if id != "users-1" && id != "users-2" {
+		return errors.NewError(errors.ErrorTypeNotFound, "instance "+id+" not found")
+	}
+	delete(m.drained, id)
+	return nil
+}
+
+func (m *mockDrainer) IsDrained(id string) bool { return m.drained[id] }
+
+func TestManagementAPI_InstanceDrain(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	api := NewAPI(nil, logger)
+	api.SetRegistry(&mockListingRegistry{})
+	drainer := &mockDrainer{drained: make(map[string]bool)}
+	api.SetInstanceDrainer(drainer)
+
+	post := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		api.handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		return w
+	}
+
+	if w := post("/management/instances/users-2/drain"); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if !drainer.drained["users-2"] {
+		t.Error("Expected users-2 to be drained")
+	}
+	if w := post("/management/instances/missing/drain"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown instance, got %d", http.StatusNotFound, w.Code)
+	}
+
+	// Drain state is visible in the services dump
+	w := httptest.NewRecorder()
+	api.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/management/services", nil))
+	var services []ServiceResponse
+	if err := json.NewDecoder(w.Body).Decode(&services); err != nil {
+		t.Fatal(err)
+	}
+	if !services[1].Instances[1].Drained || services[1].Instances[0].Drained {
+		t.Errorf("Expected only users-2 to be listed drained, got %+v", services[1].Instances)
+	}
+
+	if w := post("/management/instances/users-2/undrain"); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if drainer.drained["users-2"] {
+		t.Error("Expected users-2 to be undrained")
+	}
+	if w := post("/management/instances/missing/undrain"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown instance, got %d", http.StatusNotFound, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	api.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/management/instances/users-2/drain", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d for GET, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestManagementAPI_Maintenance(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	api := NewAPI(nil, logger)
+	sw := maintenance.NewSwitch()
+	api.SetMaintenance(sw)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		api.handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/management/maintenance", strings.NewReader(body)))
+		return w
+	}
+
+	w := post(`{"enabled":true,"routes":["users"],"retryAfter":300}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var resp MaintenanceResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Enabled || resp.State.StatusCode != http.StatusServiceUnavailable || resp.State.RetryAfter != 300 {
+		t.Errorf("Expected maintenance with defaults, got %+v", resp)
+	}
+	if state := sw.State(); state == nil || state.Routes[0] != "users" {
+		t.Errorf("Expected switch to be on for users, got %+v", state)
+	}
+
+	if w := post(`{"enabled":true,"status":99}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid status, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	if w := post(`{"enabled":false}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	w = httptest.NewRecorder()
+	api.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/management/maintenance", nil))
+	resp = MaintenanceResponse{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Enabled || resp.State != nil {
+		t.Errorf("Expected maintenance to be off, got %+v", resp)
+	}
+}
+
+type mockWebSockets struct {
+	closed    []string
+	broadcast []string
+}
+
+func (m *mockWebSockets) Connections() []wsadapter.ConnectionInfo {
+	return []wsadapter.ConnectionInfo{
+		{ID: "conn-1", RemoteAddr: "10.0.0.1:5000", Path: "/chat", Route: "chat"},
+	}
+}
+
+func (m *mockWebSockets) CloseConnection(id, reason string) error {
+	if id != "conn-1" {
+		return errors.NewError(errors.ErrorTypeNotFound, "connection "+id+" not found")
+	}
+	m.closed = append(m.closed, reason)
+	return nil
+}
+
+func (m *mockWebSockets) Broadcast(msg *core.WebSocketMessage, route string) wsadapter.BroadcastResult {
+	m.broadcast = append(m.broadcast, route+":"+string(msg.Data))
+	return wsadapter.BroadcastResult{Sent: 1}
+}
+
+func TestManagementAPI_WebSocketConnections(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	api := NewAPI(nil, logger)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		api.handler.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	if w := serve(http.MethodGet, "/management/websocket/connections", ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d without WebSocket adapter, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	ws := &mockWebSockets{}
+	api.SetWebSocketConnections(ws)
+
+	w := serve(http.MethodGet, "/management/websocket/connections", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var list WebSocketConnectionsResponse
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Count != 1 || list.Connections[0].ID != "conn-1" || list.Connections[0].Route != "chat" {
+		t.Errorf("Unexpected connections %+v", list)
+	}
+
+	if w := serve(http.MethodPost, "/management/websocket/connections/conn-1/close", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if w := serve(http.MethodPost, "/management/websocket/connections/conn-1/close", `{"reason":"maintenance"}`); w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if len(ws.closed) != 2 || ws.closed[0] != "closed by administrator" || ws.closed[1] != "maintenance" {
+		t.Errorf("Expected default and given reasons, got %v", ws.closed)
+	}
+	if w := serve(http.MethodPost, "/management/websocket/connections/conn-9/close", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown connection, got %d", http.StatusNotFound, w.Code)
+	}
+	if w := serve(http.MethodGet, "/management/websocket/connections/conn-1/close", ""); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d for GET, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+
+	w = serve(http.MethodPost, "/management/websocket/broadcast", `{"message":"hello","route":"chat"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var result wsadapter.BroadcastResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Sent != 1 || len(ws.broadcast) != 1 || ws.broadcast[0] != "chat:hello" {
+		t.Errorf("Expected broadcast to chat, got %+v and %v", result, ws.broadcast)
+	}
+	if w := serve(http.MethodPost, "/management/websocket/broadcast", `{"route":"chat"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without message, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+type mockCircuitBreaker struct {
+	reset []string
+}
+
+func (m *mockCircuitBreaker) Status() []circuitbreaker.BreakerStatus {
+	return []circuitbreaker.BreakerStatus{
+		{Key: "route:users", Service: "users", State: "open", Failures: 5},
+	}
+}
+
+func (m *mockCircuitBreaker) ResetService(service string) bool {
+	if service != "users" {
+		return false
+	}
+	m.reset = append(m.reset, service)
+	return true
+}
+
+func TestManagementAPI_CircuitBreakers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	api := NewAPI(nil, logger)
+	cb := &mockCircuitBreaker{}
+	api.SetCircuitBreaker(cb)
+
+	w := httptest.NewRecorder()
+	api.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/management/circuitbreakers", nil))
+	var resp CircuitBreakerResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Breakers) != 1 || resp.Breakers[0].Service != "users" || resp.Breakers[0].State != "open" {
+		t.Errorf("Expected open users breaker, got %+v", resp.Breakers)
+	}
+
+	w = httptest.NewRecorder()
+	api.handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/management/circuitbreakers/users/reset", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if len(cb.reset) != 1 {
+		t.Errorf("Expected users breakers to be reset, got %v", cb.reset)
+	}
+
+	w = httptest.NewRecorder()
+	api.handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/management/circuitbreakers/unknown/reset", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown service, got %d", http.StatusNotFound, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	api.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/management/circuitbreakers/users/reset", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d for GET, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestManagementAPI_EndpointsRequireAuth(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	api := NewAPI(&config.Management{
+		Enabled:  true,
+		BasePath: "/management",
+		Auth: &config.ManagementAuth{
+			Type:  "token",
+			Token: "secret123",
+		},
+	}, logger)
+	api.SetRouter(&mockRouter{})
+	api.SetRegistry(&mockListingRegistry{})
+
+	api.SetCircuitBreaker(&mockCircuitBreaker{})
+	api.SetConfig(&config.Config{})
+
+	for _, path := range []string{"/management/routes", "/management/services", "/management/circuitbreakers", "/management/config"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		api.handler.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected status %d without token, got %d", path, http.StatusUnauthorized, w.Code)
+		}
+
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer secret123")
+		w = httptest.NewRecorder()
+		api.handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected status %d with token, got %d", path, http.StatusOK, w.Code)
+		}
+	}
+}
+
 func TestManagementAPI_Auth(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	
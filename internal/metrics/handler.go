@@ -3,6 +3,7 @@ package metrics
 import (
 	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -10,3 +11,18 @@ import (
 func Handler() http.Handler {
 	return promhttp.Handler()
 }
+
+// HandlerFor returns a handler serving the default registry merged with
+// additional gatherers
+func HandlerFor(gatherers ...prometheus.Gatherer) http.Handler {
+	if len(gatherers) == 0 {
+		return Handler()
+	}
+	merged := prometheus.Gatherers{prometheus.DefaultGatherer}
+	merged = append(merged, gatherers...)
+	return promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		// OpenMetrics is required for exemplars to be exposed
+		promhttp.HandlerFor(merged, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	)
+}
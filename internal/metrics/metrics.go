@@ -25,6 +25,12 @@ type Metrics struct {
 	WebSocketMessagesSent     *prometheus.CounterVec
 	WebSocketMessagesReceived *prometheus.CounterVec
 
+	// TCP metrics
+	TCPConnections      *prometheus.GaugeVec
+	TCPConnectionsTotal *prometheus.CounterVec
+	TCPBytesSent        *prometheus.CounterVec
+	TCPBytesReceived    *prometheus.CounterVec
+
 	// SSE metrics
 	SSEConnections      *prometheus.GaugeVec
 	SSEConnectionsTotal *prometheus.CounterVec
@@ -146,6 +152,36 @@ func NewWithRegistry(registerer prometheus.Registerer, gatherer prometheus.Gathe
 			[]string{"service"},
 		),
 
+		// TCP metrics
+		TCPConnections: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "gateway_tcp_connections_active",
+				Help: "Number of active TCP connections",
+			},
+			[]string{"service"},
+		),
+		TCPConnectionsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gateway_tcp_connections_total",
+				Help: "Total number of TCP connections",
+			},
+			[]string{"service", "status"},
+		),
+		TCPBytesSent: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gateway_tcp_bytes_sent_total",
+				Help: "Total number of bytes sent to TCP clients",
+			},
+			[]string{"service"},
+		),
+		TCPBytesReceived: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gateway_tcp_bytes_received_total",
+				Help: "Total number of bytes received from TCP clients",
+			},
+			[]string{"service"},
+		),
+
 		// SSE metrics
 		SSEConnections: factory.NewGaugeVec(
 			prometheus.GaugeOpts{
@@ -47,6 +47,18 @@ func TestNew(t *testing.T) {
 	if m.WebSocketMessagesReceived == nil {
 		t.Error("WebSocketMessagesReceived is nil")
 	}
+	if m.TCPConnections == nil {
+		t.Error("TCPConnections is nil")
+	}
+	if m.TCPConnectionsTotal == nil {
+		t.Error("TCPConnectionsTotal is nil")
+	}
+	if m.TCPBytesSent == nil {
+		t.Error("TCPBytesSent is nil")
+	}
+	if m.TCPBytesReceived == nil {
+		t.Error("TCPBytesReceived is nil")
+	}
 	if m.SSEConnections == nil {
 		t.Error("SSEConnections is nil")
 	}
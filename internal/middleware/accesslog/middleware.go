@@ -0,0 +1,196 @@
+package accesslog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
+	"gateway/pkg/clientip"
+)
+
+// Access log fields
+const (
+	FieldMethod    = "method"
+	FieldPath      = "path"
+	FieldStatus    = "status"
+	FieldDuration  = "duration"
+	FieldBytes     = "bytes"
+	FieldRequestID = "request_id"
+	FieldClientIP  = "client_ip"
+	FieldInstance  = "instance"
+	FieldSubject   = "subject"
+)
+
+// Fields lists every access log field in the order records carry them
+var Fields = []string{
+	FieldMethod, FieldPath, FieldStatus, FieldDuration,
+	FieldBytes, FieldRequestID, FieldClientIP, FieldInstance, FieldSubject,
+}
+
+// Config holds access log configuration
+type Config struct {
+	// Fields limits records to the named fields; empty logs every field
+	Fields []string
+	// SampleRate is the fraction of successful requests logged, from 0 to 1.
+	// Zero logs every request. Server errors are always logged.
+	SampleRate float64
+}
+
+// Middleware writes one access log record per request. Status and bytes are
+// taken from the response writer; the upstream instance and auth subject are
+// recorded by Annotate, which runs inside routing and authentication.
+type Middleware struct {
+	logger     *slog.Logger
+	fields     map[string]bool
+	sampleRate float64
+	sample     func() float64
+}
+
+// New creates an access log middleware writing records to logger
+func New(config Config, logger *slog.Logger) *Middleware {
+	fields := make(map[string]bool, len(Fields))
+	if len(config.Fields) == 0 {
+		for _, field := range Fields {
+			fields[field] = true
+		}
+	}
+	for _, field := range config.Fields {
+		fields[field] = true
+	}
+
+	sampleRate := config.SampleRate
+	if sampleRate <= 0 || sampleRate > 1 {
+		sampleRate = 1
+	}
+
+	return &Middleware{
+		logger:     logger,
+		fields:     fields,
+		sampleRate: sampleRate,
+		sample:     rand.Float64,
+	}
+}
+
+// entry collects request details only known inside the handler chain
+type entry struct {
+	instance string
+	subject  string
+}
+
+// entryKey is the context key for the request's access log entry
+type entryKey struct{}
+
+// Handler wraps an HTTP handler, logging each request it serves
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		e := &entry{}
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), entryKey{}, e)))
+
+		if rw.status < http.StatusInternalServerError && m.sampleRate < 1 && m.sample() >= m.sampleRate {
+			return
+		}
+		m.log(r, rw, e, time.Since(start))
+	})
+}
+
+// Annotate records the upstream instance and auth subject of a request. It
+// must run after routing, so the builder applies it inside the route-aware
+// handler.
+func (m *Middleware) Annotate(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		if e, ok := ctx.Value(entryKey{}).(*entry); ok {
+			if route := core.RouteResultFromContext(ctx); route != nil && route.Instance != nil {
+				e.instance = route.Instance.ID
+			}
+			if info, ok := auth.GetAuthInfo(ctx); ok && info != nil {
+				e.subject = info.Subject
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// log writes the record for a completed request
+func (m *Middleware) log(r *http.Request, rw *responseWriter, e *entry, duration time.Duration) {
+	attrs := make([]slog.Attr, 0, len(Fields))
+	add := func(field string, value slog.Value) {
+		if m.fields[field] {
+			attrs = append(attrs, slog.Attr{Key: field, Value: value})
+		}
+	}
+
+	add(FieldMethod, slog.StringValue(r.Method))
+	add(FieldPath, slog.StringValue(r.URL.Path))
+	add(FieldStatus, slog.IntValue(rw.status))
+	add(FieldDuration, slog.DurationValue(duration))
+	add(FieldBytes, slog.Int64Value(rw.bytes))
+	add(FieldRequestID, slog.StringValue(r.Header.Get("X-Request-ID")))
+	if ip := clientip.HostIP(r.RemoteAddr); ip != nil {
+		add(FieldClientIP, slog.StringValue(ip.String()))
+	}
+	if e.instance != "" {
+		add(FieldInstance, slog.StringValue(e.instance))
+	}
+	if e.subject != "" {
+		add(FieldSubject, slog.StringValue(e.subject))
+	}
+
+	m.logger.LogAttrs(r.Context(), slog.LevelInfo, "access", attrs...)
+}
+
+// responseWriter records the status code and body size of a response
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// WriteHeader records the status code of the final response; informational
+// responses are passed through
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader && (status >= http.StatusOK || status == http.StatusSwitchingProtocols) {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write counts the bytes written
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush sends buffered data, so streamed responses keep working
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets upgraded connections take over the underlying connection
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the wrapped response writer for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,128 @@
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
+)
+
+// Mock request for testing
+type mockRequest struct{}
+
+func (m *mockRequest) ID() string                   { return "test-id" }
+func (m *mockRequest) Method() string               { return "GET" }
+func (m *mockRequest) Path() string                 { return "/api/users" }
+func (m *mockRequest) URL() string                  { return "/api/users" }
+func (m *mockRequest) RemoteAddr() string           { return "127.0.0.1:12345" }
+func (m *mockRequest) Headers() map[string][]string { return nil }
+func (m *mockRequest) Body() io.ReadCloser          { return nil }
+func (m *mockRequest) Context() context.Context     { return context.Background() }
+
+// serve runs a request through the access log and returns the logged records
+func serve(t *testing.T, m *Middleware, status int, body string) []map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	m.logger = slog.New(slog.NewJSONHandler(&buf, nil))
+
+	inner := m.Annotate(func(ctx context.Context, req core.Request) (core.Response, error) {
+		return nil, nil
+	})
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("X-Request-ID", "req-1")
+		ctx := core.WithRouteResult(r.Context(), &core.RouteResult{
+			Instance: &core.ServiceInstance{ID: "users-1"},
+		})
+		ctx = auth.WithAuthInfo(ctx, &auth.AuthInfo{Subject: "alice"})
+		inner(ctx, &mockRequest{})
+
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/users", nil))
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestMiddleware_Record(t *testing.T) {
+	m := New(Config{}, slog.Default())
+	records := serve(t, m, http.StatusCreated, "hello")
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+
+	record := records[0]
+	expected := map[string]any{
+		"msg":        "access",
+		"method":     "GET",
+		"path":       "/api/users",
+		"status":     float64(http.StatusCreated),
+		"bytes":      float64(5),
+		"request_id": "req-1",
+		"client_ip":  "192.0.2.1",
+		"instance":   "users-1",
+		"subject":    "alice",
+	}
+	for key, want := range expected {
+		if record[key] != want {
+			t.Errorf("%s: expected %v, got %v", key, want, record[key])
+		}
+	}
+	if _, ok := record["duration"]; !ok {
+		t.Error("expected duration field")
+	}
+}
+
+func TestMiddleware_Fields(t *testing.T) {
+	m := New(Config{Fields: []string{FieldStatus, FieldInstance}}, slog.Default())
+	records := serve(t, m, http.StatusOK, "")
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+
+	for _, field := range Fields {
+		_, ok := records[0][field]
+		want := field == FieldStatus || field == FieldInstance
+		if ok != want {
+			t.Errorf("field %s: expected present=%v", field, want)
+		}
+	}
+}
+
+func TestMiddleware_Sampling(t *testing.T) {
+	m := New(Config{SampleRate: 0.5}, slog.Default())
+
+	m.sample = func() float64 { return 0.7 }
+	if records := serve(t, m, http.StatusOK, ""); len(records) != 0 {
+		t.Errorf("expected unsampled request to be skipped, got %d records", len(records))
+	}
+	if records := serve(t, m, http.StatusBadGateway, ""); len(records) != 1 {
+		t.Errorf("expected server error to be logged, got %d records", len(records))
+	}
+
+	m.sample = func() float64 { return 0.2 }
+	if records := serve(t, m, http.StatusOK, ""); len(records) != 1 {
+		t.Errorf("expected sampled request to be logged, got %d records", len(records))
+	}
+}
@@ -0,0 +1,308 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
+	"gateway/pkg/errors"
+)
+
+// Decisions recorded in audit events
+const (
+	DecisionAllowed = "allowed"
+	DecisionDenied  = "denied"
+	DecisionError   = "error"
+)
+
+// redacted replaces redacted header and body values
+const redacted = "[REDACTED]"
+
+// DefaultMaxBodySize is the number of body bytes captured when no limit is set
+const DefaultMaxBodySize = 4096
+
+// Event is one audited request
+type Event struct {
+	Time            time.Time           `json:"time"`
+	RequestID       string              `json:"requestId"`
+	Route           string              `json:"route"`
+	Principal       string              `json:"principal,omitempty"`
+	Action          string              `json:"action"`
+	Resource        string              `json:"resource"`
+	Decision        string              `json:"decision"`
+	Status          int                 `json:"status"`
+	DurationMs      float64             `json:"durationMs"`
+	RemoteAddr      string              `json:"remoteAddr"`
+	RequestHeaders  map[string][]string `json:"requestHeaders,omitempty"`
+	ResponseHeaders map[string][]string `json:"responseHeaders,omitempty"`
+	RequestBody     *Body               `json:"requestBody,omitempty"`
+	ResponseBody    *Body               `json:"responseBody,omitempty"`
+	Error           string              `json:"error,omitempty"`
+}
+
+// Body is a captured request or response body
+type Body struct {
+	Content   string `json:"content"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// Config holds audit middleware configuration
+type Config struct {
+	// Routes are the audited routes by route ID
+	Routes map[string]RouteConfig
+	// IncludeBodies captures request and response bodies
+	IncludeBodies bool
+	// MaxBodySize is the number of bytes captured per body
+	MaxBodySize int
+	// RedactFields are JSON fields whose values are redacted in bodies
+	RedactFields []string
+	// RedactHeaders are headers redacted in addition to the defaults
+	RedactHeaders []string
+}
+
+// RouteConfig holds audit settings for a route
+type RouteConfig struct {
+	// MustAudit fails the request when its event cannot be written
+	MustAudit bool
+}
+
+// RouteMatcher finds the route rule for a request without routing it
+type RouteMatcher interface {
+	Match(core.Request) (*core.RouteRule, error)
+}
+
+// Middleware writes an audit event for every request on audited routes. It
+// runs ahead of authentication so denied requests are audited too; the
+// principal is recorded by Annotate, which runs inside authentication.
+type Middleware struct {
+	config        Config
+	matcher       RouteMatcher
+	sink          Sink
+	logger        *slog.Logger
+	redactFields  map[string]bool
+	redactHeaders map[string]bool
+}
+
+// New creates an audit middleware writing events for matched routes to sink
+func New(config Config, matcher RouteMatcher, sink Sink, logger *slog.Logger) *Middleware {
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultMaxBodySize
+	}
+
+	redactFields := make(map[string]bool, len(config.RedactFields))
+	for _, field := range config.RedactFields {
+		redactFields[strings.ToLower(field)] = true
+	}
+	redactHeaders := map[string]bool{
+		"Authorization": true,
+		"Cookie":        true,
+		"Set-Cookie":    true,
+	}
+	for _, header := range config.RedactHeaders {
+		redactHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+
+	return &Middleware{
+		config:        config,
+		matcher:       matcher,
+		sink:          sink,
+		logger:        logger,
+		redactFields:  redactFields,
+		redactHeaders: redactHeaders,
+	}
+}
+
+// principalKey is the context key for the request's principal holder
+type principalKey struct{}
+
+// principal holds the subject recorded by Annotate
+type principal struct {
+	subject string
+}
+
+// Handler returns the audit middleware
+func (m *Middleware) Handler(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		rule, err := m.matcher.Match(req)
+		if err != nil {
+			return next(ctx, req)
+		}
+		routeCfg, ok := m.config.Routes[rule.ID]
+		if !ok {
+			return next(ctx, req)
+		}
+
+		start := time.Now()
+		event := &Event{
+			Time:           start,
+			RequestID:      req.ID(),
+			Route:          rule.ID,
+			Action:         req.Method(),
+			Resource:       req.URL(),
+			RemoteAddr:     req.RemoteAddr(),
+			RequestHeaders: m.headers(req.Headers()),
+		}
+
+		if m.config.IncludeBodies && req.Body() != nil {
+			var body io.ReadCloser
+			event.RequestBody, body = m.capture(req.Body())
+			req = &auditedRequest{Request: req, body: body}
+		}
+
+		p := &principal{}
+		resp, err := next(context.WithValue(ctx, principalKey{}, p), req)
+
+		event.Principal = p.subject
+		event.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+		switch {
+		case err != nil:
+			event.Status = errors.HTTPStatus(err)
+			event.Decision = DecisionError
+			if event.Status == http.StatusUnauthorized || event.Status == http.StatusForbidden {
+				event.Decision = DecisionDenied
+			}
+			event.Error = err.Error()
+		case resp != nil:
+			event.Status = resp.StatusCode()
+			event.Decision = DecisionAllowed
+			event.ResponseHeaders = m.headers(resp.Headers())
+			if m.config.IncludeBodies && resp.Body() != nil {
+				var body io.ReadCloser
+				event.ResponseBody, body = m.capture(resp.Body())
+				resp = &auditedResponse{Response: resp, body: body}
+			}
+		}
+
+		if writeErr := m.sink.Write(context.WithoutCancel(ctx), event); writeErr != nil {
+			m.logger.Error("failed to write audit event",
+				"request_id", event.RequestID,
+				"route", event.Route,
+				"error", writeErr)
+			if routeCfg.MustAudit {
+				if resp != nil && resp.Body() != nil {
+					resp.Body().Close()
+				}
+				return nil, errors.NewError(errors.ErrorTypeUnavailable, "audit log unavailable").WithCause(writeErr)
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// Annotate records the authenticated principal for the request's audit
+// event, so it must run inside the authentication middleware
+func (m *Middleware) Annotate(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		if p, ok := ctx.Value(principalKey{}).(*principal); ok {
+			if info, ok := auth.GetAuthInfo(ctx); ok && info != nil {
+				p.subject = info.Subject
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// Close closes the sink
+func (m *Middleware) Close() error {
+	return m.sink.Close()
+}
+
+// headers copies headers, redacting sensitive values
+func (m *Middleware) headers(headers map[string][]string) map[string][]string {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	result := make(map[string][]string, len(headers))
+	for k, values := range headers {
+		if m.redactHeaders[http.CanonicalHeaderKey(k)] {
+			result[k] = []string{redacted}
+			continue
+		}
+		result[k] = append([]string(nil), values...)
+	}
+	return result
+}
+
+// capture reads up to MaxBodySize bytes of body for the event. It returns
+// the captured body and a reader replaying the full body.
+func (m *Middleware) capture(body io.ReadCloser) (*Body, io.ReadCloser) {
+	prefix, _ := io.ReadAll(io.LimitReader(body, int64(m.config.MaxBodySize)+1))
+	replay := &replayBody{Reader: io.MultiReader(bytes.NewReader(prefix), body), Closer: body}
+
+	captured := &Body{}
+	if len(prefix) > m.config.MaxBodySize {
+		captured.Truncated = true
+		prefix = prefix[:m.config.MaxBodySize]
+	}
+	captured.Content = m.redactBody(prefix, captured.Truncated)
+	return captured, replay
+}
+
+// redactBody redacts configured fields from a JSON body. Bodies that cannot
+// be parsed, including truncated ones, are redacted entirely when any
+// fields are configured, since their fields cannot be located.
+func (m *Middleware) redactBody(body []byte, truncated bool) string {
+	if len(m.redactFields) == 0 {
+		return string(body)
+	}
+
+	var value any
+	if truncated || json.Unmarshal(body, &value) != nil {
+		return redacted
+	}
+	data, err := json.Marshal(m.redactValue(value))
+	if err != nil {
+		return redacted
+	}
+	return string(data)
+}
+
+// redactValue replaces the values of configured fields in a decoded JSON value
+func (m *Middleware) redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if m.redactFields[strings.ToLower(k)] {
+				v[k] = redacted
+			} else {
+				v[k] = m.redactValue(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = m.redactValue(item)
+		}
+	}
+	return value
+}
+
+// replayBody replays a captured prefix followed by the rest of a body
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// auditedRequest replaces the body of a request whose body was captured
+type auditedRequest struct {
+	core.Request
+	body io.ReadCloser
+}
+
+func (r *auditedRequest) Body() io.ReadCloser { return r.body }
+
+// auditedResponse replaces the body of a response whose body was captured
+type auditedResponse struct {
+	core.Response
+	body io.ReadCloser
+}
+
+func (r *auditedResponse) Body() io.ReadCloser { return r.body }
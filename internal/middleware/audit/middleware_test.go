@@ -0,0 +1,186 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
+	gwerrors "gateway/pkg/errors"
+)
+
+// Mock request for testing
+type mockRequest struct {
+	path    string
+	headers map[string][]string
+	body    string
+}
+
+func (m *mockRequest) ID() string                   { return "test-id" }
+func (m *mockRequest) Method() string               { return "POST" }
+func (m *mockRequest) Path() string                 { return m.path }
+func (m *mockRequest) URL() string                  { return m.path }
+func (m *mockRequest) RemoteAddr() string           { return "127.0.0.1:12345" }
+func (m *mockRequest) Headers() map[string][]string { return m.headers }
+func (m *mockRequest) Body() io.ReadCloser          { return io.NopCloser(strings.NewReader(m.body)) }
+func (m *mockRequest) Context() context.Context     { return context.Background() }
+
+// mockMatcher matches paths starting with /audited to the "audited" route
+type mockMatcher struct{}
+
+func (mockMatcher) Match(req core.Request) (*core.RouteRule, error) {
+	if strings.HasPrefix(req.Path(), "/audited") {
+		return &core.RouteRule{ID: "audited"}, nil
+	}
+	return &core.RouteRule{ID: "other"}, nil
+}
+
+// memorySink records events, failing writes when err is set
+type memorySink struct {
+	events []*Event
+	err    error
+}
+
+func (s *memorySink) Write(ctx context.Context, event *Event) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *memorySink) Close() error { return nil }
+
+// authenticated simulates the auth middleware around Annotate
+func authenticated(m *Middleware, handler core.Handler) core.Handler {
+	inner := m.Annotate(handler)
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		return inner(auth.WithAuthInfo(ctx, &auth.AuthInfo{Subject: "alice"}), req)
+	}
+}
+
+func TestMiddleware_Event(t *testing.T) {
+	sink := &memorySink{}
+	m := New(Config{Routes: map[string]RouteConfig{"audited": {}}}, mockMatcher{}, sink, slog.Default())
+
+	handler := m.Handler(authenticated(m, func(ctx context.Context, req core.Request) (core.Response, error) {
+		return core.NewResponse(201, []byte("created")), nil
+	}))
+
+	req := &mockRequest{path: "/audited/users", headers: map[string][]string{
+		"Authorization": {"Bearer secret"},
+		"X-Tenant":      {"acme"},
+	}}
+	if _, err := handler(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sink.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(sink.events))
+	}
+	event := sink.events[0]
+	if event.Route != "audited" || event.Principal != "alice" || event.Action != "POST" ||
+		event.Resource != "/audited/users" || event.Decision != DecisionAllowed || event.Status != 201 {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if got := event.RequestHeaders["Authorization"]; len(got) != 1 || got[0] != redacted {
+		t.Errorf("expected Authorization redacted, got %v", got)
+	}
+	if got := event.RequestHeaders["X-Tenant"]; len(got) != 1 || got[0] != "acme" {
+		t.Errorf("expected X-Tenant kept, got %v", got)
+	}
+	if event.RequestBody != nil || event.ResponseBody != nil {
+		t.Error("expected bodies not to be captured by default")
+	}
+
+	// Requests on other routes are not audited
+	if _, err := handler(context.Background(), &mockRequest{path: "/public"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sink.events) != 1 {
+		t.Errorf("expected unaudited route to be skipped, got %d events", len(sink.events))
+	}
+}
+
+func TestMiddleware_Denied(t *testing.T) {
+	sink := &memorySink{}
+	m := New(Config{Routes: map[string]RouteConfig{"audited": {}}}, mockMatcher{}, sink, slog.Default())
+
+	handler := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		return nil, gwerrors.NewError(gwerrors.ErrorTypeForbidden, "access denied")
+	})
+	if _, err := handler(context.Background(), &mockRequest{path: "/audited"}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if len(sink.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(sink.events))
+	}
+	if event := sink.events[0]; event.Decision != DecisionDenied || event.Status != 403 {
+		t.Errorf("expected denied 403, got %s %d", event.Decision, event.Status)
+	}
+}
+
+func TestMiddleware_Bodies(t *testing.T) {
+	sink := &memorySink{}
+	m := New(Config{
+		Routes:        map[string]RouteConfig{"audited": {}},
+		IncludeBodies: true,
+		MaxBodySize:   64,
+		RedactFields:  []string{"password"},
+	}, mockMatcher{}, sink, slog.Default())
+
+	var forwarded string
+	handler := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		data, _ := io.ReadAll(req.Body())
+		forwarded = string(data)
+		return core.NewResponse(200, []byte(strings.Repeat("x", 100))), nil
+	})
+
+	body := `{"user":"alice","credentials":{"password":"hunter2"}}`
+	resp, err := handler(context.Background(), &mockRequest{path: "/audited", body: body})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if forwarded != body {
+		t.Errorf("expected backend to receive full body, got %q", forwarded)
+	}
+	data, _ := io.ReadAll(resp.Body())
+	if len(data) != 100 {
+		t.Errorf("expected client to receive full response, got %d bytes", len(data))
+	}
+
+	event := sink.events[0]
+	if got := event.RequestBody.Content; strings.Contains(got, "hunter2") || !strings.Contains(got, "alice") {
+		t.Errorf("expected password redacted, got %s", got)
+	}
+	// Truncated bodies cannot be parsed, so they are redacted entirely
+	if !event.ResponseBody.Truncated || event.ResponseBody.Content != redacted {
+		t.Errorf("expected truncated response body redacted, got %+v", event.ResponseBody)
+	}
+}
+
+func TestMiddleware_MustAudit(t *testing.T) {
+	sink := &memorySink{err: errors.New("disk full")}
+	m := New(Config{Routes: map[string]RouteConfig{"audited": {MustAudit: true}}}, mockMatcher{}, sink, slog.Default())
+
+	handler := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		return core.NewResponse(200, []byte("ok")), nil
+	})
+
+	_, err := handler(context.Background(), &mockRequest{path: "/audited"})
+	if gwerrors.HTTPStatus(err) != 503 {
+		t.Errorf("expected must-audit route to fail closed with 503, got %v", err)
+	}
+
+	// Routes that are audited but not must-audit keep serving
+	m.config.Routes["audited"] = RouteConfig{}
+	if _, err := handler(context.Background(), &mockRequest{path: "/audited"}); err != nil {
+		t.Errorf("expected audit failure to be ignored, got %v", err)
+	}
+}
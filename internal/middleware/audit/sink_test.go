@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+
+	for _, id := range []string{"req-1", "req-2"} {
+		if err := sink.Write(context.Background(), &Event{RequestID: id}); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	var event Event
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil || event.RequestID != "req-2" {
+		t.Errorf("unexpected event %q: %v", lines[1], err)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received Event
+	var token string
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("X-Audit-Token")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, map[string]string{"X-Audit-Token": "secret"}, time.Second)
+	defer sink.Close()
+
+	if err := sink.Write(context.Background(), &Event{RequestID: "req-1"}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if received.RequestID != "req-1" || token != "secret" {
+		t.Errorf("unexpected delivery: event %+v, token %q", received, token)
+	}
+
+	status = http.StatusInternalServerError
+	if err := sink.Write(context.Background(), &Event{RequestID: "req-2"}); err == nil {
+		t.Error("expected error for non-2xx webhook response")
+	}
+}
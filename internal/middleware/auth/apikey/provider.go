@@ -21,6 +21,46 @@ type Config struct {
 	HashKeys bool `yaml:"hashKeys"`
 	// DefaultScopes are scopes granted to all keys
 	DefaultScopes []string `yaml:"defaultScopes"`
+	// Store supplies keys in addition to Keys, nil for static keys only
+	Store Store `yaml:"-"`
+	// RefreshInterval is how long keys loaded from Store are used before
+	// they are loaded again
+	RefreshInterval time.Duration `yaml:"refreshInterval"`
+	// ExpiryWarning is how long before expiry keys are logged as expiring
+	// soon; zero disables the expiry check
+	ExpiryWarning time.Duration `yaml:"expiryWarning"`
+}
+
+// DefaultRefreshInterval is how often store keys are reloaded when no
+// interval is set
+const DefaultRefreshInterval = time.Minute
+
+// missReloadInterval limits reloads of the store triggered by unknown keys,
+// so requests with invalid keys cannot flood it
+const missReloadInterval = 5 * time.Second
+
+// storeLoadTimeout bounds a store load, which requests waiting on it share
+const storeLoadTimeout = 10 * time.Second
+
+// DefaultExpiryWarning is how long before expiry keys are logged when the
+// configuration does not set it
+const DefaultExpiryWarning = 7 * 24 * time.Hour
+
+// expiryCheckInterval is how often keys are checked for upcoming expiry
+const expiryCheckInterval = time.Hour
+
+// Authentication outcomes recorded by MetricsRecorder
+const (
+	ReasonValid    = "valid"
+	ReasonInvalid  = "invalid"
+	ReasonDisabled = "disabled"
+	ReasonExpired  = "expired"
+)
+
+// MetricsRecorder receives the outcome of each authentication. keyID is
+// empty for unknown keys.
+type MetricsRecorder interface {
+	RecordAPIKeyAuthentication(ctx context.Context, keyID, reason string)
 }
 
 // KeyConfig represents configuration for a single API key
@@ -47,6 +87,16 @@ type Provider struct {
 	logger *slog.Logger
 	keys   map[string]*KeyConfig
 	mu     sync.RWMutex
+
+	// Keys loaded from the store, replaced on every successful load
+	storeKeys map[string]*KeyConfig
+	nextLoad  time.Time  // Store keys are stale after this time
+	loadMu    sync.Mutex // Serializes store loads
+	lastLoad  time.Time  // Time of the last load attempt
+
+	metrics MetricsRecorder
+	stop    chan struct{} // Closed to stop the expiry check
+	done    chan struct{} // Closed when the expiry check has stopped
 }
 
 // NewProvider creates a new API key authentication provider
@@ -60,19 +110,43 @@ func NewProvider(config *Config, logger *slog.Logger) (*Provider, error) {
 		if key.Key == "" {
 			return nil, fmt.Errorf("API key %s has empty key value", id)
 		}
-		if key.Subject == "" {
-			key.Subject = id
-		}
-		if key.Type == "" {
-			key.Type = "service"
-		}
+		applyDefaults(id, key)
+	}
+	if config.Store != nil && config.RefreshInterval <= 0 {
+		config.RefreshInterval = DefaultRefreshInterval
 	}
 
-	return &Provider{
+	p := &Provider{
 		config: config,
 		logger: logger,
 		keys:   config.Keys,
-	}, nil
+	}
+	if config.ExpiryWarning > 0 {
+		p.stop = make(chan struct{})
+		p.done = make(chan struct{})
+		go p.checkExpiryLoop()
+	}
+	return p, nil
+}
+
+// WithMetrics sets the recorder notified of authentications
+func (p *Provider) WithMetrics(metrics MetricsRecorder) *Provider {
+	p.metrics = metrics
+	return p
+}
+
+// Close stops the expiry check
+func (p *Provider) Close() error {
+	if p.stop == nil {
+		return nil
+	}
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+	<-p.done
+	return nil
 }
 
 // Name returns the provider name
@@ -97,41 +171,40 @@ func (p *Provider) Authenticate(ctx context.Context, credentials auth.Credential
 		key = hex.EncodeToString(hash[:])
 	}
 
-	// Look up the key
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-
-	var keyConfig *KeyConfig
-	var keyID string
-
-	// Try to find the key
-	for id, cfg := range p.keys {
-		if cfg.Key == key {
-			keyConfig = cfg
-			keyID = id
-			break
-		}
+	// Look up the key, reloading store keys when they are stale or the key
+	// is unknown
+	keyID, keyConfig := p.lookup(key)
+	if p.config.Store != nil && (keyConfig == nil || p.storeStale()) {
+		p.loadStore(keyConfig == nil)
+		keyID, keyConfig = p.lookup(key)
 	}
 
 	if keyConfig == nil {
+		p.record(ctx, "", ReasonInvalid)
 		return nil, errors.NewError(
-			errors.ErrorTypeBadRequest,
+			errors.ErrorTypeUnauthorized,
 			"invalid API key",
 		)
 	}
 
 	// Check if key is disabled
-	if keyConfig.Disabled {
+	p.mu.RLock()
+	disabled := keyConfig.Disabled
+	p.mu.RUnlock()
+	if disabled {
+		p.record(ctx, keyID, ReasonDisabled)
 		return nil, errors.NewError(
-			errors.ErrorTypeBadRequest,
+			errors.ErrorTypeUnauthorized,
 			"API key is disabled",
 		).WithDetail("keyId", keyID)
 	}
 
 	// Check expiration
-	if keyConfig.ExpiresAt != nil && time.Now().After(*keyConfig.ExpiresAt) {
+	if keyConfig.ExpiresAt != nil && !time.Now().Before(*keyConfig.ExpiresAt) {
+		p.record(ctx, keyID, ReasonExpired)
+		p.logger.Debug("Rejected expired API key", "keyId", keyID, "expiresAt", keyConfig.ExpiresAt)
 		return nil, errors.NewError(
-			errors.ErrorTypeBadRequest,
+			errors.ErrorTypeUnauthorized,
 			"API key has expired",
 		).WithDetail("keyId", keyID)
 	}
@@ -167,6 +240,7 @@ func (p *Provider) Authenticate(ctx context.Context, credentials auth.Credential
 	// Add key ID to metadata
 	authInfo.Metadata["keyId"] = keyID
 
+	p.record(ctx, keyID, ReasonValid)
 	p.logger.Debug("API key authenticated",
 		"keyId", keyID,
 		"subject", keyConfig.Subject,
@@ -176,6 +250,139 @@ func (p *Provider) Authenticate(ctx context.Context, credentials auth.Credential
 	return authInfo, nil
 }
 
+// record notifies the metrics recorder of an authentication outcome
+func (p *Provider) record(ctx context.Context, keyID, reason string) {
+	if p.metrics != nil {
+		p.metrics.RecordAPIKeyAuthentication(ctx, keyID, reason)
+	}
+}
+
+// checkExpiryLoop checks keys for upcoming expiry until Close is called
+func (p *Provider) checkExpiryLoop() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(expiryCheckInterval)
+	defer ticker.Stop()
+
+	p.checkExpiry(time.Now())
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			p.checkExpiry(now)
+		}
+	}
+}
+
+// checkExpiry logs enabled keys that have expired or expire within the
+// warning period, so they can be rotated in time
+func (p *Provider) checkExpiry(now time.Time) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, keys := range []map[string]*KeyConfig{p.keys, p.storeKeys} {
+		for id, key := range keys {
+			if key.Disabled || key.ExpiresAt == nil {
+				continue
+			}
+			expiresIn := key.ExpiresAt.Sub(now)
+			switch {
+			case expiresIn <= 0:
+				p.logger.Warn("API key has expired", "keyId", id, "subject", key.Subject, "expiresAt", *key.ExpiresAt)
+			case expiresIn <= p.config.ExpiryWarning:
+				p.logger.Warn("API key expires soon", "keyId", id, "subject", key.Subject,
+					"expiresAt", *key.ExpiresAt, "expiresIn", expiresIn.Round(time.Minute).String())
+			}
+		}
+	}
+}
+
+// lookup finds the key with the given value, static keys first
+func (p *Provider) lookup(key string) (string, *KeyConfig) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for id, cfg := range p.keys {
+		if cfg.Key == key {
+			return id, cfg
+		}
+	}
+	for id, cfg := range p.storeKeys {
+		if cfg.Key == key {
+			return id, cfg
+		}
+	}
+	return "", nil
+}
+
+// storeStale reports whether store keys are due to be reloaded
+func (p *Provider) storeStale() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return time.Now().After(p.nextLoad)
+}
+
+// loadStore reloads the keys from the store. A reload for an unknown key
+// waits for a load in progress and is skipped when the store was loaded
+// recently; a reload of stale keys is left to a load in progress. When
+// loading fails the previous keys stay in use. The load is not tied to the
+// request that triggered it, whose cancellation would fail it for every
+// request waiting on it.
+func (p *Provider) loadStore(miss bool) {
+	if miss {
+		p.loadMu.Lock()
+	} else if !p.loadMu.TryLock() {
+		return
+	}
+	defer p.loadMu.Unlock()
+
+	now := time.Now()
+	if !p.storeStale() && (!miss || now.Sub(p.lastLoad) < missReloadInterval) {
+		// Loaded by a concurrent request while waiting
+		return
+	}
+	p.lastLoad = now
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeLoadTimeout)
+	defer cancel()
+	keys, err := p.config.Store.Load(ctx)
+	if err != nil {
+		p.logger.Warn("Failed to load API keys from store, keeping previous keys", "error", err)
+		p.mu.Lock()
+		if p.nextLoad.Before(now.Add(missReloadInterval)) {
+			p.nextLoad = now.Add(missReloadInterval)
+		}
+		p.mu.Unlock()
+		return
+	}
+
+	for id, key := range keys {
+		if key.Key == "" {
+			p.logger.Warn("Ignoring API key with empty key value from store", "keyId", id)
+			delete(keys, id)
+			continue
+		}
+		applyDefaults(id, key)
+	}
+
+	p.mu.Lock()
+	p.storeKeys = keys
+	p.nextLoad = now.Add(p.config.RefreshInterval)
+	p.mu.Unlock()
+	p.logger.Debug("Loaded API keys from store", "keys", len(keys))
+}
+
+// applyDefaults sets the subject and type of a key when they are not set
+func applyDefaults(id string, key *KeyConfig) {
+	if key.Subject == "" {
+		key.Subject = id
+	}
+	if key.Type == "" {
+		key.Type = "service"
+	}
+}
+
 // Refresh is not supported for API keys
 func (p *Provider) Refresh(ctx context.Context, token string) (*auth.AuthInfo, error) {
 	return nil, errors.NewError(
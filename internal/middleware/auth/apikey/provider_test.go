@@ -1,10 +1,13 @@
 package apikey
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"log/slog"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -207,7 +210,7 @@ func TestAPIKeyProvider_Authenticate(t *testing.T) {
 				Key: "secret-key-3",
 			},
 			wantErr:   true,
-			errorType: errors.ErrorTypeBadRequest,
+			errorType: errors.ErrorTypeUnauthorized,
 		},
 		{
 			name: "disabled key",
@@ -215,7 +218,7 @@ func TestAPIKeyProvider_Authenticate(t *testing.T) {
 				Key: "secret-key-4",
 			},
 			wantErr:   true,
-			errorType: errors.ErrorTypeBadRequest,
+			errorType: errors.ErrorTypeUnauthorized,
 		},
 		{
 			name: "invalid key",
@@ -223,7 +226,7 @@ func TestAPIKeyProvider_Authenticate(t *testing.T) {
 				Key: "invalid-key",
 			},
 			wantErr:   true,
-			errorType: errors.ErrorTypeBadRequest,
+			errorType: errors.ErrorTypeUnauthorized,
 		},
 		{
 			name: "device key with metadata",
@@ -626,4 +629,87 @@ func TestAPIKeyProvider_ConcurrentAccess(t *testing.T) {
 	}
 }
 
+type mockRecorder struct {
+	mu      sync.Mutex
+	reasons map[string]string
+}
+
+func (r *mockRecorder) RecordAPIKeyAuthentication(ctx context.Context, keyID, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reasons[keyID] = reason
+}
+
+func TestAPIKeyProvider_Metrics(t *testing.T) {
+	expired := time.Now().Add(-time.Minute)
+	provider, err := NewProvider(&Config{
+		Keys: map[string]*KeyConfig{
+			"active":   {Key: "active-key"},
+			"expired":  {Key: "expired-key", ExpiresAt: &expired},
+			"disabled": {Key: "disabled-key", Disabled: true},
+		},
+	}, slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	recorder := &mockRecorder{reasons: make(map[string]string)}
+	provider.WithMetrics(recorder)
+
+	for _, key := range []string{"active-key", "expired-key", "disabled-key", "unknown-key"} {
+		provider.Authenticate(context.Background(), &auth.APIKeyCredentials{Key: key})
+	}
+
+	want := map[string]string{
+		"active":   ReasonValid,
+		"expired":  ReasonExpired,
+		"disabled": ReasonDisabled,
+		"":         ReasonInvalid,
+	}
+	for keyID, reason := range want {
+		if recorder.reasons[keyID] != reason {
+			t.Errorf("Expected reason %q for key %q, got %q", reason, keyID, recorder.reasons[keyID])
+		}
+	}
+}
+
+func TestAPIKeyProvider_CheckExpiry(t *testing.T) {
+	now := time.Now()
+	soon := now.Add(2 * time.Hour)
+	later := now.Add(30 * 24 * time.Hour)
+	past := now.Add(-time.Hour)
+
+	var logs bytes.Buffer
+	provider, err := NewProvider(&Config{
+		Keys: map[string]*KeyConfig{
+			"soon":     {Key: "soon-key", ExpiresAt: &soon},
+			"later":    {Key: "later-key", ExpiresAt: &later},
+			"past":     {Key: "past-key", ExpiresAt: &past},
+			"disabled": {Key: "disabled-key", ExpiresAt: &soon, Disabled: true},
+			"forever":  {Key: "forever-key"},
+		},
+		ExpiryWarning: 24 * time.Hour,
+	}, slog.New(slog.NewTextHandler(&logs, nil)))
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	// Stop the background check so only the explicit one below logs
+	provider.Close()
+	logs.Reset()
+
+	provider.checkExpiry(now)
+
+	output := logs.String()
+	if !strings.Contains(output, `msg="API key expires soon" keyId=soon`) {
+		t.Errorf("Expected key expiring soon to be logged, got:\n%s", output)
+	}
+	if !strings.Contains(output, `msg="API key has expired" keyId=past`) {
+		t.Errorf("Expected expired key to be logged, got:\n%s", output)
+	}
+	for _, id := range []string{"later", "disabled", "forever"} {
+		if strings.Contains(output, "keyId="+id+" ") {
+			t.Errorf("Expected key %s not to be logged, got:\n%s", id, output)
+		}
+	}
+}
+
 // Helper function to create a string pointer
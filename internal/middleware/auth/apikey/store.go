@@ -0,0 +1,113 @@
+package apikey
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Store loads API keys from outside the gateway configuration, such as a
+// secret manager. Keys follow the same rules as the static keys: with
+// HashKeys set, Key holds the SHA-256 hash of the key.
+type Store interface {
+	// Load returns all keys by key ID
+	Load(ctx context.Context) (map[string]*KeyConfig, error)
+}
+
+// keyEntry is the JSON form of a key held in a store
+type keyEntry struct {
+	Key       string                 `json:"key"`
+	Subject   string                 `json:"subject"`
+	Type      string                 `json:"type"`
+	Scopes    []string               `json:"scopes"`
+	ExpiresAt *time.Time             `json:"expiresAt"` // RFC 3339
+	Metadata  map[string]interface{} `json:"metadata"`
+	Disabled  bool                   `json:"disabled"`
+}
+
+// decodeKeys converts a JSON object of key IDs to entries
+func decodeKeys(raw map[string]json.RawMessage) (map[string]*KeyConfig, error) {
+	keys := make(map[string]*KeyConfig, len(raw))
+	for id, data := range raw {
+		var entry keyEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		keys[id] = &KeyConfig{
+			Key:       entry.Key,
+			Subject:   entry.Subject,
+			Type:      entry.Type,
+			Scopes:    entry.Scopes,
+			ExpiresAt: entry.ExpiresAt,
+			Metadata:  entry.Metadata,
+			Disabled:  entry.Disabled,
+		}
+	}
+	return keys, nil
+}
+
+// HTTPStore loads API keys from an HTTP endpoint returning a JSON object of
+// key IDs to keys
+type HTTPStore struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewHTTPStore creates a store reading keys from url, sending headers with
+// each request
+func NewHTTPStore(url string, headers map[string]string, timeout time.Duration) *HTTPStore {
+	return &HTTPStore{
+		url:     url,
+		headers: headers,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Load fetches the keys from the endpoint
+func (s *HTTPStore) Load(ctx context.Context) (map[string]*KeyConfig, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := getJSON(s.client, req, &raw); err != nil {
+		return nil, err
+	}
+	return decodeKeys(raw)
+}
+
+// getJSON sends req and decodes a successful JSON response into v
+func getJSON(client *http.Client, req *http.Request, v any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &statusError{status: resp.StatusCode, body: string(body)}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// statusError is returned for unsuccessful responses from a store
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("store returned status %d: %s", e.status, e.body)
+}
@@ -0,0 +1,222 @@
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gateway/internal/middleware/auth"
+)
+
+// mockVault serves a KV version 2 secret, requiring an AppRole login
+type mockVault struct {
+	mu     sync.Mutex
+	keys   map[string]any
+	reads  int
+	logins int
+}
+
+func (v *mockVault) setKeys(keys map[string]any) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys = keys
+}
+
+// counts returns the number of secret reads and logins
+func (v *mockVault) counts() (reads, logins int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.reads, v.logins
+}
+
+func (v *mockVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/v1/auth/approle/login":
+		var login map[string]string
+		json.NewDecoder(r.Body).Decode(&login)
+		if login["role_id"] != "gateway" || login["secret_id"] != "s3cret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		v.logins++
+		json.NewEncoder(w).Encode(map[string]any{
+			"auth": map[string]any{"client_token": "approle-token", "lease_duration": 3600},
+		})
+	case "/v1/secret/data/gateway/api-keys":
+		if r.Header.Get("X-Vault-Token") != "approle-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		v.reads++
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"data":     v.keys,
+				"metadata": map[string]any{"version": 1},
+			},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newVaultProvider(t *testing.T, vault *mockVault, hashKeys bool) *Provider {
+	t.Helper()
+
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+
+	store, err := NewVaultStore(VaultConfig{
+		Address: server.URL,
+		Path:    "secret/data/gateway/api-keys",
+		AppRole: &VaultAppRole{RoleID: "gateway", SecretID: "s3cret"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	provider, err := NewProvider(&Config{HashKeys: hashKeys, Store: store, RefreshInterval: time.Hour}, slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	return provider
+}
+
+func authenticate(p *Provider, key string) (*auth.AuthInfo, error) {
+	return p.Authenticate(context.Background(), &auth.APIKeyCredentials{Key: key})
+}
+
+func TestVaultStore(t *testing.T) {
+	vault := &mockVault{keys: map[string]any{
+		"billing": map[string]any{"key": "billing-key", "subject": "billing-service", "scopes": []string{"invoices:read"}},
+		"expired": map[string]any{"key": "expired-key", "expiresAt": "2020-01-01T00:00:00Z"},
+		"revoked": map[string]any{"key": "revoked-key", "disabled": true},
+	}}
+	provider := newVaultProvider(t, vault, false)
+
+	info, err := authenticate(provider, "billing-key")
+	if err != nil {
+		t.Fatalf("Expected key from Vault to authenticate, got %v", err)
+	}
+	if info.Subject != "billing-service" || info.Type != auth.SubjectTypeService || info.Metadata["keyId"] != "billing" {
+		t.Errorf("Unexpected auth info %+v", info)
+	}
+	if _, err := authenticate(provider, "expired-key"); err == nil {
+		t.Error("Expected expired key to be rejected")
+	}
+	if _, err := authenticate(provider, "revoked-key"); err == nil {
+		t.Error("Expected disabled key to be rejected")
+	}
+	if _, logins := vault.counts(); logins != 1 {
+		t.Errorf("Expected a single AppRole login, got %d", logins)
+	}
+}
+
+func TestVaultStore_HashKeys(t *testing.T) {
+	hash := sha256.Sum256([]byte("billing-key"))
+	vault := &mockVault{keys: map[string]any{
+		"billing": map[string]any{"key": hex.EncodeToString(hash[:])},
+	}}
+	provider := newVaultProvider(t, vault, true)
+
+	if _, err := authenticate(provider, "billing-key"); err != nil {
+		t.Errorf("Expected key to match its hash, got %v", err)
+	}
+}
+
+func TestProvider_StoreRefresh(t *testing.T) {
+	vault := &mockVault{keys: map[string]any{
+		"billing": map[string]any{"key": "billing-key"},
+	}}
+	provider := newVaultProvider(t, vault, false)
+
+	if _, err := authenticate(provider, "billing-key"); err != nil {
+		t.Fatalf("Expected key to authenticate, got %v", err)
+	}
+
+	// Unknown keys do not reload the store right after a load
+	vault.setKeys(map[string]any{
+		"billing": map[string]any{"key": "billing-key"},
+		"reports": map[string]any{"key": "reports-key"},
+	})
+	if _, err := authenticate(provider, "reports-key"); err == nil {
+		t.Error("Expected miss right after a load not to reload the store")
+	}
+
+	// A key added to Vault is found on a later miss
+	provider.loadMu.Lock()
+	provider.lastLoad = time.Now().Add(-missReloadInterval)
+	provider.loadMu.Unlock()
+	if _, err := authenticate(provider, "reports-key"); err != nil {
+		t.Fatalf("Expected new key to be loaded on a miss, got %v", err)
+	}
+
+	// A key revoked in Vault is rejected once the keys are stale
+	vault.setKeys(map[string]any{"reports": map[string]any{"key": "reports-key"}})
+	provider.mu.Lock()
+	provider.nextLoad = time.Now().Add(-time.Second)
+	provider.mu.Unlock()
+	if _, err := authenticate(provider, "billing-key"); err == nil {
+		t.Error("Expected revoked key to be rejected after refresh")
+	}
+}
+
+// storeFunc adapts a function to the Store interface
+type storeFunc func(ctx context.Context) (map[string]*KeyConfig, error)
+
+func (f storeFunc) Load(ctx context.Context) (map[string]*KeyConfig, error) { return f(ctx) }
+
+func TestProvider_StoreLoadOutlivesRequest(t *testing.T) {
+	store := storeFunc(func(ctx context.Context) (map[string]*KeyConfig, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("Expected the store load to be bounded by a timeout")
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return map[string]*KeyConfig{"billing": {Key: "billing-key"}}, nil
+	})
+	provider, err := NewProvider(&Config{Store: store, RefreshInterval: time.Hour}, slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	// A canceled request does not fail the load it triggered
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := provider.Authenticate(ctx, &auth.APIKeyCredentials{Key: "billing-key"}); err != nil {
+		t.Errorf("Expected key to authenticate, got %v", err)
+	}
+}
+
+func TestHTTPStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer store-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"billing": {"key": "billing-key", "type": "user"}}`))
+	}))
+	defer server.Close()
+
+	store := NewHTTPStore(server.URL, map[string]string{"Authorization": "Bearer store-token"}, time.Second)
+	keys, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if key := keys["billing"]; key == nil || key.Key != "billing-key" || key.Type != "user" {
+		t.Errorf("Unexpected keys %+v", keys)
+	}
+
+	if _, err := NewHTTPStore(server.URL, nil, time.Second).Load(context.Background()); err == nil {
+		t.Error("Expected unauthorized load to fail")
+	}
+}
@@ -0,0 +1,191 @@
+package apikey
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VaultConfig configures reading API keys from a HashiCorp Vault secret
+type VaultConfig struct {
+	// Address of the Vault server, e.g. https://vault.example.com:8200
+	Address string
+	// Path is the API path of the secret below /v1/. Both KV version 1
+	// (secret/gateway/api-keys) and version 2 (secret/data/gateway/api-keys)
+	// secrets are supported.
+	Path string
+	// Namespace is the Vault Enterprise namespace, if any
+	Namespace string
+	// Token authenticates to Vault; TokenFile is read when it is empty
+	Token     string
+	TokenFile string
+	// AppRole logs in with the AppRole auth method when no token is set
+	AppRole *VaultAppRole
+	// Timeout bounds each request to Vault
+	Timeout time.Duration
+}
+
+// VaultAppRole holds AppRole credentials
+type VaultAppRole struct {
+	RoleID   string
+	SecretID string
+	// Mount is the auth mount path (default: approle)
+	Mount string
+}
+
+// VaultStore loads API keys from a Vault secret. Each field of the secret
+// is a key ID whose value is a JSON object with the key, subject, type,
+// scopes, expiresAt, metadata and disabled fields.
+type VaultStore struct {
+	config VaultConfig
+	client *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time // Zero for tokens that are not renewed by login
+}
+
+// NewVaultStore creates a store reading keys from the Vault secret in config
+func NewVaultStore(config VaultConfig) (*VaultStore, error) {
+	if config.Address == "" || config.Path == "" {
+		return nil, fmt.Errorf("vault address and path are required")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.AppRole != nil && config.AppRole.Mount == "" {
+		config.AppRole.Mount = "approle"
+	}
+
+	s := &VaultStore{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		token:  config.Token,
+	}
+	if s.token == "" && config.TokenFile != "" {
+		data, err := os.ReadFile(config.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("read vault token: %w", err)
+		}
+		s.token = strings.TrimSpace(string(data))
+	}
+	if s.token == "" && config.AppRole == nil {
+		return nil, fmt.Errorf("vault token, tokenFile or appRole is required")
+	}
+	return s, nil
+}
+
+// Load reads the secret and decodes its keys. An AppRole token is renewed
+// by logging in again when it expires or is rejected.
+func (s *VaultStore) Load(ctx context.Context) (map[string]*KeyConfig, error) {
+	var secret struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	err := s.read(ctx, &secret)
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.status == http.StatusForbidden && s.config.AppRole != nil {
+		s.mu.Lock()
+		s.token = ""
+		s.mu.Unlock()
+		err = s.read(ctx, &secret)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read vault secret %s: %w", s.config.Path, err)
+	}
+
+	// KV version 2 nests the secret's fields under data.data
+	data := secret.Data
+	if nested, ok := data["data"]; ok {
+		if _, ok := data["metadata"]; ok {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return nil, fmt.Errorf("decode vault secret %s: %w", s.config.Path, err)
+			}
+		}
+	}
+	return decodeKeys(data)
+}
+
+// read fetches the secret into v
+func (s *VaultStore) read(ctx context.Context, v any) error {
+	token, err := s.currentToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url(s.config.Path), nil)
+	if err != nil {
+		return err
+	}
+	s.setHeaders(req)
+	req.Header.Set("X-Vault-Token", token)
+	return getJSON(s.client, req, v)
+}
+
+// currentToken returns the Vault token, logging in with AppRole when there
+// is none or it has expired
+func (s *VaultStore) currentToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.tokenExpiry.IsZero() || time.Now().Before(s.tokenExpiry)) {
+		return s.token, nil
+	}
+	if s.config.AppRole == nil {
+		return s.token, nil
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"role_id":   s.config.AppRole.RoleID,
+		"secret_id": s.config.AppRole.SecretID,
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url("auth/"+s.config.AppRole.Mount+"/login"), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	s.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	var login struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	if err := getJSON(s.client, req, &login); err != nil {
+		return "", fmt.Errorf("vault approle login: %w", err)
+	}
+	if login.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault approle login: no token returned")
+	}
+
+	s.token = login.Auth.ClientToken
+	s.tokenExpiry = time.Time{}
+	if lease := time.Duration(login.Auth.LeaseDuration) * time.Second; lease > 0 {
+		// Log in again a little before the token expires
+		s.tokenExpiry = time.Now().Add(lease * 9 / 10)
+	}
+	return s.token, nil
+}
+
+// url returns the URL of a Vault API path
+func (s *VaultStore) url(path string) string {
+	return strings.TrimSuffix(s.config.Address, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+}
+
+// setHeaders sets the headers common to all Vault requests
+func (s *VaultStore) setHeaders(req *http.Request) {
+	if s.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.config.Namespace)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"gateway/internal/config"
 	"gateway/internal/core"
 	"gateway/pkg/factory"
+	"gateway/pkg/routing"
 )
 
 // ComponentName is the name used to register this component
@@ -46,6 +47,9 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 	if len(authConfig.Providers) == 0 {
 		return fmt.Errorf("no auth providers configured")
 	}
+	if _, err := routing.NewPathMatcher(authConfig.SkipPaths); err != nil {
+		return fmt.Errorf("skip paths: %w", err)
+	}
 	
 	// Configuration will be used to create middleware during Build
 	return nil
@@ -87,11 +91,12 @@ func (c *Component) Build() core.Middleware {
 	
 	// Create middleware config
 	authConfig := &Config{
-		Required:       c.config.Required,
-		Providers:      c.config.Providers,
-		SkipPaths:      c.config.SkipPaths,
-		RequiredScopes: c.config.RequiredScopes,
-		StoreAuthInfo:  true,
+		EnforcementMode: c.config.EnforcementMode,
+		Required:        c.config.Required,
+		Providers:       c.config.Providers,
+		SkipPaths:       c.config.SkipPaths,
+		RequiredScopes:  c.config.RequiredScopes,
+		StoreAuthInfo:   true,
 	}
 	
 	// Create middleware instance
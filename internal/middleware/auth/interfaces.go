@@ -51,6 +51,8 @@ type AuthInfo struct {
 	Scopes []string
 	// Metadata contains additional information
 	Metadata map[string]interface{}
+	// Claims are the verified token claims, set by the JWT provider
+	Claims map[string]interface{}
 	// ExpiresAt is when the auth expires
 	ExpiresAt *time.Time
 	// Token is the auth token (for refresh)
@@ -74,3 +76,11 @@ type Extractor interface {
 	// Extract extracts credentials from request context
 	Extract(ctx context.Context, headers map[string][]string) (Credentials, error)
 }
+
+// URLExtractor is an Extractor that can also read credentials from the
+// query of the request URL
+type URLExtractor interface {
+	Extractor
+	// ExtractURL extracts credentials from request headers and URL
+	ExtractURL(ctx context.Context, headers map[string][]string, rawURL string) (Credentials, error)
+}
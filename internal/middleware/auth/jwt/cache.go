@@ -0,0 +1,96 @@
+package jwt
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"gateway/internal/middleware/auth"
+)
+
+// DefaultCacheMaxTTL is how long validated tokens are cached at most
+// unless configured
+const DefaultCacheMaxTTL = 5 * time.Minute
+
+// tokenCache keeps the auth info of validated tokens, evicting the least
+// recently used entry when full. Tokens are keyed by their SHA-256 hash,
+// so the cache holds no bearer tokens as keys.
+type tokenCache struct {
+	mu      sync.Mutex
+	size    int
+	maxTTL  time.Duration
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List // Front is the most recently used
+}
+
+// cacheEntry is a cached token
+type cacheEntry struct {
+	key     [sha256.Size]byte
+	info    *auth.AuthInfo
+	expires time.Time
+}
+
+func newTokenCache(size int, maxTTL time.Duration) *tokenCache {
+	if maxTTL <= 0 {
+		maxTTL = DefaultCacheMaxTTL
+	}
+	return &tokenCache{
+		size:    size,
+		maxTTL:  maxTTL,
+		entries: make(map[[sha256.Size]byte]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// get returns a copy of the auth info of a cached token that has not
+// expired
+func (c *tokenCache) get(token string, now time.Time) (*auth.AuthInfo, bool) {
+	key := sha256.Sum256([]byte(token))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !now.Before(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+
+	info := *entry.info
+	return &info, true
+}
+
+// put caches the auth info of a validated token until the token expires,
+// or for maxTTL at most
+func (c *tokenCache) put(token string, info *auth.AuthInfo, now time.Time) {
+	expires := now.Add(c.maxTTL)
+	if info.ExpiresAt != nil && info.ExpiresAt.Before(expires) {
+		expires = *info.ExpiresAt
+	}
+	if !now.Before(expires) {
+		return
+	}
+	key := sha256.Sum256([]byte(token))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = &cacheEntry{key: key, info: info, expires: expires}
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, info: info, expires: expires})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
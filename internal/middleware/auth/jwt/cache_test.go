@@ -0,0 +1,44 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"gateway/internal/middleware/auth"
+)
+
+func TestTokenCache(t *testing.T) {
+	now := time.Now()
+	soon := now.Add(time.Minute)
+	cache := newTokenCache(2, time.Hour)
+
+	cache.put("a", &auth.AuthInfo{Subject: "a"}, now)
+	cache.put("b", &auth.AuthInfo{Subject: "b", ExpiresAt: &soon}, now)
+	if _, ok := cache.get("a", now); !ok {
+		t.Fatal("Expected a to be cached")
+	}
+
+	// b is the least recently used
+	cache.put("c", &auth.AuthInfo{Subject: "c"}, now)
+	if _, ok := cache.get("b", now); ok {
+		t.Error("Expected b to be evicted")
+	}
+	if info, ok := cache.get("c", now); !ok || info.Subject != "c" {
+		t.Errorf("Expected c to be cached, got %v", info)
+	}
+
+	// Entries expire after maxTTL or at the token expiry
+	if _, ok := cache.get("a", now.Add(time.Hour)); ok {
+		t.Error("Expected a to expire after maxTTL")
+	}
+	cache.put("d", &auth.AuthInfo{Subject: "d", ExpiresAt: &soon}, now)
+	if _, ok := cache.get("d", soon); ok {
+		t.Error("Expected d to expire with its token")
+	}
+
+	past := now.Add(-time.Second)
+	cache.put("e", &auth.AuthInfo{Subject: "e", ExpiresAt: &past}, now)
+	if _, ok := cache.get("e", now); ok {
+		t.Error("Expected expired token not to be cached")
+	}
+}
@@ -0,0 +1,31 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+
+	"gateway/internal/middleware/auth"
+	"gateway/internal/middleware/auth/token"
+)
+
+func TestExtractor(t *testing.T) {
+	e := NewExtractor(
+		token.Source{Type: token.SourceHeader, Name: "Authorization", Scheme: "Bearer"},
+		token.Source{Type: token.SourceQuery, Name: "access_token"},
+	)
+
+	creds, err := e.ExtractURL(context.Background(), nil, "/events?access_token=abc")
+	if err != nil {
+		t.Fatalf("ExtractURL() error = %v", err)
+	}
+	if bearer, ok := creds.(*auth.BearerCredentials); !ok || bearer.Token != "abc" {
+		t.Errorf("ExtractURL() = %#v, want bearer abc", creds)
+	}
+
+	// Without the URL only headers are read
+	if _, err := e.Extract(context.Background(), map[string][]string{"Cookie": {"access_token=abc"}}); err == nil {
+		t.Error("Extract() should fail without a header token")
+	}
+
+	var _ auth.URLExtractor = e
+}
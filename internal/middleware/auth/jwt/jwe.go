@@ -0,0 +1,114 @@
+package jwt
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	jose "github.com/go-jose/go-jose/v4"
+)
+
+// DefaultDecryptionAlgorithm is the JWE key management algorithm used with a
+// private key unless configured
+const DefaultDecryptionAlgorithm = "RSA-OAEP-256"
+
+// decryptionAlgorithms are the supported JWE key management algorithms, and
+// whether they take a private key rather than a shared secret
+var decryptionAlgorithms = map[jose.KeyAlgorithm]bool{
+	jose.RSA_OAEP:       true,
+	jose.RSA_OAEP_256:   true,
+	jose.ECDH_ES:        true,
+	jose.ECDH_ES_A128KW: true,
+	jose.ECDH_ES_A192KW: true,
+	jose.ECDH_ES_A256KW: true,
+	jose.A128KW:         false,
+	jose.A192KW:         false,
+	jose.A256KW:         false,
+	jose.A128GCMKW:      false,
+	jose.A192GCMKW:      false,
+	jose.A256GCMKW:      false,
+	jose.DIRECT:         false,
+}
+
+// contentEncryptions are the JWE content encryption algorithms accepted
+var contentEncryptions = []jose.ContentEncryption{
+	jose.A128CBC_HS256, jose.A192CBC_HS384, jose.A256CBC_HS512,
+	jose.A128GCM, jose.A192GCM, jose.A256GCM,
+}
+
+// decrypter decrypts encrypted tokens (JWE) into the signed token they carry
+type decrypter struct {
+	algorithm jose.KeyAlgorithm
+	key       interface{}
+}
+
+// newDecrypter creates a decrypter from the decryption settings of config,
+// returning nil when none are set
+func newDecrypter(config *Config) (*decrypter, error) {
+	if config.DecryptionKey == "" && config.DecryptionSecret == "" {
+		return nil, nil
+	}
+
+	name := config.DecryptionAlgorithm
+	if name == "" {
+		name = DefaultDecryptionAlgorithm
+	}
+	algorithm := jose.KeyAlgorithm(name)
+	private, ok := decryptionAlgorithms[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported JWE key algorithm %q", name)
+	}
+
+	if !private {
+		if config.DecryptionSecret == "" {
+			return nil, fmt.Errorf("JWE key algorithm %s requires decryptionSecret", name)
+		}
+		return &decrypter{algorithm: algorithm, key: []byte(config.DecryptionSecret)}, nil
+	}
+	if config.DecryptionKey == "" {
+		return nil, fmt.Errorf("JWE key algorithm %s requires decryptionKey", name)
+	}
+	key, err := parsePrivateKey(config.DecryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("parsing JWE decryption key: %w", err)
+	}
+	return &decrypter{algorithm: algorithm, key: key}, nil
+}
+
+// parsePrivateKey parses a PEM encoded PKCS #8, PKCS #1 or EC private key
+func parsePrivateKey(data string) (interface{}, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported private key type %q", block.Type)
+}
+
+// decrypt returns the signed token an encrypted token carries
+func (d *decrypter) decrypt(token string) (string, error) {
+	jwe, err := jose.ParseEncryptedCompact(token, []jose.KeyAlgorithm{d.algorithm}, contentEncryptions)
+	if err != nil {
+		return "", err
+	}
+	payload, err := jwe.Decrypt(d.key)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
+}
+
+// isEncrypted reports whether a token is in JWE compact serialization,
+// which has five parts where a signed token has three
+func isEncrypted(token string) bool {
+	return strings.Count(token, ".") == 4
+}
@@ -0,0 +1,150 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"log/slog"
+	"testing"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+
+	"gateway/internal/middleware/auth"
+)
+
+// encrypt wraps a signed token in a JWE for key
+func encrypt(t *testing.T, signed string, algorithm jose.KeyAlgorithm, key interface{}) string {
+	t.Helper()
+	encrypter, err := jose.NewEncrypter(jose.A256GCM, jose.Recipient{Algorithm: algorithm, Key: key},
+		(&jose.EncrypterOptions{}).WithContentType("JWT"))
+	if err != nil {
+		t.Fatalf("Failed to create encrypter: %v", err)
+	}
+	jwe, err := encrypter.Encrypt([]byte(signed))
+	if err != nil {
+		t.Fatalf("Failed to encrypt token: %v", err)
+	}
+	compact, err := jwe.CompactSerialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize JWE: %v", err)
+	}
+	return compact
+}
+
+func TestJWTProvider_EncryptedTokens(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal RSA key: %v", err)
+	}
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	directSecret := "0123456789abcdef0123456789abcdef"
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "user123",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		config  Config
+		token   string
+		wantErr bool
+	}{
+		{
+			name:   "RSA-OAEP-256",
+			config: Config{DecryptionKey: keyPEM},
+			token:  encrypt(t, signed, jose.RSA_OAEP_256, &privateKey.PublicKey),
+		},
+		{
+			name:   "direct",
+			config: Config{DecryptionSecret: directSecret, DecryptionAlgorithm: "dir"},
+			token:  encrypt(t, signed, jose.DIRECT, []byte(directSecret)),
+		},
+		{
+			name:   "signed token unchanged",
+			config: Config{DecryptionKey: keyPEM},
+			token:  signed,
+		},
+		{
+			name:    "wrong key",
+			config:  Config{DecryptionKey: keyPEM},
+			token:   encrypt(t, signed, jose.RSA_OAEP_256, &otherKey.PublicKey),
+			wantErr: true,
+		},
+		{
+			name:    "unexpected algorithm",
+			config:  Config{DecryptionKey: keyPEM, DecryptionAlgorithm: "RSA-OAEP"},
+			token:   encrypt(t, signed, jose.RSA_OAEP_256, &privateKey.PublicKey),
+			wantErr: true,
+		},
+		{
+			name:    "decryption not configured",
+			token:   encrypt(t, signed, jose.RSA_OAEP_256, &privateKey.PublicKey),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.SigningMethod = "HS256"
+			config.Secret = testSecret
+			provider, err := NewProvider(&config, slog.Default())
+			if err != nil {
+				t.Fatalf("Failed to create provider: %v", err)
+			}
+
+			authInfo, err := provider.Authenticate(context.Background(), &auth.BearerCredentials{Token: tt.token})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if authInfo.Subject != "user123" {
+				t.Errorf("Expected subject user123, got %s", authInfo.Subject)
+			}
+		})
+	}
+}
+
+func TestJWTProvider_DecryptionConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "unknown algorithm", config: Config{DecryptionSecret: "secret", DecryptionAlgorithm: "none"}},
+		{name: "missing secret", config: Config{DecryptionKey: "key", DecryptionAlgorithm: "A256KW"}},
+		{name: "missing private key", config: Config{DecryptionSecret: "secret"}},
+		{name: "invalid private key", config: Config{DecryptionKey: "not a key"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.SigningMethod = "HS256"
+			config.Secret = testSecret
+			if _, err := NewProvider(&config, slog.Default()); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
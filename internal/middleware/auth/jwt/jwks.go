@@ -0,0 +1,238 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"gateway/pkg/errors"
+)
+
+// Bounds of the JWKS fetch schedule
+const (
+	// jwksMinBackoff is the wait after the first failed fetch; it doubles
+	// with every failure up to jwksMaxBackoff
+	jwksMinBackoff = time.Second
+	jwksMaxBackoff = 5 * time.Minute
+	// jwksRefetchInterval is the least time between fetches, so tokens with
+	// unknown key IDs cannot hammer the endpoint
+	jwksRefetchInterval = 10 * time.Second
+)
+
+// jwksCache caches JWKS keys. Keys older than the TTL are still served
+// while they are refreshed in the background, and the last fetched keys are
+// kept while the endpoint fails. Tokens signed with a key ID not in the set
+// trigger a refetch before they are rejected. Concurrent fetches are
+// coalesced into one.
+type jwksCache struct {
+	endpoint        string
+	client          *http.Client
+	ttl             time.Duration
+	refetchInterval time.Duration
+	logger          *slog.Logger
+
+	mu          sync.RWMutex
+	keys        map[string]interface{}
+	lastUpdate  time.Time
+	nextAttempt time.Time     // No fetch before this time
+	backoff     time.Duration // Wait after the last failed fetch
+	lastErr     error         // Error of the last fetch
+	fetching    chan struct{} // Closed when the running fetch completes
+}
+
+func newJWKSCache(endpoint string, ttl time.Duration, client *http.Client, logger *slog.Logger) *jwksCache {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &jwksCache{
+		endpoint:        endpoint,
+		client:          client,
+		keys:            make(map[string]interface{}),
+		ttl:             ttl,
+		refetchInterval: jwksRefetchInterval,
+		logger:          logger.With("component", "jwks", "endpoint", endpoint),
+	}
+}
+
+func (c *jwksCache) getKey(kid string) (interface{}, error) {
+	c.mu.RLock()
+	key, ok := c.keys[kid]
+	stale := time.Since(c.lastUpdate) >= c.ttl
+	c.mu.RUnlock()
+
+	if ok {
+		if stale {
+			c.refresh(false)
+		}
+		return key, nil
+	}
+
+	// The key may have been rotated in since the last fetch
+	if err := c.refresh(true); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	key, ok = c.keys[kid]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("key %s not found in JWKS", kid)
+	}
+
+	return key, nil
+}
+
+// refresh fetches the keys unless a fetch is running or the next one is not
+// due yet. With wait set, it returns once the keys are fetched, with the
+// error of the fetch; otherwise the fetch runs in the background.
+func (c *jwksCache) refresh(wait bool) error {
+	c.mu.Lock()
+	if done := c.fetching; done != nil {
+		c.mu.Unlock()
+		if !wait {
+			return nil
+		}
+		<-done
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		return c.lastErr
+	}
+	if time.Now().Before(c.nextAttempt) {
+		defer c.mu.Unlock()
+		return c.lastErr
+	}
+	done := make(chan struct{})
+	c.fetching = done
+	c.mu.Unlock()
+
+	if !wait {
+		go c.fetch(done)
+		return nil
+	}
+	return c.fetch(done)
+}
+
+// fetch replaces the keys with the endpoint's key set, keeping the current
+// keys and backing off when it fails, and closes done
+func (c *jwksCache) fetch(done chan struct{}) error {
+	keys, err := c.fetchKeys()
+
+	c.mu.Lock()
+	now := time.Now()
+	if err != nil {
+		c.backoff = min(max(c.backoff*2, jwksMinBackoff), jwksMaxBackoff)
+		c.nextAttempt = now.Add(c.backoff)
+		c.logger.Warn("JWKS fetch failed, keeping previous keys",
+			"error", err,
+			"keys", len(c.keys),
+			"retry_in", c.backoff,
+		)
+	} else {
+		c.keys = keys
+		c.lastUpdate = now
+		c.backoff = 0
+		c.nextAttempt = now.Add(c.refetchInterval)
+	}
+	c.lastErr = err
+	c.fetching = nil
+	c.mu.Unlock()
+
+	close(done)
+	return err
+}
+
+// fetchKeys fetches and parses the key set
+func (c *jwksCache) fetchKeys() (map[string]interface{}, error) {
+	resp, err := c.client.Get(c.endpoint)
+	if err != nil {
+		return nil, errors.NewError(errors.ErrorTypeUnavailable, "failed to fetch JWKS").WithCause(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.NewError(errors.ErrorTypeUnavailable, fmt.Sprintf("JWKS endpoint returned status %d", resp.StatusCode))
+	}
+
+	var jwks struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, errors.NewError(errors.ErrorTypeInternal, "failed to decode JWKS response").WithCause(err)
+	}
+
+	keys := make(map[string]interface{})
+
+	// Parse each key
+	for _, keyData := range jwks.Keys {
+		var keyInfo struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+		}
+
+		if err := json.Unmarshal(keyData, &keyInfo); err != nil {
+			continue
+		}
+
+		// Only process signing keys
+		if keyInfo.Use != "" && keyInfo.Use != "sig" {
+			continue
+		}
+
+		// Parse based on key type
+		switch keyInfo.Kty {
+		case "RSA":
+			key, err := parseRSAKey(keyData)
+			if err == nil && keyInfo.Kid != "" {
+				keys[keyInfo.Kid] = key
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// parseRSAKey parses an RSA key from JWKS
+func parseRSAKey(data []byte) (*rsa.PublicKey, error) {
+	var key struct {
+		N string `json:"n"`
+		E string `json:"e"`
+	}
+
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, err
+	}
+
+	// Decode base64url encoded modulus
+	nBytes, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil {
+		return nil, fmt.Errorf("decoding modulus: %w", err)
+	}
+
+	// Decode base64url encoded exponent
+	eBytes, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil {
+		return nil, fmt.Errorf("decoding exponent: %w", err)
+	}
+
+	// Convert to big integers
+	n := new(big.Int).SetBytes(nBytes)
+	e := new(big.Int).SetBytes(eBytes)
+
+	// Create RSA public key
+	pubKey := &rsa.PublicKey{
+		N: n,
+		E: int(e.Int64()),
+	}
+
+	return pubKey, nil
+}
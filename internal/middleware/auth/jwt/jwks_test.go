@@ -0,0 +1,150 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// jwksServer serves a key set with the given key IDs, or fails while
+// failing is set
+type jwksServer struct {
+	*httptest.Server
+	key     *rsa.PublicKey
+	mu      sync.Mutex
+	kids    []string
+	failing bool
+	fetches atomic.Int32
+	gate    chan struct{} // Fetches block until it is closed, when set
+}
+
+func newJWKSServer(t *testing.T, kids ...string) *jwksServer {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	s := &jwksServer{key: &private.PublicKey, kids: kids}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		gate, failing, kids := s.gate, s.failing, s.kids
+		s.mu.Unlock()
+		if gate != nil {
+			<-gate
+		}
+		if failing {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		keys := make([]map[string]string, 0, len(kids))
+		for _, kid := range kids {
+			keys = append(keys, map[string]string{
+				"kid": kid,
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) set(fn func(s *jwksServer)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s)
+}
+
+func TestJWKSCache_RefetchUnknownKey(t *testing.T) {
+	server := newJWKSServer(t, "k1")
+	cache := newJWKSCache(server.URL, time.Hour, server.Client(), slog.Default())
+	cache.refetchInterval = 0
+
+	if _, err := cache.getKey("k1"); err != nil {
+		t.Fatalf("getKey(k1) failed: %v", err)
+	}
+
+	// The IdP rotates in k2 before the keys expire
+	server.set(func(s *jwksServer) { s.kids = []string{"k1", "k2"} })
+	if _, err := cache.getKey("k2"); err != nil {
+		t.Fatalf("getKey(k2) after rotation failed: %v", err)
+	}
+	if _, err := cache.getKey("unknown"); err == nil {
+		t.Error("Expected error for a key not in the set")
+	}
+	if got := server.fetches.Load(); got != 3 {
+		t.Errorf("Expected 3 fetches, got %d", got)
+	}
+}
+
+func TestJWKSCache_CoalescesFetches(t *testing.T) {
+	server := newJWKSServer(t, "k1")
+	gate := make(chan struct{})
+	server.set(func(s *jwksServer) { s.gate = gate })
+	cache := newJWKSCache(server.URL, time.Hour, server.Client(), slog.Default())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.getKey("k1")
+			errs <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(gate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("getKey failed: %v", err)
+		}
+	}
+	if got := server.fetches.Load(); got != 1 {
+		t.Errorf("Expected 1 fetch, got %d", got)
+	}
+}
+
+func TestJWKSCache_KeepsKeysOnFailure(t *testing.T) {
+	server := newJWKSServer(t, "k1")
+	cache := newJWKSCache(server.URL, time.Millisecond, server.Client(), slog.Default())
+	cache.refetchInterval = 0
+
+	if _, err := cache.getKey("k1"); err != nil {
+		t.Fatalf("getKey failed: %v", err)
+	}
+	server.set(func(s *jwksServer) { s.failing = true })
+	time.Sleep(5 * time.Millisecond)
+
+	// Expired keys are served while the background refresh fails
+	for range 5 {
+		if _, err := cache.getKey("k1"); err != nil {
+			t.Fatalf("Expected last-good key to be served, got %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Failed fetches back off instead of being retried on every request
+	if got := server.fetches.Load(); got != 2 {
+		t.Errorf("Expected 2 fetches during backoff, got %d", got)
+	}
+	if _, err := cache.getKey("k2"); err == nil {
+		t.Error("Expected error for an unknown key while the endpoint fails")
+	}
+}
@@ -2,15 +2,10 @@ package jwt
 
 import (
 	"context"
-	"crypto/rsa"
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"log/slog"
-	"math/big"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -41,6 +36,18 @@ type Config struct {
 	ScopeClaim string `yaml:"scopeClaim"`
 	// SubjectClaim is the claim containing the subject
 	SubjectClaim string `yaml:"subjectClaim"`
+	// CacheSize is how many validated tokens are cached; 0 disables caching
+	CacheSize int `yaml:"cacheSize"`
+	// CacheMaxTTL caps how long a token is cached, below its expiry
+	CacheMaxTTL time.Duration `yaml:"cacheMaxTTL"`
+	// DecryptionKey is the PEM private key decrypting encrypted tokens (JWE)
+	DecryptionKey string `yaml:"decryptionKey"`
+	// DecryptionSecret is the shared key for AES key wrap and direct JWE
+	// encryption
+	DecryptionSecret string `yaml:"decryptionSecret"`
+	// DecryptionAlgorithm is the JWE key management algorithm (default:
+	// RSA-OAEP-256)
+	DecryptionAlgorithm string `yaml:"decryptionAlgorithm"`
 }
 
 // Provider implements JWT authentication
@@ -49,6 +56,8 @@ type Provider struct {
 	logger     *slog.Logger
 	publicKey  interface{}
 	jwks       *jwksCache
+	cache      *tokenCache
+	decrypter  *decrypter
 	httpClient *http.Client
 }
 
@@ -95,7 +104,19 @@ func NewProvider(config *Config, logger *slog.Logger) (*Provider, error) {
 
 	// Initialize JWKS cache if endpoint provided
 	if config.JWKSEndpoint != "" {
-		p.jwks = newJWKSCache(config.JWKSEndpoint, config.JWKSCacheDuration, p.httpClient)
+		p.jwks = newJWKSCache(config.JWKSEndpoint, config.JWKSCacheDuration, p.httpClient, logger)
+	}
+
+	// Decrypt encrypted tokens if configured
+	decrypter, err := newDecrypter(config)
+	if err != nil {
+		return nil, errors.NewError(errors.ErrorTypeInternal, "invalid JWE decryption configuration").WithCause(err)
+	}
+	p.decrypter = decrypter
+
+	// Cache validated tokens if configured
+	if config.CacheSize > 0 {
+		p.cache = newTokenCache(config.CacheSize, config.CacheMaxTTL)
 	}
 
 	return p, nil
@@ -106,7 +127,8 @@ func (p *Provider) Name() string {
 	return "jwt"
 }
 
-// Authenticate validates a JWT token
+// Authenticate validates a JWT token. With caching enabled, tokens
+// validated before are not verified again until they expire.
 func (p *Provider) Authenticate(ctx context.Context, credentials auth.Credentials) (*auth.AuthInfo, error) {
 	bearerCreds, ok := credentials.(*auth.BearerCredentials)
 	if !ok {
@@ -116,8 +138,44 @@ func (p *Provider) Authenticate(ctx context.Context, credentials auth.Credential
 		)
 	}
 
+	if p.cache == nil {
+		return p.validate(bearerCreds)
+	}
+	now := time.Now()
+	if authInfo, ok := p.cache.get(bearerCreds.Token, now); ok {
+		return authInfo, nil
+	}
+	authInfo, err := p.validate(bearerCreds)
+	if err != nil {
+		return nil, err
+	}
+	cached := *authInfo
+	p.cache.put(bearerCreds.Token, &cached, now)
+	return authInfo, nil
+}
+
+// validate parses and verifies a token, decrypting encrypted tokens first
+func (p *Provider) validate(bearerCreds *auth.BearerCredentials) (*auth.AuthInfo, error) {
+	signed := bearerCreds.Token
+	if isEncrypted(signed) {
+		if p.decrypter == nil {
+			return nil, errors.NewError(
+				errors.ErrorTypeBadRequest,
+				"encrypted tokens are not accepted",
+			)
+		}
+		decrypted, err := p.decrypter.decrypt(signed)
+		if err != nil {
+			return nil, errors.NewError(
+				errors.ErrorTypeBadRequest,
+				"invalid encrypted token",
+			).WithCause(err)
+		}
+		signed = decrypted
+	}
+
 	// Parse token
-	token, err := jwt.Parse(bearerCreds.Token, p.keyFunc)
+	token, err := jwt.Parse(signed, p.keyFunc)
 	if err != nil {
 		return nil, errors.NewError(
 			errors.ErrorTypeBadRequest,
@@ -187,6 +245,7 @@ func (p *Provider) Authenticate(ctx context.Context, credentials auth.Credential
 		Type:      auth.SubjectTypeUser,
 		Scopes:    scopes,
 		Metadata:  make(map[string]interface{}),
+		Claims:    claims,
 		ExpiresAt: expiresAt,
 		Token:     bearerCreds.Token,
 	}
@@ -294,143 +353,3 @@ func (p *Provider) extractScopes(claims jwt.MapClaims) []string {
 
 	return scopes
 }
-
-// jwksCache caches JWKS keys
-type jwksCache struct {
-	endpoint   string
-	client     *http.Client
-	keys       map[string]interface{}
-	mu         sync.RWMutex
-	lastUpdate time.Time
-	ttl        time.Duration
-}
-
-func newJWKSCache(endpoint string, ttl time.Duration, client *http.Client) *jwksCache {
-	return &jwksCache{
-		endpoint: endpoint,
-		client:   client,
-		keys:     make(map[string]interface{}),
-		ttl:      ttl,
-	}
-}
-
-func (c *jwksCache) getKey(kid string) (interface{}, error) {
-	c.mu.RLock()
-	if time.Since(c.lastUpdate) < c.ttl {
-		key, ok := c.keys[kid]
-		c.mu.RUnlock()
-		if ok {
-			return key, nil
-		}
-	} else {
-		c.mu.RUnlock()
-	}
-
-	// Refresh keys
-	if err := c.refresh(); err != nil {
-		return nil, err
-	}
-
-	c.mu.RLock()
-	key, ok := c.keys[kid]
-	c.mu.RUnlock()
-
-	if !ok {
-		return nil, fmt.Errorf("key %s not found in JWKS", kid)
-	}
-
-	return key, nil
-}
-
-func (c *jwksCache) refresh() error {
-	resp, err := c.client.Get(c.endpoint)
-	if err != nil {
-		return errors.NewError(errors.ErrorTypeUnavailable, "failed to fetch JWKS").WithCause(err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return errors.NewError(errors.ErrorTypeUnavailable, fmt.Sprintf("JWKS endpoint returned status %d", resp.StatusCode))
-	}
-
-	var jwks struct {
-		Keys []json.RawMessage `json:"keys"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
-		return errors.NewError(errors.ErrorTypeInternal, "failed to decode JWKS response").WithCause(err)
-	}
-
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	// Clear old keys
-	c.keys = make(map[string]interface{})
-
-	// Parse each key
-	for _, keyData := range jwks.Keys {
-		var keyInfo struct {
-			Kid string `json:"kid"`
-			Kty string `json:"kty"`
-			Alg string `json:"alg"`
-			Use string `json:"use"`
-		}
-
-		if err := json.Unmarshal(keyData, &keyInfo); err != nil {
-			continue
-		}
-
-		// Only process signing keys
-		if keyInfo.Use != "" && keyInfo.Use != "sig" {
-			continue
-		}
-
-		// Parse based on key type
-		switch keyInfo.Kty {
-		case "RSA":
-			key, err := parseRSAKey(keyData)
-			if err == nil && keyInfo.Kid != "" {
-				c.keys[keyInfo.Kid] = key
-			}
-		}
-	}
-
-	c.lastUpdate = time.Now()
-	return nil
-}
-
-// parseRSAKey parses an RSA key from JWKS
-func parseRSAKey(data []byte) (*rsa.PublicKey, error) {
-	var key struct {
-		N string `json:"n"`
-		E string `json:"e"`
-	}
-
-	if err := json.Unmarshal(data, &key); err != nil {
-		return nil, err
-	}
-
-	// Decode base64url encoded modulus
-	nBytes, err := base64.RawURLEncoding.DecodeString(key.N)
-	if err != nil {
-		return nil, fmt.Errorf("decoding modulus: %w", err)
-	}
-
-	// Decode base64url encoded exponent
-	eBytes, err := base64.RawURLEncoding.DecodeString(key.E)
-	if err != nil {
-		return nil, fmt.Errorf("decoding exponent: %w", err)
-	}
-
-	// Convert to big integers
-	n := new(big.Int).SetBytes(nBytes)
-	e := new(big.Int).SetBytes(eBytes)
-
-	// Create RSA public key
-	pubKey := &rsa.PublicKey{
-		N: n,
-		E: int(e.Int64()),
-	}
-
-	return pubKey, nil
-}
@@ -707,3 +707,46 @@ func TestJWTProvider_WrongSigningMethod(t *testing.T) {
 		t.Errorf("Expected error type %s, got %s", errors.ErrorTypeBadRequest, gwErr.Type)
 	}
 }
+
+func TestJWTProvider_Cache(t *testing.T) {
+	provider, err := NewProvider(&Config{
+		SigningMethod: "HS256",
+		Secret:        testSecret,
+		Issuer:        "test-issuer",
+		CacheSize:     10,
+	}, slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	sign := func(claims jwt.MapClaims) string {
+		tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return tokenString
+	}
+	valid := sign(jwt.MapClaims{"iss": "test-issuer", "sub": "user123", "exp": time.Now().Add(time.Hour).Unix()})
+	wrongIssuer := sign(jwt.MapClaims{"iss": "other", "sub": "user123", "exp": time.Now().Add(time.Hour).Unix()})
+
+	if _, err := provider.Authenticate(context.Background(), &auth.BearerCredentials{Token: valid}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := provider.Authenticate(context.Background(), &auth.BearerCredentials{Token: wrongIssuer}); err == nil {
+		t.Fatal("Expected error for wrong issuer")
+	}
+
+	// Rotate the key: only a token that is not verified again still passes
+	provider.publicKey = []byte("rotated-secret")
+
+	authInfo, err := provider.Authenticate(context.Background(), &auth.BearerCredentials{Token: valid})
+	if err != nil {
+		t.Fatalf("Expected cached token to skip verification, got %v", err)
+	}
+	if authInfo.Subject != "user123" {
+		t.Errorf("Expected subject user123, got %s", authInfo.Subject)
+	}
+	if _, err := provider.Authenticate(context.Background(), &auth.BearerCredentials{Token: wrongIssuer}); err == nil {
+		t.Error("Expected failed token not to be cached")
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"gateway/internal/core"
 	"gateway/internal/middleware/auth"
+	"gateway/internal/middleware/auth/token"
 	"gateway/pkg/errors"
 )
 
@@ -166,62 +167,17 @@ func (v *TokenValidator) StopAll() {
 	v.logger.Debug("Stopped all token validations")
 }
 
+// requestSources are where ExtractTokenFromRequest looks for a token
+var requestSources = token.NewExtractor([]token.Source{
+	{Type: token.SourceHeader, Name: "Authorization", Scheme: "Bearer"},
+	{Type: token.SourceCookie, Name: "jwt"},
+	{Type: token.SourceCookie, Name: "token"},
+})
+
 // ExtractTokenFromRequest extracts JWT token from a request
 func ExtractTokenFromRequest(req core.Request) (string, error) {
-	headers := req.Headers()
-
-	// Check Authorization header
-	if authHeaders, ok := headers["Authorization"]; ok && len(authHeaders) > 0 {
-		authHeader := authHeaders[0]
-		// Bearer token
-		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			return authHeader[7:], nil
-		}
+	if t, ok := requestSources.FromRequest(req); ok {
+		return t, nil
 	}
-
-	// Check cookie
-	if cookieHeaders, ok := headers["Cookie"]; ok && len(cookieHeaders) > 0 {
-		cookies := cookieHeaders[0]
-		// Simple cookie parser for jwt token
-		for _, cookie := range splitCookies(cookies) {
-			if name, value := parseCookie(cookie); name == "jwt" || name == "token" {
-				return value, nil
-			}
-		}
-	}
-
-	// For WebSocket/SSE, we might need to check query parameters
-	// This would require parsing the URL, which we can do from req.URL()
-
 	return "", errors.NewError(errors.ErrorTypeUnauthorized, "no token found in request")
 }
-
-// Helper functions for cookie parsing
-func splitCookies(cookies string) []string {
-	var result []string
-	start := 0
-	for i := 0; i < len(cookies); i++ {
-		if cookies[i] == ';' {
-			result = append(result, cookies[start:i])
-			start = i + 1
-			// Skip whitespace
-			for start < len(cookies) && cookies[start] == ' ' {
-				start++
-			}
-			i = start - 1
-		}
-	}
-	if start < len(cookies) {
-		result = append(result, cookies[start:])
-	}
-	return result
-}
-
-func parseCookie(cookie string) (name, value string) {
-	for i := 0; i < len(cookie); i++ {
-		if cookie[i] == '=' {
-			return cookie[:i], cookie[i+1:]
-		}
-	}
-	return cookie, ""
-}
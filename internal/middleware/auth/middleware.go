@@ -4,19 +4,49 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
-	"strings"
 
 	"gateway/internal/core"
 	"gateway/pkg/errors"
+	"gateway/pkg/routing"
 )
 
+// Enforcement modes
+const (
+	// ModeEnforce rejects requests failing authentication
+	ModeEnforce = "enforce"
+	// ModeShadow authenticates requests and records the decision, but lets
+	// every request through, to try a configuration before enforcing it
+	ModeShadow = "shadow"
+	// ModeDisabled lets requests through without authenticating them
+	ModeDisabled = "disabled"
+)
+
+// Reasons of authentication decisions
+const (
+	ReasonAuthenticated      = "authenticated"
+	ReasonAnonymous          = "anonymous" // No valid credentials where authentication is optional
+	ReasonNoCredentials      = "no_credentials"
+	ReasonInvalidCredentials = "invalid_credentials"
+	ReasonInsufficientScopes = "insufficient_scopes"
+)
+
+// MetricsRecorder receives the decision authentication would have made on
+// each request in shadow mode; allowed is false for requests enforcement
+// would reject
+type MetricsRecorder interface {
+	RecordShadowAuthDecision(ctx context.Context, allowed bool, reason string)
+}
+
 // Config represents authentication middleware configuration
 type Config struct {
+	// EnforcementMode is enforce (default), shadow or disabled
+	EnforcementMode string `yaml:"enforcementMode"`
 	// Required indicates if authentication is required
 	Required bool `yaml:"required"`
 	// Providers is the list of auth providers to use
 	Providers []string `yaml:"providers"`
-	// SkipPaths are paths that don't require authentication
+	// SkipPaths are "[METHODS] PATH" patterns that don't require
+	// authentication, see routing.PathMatcher
 	SkipPaths []string `yaml:"skipPaths"`
 	// RequiredScopes are scopes required for all requests
 	RequiredScopes []string `yaml:"requiredScopes"`
@@ -30,6 +60,8 @@ type Middleware struct {
 	logger     *slog.Logger
 	providers  map[string]Provider
 	extractors []Extractor
+	skip       *routing.PathMatcher
+	metrics    MetricsRecorder
 }
 
 // NewMiddleware creates a new authentication middleware
@@ -38,14 +70,28 @@ func NewMiddleware(config *Config, logger *slog.Logger) *Middleware {
 		config.StoreAuthInfo = true // Default to true
 	}
 
+	// Invalid patterns are rejected when the config is loaded; should one
+	// get here, no path skips authentication
+	skip, err := routing.NewPathMatcher(config.SkipPaths)
+	if err != nil {
+		logger.Error("Invalid auth skip paths, authenticating all paths", "error", err)
+	}
+
 	return &Middleware{
 		config:     config,
 		logger:     logger,
 		providers:  make(map[string]Provider),
 		extractors: make([]Extractor, 0),
+		skip:       skip,
 	}
 }
 
+// WithMetrics sets the recorder of shadow mode decisions
+func (m *Middleware) WithMetrics(metrics MetricsRecorder) *Middleware {
+	m.metrics = metrics
+	return m
+}
+
 // AddProvider adds an authentication provider
 func (m *Middleware) AddProvider(provider Provider) {
 	m.providers[provider.Name()] = provider
@@ -61,26 +107,28 @@ func (m *Middleware) AddExtractor(extractor Extractor) {
 func (m *Middleware) Handler(next core.Handler) core.Handler {
 	return func(ctx context.Context, req core.Request) (core.Response, error) {
 		// Check if path should skip auth
-		if m.shouldSkipAuth(req.Path()) {
+		if m.config.EnforcementMode == ModeDisabled || m.skip.Match(req.Method(), req.Path()) {
 			return next(ctx, req)
 		}
 
 		// Extract and authenticate
-		authInfo, err := m.authenticateRequest(ctx, req)
-		if err != nil {
-			return nil, err
+		d := m.decide(ctx, req.Headers(), req.URL())
+		if m.config.EnforcementMode == ModeShadow {
+			m.recordShadow(ctx, req.Method(), req.Path(), d)
+			if d.err != nil {
+				return next(ctx, req)
+			}
+		}
+		if d.err != nil {
+			return nil, d.err
 		}
 
 		// Continue without auth if not required and no auth info
+		authInfo := d.info
 		if authInfo == nil {
 			return next(ctx, req)
 		}
 
-		// Validate scopes
-		if err := m.validateScopes(authInfo); err != nil {
-			return nil, err
-		}
-
 		// Store auth info in context if configured
 		if m.config.StoreAuthInfo {
 			ctx = WithAuthInfo(ctx, authInfo)
@@ -91,32 +139,62 @@ func (m *Middleware) Handler(next core.Handler) core.Handler {
 	}
 }
 
-// authenticateRequest extracts credentials and attempts authentication
-func (m *Middleware) authenticateRequest(ctx context.Context, req core.Request) (*AuthInfo, error) {
+// decision is the outcome of authenticating a request
+type decision struct {
+	info   *AuthInfo // Set when the request authenticated
+	reason string
+	err    error // Set when the request is denied
+}
+
+// decide extracts credentials, attempts authentication and checks the
+// required scopes
+func (m *Middleware) decide(ctx context.Context, headers map[string][]string, rawURL string) decision {
 	// Extract credentials
-	credentials, err := m.extractCredentials(ctx, req)
+	credentials, err := m.extractCredentials(ctx, headers, rawURL)
 	if err != nil {
-		if m.config.Required {
-			return nil, errors.NewError(
+		if !m.config.Required {
+			// Auth not required, continue without auth
+			return decision{reason: ReasonAnonymous}
+		}
+		return decision{
+			reason: ReasonNoCredentials,
+			err: errors.NewError(
 				errors.ErrorTypeBadRequest,
 				"authentication required",
-			).WithCause(err)
+			).WithCause(err),
 		}
-		// Auth not required, continue without auth
-		return nil, nil
 	}
 
 	// Try authentication with providers
 	authInfo, err := m.tryAuthentication(ctx, credentials)
 	if err != nil {
-		if m.config.Required {
-			return nil, err
+		if !m.config.Required {
+			return decision{reason: ReasonAnonymous}
 		}
-		// Auth not required, continue without auth
-		return nil, nil
+		return decision{reason: ReasonInvalidCredentials, err: err}
 	}
 
-	return authInfo, nil
+	if err := m.validateScopes(authInfo); err != nil {
+		return decision{info: authInfo, reason: ReasonInsufficientScopes, err: err}
+	}
+	return decision{info: authInfo, reason: ReasonAuthenticated}
+}
+
+// recordShadow logs and records the decision of a request in shadow mode
+func (m *Middleware) recordShadow(ctx context.Context, method, path string, d decision) {
+	allowed := d.err == nil
+	attrs := []any{"method", method, "path", path, "reason", d.reason}
+	if d.info != nil {
+		attrs = append(attrs, "subject", d.info.Subject)
+	}
+	if allowed {
+		m.logger.Debug("Shadow authentication allowed request", attrs...)
+	} else {
+		m.logger.Warn("Shadow authentication would deny request", append(attrs, "error", d.err)...)
+	}
+	if m.metrics != nil {
+		m.metrics.RecordShadowAuthDecision(ctx, allowed, d.reason)
+	}
 }
 
 // tryAuthentication attempts authentication with configured providers
@@ -148,7 +226,7 @@ func (m *Middleware) tryAuthentication(ctx context.Context, credentials Credenti
 
 	// All providers failed
 	err := errors.NewError(
-		errors.ErrorTypeBadRequest,
+		errors.ErrorTypeUnauthorized,
 		"authentication failed",
 	)
 	if lastErr != nil {
@@ -186,25 +264,12 @@ func (m *Middleware) callNextWithAuth(ctx context.Context, req core.Request, nex
 	}, nil
 }
 
-// shouldSkipAuth checks if the path should skip authentication
-func (m *Middleware) shouldSkipAuth(path string) bool {
-	for _, skip := range m.config.SkipPaths {
-		if strings.HasPrefix(path, skip) {
-			return true
-		}
-	}
-	return false
-}
-
 // extractCredentials extracts credentials from the request
-func (m *Middleware) extractCredentials(ctx context.Context, req core.Request) (Credentials, error) {
-	// Get headers from request
-	headers := req.Headers()
-
+func (m *Middleware) extractCredentials(ctx context.Context, headers map[string][]string, rawURL string) (Credentials, error) {
 	// Try all extractors
 	var lastErr error
 	for _, extractor := range m.extractors {
-		creds, err := extractor.Extract(ctx, headers)
+		creds, err := extract(ctx, extractor, headers, rawURL)
 		if err == nil {
 			return creds, nil
 		}
@@ -220,6 +285,15 @@ func (m *Middleware) extractCredentials(ctx context.Context, req core.Request) (
 	)
 }
 
+// extract runs an extractor, giving it the request URL when it reads the
+// query
+func extract(ctx context.Context, extractor Extractor, headers map[string][]string, rawURL string) (Credentials, error) {
+	if e, ok := extractor.(URLExtractor); ok {
+		return e.ExtractURL(ctx, headers, rawURL)
+	}
+	return extractor.Extract(ctx, headers)
+}
+
 // canHandleCredentials checks if a provider can handle the given credentials
 func (m *Middleware) canHandleCredentials(provider Provider, creds Credentials) bool {
 	switch provider.Name() {
@@ -274,75 +348,39 @@ func GetAuthInfo(ctx context.Context) (*AuthInfo, bool) {
 func (m *Middleware) HTTPMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check if path should skip auth
-		if m.shouldSkipAuth(r.URL.Path) {
+		if m.config.EnforcementMode == ModeDisabled || m.skip.Match(r.Method, r.URL.Path) {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		// Extract headers
-		headers := make(map[string][]string)
-		for key, values := range r.Header {
-			headers[key] = values
-		}
-
-		// Try to extract credentials
-		var credentials Credentials
-		var lastErr error
-
-		for _, extractor := range m.extractors {
-			creds, err := extractor.Extract(r.Context(), headers)
-			if err == nil {
-				credentials = creds
-				break
+		d := m.decide(r.Context(), r.Header, r.URL.String())
+		if m.config.EnforcementMode == ModeShadow {
+			m.recordShadow(r.Context(), r.Method, r.URL.Path, d)
+			if d.err != nil {
+				next.ServeHTTP(w, r)
+				return
 			}
-			lastErr = err
 		}
-
-		if credentials == nil {
-			if m.config.Required {
+		if d.err != nil {
+			switch d.reason {
+			case ReasonNoCredentials:
 				http.Error(w, "Authentication required", http.StatusUnauthorized)
-				return
+			case ReasonInsufficientScopes:
+				http.Error(w, "Insufficient permissions", http.StatusForbidden)
+			default:
+				m.logger.Warn("Authentication failed", "error", d.err)
+				http.Error(w, "Authentication failed", http.StatusUnauthorized)
 			}
-			// Auth not required, continue
-			next.ServeHTTP(w, r)
 			return
 		}
 
-		// Try authentication
-		var authInfo *AuthInfo
-		for _, providerName := range m.config.Providers {
-			provider, ok := m.providers[providerName]
-			if !ok || !m.canHandleCredentials(provider, credentials) {
-				continue
-			}
-
-			info, err := provider.Authenticate(r.Context(), credentials)
-			if err == nil {
-				authInfo = info
-				break
-			}
-			lastErr = err
-		}
-
+		authInfo := d.info
 		if authInfo == nil {
-			if m.config.Required {
-				if lastErr != nil {
-					m.logger.Warn("Authentication failed", "error", lastErr)
-				}
-				http.Error(w, "Authentication failed", http.StatusUnauthorized)
-				return
-			}
 			// Auth not required, continue
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		// Check required scopes
-		if len(m.config.RequiredScopes) > 0 && !m.hasRequiredScopes(authInfo.Scopes) {
-			http.Error(w, "Insufficient permissions", http.StatusForbidden)
-			return
-		}
-
 		// Store auth info in context
 		if m.config.StoreAuthInfo {
 			r = r.WithContext(WithAuthInfo(r.Context(), authInfo))
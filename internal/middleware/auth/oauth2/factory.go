@@ -6,6 +6,7 @@ import (
 
 	"gateway/internal/config"
 	"gateway/internal/core"
+	"gateway/internal/middleware/auth/token"
 	"gateway/pkg/factory"
 )
 
@@ -70,6 +71,8 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 			TokenQuery:      c.config.TokenQuery,
 			TokenCookie:     c.config.TokenCookie,
 			BearerPrefix:    c.config.BearerPrefix,
+			TokenSources:    token.SourcesFromConfig(c.config.TokenSources),
+			SkipPaths:       c.config.SkipPaths,
 			RequireScopes:   c.config.RequireScopes,
 			RequireAudience: c.config.RequireAudience,
 			ClaimsKey:       c.config.ClaimsKey,
@@ -4,10 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"strings"
 	
 	"gateway/internal/core"
+	"gateway/internal/middleware/auth/token"
 	"gateway/pkg/errors"
+	"gateway/pkg/routing"
 )
 
 // Config represents OAuth2 middleware configuration
@@ -21,6 +22,13 @@ type Config struct {
 	TokenCookie     string `yaml:"tokenCookie"`     // Cookie name
 	BearerPrefix    string `yaml:"bearerPrefix"`    // Default: Bearer
 	
+	// Token sources tried in order, replacing the fields above when set
+	TokenSources []token.Source `yaml:"tokenSources"`
+	
+	// "[METHODS] PATH" patterns that don't require a token, see
+	// routing.PathMatcher
+	SkipPaths []string `yaml:"skipPaths"`
+	
 	// Validation options
 	RequireScopes   []string `yaml:"requireScopes"`   // Required scopes
 	RequireAudience []string `yaml:"requireAudience"` // Required audience
@@ -36,6 +44,8 @@ type Config struct {
 type Middleware struct {
 	config    *Config
 	providers map[string]*Provider
+	tokens    *token.Extractor
+	skip      *routing.PathMatcher
 	logger    *slog.Logger
 }
 
@@ -66,9 +76,16 @@ func NewMiddleware(config *Config, logger *slog.Logger) (*Middleware, error) {
 		providers[providerConfig.Name] = provider
 	}
 	
+	skip, err := routing.NewPathMatcher(config.SkipPaths)
+	if err != nil {
+		return nil, fmt.Errorf("skip paths: %w", err)
+	}
+	
 	return &Middleware{
 		config:    config,
 		providers: providers,
+		tokens:    token.NewExtractor(tokenSources(config)),
+		skip:      skip,
 		logger:    logger.With("middleware", "oauth2"),
 	}, nil
 }
@@ -78,7 +95,7 @@ func (m *Middleware) Middleware() core.Middleware {
 	return func(next core.Handler) core.Handler {
 		return func(ctx context.Context, req core.Request) (core.Response, error) {
 			// Skip if disabled
-			if !m.config.Enabled {
+			if !m.config.Enabled || m.skip.Match(req.Method(), req.Path()) {
 				return next(ctx, req)
 			}
 			
@@ -138,51 +155,24 @@ func (m *Middleware) Middleware() core.Middleware {
 
 // extractTokenFromRequest extracts the token from the request
 func (m *Middleware) extractTokenFromRequest(req core.Request) string {
-	headers := req.Headers()
-	
-	// Try header first
-	if m.config.TokenHeader != "" {
-		if headerValues, ok := headers[m.config.TokenHeader]; ok && len(headerValues) > 0 {
-			header := headerValues[0]
-			// Remove bearer prefix if present
-			if m.config.BearerPrefix != "" {
-				header = strings.TrimPrefix(header, m.config.BearerPrefix+" ")
-			}
-			return strings.TrimSpace(header)
-		}
+	t, _ := m.tokens.FromRequest(req)
+	return t
+}
+
+// tokenSources returns the configured token sources, or the header, query
+// parameter and cookie fields in that order
+func tokenSources(config *Config) []token.Source {
+	if len(config.TokenSources) > 0 {
+		return config.TokenSources
 	}
-	
-	// Try query parameter
-	if m.config.TokenQuery != "" {
-		// Parse URL to get query params
-		if url := req.URL(); url != "" {
-			if idx := strings.Index(url, "?"); idx != -1 {
-				query := url[idx+1:]
-				params := strings.Split(query, "&")
-				for _, param := range params {
-					parts := strings.SplitN(param, "=", 2)
-					if len(parts) == 2 && parts[0] == m.config.TokenQuery {
-						return parts[1]
-					}
-				}
-			}
-		}
+	sources := []token.Source{{Type: token.SourceHeader, Name: config.TokenHeader, Scheme: config.BearerPrefix}}
+	if config.TokenQuery != "" {
+		sources = append(sources, token.Source{Type: token.SourceQuery, Name: config.TokenQuery})
 	}
-	
-	// Try cookie
-	if m.config.TokenCookie != "" {
-		if cookieHeader, ok := headers["Cookie"]; ok && len(cookieHeader) > 0 {
-			cookies := strings.Split(cookieHeader[0], "; ")
-			for _, cookie := range cookies {
-				parts := strings.SplitN(cookie, "=", 2)
-				if len(parts) == 2 && parts[0] == m.config.TokenCookie {
-					return parts[1]
-				}
-			}
-		}
+	if config.TokenCookie != "" {
+		sources = append(sources, token.Source{Type: token.SourceCookie, Name: config.TokenCookie})
 	}
-	
-	return ""
+	return sources
 }
 
 // hasRequiredScopes checks if claims contain all required scopes
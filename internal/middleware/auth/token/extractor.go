@@ -0,0 +1,155 @@
+package token
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+)
+
+// Source types
+const (
+	SourceHeader = "header"
+	SourceCookie = "cookie"
+	SourceQuery  = "query"
+)
+
+// Source is a place a token is read from
+type Source struct {
+	// Type is header, cookie or query
+	Type string `yaml:"type"`
+	// Name is the header, cookie or query parameter name
+	Name string `yaml:"name"`
+	// Scheme is the prefix a header value must carry, e.g. Bearer. The
+	// whole header value is the token when empty.
+	Scheme string `yaml:"scheme"`
+}
+
+// DefaultSources reads a bearer token from the Authorization header
+func DefaultSources() []Source {
+	return []Source{{Type: SourceHeader, Name: "Authorization", Scheme: "Bearer"}}
+}
+
+// SourcesFromConfig converts configured token sources
+func SourcesFromConfig(cfg []config.TokenSource) []Source {
+	if len(cfg) == 0 {
+		return nil
+	}
+	sources := make([]Source, len(cfg))
+	for i, s := range cfg {
+		sources[i] = Source{Type: s.Type, Name: s.Name, Scheme: s.Scheme}
+	}
+	return sources
+}
+
+// Extractor reads a token from the first of its sources that carries one
+type Extractor struct {
+	sources []Source
+}
+
+// NewExtractor creates an extractor trying sources in order, or
+// DefaultSources when none are given
+func NewExtractor(sources []Source) *Extractor {
+	if len(sources) == 0 {
+		sources = DefaultSources()
+	}
+	return &Extractor{sources: sources}
+}
+
+// Sources returns the sources in the order they are tried
+func (e *Extractor) Sources() []Source {
+	return e.sources
+}
+
+// Extract returns the token carried by headers or the query of rawURL
+func (e *Extractor) Extract(headers map[string][]string, rawURL string) (string, bool) {
+	return e.extract(headers, func() url.Values {
+		if i := strings.IndexByte(rawURL, '?'); i >= 0 {
+			query, _ := url.ParseQuery(rawURL[i+1:])
+			return query
+		}
+		return nil
+	})
+}
+
+// FromRequest returns the token carried by a gateway request
+func (e *Extractor) FromRequest(req core.Request) (string, bool) {
+	return e.Extract(req.Headers(), req.URL())
+}
+
+// FromHTTP returns the token carried by an HTTP request
+func (e *Extractor) FromHTTP(r *http.Request) (string, bool) {
+	return e.extract(r.Header, r.URL.Query)
+}
+
+func (e *Extractor) extract(headers map[string][]string, query func() url.Values) (string, bool) {
+	var params url.Values
+	for _, source := range e.sources {
+		var token string
+		switch source.Type {
+		case SourceHeader:
+			token = fromHeader(headers, source)
+		case SourceCookie:
+			token = fromCookie(headers, source.Name)
+		case SourceQuery:
+			if params == nil {
+				params = query()
+			}
+			token = strings.TrimSpace(params.Get(source.Name))
+		}
+		if token != "" {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+// fromHeader returns the first value of the header carrying the scheme
+func fromHeader(headers map[string][]string, source Source) string {
+	for _, value := range headerValues(headers, source.Name) {
+		if source.Scheme == "" {
+			if token := strings.TrimSpace(value); token != "" {
+				return token
+			}
+			continue
+		}
+		scheme, token, ok := strings.Cut(value, " ")
+		if ok && strings.EqualFold(scheme, source.Scheme) {
+			if token = strings.TrimSpace(token); token != "" {
+				return token
+			}
+		}
+	}
+	return ""
+}
+
+// fromCookie returns the value of the named cookie
+func fromCookie(headers map[string][]string, name string) string {
+	cookies := headerValues(headers, "Cookie")
+	if len(cookies) == 0 {
+		return ""
+	}
+	cookie, err := (&http.Request{Header: http.Header{"Cookie": cookies}}).Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// headerValues looks a header up by name, ignoring case
+func headerValues(headers map[string][]string, name string) []string {
+	if values, ok := headers[name]; ok {
+		return values
+	}
+	if values, ok := headers[http.CanonicalHeaderKey(name)]; ok {
+		return values
+	}
+	for key, values := range headers {
+		if strings.EqualFold(key, name) {
+			return values
+		}
+	}
+	return nil
+}
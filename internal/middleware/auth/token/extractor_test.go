@@ -0,0 +1,126 @@
+package token
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"gateway/internal/config"
+)
+
+func TestExtractor_Extract(t *testing.T) {
+	sources := []Source{
+		{Type: SourceHeader, Name: "Authorization", Scheme: "Bearer"},
+		{Type: SourceHeader, Name: "X-Token"},
+		{Type: SourceCookie, Name: "session"},
+		{Type: SourceQuery, Name: "access_token"},
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string][]string
+		url     string
+		want    string
+	}{
+		{
+			name:    "bearer header",
+			headers: map[string][]string{"Authorization": {"Bearer abc"}},
+			want:    "abc",
+		},
+		{
+			name:    "scheme is case insensitive",
+			headers: map[string][]string{"Authorization": {"bearer abc"}},
+			want:    "abc",
+		},
+		{
+			name:    "other scheme skipped",
+			headers: map[string][]string{"Authorization": {"Basic dXNlcjpwYXNz"}, "X-Token": {"raw"}},
+			want:    "raw",
+		},
+		{
+			name:    "header name is case insensitive",
+			headers: map[string][]string{"x-token": {" raw "}},
+			want:    "raw",
+		},
+		{
+			name:    "cookie",
+			headers: map[string][]string{"Cookie": {"theme=dark; session=from-cookie"}},
+			want:    "from-cookie",
+		},
+		{
+			name: "query",
+			url:  "/events?stream=1&access_token=from-query",
+			want: "from-query",
+		},
+		{
+			name: "earlier source wins",
+			headers: map[string][]string{
+				"Cookie":        {"session=from-cookie"},
+				"Authorization": {"Bearer from-header"},
+			},
+			url:  "/events?access_token=from-query",
+			want: "from-header",
+		},
+		{
+			name:    "empty bearer falls through",
+			headers: map[string][]string{"Authorization": {"Bearer "}},
+			url:     "/events?access_token=from-query",
+			want:    "from-query",
+		},
+		{
+			name: "none",
+			url:  "/events",
+		},
+	}
+
+	e := NewExtractor(sources)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := e.Extract(tt.headers, tt.url)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("Extract() = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractor_FromHTTP(t *testing.T) {
+	e := NewExtractor([]Source{
+		{Type: SourceQuery, Name: "token"},
+		{Type: SourceCookie, Name: "token"},
+	})
+
+	r := httptest.NewRequest("GET", "/ws?token=from-query", nil)
+	r.Header.Set("Cookie", "token=from-cookie")
+	if got, _ := e.FromHTTP(r); got != "from-query" {
+		t.Errorf("FromHTTP() = %q, want from-query", got)
+	}
+
+	r = httptest.NewRequest("GET", "/ws", nil)
+	r.Header.Set("Cookie", "token=from-cookie")
+	if got, _ := e.FromHTTP(r); got != "from-cookie" {
+		t.Errorf("FromHTTP() = %q, want from-cookie", got)
+	}
+}
+
+func TestNewExtractor_Defaults(t *testing.T) {
+	e := NewExtractor(nil)
+
+	if got, ok := e.Extract(map[string][]string{"Authorization": {"Bearer abc"}}, ""); !ok || got != "abc" {
+		t.Errorf("Extract() = %q, %v, want abc", got, ok)
+	}
+	if _, ok := e.Extract(map[string][]string{"Cookie": {"token=abc"}}, "/?token=abc"); ok {
+		t.Error("default sources should only read the Authorization header")
+	}
+}
+
+func TestSourcesFromConfig(t *testing.T) {
+	if got := SourcesFromConfig(nil); got != nil {
+		t.Errorf("SourcesFromConfig(nil) = %v, want nil", got)
+	}
+
+	got := SourcesFromConfig([]config.TokenSource{{Type: "header", Name: "X-Auth", Scheme: "Token"}})
+	want := Source{Type: SourceHeader, Name: "X-Auth", Scheme: "Token"}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("SourcesFromConfig() = %v, want [%v]", got, want)
+	}
+}
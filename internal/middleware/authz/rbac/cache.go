@@ -1,120 +1,124 @@
 package rbac
 
 import (
-	"strings"
+	"container/list"
 	"sync"
 	"time"
 )
 
+// cacheKey identifies a permission check. Fields are kept apart so that
+// subjects and resources containing ':' cannot collide.
+type cacheKey struct {
+	subject  string
+	resource string
+	action   string
+}
+
 // cacheEntry represents a cached permission check result
 type cacheEntry struct {
-	allowed   bool
+	key       cacheKey
+	decision  Decision
 	timestamp time.Time
 }
 
-// permissionCache caches permission check results
+// permissionCache caches permission check results, evicting the least
+// recently used entry when full
 type permissionCache struct {
-	entries  map[string]*cacheEntry
-	mu       sync.RWMutex
-	maxSize  int
-	ttl      time.Duration
-	lastClean time.Time
+	mu      sync.Mutex
+	maxSize int
+	ttl     time.Duration
+	entries map[cacheKey]*list.Element
+	order   *list.List // Front is the most recently used
+	// generation changes whenever entries are invalidated, so a decision
+	// evaluated against policies replaced meanwhile is not cached
+	generation uint64
 }
 
 // newPermissionCache creates a new permission cache
 func newPermissionCache(maxSize int, ttl time.Duration) *permissionCache {
 	return &permissionCache{
-		entries:   make(map[string]*cacheEntry),
-		maxSize:   maxSize,
-		ttl:       ttl,
-		lastClean: time.Now(),
+		maxSize: maxSize,
+		ttl:     ttl,
+		entries: make(map[cacheKey]*list.Element),
+		order:   list.New(),
 	}
 }
 
-// Get retrieves a cached permission check result
-func (c *permissionCache) Get(key string) (bool, bool) {
-	c.mu.RLock()
-	entry, exists := c.entries[key]
-	c.mu.RUnlock()
-	
+// Get retrieves a cached permission check result. The generation returned
+// is passed to Set to cache the result of a miss.
+func (c *permissionCache) Get(key cacheKey) (decision Decision, found bool, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, exists := c.entries[key]
 	if !exists {
-		return false, false
+		return DecisionNone, false, c.generation
 	}
-	
+
 	// Check if entry is expired
+	entry := elem.Value.(*cacheEntry)
 	if time.Since(entry.timestamp) > c.ttl {
-		c.mu.Lock()
+		c.order.Remove(elem)
 		delete(c.entries, key)
-		c.mu.Unlock()
-		return false, false
+		return DecisionNone, false, c.generation
 	}
-	
-	return entry.allowed, true
+
+	c.order.MoveToFront(elem)
+	return entry.decision, true, c.generation
 }
 
-// Set stores a permission check result
-func (c *permissionCache) Set(key string, allowed bool) {
+// Set stores a permission check result, unless the cache was invalidated
+// since the generation it was evaluated in
+func (c *permissionCache) Set(key cacheKey, decision Decision, generation uint64) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	
-	// Clean old entries if needed
-	if time.Since(c.lastClean) > c.ttl {
-		c.cleanExpiredLocked()
+
+	if generation != c.generation {
+		return
 	}
-	
-	// Check size limit
-	if len(c.entries) >= c.maxSize {
-		// Remove oldest entry
-		var oldestKey string
-		var oldestTime time.Time
-		first := true
-		
-		for k, entry := range c.entries {
-			if first || entry.timestamp.Before(oldestTime) {
-				oldestKey = k
-				oldestTime = entry.timestamp
-				first = false
-			}
-		}
-		
-		if oldestKey != "" {
-			delete(c.entries, oldestKey)
-		}
+
+	entry := &cacheEntry{key: key, decision: decision, timestamp: time.Now()}
+	if elem, exists := c.entries[key]; exists {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
 	}
-	
-	c.entries[key] = &cacheEntry{
-		allowed:   allowed,
-		timestamp: time.Now(),
+
+	c.entries[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
 	}
 }
 
+// Len returns the number of cached results
+func (c *permissionCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
 // Clear removes all entries from the cache
 func (c *permissionCache) Clear() {
 	c.mu.Lock()
-	c.entries = make(map[string]*cacheEntry)
-	c.mu.Unlock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[cacheKey]*list.Element)
+	c.order.Init()
+	c.generation++
 }
 
 // ClearSubject removes all entries for a specific subject
 func (c *permissionCache) ClearSubject(subject string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	
-	prefix := subject + ":"
-	for key := range c.entries {
-		if strings.HasPrefix(key, prefix) {
-			delete(c.entries, key)
-		}
-	}
-}
 
-// cleanExpiredLocked removes expired entries (must be called with lock held)
-func (c *permissionCache) cleanExpiredLocked() {
-	now := time.Now()
-	for key, entry := range c.entries {
-		if now.Sub(entry.timestamp) > c.ttl {
+	for key, elem := range c.entries {
+		if key.subject == subject {
+			c.order.Remove(elem)
 			delete(c.entries, key)
 		}
 	}
-	c.lastClean = now
+	c.generation++
 }
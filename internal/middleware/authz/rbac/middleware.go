@@ -8,6 +8,7 @@ import (
 	
 	"gateway/internal/core"
 	"gateway/pkg/errors"
+	"gateway/pkg/routing"
 )
 
 // MiddlewareConfig represents RBAC middleware configuration
@@ -19,7 +20,7 @@ type MiddlewareConfig struct {
 	ActionExtractor      ActionExtractor   `yaml:"-"` // Function to extract action from request
 	EnforcementMode      string            `yaml:"enforcementMode"` // "enforce" or "permissive"
 	DefaultAllow         bool              `yaml:"defaultAllow"`    // Default decision when no policy matches
-	SkipPaths            []string          `yaml:"skipPaths"`       // Paths to skip authorization
+	SkipPaths            []string          `yaml:"skipPaths"`       // "[METHODS] PATH" patterns to skip authorization, see routing.PathMatcher
 	PolicyRefreshInterval int              `yaml:"policyRefreshInterval"` // Seconds between policy refresh
 }
 
@@ -36,6 +37,7 @@ type ActionExtractor func(req core.Request) string
 type Middleware struct {
 	rbac   *RBAC
 	config *MiddlewareConfig
+	skip   *routing.PathMatcher
 	logger *slog.Logger
 }
 
@@ -56,9 +58,17 @@ func NewMiddleware(rbac *RBAC, config *MiddlewareConfig, logger *slog.Logger) *M
 		config.ActionExtractor = defaultActionExtractor
 	}
 	
+	// Invalid patterns are rejected when the config is loaded; should one
+	// get here, no path skips authorization
+	skip, err := routing.NewPathMatcher(config.SkipPaths)
+	if err != nil {
+		logger.Error("Invalid RBAC skip paths, authorizing all paths", "error", err)
+	}
+	
 	return &Middleware{
 		rbac:   rbac,
 		config: config,
+		skip:   skip,
 		logger: logger.With("middleware", "rbac"),
 	}
 }
@@ -73,7 +83,7 @@ func (m *Middleware) Middleware() core.Middleware {
 			}
 			
 			// Check if path should be skipped
-			if m.shouldSkipPath(req.Path()) {
+			if m.skip.Match(req.Method(), req.Path()) {
 				return next(ctx, req)
 			}
 			
@@ -92,20 +102,20 @@ func (m *Middleware) Middleware() core.Middleware {
 			action := m.config.ActionExtractor(req)
 			
 			// Check permission
-			allowed := m.rbac.HasPermission(ctx, subject, resource, action)
+			decision := m.rbac.Decide(ctx, subject, resource, action)
 			
 			// Log decision
 			m.logger.Debug("Authorization decision",
 				"subject", subject,
 				"resource", resource,
 				"action", action,
-				"allowed", allowed,
+				"decision", decision.String(),
 				"path", req.Path(),
 			)
 			
-			if !allowed {
-				// Check default policy
-				if m.config.DefaultAllow {
+			if decision != DecisionAllow {
+				// Check default policy; explicit denies are not overridden
+				if m.config.DefaultAllow && decision == DecisionNone {
 					m.logger.Warn("No matching policy, allowing by default",
 						"subject", subject,
 						"resource", resource,
@@ -132,16 +142,6 @@ func (m *Middleware) Middleware() core.Middleware {
 	}
 }
 
-// shouldSkipPath checks if a path should skip authorization
-func (m *Middleware) shouldSkipPath(path string) bool {
-	for _, skipPath := range m.config.SkipPaths {
-		if strings.HasPrefix(path, skipPath) {
-			return true
-		}
-	}
-	return false
-}
-
 // Default extractors
 
 func defaultSubjectExtractor(subjectKey string) SubjectExtractor {
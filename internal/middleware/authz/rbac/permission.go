@@ -0,0 +1,123 @@
+package rbac
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Decision is the outcome of evaluating the permissions of a subject
+type Decision int
+
+const (
+	// DecisionNone means no permission of the subject applies
+	DecisionNone Decision = iota
+	// DecisionAllow means an allow permission applies and no deny does
+	DecisionAllow
+	// DecisionDeny means a deny permission applies, whatever else allows
+	DecisionDeny
+)
+
+// String returns the name of the decision
+func (d Decision) String() string {
+	switch d {
+	case DecisionAllow:
+		return "allow"
+	case DecisionDeny:
+		return "deny"
+	default:
+		return "none"
+	}
+}
+
+// permission is a parsed permission pattern
+type permission struct {
+	deny        bool
+	anyResource bool
+	resource    []string // Path segments of the resource pattern
+	action      string   // * matches any action
+}
+
+// parsePermission parses a permission pattern of the form resource:action,
+// e.g. orders:read or /orders/{id}:read, or resource:action:segments, a
+// shorthand for the resource /resource/segments, e.g. orders:read:{id}.
+// Resources are matched segment by segment against the request path: *
+// matches one segment, ** any number of segments and {name} one path
+// parameter; * alone matches any resource. A leading ! denies instead.
+func parsePermission(s string) (permission, error) {
+	var p permission
+	if strings.HasPrefix(s, "!") {
+		p.deny = true
+		s = s[1:]
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 {
+		return p, fmt.Errorf("permission %q: must be resource:action", s)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return p, fmt.Errorf("permission %q: empty resource or action", s)
+		}
+	}
+	p.action = parts[1]
+
+	if parts[0] == "*" && len(parts) == 2 {
+		p.anyResource = true
+		return p, nil
+	}
+	p.resource = resourceSegments(parts[0])
+	for _, part := range parts[2:] {
+		p.resource = append(p.resource, resourceSegments(part)...)
+	}
+	for _, segment := range p.resource {
+		if segment == "" {
+			return p, fmt.Errorf("permission %q: empty path segment", s)
+		}
+		if strings.HasPrefix(segment, "{") != strings.HasSuffix(segment, "}") || segment == "{}" {
+			return p, fmt.Errorf("permission %q: malformed path parameter %q", s, segment)
+		}
+	}
+	return p, nil
+}
+
+// matches reports whether the permission applies to action on the
+// resource with the given path segments
+func (p permission) matches(resource []string, action string) bool {
+	if p.action != "*" && p.action != action {
+		return false
+	}
+	return p.anyResource || matchSegments(p.resource, resource)
+}
+
+// resourceSegments splits a resource path into segments, ignoring leading
+// and trailing slashes
+func resourceSegments(resource string) []string {
+	resource = strings.Trim(resource, "/")
+	if resource == "" {
+		return nil
+	}
+	return strings.Split(resource, "/")
+}
+
+// matchSegments reports whether the segments of a resource match those of
+// a pattern
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if pattern[0] != "*" && !strings.HasPrefix(pattern[0], "{") && pattern[0] != segments[0] {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
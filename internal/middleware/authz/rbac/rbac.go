@@ -16,6 +16,8 @@ type Role struct {
 	Permissions []string            `yaml:"permissions"`
 	Inherits    []string            `yaml:"inherits"` // Role inheritance
 	Metadata    map[string]string   `yaml:"metadata"`
+
+	permissions []permission // Parsed Permissions, set when the policy is added
 }
 
 // Policy represents an RBAC policy
@@ -28,6 +30,11 @@ type Policy struct {
 	UpdatedAt   time.Time           `yaml:"updatedAt"`
 }
 
+// MetricsRecorder receives the outcome of permission cache lookups
+type MetricsRecorder interface {
+	RecordRBACCacheLookup(ctx context.Context, hit bool)
+}
+
 // RBAC implements role-based access control
 type RBAC struct {
 	policies  map[string]*Policy
@@ -36,6 +43,10 @@ type RBAC struct {
 	cache     *permissionCache
 	cacheSize int
 	cacheTTL  time.Duration
+	metrics   MetricsRecorder
+	// literals holds the literal resource segments of all permissions, see
+	// cacheResource
+	literals map[string]bool
 }
 
 // Config represents RBAC configuration
@@ -80,6 +91,12 @@ func New(config *Config, logger *slog.Logger) (*RBAC, error) {
 	return rbac, nil
 }
 
+// WithMetrics sets the recorder of permission cache lookups
+func (r *RBAC) WithMetrics(metrics MetricsRecorder) *RBAC {
+	r.metrics = metrics
+	return r
+}
+
 // AddPolicy adds a new policy
 func (r *RBAC) AddPolicy(policy *Policy) error {
 	r.mu.Lock()
@@ -106,6 +123,7 @@ func (r *RBAC) AddPolicy(policy *Policy) error {
 	r.policies[policy.Name] = policy
 	
 	// Clear cache as permissions may have changed
+	r.indexLiterals()
 	r.cache.Clear()
 	
 	r.logger.Info("Policy added", "policy", policy.Name, "roles", len(policy.Roles))
@@ -122,6 +140,7 @@ func (r *RBAC) RemovePolicy(name string) error {
 	}
 	
 	delete(r.policies, name)
+	r.indexLiterals()
 	r.cache.Clear()
 	
 	r.logger.Info("Policy removed", "policy", name)
@@ -130,21 +149,75 @@ func (r *RBAC) RemovePolicy(name string) error {
 
 // HasPermission checks if a subject has a specific permission
 func (r *RBAC) HasPermission(ctx context.Context, subject, resource, action string) bool {
-	// Check cache first
-	permission := formatPermission(resource, action)
-	cacheKey := subject + ":" + permission
-	
-	if allowed, found := r.cache.Get(cacheKey); found {
-		return allowed
+	return r.Decide(ctx, subject, resource, action) == DecisionAllow
+}
+
+// Decide evaluates the permissions of a subject, including those of
+// inherited roles, for action on resource. Decisions are cached by subject,
+// resource pattern and action until the policies or the subject's bindings
+// change.
+func (r *RBAC) Decide(ctx context.Context, subject, resource, action string) Decision {
+	// Check cache first. The read lock keeps the pattern and the cache
+	// generation consistent with each other.
+	r.mu.RLock()
+	key := cacheKey{subject: subject, resource: r.cacheResource(resource), action: action}
+	decision, found, generation := r.cache.Get(key)
+	r.mu.RUnlock()
+	if r.metrics != nil {
+		r.metrics.RecordRBACCacheLookup(ctx, found)
+	}
+	if found {
+		return decision
 	}
 	
-	// Check permissions
-	allowed := r.checkPermission(subject, permission)
+	// Check permissions, resolving inherited roles and patterns
+	decision = r.checkPermission(subject, resourceSegments(resource), action)
 	
 	// Cache result
-	r.cache.Set(cacheKey, allowed)
+	r.cache.Set(key, decision, generation)
 	
-	return allowed
+	return decision
+}
+
+// ClearCache drops all cached decisions, for policies changed in place
+func (r *RBAC) ClearCache() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.indexLiterals()
+	r.cache.Clear()
+}
+
+// cacheResource returns the pattern a resource is cached under: its path
+// with every segment that no permission names literally replaced by *, so
+// that /orders/123 and /orders/456 share the entry /orders/*. Such segments
+// only ever match wildcards and parameters, so every resource with the same
+// pattern gets the same decision. Must be called with r.mu held.
+func (r *RBAC) cacheResource(resource string) string {
+	segments := resourceSegments(resource)
+	for i, segment := range segments {
+		if !r.literals[segment] {
+			segments[i] = "*"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// indexLiterals collects the literal resource segments of the permissions of
+// all policies. Must be called with r.mu held for writing.
+func (r *RBAC) indexLiterals() {
+	r.literals = make(map[string]bool)
+	for _, policy := range r.policies {
+		for _, role := range policy.Roles {
+			for _, perm := range role.permissions {
+				for _, segment := range perm.resource {
+					if segment != "*" && segment != "**" && !strings.HasPrefix(segment, "{") {
+						r.literals[segment] = true
+					}
+				}
+			}
+		}
+	}
 }
 
 // GetRoles returns all roles for a subject
@@ -265,43 +338,63 @@ func (r *RBAC) UnbindRole(policyName, subject, role string) error {
 
 // Internal methods
 
-func (r *RBAC) checkPermission(subject, permission string) bool {
+// checkPermission evaluates every role bound to subject. A deny
+// permission in any role, inherited or not, takes precedence over allows.
+func (r *RBAC) checkPermission(subject string, resource []string, action string) Decision {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	
+	decision := DecisionNone
 	for _, policy := range r.policies {
-		if roles, exists := policy.Bindings[subject]; exists {
-			for _, roleName := range roles {
-				if role, exists := policy.Roles[roleName]; exists {
-					if r.roleHasPermission(role, permission, policy) {
-						return true
-					}
+		visited := make(map[string]bool)
+		for _, roleName := range policy.Bindings[subject] {
+			if role, exists := policy.Roles[roleName]; exists {
+				switch r.roleDecision(role, resource, action, policy, visited) {
+				case DecisionDeny:
+					return DecisionDeny
+				case DecisionAllow:
+					decision = DecisionAllow
 				}
 			}
 		}
 	}
 	
-	return false
+	return decision
 }
 
-func (r *RBAC) roleHasPermission(role *Role, permission string, policy *Policy) bool {
+// roleDecision evaluates the permissions of role and the roles it inherits.
+// visited holds the roles already evaluated, which are skipped.
+func (r *RBAC) roleDecision(role *Role, resource []string, action string, policy *Policy, visited map[string]bool) Decision {
+	if visited[role.Name] {
+		return DecisionNone
+	}
+	visited[role.Name] = true
+	
 	// Check direct permissions
-	for _, perm := range role.Permissions {
-		if matchPermission(perm, permission) {
-			return true
+	decision := DecisionNone
+	for _, perm := range role.permissions {
+		if !perm.matches(resource, action) {
+			continue
+		}
+		if perm.deny {
+			return DecisionDeny
 		}
+		decision = DecisionAllow
 	}
 	
 	// Check inherited roles
 	for _, inheritedRoleName := range role.Inherits {
 		if inheritedRole, exists := policy.Roles[inheritedRoleName]; exists {
-			if r.roleHasPermission(inheritedRole, permission, policy) {
-				return true
+			switch r.roleDecision(inheritedRole, resource, action, policy, visited) {
+			case DecisionDeny:
+				return DecisionDeny
+			case DecisionAllow:
+				decision = DecisionAllow
 			}
 		}
 	}
 	
-	return false
+	return decision
 }
 
 func (r *RBAC) collectRolePermissions(role *Role, policy *Policy, permissions map[string]bool) {
@@ -323,6 +416,15 @@ func (r *RBAC) validateRole(role *Role, policy *Policy) error {
 		return fmt.Errorf("role name is required")
 	}
 	
+	role.permissions = make([]permission, 0, len(role.Permissions))
+	for _, s := range role.Permissions {
+		perm, err := parsePermission(s)
+		if err != nil {
+			return err
+		}
+		role.permissions = append(role.permissions, perm)
+	}
+	
 	// Check for circular inheritance
 	visited := make(map[string]bool)
 	return r.checkCircularInheritance(role.Name, role, policy, visited)
@@ -349,35 +451,3 @@ func (r *RBAC) checkCircularInheritance(originalRole string, role *Role, policy
 	
 	return nil
 }
-
-// Helper functions
-
-func formatPermission(resource, action string) string {
-	return resource + ":" + action
-}
-
-func matchPermission(pattern, permission string) bool {
-	// Exact match
-	if pattern == permission {
-		return true
-	}
-	
-	// Full wildcard
-	if pattern == "*:*" {
-		return true
-	}
-	
-	// Resource wildcard (e.g., "documents:*")
-	if strings.HasSuffix(pattern, ":*") {
-		prefix := strings.TrimSuffix(pattern, "*")
-		return strings.HasPrefix(permission, prefix)
-	}
-	
-	// Action wildcard (e.g., "*:read")
-	if strings.HasPrefix(pattern, "*:") {
-		suffix := strings.TrimPrefix(pattern, "*:")
-		return strings.HasSuffix(permission, ":"+suffix)
-	}
-	
-	return false
-}
@@ -2,8 +2,11 @@ package rbac
 
 import (
 	"context"
+	"io"
 	"testing"
 	"time"
+
+	"gateway/internal/core"
 )
 
 func TestRBAC(t *testing.T) {
@@ -233,3 +236,295 @@ func TestPermissionCache(t *testing.T) {
 		t.Error("Should have permission (cache expired)")
 	}
 }
+
+// mockRecorder counts permission cache lookups
+type mockRecorder struct {
+	hits, misses int
+}
+
+func (r *mockRecorder) RecordRBACCacheLookup(ctx context.Context, hit bool) {
+	if hit {
+		r.hits++
+	} else {
+		r.misses++
+	}
+}
+
+func TestPermissionCache_Invalidation(t *testing.T) {
+	policy := &Policy{
+		Name: "orders",
+		Roles: map[string]*Role{
+			"reader": {Name: "reader", Permissions: []string{"orders:read"}},
+			"admin":  {Name: "admin", Permissions: []string{"orders:*"}, Inherits: []string{"reader"}},
+			"owner":  {Name: "owner", Inherits: []string{"admin"}},
+		},
+		Bindings: map[string][]string{
+			"alice":  {"owner"},
+			"user:a": {"reader"},
+		},
+	}
+	rbac, err := New(&Config{Policies: []*Policy{policy}}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create RBAC: %v", err)
+	}
+	recorder := &mockRecorder{}
+	rbac.WithMetrics(recorder)
+	ctx := context.Background()
+
+	// Wildcards inherited through two levels are cached like direct grants
+	for i := 0; i < 2; i++ {
+		if !rbac.HasPermission(ctx, "alice", "orders", "delete") {
+			t.Error("Expected alice to inherit orders:*")
+		}
+	}
+	if recorder.hits != 1 || recorder.misses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss, got %d and %d", recorder.hits, recorder.misses)
+	}
+
+	// Subjects and resources containing ':' do not share entries
+	if !rbac.HasPermission(ctx, "user:a", "orders", "read") {
+		t.Error("Expected user:a to read orders")
+	}
+	if rbac.HasPermission(ctx, "user", "a:orders", "read") {
+		t.Error("Expected user to be denied a:orders:read")
+	}
+
+	// Binding a role drops the subject's cached decisions
+	if rbac.HasPermission(ctx, "bob", "orders", "read") {
+		t.Error("Expected bob to be denied before binding")
+	}
+	if err := rbac.BindRole("orders", "bob", "reader"); err != nil {
+		t.Fatal(err)
+	}
+	if !rbac.HasPermission(ctx, "bob", "orders", "read") {
+		t.Error("Expected bob to read orders once bound")
+	}
+
+	// Reloading the policy drops every cached decision
+	if err := rbac.AddPolicy(&Policy{
+		Name:     "orders",
+		Roles:    map[string]*Role{"reader": {Name: "reader", Permissions: []string{"orders:read"}}},
+		Bindings: map[string][]string{"bob": {"reader"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if rbac.cache.Len() != 0 {
+		t.Errorf("Expected the cache to be cleared, got %d entries", rbac.cache.Len())
+	}
+	if rbac.HasPermission(ctx, "alice", "orders", "delete") {
+		t.Error("Expected alice to lose orders:* with the reloaded policy")
+	}
+}
+
+func TestPermissionCache_Eviction(t *testing.T) {
+	cache := newPermissionCache(2, time.Minute)
+	key := func(subject string) cacheKey { return cacheKey{subject: subject, resource: "r", action: "a"} }
+
+	_, _, generation := cache.Get(key("a"))
+	cache.Set(key("a"), DecisionAllow, generation)
+	cache.Set(key("b"), DecisionAllow, generation)
+	cache.Get(key("a"))
+	cache.Set(key("c"), DecisionAllow, generation)
+
+	if _, found, _ := cache.Get(key("b")); found {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	if _, found, _ := cache.Get(key("a")); !found {
+		t.Error("Expected the recently used entry to be kept")
+	}
+
+	// Results evaluated before an invalidation are not cached
+	cache.Clear()
+	cache.Set(key("d"), DecisionAllow, generation)
+	if _, found, _ := cache.Get(key("d")); found {
+		t.Error("Expected a stale result not to be cached")
+	}
+}
+
+func TestDecisionCacheKeyedByPattern(t *testing.T) {
+	rbac, err := New(&Config{Policies: []*Policy{{
+		Name: "orders",
+		Roles: map[string]*Role{
+			"customer": {Name: "customer", Permissions: []string{"orders:read:{id}", "!orders:read:internal"}},
+		},
+		Bindings: map[string][]string{"alice": {"customer"}},
+	}}}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create RBAC: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, id := range []string{"1", "2", "3"} {
+		if got := rbac.Decide(ctx, "alice", "/orders/"+id, "read"); got != DecisionAllow {
+			t.Errorf("Decide(/orders/%s) = %s, want allow", id, got)
+		}
+	}
+	if got := rbac.cache.order.Len(); got != 1 {
+		t.Errorf("Expected requests differing by ID to share one entry, got %d", got)
+	}
+
+	// Segments named by a permission keep their own entry
+	if got := rbac.Decide(ctx, "alice", "/orders/internal", "read"); got != DecisionDeny {
+		t.Errorf("Decide(/orders/internal) = %s, want deny", got)
+	}
+	if got := rbac.Decide(ctx, "alice", "/orders/4", "read"); got != DecisionAllow {
+		t.Errorf("Decide(/orders/4) = %s, want allow", got)
+	}
+
+	// Literals of a policy added later are keyed apart too
+	if err := rbac.AddPolicy(&Policy{
+		Name:     "vip",
+		Roles:    map[string]*Role{"blocked": {Name: "blocked", Permissions: []string{"!orders:read:2"}}},
+		Bindings: map[string][]string{"alice": {"blocked"}},
+	}); err != nil {
+		t.Fatalf("AddPolicy() error = %v", err)
+	}
+	if got := rbac.Decide(ctx, "alice", "/orders/1", "read"); got != DecisionAllow {
+		t.Errorf("Decide(/orders/1) = %s, want allow", got)
+	}
+	if got := rbac.Decide(ctx, "alice", "/orders/2", "read"); got != DecisionDeny {
+		t.Errorf("Decide(/orders/2) = %s, want deny", got)
+	}
+}
+
+func TestPermissionPatterns(t *testing.T) {
+	tests := []struct {
+		permission string
+		resource   string
+		action     string
+		want       bool
+	}{
+		{"orders:read:{id}", "/orders/123", "read", true},
+		{"orders:read:{id}", "/orders/123/items", "read", false},
+		{"orders:read:{id}", "/orders", "read", false},
+		{"orders:read:{id}", "/orders/123", "delete", false},
+		{"orders:read:*", "/orders/123", "read", true},
+		{"orders:read:**", "/orders/123/items/4", "read", true},
+		{"orders:read:**", "/orders", "read", true},
+		{"orders:*:{id}:items", "/orders/123/items", "update", true},
+		{"/orders/{id}/items/*:read", "/orders/123/items/4", "read", true},
+		{"/orders/**:read", "/customers/1", "read", false},
+		{"orders:read", "/orders", "read", true},
+		{"orders:read", "orders", "read", true},
+		{"orders:read", "/orders/123", "read", false},
+		{"*:read", "/anything/at/all", "read", true},
+		{"*:*", "/", "delete", true},
+		{"*:read:{id}", "/orders/123", "read", true},
+	}
+	for _, tt := range tests {
+		p, err := parsePermission(tt.permission)
+		if err != nil {
+			t.Fatalf("parsePermission(%q) error = %v", tt.permission, err)
+		}
+		if got := p.matches(resourceSegments(tt.resource), tt.action); got != tt.want {
+			t.Errorf("%q matches %s %s = %v, want %v", tt.permission, tt.action, tt.resource, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"orders", "orders:", ":read", "orders:read:{id", "orders:read:{}", "/orders//items:read"} {
+		if _, err := parsePermission(invalid); err == nil {
+			t.Errorf("Expected parsePermission(%q) to fail", invalid)
+		}
+	}
+	if _, err := New(&Config{Policies: []*Policy{{
+		Name:  "invalid",
+		Roles: map[string]*Role{"r": {Name: "r", Permissions: []string{"orders"}}},
+	}}}, nil); err == nil {
+		t.Error("Expected a policy with an invalid permission to be rejected")
+	}
+}
+
+func TestDenyPrecedence(t *testing.T) {
+	policy := &Policy{
+		Name: "orders",
+		Roles: map[string]*Role{
+			"reader":  {Name: "reader", Permissions: []string{"orders:read:**"}},
+			"limited": {Name: "limited", Permissions: []string{"!orders:read:{id}:invoice"}},
+			// Inherits an allow and a deny; the deny wins
+			"support": {Name: "support", Permissions: []string{"orders:update:{id}"}, Inherits: []string{"reader", "limited"}},
+			"writer":  {Name: "writer", Permissions: []string{"orders:*:**"}},
+		},
+		Bindings: map[string][]string{
+			"agent":   {"support"},
+			"partner": {"writer", "limited"},
+		},
+	}
+	rbac, err := New(&Config{Policies: []*Policy{policy}}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create RBAC: %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		subject, resource, action string
+		want                      Decision
+	}{
+		{"agent", "/orders/1", "read", DecisionAllow},
+		{"agent", "/orders/1", "update", DecisionAllow},
+		{"agent", "/orders/1/invoice", "read", DecisionDeny},
+		{"agent", "/orders/1", "delete", DecisionNone},
+		// A deny of one bound role overrides the allow of another
+		{"partner", "/orders/1/invoice", "read", DecisionDeny},
+		{"partner", "/orders/1/invoice", "update", DecisionAllow},
+		{"nobody", "/orders/1", "read", DecisionNone},
+	}
+	for _, tt := range tests {
+		if got := rbac.Decide(ctx, tt.subject, tt.resource, tt.action); got != tt.want {
+			t.Errorf("Decide(%s, %s, %s) = %s, want %s", tt.subject, tt.resource, tt.action, got, tt.want)
+		}
+	}
+}
+
+// testRequest implements core.Request for middleware tests
+type testRequest struct {
+	method, path string
+}
+
+func (r *testRequest) ID() string                   { return "test" }
+func (r *testRequest) Method() string               { return r.method }
+func (r *testRequest) Path() string                 { return r.path }
+func (r *testRequest) URL() string                  { return "http://test" + r.path }
+func (r *testRequest) RemoteAddr() string           { return "127.0.0.1:1234" }
+func (r *testRequest) Headers() map[string][]string { return nil }
+func (r *testRequest) Body() io.ReadCloser          { return nil }
+func (r *testRequest) Context() context.Context     { return context.Background() }
+
+func TestMiddleware_DefaultAllow(t *testing.T) {
+	rbac, err := New(&Config{Policies: []*Policy{{
+		Name: "orders",
+		Roles: map[string]*Role{
+			"customer": {Name: "customer", Permissions: []string{"orders:read:{id}", "!orders:delete:{id}"}},
+		},
+		Bindings: map[string][]string{"alice": {"customer"}},
+	}}}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create RBAC: %v", err)
+	}
+
+	handler := NewMiddleware(rbac, &MiddlewareConfig{
+		Enabled:      true,
+		DefaultAllow: true,
+		SubjectExtractor: func(ctx context.Context) (string, error) {
+			return "alice", nil
+		},
+	}, nil).Middleware()(func(ctx context.Context, req core.Request) (core.Response, error) {
+		return nil, nil
+	})
+
+	tests := []struct {
+		method, path string
+		allowed      bool
+	}{
+		{"GET", "/orders/1", true},
+		// No permission applies, so the default allows it
+		{"PUT", "/orders/1", true},
+		// An explicit deny is not overridden by the default
+		{"DELETE", "/orders/1", false},
+	}
+	for _, tt := range tests {
+		_, err := handler(context.Background(), &testRequest{method: tt.method, path: tt.path})
+		if (err == nil) != tt.allowed {
+			t.Errorf("%s %s: error = %v, want allowed %v", tt.method, tt.path, err, tt.allowed)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package bodylimit enforces route limits on the size and content type of
+// request bodies
+package bodylimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+)
+
+// Config holds body limit configuration
+type Config struct {
+	// Routes are the route limits by route ID
+	Routes map[string]Limit
+}
+
+// Limit restricts the request bodies of a route
+type Limit struct {
+	// MaxBodySize is the largest body accepted, in bytes; 0 means no limit
+	MaxBodySize int64
+	// AllowedContentTypes lists the media types accepted for requests with
+	// a body, such as "application/json" or "image/*"; empty accepts any
+	AllowedContentTypes []string
+}
+
+// RouteMatcher finds the route rule of a request
+type RouteMatcher interface {
+	Match(core.Request) (*core.RouteRule, error)
+}
+
+// Middleware rejects requests whose body is too large with 413 Request
+// Entity Too Large, and requests whose content type is not allowed with 415
+// Unsupported Media Type. Bodies without a Content-Length are cut off at the
+// limit while they are forwarded.
+type Middleware struct {
+	routes  map[string]Limit
+	matcher RouteMatcher
+	logger  *slog.Logger
+}
+
+// New creates a body limit middleware
+func New(config Config, matcher RouteMatcher, logger *slog.Logger) (*Middleware, error) {
+	if matcher == nil {
+		return nil, fmt.Errorf("router does not support body limit route matching")
+	}
+
+	routes := make(map[string]Limit, len(config.Routes))
+	for id, limit := range config.Routes {
+		types := make([]string, len(limit.AllowedContentTypes))
+		for i, t := range limit.AllowedContentTypes {
+			types[i] = strings.ToLower(strings.TrimSpace(t))
+		}
+		limit.AllowedContentTypes = types
+		routes[id] = limit
+	}
+
+	return &Middleware{
+		routes:  routes,
+		matcher: matcher,
+		logger:  logger.With("component", "bodylimit"),
+	}, nil
+}
+
+// Handler enforces the limit of the request's route
+func (m *Middleware) Handler(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		rule, err := m.matcher.Match(req)
+		if err != nil || rule == nil {
+			return next(ctx, req)
+		}
+		limit, ok := m.routes[rule.ID]
+		if !ok {
+			return next(ctx, req)
+		}
+
+		headers := http.Header(req.Headers())
+		if len(limit.AllowedContentTypes) > 0 && hasBody(req) && !allowed(limit.AllowedContentTypes, headers.Get("Content-Type")) {
+			m.logger.Debug("request rejected, content type not allowed",
+				"route", rule.ID,
+				"content_type", headers.Get("Content-Type"),
+			)
+			return nil, gwerrors.NewError(gwerrors.ErrorTypeUnsupportedMediaType, "Unsupported media type")
+		}
+
+		if limit.MaxBodySize <= 0 || !hasBody(req) {
+			return next(ctx, req)
+		}
+		if length, err := strconv.ParseInt(headers.Get("Content-Length"), 10, 64); err == nil && length > limit.MaxBodySize {
+			return nil, m.tooLarge(rule.ID, limit.MaxBodySize)
+		}
+
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(nil, req.Body(), limit.MaxBodySize)}
+		resp, err := next(ctx, &limitedRequest{Request: req, body: body})
+		if body.exceeded.Load() {
+			if resp != nil && resp.Body() != nil {
+				resp.Body().Close()
+			}
+			return nil, m.tooLarge(rule.ID, limit.MaxBodySize)
+		}
+		return resp, err
+	}
+}
+
+// tooLarge logs and returns the error for a body over the limit
+func (m *Middleware) tooLarge(route string, max int64) error {
+	m.logger.Debug("request rejected, body too large", "route", route, "max_size", max)
+	return gwerrors.NewError(gwerrors.ErrorTypePayloadTooLarge, "Request body too large")
+}
+
+// hasBody reports whether a request carries a body
+func hasBody(req core.Request) bool {
+	body := req.Body()
+	if body == nil || body == http.NoBody {
+		return false
+	}
+	return http.Header(req.Headers()).Get("Content-Length") != "0"
+}
+
+// allowed reports whether contentType matches one of the allowed media
+// types
+func allowed(types []string, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range types {
+		if t == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(t, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedBody records whether a body hit its size limit; the connector may
+// read it on another goroutine
+type limitedBody struct {
+	io.ReadCloser
+	exceeded atomic.Bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		b.exceeded.Store(true)
+	}
+	return n, err
+}
+
+// limitedRequest replaces the body of a request with its limited body
+type limitedRequest struct {
+	core.Request
+	body io.ReadCloser
+}
+
+func (r *limitedRequest) Body() io.ReadCloser { return r.body }
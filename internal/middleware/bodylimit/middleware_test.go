@@ -0,0 +1,93 @@
+package bodylimit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+)
+
+// mockMatcher routes /upload requests to the upload route
+type mockMatcher struct{}
+
+func (mockMatcher) Match(req core.Request) (*core.RouteRule, error) {
+	if req.Path() == "/upload" {
+		return &core.RouteRule{ID: "upload"}, nil
+	}
+	return &core.RouteRule{ID: "public"}, nil
+}
+
+// drain reads the request body like a connector forwarding it
+func drain(ctx context.Context, req core.Request) (core.Response, error) {
+	if body := req.Body(); body != nil {
+		if _, err := io.ReadAll(body); err != nil {
+			return nil, gwerrors.NewError(gwerrors.ErrorTypeUnavailable, "backend request failed").WithCause(err)
+		}
+	}
+	return core.NewResponse(http.StatusOK, nil), nil
+}
+
+func request(path, contentType, body string, withLength bool) core.Request {
+	headers := map[string][]string{}
+	if contentType != "" {
+		headers["Content-Type"] = []string{contentType}
+	}
+	var reqBody io.ReadCloser = http.NoBody
+	if body != "" {
+		reqBody = io.NopCloser(strings.NewReader(body))
+		if withLength {
+			headers["Content-Length"] = []string{strconv.Itoa(len(body))}
+		}
+	}
+	return core.NewRequest("id", "POST", path, path, "203.0.113.1:1000", headers, reqBody, context.Background())
+}
+
+func TestMiddleware_Handler(t *testing.T) {
+	m, err := New(Config{Routes: map[string]Limit{
+		"upload": {MaxBodySize: 8, AllowedContentTypes: []string{"application/json", "Image/*"}},
+	}}, mockMatcher{}, slog.Default())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	handler := m.Handler(drain)
+
+	tests := []struct {
+		name   string
+		req    core.Request
+		status int
+	}{
+		{name: "other route", req: request("/", "text/plain", strings.Repeat("x", 100), true), status: http.StatusOK},
+		{name: "allowed", req: request("/upload", "application/json; charset=utf-8", `{"a":1}`, true), status: http.StatusOK},
+		{name: "wildcard type", req: request("/upload", "image/png", "png", true), status: http.StatusOK},
+		{name: "no body", req: request("/upload", "", "", false), status: http.StatusOK},
+		{name: "type not allowed", req: request("/upload", "text/plain", "hi", true), status: http.StatusUnsupportedMediaType},
+		{name: "missing type", req: request("/upload", "", "hi", true), status: http.StatusUnsupportedMediaType},
+		{name: "content length over limit", req: request("/upload", "application/json", `{"a":12345}`, true), status: http.StatusRequestEntityTooLarge},
+		{name: "streamed body over limit", req: request("/upload", "application/json", `{"a":12345}`, false), status: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler(context.Background(), tt.req)
+			status := http.StatusOK
+			if err != nil {
+				status = gwerrors.HTTPStatus(err)
+			}
+			if status != tt.status {
+				t.Errorf("status = %d, want %d (error: %v)", status, tt.status, err)
+			}
+		})
+	}
+}
+
+func TestNew_RequiresMatcher(t *testing.T) {
+	if _, err := New(Config{Routes: map[string]Limit{"upload": {MaxBodySize: 1}}}, nil, slog.Default()); err == nil {
+		t.Error("expected an error without a matcher")
+	}
+}
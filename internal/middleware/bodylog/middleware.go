@@ -0,0 +1,182 @@
+package bodylog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+
+	"gateway/internal/core"
+)
+
+// DefaultMaxBytes is the number of bytes logged per body when no limit is set
+const DefaultMaxBytes = 4096
+
+// Config holds body logging middleware configuration
+type Config struct {
+	// Routes are the routes whose bodies are logged, by route ID
+	Routes map[string]RouteConfig
+}
+
+// RouteConfig holds body logging settings for a route
+type RouteConfig struct {
+	// MaxBytes is the number of bytes logged per body
+	MaxBytes int
+	// Redact are the parsed JSONPath expressions of fields whose values
+	// are replaced in logged JSON bodies, see jsonpath.Parse
+	Redact [][]string
+}
+
+// Middleware logs the request and response bodies of configured routes at
+// debug level, for debugging. Bodies are copied as they stream through, up
+// to MaxBytes, and logged once read to the end or closed, so the backend
+// and the client get them unchanged and without delay. Nothing is copied
+// unless the logger has debug logging enabled.
+type Middleware struct {
+	config Config
+	logger *slog.Logger
+}
+
+// New creates a body logging middleware
+func New(config Config, logger *slog.Logger) *Middleware {
+	for id, cfg := range config.Routes {
+		if cfg.MaxBytes <= 0 {
+			cfg.MaxBytes = DefaultMaxBytes
+		}
+		config.Routes[id] = cfg
+	}
+
+	return &Middleware{
+		config: config,
+		logger: logger.With("component", "bodylog"),
+	}
+}
+
+// Handler logs the bodies of configured routes. It needs the route result,
+// so it runs inside the route-aware handler.
+func (m *Middleware) Handler(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		route := core.RouteResultFromContext(ctx)
+		if route == nil || route.Rule == nil {
+			return next(ctx, req)
+		}
+		cfg, ok := m.config.Routes[route.Rule.ID]
+		if !ok || !m.logger.Enabled(ctx, slog.LevelDebug) {
+			return next(ctx, req)
+		}
+
+		logger := m.logger.With("request_id", req.ID(), "route", route.Rule.ID)
+		if body := req.Body(); body != nil {
+			req = &loggedRequest{
+				Request: req,
+				body:    newCaptureBody(ctx, body, cfg, logger, "Request body"),
+			}
+		}
+
+		resp, err := next(ctx, req)
+		if err != nil || resp == nil {
+			return resp, err
+		}
+		body := resp.Body()
+		if body == nil {
+			return resp, nil
+		}
+
+		logged := &loggedResponse{
+			Response: resp,
+			body:     newCaptureBody(ctx, body, cfg, logger.With("status", resp.StatusCode()), "Response body"),
+		}
+		if trailers, ok := resp.(core.TrailerResponse); ok {
+			return &loggedTrailerResponse{loggedResponse: logged, trailers: trailers}, nil
+		}
+		return logged, nil
+	}
+}
+
+// captureBody copies the start of a body as it is read and logs it once
+// the body is read to the end or closed
+type captureBody struct {
+	io.ReadCloser
+	ctx      context.Context
+	cfg      RouteConfig
+	logger   *slog.Logger
+	message  string
+	mu       sync.Mutex
+	captured bytes.Buffer
+	size     int64 // Bytes read
+	logged   bool
+}
+
+func newCaptureBody(ctx context.Context, body io.ReadCloser, cfg RouteConfig, logger *slog.Logger, message string) *captureBody {
+	return &captureBody{
+		ReadCloser: body,
+		ctx:        context.WithoutCancel(ctx),
+		cfg:        cfg,
+		logger:     logger,
+		message:    message,
+	}
+}
+
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.size += int64(n)
+	if room := b.cfg.MaxBytes - b.captured.Len(); room > 0 {
+		b.captured.Write(p[:min(n, room)])
+	}
+	if err == io.EOF {
+		b.log(true)
+	}
+	return n, err
+}
+
+func (b *captureBody) Close() error {
+	b.mu.Lock()
+	b.log(false)
+	b.mu.Unlock()
+	return b.ReadCloser.Close()
+}
+
+// log writes the captured body once. A body closed before its end is
+// logged as truncated, as is one longer than MaxBytes.
+func (b *captureBody) log(complete bool) {
+	if b.logged {
+		return
+	}
+	b.logged = true
+
+	truncated := !complete || b.size > int64(b.captured.Len())
+	b.logger.DebugContext(b.ctx, b.message,
+		"body", redactBody(b.captured.Bytes(), truncated, b.cfg.Redact),
+		"bytes", b.size,
+		"truncated", truncated,
+	)
+}
+
+// loggedRequest replaces the body of a request whose body is logged
+type loggedRequest struct {
+	core.Request
+	body io.ReadCloser
+}
+
+func (r *loggedRequest) Body() io.ReadCloser { return r.body }
+
+// loggedResponse replaces the body of a response whose body is logged
+type loggedResponse struct {
+	core.Response
+	body io.ReadCloser
+}
+
+func (r *loggedResponse) Body() io.ReadCloser { return r.body }
+
+// loggedTrailerResponse keeps the trailers of a response whose body is
+// logged
+type loggedTrailerResponse struct {
+	*loggedResponse
+	trailers core.TrailerResponse
+}
+
+func (r *loggedTrailerResponse) Trailers() map[string][]string { return r.trailers.Trailers() }
@@ -0,0 +1,217 @@
+package bodylog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gateway/internal/core"
+	"gateway/pkg/jsonpath"
+)
+
+type mockRequest struct {
+	body io.ReadCloser
+}
+
+func (m *mockRequest) ID() string                   { return "req-1" }
+func (m *mockRequest) Method() string               { return "POST" }
+func (m *mockRequest) Path() string                 { return "/api/login" }
+func (m *mockRequest) URL() string                  { return "/api/login" }
+func (m *mockRequest) RemoteAddr() string           { return "127.0.0.1:12345" }
+func (m *mockRequest) Headers() map[string][]string { return map[string][]string{} }
+func (m *mockRequest) Body() io.ReadCloser          { return m.body }
+func (m *mockRequest) Context() context.Context     { return context.Background() }
+
+// trailerResponse is a streamed response with trailers
+type trailerResponse struct {
+	body io.ReadCloser
+}
+
+func (r *trailerResponse) StatusCode() int              { return http.StatusOK }
+func (r *trailerResponse) Headers() map[string][]string { return map[string][]string{} }
+func (r *trailerResponse) Body() io.ReadCloser          { return r.body }
+func (r *trailerResponse) Trailers() map[string][]string {
+	return map[string][]string{"X-Checksum": {"abc"}}
+}
+
+func routeContext(id string) context.Context {
+	return core.WithRouteResult(context.Background(), &core.RouteResult{Rule: &core.RouteRule{ID: id}})
+}
+
+func newLogger(level slog.Level) (*slog.Logger, *bytes.Buffer) {
+	var logs bytes.Buffer
+	return slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: level})), &logs
+}
+
+func TestMiddleware_LogsBodies(t *testing.T) {
+	logger, logs := newLogger(slog.LevelDebug)
+	m := New(Config{Routes: map[string]RouteConfig{
+		"login": {Redact: [][]string{jsonpath.Parse("$.password"), jsonpath.Parse("$..token")}},
+	}}, logger)
+
+	var received string
+	handler := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		data, _ := io.ReadAll(req.Body())
+		req.Body().Close()
+		received = string(data)
+		return core.NewResponse(http.StatusOK, []byte(`{"session":{"token":"t0k3n","user":"ada"}}`)), nil
+	})
+
+	request := `{"user":"ada","password":"hunter2"}`
+	resp, err := handler(routeContext("login"), &mockRequest{body: io.NopCloser(strings.NewReader(request))})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	body := resp.Body()
+	data, _ := io.ReadAll(body)
+	body.Close()
+
+	// Bodies pass through unchanged
+	if received != request {
+		t.Errorf("Backend received %q, want %q", received, request)
+	}
+	if string(data) != `{"session":{"token":"t0k3n","user":"ada"}}` {
+		t.Errorf("Client received %q", data)
+	}
+
+	output := logs.String()
+	for _, want := range []string{
+		`msg="Request body"`,
+		`msg="Response body"`,
+		"request_id=req-1",
+		"route=login",
+		`\"password\":\"[REDACTED]\"`,
+		`\"token\":\"[REDACTED]\"`,
+		`\"user\":\"ada\"`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %s in log output, got %s", want, output)
+		}
+	}
+	if strings.Contains(output, "hunter2") || strings.Contains(output, "t0k3n") {
+		t.Errorf("Expected secrets to be redacted, got %s", output)
+	}
+	if strings.Count(output, "Request body") != 1 {
+		t.Errorf("Expected the request body to be logged once, got %s", output)
+	}
+}
+
+func TestMiddleware_Truncates(t *testing.T) {
+	logger, logs := newLogger(slog.LevelDebug)
+	m := New(Config{Routes: map[string]RouteConfig{"upload": {MaxBytes: 8}}}, logger)
+
+	handler := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		io.Copy(io.Discard, req.Body())
+		return nil, nil
+	})
+	handler(routeContext("upload"), &mockRequest{body: io.NopCloser(strings.NewReader(strings.Repeat("x", 100)))})
+
+	output := logs.String()
+	if !strings.Contains(output, "body=xxxxxxxx bytes=100 truncated=true") {
+		t.Errorf("Expected the first 8 of 100 bytes to be logged, got %s", output)
+	}
+}
+
+func TestMiddleware_StreamsResponse(t *testing.T) {
+	logger, logs := newLogger(slog.LevelDebug)
+	m := New(Config{Routes: map[string]RouteConfig{"events": {}}}, logger)
+
+	reader, writer := io.Pipe()
+	handler := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		return &trailerResponse{body: reader}, nil
+	})
+	resp, err := handler(routeContext("events"), &mockRequest{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	if _, ok := resp.(core.TrailerResponse); !ok {
+		t.Fatal("Expected the trailers to be kept")
+	}
+
+	// Each chunk reaches the client as it is written
+	go writer.Write([]byte("first"))
+	buf := make([]byte, 16)
+	n, err := resp.Body().Read(buf)
+	if err != nil || string(buf[:n]) != "first" {
+		t.Fatalf("Read %q, %v, want the first chunk", buf[:n], err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected nothing logged before the body ends, got %s", logs.String())
+	}
+
+	go func() {
+		writer.Write([]byte(" second"))
+		writer.Close()
+	}()
+	io.ReadAll(resp.Body())
+	if !strings.Contains(logs.String(), `body="first second" bytes=12 truncated=false`) {
+		t.Errorf("Expected the whole body logged at its end, got %s", logs.String())
+	}
+}
+
+func TestMiddleware_Disabled(t *testing.T) {
+	tests := []struct {
+		name  string
+		level slog.Level
+		route string
+	}{
+		{"info level", slog.LevelInfo, "login"},
+		{"other route", slog.LevelDebug, "health"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := newLogger(tt.level)
+			m := New(Config{Routes: map[string]RouteConfig{"login": {}}}, logger)
+
+			body := io.NopCloser(strings.NewReader("secret"))
+			handler := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+				if req.Body() != body {
+					t.Error("Expected the request body not to be wrapped")
+				}
+				io.ReadAll(req.Body())
+				return nil, nil
+			})
+			handler(routeContext(tt.route), &mockRequest{body: body})
+
+			if logs.Len() != 0 {
+				t.Errorf("Expected nothing logged, got %s", logs.String())
+			}
+		})
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		paths     []string
+		truncated bool
+		want      string
+	}{
+		{"no paths", `{"a":1}`, nil, false, `{"a":1}`},
+		{"field", `{"a":1,"b":2}`, []string{"$.a"}, false, `{"a":"[REDACTED]","b":2}`},
+		{"nested", `{"user":{"password":"x","name":"n"}}`, []string{"$.user.password"}, false, `{"user":{"name":"n","password":"[REDACTED]"}}`},
+		{"array elements", `{"items":[{"token":"x"},{"token":"y","id":1}]}`, []string{"$.items[*].token"}, false, `{"items":[{"token":"[REDACTED]"},{"id":1,"token":"[REDACTED]"}]}`},
+		{"implicit array", `{"items":[{"token":"x"}]}`, []string{"$.items.token"}, false, `{"items":[{"token":"[REDACTED]"}]}`},
+		{"recursive", `{"a":{"secret":1,"b":[{"secret":2}]},"secret":3}`, []string{"$..secret"}, false, `{"a":{"b":[{"secret":"[REDACTED]"}],"secret":"[REDACTED]"},"secret":"[REDACTED]"}`},
+		{"whole array", `{"keys":["x","y"]}`, []string{"$.keys[*]"}, false, `{"keys":["[REDACTED]","[REDACTED]"]}`},
+		{"no match", `{"a":1}`, []string{"$.b.c"}, false, `{"a":1}`},
+		{"not JSON", `password=x`, []string{"$.password"}, false, redacted},
+		{"truncated", `{"a":1}`, []string{"$.b"}, true, redacted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths [][]string
+			for _, path := range tt.paths {
+				paths = append(paths, jsonpath.Parse(path))
+			}
+			if got := redactBody([]byte(tt.body), tt.truncated, paths); got != tt.want {
+				t.Errorf("redactBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
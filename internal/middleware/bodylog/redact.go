@@ -0,0 +1,60 @@
+package bodylog
+
+import (
+	"encoding/json"
+
+	"gateway/pkg/jsonpath"
+)
+
+// redacted replaces the values of redacted fields, and bodies whose fields
+// cannot be located
+const redacted = "[REDACTED]"
+
+// redactBody replaces the values of paths in a JSON body. Bodies that
+// cannot be parsed, including truncated ones, are redacted entirely when
+// paths are configured, since their fields cannot be located.
+func redactBody(body []byte, truncated bool, paths [][]string) string {
+	if len(paths) == 0 {
+		return string(body)
+	}
+
+	var value any
+	if truncated || json.Unmarshal(body, &value) != nil {
+		return redacted
+	}
+	data, err := json.Marshal(redactValue(value, paths))
+	if err != nil {
+		return redacted
+	}
+	return string(data)
+}
+
+// redactValue replaces the values of paths in a decoded JSON value in place
+func redactValue(value any, paths [][]string) any {
+	switch v := value.(type) {
+	case []any:
+		elementPaths := jsonpath.Elements(paths)
+		if jsonpath.Selected(elementPaths) {
+			for i := range v {
+				v[i] = redacted
+			}
+			return v
+		}
+		for i, item := range v {
+			v[i] = redactValue(item, elementPaths)
+		}
+
+	case map[string]any:
+		for key, child := range v {
+			next := jsonpath.Child(paths, key)
+			switch {
+			case jsonpath.Selected(next):
+				v[key] = redacted
+			case len(next) > 0:
+				v[key] = redactValue(child, next)
+			}
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,68 @@
+// Package buffered reads request and response bodies into memory so they
+// can be sent or shared more than once, replaying bodies too large to keep.
+package buffered
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"gateway/internal/core"
+)
+
+// Read reads up to limit bytes of body. It returns the body, a reader
+// replaying the bytes read followed by the rest of body, and whether the
+// whole body fit in limit. The body is only returned when it fits; closing
+// the replaying reader closes body.
+func Read(body io.ReadCloser, limit int64) ([]byte, io.ReadCloser, bool) {
+	if body == nil || body == http.NoBody {
+		return nil, http.NoBody, true
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	replay := &replayBody{Reader: io.MultiReader(bytes.NewReader(data), body), Closer: body}
+	if err != nil || int64(len(data)) > limit {
+		return nil, replay, false
+	}
+	return data, replay, true
+}
+
+// Body returns a reader of data, or http.NoBody when data is nil
+func Body(data []byte) io.ReadCloser {
+	if data == nil {
+		return http.NoBody
+	}
+	return io.NopCloser(bytes.NewReader(data))
+}
+
+// Request returns req with its body replaced by body
+func Request(req core.Request, body io.ReadCloser) core.Request {
+	return &request{Request: req, body: body}
+}
+
+// Response returns resp with its body replaced by body
+func Response(resp core.Response, body io.ReadCloser) core.Response {
+	return &response{Response: resp, body: body}
+}
+
+// replayBody replays a buffered prefix followed by the rest of a body
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// request replaces the body of a request whose body was buffered
+type request struct {
+	core.Request
+	body io.ReadCloser
+}
+
+func (r *request) Body() io.ReadCloser { return r.body }
+
+// response replaces the body of a response whose body was buffered
+type response struct {
+	core.Response
+	body io.ReadCloser
+}
+
+func (r *response) Body() io.ReadCloser { return r.body }
@@ -0,0 +1,65 @@
+package buffered
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// closeRecorder records whether a body was closed
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		limit    int64
+		wantData string
+		wantFits bool
+	}{
+		{"fits", "hello", 5, "hello", true},
+		{"too large", "hello world", 5, "", false},
+		{"empty", "", 5, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &closeRecorder{Reader: strings.NewReader(tt.body)}
+			data, replay, fits := Read(body, tt.limit)
+			if fits != tt.wantFits || string(data) != tt.wantData {
+				t.Errorf("Read() = %q, %v, want %q, %v", data, fits, tt.wantData, tt.wantFits)
+			}
+
+			// The replaying reader returns the whole body either way
+			replayed, err := io.ReadAll(replay)
+			if err != nil || string(replayed) != tt.body {
+				t.Errorf("Replayed %q, %v, want %q", replayed, err, tt.body)
+			}
+			replay.Close()
+			if !body.closed {
+				t.Error("Expected closing the replaying reader to close the body")
+			}
+		})
+	}
+}
+
+func TestReadNoBody(t *testing.T) {
+	for _, body := range []io.ReadCloser{nil, http.NoBody} {
+		data, replay, fits := Read(body, 5)
+		if data != nil || replay != http.NoBody || !fits {
+			t.Errorf("Read(%v) = %v, %v, %v, want no body", body, data, replay, fits)
+		}
+	}
+	if Body(nil) != http.NoBody {
+		t.Error("Expected Body(nil) to be http.NoBody")
+	}
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"sort"
 	"sync"
+	"time"
 
 	"gateway/pkg/circuitbreaker"
 	"gateway/internal/core"
 	gwerrors "gateway/pkg/errors"
 )
 
+// ErrCircuitOpen matches errors of requests rejected by an open breaker
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
 // Config holds circuit breaker middleware configuration
 type Config struct {
 	// Default circuit breaker config for all routes
@@ -21,10 +26,28 @@ type Config struct {
 	Services map[string]circuitbreaker.Config
 }
 
+// StateMetricsRecorder receives the circuit breaker state of a service
+type StateMetricsRecorder interface {
+	RecordCircuitBreakerState(ctx context.Context, service string, state int64)
+}
+
+// BreakerStatus describes a circuit breaker
+type BreakerStatus struct {
+	Key             string    `json:"key"`
+	Service         string    `json:"service,omitempty"`
+	State           string    `json:"state"`
+	Failures        int       `json:"failures"`
+	Successes       int       `json:"successes"`
+	LastFailureTime time.Time `json:"lastFailureTime"`
+	LastStateChange time.Time `json:"lastStateChange"`
+}
+
 // Middleware implements circuit breaker pattern for backend requests
 type Middleware struct {
 	config   Config
 	breakers sync.Map // map[string]*circuitbreaker.CircuitBreaker
+	services sync.Map // map[string]string, breaker key -> service name
+	metrics  StateMetricsRecorder
 	logger   *slog.Logger
 }
 
@@ -36,6 +59,12 @@ func New(config Config, logger *slog.Logger) *Middleware {
 	}
 }
 
+// WithMetrics sets the recorder notified of circuit breaker state changes
+func (m *Middleware) WithMetrics(metrics StateMetricsRecorder) *Middleware {
+	m.metrics = metrics
+	return m
+}
+
 // Apply returns a middleware function that applies circuit breaking
 func (m *Middleware) Apply() core.Middleware {
 	return func(next core.Handler) core.Handler {
@@ -44,7 +73,7 @@ func (m *Middleware) Apply() core.Middleware {
 			key := m.getCircuitBreakerKey(ctx, req)
 
 			// Get or create circuit breaker for this key
-			cb := m.getOrCreateBreaker(key)
+			cb := m.getOrCreateBreaker(key, routeID(ctx), serviceName(ctx))
 
 			// Check if request is allowed
 			if !cb.Allow() {
@@ -60,6 +89,7 @@ func (m *Middleware) Apply() core.Middleware {
 						"circuit_breaker": "open",
 						"key":             key,
 					},
+					Cause: ErrCircuitOpen,
 				}
 			}
 
@@ -86,20 +116,22 @@ func (m *Middleware) Apply() core.Middleware {
 	}
 }
 
-// routeContextKey is the key for storing route info in context
-type routeContextKey struct{}
-
 // getCircuitBreakerKey determines the circuit breaker key for a request
 func (m *Middleware) getCircuitBreakerKey(ctx context.Context, req core.Request) string {
 	// Try to get route result from context (set by route-aware middleware)
-	if route, ok := ctx.Value(routeContextKey{}).(*core.RouteResult); ok && route != nil {
-		// Prefer route ID if available
-		if route.Rule != nil && route.Rule.ID != "" {
+	if route := core.RouteResultFromContext(ctx); route != nil && route.Rule != nil {
+		service := serviceName(ctx)
+		// Prefer route ID if available. The services of a split route fail
+		// independently, so each gets its own breaker.
+		if route.Rule.ID != "" {
+			if route.Rule.TrafficSplit != nil && service != "" {
+				return "route:" + route.Rule.ID + ":service:" + service
+			}
 			return "route:" + route.Rule.ID
 		}
 		// Fall back to service name
-		if route.Rule != nil && route.Rule.ServiceName != "" {
-			return "service:" + route.Rule.ServiceName
+		if service != "" {
+			return "service:" + service
 		}
 	}
 
@@ -107,15 +139,40 @@ func (m *Middleware) getCircuitBreakerKey(ctx context.Context, req core.Request)
 	return "path:" + req.Path()
 }
 
+// routeID returns the ID of the request's route, if known
+func routeID(ctx context.Context) string {
+	if route := core.RouteResultFromContext(ctx); route != nil && route.Rule != nil {
+		return route.Rule.ID
+	}
+	return ""
+}
+
+// serviceName returns the backend service for the request, if known. On
+// split routes this is the service picked for the request, not that of the
+// rule.
+func serviceName(ctx context.Context) string {
+	route := core.RouteResultFromContext(ctx)
+	if route == nil {
+		return ""
+	}
+	if route.ServiceName != "" {
+		return route.ServiceName
+	}
+	if route.Rule != nil {
+		return route.Rule.ServiceName
+	}
+	return ""
+}
+
 // getOrCreateBreaker gets or creates a circuit breaker for the given key
-func (m *Middleware) getOrCreateBreaker(key string) *circuitbreaker.CircuitBreaker {
+func (m *Middleware) getOrCreateBreaker(key, routeID, service string) *circuitbreaker.CircuitBreaker {
 	// Try to get existing breaker
 	if breaker, ok := m.breakers.Load(key); ok {
 		return breaker.(*circuitbreaker.CircuitBreaker)
 	}
 
 	// Create new breaker with appropriate config
-	config := m.getConfig(key)
+	config := m.getConfig(routeID, service)
 
 	// Add state change logging
 	originalOnChange := config.OnStateChange
@@ -125,6 +182,7 @@ func (m *Middleware) getOrCreateBreaker(key string) *circuitbreaker.CircuitBreak
 			"from", from.String(),
 			"to", to.String(),
 		)
+		m.recordState(service)
 		if originalOnChange != nil {
 			originalOnChange(from, to)
 		}
@@ -133,26 +191,48 @@ func (m *Middleware) getOrCreateBreaker(key string) *circuitbreaker.CircuitBreak
 	breaker := circuitbreaker.New(config)
 
 	// Store and return
+	if service != "" {
+		m.services.LoadOrStore(key, service)
+	}
 	actual, _ := m.breakers.LoadOrStore(key, breaker)
 	return actual.(*circuitbreaker.CircuitBreaker)
 }
 
-// getConfig returns the configuration for a given key
-func (m *Middleware) getConfig(key string) circuitbreaker.Config {
-	// Check for specific route config
-	if len(key) > 6 && key[:6] == "route:" {
-		routeID := key[6:]
-		if config, ok := m.config.Routes[routeID]; ok {
-			return config
+// recordState reports the state of a service's breakers to the metrics
+// recorder; the most restrictive state of the service's breakers wins
+func (m *Middleware) recordState(service string) {
+	if m.metrics == nil || service == "" {
+		return
+	}
+
+	state := circuitbreaker.StateClosed
+	m.breakers.Range(func(key, value interface{}) bool {
+		if s, _ := m.services.Load(key); s != service {
+			return true
 		}
+		switch value.(*circuitbreaker.CircuitBreaker).State() {
+		case circuitbreaker.StateOpen:
+			state = circuitbreaker.StateOpen
+			return false
+		case circuitbreaker.StateHalfOpen:
+			state = circuitbreaker.StateHalfOpen
+		}
+		return true
+	})
+	m.metrics.RecordCircuitBreakerState(context.Background(), service, int64(state))
+}
+
+// getConfig returns the configuration of a route's breaker, that of its
+// service when the route has none
+func (m *Middleware) getConfig(routeID, service string) circuitbreaker.Config {
+	// Check for specific route config
+	if config, ok := m.config.Routes[routeID]; ok && routeID != "" {
+		return config
 	}
 
 	// Check for specific service config
-	if len(key) > 8 && key[:8] == "service:" {
-		serviceName := key[8:]
-		if config, ok := m.config.Services[serviceName]; ok {
-			return config
-		}
+	if config, ok := m.config.Services[service]; ok && service != "" {
+		return config
 	}
 
 	// Return default config
@@ -168,7 +248,9 @@ func (m *Middleware) isRetryableError(err error) bool {
 		case gwerrors.ErrorTypeBadRequest,
 			gwerrors.ErrorTypeUnauthorized,
 			gwerrors.ErrorTypeForbidden,
-			gwerrors.ErrorTypeNotFound:
+			gwerrors.ErrorTypeNotFound,
+			gwerrors.ErrorTypePayloadTooLarge,
+			gwerrors.ErrorTypeUnsupportedMediaType:
 			return false
 		}
 	}
@@ -185,6 +267,48 @@ func (m *Middleware) GetBreaker(key string) *circuitbreaker.CircuitBreaker {
 	return nil
 }
 
+// Status returns the status of all circuit breakers, sorted by key
+func (m *Middleware) Status() []BreakerStatus {
+	var statuses []BreakerStatus
+	m.breakers.Range(func(key, value interface{}) bool {
+		stats := value.(*circuitbreaker.CircuitBreaker).Stats()
+		service, _ := m.services.Load(key)
+		name, _ := service.(string)
+		statuses = append(statuses, BreakerStatus{
+			Key:             key.(string),
+			Service:         name,
+			State:           stats.State.String(),
+			Failures:        stats.Failures,
+			Successes:       stats.Successes,
+			LastFailureTime: stats.LastFailureTime,
+			LastStateChange: stats.LastStateChange,
+		})
+		return true
+	})
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Key < statuses[j].Key
+	})
+	return statuses
+}
+
+// ResetService closes the circuit breakers of a service and clears their
+// counters. It returns false if the service has no circuit breakers.
+func (m *Middleware) ResetService(service string) bool {
+	found := false
+	m.breakers.Range(func(key, value interface{}) bool {
+		if s, _ := m.services.Load(key); s == service {
+			value.(*circuitbreaker.CircuitBreaker).Reset()
+			found = true
+		}
+		return true
+	})
+	if found {
+		m.logger.Info("circuit breakers reset", "service", service)
+		m.recordState(service)
+	}
+	return found
+}
+
 // ResetAll resets all circuit breakers
 func (m *Middleware) ResetAll() {
 	m.breakers.Range(func(key, value interface{}) bool {
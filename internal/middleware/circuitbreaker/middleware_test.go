@@ -139,7 +139,7 @@ func TestMiddleware_RouteBasedCircuitBreaker(t *testing.T) {
 			ID: "api-route",
 		},
 	}
-	ctx := context.WithValue(context.Background(), routeContextKey{}, routeResult)
+	ctx := core.WithRouteResult(context.Background(), routeResult)
 
 	req := &mockRequest{path: "/api/test"}
 
@@ -183,7 +183,7 @@ func TestMiddleware_ServiceBasedCircuitBreaker(t *testing.T) {
 			ServiceName: "payment-service",
 		},
 	}
-	ctx := context.WithValue(context.Background(), routeContextKey{}, routeResult)
+	ctx := core.WithRouteResult(context.Background(), routeResult)
 
 	// Get the breaker that will be created
 	key := "service:payment-service"
@@ -210,6 +210,47 @@ func TestMiddleware_ServiceBasedCircuitBreaker(t *testing.T) {
 	}
 }
 
+func TestMiddleware_SplitRouteCircuitBreaker(t *testing.T) {
+	config := Config{
+		Default: circuitbreaker.Config{
+			MaxFailures: 5,
+			Timeout:     time.Minute,
+		},
+		Services: map[string]circuitbreaker.Config{
+			"orders-canary": {
+				MaxFailures: 1,
+				Timeout:     time.Minute,
+			},
+		},
+	}
+	middleware := New(config, slog.Default())
+
+	// Only the canary fails
+	wrapped := middleware.Apply()(func(ctx context.Context, req core.Request) (core.Response, error) {
+		if core.RouteResultFromContext(ctx).ServiceName == "orders-canary" {
+			return nil, errors.New("failure")
+		}
+		return &mockResponse{statusCode: 200}, nil
+	})
+
+	// Split routes name the picked service on the result, not the rule
+	rule := &core.RouteRule{ID: "orders", TrafficSplit: &core.TrafficSplit{}}
+	for _, service := range []string{"orders-canary", "orders"} {
+		route := &core.RouteResult{Rule: rule, ServiceName: service}
+		wrapped(core.WithRouteResult(context.Background(), route), &mockRequest{path: "/orders"})
+	}
+
+	if canary := middleware.GetBreaker("route:orders:service:orders-canary"); canary == nil || canary.State() != circuitbreaker.StateOpen {
+		t.Error("Expected the canary breaker to open")
+	}
+	if stable := middleware.GetBreaker("route:orders:service:orders"); stable == nil || stable.State() != circuitbreaker.StateClosed {
+		t.Error("Expected the stable breaker to stay closed")
+	}
+	if got := middleware.getConfig("orders", "orders-canary").MaxFailures; got != 1 {
+		t.Errorf("Expected the canary service config, got maxFailures %d", got)
+	}
+}
+
 func TestMiddleware_NonRetryableErrors(t *testing.T) {
 	config := Config{
 		Default: circuitbreaker.Config{
@@ -422,4 +463,80 @@ func TestMiddleware_StateChangeCallback(t *testing.T) {
 	if !changed {
 		t.Error("Expected state change callback to be called")
 	}
-}
\ No newline at end of file
+}
+type mockStateRecorder struct {
+	mu     sync.Mutex
+	states map[string]int64
+}
+
+func (m *mockStateRecorder) RecordCircuitBreakerState(ctx context.Context, service string, state int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[service] = state
+}
+
+func (m *mockStateRecorder) state(service string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.states[service]
+}
+
+func TestMiddleware_ResetService(t *testing.T) {
+	config := Config{
+		Default: circuitbreaker.Config{
+			MaxFailures: 1,
+			Timeout:     time.Minute,
+		},
+	}
+	recorder := &mockStateRecorder{states: make(map[string]int64)}
+	middleware := New(config, slog.Default()).WithMetrics(recorder)
+
+	wrapped := middleware.Apply()(func(ctx context.Context, req core.Request) (core.Response, error) {
+		return nil, errors.New("failure")
+	})
+
+	// Two routes to the same service and one to another
+	routes := []*core.RouteResult{
+		{Rule: &core.RouteRule{ID: "users-read", ServiceName: "users"}},
+		{Rule: &core.RouteRule{ID: "users-write", ServiceName: "users"}},
+		{Rule: &core.RouteRule{ID: "orders", ServiceName: "orders"}},
+	}
+	for _, route := range routes {
+		wrapped(core.WithRouteResult(context.Background(), route), &mockRequest{path: "/"})
+	}
+
+	for _, status := range middleware.Status() {
+		if status.State != "open" {
+			t.Errorf("Expected breaker %s to be open, got %s", status.Key, status.State)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for recorder.state("users") != int64(circuitbreaker.StateOpen) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if recorder.state("users") != int64(circuitbreaker.StateOpen) {
+		t.Fatal("Expected open state to be recorded for users")
+	}
+
+	if middleware.ResetService("unknown") {
+		t.Error("Expected reset of unknown service to report no breakers")
+	}
+	if !middleware.ResetService("users") {
+		t.Fatal("Expected reset of users to find breakers")
+	}
+
+	// The state is recorded before ResetService returns
+	if recorder.state("users") != int64(circuitbreaker.StateClosed) {
+		t.Error("Expected closed state to be recorded for users")
+	}
+
+	for _, status := range middleware.Status() {
+		want := "closed"
+		if status.Service == "orders" {
+			want = "open"
+		}
+		if status.State != want || (want == "closed" && status.Failures != 0) {
+			t.Errorf("Expected breaker %s to be %s with cleared counters, got %+v", status.Key, want, status)
+		}
+	}
+}
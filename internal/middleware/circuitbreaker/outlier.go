@@ -0,0 +1,405 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gateway/internal/connector"
+	"gateway/internal/core"
+	"gateway/pkg/circuitbreaker"
+	gwerrors "gateway/pkg/errors"
+)
+
+// OutlierConfig holds per-instance outlier detection settings
+type OutlierConfig struct {
+	// EjectionThreshold is the error rate (0-1) at which an instance is ejected
+	EjectionThreshold float64
+	// MinRequests is the number of requests in an interval before the error rate is evaluated
+	MinRequests int
+	// MaxEjectionPercent caps the percentage of a service's instances that can be ejected at once
+	MaxEjectionPercent int
+	// BaseEjectionTime is multiplied by the number of consecutive ejections
+	BaseEjectionTime time.Duration
+	// Interval is the period after which request counters are cleared
+	Interval time.Duration
+}
+
+// InstanceMetricsRecorder receives service instance counts after ejections are applied
+type InstanceMetricsRecorder interface {
+	RecordServiceInstances(ctx context.Context, service string, total, healthy int64)
+}
+
+// instanceState tracks outcomes and ejection state for a single instance
+type instanceState struct {
+	state        circuitbreaker.State
+	requests     int
+	failures     int
+	windowStart  time.Time
+	ejectedUntil time.Time
+	ejections    int  // consecutive ejections, drives the ejection time
+	probing      bool // a half-open probe request is in flight
+}
+
+// OutlierDetector ejects individual service instances whose error rate
+// exceeds the configured threshold, independently of route or service breakers.
+//
+// An ejected instance is excluded from load balancing until its ejection time
+// expires. It then moves to half-open and receives a single probe request:
+// success reinstates it, failure ejects it again for a longer period.
+type OutlierDetector struct {
+	config    OutlierConfig
+	mu        sync.Mutex
+	instances map[string]map[string]*instanceState // service -> instanceID -> state
+	sizes     map[string]int                       // service -> last seen instance count
+	metrics   InstanceMetricsRecorder
+	logger    *slog.Logger
+}
+
+// NewOutlierDetector creates a new outlier detector
+func NewOutlierDetector(config OutlierConfig, logger *slog.Logger) *OutlierDetector {
+	if config.EjectionThreshold <= 0 || config.EjectionThreshold > 1 {
+		config.EjectionThreshold = 0.5
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 5
+	}
+	if config.MaxEjectionPercent <= 0 || config.MaxEjectionPercent > 100 {
+		config.MaxEjectionPercent = 50
+	}
+	if config.BaseEjectionTime <= 0 {
+		config.BaseEjectionTime = 30 * time.Second
+	}
+	if config.Interval <= 0 {
+		config.Interval = 10 * time.Second
+	}
+
+	return &OutlierDetector{
+		config:    config,
+		instances: make(map[string]map[string]*instanceState),
+		sizes:     make(map[string]int),
+		logger:    logger.With("component", "outlier-detection"),
+	}
+}
+
+// WithMetrics sets the recorder used to report healthy instance counts
+func (d *OutlierDetector) WithMetrics(metrics InstanceMetricsRecorder) *OutlierDetector {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.metrics = metrics
+	return d
+}
+
+// Filter returns a copy of instances with ejected instances marked unhealthy
+func (d *OutlierDetector) Filter(service string, instances []core.ServiceInstance) []core.ServiceInstance {
+	d.mu.Lock()
+	now := time.Now()
+	d.sizes[service] = len(instances)
+
+	filtered := make([]core.ServiceInstance, len(instances))
+	copy(filtered, instances)
+
+	var healthy int64
+	for i := range filtered {
+		if st := d.stateLocked(service, filtered[i].ID, false); st != nil {
+			d.updateStateLocked(service, filtered[i].ID, st, now)
+			if st.state == circuitbreaker.StateOpen || (st.state == circuitbreaker.StateHalfOpen && st.probing) {
+				filtered[i].Healthy = false
+			}
+		}
+		if filtered[i].Healthy {
+			healthy++
+		}
+	}
+	metrics := d.metrics
+	d.mu.Unlock()
+
+	if metrics != nil {
+		metrics.RecordServiceInstances(context.Background(), service, int64(len(instances)), healthy)
+	}
+
+	return filtered
+}
+
+// Begin marks the start of a request to an instance. The first request to a
+// half-open instance claims the probe slot, which probe reports and End must
+// release. Requests reaching a half-open instance while another probe is in
+// flight are not counted, so record is false and only the probe decides.
+func (d *OutlierDetector) Begin(service, instanceID string) (probe, record bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st := d.stateLocked(service, instanceID, false)
+	if st == nil || st.state != circuitbreaker.StateHalfOpen {
+		return false, true
+	}
+	if st.probing {
+		return false, false
+	}
+	st.probing = true
+	return true, true
+}
+
+// End releases the probe slot claimed by Begin when the probe ended without
+// an outcome being recorded, so that the next request probes again
+func (d *OutlierDetector) End(service, instanceID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if st := d.stateLocked(service, instanceID, false); st != nil && st.state == circuitbreaker.StateHalfOpen {
+		st.probing = false
+	}
+}
+
+// Record records the outcome of a request to an instance
+func (d *OutlierDetector) Record(service, instanceID string, success bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	st := d.stateLocked(service, instanceID, true)
+	d.updateStateLocked(service, instanceID, st, now)
+
+	switch st.state {
+	case circuitbreaker.StateHalfOpen:
+		if success {
+			d.logger.Info("instance reinstated",
+				"service", service,
+				"instance", instanceID,
+			)
+			st.state = circuitbreaker.StateClosed
+			st.ejections = 0
+			st.probing = false
+			st.requests = 0
+			st.failures = 0
+			st.windowStart = now
+			return
+		}
+		d.ejectLocked(service, instanceID, st, now)
+
+	case circuitbreaker.StateClosed:
+		st.requests++
+		if !success {
+			st.failures++
+		}
+		if st.requests < d.config.MinRequests {
+			return
+		}
+		if float64(st.failures)/float64(st.requests) < d.config.EjectionThreshold {
+			return
+		}
+		if !d.canEjectLocked(service) {
+			d.logger.Debug("ejection skipped, max ejection percent reached",
+				"service", service,
+				"instance", instanceID,
+			)
+			return
+		}
+		d.ejectLocked(service, instanceID, st, now)
+	}
+}
+
+// State returns the ejection state of an instance
+func (d *OutlierDetector) State(service, instanceID string) circuitbreaker.State {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st := d.stateLocked(service, instanceID, false)
+	if st == nil {
+		return circuitbreaker.StateClosed
+	}
+	d.updateStateLocked(service, instanceID, st, time.Now())
+	return st.state
+}
+
+// Reset reinstates all ejected instances and clears their statistics
+func (d *OutlierDetector) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.instances = make(map[string]map[string]*instanceState)
+}
+
+// stateLocked returns the state for an instance, optionally creating it
+func (d *OutlierDetector) stateLocked(service, instanceID string, create bool) *instanceState {
+	serviceStates, ok := d.instances[service]
+	if !ok {
+		if !create {
+			return nil
+		}
+		serviceStates = make(map[string]*instanceState)
+		d.instances[service] = serviceStates
+	}
+
+	st, ok := serviceStates[instanceID]
+	if !ok && create {
+		st = &instanceState{
+			state:       circuitbreaker.StateClosed,
+			windowStart: time.Now(),
+		}
+		serviceStates[instanceID] = st
+	}
+	return st
+}
+
+// updateStateLocked moves expired ejections to half-open and rotates the stats window
+func (d *OutlierDetector) updateStateLocked(service, instanceID string, st *instanceState, now time.Time) {
+	switch st.state {
+	case circuitbreaker.StateOpen:
+		if now.After(st.ejectedUntil) {
+			st.state = circuitbreaker.StateHalfOpen
+			st.probing = false
+			d.logger.Debug("instance ejection expired, probing",
+				"service", service,
+				"instance", instanceID,
+			)
+		}
+	case circuitbreaker.StateClosed:
+		if now.Sub(st.windowStart) >= d.config.Interval {
+			st.requests = 0
+			st.failures = 0
+			st.windowStart = now
+		}
+	}
+}
+
+// canEjectLocked checks whether ejecting one more instance stays within MaxEjectionPercent
+func (d *OutlierDetector) canEjectLocked(service string) bool {
+	total := d.sizes[service]
+	if total == 0 {
+		return false
+	}
+
+	ejected := 0
+	for _, st := range d.instances[service] {
+		if st.state != circuitbreaker.StateClosed {
+			ejected++
+		}
+	}
+
+	// Always allow at least one ejection so small services are still protected
+	maxEjected := total * d.config.MaxEjectionPercent / 100
+	if maxEjected < 1 {
+		maxEjected = 1
+	}
+	// Never eject every instance of a service
+	if maxEjected >= total {
+		maxEjected = total - 1
+	}
+
+	return ejected < maxEjected
+}
+
+// ejectLocked ejects an instance for BaseEjectionTime times its consecutive ejections
+func (d *OutlierDetector) ejectLocked(service, instanceID string, st *instanceState, now time.Time) {
+	st.ejections++
+	duration := d.config.BaseEjectionTime * time.Duration(st.ejections)
+
+	st.state = circuitbreaker.StateOpen
+	st.ejectedUntil = now.Add(duration)
+	st.probing = false
+	st.requests = 0
+	st.failures = 0
+	st.windowStart = now
+
+	d.logger.Warn("instance ejected",
+		"service", service,
+		"instance", instanceID,
+		"duration", duration,
+		"ejections", st.ejections,
+	)
+}
+
+// WrapRegistry returns a registry that hides ejected instances from load balancers
+func (d *OutlierDetector) WrapRegistry(registry core.ServiceRegistry) core.ServiceRegistry {
+	return &outlierRegistry{
+		registry: registry,
+		detector: d,
+	}
+}
+
+// WrapConnector returns a connector that reports per-instance outcomes to the detector
+func (d *OutlierDetector) WrapConnector(conn connector.Connector) connector.Connector {
+	return &outlierConnector{
+		next:     conn,
+		detector: d,
+	}
+}
+
+// outlierRegistry filters ejected instances out of service discovery results
+type outlierRegistry struct {
+	registry core.ServiceRegistry
+	detector *OutlierDetector
+}
+
+// GetService returns instances with ejected ones marked unhealthy
+func (r *outlierRegistry) GetService(name string) ([]core.ServiceInstance, error) {
+	instances, err := r.registry.GetService(name)
+	if err != nil {
+		return nil, err
+	}
+	return r.detector.Filter(name, instances), nil
+}
+
+// Close closes the underlying registry if it supports it
+func (r *outlierRegistry) Close() error {
+	if closer, ok := r.registry.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// outlierConnector records backend outcomes per instance
+type outlierConnector struct {
+	next     connector.Connector
+	detector *OutlierDetector
+}
+
+// Forward forwards the request and records the result against the selected instance
+func (c *outlierConnector) Forward(ctx context.Context, req core.Request, route *core.RouteResult) (core.Response, error) {
+	if route == nil || route.Instance == nil {
+		return c.next.Forward(ctx, req, route)
+	}
+
+	service := route.ServiceName
+	if service == "" {
+		service = route.Instance.Name
+	}
+	instanceID := route.Instance.ID
+
+	probe, record := c.detector.Begin(service, instanceID)
+	if probe {
+		defer c.detector.End(service, instanceID)
+	}
+	resp, err := c.next.Forward(ctx, req, route)
+	if !record {
+		return resp, err
+	}
+
+	if err != nil {
+		// Client-side errors say nothing about the instance
+		if isInstanceFailure(err) {
+			c.detector.Record(service, instanceID, false)
+		}
+		return resp, err
+	}
+
+	c.detector.Record(service, instanceID, resp == nil || resp.StatusCode() < 500)
+	return resp, nil
+}
+
+// isInstanceFailure determines if a backend error should count against the instance
+func isInstanceFailure(err error) bool {
+	var gwErr *gwerrors.Error
+	if errors.As(err, &gwErr) {
+		switch gwErr.Type {
+		case gwerrors.ErrorTypeBadRequest,
+			gwerrors.ErrorTypeUnauthorized,
+			gwerrors.ErrorTypeForbidden,
+			gwerrors.ErrorTypeNotFound:
+			return false
+		}
+	}
+	return !errors.Is(err, context.Canceled)
+}
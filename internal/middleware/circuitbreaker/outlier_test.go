@@ -0,0 +1,306 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/pkg/circuitbreaker"
+	gwerrors "gateway/pkg/errors"
+)
+
+// mockRegistry returns a fixed set of instances
+type mockRegistry struct {
+	instances []core.ServiceInstance
+}
+
+func (r *mockRegistry) GetService(name string) ([]core.ServiceInstance, error) {
+	return r.instances, nil
+}
+
+// mockConnector returns a configured response or error per instance
+type mockConnector struct {
+	status map[string]int
+	err    map[string]error
+}
+
+func (c *mockConnector) Forward(ctx context.Context, req core.Request, route *core.RouteResult) (core.Response, error) {
+	if err := c.err[route.Instance.ID]; err != nil {
+		return nil, err
+	}
+	return &mockResponse{statusCode: c.status[route.Instance.ID]}, nil
+}
+
+// mockInstanceMetrics captures recorded instance counts
+type mockInstanceMetrics struct {
+	total, healthy int64
+}
+
+func (m *mockInstanceMetrics) RecordServiceInstances(ctx context.Context, service string, total, healthy int64) {
+	m.total = total
+	m.healthy = healthy
+}
+
+func testInstances(n int) []core.ServiceInstance {
+	instances := make([]core.ServiceInstance, n)
+	for i := range instances {
+		instances[i] = core.ServiceInstance{
+			ID:      string(rune('a' + i)),
+			Name:    "svc",
+			Healthy: true,
+		}
+	}
+	return instances
+}
+
+func TestOutlierDetector_EjectsFailingInstance(t *testing.T) {
+	detector := NewOutlierDetector(OutlierConfig{
+		EjectionThreshold:  0.5,
+		MinRequests:        4,
+		MaxEjectionPercent: 50,
+		BaseEjectionTime:   time.Minute,
+	}, slog.Default())
+
+	instances := testInstances(4)
+	detector.Filter("svc", instances)
+
+	// Below MinRequests nothing happens
+	for i := 0; i < 3; i++ {
+		detector.Record("svc", "a", false)
+	}
+	if state := detector.State("svc", "a"); state != circuitbreaker.StateClosed {
+		t.Fatalf("Expected closed before MinRequests, got %s", state)
+	}
+
+	detector.Record("svc", "a", false)
+	if state := detector.State("svc", "a"); state != circuitbreaker.StateOpen {
+		t.Fatalf("Expected instance to be ejected, got %s", state)
+	}
+
+	filtered := detector.Filter("svc", instances)
+	for _, inst := range filtered {
+		if inst.ID == "a" && inst.Healthy {
+			t.Error("Expected ejected instance to be marked unhealthy")
+		}
+		if inst.ID != "a" && !inst.Healthy {
+			t.Errorf("Expected instance %s to remain healthy", inst.ID)
+		}
+	}
+
+	// The caller's slice must not be modified
+	if !instances[0].Healthy {
+		t.Error("Filter modified the input slice")
+	}
+}
+
+func TestOutlierDetector_MaxEjectionPercent(t *testing.T) {
+	detector := NewOutlierDetector(OutlierConfig{
+		EjectionThreshold:  0.5,
+		MinRequests:        1,
+		MaxEjectionPercent: 50,
+		BaseEjectionTime:   time.Minute,
+	}, slog.Default())
+
+	detector.Filter("svc", testInstances(4))
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		detector.Record("svc", id, false)
+	}
+
+	ejected := 0
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if detector.State("svc", id) == circuitbreaker.StateOpen {
+			ejected++
+		}
+	}
+	if ejected != 2 {
+		t.Errorf("Expected 2 ejected instances (50%% of 4), got %d", ejected)
+	}
+}
+
+func TestOutlierDetector_NeverEjectsAllInstances(t *testing.T) {
+	detector := NewOutlierDetector(OutlierConfig{
+		MinRequests:        1,
+		MaxEjectionPercent: 100,
+	}, slog.Default())
+
+	detector.Filter("svc", testInstances(1))
+	detector.Record("svc", "a", false)
+
+	if state := detector.State("svc", "a"); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected single instance to stay in rotation, got %s", state)
+	}
+}
+
+func TestOutlierDetector_ProbeAndReinstate(t *testing.T) {
+	detector := NewOutlierDetector(OutlierConfig{
+		MinRequests:      1,
+		BaseEjectionTime: 50 * time.Millisecond,
+	}, slog.Default())
+
+	instances := testInstances(2)
+	detector.Filter("svc", instances)
+	detector.Record("svc", "a", false)
+
+	time.Sleep(60 * time.Millisecond)
+
+	if state := detector.State("svc", "a"); state != circuitbreaker.StateHalfOpen {
+		t.Fatalf("Expected half-open after ejection time, got %s", state)
+	}
+
+	// Half-open instance is eligible until a probe is in flight
+	if filtered := detector.Filter("svc", instances); !filtered[0].Healthy {
+		t.Error("Expected half-open instance to be eligible for a probe")
+	}
+	detector.Begin("svc", "a")
+	if filtered := detector.Filter("svc", instances); filtered[0].Healthy {
+		t.Error("Expected instance to be excluded while probe is in flight")
+	}
+
+	// Failed probe ejects again
+	detector.Record("svc", "a", false)
+	if state := detector.State("svc", "a"); state != circuitbreaker.StateOpen {
+		t.Fatalf("Expected re-ejection after failed probe, got %s", state)
+	}
+
+	// Second ejection lasts twice as long
+	time.Sleep(60 * time.Millisecond)
+	if state := detector.State("svc", "a"); state != circuitbreaker.StateOpen {
+		t.Fatalf("Expected longer second ejection, got %s", state)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	detector.Begin("svc", "a")
+	detector.Record("svc", "a", true)
+	if state := detector.State("svc", "a"); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected instance to be reinstated, got %s", state)
+	}
+}
+
+func TestOutlierDetector_Reset(t *testing.T) {
+	detector := NewOutlierDetector(OutlierConfig{MinRequests: 1}, slog.Default())
+	detector.Filter("svc", testInstances(2))
+	detector.Record("svc", "a", false)
+
+	detector.Reset()
+
+	if state := detector.State("svc", "a"); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected closed after reset, got %s", state)
+	}
+}
+
+func TestOutlierDetector_WrapRegistryRecordsMetrics(t *testing.T) {
+	metrics := &mockInstanceMetrics{}
+	detector := NewOutlierDetector(OutlierConfig{MinRequests: 1}, slog.Default()).WithMetrics(metrics)
+
+	registry := detector.WrapRegistry(&mockRegistry{instances: testInstances(3)})
+	if _, err := registry.GetService("svc"); err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if metrics.total != 3 || metrics.healthy != 3 {
+		t.Errorf("Expected 3/3 instances, got %d/%d", metrics.healthy, metrics.total)
+	}
+
+	detector.Record("svc", "b", false)
+
+	instances, err := registry.GetService("svc")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if instances[1].Healthy {
+		t.Error("Expected ejected instance to be unhealthy")
+	}
+	if metrics.total != 3 || metrics.healthy != 2 {
+		t.Errorf("Expected 2/3 healthy instances, got %d/%d", metrics.healthy, metrics.total)
+	}
+}
+
+func TestOutlierDetector_WrapConnector(t *testing.T) {
+	detector := NewOutlierDetector(OutlierConfig{MinRequests: 2}, slog.Default())
+	detector.Filter("svc", testInstances(3))
+
+	conn := detector.WrapConnector(&mockConnector{
+		status: map[string]int{"a": 200, "b": 503},
+		err: map[string]error{
+			"c": gwerrors.NewError(gwerrors.ErrorTypeBadRequest, "bad request"),
+		},
+	})
+
+	req := &mockRequest{path: "/test"}
+	instances := testInstances(3)
+	for i := 0; i < 2; i++ {
+		for j := range instances {
+			conn.Forward(context.Background(), req, &core.RouteResult{
+				Instance:    &instances[j],
+				ServiceName: "svc",
+			})
+		}
+	}
+
+	if state := detector.State("svc", "a"); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected healthy instance to stay closed, got %s", state)
+	}
+	if state := detector.State("svc", "b"); state != circuitbreaker.StateOpen {
+		t.Errorf("Expected 5xx instance to be ejected, got %s", state)
+	}
+	if state := detector.State("svc", "c"); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected client errors not to count against instance, got %s", state)
+	}
+}
+
+func TestOutlierDetector_ProbeWithoutOutcome(t *testing.T) {
+	detector := NewOutlierDetector(OutlierConfig{
+		MinRequests:      1,
+		BaseEjectionTime: 20 * time.Millisecond,
+	}, slog.Default())
+	instances := testInstances(2)
+	detector.Filter("svc", instances)
+	detector.Record("svc", "a", false)
+	time.Sleep(30 * time.Millisecond)
+
+	// A probe ending in a client error says nothing about the instance, so
+	// the next request probes again
+	conn := detector.WrapConnector(&mockConnector{
+		err: map[string]error{"a": gwerrors.NewError(gwerrors.ErrorTypeBadRequest, "bad request")},
+	})
+	conn.Forward(context.Background(), &mockRequest{path: "/test"}, &core.RouteResult{Instance: &instances[0], ServiceName: "svc"})
+	if filtered := detector.Filter("svc", instances); !filtered[0].Healthy {
+		t.Error("Expected the probe slot to be released after a probe without outcome")
+	}
+
+	// Only the request claiming the probe slot decides
+	probe, record := detector.Begin("svc", "a")
+	if !probe || !record {
+		t.Fatalf("Expected the first request to probe, got probe %v record %v", probe, record)
+	}
+	if probe, record := detector.Begin("svc", "a"); probe || record {
+		t.Errorf("Expected a concurrent request not to be counted, got probe %v record %v", probe, record)
+	}
+	detector.Record("svc", "a", true)
+	detector.End("svc", "a")
+	if state := detector.State("svc", "a"); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected instance to be reinstated, got %s", state)
+	}
+}
+
+func TestIsInstanceFailure(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{errors.New("connection refused"), true},
+		{gwerrors.NewError(gwerrors.ErrorTypeTimeout, "timeout"), true},
+		{gwerrors.NewError(gwerrors.ErrorTypeUnavailable, "unavailable"), true},
+		{gwerrors.NewError(gwerrors.ErrorTypeNotFound, "not found"), false},
+		{context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		if got := isInstanceFailure(tt.err); got != tt.expected {
+			t.Errorf("isInstanceFailure(%v) = %v, want %v", tt.err, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
+	"gateway/internal/middleware/buffered"
+)
+
+// DefaultMaxWait bounds how long a request waits for a coalesced response
+// when no limit is set
+const DefaultMaxWait = 5 * time.Second
+
+// DefaultMaxBodySize is the largest response body shared when no limit is set
+const DefaultMaxBodySize = 1 << 20
+
+// privateHeaders are always part of the key, so responses are only shared
+// between requests with the same credentials
+var privateHeaders = []string{"Authorization", "Cookie"}
+
+// Config holds coalescing middleware configuration
+type Config struct {
+	// Routes are the coalesced routes by route ID
+	Routes map[string]RouteConfig
+	// MaxBodySize is the largest response body shared; waiters of larger
+	// responses send their own request
+	MaxBodySize int64
+}
+
+// RouteConfig holds coalescing settings for a route
+type RouteConfig struct {
+	// VaryHeaders are request headers whose values distinguish responses,
+	// in addition to Authorization and Cookie
+	VaryHeaders []string
+	// MaxWait bounds how long a request waits for the in-flight request it
+	// joined before sending its own
+	MaxWait time.Duration
+}
+
+// MetricsRecorder receives requests answered with a shared response
+type MetricsRecorder interface {
+	RecordCoalescedRequest(ctx context.Context, route string)
+}
+
+// Middleware coalesces identical GET requests of configured routes: while
+// one request is in flight, identical requests wait for it and share its
+// response instead of reaching the backend.
+type Middleware struct {
+	config  Config
+	mu      sync.Mutex
+	calls   map[string]*call
+	metrics MetricsRecorder
+	logger  *slog.Logger
+}
+
+// call is a request in flight and the outcome shared with its waiters
+type call struct {
+	done chan struct{}
+	resp *sharedResponse // Set when the response can be shared
+	err  error
+}
+
+// New creates a coalescing middleware
+func New(config Config, logger *slog.Logger) *Middleware {
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultMaxBodySize
+	}
+	for id, cfg := range config.Routes {
+		if cfg.MaxWait <= 0 {
+			cfg.MaxWait = DefaultMaxWait
+		}
+		config.Routes[id] = cfg
+	}
+
+	return &Middleware{
+		config: config,
+		calls:  make(map[string]*call),
+		logger: logger.With("component", "coalesce"),
+	}
+}
+
+// WithMetrics sets the recorder notified of coalesced requests
+func (m *Middleware) WithMetrics(metrics MetricsRecorder) *Middleware {
+	m.metrics = metrics
+	return m
+}
+
+// Handler coalesces requests of configured routes. It needs the route
+// result, so it runs inside the route-aware handler.
+func (m *Middleware) Handler(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		route := core.RouteResultFromContext(ctx)
+		if route == nil || route.Rule == nil || req.Method() != http.MethodGet {
+			return next(ctx, req)
+		}
+		cfg, ok := m.config.Routes[route.Rule.ID]
+		if !ok {
+			return next(ctx, req)
+		}
+
+		key := m.key(ctx, route.Rule.ID, cfg, req)
+		m.mu.Lock()
+		if c, ok := m.calls[key]; ok {
+			m.mu.Unlock()
+			return m.wait(ctx, route.Rule.ID, cfg, c, next, req)
+		}
+		c := &call{done: make(chan struct{})}
+		m.calls[key] = c
+		m.mu.Unlock()
+
+		// Release the waiters even when the handler panics; they then
+		// send their own requests
+		defer func() {
+			m.mu.Lock()
+			delete(m.calls, key)
+			m.mu.Unlock()
+			close(c.done)
+		}()
+
+		return m.lead(ctx, c, next, req)
+	}
+}
+
+// lead sends the request for itself and its waiters, buffering the
+// response so it can be shared
+func (m *Middleware) lead(ctx context.Context, c *call, next core.Handler, req core.Request) (core.Response, error) {
+	resp, err := next(ctx, req)
+	if err != nil {
+		c.err = err
+		return nil, err
+	}
+
+	data, body, fits := buffered.Read(resp.Body(), m.config.MaxBodySize)
+	if !fits {
+		// Too large to share; waiters send their own request
+		return buffered.Response(resp, body), nil
+	}
+	body.Close()
+
+	c.resp = &sharedResponse{statusCode: resp.StatusCode(), headers: resp.Headers(), body: data}
+	return c.resp.copy(), nil
+}
+
+// wait waits for the in-flight call and shares its outcome. A request
+// whose wait times out, or whose call ended without a shareable result,
+// is sent on its own.
+func (m *Middleware) wait(ctx context.Context, routeID string, cfg RouteConfig, c *call, next core.Handler, req core.Request) (core.Response, error) {
+	timer := time.NewTimer(cfg.MaxWait)
+	defer timer.Stop()
+
+	select {
+	case <-c.done:
+	case <-timer.C:
+		m.logger.Debug("Coalesced request timed out, sending it on its own", "route", routeID)
+		return next(ctx, req)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	switch {
+	case c.resp != nil:
+		m.record(ctx, routeID)
+		return c.resp.copy(), nil
+	case c.err != nil && !errors.Is(c.err, context.Canceled) && !errors.Is(c.err, context.DeadlineExceeded):
+		// The leader's own cancellation is not shared
+		m.record(ctx, routeID)
+		return nil, c.err
+	}
+	return next(ctx, req)
+}
+
+// record notifies the metrics recorder of a coalesced request
+func (m *Middleware) record(ctx context.Context, route string) {
+	if m.metrics != nil {
+		m.metrics.RecordCoalescedRequest(ctx, route)
+	}
+}
+
+// key identifies identical requests: the route, URL, authenticated
+// subject and the values of the private and vary headers
+func (m *Middleware) key(ctx context.Context, routeID string, cfg RouteConfig, req core.Request) string {
+	headers := http.Header(req.Headers())
+
+	var b strings.Builder
+	b.WriteString(routeID)
+	b.WriteByte('\n')
+	b.WriteString(req.URL())
+	if info, ok := auth.GetAuthInfo(ctx); ok && info != nil {
+		b.WriteByte('\n')
+		b.WriteString(info.Subject)
+	}
+	for _, names := range [][]string{privateHeaders, cfg.VaryHeaders} {
+		for _, name := range names {
+			b.WriteByte('\n')
+			b.WriteString(strings.Join(headers.Values(name), ","))
+		}
+	}
+	return b.String()
+}
+
+// sharedResponse is a buffered response shared by coalesced requests
+type sharedResponse struct {
+	statusCode int
+	headers    map[string][]string
+	body       []byte
+}
+
+// copy returns a response with its own headers and body reader
+func (r *sharedResponse) copy() core.Response {
+	headers := make(map[string][]string, len(r.headers))
+	for k, values := range r.headers {
+		headers[k] = append([]string(nil), values...)
+	}
+	return &response{statusCode: r.statusCode, headers: headers, body: r.body}
+}
+
+type response struct {
+	statusCode int
+	headers    map[string][]string
+	body       []byte
+}
+
+func (r *response) StatusCode() int              { return r.statusCode }
+func (r *response) Headers() map[string][]string { return r.headers }
+func (r *response) Body() io.ReadCloser          { return buffered.Body(r.body) }
@@ -0,0 +1,193 @@
+package coalesce
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+)
+
+type mockRequest struct {
+	method  string
+	headers map[string][]string
+}
+
+func (m *mockRequest) ID() string                   { return "test-id" }
+func (m *mockRequest) Method() string               { return m.method }
+func (m *mockRequest) Path() string                 { return "/api/products" }
+func (m *mockRequest) URL() string                  { return "/api/products?page=1" }
+func (m *mockRequest) RemoteAddr() string           { return "127.0.0.1:12345" }
+func (m *mockRequest) Headers() map[string][]string { return m.headers }
+func (m *mockRequest) Body() io.ReadCloser          { return http.NoBody }
+func (m *mockRequest) Context() context.Context     { return context.Background() }
+
+// backend counts calls and answers once release is closed
+type backend struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (b *backend) handle(ctx context.Context, req core.Request) (core.Response, error) {
+	n := b.calls.Add(1)
+	<-b.release
+	return core.NewResponse(http.StatusOK, []byte("response "+strconv.Itoa(int(n)))), nil
+}
+
+type mockRecorder struct {
+	coalesced atomic.Int32
+}
+
+func (r *mockRecorder) RecordCoalescedRequest(ctx context.Context, route string) {
+	r.coalesced.Add(1)
+}
+
+func routeContext() context.Context {
+	return core.WithRouteResult(context.Background(), &core.RouteResult{Rule: &core.RouteRule{ID: "products"}})
+}
+
+func get(headers map[string][]string) *mockRequest {
+	return &mockRequest{method: "GET", headers: headers}
+}
+
+// callConcurrently sends reqs at once and returns the response bodies once
+// the backend is released
+func callConcurrently(handler core.Handler, b *backend, reqs ...core.Request) []string {
+	var wg sync.WaitGroup
+	bodies := make([]string, len(reqs))
+	for i, req := range reqs {
+		wg.Add(1)
+		go func(i int, req core.Request) {
+			defer wg.Done()
+			if resp, err := handler(routeContext(), req); err == nil {
+				data, _ := io.ReadAll(resp.Body())
+				bodies[i] = string(data)
+			}
+		}(i, req)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(b.release)
+	wg.Wait()
+	return bodies
+}
+
+func TestMiddleware_Coalesces(t *testing.T) {
+	b := &backend{release: make(chan struct{})}
+	recorder := &mockRecorder{}
+	m := New(Config{Routes: map[string]RouteConfig{"products": {}}}, slog.Default()).WithMetrics(recorder)
+
+	reqs := make([]core.Request, 5)
+	for i := range reqs {
+		reqs[i] = get(nil)
+	}
+	bodies := callConcurrently(m.Handler(b.handle), b, reqs...)
+
+	if calls := b.calls.Load(); calls != 1 {
+		t.Errorf("Expected 1 backend call, got %d", calls)
+	}
+	for _, body := range bodies {
+		if body != "response 1" {
+			t.Errorf("Expected all requests to share the response, got %q", body)
+		}
+	}
+	if coalesced := recorder.coalesced.Load(); coalesced != 4 {
+		t.Errorf("Expected 4 coalesced requests, got %d", coalesced)
+	}
+}
+
+func TestMiddleware_Key(t *testing.T) {
+	b := &backend{release: make(chan struct{})}
+	m := New(Config{Routes: map[string]RouteConfig{"products": {VaryHeaders: []string{"Accept-Language"}}}}, slog.Default())
+
+	callConcurrently(m.Handler(b.handle), b,
+		get(map[string][]string{"Accept-Language": {"en"}}),
+		get(map[string][]string{"Accept-Language": {"en"}}),
+		get(map[string][]string{"Accept-Language": {"de"}}),
+		get(map[string][]string{"Authorization": {"Bearer other"}}),
+		&mockRequest{method: "HEAD"},
+	)
+
+	if calls := b.calls.Load(); calls != 4 {
+		t.Errorf("Expected 4 backend calls, got %d", calls)
+	}
+}
+
+func TestMiddleware_MaxWait(t *testing.T) {
+	b := &backend{release: make(chan struct{})}
+	recorder := &mockRecorder{}
+	m := New(Config{Routes: map[string]RouteConfig{"products": {MaxWait: 10 * time.Millisecond}}}, slog.Default()).WithMetrics(recorder)
+
+	callConcurrently(m.Handler(b.handle), b, get(nil), get(nil))
+
+	if calls := b.calls.Load(); calls != 2 {
+		t.Errorf("Expected waiter to send its own request after max wait, got %d backend calls", calls)
+	}
+	if coalesced := recorder.coalesced.Load(); coalesced != 0 {
+		t.Errorf("Expected no coalesced requests, got %d", coalesced)
+	}
+}
+
+func TestMiddleware_LargeBodyNotShared(t *testing.T) {
+	b := &backend{release: make(chan struct{})}
+	m := New(Config{Routes: map[string]RouteConfig{"products": {}}, MaxBodySize: 4}, slog.Default())
+
+	bodies := callConcurrently(m.Handler(b.handle), b, get(nil), get(nil))
+
+	if calls := b.calls.Load(); calls != 2 {
+		t.Errorf("Expected waiter to send its own request, got %d backend calls", calls)
+	}
+	for _, body := range bodies {
+		if body != "response 1" && body != "response 2" {
+			t.Errorf("Expected the full response body, got %q", body)
+		}
+	}
+}
+
+func TestMiddleware_LeaderPanicReleasesWaiters(t *testing.T) {
+	m := New(Config{Routes: map[string]RouteConfig{"products": {MaxWait: time.Minute}}}, slog.Default())
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls atomic.Int32
+	handler := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+			panic("backend panic")
+		}
+		return core.NewResponse(http.StatusOK, []byte("own response")), nil
+	})
+
+	go func() {
+		defer func() { recover() }()
+		handler(routeContext(), get(nil))
+	}()
+	<-started
+
+	done := make(chan string, 1)
+	go func() {
+		resp, err := handler(routeContext(), get(nil))
+		if err != nil {
+			done <- err.Error()
+			return
+		}
+		data, _ := io.ReadAll(resp.Body())
+		done <- string(data)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	select {
+	case body := <-done:
+		if body != "own response" {
+			t.Errorf("Expected the waiter to send its own request, got %q", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Waiter still blocked after the leader panicked")
+	}
+}
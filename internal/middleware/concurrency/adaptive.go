@@ -0,0 +1,98 @@
+package concurrency
+
+import (
+	"math"
+	"time"
+)
+
+// Defaults of adaptive limits
+const (
+	DefaultTolerance = 1.5
+	DefaultSmoothing = 0.2
+	// DefaultBackoff is the factor the cap shrinks by when a request fails
+	DefaultBackoff = 0.9
+	// longWindow is the number of samples the long-term RTT averages over
+	longWindow = 600
+)
+
+// Adaptive configures a cap that follows the latency of the backend: it
+// shrinks while the latency inflates above its long-term average and grows
+// while the backend keeps up
+type Adaptive struct {
+	MinLimit int
+	MaxLimit int
+	// Tolerance is the latency inflation tolerated before the cap shrinks,
+	// as a ratio of the long-term latency
+	Tolerance float64
+	// Smoothing weighs each new estimate of the cap against the current one
+	Smoothing float64
+}
+
+// gradient estimates a concurrency cap from request round trip times
+// after Netflix's gradient2 algorithm. The cap moves by the ratio of the
+// long-term to the current latency, plus a queue allowance of its square
+// root so it keeps probing for headroom; failed requests shrink it
+// multiplicatively, like AIMD.
+type gradient struct {
+	config   Adaptive
+	estimate float64
+	longRTT  float64 // Exponential moving average, in seconds
+	samples  int
+}
+
+func newGradient(config Adaptive, initial int) *gradient {
+	if config.MinLimit <= 0 {
+		config.MinLimit = 1
+	}
+	if config.Tolerance <= 0 {
+		config.Tolerance = DefaultTolerance
+	}
+	if config.Smoothing <= 0 || config.Smoothing > 1 {
+		config.Smoothing = DefaultSmoothing
+	}
+	if config.MaxLimit < config.MinLimit {
+		config.MaxLimit = max(initial, config.MinLimit)
+	}
+	g := &gradient{config: config}
+	g.estimate = g.clamp(float64(initial))
+	return g
+}
+
+// update records a request that completed with inFlight requests in
+// flight and returns the new cap
+func (g *gradient) update(rtt time.Duration, inFlight int, overloaded bool) int {
+	if overloaded {
+		g.estimate = g.clamp(g.estimate * DefaultBackoff)
+		return int(g.estimate)
+	}
+
+	short := rtt.Seconds()
+	g.samples++
+	if g.samples == 1 {
+		g.longRTT = short
+	} else {
+		window := math.Min(float64(g.samples), longWindow)
+		g.longRTT += (short - g.longRTT) / window
+	}
+
+	// Let the long-term latency recover quickly after a sustained
+	// latency drop, for example once a slow dependency is back
+	if g.longRTT/short > 2 {
+		g.longRTT *= 0.95
+	}
+
+	// Do not grow the cap while it is not the bottleneck
+	if float64(inFlight) < g.estimate/2 {
+		return int(g.estimate)
+	}
+
+	ratio := math.Max(0.5, math.Min(1, g.config.Tolerance*g.longRTT/short))
+	next := g.estimate*ratio + math.Sqrt(g.estimate)
+	g.estimate = g.clamp(g.estimate*(1-g.config.Smoothing) + next*g.config.Smoothing)
+	return int(g.estimate)
+}
+
+// clamp bounds limit to the configured range
+func (g *gradient) clamp(limit float64) float64 {
+	return math.Max(float64(g.config.MinLimit), math.Min(float64(g.config.MaxLimit), limit))
+}
@@ -0,0 +1,43 @@
+package concurrency
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGradient_Update(t *testing.T) {
+	g := newGradient(Adaptive{MinLimit: 5, MaxLimit: 100}, 20)
+
+	// Steady latency with the cap fully used grows it up to the maximum
+	limit := 0
+	for i := 0; i < 200; i++ {
+		limit = g.update(10*time.Millisecond, limit+1, false)
+	}
+	if limit != 100 {
+		t.Errorf("Expected cap to grow to 100, got %d", limit)
+	}
+
+	// Inflated latency shrinks it down to the minimum
+	for i := 0; i < 60; i++ {
+		limit = g.update(200*time.Millisecond, limit, false)
+	}
+	if limit != 5 {
+		t.Errorf("Expected cap to shrink to 5, got %d", limit)
+	}
+}
+
+func TestGradient_AppLimited(t *testing.T) {
+	g := newGradient(Adaptive{MinLimit: 1, MaxLimit: 100}, 20)
+	for i := 0; i < 50; i++ {
+		if limit := g.update(10*time.Millisecond, 2, false); limit != 20 {
+			t.Fatalf("Expected cap to stay at 20 with few requests in flight, got %d", limit)
+		}
+	}
+}
+
+func TestGradient_Overloaded(t *testing.T) {
+	g := newGradient(Adaptive{MinLimit: 1, MaxLimit: 100}, 50)
+	if limit := g.update(0, 50, true); limit != 45 {
+		t.Errorf("Expected failed request to shrink cap to 45, got %d", limit)
+	}
+}
@@ -0,0 +1,362 @@
+// Package concurrency caps the requests in flight globally, per route and
+// per service
+package concurrency
+
+import (
+	"context"
+	stderrors "errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/pkg/errors"
+)
+
+// DefaultQueueTimeout bounds how long a queued request waits for a slot
+// when no timeout is set
+const DefaultQueueTimeout = time.Second
+
+// Config holds concurrency limiting configuration
+type Config struct {
+	// Global caps all requests; nil means no global limit
+	Global *Limit
+	// Routes are the route limits by route ID
+	Routes map[string]Limit
+	// Services are the service limits by service name
+	Services map[string]Limit
+	// Classes assign priorities to requests; queued requests with a higher
+	// priority are admitted first and shed last
+	Classes []PriorityClass
+}
+
+// Limit caps the requests in flight. Requests over MaxInFlight wait in a
+// queue of up to MaxQueue requests for at most QueueTimeout; requests that
+// find the queue full or time out are rejected.
+type Limit struct {
+	MaxInFlight  int
+	MaxQueue     int
+	QueueTimeout time.Duration
+	// Adaptive adjusts the cap to the observed latency, starting at
+	// MaxInFlight; nil keeps the cap fixed
+	Adaptive *Adaptive
+}
+
+// MetricsRecorder receives changes of the requests active and queued under
+// a limit, and the current cap of adaptive limits. Limits are named global,
+// route:<id> or service:<name>.
+type MetricsRecorder interface {
+	RecordConcurrency(ctx context.Context, limit string, activeDelta, queuedDelta int64)
+	RecordConcurrencyLimit(ctx context.Context, limit string, value int64)
+	RecordPriorityRequest(ctx context.Context, class string, admitted bool)
+}
+
+// limiter is a semaphore with a bounded FIFO wait queue and a cap that
+// adaptive limits change as requests complete
+type limiter struct {
+	name     string
+	limit    Limit
+	gradient *gradient // nil for fixed caps
+
+	mu      sync.Mutex
+	max     int
+	active  int
+	waiters []*waiter // In arrival order
+}
+
+// waiter is a queued request. It is woken by closing ready once it is
+// granted a slot or shed for a request with a higher priority.
+type waiter struct {
+	ready    chan struct{}
+	priority int
+	granted  bool
+}
+
+func newLimiter(name string, limit Limit) *limiter {
+	if limit.QueueTimeout <= 0 {
+		limit.QueueTimeout = DefaultQueueTimeout
+	}
+	l := &limiter{
+		name:  name,
+		limit: limit,
+		max:   limit.MaxInFlight,
+	}
+	if limit.Adaptive != nil {
+		l.gradient = newGradient(*limit.Adaptive, limit.MaxInFlight)
+		l.max = int(l.gradient.estimate)
+	}
+	return l
+}
+
+// Middleware rejects requests over the concurrency limit of the gateway,
+// their route or their service with 503 Service Unavailable
+type Middleware struct {
+	global   *limiter
+	routes   map[string]*limiter
+	services map[string]*limiter
+	classes  []PriorityClass
+	metrics  MetricsRecorder
+	logger   *slog.Logger
+}
+
+// New creates a concurrency limiting middleware
+func New(config Config, logger *slog.Logger) *Middleware {
+	m := &Middleware{
+		routes:   make(map[string]*limiter, len(config.Routes)),
+		services: make(map[string]*limiter, len(config.Services)),
+		classes:  config.Classes,
+		logger:   logger.With("component", "concurrency"),
+	}
+	if config.Global != nil {
+		m.global = newLimiter("global", *config.Global)
+	}
+	for id, limit := range config.Routes {
+		m.routes[id] = newLimiter("route:"+id, limit)
+	}
+	for name, limit := range config.Services {
+		m.services[name] = newLimiter("service:"+name, limit)
+	}
+	return m
+}
+
+// WithMetrics sets the recorder notified of active and queued requests,
+// reporting the initial cap of adaptive limits
+func (m *Middleware) WithMetrics(metrics MetricsRecorder) *Middleware {
+	m.metrics = metrics
+	for _, l := range m.all() {
+		if l.gradient != nil {
+			metrics.RecordConcurrencyLimit(context.Background(), l.name, int64(l.max))
+		}
+	}
+	return m
+}
+
+// all returns every limiter
+func (m *Middleware) all() []*limiter {
+	var limiters []*limiter
+	if m.global != nil {
+		limiters = append(limiters, m.global)
+	}
+	for _, l := range m.routes {
+		limiters = append(limiters, l)
+	}
+	for _, l := range m.services {
+		limiters = append(limiters, l)
+	}
+	return limiters
+}
+
+// Handler holds a slot of every limit applying to the request while it is
+// served. It needs the route result, so it runs inside the route-aware
+// handler.
+func (m *Middleware) Handler(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (resp core.Response, err error) {
+		// Limits are always acquired in the same order, so requests
+		// holding one limit and waiting for another cannot deadlock
+		limiters := m.limiters(ctx)
+		class := m.classify(ctx, req)
+		for i, l := range limiters {
+			if err := m.acquire(ctx, l, class.Weight); err != nil {
+				m.logger.Debug("request rejected by concurrency limit",
+					"limit", l.name,
+					"class", class.Name,
+					"path", req.Path(),
+				)
+				for _, held := range limiters[:i] {
+					m.release(ctx, held, 0, false)
+				}
+				if ctx.Err() == nil {
+					m.recordPriority(ctx, class, false)
+				}
+				return nil, err
+			}
+		}
+		if len(limiters) > 0 {
+			m.recordPriority(ctx, class, true)
+		}
+
+		// The slots are released even when next panics; a panicking
+		// request says nothing about the backend either
+		start := time.Now()
+		completed := false
+		defer func() {
+			rtt := time.Since(start)
+			// Requests the client gave up on say nothing about the backend
+			sample := completed && !stderrors.Is(err, context.Canceled)
+			overloaded := err != nil && sample
+			for _, l := range limiters {
+				if sample {
+					m.release(ctx, l, rtt, overloaded)
+				} else {
+					m.release(ctx, l, 0, false)
+				}
+			}
+		}()
+		resp, err = next(ctx, req)
+		completed = true
+		return resp, err
+	}
+}
+
+// limiters returns the limits applying to the request of ctx
+func (m *Middleware) limiters(ctx context.Context) []*limiter {
+	var limiters []*limiter
+	if m.global != nil {
+		limiters = append(limiters, m.global)
+	}
+
+	route := core.RouteResultFromContext(ctx)
+	if route == nil {
+		return limiters
+	}
+	service := ""
+	if route.Instance != nil {
+		service = route.Instance.Name
+	}
+	if service == "" && route.Rule != nil {
+		service = route.Rule.ServiceName
+	}
+	if l, ok := m.services[service]; ok {
+		limiters = append(limiters, l)
+	}
+	if route.Rule != nil {
+		if l, ok := m.routes[route.Rule.ID]; ok {
+			limiters = append(limiters, l)
+		}
+	}
+	return limiters
+}
+
+// errTooManyRequests rejects requests over a concurrency limit
+func errTooManyRequests() error {
+	return errors.NewError(errors.ErrorTypeUnavailable, "Too many concurrent requests")
+}
+
+// acquire takes a slot of l, queueing with priority when none is free. A
+// full queue sheds its lowest-priority request for a request with a higher
+// priority.
+func (m *Middleware) acquire(ctx context.Context, l *limiter, priority int) error {
+	l.mu.Lock()
+	if l.active < l.max {
+		l.active++
+		l.mu.Unlock()
+		m.record(ctx, l, 1, 0)
+		return nil
+	}
+	if len(l.waiters) >= l.limit.MaxQueue {
+		victim := l.lowest()
+		if victim < 0 || l.waiters[victim].priority >= priority {
+			l.mu.Unlock()
+			return errTooManyRequests()
+		}
+		close(l.waiters[victim].ready)
+		l.remove(victim)
+	}
+	w := &waiter{ready: make(chan struct{}), priority: priority}
+	l.waiters = append(l.waiters, w)
+	l.mu.Unlock()
+	m.record(ctx, l, 0, 1)
+
+	timer := time.NewTimer(l.limit.QueueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-w.ready:
+	case <-timer.C:
+		err = errTooManyRequests()
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	if err != nil {
+		for i, queued := range l.waiters {
+			if queued == w {
+				l.remove(i)
+				break
+			}
+		}
+	}
+	granted := w.granted
+	l.mu.Unlock()
+
+	if !granted {
+		m.record(ctx, l, 0, -1)
+		if err == nil {
+			// Shed for a request with a higher priority
+			err = errTooManyRequests()
+		}
+		return err
+	}
+	// A slot handed over while giving up is kept
+	m.record(ctx, l, 1, -1)
+	return nil
+}
+
+// lowest returns the index of the queued request to shed first: the most
+// recent one of the lowest priority, or -1 when the queue is empty
+func (l *limiter) lowest() int {
+	victim := -1
+	for i, w := range l.waiters {
+		if victim < 0 || w.priority <= l.waiters[victim].priority {
+			victim = i
+		}
+	}
+	return victim
+}
+
+// highest returns the index of the queued request to admit first: the
+// oldest one of the highest priority
+func (l *limiter) highest() int {
+	next := 0
+	for i, w := range l.waiters {
+		if w.priority > l.waiters[next].priority {
+			next = i
+		}
+	}
+	return next
+}
+
+// remove drops the waiter at index i from the queue
+func (l *limiter) remove(i int) {
+	l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+}
+
+// release frees a slot of l. Adaptive limits update their cap from the
+// round trip time rtt of a sampled request; overloaded reports a request
+// that failed.
+func (m *Middleware) release(ctx context.Context, l *limiter, rtt time.Duration, overloaded bool) {
+	l.mu.Lock()
+	inFlight := l.active
+	l.active--
+	changed := false
+	if l.gradient != nil && (rtt > 0 || overloaded) {
+		if max := l.gradient.update(rtt, inFlight, overloaded); max != l.max {
+			l.max = max
+			changed = true
+		}
+	}
+	// Hand freed slots to the queued requests by priority
+	for l.active < l.max && len(l.waiters) > 0 {
+		i := l.highest()
+		l.waiters[i].granted = true
+		close(l.waiters[i].ready)
+		l.remove(i)
+		l.active++
+	}
+	max := l.max
+	l.mu.Unlock()
+
+	m.record(ctx, l, -1, 0)
+	if changed && m.metrics != nil {
+		m.metrics.RecordConcurrencyLimit(ctx, l.name, int64(max))
+	}
+}
+
+// record notifies the metrics recorder of a change under l
+func (m *Middleware) record(ctx context.Context, l *limiter, activeDelta, queuedDelta int64) {
+	if m.metrics != nil {
+		m.metrics.RecordConcurrency(ctx, l.name, activeDelta, queuedDelta)
+	}
+}
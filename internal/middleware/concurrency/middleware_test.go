@@ -0,0 +1,199 @@
+package concurrency
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+)
+
+type mockRecorder struct {
+	mu     sync.Mutex
+	active map[string]int64
+	queued map[string]int64
+}
+
+func (r *mockRecorder) RecordConcurrency(ctx context.Context, limit string, activeDelta, queuedDelta int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active[limit] += activeDelta
+	r.queued[limit] += queuedDelta
+}
+
+func (r *mockRecorder) RecordConcurrencyLimit(ctx context.Context, limit string, value int64) {}
+
+func (r *mockRecorder) RecordPriorityRequest(ctx context.Context, class string, admitted bool) {}
+
+func (r *mockRecorder) get(limit string) (int64, int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.active[limit], r.queued[limit]
+}
+
+func routeContext(routeID, service string) context.Context {
+	return core.WithRouteResult(context.Background(), &core.RouteResult{
+		Rule:     &core.RouteRule{ID: routeID, ServiceName: service},
+		Instance: &core.ServiceInstance{Name: service},
+	})
+}
+
+// blockingHandler holds requests until release is closed
+func blockingHandler(started chan<- struct{}, release <-chan struct{}) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		started <- struct{}{}
+		<-release
+		return core.NewResponse(http.StatusOK, nil), nil
+	}
+}
+
+func newRequest() core.Request {
+	return core.NewRequest("id", "GET", "/api", "/api", "127.0.0.1:1000", nil, nil, context.Background())
+}
+
+func TestMiddleware_RouteLimitQueues(t *testing.T) {
+	recorder := &mockRecorder{active: map[string]int64{}, queued: map[string]int64{}}
+	m := New(Config{
+		Routes: map[string]Limit{"api": {MaxInFlight: 1, MaxQueue: 1, QueueTimeout: time.Second}},
+	}, slog.Default()).WithMetrics(recorder)
+
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	handler := m.Handler(blockingHandler(started, release))
+
+	errs := make(chan error, 3)
+	serve := func() {
+		_, err := handler(routeContext("api", "users"), newRequest())
+		errs <- err
+	}
+
+	go serve()
+	<-started
+	go serve()
+	// Wait for the second request to queue
+	for i := 0; i < 100; i++ {
+		if _, queued := recorder.get("route:api"); queued == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if active, queued := recorder.get("route:api"); active != 1 || queued != 1 {
+		t.Fatalf("Expected 1 active and 1 queued request, got %d and %d", active, queued)
+	}
+
+	// The queue is full
+	_, err := handler(routeContext("api", "users"), newRequest())
+	if gwerrors.HTTPStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 with a full queue, got %v", err)
+	}
+
+	// Other routes are not limited
+	if _, err := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		return core.NewResponse(http.StatusOK, nil), nil
+	})(routeContext("other", "users"), newRequest()); err != nil {
+		t.Errorf("Expected other route to be served, got %v", err)
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Expected queued requests to be served, got %v", err)
+		}
+	}
+	if active, queued := recorder.get("route:api"); active != 0 || queued != 0 {
+		t.Errorf("Expected no requests left, got %d active and %d queued", active, queued)
+	}
+}
+
+func TestMiddleware_QueueTimeout(t *testing.T) {
+	m := New(Config{
+		Global:   &Limit{MaxInFlight: 10},
+		Services: map[string]Limit{"users": {MaxInFlight: 1, MaxQueue: 5, QueueTimeout: 20 * time.Millisecond}},
+	}, slog.Default())
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+	handler := m.Handler(blockingHandler(started, release))
+
+	go handler(routeContext("a", "users"), newRequest())
+	<-started
+
+	start := time.Now()
+	_, err := handler(routeContext("b", "users"), newRequest())
+	if gwerrors.HTTPStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 after the queue timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected request to wait for the queue timeout, waited %v", elapsed)
+	}
+}
+
+func TestMiddleware_NoQueue(t *testing.T) {
+	m := New(Config{Global: &Limit{MaxInFlight: 1}}, slog.Default())
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+	handler := m.Handler(blockingHandler(started, release))
+
+	go handler(context.Background(), newRequest())
+	<-started
+
+	if _, err := handler(context.Background(), newRequest()); gwerrors.HTTPStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("Expected immediate 503 without a queue, got %v", err)
+	}
+}
+
+func TestMiddleware_ReleasesOnPanic(t *testing.T) {
+	m := New(Config{Global: &Limit{MaxInFlight: 1}}, slog.Default())
+
+	panicking := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		panic("handler failed")
+	})
+	func() {
+		defer func() { recover() }()
+		panicking(context.Background(), newRequest())
+	}()
+
+	ok := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		return core.NewResponse(http.StatusOK, nil), nil
+	})
+	if _, err := ok(context.Background(), newRequest()); err != nil {
+		t.Errorf("Expected the slot of the panicking request to be released, got %v", err)
+	}
+}
+
+func TestMiddleware_AdaptiveLimit(t *testing.T) {
+	m := New(Config{
+		Services: map[string]Limit{"users": {
+			MaxInFlight: 2,
+			Adaptive:    &Adaptive{MinLimit: 1, MaxLimit: 2},
+		}},
+	}, slog.Default())
+
+	failing := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		return nil, gwerrors.NewError(gwerrors.ErrorTypeUnavailable, "backend down")
+	})
+	for i := 0; i < 10; i++ {
+		failing(routeContext("api", "users"), newRequest())
+	}
+	if max := m.services["users"].max; max != 1 {
+		t.Fatalf("Expected failures to shrink the cap to 1, got %d", max)
+	}
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+	handler := m.Handler(blockingHandler(started, release))
+	go handler(routeContext("api", "users"), newRequest())
+	<-started
+
+	if _, err := handler(routeContext("api", "users"), newRequest()); gwerrors.HTTPStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 over the shrunk cap, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package concurrency
+
+import (
+	"context"
+	"net/http"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
+)
+
+// DefaultClass is the priority class of requests matching no class
+var DefaultClass = PriorityClass{Name: "default"}
+
+// PriorityClass assigns a priority to the requests it matches. A request
+// matches when it meets every criterion set; within a criterion any listed
+// value matches.
+type PriorityClass struct {
+	Name string
+	// Weight is the priority; higher weights are admitted first
+	Weight int
+	// SubjectTypes match authenticated subjects of these types
+	SubjectTypes []string
+	// Scopes match requests granted one of these scopes
+	Scopes []string
+	// Headers match requests with these header values
+	Headers map[string]string
+}
+
+// matches reports whether the request belongs to the class
+func (c *PriorityClass) matches(info *auth.AuthInfo, headers http.Header) bool {
+	if len(c.SubjectTypes) > 0 {
+		if info == nil || !contains(c.SubjectTypes, string(info.Type)) {
+			return false
+		}
+	}
+	if len(c.Scopes) > 0 {
+		if info == nil || !containsAny(c.Scopes, info.Scopes) {
+			return false
+		}
+	}
+	for name, value := range c.Headers {
+		if headers.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// classify returns the class with the highest weight among those the
+// request matches
+func (m *Middleware) classify(ctx context.Context, req core.Request) PriorityClass {
+	class := DefaultClass
+	if len(m.classes) == 0 {
+		return class
+	}
+
+	info, _ := auth.GetAuthInfo(ctx)
+	headers := http.Header(req.Headers())
+	matched := false
+	for _, c := range m.classes {
+		if (!matched || c.Weight > class.Weight) && c.matches(info, headers) {
+			class = c
+			matched = true
+		}
+	}
+	return class
+}
+
+// recordPriority notifies the metrics recorder of a request admitted or
+// shed, when priority classes are configured
+func (m *Middleware) recordPriority(ctx context.Context, class PriorityClass, admitted bool) {
+	if m.metrics != nil && len(m.classes) > 0 {
+		m.metrics.RecordPriorityRequest(ctx, class.Name, admitted)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values, candidates []string) bool {
+	for _, c := range candidates {
+		if contains(values, c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package concurrency
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
+	gwerrors "gateway/pkg/errors"
+)
+
+type priorityRecorder struct {
+	mockRecorder
+	mu       sync.Mutex
+	admitted map[string]int
+	shed     map[string]int
+}
+
+func (r *priorityRecorder) RecordPriorityRequest(ctx context.Context, class string, admitted bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if admitted {
+		r.admitted[class]++
+	} else {
+		r.shed[class]++
+	}
+}
+
+func TestMiddleware_Classify(t *testing.T) {
+	m := New(Config{Classes: []PriorityClass{
+		{Name: "services", Weight: 50, SubjectTypes: []string{"service"}},
+		{Name: "premium", Weight: 100, Scopes: []string{"premium"}},
+		{Name: "internal", Weight: 80, Headers: map[string]string{"X-Priority": "high"}},
+	}}, slog.Default())
+
+	tests := []struct {
+		name    string
+		info    *auth.AuthInfo
+		headers map[string][]string
+		want    string
+	}{
+		{"anonymous", nil, nil, "default"},
+		{"service", &auth.AuthInfo{Type: auth.SubjectTypeService}, nil, "services"},
+		{"premium service", &auth.AuthInfo{Type: auth.SubjectTypeService, Scopes: []string{"read", "premium"}}, nil, "premium"},
+		{"header", nil, map[string][]string{"X-Priority": {"high"}}, "internal"},
+		{"user", &auth.AuthInfo{Type: auth.SubjectTypeUser}, map[string][]string{"X-Priority": {"low"}}, "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.info != nil {
+				ctx = auth.WithAuthInfo(ctx, tt.info)
+			}
+			req := core.NewRequest("id", "GET", "/", "/", "127.0.0.1:1000", tt.headers, nil, ctx)
+			if got := m.classify(ctx, req).Name; got != tt.want {
+				t.Errorf("Expected class %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMiddleware_PriorityShedding(t *testing.T) {
+	recorder := &priorityRecorder{
+		mockRecorder: mockRecorder{active: map[string]int64{}, queued: map[string]int64{}},
+		admitted:     map[string]int{},
+		shed:         map[string]int{},
+	}
+	m := New(Config{
+		Global:  &Limit{MaxInFlight: 1, MaxQueue: 1, QueueTimeout: time.Second},
+		Classes: []PriorityClass{{Name: "premium", Weight: 100, Scopes: []string{"premium"}}},
+	}, slog.Default()).WithMetrics(recorder)
+
+	started := make(chan string, 3)
+	release := make(chan struct{})
+	handler := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		started <- req.ID()
+		<-release
+		return core.NewResponse(http.StatusOK, nil), nil
+	})
+	premium := auth.WithAuthInfo(context.Background(), &auth.AuthInfo{Scopes: []string{"premium"}})
+	serve := func(ctx context.Context, id string) <-chan error {
+		errs := make(chan error, 1)
+		go func() {
+			_, err := handler(ctx, core.NewRequest(id, "GET", "/", "/", "127.0.0.1:1000", nil, nil, ctx))
+			errs <- err
+		}()
+		return errs
+	}
+	waitQueued := func() {
+		for i := 0; i < 100; i++ {
+			if _, queued := recorder.get("global"); queued == 1 {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatal("Request was not queued")
+	}
+
+	first := serve(context.Background(), "first")
+	<-started
+	anonymous := serve(context.Background(), "anonymous")
+	waitQueued()
+
+	// The premium request takes the place of the queued anonymous one
+	high := serve(premium, "premium")
+	if err := <-anonymous; gwerrors.HTTPStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("Expected anonymous request to be shed, got %v", err)
+	}
+	waitQueued()
+
+	// Another anonymous request cannot displace it
+	if err := <-serve(context.Background(), "late"); gwerrors.HTTPStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("Expected late anonymous request to be rejected, got %v", err)
+	}
+
+	release <- struct{}{}
+	if err := <-first; err != nil {
+		t.Fatalf("First request failed: %v", err)
+	}
+	if id := <-started; id != "premium" {
+		t.Errorf("Expected premium request to be admitted, got %s", id)
+	}
+	close(release)
+	if err := <-high; err != nil {
+		t.Errorf("Premium request failed: %v", err)
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if recorder.admitted["default"] != 1 || recorder.admitted["premium"] != 1 || recorder.shed["default"] != 2 {
+		t.Errorf("Unexpected counters: admitted %v, shed %v", recorder.admitted, recorder.shed)
+	}
+}
+
+func TestMiddleware_PriorityOrder(t *testing.T) {
+	m := New(Config{
+		Global:  &Limit{MaxInFlight: 1, MaxQueue: 2, QueueTimeout: time.Second},
+		Classes: []PriorityClass{{Name: "high", Weight: 10, Headers: map[string]string{"X-Priority": "high"}}},
+	}, slog.Default())
+
+	started := make(chan string, 3)
+	release := make(chan struct{}, 3)
+	handler := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		started <- req.ID()
+		<-release
+		return core.NewResponse(http.StatusOK, nil), nil
+	})
+	var wg sync.WaitGroup
+	serve := func(id string, headers map[string][]string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler(context.Background(), core.NewRequest(id, "GET", "/", "/", "127.0.0.1:1000", headers, nil, context.Background()))
+		}()
+	}
+
+	serve("first", nil)
+	<-started
+	serve("low", nil)
+	time.Sleep(20 * time.Millisecond)
+	serve("high", map[string][]string{"X-Priority": {"high"}})
+	time.Sleep(20 * time.Millisecond)
+
+	release <- struct{}{}
+	if id := <-started; id != "high" {
+		t.Errorf("Expected high priority request first, got %s", id)
+	}
+	release <- struct{}{}
+	if id := <-started; id != "low" {
+		t.Errorf("Expected low priority request second, got %s", id)
+	}
+	release <- struct{}{}
+	wg.Wait()
+}
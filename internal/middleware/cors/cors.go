@@ -111,20 +111,31 @@ func New(config Config) *CORS {
 // Handler returns an HTTP handler that applies CORS headers
 func (c *CORS) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-
-		// Check if this is a preflight request
-		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
-			c.handlePreflight(w, r, origin)
-			if !c.config.OptionsPassthrough {
-				return
-			}
-		} else {
-			c.handleActualRequest(w, r, origin)
+		c.serve(w, r, next)
+	})
+}
+
+// serve applies CORS headers and passes the request on unless it is a
+// preflight request answered here
+func (c *CORS) serve(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	origin := r.Header.Get("Origin")
+
+	// Check if this is a preflight request
+	if isPreflight(r) {
+		c.handlePreflight(w, r, origin)
+		if !c.config.OptionsPassthrough {
+			return
 		}
+	} else {
+		c.handleActualRequest(w, r, origin)
+	}
 
-		next.ServeHTTP(w, r)
-	})
+	next.ServeHTTP(w, r)
+}
+
+// isPreflight reports whether r is a CORS preflight request
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
 }
 
 // handlePreflight handles CORS preflight requests
@@ -0,0 +1,72 @@
+package cors
+
+import (
+	"net/http"
+
+	"gateway/internal/core"
+)
+
+// RouteMatcher finds the route rule for a request without routing it
+type RouteMatcher interface {
+	Match(core.Request) (*core.RouteRule, error)
+}
+
+// RoutePolicies applies the CORS policy of the route a request matches,
+// falling back to a default policy for other requests
+type RoutePolicies struct {
+	fallback *CORS
+	routes   map[string]*CORS
+	matcher  RouteMatcher
+}
+
+// NewRoutePolicies creates per-route CORS handling. routes maps route IDs to
+// their policy; a nil policy disables CORS for the route. fallback applies
+// to requests on other routes and may be nil to disable CORS for them.
+// Routes whose metadata sets "cors" to false, such as generated routes that
+// exclude CORS, never use the fallback.
+func NewRoutePolicies(fallback *CORS, routes map[string]*CORS, matcher RouteMatcher) *RoutePolicies {
+	return &RoutePolicies{
+		fallback: fallback,
+		routes:   routes,
+		matcher:  matcher,
+	}
+}
+
+// Handler returns an HTTP handler that applies the matching CORS policy
+func (p *RoutePolicies) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy := p.policy(r)
+		if policy == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		policy.serve(w, r, next)
+	})
+}
+
+// policy returns the CORS policy for the request's route
+func (p *RoutePolicies) policy(r *http.Request) *CORS {
+	if p.matcher == nil {
+		return p.fallback
+	}
+
+	// Preflight requests are matched with the method they ask about, so
+	// routes restricted to that method are found
+	method := r.Method
+	if isPreflight(r) {
+		method = r.Header.Get("Access-Control-Request-Method")
+	}
+
+	req := core.NewRequest("", method, r.URL.Path, r.URL.String(), r.RemoteAddr, r.Header, nil, r.Context())
+	rule, err := p.matcher.Match(req)
+	if err != nil {
+		return p.fallback
+	}
+	if policy, ok := p.routes[rule.ID]; ok {
+		return policy
+	}
+	if enabled, ok := rule.Metadata["cors"].(bool); ok && !enabled {
+		return nil
+	}
+	return p.fallback
+}
@@ -0,0 +1,129 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gateway/internal/core"
+	"gateway/pkg/errors"
+)
+
+// prefixMatcher matches requests to the route whose ID prefixes the path
+type prefixMatcher struct{}
+
+func (prefixMatcher) Match(req core.Request) (*core.RouteRule, error) {
+	if strings.HasPrefix(req.Path(), "/generated") {
+		// Routes generated without CORS opt out through metadata
+		return &core.RouteRule{ID: "generated", Metadata: map[string]interface{}{"cors": false}}, nil
+	}
+	for _, id := range []string{"widget", "internal"} {
+		if strings.HasPrefix(req.Path(), "/"+id) {
+			// The widget route only serves GET
+			if id == "widget" && req.Method() != http.MethodGet {
+				break
+			}
+			return &core.RouteRule{ID: id}, nil
+		}
+	}
+	return nil, errors.NewError(errors.ErrorTypeNotFound, "route not found")
+}
+
+func TestRoutePolicies(t *testing.T) {
+	fallback := New(Config{AllowedOrigins: []string{"https://app.example.com"}})
+	policies := NewRoutePolicies(fallback, map[string]*CORS{
+		"widget":   New(Config{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}}),
+		"internal": nil,
+	}, prefixMatcher{})
+
+	handler := policies.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		origin        string
+		requestMethod string
+		allowOrigin   string
+		status        int
+	}{
+		{
+			name:        "route policy allows any origin",
+			method:      "GET",
+			path:        "/widget/embed.js",
+			origin:      "https://blog.example.org",
+			allowOrigin: "https://blog.example.org",
+			status:      http.StatusOK,
+		},
+		{
+			name:          "preflight matched by requested method",
+			method:        "OPTIONS",
+			path:          "/widget/embed.js",
+			origin:        "https://blog.example.org",
+			requestMethod: "GET",
+			allowOrigin:   "https://blog.example.org",
+			status:        http.StatusNoContent,
+		},
+		{
+			name:          "preflight for other method falls back",
+			method:        "OPTIONS",
+			path:          "/widget/embed.js",
+			origin:        "https://blog.example.org",
+			requestMethod: "POST",
+			status:        http.StatusNoContent,
+		},
+		{
+			name:   "global policy locks other routes",
+			method: "GET",
+			path:   "/api/users",
+			origin: "https://blog.example.org",
+			status: http.StatusOK,
+		},
+		{
+			name:        "global policy allows configured origin",
+			method:      "GET",
+			path:        "/api/users",
+			origin:      "https://app.example.com",
+			allowOrigin: "https://app.example.com",
+			status:      http.StatusOK,
+		},
+		{
+			name:          "route with CORS disabled passes preflight through",
+			method:        "OPTIONS",
+			path:          "/internal/jobs",
+			origin:        "https://app.example.com",
+			requestMethod: "POST",
+			status:        http.StatusOK,
+		},
+		{
+			name:          "route excluded by metadata passes preflight through",
+			method:        "OPTIONS",
+			path:          "/generated/items",
+			origin:        "https://app.example.com",
+			requestMethod: "GET",
+			status:        http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.requestMethod != "" {
+				req.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got: %d", tt.status, w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("Expected Access-Control-Allow-Origin %q, got: %q", tt.allowOrigin, got)
+			}
+		})
+	}
+}
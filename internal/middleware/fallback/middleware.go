@@ -0,0 +1,213 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/buffered"
+	"gateway/internal/middleware/circuitbreaker"
+	"gateway/internal/middleware/retry"
+	"gateway/internal/router"
+)
+
+// Failure classes answered by a fallback
+const (
+	TriggerCircuitOpen      = "circuit_open"
+	TriggerRetriesExhausted = "retries_exhausted"
+)
+
+// Fallback kinds recorded by MetricsRecorder
+const (
+	KindStatic  = "static"
+	KindService = "service"
+)
+
+// maxBodySize is the largest request body buffered so it can be sent to a
+// fallback service; larger requests get no service fallback
+const maxBodySize = 1 << 20
+
+// Config holds fallback middleware configuration
+type Config struct {
+	// Routes are the routes with fallbacks by route ID
+	Routes map[string]RouteConfig
+}
+
+// RouteConfig holds the fallback of a route
+type RouteConfig struct {
+	// ServiceName, if set, is the service the request is sent to instead;
+	// otherwise the static response is returned
+	ServiceName string
+	// StatusCode, Headers and Body make up the static response
+	StatusCode int
+	Headers    map[string][]string
+	Body       []byte
+	// On lists the triggers answered by the fallback (default: all)
+	On []string
+}
+
+// RouteMatcher finds the route rule of a request
+type RouteMatcher interface {
+	Match(core.Request) (*core.RouteRule, error)
+}
+
+// Connector forwards requests to backend instances
+type Connector interface {
+	Forward(ctx context.Context, req core.Request, route *core.RouteResult) (core.Response, error)
+}
+
+// MetricsRecorder receives the fallbacks served
+type MetricsRecorder interface {
+	RecordFallback(ctx context.Context, route, trigger, kind string)
+}
+
+// Middleware answers requests whose route failed with an open circuit
+// breaker or exhausted retries with the route's fallback instead of an
+// error. Other failures are returned unchanged.
+type Middleware struct {
+	config    Config
+	matcher   RouteMatcher
+	registry  core.ServiceRegistry
+	connector Connector
+	balancers map[string]core.LoadBalancer
+	metrics   MetricsRecorder
+	logger    *slog.Logger
+}
+
+// New creates a fallback middleware. Fallback services are resolved in
+// registry and reached through connector.
+func New(config Config, matcher RouteMatcher, registry core.ServiceRegistry, connector Connector, logger *slog.Logger) *Middleware {
+	balancers := make(map[string]core.LoadBalancer, len(config.Routes))
+	for id, cfg := range config.Routes {
+		if len(cfg.On) == 0 {
+			cfg.On = []string{TriggerCircuitOpen, TriggerRetriesExhausted}
+		}
+		if cfg.StatusCode == 0 {
+			cfg.StatusCode = http.StatusOK
+		}
+		config.Routes[id] = cfg
+		balancers[id] = router.NewRoundRobinBalancer()
+	}
+
+	return &Middleware{
+		config:    config,
+		matcher:   matcher,
+		registry:  registry,
+		connector: connector,
+		balancers: balancers,
+		logger:    logger.With("component", "fallback"),
+	}
+}
+
+// WithMetrics sets the recorder notified of fallbacks
+func (m *Middleware) WithMetrics(metrics MetricsRecorder) *Middleware {
+	m.metrics = metrics
+	return m
+}
+
+// Handler serves fallbacks for failed requests. It runs outside the retry
+// middleware, so it sees the error left once retries are exhausted.
+func (m *Middleware) Handler(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		rule, err := m.matcher.Match(req)
+		if err != nil || rule == nil {
+			return next(ctx, req)
+		}
+		cfg, ok := m.config.Routes[rule.ID]
+		if !ok {
+			return next(ctx, req)
+		}
+
+		// Keep the body for the fallback service
+		var body []byte
+		if cfg.ServiceName != "" {
+			var fits bool
+			var replay io.ReadCloser
+			body, replay, fits = buffered.Read(req.Body(), maxBodySize)
+			req = buffered.Request(req, replay)
+			if !fits {
+				return next(ctx, req)
+			}
+		}
+
+		resp, err := next(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		trigger := classify(err)
+		if trigger == "" || !contains(cfg.On, trigger) {
+			return nil, err
+		}
+
+		if cfg.ServiceName == "" {
+			m.logger.Debug("Serving static fallback", "route", rule.ID, "trigger", trigger, "error", err)
+			m.record(ctx, rule.ID, trigger, KindStatic)
+			return staticResponse(cfg), nil
+		}
+
+		fallbackResp, fallbackErr := m.forward(ctx, rule, cfg, buffered.Request(req, buffered.Body(body)))
+		if fallbackErr != nil {
+			m.logger.Warn("Fallback service failed", "route", rule.ID, "service", cfg.ServiceName, "error", fallbackErr)
+			return nil, err
+		}
+		m.logger.Debug("Served fallback from service", "route", rule.ID, "service", cfg.ServiceName, "trigger", trigger)
+		m.record(ctx, rule.ID, trigger, KindService)
+		return fallbackResp, nil
+	}
+}
+
+// forward sends req to an instance of the fallback service
+func (m *Middleware) forward(ctx context.Context, rule *core.RouteRule, cfg RouteConfig, req core.Request) (core.Response, error) {
+	instances, err := m.registry.GetService(cfg.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := m.balancers[rule.ID].Select(instances)
+	if err != nil {
+		return nil, err
+	}
+
+	fallbackRule := *rule
+	fallbackRule.ServiceName = cfg.ServiceName
+	route := &core.RouteResult{Instance: instance, Rule: &fallbackRule, ServiceName: cfg.ServiceName}
+	return m.connector.Forward(core.WithRouteResult(ctx, route), req, route)
+}
+
+// record notifies the metrics recorder of a fallback
+func (m *Middleware) record(ctx context.Context, route, trigger, kind string) {
+	if m.metrics != nil {
+		m.metrics.RecordFallback(ctx, route, trigger, kind)
+	}
+}
+
+// classify returns the trigger matching err, or "" for other failures
+func classify(err error) string {
+	switch {
+	case errors.Is(err, circuitbreaker.ErrCircuitOpen):
+		return TriggerCircuitOpen
+	case errors.Is(err, retry.ErrRetriesExhausted):
+		return TriggerRetriesExhausted
+	}
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// staticResponse builds the static fallback response of a route
+func staticResponse(cfg RouteConfig) core.Response {
+	resp := core.NewResponse(cfg.StatusCode, cfg.Body)
+	for k, values := range cfg.Headers {
+		resp.Headers()[k] = append([]string(nil), values...)
+	}
+	return resp
+}
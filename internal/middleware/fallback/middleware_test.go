@@ -0,0 +1,125 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/circuitbreaker"
+	"gateway/internal/middleware/retry"
+	gwerrors "gateway/pkg/errors"
+)
+
+type mockRequest struct {
+	body string
+}
+
+func (m *mockRequest) ID() string                   { return "test-id" }
+func (m *mockRequest) Method() string               { return "POST" }
+func (m *mockRequest) Path() string                 { return "/api/quotes" }
+func (m *mockRequest) URL() string                  { return "/api/quotes" }
+func (m *mockRequest) RemoteAddr() string           { return "127.0.0.1:12345" }
+func (m *mockRequest) Headers() map[string][]string { return nil }
+func (m *mockRequest) Body() io.ReadCloser          { return io.NopCloser(strings.NewReader(m.body)) }
+func (m *mockRequest) Context() context.Context     { return context.Background() }
+
+type mockMatcher struct{}
+
+func (mockMatcher) Match(req core.Request) (*core.RouteRule, error) {
+	return &core.RouteRule{ID: "quotes", ServiceName: "quotes"}, nil
+}
+
+type mockRegistry struct{}
+
+func (mockRegistry) GetService(name string) ([]core.ServiceInstance, error) {
+	return []core.ServiceInstance{{ID: name + "-1", Address: "127.0.0.1", Port: 8080, Healthy: true}}, nil
+}
+
+// mockConnector answers with the service and body it received
+type mockConnector struct{}
+
+func (mockConnector) Forward(ctx context.Context, req core.Request, route *core.RouteResult) (core.Response, error) {
+	data, _ := io.ReadAll(req.Body())
+	return core.NewResponse(http.StatusOK, []byte(route.ServiceName+":"+string(data))), nil
+}
+
+type mockRecorder struct {
+	fallbacks []string
+}
+
+func (r *mockRecorder) RecordFallback(ctx context.Context, route, trigger, kind string) {
+	r.fallbacks = append(r.fallbacks, route+"/"+trigger+"/"+kind)
+}
+
+// failing returns a handler reading the request body and failing with err
+func failing(err error) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		io.ReadAll(req.Body())
+		return nil, err
+	}
+}
+
+func newTestMiddleware(cfg RouteConfig) (*Middleware, *mockRecorder) {
+	recorder := &mockRecorder{}
+	m := New(Config{Routes: map[string]RouteConfig{"quotes": cfg}}, mockMatcher{}, mockRegistry{}, mockConnector{}, slog.Default())
+	return m.WithMetrics(recorder), recorder
+}
+
+var (
+	circuitOpen = gwerrors.NewError(gwerrors.ErrorTypeUnavailable, "Service temporarily unavailable").WithCause(circuitbreaker.ErrCircuitOpen)
+	exhausted   = fmt.Errorf("upstream failed: %w", retry.ErrRetriesExhausted)
+)
+
+func TestMiddleware_Static(t *testing.T) {
+	m, recorder := newTestMiddleware(RouteConfig{
+		StatusCode: http.StatusOK,
+		Headers:    map[string][]string{"Content-Type": {"application/json"}},
+		Body:       []byte(`{"quotes":[]}`),
+	})
+
+	resp, err := m.Handler(failing(circuitOpen))(context.Background(), &mockRequest{})
+	if err != nil {
+		t.Fatalf("Expected fallback response, got %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body())
+	if resp.StatusCode() != http.StatusOK || string(data) != `{"quotes":[]}` {
+		t.Errorf("Unexpected fallback response %d %q", resp.StatusCode(), data)
+	}
+	if len(recorder.fallbacks) != 1 || recorder.fallbacks[0] != "quotes/circuit_open/static" {
+		t.Errorf("Expected static fallback to be recorded, got %v", recorder.fallbacks)
+	}
+}
+
+func TestMiddleware_Service(t *testing.T) {
+	m, recorder := newTestMiddleware(RouteConfig{ServiceName: "quotes-cache"})
+
+	resp, err := m.Handler(failing(exhausted))(context.Background(), &mockRequest{body: "symbol=ACME"})
+	if err != nil {
+		t.Fatalf("Expected fallback response, got %v", err)
+	}
+	if data, _ := io.ReadAll(resp.Body()); string(data) != "quotes-cache:symbol=ACME" {
+		t.Errorf("Expected fallback service to get the request body, got %q", data)
+	}
+	if len(recorder.fallbacks) != 1 || recorder.fallbacks[0] != "quotes/retries_exhausted/service" {
+		t.Errorf("Expected service fallback to be recorded, got %v", recorder.fallbacks)
+	}
+}
+
+func TestMiddleware_OtherErrors(t *testing.T) {
+	m, recorder := newTestMiddleware(RouteConfig{Body: []byte("{}"), On: []string{TriggerCircuitOpen}})
+
+	for _, want := range []error{exhausted, gwerrors.NewError(gwerrors.ErrorTypeBadRequest, "invalid request")} {
+		if _, err := m.Handler(failing(want))(context.Background(), &mockRequest{}); !errors.Is(err, want) {
+			t.Errorf("Expected error %v to be returned, got %v", want, err)
+		}
+	}
+	if len(recorder.fallbacks) != 0 {
+		t.Errorf("Expected no fallbacks, got %v", recorder.fallbacks)
+	}
+}
@@ -0,0 +1,172 @@
+// Package headers adds, replaces and removes headers of the responses the
+// gateway writes, globally and per route
+package headers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gateway/internal/core"
+)
+
+// Config holds response header configuration
+type Config struct {
+	// Global applies to every response
+	Global Policy
+	// Routes are the route policies by route ID, applied after Global
+	Routes map[string]Policy
+}
+
+// Policy edits response headers. Remove is applied first, then Add, then
+// Defaults, so a policy can replace a header it removes.
+type Policy struct {
+	// Add sets headers, replacing the values of the response
+	Add map[string]string
+	// Defaults sets headers the response does not have
+	Defaults map[string]string
+	// Remove drops headers
+	Remove []string
+}
+
+// RouteMatcher finds the route rule of a request
+type RouteMatcher interface {
+	Match(core.Request) (*core.RouteRule, error)
+}
+
+// policy is a Policy with canonical header names
+type policy struct {
+	add      map[string]string
+	defaults map[string]string
+	remove   []string
+}
+
+// Middleware applies the global and the route policy to the headers of
+// responses. Errors are answered by the frontend; their headers are carried
+// on the error as a core.HeaderError.
+type Middleware struct {
+	global  *policy
+	routes  map[string]*policy
+	matcher RouteMatcher
+}
+
+// New creates a response header middleware. The matcher may be nil when no
+// route has a policy.
+func New(config Config, matcher RouteMatcher) (*Middleware, error) {
+	routes := make(map[string]*policy, len(config.Routes))
+	for id, p := range config.Routes {
+		routes[id] = newPolicy(p)
+	}
+	if len(routes) > 0 && matcher == nil {
+		return nil, fmt.Errorf("router does not support response header route matching")
+	}
+
+	return &Middleware{
+		global:  newPolicy(config.Global),
+		routes:  routes,
+		matcher: matcher,
+	}, nil
+}
+
+// newPolicy canonicalizes the header names of p
+func newPolicy(p Policy) *policy {
+	parsed := &policy{
+		add:      make(map[string]string, len(p.Add)),
+		defaults: make(map[string]string, len(p.Defaults)),
+	}
+	for name, value := range p.Add {
+		parsed.add[http.CanonicalHeaderKey(name)] = value
+	}
+	for name, value := range p.Defaults {
+		parsed.defaults[http.CanonicalHeaderKey(name)] = value
+	}
+	for _, name := range p.Remove {
+		parsed.remove = append(parsed.remove, http.CanonicalHeaderKey(name))
+	}
+	return parsed
+}
+
+// apply edits headers in place
+func (p *policy) apply(headers map[string][]string) {
+	for _, name := range p.remove {
+		del(headers, name)
+	}
+	for name, value := range p.add {
+		del(headers, name)
+		headers[name] = []string{value}
+	}
+	for name, value := range p.defaults {
+		if !has(headers, name) {
+			headers[name] = []string{value}
+		}
+	}
+}
+
+// del removes name from headers, whatever the case of its key
+func del(headers map[string][]string, name string) {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			delete(headers, key)
+		}
+	}
+}
+
+// has reports whether headers hold a value for name, whatever the case of
+// its key
+func has(headers map[string][]string, name string) bool {
+	for key, values := range headers {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler applies the policies to the response of next
+func (m *Middleware) Handler(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		resp, err := next(ctx, req)
+		if err != nil {
+			headers := make(map[string][]string)
+			var carried core.HeaderError
+			if errors.As(err, &carried) {
+				for name, values := range carried.ResponseHeaders() {
+					headers[name] = values
+				}
+			}
+			m.apply(req, headers)
+			return nil, &headerError{error: err, headers: headers}
+		}
+		if resp != nil && resp.Headers() != nil {
+			m.apply(req, resp.Headers())
+		}
+		return resp, nil
+	}
+}
+
+// apply applies the global policy, then the policy of the request's route
+func (m *Middleware) apply(req core.Request, headers map[string][]string) {
+	m.global.apply(headers)
+	if len(m.routes) == 0 {
+		return
+	}
+	if rule, err := m.matcher.Match(req); err == nil && rule != nil {
+		if p, ok := m.routes[rule.ID]; ok {
+			p.apply(headers)
+		}
+	}
+}
+
+// headerError carries the headers of an error response
+type headerError struct {
+	error
+	headers map[string][]string
+}
+
+// ResponseHeaders returns the headers to write with the error response
+func (e *headerError) ResponseHeaders() map[string][]string { return e.headers }
+
+// Unwrap returns the wrapped error
+func (e *headerError) Unwrap() error { return e.error }
@@ -0,0 +1,124 @@
+package headers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+)
+
+// mockMatcher routes /admin requests to the admin route
+type mockMatcher struct{}
+
+func (mockMatcher) Match(req core.Request) (*core.RouteRule, error) {
+	if req.Path() == "/admin" {
+		return &core.RouteRule{ID: "admin"}, nil
+	}
+	return &core.RouteRule{ID: "public"}, nil
+}
+
+// backend answers with a Server header and a cache policy
+func backend(ctx context.Context, req core.Request) (core.Response, error) {
+	resp := core.NewResponse(http.StatusOK, nil)
+	resp.Headers()["Server"] = []string{"nginx"}
+	resp.Headers()["Cache-Control"] = []string{"max-age=60"}
+	return resp, nil
+}
+
+func request(path string) core.Request {
+	return core.NewRequest("id", "GET", path, path, "203.0.113.1:1000", nil, nil, context.Background())
+}
+
+func TestMiddleware_Handler(t *testing.T) {
+	m, err := New(Config{
+		Global: Policy{
+			Add:      map[string]string{"x-frame-options": "DENY"},
+			Defaults: map[string]string{"Cache-Control": "no-store", "Referrer-Policy": "no-referrer"},
+			Remove:   []string{"server"},
+		},
+		Routes: map[string]Policy{
+			"admin": {
+				Add:    map[string]string{"Cache-Control": "private"},
+				Remove: []string{"X-Frame-Options"},
+			},
+		},
+	}, mockMatcher{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	handler := m.Handler(backend)
+
+	tests := []struct {
+		path string
+		want map[string][]string
+	}{
+		{
+			path: "/",
+			want: map[string][]string{
+				"Cache-Control":   {"max-age=60"},
+				"Referrer-Policy": {"no-referrer"},
+				"X-Frame-Options": {"DENY"},
+			},
+		},
+		{
+			path: "/admin",
+			want: map[string][]string{
+				"Cache-Control":   {"private"},
+				"Referrer-Policy": {"no-referrer"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := handler(context.Background(), request(tt.path))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(resp.Headers(), tt.want) {
+				t.Errorf("headers = %v, want %v", resp.Headers(), tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware_HandlerError(t *testing.T) {
+	m, err := New(Config{
+		Global: Policy{Defaults: map[string]string{"X-Frame-Options": "DENY"}},
+		Routes: map[string]Policy{"admin": {Add: map[string]string{"Cache-Control": "no-store"}}},
+	}, mockMatcher{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	denied := gwerrors.NewError(gwerrors.ErrorTypeForbidden, "Access denied")
+	handler := m.Handler(func(ctx context.Context, req core.Request) (core.Response, error) {
+		return nil, denied
+	})
+
+	_, err = handler(context.Background(), request("/admin"))
+	if status := gwerrors.HTTPStatus(err); status != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", status, http.StatusForbidden)
+	}
+	if !errors.Is(err, denied) {
+		t.Errorf("error %v does not wrap the handler error", err)
+	}
+	var headerErr core.HeaderError
+	if !errors.As(err, &headerErr) {
+		t.Fatalf("error %v carries no headers", err)
+	}
+	want := map[string][]string{"X-Frame-Options": {"DENY"}, "Cache-Control": {"no-store"}}
+	if got := headerErr.ResponseHeaders(); !reflect.DeepEqual(got, want) {
+		t.Errorf("headers = %v, want %v", got, want)
+	}
+}
+
+func TestNew_RoutesRequireMatcher(t *testing.T) {
+	_, err := New(Config{Routes: map[string]Policy{"admin": {Remove: []string{"Server"}}}}, nil)
+	if err == nil {
+		t.Error("expected an error for route policies without a matcher")
+	}
+}
@@ -0,0 +1,158 @@
+package headers
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// Security header names
+const (
+	HeaderHSTS                  = "Strict-Transport-Security"
+	HeaderContentTypeOptions    = "X-Content-Type-Options"
+	HeaderFrameOptions          = "X-Frame-Options"
+	HeaderReferrerPolicy        = "Referrer-Policy"
+	HeaderContentSecurityPolicy = "Content-Security-Policy"
+)
+
+// Defaults of the security headers
+const (
+	DefaultHSTSMaxAge         = 365 * 24 * 60 * 60
+	DefaultContentTypeOptions = "nosniff"
+	DefaultFrameOptions       = "DENY"
+	DefaultReferrerPolicy     = "strict-origin-when-cross-origin"
+)
+
+// SecurityConfig holds security header configuration. Empty values take
+// the defaults; the Content-Security-Policy header is only sent when set.
+type SecurityConfig struct {
+	HSTSMaxAge            int // Seconds
+	HSTSIncludeSubDomains bool
+	ContentTypeOptions    string
+	FrameOptions          string
+	ReferrerPolicy        string
+	ContentSecurityPolicy string
+	// Disable lists security headers never sent
+	Disable []string
+}
+
+// Security adds security headers to every response written by the
+// frontend, built-in endpoints and error responses included. Headers the
+// response already has are kept, so backends can send their own policy.
+// Strict-Transport-Security is only sent over TLS.
+type Security struct {
+	plain  map[string]string
+	secure map[string]string
+}
+
+// NewSecurity creates security header middleware
+func NewSecurity(config SecurityConfig) *Security {
+	disabled := make(map[string]bool, len(config.Disable))
+	for _, name := range config.Disable {
+		disabled[http.CanonicalHeaderKey(name)] = true
+	}
+
+	plain := make(map[string]string)
+	set := func(name, value, fallback string) {
+		if value == "" {
+			value = fallback
+		}
+		if value != "" && !disabled[name] {
+			plain[name] = value
+		}
+	}
+	set(HeaderContentTypeOptions, config.ContentTypeOptions, DefaultContentTypeOptions)
+	set(HeaderFrameOptions, config.FrameOptions, DefaultFrameOptions)
+	set(HeaderReferrerPolicy, config.ReferrerPolicy, DefaultReferrerPolicy)
+	set(HeaderContentSecurityPolicy, config.ContentSecurityPolicy, "")
+
+	secure := make(map[string]string, len(plain)+1)
+	for name, value := range plain {
+		secure[name] = value
+	}
+	if !disabled[HeaderHSTS] {
+		maxAge := config.HSTSMaxAge
+		if maxAge == 0 {
+			maxAge = DefaultHSTSMaxAge
+		}
+		hsts := "max-age=" + strconv.Itoa(maxAge)
+		if config.HSTSIncludeSubDomains {
+			hsts += "; includeSubDomains"
+		}
+		secure[HeaderHSTS] = hsts
+	}
+
+	return &Security{plain: plain, secure: secure}
+}
+
+// Middleware adds the security headers to the responses of next
+func (s *Security) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers := s.plain
+		if r.TLS != nil {
+			headers = s.secure
+		}
+		next.ServeHTTP(&securityWriter{ResponseWriter: w, headers: headers}, r)
+	})
+}
+
+// securityWriter sets the security headers the response lacks when its
+// header is written
+type securityWriter struct {
+	http.ResponseWriter
+	headers     map[string]string
+	wroteHeader bool
+}
+
+// setHeaders sets the missing security headers once
+func (w *securityWriter) setHeaders() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	header := w.ResponseWriter.Header()
+	for name, value := range w.headers {
+		if _, ok := header[name]; !ok {
+			header[name] = []string{value}
+		}
+	}
+}
+
+// WriteHeader sets the security headers on the final response; informational
+// responses are passed through
+func (w *securityWriter) WriteHeader(status int) {
+	if status >= http.StatusOK || status == http.StatusSwitchingProtocols {
+		w.setHeaders()
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write sets the security headers before an implicit 200 OK
+func (w *securityWriter) Write(b []byte) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends buffered data, so streamed responses keep working
+func (w *securityWriter) Flush() {
+	w.setHeaders()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets upgraded connections take over the underlying connection
+func (w *securityWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the wrapped response writer for http.ResponseController
+func (w *securityWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,101 @@
+package headers
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecurity_Middleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  SecurityConfig
+		tls     bool
+		backend map[string]string
+		want    map[string]string
+	}{
+		{
+			name: "defaults over plain HTTP",
+			want: map[string]string{
+				HeaderContentTypeOptions: "nosniff",
+				HeaderFrameOptions:       "DENY",
+				HeaderReferrerPolicy:     "strict-origin-when-cross-origin",
+			},
+		},
+		{
+			name:   "HSTS over TLS",
+			config: SecurityConfig{HSTSMaxAge: 600, HSTSIncludeSubDomains: true},
+			tls:    true,
+			want: map[string]string{
+				HeaderHSTS:               "max-age=600; includeSubDomains",
+				HeaderContentTypeOptions: "nosniff",
+				HeaderFrameOptions:       "DENY",
+				HeaderReferrerPolicy:     "strict-origin-when-cross-origin",
+			},
+		},
+		{
+			name: "overrides and disabled headers",
+			config: SecurityConfig{
+				FrameOptions:          "SAMEORIGIN",
+				ContentSecurityPolicy: "default-src 'self'",
+				Disable:               []string{"referrer-policy", "strict-transport-security"},
+			},
+			tls: true,
+			want: map[string]string{
+				HeaderContentTypeOptions:    "nosniff",
+				HeaderFrameOptions:          "SAMEORIGIN",
+				HeaderContentSecurityPolicy: "default-src 'self'",
+			},
+		},
+		{
+			name:    "backend headers kept",
+			backend: map[string]string{HeaderFrameOptions: "SAMEORIGIN"},
+			want: map[string]string{
+				HeaderContentTypeOptions: "nosniff",
+				HeaderFrameOptions:       "SAMEORIGIN",
+				HeaderReferrerPolicy:     "strict-origin-when-cross-origin",
+			},
+		},
+	}
+
+	names := []string{HeaderHSTS, HeaderContentTypeOptions, HeaderFrameOptions, HeaderReferrerPolicy, HeaderContentSecurityPolicy}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSecurity(tt.config).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for name, value := range tt.backend {
+					w.Header().Set(name, value)
+				}
+				w.Write([]byte("ok"))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			for _, name := range names {
+				if got := rec.Header().Get(name); got != tt.want[name] {
+					t.Errorf("%s = %q, want %q", name, got, tt.want[name])
+				}
+			}
+		})
+	}
+}
+
+func TestSecurity_InformationalResponses(t *testing.T) {
+	handler := NewSecurity(SecurityConfig{}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusEarlyHints)
+		w.Header().Set(HeaderFrameOptions, "SAMEORIGIN")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := rec.Header().Get(HeaderFrameOptions); got != "SAMEORIGIN" {
+		t.Errorf("X-Frame-Options = %q, want the header set after 103 Early Hints", got)
+	}
+}
@@ -0,0 +1,263 @@
+package idempotency
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
+	"gateway/internal/middleware/buffered"
+	"gateway/pkg/errors"
+)
+
+// DefaultHeader carries the idempotency key when a route sets no header
+const DefaultHeader = "Idempotency-Key"
+
+// ReplayedHeader is set on responses replayed from the store
+const ReplayedHeader = "Idempotent-Replayed"
+
+// DefaultTTL is how long responses are kept when a route sets no TTL
+const DefaultTTL = 24 * time.Hour
+
+// DefaultLockTimeout bounds how long a key stays locked by a request in
+// progress, and how long duplicates wait for it, when no timeout is set
+const DefaultLockTimeout = 30 * time.Second
+
+// DefaultMaxBodySize is the largest response body stored when no limit is set
+const DefaultMaxBodySize = 1 << 20
+
+// maxKeyLength is the longest idempotency key accepted
+const maxKeyLength = 255
+
+// pollInterval is how often a duplicate checks whether the request holding
+// its key has completed
+const pollInterval = 50 * time.Millisecond
+
+// Config holds idempotency middleware configuration
+type Config struct {
+	// Routes are the routes with idempotency keys by route ID
+	Routes map[string]RouteConfig
+	// LockTimeout bounds how long a request holds its key and how long
+	// duplicates wait for it
+	LockTimeout time.Duration
+	// MaxBodySize is the largest response body stored; larger responses
+	// are passed through without being stored
+	MaxBodySize int64
+}
+
+// RouteConfig holds idempotency settings for a route
+type RouteConfig struct {
+	// Header carries the idempotency key
+	Header string
+	// Methods with idempotency keys (default: POST)
+	Methods []string
+	// TTL is how long a response is replayed for duplicates
+	TTL time.Duration
+}
+
+// Middleware makes requests carrying an idempotency key safe to retry. The
+// first response for a key is stored and replayed for later requests with
+// the same key; duplicates arriving while the first request is processed
+// wait for its response. Keys are scoped to the route and the
+// authenticated subject.
+type Middleware struct {
+	config Config
+	store  Store
+	logger *slog.Logger
+}
+
+// New creates an idempotency middleware storing responses in store
+func New(config Config, store Store, logger *slog.Logger) *Middleware {
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = DefaultLockTimeout
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultMaxBodySize
+	}
+	for id, cfg := range config.Routes {
+		if cfg.Header == "" {
+			cfg.Header = DefaultHeader
+		}
+		if len(cfg.Methods) == 0 {
+			cfg.Methods = []string{http.MethodPost}
+		}
+		if cfg.TTL <= 0 {
+			cfg.TTL = DefaultTTL
+		}
+		config.Routes[id] = cfg
+	}
+
+	return &Middleware{
+		config: config,
+		store:  store,
+		logger: logger.With("component", "idempotency"),
+	}
+}
+
+// Handler deduplicates requests of configured routes. It needs the route
+// result, so it runs inside the route-aware handler.
+func (m *Middleware) Handler(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		route := core.RouteResultFromContext(ctx)
+		if route == nil || route.Rule == nil {
+			return next(ctx, req)
+		}
+		cfg, ok := m.config.Routes[route.Rule.ID]
+		if !ok || !hasMethod(cfg.Methods, req.Method()) {
+			return next(ctx, req)
+		}
+		value := http.Header(req.Headers()).Get(cfg.Header)
+		if value == "" {
+			return next(ctx, req)
+		}
+		if len(value) > maxKeyLength {
+			return nil, errors.NewError(
+				errors.ErrorTypeBadRequest,
+				"idempotency key is too long",
+			).WithDetail("maxLength", maxKeyLength)
+		}
+
+		info, _ := auth.GetAuthInfo(ctx)
+		key := storeKey(route.Rule.ID, info, value)
+
+		lock, stored, err := m.acquire(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if stored != nil {
+			m.logger.Debug("Replaying stored response", "route", route.Rule.ID, "status", stored.StatusCode)
+			return replay(stored), nil
+		}
+		if lock == "" {
+			// The store failed; serve the request without deduplication
+			return next(ctx, req)
+		}
+
+		return m.process(ctx, req, next, key, lock, cfg)
+	}
+}
+
+// Close releases the store's resources
+func (m *Middleware) Close() error {
+	if c, ok := m.store.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// acquire locks key, waiting while another request holds it. It returns
+// the stored response for keys that completed, and an empty lock when the
+// store cannot be used.
+func (m *Middleware) acquire(ctx context.Context, key string) (string, *Response, error) {
+	deadline := time.Now().Add(m.config.LockTimeout)
+	for {
+		lock, stored, err := m.store.Acquire(ctx, key, m.config.LockTimeout)
+		if err != nil {
+			m.logger.Warn("Idempotency store failed, processing request without deduplication", "error", err)
+			return "", nil, nil
+		}
+		if lock != "" || stored != nil {
+			return lock, stored, nil
+		}
+
+		if time.Now().After(deadline) {
+			return "", nil, errors.NewError(
+				errors.ErrorTypeConflict,
+				"a request with this idempotency key is in progress",
+			)
+		}
+		select {
+		case <-ctx.Done():
+			return "", nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// process forwards the request holding the lock and stores its response.
+// Errors and server errors are not stored, so the request can be retried
+// with the same key.
+func (m *Middleware) process(ctx context.Context, req core.Request, next core.Handler, key, lock string, cfg RouteConfig) (core.Response, error) {
+	// Unlock with a context of its own, so a cancelled request still frees
+	// its key for retries
+	release := func() {
+		if err := m.store.Release(context.WithoutCancel(ctx), key, lock); err != nil {
+			m.logger.Warn("Failed to release idempotency key", "error", err)
+		}
+	}
+
+	resp, err := next(ctx, req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		release()
+		return resp, nil
+	}
+
+	data, body, fits := buffered.Read(resp.Body(), m.config.MaxBodySize)
+	if !fits {
+		release()
+		m.logger.Debug("Response not stored, body too large or unreadable", "status", resp.StatusCode())
+		return buffered.Response(resp, body), nil
+	}
+	body.Close()
+
+	stored := &Response{StatusCode: resp.StatusCode(), Headers: resp.Headers(), Body: data}
+	if err := m.store.Complete(context.WithoutCancel(ctx), key, lock, stored, cfg.TTL); err != nil {
+		m.logger.Warn("Failed to store idempotent response", "error", err)
+	}
+	return buffered.Response(resp, buffered.Body(data)), nil
+}
+
+// storeKey scopes an idempotency key to its route and subject. Each part is
+// length-prefixed and anonymous requests get a marker no subject can produce,
+// so keys of different routes and callers never collide.
+func storeKey(routeID string, info *auth.AuthInfo, value string) string {
+	subject := "-"
+	if info != nil {
+		subject = lengthPrefixed(info.Subject)
+	}
+	return lengthPrefixed(routeID) + subject + lengthPrefixed(value)
+}
+
+// lengthPrefixed prefixes s with its length
+func lengthPrefixed(s string) string {
+	return strconv.Itoa(len(s)) + ":" + s
+}
+
+// hasMethod reports whether methods contains method
+func hasMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// storedResponse is a stored response replayed for a duplicate request
+type storedResponse struct {
+	resp    *Response
+	headers map[string][]string
+}
+
+// replay returns a stored response marked as replayed
+func replay(resp *Response) *storedResponse {
+	headers := make(map[string][]string, len(resp.Headers)+1)
+	for k, values := range resp.Headers {
+		headers[k] = append([]string(nil), values...)
+	}
+	headers[ReplayedHeader] = []string{"true"}
+	return &storedResponse{resp: resp, headers: headers}
+}
+
+func (r *storedResponse) StatusCode() int              { return r.resp.StatusCode }
+func (r *storedResponse) Headers() map[string][]string { return r.headers }
+func (r *storedResponse) Body() io.ReadCloser          { return buffered.Body(r.resp.Body) }
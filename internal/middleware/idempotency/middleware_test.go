@@ -0,0 +1,186 @@
+package idempotency
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
+	"gateway/pkg/errors"
+)
+
+type mockRequest struct {
+	method  string
+	headers map[string][]string
+}
+
+func (m *mockRequest) ID() string                   { return "test-id" }
+func (m *mockRequest) Method() string               { return m.method }
+func (m *mockRequest) Path() string                 { return "/api/payments" }
+func (m *mockRequest) URL() string                  { return "/api/payments" }
+func (m *mockRequest) RemoteAddr() string           { return "127.0.0.1:12345" }
+func (m *mockRequest) Headers() map[string][]string { return m.headers }
+func (m *mockRequest) Body() io.ReadCloser          { return http.NoBody }
+func (m *mockRequest) Context() context.Context     { return context.Background() }
+
+type mockResponse struct {
+	status int
+	body   string
+}
+
+func (r *mockResponse) StatusCode() int { return r.status }
+func (r *mockResponse) Headers() map[string][]string {
+	return map[string][]string{"Content-Type": {"application/json"}}
+}
+func (r *mockResponse) Body() io.ReadCloser { return io.NopCloser(strings.NewReader(r.body)) }
+
+// backend counts calls and answers with status, blocking on release when set
+type backend struct {
+	calls   atomic.Int32
+	status  int
+	release chan struct{}
+}
+
+func (b *backend) handle(ctx context.Context, req core.Request) (core.Response, error) {
+	n := b.calls.Add(1)
+	if b.release != nil {
+		<-b.release
+	}
+	return &mockResponse{status: b.status, body: `{"payment":` + strconv.Itoa(int(n)) + `}`}, nil
+}
+
+func newTestMiddleware(store Store) *Middleware {
+	return New(Config{Routes: map[string]RouteConfig{"payments": {}}}, store, slog.Default())
+}
+
+func routeContext() context.Context {
+	return core.WithRouteResult(context.Background(), &core.RouteResult{Rule: &core.RouteRule{ID: "payments"}})
+}
+
+func post(key string) *mockRequest {
+	return &mockRequest{method: "POST", headers: map[string][]string{"Idempotency-Key": {key}}}
+}
+
+// call runs req through the middleware and returns the response body and
+// whether it was replayed
+func call(t *testing.T, handler core.Handler, ctx context.Context, req core.Request) (string, bool) {
+	t.Helper()
+	resp, err := handler(ctx, req)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body())
+	return string(data), http.Header(resp.Headers()).Get(ReplayedHeader) == "true"
+}
+
+func TestMiddleware_Replay(t *testing.T) {
+	b := &backend{status: http.StatusCreated}
+	handler := newTestMiddleware(NewMemoryStore(0)).Handler(b.handle)
+
+	first, replayed := call(t, handler, routeContext(), post("abc"))
+	if replayed {
+		t.Error("Expected first response not to be replayed")
+	}
+	second, replayed := call(t, handler, routeContext(), post("abc"))
+	if !replayed || second != first {
+		t.Errorf("Expected replay of %q, got %q (replayed=%v)", first, second, replayed)
+	}
+	call(t, handler, routeContext(), post("other"))
+	call(t, handler, routeContext(), &mockRequest{method: "POST"})
+	call(t, handler, routeContext(), &mockRequest{method: "GET", headers: post("abc").headers})
+
+	if calls := b.calls.Load(); calls != 4 {
+		t.Errorf("Expected 4 backend calls, got %d", calls)
+	}
+}
+
+func TestMiddleware_ServerErrorNotStored(t *testing.T) {
+	b := &backend{status: http.StatusBadGateway}
+	handler := newTestMiddleware(NewMemoryStore(0)).Handler(b.handle)
+
+	call(t, handler, routeContext(), post("abc"))
+	if _, replayed := call(t, handler, routeContext(), post("abc")); replayed {
+		t.Error("Expected server error not to be replayed")
+	}
+	if calls := b.calls.Load(); calls != 2 {
+		t.Errorf("Expected retry to reach the backend, got %d calls", calls)
+	}
+}
+
+func TestMiddleware_ConcurrentDuplicates(t *testing.T) {
+	b := &backend{status: http.StatusOK, release: make(chan struct{})}
+	handler := newTestMiddleware(NewMemoryStore(0)).Handler(b.handle)
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 5)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if resp, err := handler(routeContext(), post("abc")); err == nil {
+				data, _ := io.ReadAll(resp.Body())
+				bodies[i] = string(data)
+			}
+		}(i)
+	}
+	time.Sleep(2 * pollInterval)
+	close(b.release)
+	wg.Wait()
+
+	if calls := b.calls.Load(); calls != 1 {
+		t.Errorf("Expected duplicates to wait for the first request, got %d backend calls", calls)
+	}
+	for _, body := range bodies {
+		if body == "" || body != bodies[0] {
+			t.Errorf("Expected all duplicates to get %q, got %q", bodies[0], body)
+		}
+	}
+}
+
+func TestMiddleware_InProgressTimeout(t *testing.T) {
+	store := NewMemoryStore(0)
+	if lock, _, _ := store.Acquire(context.Background(), storeKey("payments", nil, "abc"), time.Minute); lock == "" {
+		t.Fatal("Expected to lock key")
+	}
+	m := New(Config{Routes: map[string]RouteConfig{"payments": {}}, LockTimeout: 100 * time.Millisecond}, store, slog.Default())
+
+	_, err := m.Handler((&backend{}).handle)(routeContext(), post("abc"))
+	var gwErr *errors.Error
+	if !errors.As(err, &gwErr) || gwErr.Type != errors.ErrorTypeConflict {
+		t.Errorf("Expected conflict while the key is locked, got %v", err)
+	}
+}
+
+func TestMiddleware_ScopedBySubject(t *testing.T) {
+	b := &backend{status: http.StatusOK}
+	handler := newTestMiddleware(NewMemoryStore(0)).Handler(b.handle)
+
+	alice := auth.WithAuthInfo(routeContext(), &auth.AuthInfo{Subject: "alice"})
+	bob := auth.WithAuthInfo(routeContext(), &auth.AuthInfo{Subject: "bob"})
+	call(t, handler, alice, post("abc"))
+	if _, replayed := call(t, handler, bob, post("abc")); replayed {
+		t.Error("Expected keys of different subjects not to collide")
+	}
+}
+
+func TestMiddleware_AnonymousKeysDoNotCollideWithSubjects(t *testing.T) {
+	b := &backend{status: http.StatusOK}
+	handler := newTestMiddleware(NewMemoryStore(0)).Handler(b.handle)
+
+	alice := auth.WithAuthInfo(routeContext(), &auth.AuthInfo{Subject: "alice"})
+	call(t, handler, alice, post("abc"))
+	if _, replayed := call(t, handler, routeContext(), post("alice:abc")); replayed {
+		t.Error("Expected an anonymous key not to collide with a subject's key")
+	}
+	if calls := b.calls.Load(); calls != 2 {
+		t.Errorf("Expected 2 backend calls, got %d", calls)
+	}
+}
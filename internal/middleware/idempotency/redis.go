@@ -0,0 +1,105 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisClient is the subset of the go-redis client used by RedisStore
+type RedisClient interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd
+}
+
+// lockPrefix marks values of keys whose first request is in progress;
+// stored responses are JSON objects
+const lockPrefix = "lock:"
+
+// completeScript stores the response when the lock still holds the key
+const completeScript = `
+	if redis.call('GET', KEYS[1]) == ARGV[1] then
+		return redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+	end
+	return false
+`
+
+// releaseScript deletes the key when the lock still holds it
+const releaseScript = `
+	if redis.call('GET', KEYS[1]) == ARGV[1] then
+		return redis.call('DEL', KEYS[1])
+	end
+	return 0
+`
+
+// RedisStore keeps responses in Redis, so duplicates are detected across
+// gateway instances
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisStore creates a store prefixing its Redis keys with prefix
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Acquire locks key or returns its response
+func (s *RedisStore) Acquire(ctx context.Context, key string, lockTimeout time.Duration) (string, *Response, error) {
+	lock := newLock()
+	acquired, err := s.client.SetNX(ctx, s.prefix+key, lockPrefix+lock, lockTimeout).Result()
+	if err != nil {
+		return "", nil, err
+	}
+	if acquired {
+		return lock, nil, nil
+	}
+
+	value, err := s.client.Get(ctx, s.prefix+key).Result()
+	if errors.Is(err, redis.Nil) || strings.HasPrefix(value, lockPrefix) {
+		// In progress, or expired since SETNX; the caller tries again
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	var resp Response
+	if err := json.Unmarshal([]byte(value), &resp); err != nil {
+		return "", nil, fmt.Errorf("decode stored response: %w", err)
+	}
+	return "", &resp, nil
+}
+
+// Complete stores the response when lock still holds key
+func (s *RedisStore) Complete(ctx context.Context, key, lock string, resp *Response, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	err = s.client.Eval(ctx, completeScript, []string{s.prefix + key}, lockPrefix+lock, data, ttl.Milliseconds()).Err()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// Close closes the Redis client
+func (s *RedisStore) Close() error {
+	if c, ok := s.client.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Release drops the lock when it still holds key
+func (s *RedisStore) Release(ctx context.Context, key, lock string) error {
+	return s.client.Eval(ctx, releaseScript, []string{s.prefix + key}, lockPrefix+lock).Err()
+}
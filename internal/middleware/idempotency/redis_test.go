@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// mockRedis runs the commands and scripts of RedisStore on a map, ignoring
+// expiration
+type mockRedis struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (r *mockRedis) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.values[key]; ok {
+		return redis.NewBoolResult(false, nil)
+	}
+	r.values[key] = value.(string)
+	return redis.NewBoolResult(true, nil)
+}
+
+func (r *mockRedis) Get(ctx context.Context, key string) *redis.StringCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	value, ok := r.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(value, nil)
+}
+
+func (r *mockRedis) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.values[keys[0]] != args[0].(string) {
+		return redis.NewCmdResult(nil, redis.Nil)
+	}
+	switch script {
+	case completeScript:
+		r.values[keys[0]] = string(args[1].([]byte))
+	case releaseScript:
+		delete(r.values, keys[0])
+	}
+	return redis.NewCmdResult(int64(1), nil)
+}
+
+func TestRedisStore(t *testing.T) {
+	client := &mockRedis{values: make(map[string]string)}
+	b := &backend{status: http.StatusCreated}
+	handler := New(Config{Routes: map[string]RouteConfig{"payments": {}}}, NewRedisStore(client, "idem:"), slog.Default()).Handler(b.handle)
+
+	first, _ := call(t, handler, routeContext(), post("abc"))
+	second, replayed := call(t, handler, routeContext(), post("abc"))
+	if !replayed || second != first {
+		t.Errorf("Expected replay of %q, got %q (replayed=%v)", first, second, replayed)
+	}
+	if _, ok := client.values["idem:"+storeKey("payments", nil, "abc")]; !ok {
+		t.Errorf("Expected response stored under prefixed key, got %v", client.values)
+	}
+	if calls := b.calls.Load(); calls != 1 {
+		t.Errorf("Expected 1 backend call, got %d", calls)
+	}
+}
+
+func TestRedisStore_ReleaseOnServerError(t *testing.T) {
+	client := &mockRedis{values: make(map[string]string)}
+	b := &backend{status: http.StatusServiceUnavailable}
+	handler := New(Config{Routes: map[string]RouteConfig{"payments": {}}}, NewRedisStore(client, "idem:"), slog.Default()).Handler(b.handle)
+
+	call(t, handler, routeContext(), post("abc"))
+	if len(client.values) != 0 {
+		t.Errorf("Expected lock to be released, got %v", client.values)
+	}
+}
@@ -0,0 +1,131 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Response is a response stored for an idempotency key
+type Response struct {
+	StatusCode int                 `json:"status"`
+	Headers    map[string][]string `json:"headers"`
+	Body       []byte              `json:"body"`
+}
+
+// Store holds the responses of requests by idempotency key, and locks keys
+// while their first request is processed
+type Store interface {
+	// Acquire locks key for the calling request and returns the lock. When
+	// the key already has a response, that response is returned instead;
+	// when another request holds the lock, both are empty.
+	Acquire(ctx context.Context, key string, lockTimeout time.Duration) (lock string, resp *Response, err error)
+	// Complete stores the response of the request holding lock for ttl
+	Complete(ctx context.Context, key, lock string, resp *Response, ttl time.Duration) error
+	// Release drops lock without storing a response, so the key can be
+	// retried
+	Release(ctx context.Context, key, lock string) error
+}
+
+// newLock returns a random lock token
+func newLock() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// DefaultMaxEntries is the number of keys a MemoryStore holds when no limit
+// is set
+const DefaultMaxEntries = 10000
+
+// cleanupInterval is how often a MemoryStore drops expired keys
+const cleanupInterval = time.Minute
+
+// errStoreFull is returned by a MemoryStore holding maxEntries live keys
+var errStoreFull = errors.New("idempotency store is full")
+
+// MemoryStore keeps responses in memory, so duplicates are only detected
+// by the gateway instance that served the first request
+type MemoryStore struct {
+	mu          sync.Mutex
+	entries     map[string]*memoryEntry
+	maxEntries  int
+	lastCleanup time.Time
+}
+
+type memoryEntry struct {
+	lock    string    // Set while the first request is processed
+	resp    *Response // Set once it completed
+	expires time.Time
+}
+
+// NewMemoryStore creates a store holding at most maxEntries keys
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &MemoryStore{
+		entries:     make(map[string]*memoryEntry),
+		maxEntries:  maxEntries,
+		lastCleanup: time.Now(),
+	}
+}
+
+// Acquire locks key or returns its response
+func (s *MemoryStore) Acquire(ctx context.Context, key string, lockTimeout time.Duration) (string, *Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastCleanup) >= cleanupInterval || len(s.entries) >= s.maxEntries {
+		s.cleanup(now)
+	}
+
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expires) {
+		return "", entry.resp, nil
+	}
+	if len(s.entries) >= s.maxEntries {
+		// Full of live keys; process the request without deduplication
+		// rather than evicting responses that are still valid
+		return "", nil, errStoreFull
+	}
+
+	lock := newLock()
+	s.entries[key] = &memoryEntry{lock: lock, expires: now.Add(lockTimeout)}
+	return lock, nil, nil
+}
+
+// Complete stores the response when lock still holds key
+func (s *MemoryStore) Complete(ctx context.Context, key, lock string, resp *Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && entry.lock == lock {
+		s.entries[key] = &memoryEntry{resp: resp, expires: time.Now().Add(ttl)}
+	}
+	return nil
+}
+
+// Release drops the lock when it still holds key
+func (s *MemoryStore) Release(ctx context.Context, key, lock string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && entry.lock == lock {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// cleanup drops expired keys; s.mu must be held
+func (s *MemoryStore) cleanup(now time.Time) {
+	for key, entry := range s.entries {
+		if !now.Before(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+	s.lastCleanup = now
+}
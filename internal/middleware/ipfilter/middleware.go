@@ -0,0 +1,131 @@
+// Package ipfilter rejects requests by client IP with allow and deny lists
+// of CIDRs
+package ipfilter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"gateway/internal/core"
+	"gateway/pkg/clientip"
+	"gateway/pkg/errors"
+)
+
+// Config holds IP filter configuration
+type Config struct {
+	// Global applies to every request
+	Global Policy
+	// Routes are the route policies by route ID, applied on top of Global
+	Routes map[string]Policy
+}
+
+// Policy lists the client CIDRs allowed and denied. Deny entries win; a
+// non-empty allow list rejects every client not in it.
+type Policy struct {
+	AllowCIDRs []string
+	DenyCIDRs  []string
+}
+
+// RouteMatcher finds the route rule of a request
+type RouteMatcher interface {
+	Match(core.Request) (*core.RouteRule, error)
+}
+
+// policy is a parsed Policy
+type policy struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// Middleware rejects requests from client IPs denied by the global or the
+// route policy with 403 Forbidden. The client IP is the request's remote
+// address, which the frontend resolves through trusted proxies.
+type Middleware struct {
+	global  *policy
+	routes  map[string]*policy
+	matcher RouteMatcher
+	logger  *slog.Logger
+}
+
+// New creates an IP filter middleware. The matcher may be nil when no route
+// has a policy.
+func New(config Config, matcher RouteMatcher, logger *slog.Logger) (*Middleware, error) {
+	global, err := parsePolicy(config.Global)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make(map[string]*policy, len(config.Routes))
+	for id, p := range config.Routes {
+		parsed, err := parsePolicy(p)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %w", id, err)
+		}
+		routes[id] = parsed
+	}
+	if len(routes) > 0 && matcher == nil {
+		return nil, fmt.Errorf("router does not support IP filter route matching")
+	}
+
+	return &Middleware{
+		global:  global,
+		routes:  routes,
+		matcher: matcher,
+		logger:  logger.With("component", "ipfilter"),
+	}, nil
+}
+
+// parsePolicy parses the CIDRs of p
+func parsePolicy(p Policy) (*policy, error) {
+	allow, err := clientip.ParseCIDRs(p.AllowCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("allowCIDRs: %w", err)
+	}
+	deny, err := clientip.ParseCIDRs(p.DenyCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("denyCIDRs: %w", err)
+	}
+	return &policy{allow: allow, deny: deny}, nil
+}
+
+// permits reports whether the policy lets ip through
+func (p *policy) permits(ip net.IP) bool {
+	if clientip.Contains(p.deny, ip) {
+		return false
+	}
+	return len(p.allow) == 0 || clientip.Contains(p.allow, ip)
+}
+
+// Handler rejects requests whose client IP is not permitted
+func (m *Middleware) Handler(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		ip := clientip.HostIP(req.RemoteAddr())
+		if ip == nil {
+			return nil, m.forbidden(req, "unknown")
+		}
+
+		if !m.global.permits(ip) {
+			return nil, m.forbidden(req, ip.String())
+		}
+		if len(m.routes) > 0 {
+			if rule, err := m.matcher.Match(req); err == nil && rule != nil {
+				if p, ok := m.routes[rule.ID]; ok && !p.permits(ip) {
+					return nil, m.forbidden(req, ip.String())
+				}
+			}
+		}
+
+		return next(ctx, req)
+	}
+}
+
+// forbidden logs and returns the error for a rejected client
+func (m *Middleware) forbidden(req core.Request, ip string) error {
+	m.logger.Debug("request rejected by IP filter",
+		"client_ip", ip,
+		"path", req.Path(),
+	)
+	return errors.NewError(errors.ErrorTypeForbidden, "Access denied")
+}
@@ -0,0 +1,81 @@
+package ipfilter
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+)
+
+// mockMatcher routes /admin requests to the admin route
+type mockMatcher struct{}
+
+func (mockMatcher) Match(req core.Request) (*core.RouteRule, error) {
+	if req.Path() == "/admin" {
+		return &core.RouteRule{ID: "admin"}, nil
+	}
+	return &core.RouteRule{ID: "public"}, nil
+}
+
+func ok(ctx context.Context, req core.Request) (core.Response, error) {
+	return core.NewResponse(http.StatusOK, nil), nil
+}
+
+func request(path, remoteAddr string) core.Request {
+	return core.NewRequest("id", "GET", path, path, remoteAddr, nil, nil, context.Background())
+}
+
+func TestMiddleware_Handler(t *testing.T) {
+	m, err := New(Config{
+		Global: Policy{DenyCIDRs: []string{"198.51.100.0/24", "2001:db8:bad::/48"}},
+		Routes: map[string]Policy{
+			"admin": {AllowCIDRs: []string{"10.0.0.0/8", "fd00::/8"}, DenyCIDRs: []string{"10.9.0.0/16"}},
+		},
+	}, mockMatcher{}, slog.Default())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	handler := m.Handler(ok)
+
+	tests := []struct {
+		name    string
+		req     core.Request
+		allowed bool
+	}{
+		{name: "public", req: request("/", "203.0.113.1:1000"), allowed: true},
+		{name: "globally denied", req: request("/", "198.51.100.4:1000"), allowed: false},
+		{name: "globally denied ipv6", req: request("/", "[2001:db8:bad::1]:1000"), allowed: false},
+		{name: "route allowed", req: request("/admin", "10.1.2.3:1000"), allowed: true},
+		{name: "route allowed ipv6", req: request("/admin", "[fd00::1]:1000"), allowed: true},
+		{name: "route not allowed", req: request("/admin", "203.0.113.1:1000"), allowed: false},
+		{name: "route deny wins", req: request("/admin", "10.9.1.1:1000"), allowed: false},
+		{name: "unknown address", req: request("/", "@"), allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler(context.Background(), tt.req)
+			if tt.allowed {
+				if err != nil {
+					t.Errorf("Expected request to be allowed, got %v", err)
+				}
+				return
+			}
+			if err == nil || gwerrors.HTTPStatus(err) != http.StatusForbidden {
+				t.Errorf("Expected 403 error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidCIDR(t *testing.T) {
+	if _, err := New(Config{Global: Policy{AllowCIDRs: []string{"10.0.0.0/40"}}}, nil, slog.Default()); err == nil {
+		t.Error("Expected invalid CIDR to fail")
+	}
+	if _, err := New(Config{Routes: map[string]Policy{"a": {DenyCIDRs: []string{"10.0.0.0/8"}}}}, nil, slog.Default()); err == nil {
+		t.Error("Expected route policies without a matcher to fail")
+	}
+}
@@ -0,0 +1,192 @@
+// Package loadshed rejects a share of requests while the gateway process
+// itself is overloaded, so it degrades instead of failing as a whole
+package loadshed
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
+	"gateway/pkg/errors"
+)
+
+// Defaults of unset configuration fields
+const (
+	DefaultInterval = time.Second
+	DefaultStep     = 0.1
+	DefaultMaxRate  = 0.9
+)
+
+// Config holds load shedding configuration. A signal with a zero threshold
+// is not checked.
+type Config struct {
+	// Interval is the time between load samples
+	Interval time.Duration
+	// MaxCPU is the share of GOMAXPROCS busy past which requests are shed
+	MaxCPU float64
+	// MaxGCFraction is the share of CPU time spent in garbage collection
+	// past which requests are shed
+	MaxGCFraction float64
+	// MaxHeapBytes is the heap size past which requests are shed
+	MaxHeapBytes uint64
+	// MaxGoroutines is the goroutine count past which requests are shed
+	MaxGoroutines uint64
+	// Step is the change of the shed rate per sample: it rises while a
+	// signal is past its threshold and falls once none is
+	Step float64
+	// MaxRate caps the share of requests shed
+	MaxRate float64
+	// ExemptPaths are path prefixes never shed, such as health endpoints
+	// proxied to backends
+	ExemptPaths []string
+	// ProtectAuthenticated sheds only requests without an authenticated
+	// subject
+	ProtectAuthenticated bool
+}
+
+// MetricsRecorder receives the shed rate after each change
+type MetricsRecorder interface {
+	RecordLoadShedRate(ctx context.Context, rate float64)
+}
+
+// Middleware rejects a share of the requests with 503 Service Unavailable
+// while the gateway is overloaded. Health endpoints are served by the
+// frontend before the handler chain and are never shed.
+type Middleware struct {
+	config  Config
+	sample  func() Signals
+	metrics MetricsRecorder
+	logger  *slog.Logger
+
+	mu   sync.Mutex // Held while sampling
+	last time.Time
+	rate atomic.Uint64 // math.Float64bits of the shed rate
+}
+
+// New creates a load shedding middleware reading the runtime signals of
+// the process
+func New(config Config, logger *slog.Logger) *Middleware {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Step <= 0 {
+		config.Step = DefaultStep
+	}
+	if config.MaxRate <= 0 {
+		config.MaxRate = DefaultMaxRate
+	}
+	return &Middleware{
+		config: config,
+		sample: newRuntimeSampler().Sample,
+		logger: logger.With("component", "loadshed"),
+	}
+}
+
+// WithMetrics sets the recorder notified of shed rate changes
+func (m *Middleware) WithMetrics(metrics MetricsRecorder) *Middleware {
+	m.metrics = metrics
+	metrics.RecordLoadShedRate(context.Background(), m.Rate())
+	return m
+}
+
+// Rate returns the share of requests currently shed
+func (m *Middleware) Rate() float64 {
+	return math.Float64frombits(m.rate.Load())
+}
+
+// Handler sheds requests at the current rate
+func (m *Middleware) Handler(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		m.update(ctx, time.Now())
+
+		rate := m.Rate()
+		if rate == 0 || m.exempt(ctx, req) || rand.Float64() >= rate {
+			return next(ctx, req)
+		}
+		return nil, errors.NewError(errors.ErrorTypeUnavailable, "Gateway overloaded")
+	}
+}
+
+// update samples the signals once the interval has passed and moves the
+// shed rate a step towards the load. Requests arriving while another one
+// samples do not wait for it.
+func (m *Middleware) update(ctx context.Context, now time.Time) {
+	if !m.mu.TryLock() {
+		return
+	}
+	defer m.mu.Unlock()
+
+	if now.Sub(m.last) < m.config.Interval {
+		return
+	}
+	m.last = now
+
+	signals := m.sample()
+	previous := m.Rate()
+	rate := previous
+	signal := m.overloaded(signals)
+	if signal != "" {
+		rate = min(rate+m.config.Step, m.config.MaxRate)
+	} else {
+		rate = max(rate-m.config.Step, 0)
+	}
+	if rate == previous {
+		return
+	}
+
+	m.rate.Store(math.Float64bits(rate))
+	if m.metrics != nil {
+		m.metrics.RecordLoadShedRate(ctx, rate)
+	}
+	switch {
+	case previous == 0:
+		m.logger.Warn("Gateway overloaded, shedding requests",
+			"signal", signal,
+			"rate", rate,
+			"cpu", signals.CPU,
+			"gcFraction", signals.GCFraction,
+			"heapBytes", signals.HeapBytes,
+			"goroutines", signals.Goroutines,
+		)
+	case rate == 0:
+		m.logger.Info("Gateway load back to normal, no longer shedding requests")
+	}
+}
+
+// overloaded returns the first signal past its threshold, or an empty
+// string
+func (m *Middleware) overloaded(s Signals) string {
+	switch {
+	case m.config.MaxCPU > 0 && s.CPU > m.config.MaxCPU:
+		return "cpu"
+	case m.config.MaxGCFraction > 0 && s.GCFraction > m.config.MaxGCFraction:
+		return "gc"
+	case m.config.MaxHeapBytes > 0 && s.HeapBytes > m.config.MaxHeapBytes:
+		return "heap"
+	case m.config.MaxGoroutines > 0 && s.Goroutines > m.config.MaxGoroutines:
+		return "goroutines"
+	}
+	return ""
+}
+
+// exempt reports whether req is never shed
+func (m *Middleware) exempt(ctx context.Context, req core.Request) bool {
+	for _, prefix := range m.config.ExemptPaths {
+		if strings.HasPrefix(req.Path(), prefix) {
+			return true
+		}
+	}
+	if m.config.ProtectAuthenticated {
+		if info, ok := auth.GetAuthInfo(ctx); ok && info != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package loadshed
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/auth"
+	gwerrors "gateway/pkg/errors"
+)
+
+type mockRecorder struct {
+	mu    sync.Mutex
+	rates []float64
+}
+
+func (r *mockRecorder) RecordLoadShedRate(ctx context.Context, rate float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rates = append(r.rates, rate)
+}
+
+func ok(ctx context.Context, req core.Request) (core.Response, error) {
+	return core.NewResponse(http.StatusOK, nil), nil
+}
+
+// newTestMiddleware returns a middleware sampling signals and a clock
+// advancing past the interval on each call
+func newTestMiddleware(config Config, signals *Signals) (*Middleware, func() time.Time) {
+	m := New(config, slog.Default())
+	m.sample = func() Signals { return *signals }
+	now := time.Now()
+	return m, func() time.Time {
+		now = now.Add(m.config.Interval)
+		return now
+	}
+}
+
+func TestMiddleware_Rate(t *testing.T) {
+	signals := &Signals{CPU: 0.5, Goroutines: 100}
+	m, clock := newTestMiddleware(Config{MaxCPU: 0.8, MaxGoroutines: 1000, Step: 0.25, MaxRate: 0.5}, signals)
+	recorder := &mockRecorder{}
+	m.WithMetrics(recorder)
+	ctx := context.Background()
+
+	m.update(ctx, clock())
+	if m.Rate() != 0 {
+		t.Fatalf("Expected no shedding under the thresholds, got %v", m.Rate())
+	}
+
+	signals.CPU = 0.95
+	m.update(ctx, clock())
+	if m.Rate() != 0.25 {
+		t.Errorf("Expected rate to rise by a step, got %v", m.Rate())
+	}
+	// Samples within the interval are skipped
+	m.update(ctx, time.Now())
+	m.update(ctx, clock())
+	m.update(ctx, clock())
+	if m.Rate() != 0.5 {
+		t.Errorf("Expected rate capped at maxRate, got %v", m.Rate())
+	}
+
+	signals.CPU = 0.5
+	signals.Goroutines = 5000
+	m.update(ctx, clock())
+	if m.Rate() != 0.5 {
+		t.Errorf("Expected goroutines to keep shedding, got %v", m.Rate())
+	}
+
+	signals.Goroutines = 100
+	m.update(ctx, clock())
+	m.update(ctx, clock())
+	if m.Rate() != 0 {
+		t.Errorf("Expected rate to fall back to 0, got %v", m.Rate())
+	}
+
+	want := []float64{0, 0.25, 0.5, 0.25, 0}
+	if len(recorder.rates) != len(want) {
+		t.Fatalf("Expected rates %v, got %v", want, recorder.rates)
+	}
+	for i := range want {
+		if recorder.rates[i] != want[i] {
+			t.Errorf("Expected rates %v, got %v", want, recorder.rates)
+			break
+		}
+	}
+}
+
+func TestMiddleware_Handler(t *testing.T) {
+	signals := &Signals{HeapBytes: 2 << 30}
+	m := New(Config{
+		Interval:             time.Hour,
+		MaxHeapBytes:         1 << 30,
+		Step:                 1,
+		MaxRate:              1,
+		ExemptPaths:          []string{"/status"},
+		ProtectAuthenticated: true,
+	}, slog.Default())
+	m.sample = func() Signals { return *signals }
+	handler := m.Handler(ok)
+
+	call := func(ctx context.Context, path string) error {
+		req := core.NewRequest("id", "GET", path, path, "127.0.0.1:1000", nil, nil, ctx)
+		_, err := handler(ctx, req)
+		return err
+	}
+
+	// The first request samples the heap over its threshold
+	if err := call(context.Background(), "/api"); gwerrors.HTTPStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("Expected request to be shed with 503, got %v", err)
+	}
+	if err := call(context.Background(), "/status/ready"); err != nil {
+		t.Errorf("Expected exempt path to pass, got %v", err)
+	}
+	authenticated := auth.WithAuthInfo(context.Background(), &auth.AuthInfo{Subject: "svc", Type: auth.SubjectTypeService})
+	if err := call(authenticated, "/api"); err != nil {
+		t.Errorf("Expected authenticated request to pass, got %v", err)
+	}
+}
+
+func TestRuntimeSampler(t *testing.T) {
+	s := newRuntimeSampler()
+	s.Sample()
+	signals := s.Sample()
+	if signals.Goroutines == 0 || signals.HeapBytes == 0 {
+		t.Errorf("Expected goroutines and heap size, got %+v", signals)
+	}
+	if signals.CPU < 0 || signals.CPU > 1 || signals.GCFraction < 0 || signals.GCFraction > 1 {
+		t.Errorf("Expected shares between 0 and 1, got %+v", signals)
+	}
+}
@@ -0,0 +1,85 @@
+package loadshed
+
+import (
+	"runtime/metrics"
+	"sync"
+)
+
+// Signals are the load signals of the gateway process
+type Signals struct {
+	// CPU is the share of GOMAXPROCS busy running Go code or the runtime
+	CPU float64
+	// GCFraction is the share of CPU time spent in garbage collection
+	GCFraction float64
+	// HeapBytes is the memory held by heap objects
+	HeapBytes uint64
+	// Goroutines is the number of live goroutines
+	Goroutines uint64
+}
+
+// Runtime metrics read by runtimeSampler, in sample order
+var runtimeMetrics = []string{
+	"/cpu/classes/total:cpu-seconds",
+	"/cpu/classes/idle:cpu-seconds",
+	"/cpu/classes/gc/total:cpu-seconds",
+	"/memory/classes/heap/objects:bytes",
+	"/sched/goroutines:goroutines",
+}
+
+// runtimeSampler reads Signals from runtime/metrics. CPU shares are
+// computed over the time since the previous sample; the runtime updates
+// them at garbage collections, so the last shares are kept until it does.
+type runtimeSampler struct {
+	mu          sync.Mutex
+	samples     []metrics.Sample
+	total, idle float64
+	gc          float64
+	last        Signals
+}
+
+func newRuntimeSampler() *runtimeSampler {
+	s := &runtimeSampler{samples: make([]metrics.Sample, len(runtimeMetrics))}
+	for i, name := range runtimeMetrics {
+		s.samples[i].Name = name
+	}
+	return s
+}
+
+// Sample reads the current signals
+func (s *runtimeSampler) Sample() Signals {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metrics.Read(s.samples)
+	total := float64Value(s.samples[0])
+	idle := float64Value(s.samples[1])
+	gc := float64Value(s.samples[2])
+
+	if elapsed := total - s.total; elapsed > 0 {
+		s.last.CPU = clamp(1 - (idle-s.idle)/elapsed)
+		s.last.GCFraction = clamp((gc - s.gc) / elapsed)
+		s.total, s.idle, s.gc = total, idle, gc
+	}
+	s.last.HeapBytes = uint64Value(s.samples[3])
+	s.last.Goroutines = uint64Value(s.samples[4])
+	return s.last
+}
+
+func float64Value(s metrics.Sample) float64 {
+	if s.Value.Kind() != metrics.KindFloat64 {
+		return 0
+	}
+	return s.Value.Float64()
+}
+
+func uint64Value(s metrics.Sample) uint64 {
+	if s.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return s.Value.Uint64()
+}
+
+// clamp bounds a share to [0, 1], as runtime estimates can overshoot
+func clamp(v float64) float64 {
+	return min(max(v, 0), 1)
+}
@@ -0,0 +1,127 @@
+// Package maintenance answers requests with a maintenance response while
+// maintenance mode is switched on
+package maintenance
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"gateway/internal/core"
+)
+
+// Defaults of the maintenance response
+const (
+	DefaultStatusCode  = http.StatusServiceUnavailable
+	DefaultBody        = `{"error":"Service under maintenance"}`
+	DefaultContentType = "application/json"
+)
+
+// State describes maintenance mode
+type State struct {
+	// Routes limits maintenance to these route IDs; empty means all routes
+	Routes []string `json:"routes,omitempty"`
+	// StatusCode, Body and ContentType make up the response
+	StatusCode  int    `json:"status"`
+	Body        string `json:"body"`
+	ContentType string `json:"contentType"`
+	// RetryAfter is sent in the Retry-After header, in seconds, when set
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
+
+// Switch turns maintenance mode on and off. It is safe for concurrent use.
+type Switch struct {
+	state atomic.Pointer[State]
+}
+
+// NewSwitch creates a switch with maintenance mode off
+func NewSwitch() *Switch {
+	return &Switch{}
+}
+
+// Enable switches maintenance mode on with state, filling in defaults for
+// the unset response fields
+func (s *Switch) Enable(state State) {
+	if state.StatusCode == 0 {
+		state.StatusCode = DefaultStatusCode
+	}
+	if state.Body == "" {
+		state.Body = DefaultBody
+		state.ContentType = DefaultContentType
+	}
+	s.state.Store(&state)
+}
+
+// Disable switches maintenance mode off
+func (s *Switch) Disable() {
+	s.state.Store(nil)
+}
+
+// State returns the maintenance state, or nil when maintenance mode is off
+func (s *Switch) State() *State {
+	return s.state.Load()
+}
+
+// Restore carries the state of a previous switch over on reload
+func (s *Switch) Restore(previous *Switch) {
+	s.state.Store(previous.state.Load())
+}
+
+// RouteMatcher finds the route rule of a request
+type RouteMatcher interface {
+	Match(core.Request) (*core.RouteRule, error)
+}
+
+// Middleware short-circuits requests with the maintenance response while
+// the switch is on. Health endpoints are served by the frontend before the
+// handler chain and stay reachable.
+type Middleware struct {
+	sw      *Switch
+	matcher RouteMatcher
+}
+
+// New creates a maintenance middleware. Without a matcher, maintenance
+// limited to routes applies to no request.
+func New(sw *Switch, matcher RouteMatcher) *Middleware {
+	return &Middleware{sw: sw, matcher: matcher}
+}
+
+// Handler answers requests under maintenance
+func (m *Middleware) Handler(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		state := m.sw.State()
+		if state == nil || !m.applies(state, req) {
+			return next(ctx, req)
+		}
+
+		resp := core.NewResponse(state.StatusCode, []byte(state.Body))
+		if state.ContentType != "" {
+			resp.Headers()["Content-Type"] = []string{state.ContentType}
+		}
+		if state.RetryAfter > 0 {
+			resp.Headers()["Retry-After"] = []string{strconv.Itoa(state.RetryAfter)}
+		}
+		return resp, nil
+	}
+}
+
+// applies reports whether maintenance covers the route of req
+func (m *Middleware) applies(state *State, req core.Request) bool {
+	if len(state.Routes) == 0 {
+		return true
+	}
+	if m.matcher == nil {
+		return false
+	}
+	rule, err := m.matcher.Match(req)
+	if err != nil || rule == nil {
+		return false
+	}
+	for _, id := range state.Routes {
+		if id == rule.ID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package maintenance
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"gateway/internal/core"
+)
+
+// mockMatcher routes /admin requests to the admin route
+type mockMatcher struct{}
+
+func (mockMatcher) Match(req core.Request) (*core.RouteRule, error) {
+	if req.Path() == "/admin" {
+		return &core.RouteRule{ID: "admin"}, nil
+	}
+	return &core.RouteRule{ID: "public"}, nil
+}
+
+func ok(ctx context.Context, req core.Request) (core.Response, error) {
+	return core.NewResponse(http.StatusOK, []byte("ok")), nil
+}
+
+func serve(t *testing.T, handler core.Handler, path string) core.Response {
+	t.Helper()
+	req := core.NewRequest("id", "GET", path, path, "127.0.0.1:1000", nil, nil, context.Background())
+	resp, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	return resp
+}
+
+func TestMiddleware_Handler(t *testing.T) {
+	sw := NewSwitch()
+	handler := New(sw, mockMatcher{}).Handler(ok)
+
+	if resp := serve(t, handler, "/"); resp.StatusCode() != http.StatusOK {
+		t.Errorf("Expected 200 while off, got %d", resp.StatusCode())
+	}
+
+	sw.Enable(State{RetryAfter: 120})
+	resp := serve(t, handler, "/")
+	if resp.StatusCode() != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %d", resp.StatusCode())
+	}
+	body, _ := io.ReadAll(resp.Body())
+	if string(body) != DefaultBody {
+		t.Errorf("Expected default body, got %q", body)
+	}
+	if got := resp.Headers()["Retry-After"]; len(got) != 1 || got[0] != "120" {
+		t.Errorf("Expected Retry-After 120, got %v", got)
+	}
+
+	sw.Enable(State{Routes: []string{"admin"}, StatusCode: http.StatusTeapot, Body: "brb", ContentType: "text/plain"})
+	if resp := serve(t, handler, "/"); resp.StatusCode() != http.StatusOK {
+		t.Errorf("Expected other routes to be served, got %d", resp.StatusCode())
+	}
+	resp = serve(t, handler, "/admin")
+	if resp.StatusCode() != http.StatusTeapot || resp.Headers()["Content-Type"][0] != "text/plain" {
+		t.Errorf("Expected custom response, got %d %v", resp.StatusCode(), resp.Headers())
+	}
+
+	sw.Disable()
+	if resp := serve(t, handler, "/admin"); resp.StatusCode() != http.StatusOK {
+		t.Errorf("Expected 200 after disabling, got %d", resp.StatusCode())
+	}
+}
+
+func TestSwitch_Restore(t *testing.T) {
+	previous := NewSwitch()
+	previous.Enable(State{Routes: []string{"admin"}})
+
+	next := NewSwitch()
+	next.Restore(previous)
+	if state := next.State(); state == nil || state.Routes[0] != "admin" {
+		t.Errorf("Expected state to be carried over, got %v", state)
+	}
+}
@@ -0,0 +1,228 @@
+package mirror
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/middleware/buffered"
+	"gateway/internal/router"
+)
+
+// DefaultMaxBodySize is the largest request body mirrored when no limit is set
+const DefaultMaxBodySize = 1 << 20
+
+// DefaultTimeout bounds a mirrored request when no timeout is set
+const DefaultTimeout = 10 * time.Second
+
+// DefaultMaxConcurrent is the number of mirrored requests in flight when no
+// limit is set
+const DefaultMaxConcurrent = 100
+
+// Config holds mirror middleware configuration
+type Config struct {
+	// Routes are the mirrored routes by route ID
+	Routes map[string]RouteConfig
+	// MaxBodySize is the largest request body mirrored; larger requests are
+	// not mirrored
+	MaxBodySize int64
+	// MaxConcurrent limits mirrored requests in flight; requests arriving
+	// while the limit is reached are not mirrored
+	MaxConcurrent int
+}
+
+// RouteConfig holds mirror settings for a route
+type RouteConfig struct {
+	// ServiceName is the service receiving the copies
+	ServiceName string
+	// Percentage of matching requests mirrored, 0-100
+	Percentage float64
+	// Header, if set, only mirrors requests carrying this header
+	Header string
+	// Cookie, if set, only mirrors requests carrying this cookie
+	Cookie string
+	// Timeout bounds a mirrored request
+	Timeout time.Duration
+}
+
+// Connector forwards requests to backend instances
+type Connector interface {
+	Forward(ctx context.Context, req core.Request, route *core.RouteResult) (core.Response, error)
+}
+
+// MetricsRecorder receives the outcome of mirrored requests
+type MetricsRecorder interface {
+	RecordMirrorRequest(ctx context.Context, route, service string, statusCode int, duration time.Duration)
+	RecordMirrorError(ctx context.Context, route, service string)
+}
+
+// Middleware sends a copy of requests on mirrored routes to a shadow
+// service. Copies are sent asynchronously and their responses discarded,
+// so the mirror never affects the response or latency seen by the client.
+type Middleware struct {
+	config    Config
+	registry  core.ServiceRegistry
+	connector Connector
+	balancers map[string]core.LoadBalancer
+	slots     chan struct{}
+	metrics   MetricsRecorder
+	logger    *slog.Logger
+}
+
+// New creates a mirror middleware sending copies to instances of registry
+// through connector
+func New(config Config, registry core.ServiceRegistry, connector Connector, logger *slog.Logger) *Middleware {
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultMaxBodySize
+	}
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = DefaultMaxConcurrent
+	}
+
+	balancers := make(map[string]core.LoadBalancer, len(config.Routes))
+	for id := range config.Routes {
+		balancers[id] = router.NewRoundRobinBalancer()
+	}
+
+	return &Middleware{
+		config:    config,
+		registry:  registry,
+		connector: connector,
+		balancers: balancers,
+		slots:     make(chan struct{}, config.MaxConcurrent),
+		logger:    logger.With("component", "mirror"),
+	}
+}
+
+// WithMetrics sets the recorder notified of mirrored requests
+func (m *Middleware) WithMetrics(metrics MetricsRecorder) *Middleware {
+	m.metrics = metrics
+	return m
+}
+
+// Handler mirrors requests of mirrored routes. It needs the route result,
+// so it runs inside the route-aware handler.
+func (m *Middleware) Handler(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		route := core.RouteResultFromContext(ctx)
+		if route == nil || route.Rule == nil {
+			return next(ctx, req)
+		}
+		cfg, ok := m.config.Routes[route.Rule.ID]
+		if !ok || !m.selected(cfg, req) {
+			return next(ctx, req)
+		}
+
+		// Reserve a slot before buffering the body, so requests are not
+		// buffered when they will not be mirrored anyway
+		select {
+		case m.slots <- struct{}{}:
+		default:
+			m.logger.Debug("Mirror skipped, too many mirrored requests in flight", "route", route.Rule.ID)
+			return next(ctx, req)
+		}
+
+		body, replay, ok := buffered.Read(req.Body(), m.config.MaxBodySize)
+		primary := buffered.Request(req, replay)
+		if !ok {
+			<-m.slots
+			m.logger.Debug("Mirror skipped, request body too large", "route", route.Rule.ID)
+			return next(ctx, primary)
+		}
+
+		go m.send(context.WithoutCancel(ctx), route.Rule.ID, cfg, newMirroredRequest(req, body))
+
+		return next(ctx, primary)
+	}
+}
+
+// selected reports whether a request of a mirrored route is mirrored
+func (m *Middleware) selected(cfg RouteConfig, req core.Request) bool {
+	headers := http.Header(req.Headers())
+	if cfg.Header != "" && headers.Get(cfg.Header) == "" {
+		return false
+	}
+	if cfg.Cookie != "" {
+		if _, err := (&http.Request{Header: headers}).Cookie(cfg.Cookie); err != nil {
+			return false
+		}
+	}
+	return cfg.Percentage >= 100 || rand.Float64()*100 < cfg.Percentage
+}
+
+// send forwards the copy to an instance of the mirror service and discards
+// the response
+func (m *Middleware) send(ctx context.Context, routeID string, cfg RouteConfig, req core.Request) {
+	defer func() { <-m.slots }()
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// Interim responses of the mirror are not the client's
+	ctx = core.WithInformational(ctx, nil)
+
+	start := time.Now()
+	resp, err := m.forward(ctx, routeID, cfg, req)
+	if err != nil {
+		m.logger.Debug("Mirrored request failed", "route", routeID, "service", cfg.ServiceName, "error", err)
+		if m.metrics != nil {
+			m.metrics.RecordMirrorError(ctx, routeID, cfg.ServiceName)
+		}
+		return
+	}
+
+	if body := resp.Body(); body != nil {
+		io.Copy(io.Discard, body)
+		body.Close()
+	}
+	if m.metrics != nil {
+		m.metrics.RecordMirrorRequest(ctx, routeID, cfg.ServiceName, resp.StatusCode(), time.Since(start))
+	}
+}
+
+// forward selects a mirror instance and forwards req to it
+func (m *Middleware) forward(ctx context.Context, routeID string, cfg RouteConfig, req core.Request) (core.Response, error) {
+	instances, err := m.registry.GetService(cfg.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := m.balancers[routeID].Select(instances)
+	if err != nil {
+		return nil, err
+	}
+
+	route := &core.RouteResult{
+		Instance:    instance,
+		Rule:        &core.RouteRule{ID: routeID, ServiceName: cfg.ServiceName, Timeout: cfg.Timeout},
+		ServiceName: cfg.ServiceName,
+	}
+	return m.connector.Forward(ctx, req, route)
+}
+
+// mirroredRequest is the copy of a request sent to the mirror service. It
+// has its own headers, so the two requests can be modified independently.
+type mirroredRequest struct {
+	core.Request
+	headers map[string][]string
+	body    []byte
+}
+
+// newMirroredRequest copies req with a buffered body
+func newMirroredRequest(req core.Request, body []byte) *mirroredRequest {
+	headers := make(map[string][]string, len(req.Headers()))
+	for k, values := range req.Headers() {
+		headers[k] = append([]string(nil), values...)
+	}
+	return &mirroredRequest{Request: req, headers: headers, body: body}
+}
+
+func (r *mirroredRequest) Headers() map[string][]string { return r.headers }
+
+func (r *mirroredRequest) Body() io.ReadCloser { return buffered.Body(r.body) }
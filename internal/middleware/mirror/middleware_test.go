@@ -0,0 +1,160 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+)
+
+// Mock request for testing
+type mockRequest struct {
+	headers map[string][]string
+	body    string
+}
+
+func (m *mockRequest) ID() string                   { return "test-id" }
+func (m *mockRequest) Method() string               { return "POST" }
+func (m *mockRequest) Path() string                 { return "/api/users" }
+func (m *mockRequest) URL() string                  { return "/api/users" }
+func (m *mockRequest) RemoteAddr() string           { return "127.0.0.1:12345" }
+func (m *mockRequest) Headers() map[string][]string { return m.headers }
+func (m *mockRequest) Body() io.ReadCloser          { return io.NopCloser(strings.NewReader(m.body)) }
+func (m *mockRequest) Context() context.Context     { return context.Background() }
+
+type mockResponse struct{}
+
+func (mockResponse) StatusCode() int              { return 200 }
+func (mockResponse) Headers() map[string][]string { return nil }
+func (mockResponse) Body() io.ReadCloser          { return io.NopCloser(strings.NewReader("ok")) }
+
+type mockRegistry struct{}
+
+func (mockRegistry) GetService(name string) ([]core.ServiceInstance, error) {
+	return []core.ServiceInstance{{ID: name + "-1", Address: "127.0.0.1", Port: 8080, Healthy: true}}, nil
+}
+
+// mockConnector sends the body of each forwarded request on bodies
+type mockConnector struct {
+	bodies chan string
+	err    error
+}
+
+func (c *mockConnector) Forward(ctx context.Context, req core.Request, route *core.RouteResult) (core.Response, error) {
+	data, _ := io.ReadAll(req.Body())
+	c.bodies <- route.ServiceName + ":" + string(data)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return mockResponse{}, nil
+}
+
+type mockRecorder struct {
+	mu       sync.Mutex
+	requests int
+	errors   int
+	done     chan struct{}
+}
+
+func (r *mockRecorder) RecordMirrorRequest(ctx context.Context, route, service string, statusCode int, duration time.Duration) {
+	r.mu.Lock()
+	r.requests++
+	r.mu.Unlock()
+	r.done <- struct{}{}
+}
+
+func (r *mockRecorder) RecordMirrorError(ctx context.Context, route, service string) {
+	r.mu.Lock()
+	r.errors++
+	r.mu.Unlock()
+	r.done <- struct{}{}
+}
+
+func newTestMiddleware(cfg RouteConfig, connector *mockConnector) (*Middleware, *mockRecorder) {
+	recorder := &mockRecorder{done: make(chan struct{}, 10)}
+	m := New(Config{Routes: map[string]RouteConfig{"users": cfg}}, mockRegistry{}, connector, slog.Default())
+	return m.WithMetrics(recorder), recorder
+}
+
+// serve runs req through handler on the users route, returning the body
+// the primary handler read
+func serve(t *testing.T, handler func(core.Handler) core.Handler, req core.Request) string {
+	t.Helper()
+
+	var primary string
+	next := func(ctx context.Context, req core.Request) (core.Response, error) {
+		data, _ := io.ReadAll(req.Body())
+		primary = string(data)
+		return mockResponse{}, nil
+	}
+	ctx := core.WithRouteResult(context.Background(), &core.RouteResult{Rule: &core.RouteRule{ID: "users"}, ServiceName: "users"})
+	if _, err := handler(next)(ctx, req); err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	return primary
+}
+
+func waitFor(t *testing.T, done chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for mirrored request")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	connector := &mockConnector{bodies: make(chan string, 10)}
+	m, recorder := newTestMiddleware(RouteConfig{ServiceName: "users-shadow", Percentage: 100}, connector)
+
+	primary := serve(t, m.Handler, &mockRequest{body: `{"name":"alice"}`})
+	if primary != `{"name":"alice"}` {
+		t.Errorf("Expected primary to read the full body, got %q", primary)
+	}
+
+	waitFor(t, recorder.done)
+	if body := <-connector.bodies; body != `users-shadow:{"name":"alice"}` {
+		t.Errorf("Expected mirror to receive the body, got %q", body)
+	}
+	if recorder.requests != 1 {
+		t.Errorf("Expected 1 recorded mirror request, got %d", recorder.requests)
+	}
+}
+
+func TestMiddleware_MirrorErrorIgnored(t *testing.T) {
+	connector := &mockConnector{bodies: make(chan string, 10), err: errors.New("connection refused")}
+	m, recorder := newTestMiddleware(RouteConfig{ServiceName: "users-shadow", Percentage: 100}, connector)
+
+	if primary := serve(t, m.Handler, &mockRequest{body: "data"}); primary != "data" {
+		t.Errorf("Expected primary to be served, got %q", primary)
+	}
+
+	waitFor(t, recorder.done)
+	if recorder.errors != 1 || recorder.requests != 0 {
+		t.Errorf("Expected 1 recorded mirror error, got %d errors and %d requests", recorder.errors, recorder.requests)
+	}
+}
+
+func TestMiddleware_Header(t *testing.T) {
+	connector := &mockConnector{bodies: make(chan string, 10)}
+	m, recorder := newTestMiddleware(RouteConfig{ServiceName: "users-shadow", Percentage: 100, Header: "X-Mirror"}, connector)
+
+	serve(t, m.Handler, &mockRequest{body: "skipped"})
+	serve(t, m.Handler, &mockRequest{body: "mirrored", headers: map[string][]string{"X-Mirror": {"1"}}})
+
+	waitFor(t, recorder.done)
+	if body := <-connector.bodies; body != "users-shadow:mirrored" {
+		t.Errorf("Expected only the request with the header to be mirrored, got %q", body)
+	}
+	select {
+	case body := <-connector.bodies:
+		t.Errorf("Expected a single mirrored request, got %q", body)
+	default:
+	}
+}
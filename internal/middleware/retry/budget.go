@@ -6,7 +6,9 @@ import (
 	"time"
 )
 
-// Budget tracks retry budget to prevent retry storms
+// Budget tracks retry budget to prevent retry storms.
+// Counts from the previous window are weighted by how much of it still
+// overlaps the sliding window, so the budget does not reset abruptly.
 type Budget struct {
 	// Configuration
 	ratio        float64       // Ratio of requests that can be retried (0-1)
@@ -17,6 +19,8 @@ type Budget struct {
 	requests     atomic.Int64  // Total requests in window
 	retries      atomic.Int64  // Total retries in window
 	windowStart  atomic.Int64  // Start of current window (unix nano)
+	prevRequests atomic.Int64  // Total requests in previous window
+	prevRetries  atomic.Int64  // Total retries in previous window
 	mu           sync.Mutex    // Protects window rotation
 }
 
@@ -51,16 +55,13 @@ func (b *Budget) CanRetry() bool {
 	b.maybeRotateWindow()
 	
 	// Always allow retries until we have enough requests
-	requests := b.requests.Load()
-	if requests < b.minRequests {
+	requests, retries := b.counts()
+	if requests < float64(b.minRequests) {
 		return true
 	}
 	
 	// Check if we're within budget
-	retries := b.retries.Load()
-	maxRetries := int64(float64(requests) * b.ratio)
-	
-	return retries < maxRetries
+	return retries < requests*b.ratio
 }
 
 // RecordRequest records a request attempt
@@ -79,17 +80,16 @@ func (b *Budget) RecordRetry() {
 func (b *Budget) Stats() BudgetStats {
 	b.maybeRotateWindow()
 	
-	requests := b.requests.Load()
-	retries := b.retries.Load()
+	requests, retries := b.counts()
 	
 	var retryRate float64
 	if requests > 0 {
-		retryRate = float64(retries) / float64(requests)
+		retryRate = retries / requests
 	}
 	
 	return BudgetStats{
-		Requests:       requests,
-		Retries:        retries,
+		Requests:       int64(requests),
+		Retries:        int64(retries),
 		RetryRate:      retryRate,
 		BudgetRatio:    b.ratio,
 		WindowStart:    time.Unix(0, b.windowStart.Load()),
@@ -97,6 +97,19 @@ func (b *Budget) Stats() BudgetStats {
 	}
 }
 
+// counts returns requests and retries over the sliding window
+func (b *Budget) counts() (requests, retries float64) {
+	elapsed := time.Now().UnixNano() - b.windowStart.Load()
+	weight := 1 - float64(elapsed)/float64(b.windowSize)
+	if weight < 0 {
+		weight = 0
+	}
+	
+	requests = float64(b.requests.Load()) + float64(b.prevRequests.Load())*weight
+	retries = float64(b.retries.Load()) + float64(b.prevRetries.Load())*weight
+	return requests, retries
+}
+
 // maybeRotateWindow checks if we need to start a new time window
 func (b *Budget) maybeRotateWindow() {
 	now := time.Now().UnixNano()
@@ -117,6 +130,15 @@ func (b *Budget) maybeRotateWindow() {
 		return
 	}
 	
+	// Carry the finished window over if it is still adjacent
+	if now-windowStart < 2*int64(b.windowSize) {
+		b.prevRequests.Store(b.requests.Load())
+		b.prevRetries.Store(b.retries.Load())
+	} else {
+		b.prevRequests.Store(0)
+		b.prevRetries.Store(0)
+	}
+	
 	// Reset counters for new window
 	b.requests.Store(0)
 	b.retries.Store(0)
@@ -0,0 +1,194 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/pkg/retry"
+)
+
+// mockBudgetMetrics counts exhausted budget notifications per service
+type mockBudgetMetrics struct {
+	exhausted map[string]int
+}
+
+func (m *mockBudgetMetrics) RecordRetryBudgetExhausted(ctx context.Context, service string) {
+	m.exhausted[service]++
+}
+
+func TestBudget_EnforcesRatio(t *testing.T) {
+	budget := NewBudget(0.1, 10, time.Minute)
+
+	for i := 0; i < 20; i++ {
+		budget.RecordRequest()
+	}
+
+	// 10% of 20 requests allows 2 retries
+	for i := 0; i < 2; i++ {
+		if !budget.CanRetry() {
+			t.Fatalf("Expected retry %d to be allowed", i+1)
+		}
+		budget.RecordRetry()
+	}
+
+	if budget.CanRetry() {
+		t.Error("Expected budget to be exhausted")
+	}
+}
+
+func TestBudget_SlidingWindow(t *testing.T) {
+	budget := NewBudget(0.5, 1, 100*time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		budget.RecordRequest()
+		budget.RecordRetry()
+	}
+	if budget.CanRetry() {
+		t.Fatal("Expected budget to be exhausted")
+	}
+
+	// Just after rotation the previous window still counts almost fully
+	time.Sleep(110 * time.Millisecond)
+	budget.RecordRequest()
+	if budget.CanRetry() {
+		t.Error("Expected previous window retries to still count after rotation")
+	}
+
+	// Once the previous window has slid out the budget recovers
+	time.Sleep(100 * time.Millisecond)
+	budget.RecordRequest()
+	if !budget.CanRetry() {
+		t.Error("Expected budget to recover after the window slides")
+	}
+}
+
+func TestMiddleware_Apply_ServiceBudgetExhausted(t *testing.T) {
+	metrics := &mockBudgetMetrics{exhausted: make(map[string]int)}
+	middleware := NewWithBudget(Config{
+		Default: retry.Config{
+			MaxAttempts:  3,
+			InitialDelay: time.Millisecond,
+		},
+	}, 0.01, slog.Default()).WithMetrics(metrics)
+
+	var attempts int32
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		return nil, errors.New("failure")
+	}
+	wrapped := middleware.Apply()(handler)
+
+	routeCtx := func(service string) context.Context {
+		return core.WithRouteResult(context.Background(), &core.RouteResult{
+			ServiceName: service,
+			Rule:        &core.RouteRule{ID: service + "-route", ServiceName: service},
+		})
+	}
+
+	// Fill the failing service's budget with successful traffic first
+	budget := middleware.serviceBudgets.GetBudget("failing")
+	for i := 0; i < budgetMinRequests; i++ {
+		budget.RecordRequest()
+	}
+	budget.RecordRetry()
+	budget.RecordRetry()
+
+	wrapped(routeCtx("failing"), &mockRequest{method: "GET", path: "/test"})
+
+	if atomic.LoadInt32(&attempts) != 1 {
+		t.Errorf("Expected no retries once the service budget is exhausted, got %d attempts", atomic.LoadInt32(&attempts))
+	}
+	if metrics.exhausted["failing"] != 1 {
+		t.Errorf("Expected budget exhaustion to be recorded once, got %d", metrics.exhausted["failing"])
+	}
+
+	// Other services keep their own budget
+	atomic.StoreInt32(&attempts, 0)
+	wrapped(routeCtx("healthy"), &mockRequest{method: "GET", path: "/test"})
+
+	if atomic.LoadInt32(&attempts) != 3 {
+		t.Errorf("Expected other service to retry, got %d attempts", atomic.LoadInt32(&attempts))
+	}
+}
+
+func TestMiddleware_Apply_RetryAfter(t *testing.T) {
+	middleware := New(Config{
+		Default: retry.Config{
+			MaxAttempts:  2,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     2 * time.Second,
+		},
+	}, slog.Default())
+
+	var attempts int32
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return &mockResponse{
+				statusCode: http.StatusServiceUnavailable,
+				headers:    map[string][]string{"Retry-After": {"1"}},
+			}, nil
+		}
+		return &mockResponse{statusCode: http.StatusOK}, nil
+	}
+
+	start := time.Now()
+	resp, err := middleware.Apply()(handler)(context.Background(), &mockRequest{method: "GET", path: "/test"})
+	duration := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("Expected success after retry, got %v", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode())
+	}
+	if duration < time.Second {
+		t.Errorf("Expected Retry-After delay of 1s, took %v", duration)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		value  string
+		delay  time.Duration
+		ok     bool
+	}{
+		{"seconds on 429", http.StatusTooManyRequests, "5", 5 * time.Second, true},
+		{"seconds on 503", http.StatusServiceUnavailable, "2", 2 * time.Second, true},
+		{"ignored on 500", http.StatusInternalServerError, "5", 0, false},
+		{"invalid value", http.StatusTooManyRequests, "soon", 0, false},
+		{"negative value", http.StatusTooManyRequests, "-1", 0, false},
+		{"missing header", http.StatusTooManyRequests, "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &mockResponse{statusCode: tt.status, headers: map[string][]string{}}
+			if tt.value != "" {
+				resp.headers["retry-after"] = []string{tt.value}
+			}
+
+			delay, ok := retryAfter(resp)
+			if ok != tt.ok || delay != tt.delay {
+				t.Errorf("retryAfter() = (%v, %v), want (%v, %v)", delay, ok, tt.delay, tt.ok)
+			}
+		})
+	}
+
+	// HTTP date format
+	resp := &mockResponse{
+		statusCode: http.StatusServiceUnavailable,
+		headers:    map[string][]string{"Retry-After": {time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)}},
+	}
+	delay, ok := retryAfter(resp)
+	if !ok || delay <= 8*time.Second || delay > 10*time.Second {
+		t.Errorf("Expected ~10s delay from HTTP date, got (%v, %v)", delay, ok)
+	}
+}
@@ -48,19 +48,32 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 	
 	// Convert config to middleware config
 	c.config = Config{
-		Default:  convertRetryConfig(retryConfig.Default),
-		Routes:   make(map[string]retry.Config),
-		Services: make(map[string]retry.Config),
+		Default:             convertRetryConfig(retryConfig.Default),
+		Routes:              make(map[string]retry.Config),
+		Services:            make(map[string]retry.Config),
+		DefaultPolicy:       convertRetryPolicy(retryConfig.Default),
+		RoutePolicies:       make(map[string]Policy),
+		ServicePolicies:     make(map[string]Policy),
+		ServiceBudgetRatios: make(map[string]float64),
 	}
 	
 	// Convert route-specific configs
 	for route, routeCfg := range retryConfig.Routes {
 		c.config.Routes[route] = convertRetryConfig(routeCfg)
+		if len(routeCfg.RetryOn) > 0 || len(routeCfg.RetryableMethods) > 0 {
+			c.config.RoutePolicies[route] = convertRetryPolicy(routeCfg)
+		}
 	}
 	
 	// Convert service-specific configs
 	for service, serviceCfg := range retryConfig.Services {
 		c.config.Services[service] = convertRetryConfig(serviceCfg)
+		if len(serviceCfg.RetryOn) > 0 || len(serviceCfg.RetryableMethods) > 0 {
+			c.config.ServicePolicies[service] = convertRetryPolicy(serviceCfg)
+		}
+		if serviceCfg.BudgetRatio > 0 {
+			c.config.ServiceBudgetRatios[service] = serviceCfg.BudgetRatio
+		}
 	}
 	
 	// Create middleware with budget
@@ -123,5 +136,13 @@ func convertRetryConfig(cfg config.RetryConfig) retry.Config {
 	}
 }
 
+// convertRetryPolicy extracts the retry conditions from config
+func convertRetryPolicy(cfg config.RetryConfig) Policy {
+	return Policy{
+		RetryOn:          cfg.RetryOn,
+		RetryableMethods: cfg.RetryableMethods,
+	}
+}
+
 // Ensure Component implements factory.Component
 var _ factory.Component = (*Component)(nil)
\ No newline at end of file
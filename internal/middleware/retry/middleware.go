@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"gateway/internal/core"
@@ -11,6 +14,18 @@ import (
 	gwerrors "gateway/pkg/errors"
 )
 
+// ErrRetriesExhausted matches errors of requests that failed on every
+// attempt or ran out of retry budget
+var ErrRetriesExhausted = errors.New("retries exhausted")
+
+// exhaustedError is the last error of a request whose retries are exhausted
+type exhaustedError struct {
+	err error
+}
+
+func (e *exhaustedError) Error() string   { return e.err.Error() }
+func (e *exhaustedError) Unwrap() []error { return []error{e.err, ErrRetriesExhausted} }
+
 // Config holds retry middleware configuration
 type Config struct {
 	// Default retry configuration for all routes
@@ -19,14 +34,37 @@ type Config struct {
 	Routes map[string]retry.Config
 	// Per-service retry configurations
 	Services map[string]retry.Config
+	// Retry conditions for the default configuration
+	DefaultPolicy Policy
+	// Per-route retry conditions
+	RoutePolicies map[string]Policy
+	// Per-service retry conditions
+	ServicePolicies map[string]Policy
+	// Per-service retry budget ratios, services without one use the default ratio
+	ServiceBudgetRatios map[string]float64
+}
+
+// BudgetMetricsRecorder receives notifications when a retry budget is exhausted
+type BudgetMetricsRecorder interface {
+	RecordRetryBudgetExhausted(ctx context.Context, service string)
 }
 
+const (
+	// budgetMinRequests is the number of requests before a budget is enforced
+	budgetMinRequests = 100
+	// budgetWindow is the sliding window over which budgets are tracked
+	budgetWindow = time.Minute
+)
+
 // Middleware implements retry logic for backend requests
 type Middleware struct {
-	config       Config
-	retriers     map[string]*retry.Retrier
-	retryBudget  *GlobalBudget
-	logger       *slog.Logger
+	config         Config
+	retriers       map[string]*retry.Retrier
+	policies       map[string]*retryPolicy
+	retryBudget    *GlobalBudget
+	serviceBudgets *PerRouteBudget
+	metrics        BudgetMetricsRecorder
+	logger         *slog.Logger
 }
 
 // New creates a new retry middleware
@@ -47,22 +85,42 @@ func New(config Config, logger *slog.Logger) *Middleware {
 		retriers["service:"+service] = retry.New(cfg)
 	}
 
+	// Parse retry conditions; routes and services fall back to the default
+	// conditions and methods they do not set
+	policies := make(map[string]*retryPolicy)
+	policies["default"] = newRetryPolicy(config.DefaultPolicy)
+	for route, policy := range config.RoutePolicies {
+		policies["route:"+route] = newRetryPolicy(policy.over(config.DefaultPolicy))
+	}
+	for service, policy := range config.ServicePolicies {
+		policies["service:"+service] = newRetryPolicy(policy.over(config.DefaultPolicy))
+	}
+
 	// Create a global retry budget (10% of requests can retry by default)
 	// This prevents retry storms when backends are failing
-	retryBudget := NewGlobalBudget(0.1, 100, time.Minute)
+	retryBudget := NewGlobalBudget(0.1, budgetMinRequests, budgetWindow)
 
 	return &Middleware{
-		config:      config,
-		retriers:    retriers,
-		retryBudget: retryBudget,
-		logger:      logger.With("component", "retry"),
+		config:         config,
+		retriers:       retriers,
+		policies:       policies,
+		retryBudget:    retryBudget,
+		serviceBudgets: NewPerRouteBudget(0.1, budgetMinRequests, budgetWindow, config.ServiceBudgetRatios),
+		logger:         logger.With("component", "retry"),
 	}
 }
 
 // NewWithBudget creates a new retry middleware with custom budget
 func NewWithBudget(config Config, budgetRatio float64, logger *slog.Logger) *Middleware {
 	m := New(config, logger)
-	m.retryBudget = NewGlobalBudget(budgetRatio, 100, time.Minute)
+	m.retryBudget = NewGlobalBudget(budgetRatio, budgetMinRequests, budgetWindow)
+	m.serviceBudgets = NewPerRouteBudget(budgetRatio, budgetMinRequests, budgetWindow, config.ServiceBudgetRatios)
+	return m
+}
+
+// WithMetrics sets the recorder notified when a retry budget is exhausted
+func (m *Middleware) WithMetrics(metrics BudgetMetricsRecorder) *Middleware {
+	m.metrics = metrics
 	return m
 }
 
@@ -70,16 +128,28 @@ func NewWithBudget(config Config, budgetRatio float64, logger *slog.Logger) *Mid
 func (m *Middleware) Apply() core.Middleware {
 	return func(next core.Handler) core.Handler {
 		return func(ctx context.Context, req core.Request) (core.Response, error) {
-			// Record the request for budget tracking
+			// Record the request for budget tracking, per service when known
 			m.retryBudget.RecordRequest()
+			service := m.serviceName(ctx)
+			var serviceBudget *Budget
+			if service != "" {
+				serviceBudget = m.serviceBudgets.GetBudget(service)
+				serviceBudget.RecordRequest()
+			}
 			
-			// Get retrier based on route or service
-			retrier := m.getRetrier(ctx)
+			// Get retrier and retry conditions based on route or service
+			retrier, policy := m.getRetrier(ctx)
+
+			// Non-idempotent requests are sent once to avoid duplicate side effects
+			if !policy.allowsMethod(req.Method()) {
+				return next(ctx, req)
+			}
 
 			var resp core.Response
 			var lastErr error
 			startTime := time.Now()
 			attemptCount := 0
+			budgetExhausted := false
 
 			// Use retrier to execute the request
 			err := retrier.Do(ctx, func(ctx context.Context) error {
@@ -87,15 +157,29 @@ func (m *Middleware) Apply() core.Middleware {
 				
 				// Check retry budget before retrying (first attempt always allowed)
 				if attemptCount > 1 {
-					if !m.retryBudget.CanRetry() {
+					canRetry := m.retryBudget.CanRetry()
+					stats := m.retryBudget.Stats()
+					if serviceBudget != nil {
+						canRetry = serviceBudget.CanRetry()
+						stats = serviceBudget.Stats()
+					}
+					if !canRetry {
 						m.logger.Debug("retry budget exhausted",
 							"path", req.Path(),
-							"budget_stats", m.retryBudget.Stats(),
+							"service", service,
+							"budget_stats", stats,
 						)
+						if m.metrics != nil {
+							m.metrics.RecordRetryBudgetExhausted(ctx, service)
+						}
+						budgetExhausted = true
 						return retry.NewNonRetryableError(lastErr)
 					}
 					// Record the retry
 					m.retryBudget.RecordRetry()
+					if serviceBudget != nil {
+						serviceBudget.RecordRetry()
+					}
 				}
 				
 				var err error
@@ -103,7 +187,7 @@ func (m *Middleware) Apply() core.Middleware {
 
 				if err != nil {
 					// Check if error is retryable
-					if !m.isRetryableError(err) {
+					if !m.shouldRetryError(policy, err) {
 						return retry.NewNonRetryableError(err)
 					}
 					lastErr = err
@@ -111,7 +195,7 @@ func (m *Middleware) Apply() core.Middleware {
 				}
 
 				// Check response status for retryable conditions
-				if resp != nil && m.isRetryableStatus(resp.StatusCode()) {
+				if resp != nil && m.shouldRetryStatus(policy, resp.StatusCode()) {
 					lastErr = &gwerrors.Error{
 						Type:    gwerrors.ErrorTypeInternal,
 						Message: "Retryable HTTP status",
@@ -119,6 +203,10 @@ func (m *Middleware) Apply() core.Middleware {
 							"status": resp.StatusCode(),
 						},
 					}
+					// Honor the backend's requested delay on throttling and unavailability
+					if delay, ok := retryAfter(resp); ok {
+						return retry.NewRetryAfterError(lastErr, delay)
+					}
 					return lastErr
 				}
 
@@ -128,7 +216,9 @@ func (m *Middleware) Apply() core.Middleware {
 			// Handle retry exhaustion
 			if err != nil {
 				var retryErr *retry.Error
+				exhausted := budgetExhausted
 				if errors.As(err, &retryErr) {
+					exhausted = true
 					m.logger.Warn("retry exhausted",
 						"path", req.Path(),
 						"attempts", retryErr.Attempts,
@@ -139,7 +229,10 @@ func (m *Middleware) Apply() core.Middleware {
 
 				// Return the last error
 				if lastErr != nil {
-					return nil, lastErr
+					err = lastErr
+				}
+				if exhausted {
+					err = &exhaustedError{err: err}
 				}
 				return nil, err
 			}
@@ -158,30 +251,107 @@ func (m *Middleware) Apply() core.Middleware {
 	}
 }
 
-// routeContextKey is the key for storing route info in context
-type routeContextKey struct{}
+// getRetrier returns the appropriate retrier and retry conditions for the request
+func (m *Middleware) getRetrier(ctx context.Context) (*retry.Retrier, *retryPolicy) {
+	key := m.configKey(ctx)
+
+	policy, exists := m.policies[key]
+	if !exists {
+		policy = m.policies["default"]
+	}
+
+	return m.retriers[key], policy
+}
 
-// getRetrier returns the appropriate retrier for the request
-func (m *Middleware) getRetrier(ctx context.Context) *retry.Retrier {
+// configKey returns the retrier key for the request
+func (m *Middleware) configKey(ctx context.Context) string {
 	// Try to get route result from context (set by route-aware middleware)
-	if route, ok := ctx.Value(routeContextKey{}).(*core.RouteResult); ok && route != nil {
+	if route := core.RouteResultFromContext(ctx); route != nil {
 		// Try route-specific retrier
 		if route.Rule != nil && route.Rule.ID != "" {
-			if retrier, exists := m.retriers["route:"+route.Rule.ID]; exists {
-				return retrier
+			if _, exists := m.retriers["route:"+route.Rule.ID]; exists {
+				return "route:" + route.Rule.ID
 			}
 		}
 		
-		// Try service-specific retrier
-		if route.Rule != nil && route.Rule.ServiceName != "" {
-			if retrier, exists := m.retriers["service:"+route.Rule.ServiceName]; exists {
-				return retrier
+		// Try service-specific retrier, of the service picked for split routes
+		if service := m.serviceName(ctx); service != "" {
+			if _, exists := m.retriers["service:"+service]; exists {
+				return "service:" + service
 			}
 		}
 	}
 
-	// Return default retrier
-	return m.retriers["default"]
+	// Use default retrier
+	return "default"
+}
+
+// serviceName returns the backend service for the request, if known
+func (m *Middleware) serviceName(ctx context.Context) string {
+	route := core.RouteResultFromContext(ctx)
+	if route == nil {
+		return ""
+	}
+	if route.ServiceName != "" {
+		return route.ServiceName
+	}
+	if route.Rule != nil {
+		return route.Rule.ServiceName
+	}
+	return ""
+}
+
+// retryAfter parses the Retry-After header of a 429 or 503 response,
+// which holds either a number of seconds or an HTTP date
+func retryAfter(resp core.Response) (time.Duration, bool) {
+	status := resp.StatusCode()
+	if status != http.StatusTooManyRequests && status != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	var value string
+	for name, values := range resp.Headers() {
+		if strings.EqualFold(name, "Retry-After") && len(values) > 0 {
+			value = strings.TrimSpace(values[0])
+			break
+		}
+	}
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// shouldRetryError applies the configured retry conditions to a backend error
+func (m *Middleware) shouldRetryError(policy *retryPolicy, err error) bool {
+	if policy == nil || !policy.configured {
+		return m.isRetryableError(err)
+	}
+	return policy.matchesError(err)
+}
+
+// shouldRetryStatus applies the configured retry conditions to a response status
+func (m *Middleware) shouldRetryStatus(policy *retryPolicy, status int) bool {
+	if policy == nil || !policy.configured {
+		return m.isRetryableStatus(status)
+	}
+	return policy.matchesStatus(status)
 }
 
 // isRetryableError determines if an error should trigger a retry
@@ -199,7 +369,9 @@ func (m *Middleware) isRetryableError(err error) bool {
 			gwerrors.ErrorTypeUnauthorized,
 			gwerrors.ErrorTypeForbidden,
 			gwerrors.ErrorTypeNotFound,
-			gwerrors.ErrorTypeRateLimit:
+			gwerrors.ErrorTypeRateLimit,
+			gwerrors.ErrorTypePayloadTooLarge,
+			gwerrors.ErrorTypeUnsupportedMediaType:
 			return false
 		}
 	}
@@ -185,6 +185,10 @@ func TestMiddleware_Apply_MaxAttemptsExceeded(t *testing.T) {
 		t.Fatal("Expected error after max attempts")
 	}
 	
+	if !errors.Is(err, ErrRetriesExhausted) {
+		t.Errorf("Expected error to match ErrRetriesExhausted, got %v", err)
+	}
+
 	if resp != nil {
 		t.Error("Expected nil response on failure")
 	}
@@ -305,7 +309,7 @@ func TestMiddleware_Apply_RouteSpecificConfig(t *testing.T) {
 			ID: "critical-route",
 		},
 	}
-	ctx := context.WithValue(context.Background(), routeContextKey{}, routeResult)
+	ctx := core.WithRouteResult(context.Background(), routeResult)
 	
 	req := &mockRequest{
 		method: "GET",
@@ -328,6 +332,32 @@ func TestMiddleware_Apply_RouteSpecificConfig(t *testing.T) {
 	}
 }
 
+func TestMiddleware_Apply_SplitRouteServiceConfig(t *testing.T) {
+	middleware := New(Config{
+		Default: retry.Config{MaxAttempts: 1},
+		Services: map[string]retry.Config{
+			"orders-canary": {MaxAttempts: 3, InitialDelay: time.Millisecond},
+		},
+	}, slog.Default())
+
+	var attempts int32
+	wrapped := middleware.Apply()(func(ctx context.Context, req core.Request) (core.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		return nil, errors.New("failure")
+	})
+
+	// Split routes name the picked service on the result, not the rule
+	ctx := core.WithRouteResult(context.Background(), &core.RouteResult{
+		ServiceName: "orders-canary",
+		Rule:        &core.RouteRule{ID: "orders", TrafficSplit: &core.TrafficSplit{}},
+	})
+	wrapped(ctx, &mockRequest{method: "GET", path: "/orders"})
+
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("Expected the canary service's 3 attempts, got %d", got)
+	}
+}
+
 func TestMiddleware_Apply_ExponentialBackoff(t *testing.T) {
 	config := Config{
 		Default: retry.Config{
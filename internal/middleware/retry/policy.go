@@ -0,0 +1,151 @@
+package retry
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"syscall"
+
+	gwerrors "gateway/pkg/errors"
+)
+
+// Retry conditions accepted in Policy.RetryOn
+const (
+	// RetryOn5xx retries any 5xx response and internal errors
+	RetryOn5xx = "5xx"
+	// RetryOnGatewayError retries 502, 503 and 504 responses
+	RetryOnGatewayError = "gateway-error"
+	// RetryOnConnectFailure retries when the backend could not be reached
+	RetryOnConnectFailure = "connect-failure"
+	// RetryOnTimeout retries backend timeouts
+	RetryOnTimeout = "timeout"
+	// RetryOnReset retries when the backend reset the connection
+	RetryOnReset = "reset"
+)
+
+// defaultRetryableMethods are the idempotent methods retried when none are configured
+var defaultRetryableMethods = []string{"GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE"}
+
+// Policy determines when a failed request is retried
+type Policy struct {
+	// RetryOn lists the conditions that trigger a retry: 5xx, gateway-error,
+	// connect-failure, timeout, reset, or explicit status codes such as "502,503".
+	// When empty, the middleware's built-in status and error checks apply.
+	RetryOn []string
+	// RetryableMethods lists the HTTP methods that may be retried ("*" allows all).
+	// When empty, only idempotent methods are retried.
+	RetryableMethods []string
+}
+
+// retryPolicy is the parsed form of a Policy
+type retryPolicy struct {
+	configured     bool
+	any5xx         bool
+	statuses       map[int]bool
+	connectFailure bool
+	timeout        bool
+	reset          bool
+	allMethods     bool
+	methods        map[string]bool
+}
+
+// newRetryPolicy parses a policy, ignoring unknown conditions
+func newRetryPolicy(p Policy) *retryPolicy {
+	rp := &retryPolicy{
+		statuses: make(map[int]bool),
+		methods:  make(map[string]bool),
+	}
+
+	for _, entry := range p.RetryOn {
+		for _, cond := range strings.Split(entry, ",") {
+			cond = strings.ToLower(strings.TrimSpace(cond))
+			if cond == "" {
+				continue
+			}
+			rp.configured = true
+
+			switch cond {
+			case RetryOn5xx:
+				rp.any5xx = true
+			case RetryOnGatewayError:
+				rp.statuses[502] = true
+				rp.statuses[503] = true
+				rp.statuses[504] = true
+			case RetryOnConnectFailure:
+				rp.connectFailure = true
+			case RetryOnTimeout:
+				rp.timeout = true
+			case RetryOnReset:
+				rp.reset = true
+			default:
+				if code, err := strconv.Atoi(cond); err == nil {
+					rp.statuses[code] = true
+				}
+			}
+		}
+	}
+
+	methods := p.RetryableMethods
+	if len(methods) == 0 {
+		methods = defaultRetryableMethods
+	}
+	for _, method := range methods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "*" {
+			rp.allMethods = true
+		}
+		rp.methods[method] = true
+	}
+
+	return rp
+}
+
+// over returns p with the conditions and methods it does not set taken
+// from base
+func (p Policy) over(base Policy) Policy {
+	if len(p.RetryOn) == 0 {
+		p.RetryOn = base.RetryOn
+	}
+	if len(p.RetryableMethods) == 0 {
+		p.RetryableMethods = base.RetryableMethods
+	}
+	return p
+}
+
+// allowsMethod reports whether requests with the given method may be retried
+func (p *retryPolicy) allowsMethod(method string) bool {
+	return p.allMethods || p.methods[strings.ToUpper(method)]
+}
+
+// matchesStatus reports whether a response status matches the configured conditions
+func (p *retryPolicy) matchesStatus(status int) bool {
+	if p.any5xx && status >= 500 && status < 600 {
+		return true
+	}
+	return p.statuses[status]
+}
+
+// matchesError reports whether a backend error matches the configured conditions
+func (p *retryPolicy) matchesError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if p.timeout && gwerrors.IsTimeout(err) {
+		return true
+	}
+	if p.connectFailure && gwerrors.IsConnectFailure(err) {
+		return true
+	}
+	if p.reset && errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var gwErr *gwerrors.Error
+	if p.any5xx && errors.As(err, &gwErr) &&
+		(gwErr.Type == gwerrors.ErrorTypeInternal || gwErr.Type == gwerrors.ErrorTypeBadGateway) {
+		return true
+	}
+
+	return false
+}
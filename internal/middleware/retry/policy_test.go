@@ -0,0 +1,233 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+	"gateway/pkg/retry"
+)
+
+func TestRetryPolicy_Methods(t *testing.T) {
+	tests := []struct {
+		name    string
+		methods []string
+		method  string
+		allowed bool
+	}{
+		{"default GET", nil, "GET", true},
+		{"default PUT", nil, "PUT", true},
+		{"default POST", nil, "POST", false},
+		{"default PATCH", nil, "PATCH", false},
+		{"explicit POST", []string{"post"}, "POST", true},
+		{"explicit excludes GET", []string{"POST"}, "GET", false},
+		{"wildcard", []string{"*"}, "PATCH", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newRetryPolicy(Policy{RetryableMethods: tt.methods})
+			if got := p.allowsMethod(tt.method); got != tt.allowed {
+				t.Errorf("allowsMethod(%s) = %v, want %v", tt.method, got, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Status(t *testing.T) {
+	tests := []struct {
+		name    string
+		retryOn []string
+		status  int
+		retry   bool
+	}{
+		{"5xx matches 500", []string{"5xx"}, 500, true},
+		{"5xx ignores 429", []string{"5xx"}, 429, false},
+		{"gateway-error matches 503", []string{"gateway-error"}, 503, true},
+		{"gateway-error ignores 500", []string{"gateway-error"}, 500, false},
+		{"comma list", []string{"502, 503,504"}, 504, true},
+		{"comma list excludes", []string{"502,503,504"}, 500, false},
+		{"explicit 429", []string{"429"}, 429, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newRetryPolicy(Policy{RetryOn: tt.retryOn})
+			if !p.configured {
+				t.Fatal("Expected policy to be configured")
+			}
+			if got := p.matchesStatus(tt.status); got != tt.retry {
+				t.Errorf("matchesStatus(%d) = %v, want %v", tt.status, got, tt.retry)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Errors(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	resetErr := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
+	tests := []struct {
+		name    string
+		retryOn []string
+		err     error
+		retry   bool
+	}{
+		{"connect-failure dial", []string{"connect-failure"}, dialErr, true},
+		{"connect-failure unavailable", []string{"connect-failure"},
+			gwerrors.NewError(gwerrors.ErrorTypeUnavailable, "send failed"), true},
+		{"connect-failure ignores timeout", []string{"connect-failure"},
+			gwerrors.NewError(gwerrors.ErrorTypeTimeout, "timed out"), false},
+		{"timeout", []string{"timeout"}, gwerrors.NewError(gwerrors.ErrorTypeTimeout, "timed out"), true},
+		{"timeout deadline", []string{"timeout"}, fmt.Errorf("wrapped: %w", context.DeadlineExceeded), true},
+		{"timeout ignores dial", []string{"timeout"}, dialErr, false},
+		{"reset", []string{"reset"}, resetErr, true},
+		{"5xx internal", []string{"5xx"}, gwerrors.NewError(gwerrors.ErrorTypeInternal, "internal"), true},
+		{"5xx bad gateway", []string{"5xx"}, gwerrors.NewError(gwerrors.ErrorTypeBadGateway, "headers too large"), true},
+		{"5xx ignores generic", []string{"5xx"}, errors.New("failure"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newRetryPolicy(Policy{RetryOn: tt.retryOn})
+			if got := p.matchesError(tt.err); got != tt.retry {
+				t.Errorf("matchesError(%v) = %v, want %v", tt.err, got, tt.retry)
+			}
+		})
+	}
+}
+
+func TestMiddleware_Apply_NonIdempotentNotRetried(t *testing.T) {
+	middleware := New(Config{
+		Default: retry.Config{
+			MaxAttempts:  3,
+			InitialDelay: time.Millisecond,
+		},
+	}, slog.Default())
+
+	var attempts int32
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		return nil, errors.New("failure")
+	}
+
+	wrapped := middleware.Apply()(handler)
+	if _, err := wrapped(context.Background(), &mockRequest{method: "POST", path: "/orders"}); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if atomic.LoadInt32(&attempts) != 1 {
+		t.Errorf("Expected POST to be sent once, got %d attempts", atomic.LoadInt32(&attempts))
+	}
+}
+
+func TestMiddleware_Apply_RetryableMethodsAllowPost(t *testing.T) {
+	middleware := New(Config{
+		Default: retry.Config{
+			MaxAttempts:  3,
+			InitialDelay: time.Millisecond,
+		},
+		DefaultPolicy: Policy{
+			RetryableMethods: []string{"POST"},
+		},
+	}, slog.Default())
+
+	var attempts int32
+	handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		return nil, errors.New("failure")
+	}
+
+	wrapped := middleware.Apply()(handler)
+	wrapped(context.Background(), &mockRequest{method: "POST", path: "/orders"})
+
+	if atomic.LoadInt32(&attempts) != 3 {
+		t.Errorf("Expected 3 attempts for allowed POST, got %d", atomic.LoadInt32(&attempts))
+	}
+}
+
+func TestMiddleware_Apply_RetryOnStatus(t *testing.T) {
+	middleware := New(Config{
+		Default: retry.Config{
+			MaxAttempts:  3,
+			InitialDelay: time.Millisecond,
+		},
+		DefaultPolicy: Policy{
+			RetryOn: []string{"503"},
+		},
+	}, slog.Default())
+
+	tests := []struct {
+		status   int
+		attempts int32
+	}{
+		{503, 3},
+		{500, 1},
+	}
+
+	for _, tt := range tests {
+		var attempts int32
+		handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+			atomic.AddInt32(&attempts, 1)
+			return &mockResponse{statusCode: tt.status}, nil
+		}
+
+		wrapped := middleware.Apply()(handler)
+		wrapped(context.Background(), &mockRequest{method: "GET", path: "/test"})
+
+		if atomic.LoadInt32(&attempts) != tt.attempts {
+			t.Errorf("Status %d: expected %d attempts, got %d", tt.status, tt.attempts, atomic.LoadInt32(&attempts))
+		}
+	}
+}
+
+func TestMiddleware_Apply_RoutePolicyInheritsDefault(t *testing.T) {
+	middleware := New(Config{
+		Default: retry.Config{
+			MaxAttempts:  3,
+			InitialDelay: time.Millisecond,
+		},
+		Routes: map[string]retry.Config{
+			"orders": {MaxAttempts: 3, InitialDelay: time.Millisecond},
+		},
+		DefaultPolicy: Policy{
+			RetryOn: []string{"503"},
+		},
+		RoutePolicies: map[string]Policy{
+			"orders": {RetryableMethods: []string{"POST"}},
+		},
+	}, slog.Default())
+	ctx := core.WithRouteResult(context.Background(), &core.RouteResult{
+		Rule: &core.RouteRule{ID: "orders"},
+	})
+
+	tests := []struct {
+		status   int
+		attempts int32
+	}{
+		{503, 3},
+		{500, 1}, // Not in the default retryOn
+	}
+
+	for _, tt := range tests {
+		var attempts int32
+		handler := func(ctx context.Context, req core.Request) (core.Response, error) {
+			atomic.AddInt32(&attempts, 1)
+			return &mockResponse{statusCode: tt.status}, nil
+		}
+
+		middleware.Apply()(handler)(ctx, &mockRequest{method: "POST", path: "/orders"})
+
+		if atomic.LoadInt32(&attempts) != tt.attempts {
+			t.Errorf("Status %d: expected %d attempts, got %d", tt.status, tt.attempts, atomic.LoadInt32(&attempts))
+		}
+	}
+}
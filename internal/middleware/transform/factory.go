@@ -123,6 +123,7 @@ func (c *Component) convertTransformRule(rule config.TransformRule) TransformCon
 		tc.Body = &BodyConfig{
 			Operations: c.convertOperations(rule.Body.Operations),
 			Format:     rule.Body.Format,
+			MaxSize:    rule.Body.MaxSize,
 		}
 	}
 	
@@ -145,11 +146,14 @@ func (c *Component) convertOperations(ops []config.TransformOperation) []Operati
 	var operations []Operation
 	for _, op := range ops {
 		operations = append(operations, Operation{
-			Type:   op.Type,
-			Path:   op.Path,
-			Value:  op.Value,
-			From:   op.From,
-			To:     op.To,
+			Type:    op.Type,
+			Path:    op.Path,
+			Value:   op.Value,
+			From:    op.From,
+			To:      op.To,
+			Script:  op.Script,
+			Include: op.Include,
+			Exclude: op.Exclude,
 		})
 	}
 	return operations
@@ -2,11 +2,14 @@ package transform
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log/slog"
+	"strconv"
 	"strings"
 
 	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
 )
 
 // Config represents transformation middleware configuration
@@ -28,7 +31,8 @@ type TransformConfig struct {
 // BodyConfig represents body transformation configuration
 type BodyConfig struct {
 	Operations []Operation `yaml:"operations"`
-	Format     string      `yaml:"format"` // json, xml, etc.
+	Format     string      `yaml:"format"` // json (default) or xml
+	MaxSize    int64       `yaml:"maxSize"` // bytes, 0 means unlimited
 }
 
 // Condition represents a transformation condition
@@ -86,6 +90,15 @@ func (m *Middleware) Middleware() core.Middleware {
 					"path", req.Path(),
 					"error", err,
 				)
+				// Failed scripts and conversions reject the request, the body has been consumed
+				var sizeErr *BodySizeError
+				if errors.As(err, &sizeErr) {
+					sizeErr.body.Close()
+					return nil, gwerrors.NewError(gwerrors.ErrorTypePayloadTooLarge, "request body too large to transform").WithCause(err)
+				}
+				if isBodyError(err) {
+					return nil, gwerrors.NewError(gwerrors.ErrorTypeBadRequest, "request transformation failed").WithCause(err)
+				}
 				// Continue with original request
 				transformedReq = req
 			}
@@ -97,12 +110,15 @@ func (m *Middleware) Middleware() core.Middleware {
 			}
 
 			// Transform response
-			transformedResp, err := m.transformResponse(resp, req.Path())
+			transformedResp, err := m.transformResponse(resp, req)
 			if err != nil {
 				m.logger.Error("Response transformation failed",
 					"path", req.Path(),
 					"error", err,
 				)
+				if isBodyError(err) {
+					return nil, gwerrors.NewError(gwerrors.ErrorTypeInternal, "response transformation failed").WithCause(err)
+				}
 				// Return original response
 				return resp, nil
 			}
@@ -116,12 +132,14 @@ func (m *Middleware) Middleware() core.Middleware {
 func (m *Middleware) transformRequest(req core.Request) (core.Request, error) {
 	// Find matching transform config
 	var transformConfig *TransformConfig
+	var params map[string]string
 
 	// Check path-specific transforms
 	for pattern, config := range m.config.RequestTransforms {
 		if matcher, exists := m.matchers[pattern]; exists && matcher(req.Path()) {
 			c := config // Create copy to avoid reference issues
 			transformConfig = &c
+			params, _ = matchParams(pattern, req.Path())
 			break
 		}
 	}
@@ -155,13 +173,37 @@ func (m *Middleware) transformRequest(req core.Request) (core.Request, error) {
 	// Transform body
 	if transformConfig.Body != nil && req.Body() != nil {
 		contentType := getContentType(req.Headers())
-		bodyTransformer := NewJSONTransformer(transformConfig.Body.Operations, m.logger)
+		jsonTransformer := NewJSONTransformer(transformConfig.Body.Operations, m.logger).
+			WithScriptInput(ScriptInput{
+				Headers: req.Headers(),
+				Params:  params,
+				Method:  req.Method(),
+				Path:    req.Path(),
+			})
+
+		// XML request bodies are converted to JSON before forwarding
+		var bodyTransformer Transformer = jsonTransformer
+		converted := false
+		if transformConfig.Body.Format == "xml" && isXMLContentType(contentType) {
+			bodyTransformer = NewXMLTransformer(jsonTransformer)
+			converted = true
+		}
 		
-		transformedBody, err := NewBodyTransformer(req.Body(), bodyTransformer, contentType)
+		// Bodies that are neither JSON nor converted pass through unbuffered
+		if !converted && !strings.Contains(contentType, "json") {
+			transformed.body = req.Body()
+			return transformed, nil
+		}
+
+		transformedBody, err := NewLimitedBodyTransformer(req.Body(), bodyTransformer, contentType, transformConfig.Body.MaxSize)
 		if err != nil {
 			return req, err
 		}
 		transformed.body = transformedBody
+		setContentLength(transformed.headers, transformedBody.Len())
+		if converted {
+			transformed.headers["Content-Type"] = []string{bodyTransformer.GetContentType()}
+		}
 	} else {
 		transformed.body = req.Body()
 	}
@@ -170,15 +212,19 @@ func (m *Middleware) transformRequest(req core.Request) (core.Request, error) {
 }
 
 // transformResponse applies response transformations
-func (m *Middleware) transformResponse(resp core.Response, path string) (core.Response, error) {
+func (m *Middleware) transformResponse(resp core.Response, req core.Request) (core.Response, error) {
+	path := req.Path()
+
 	// Find matching transform config
 	var transformConfig *TransformConfig
+	var params map[string]string
 
 	// Check path-specific transforms
 	for pattern, config := range m.config.ResponseTransforms {
 		if matcher, exists := m.matchers[pattern]; exists && matcher(path) {
 			c := config // Create copy
 			transformConfig = &c
+			params, _ = matchParams(pattern, path)
 			break
 		}
 	}
@@ -208,13 +254,53 @@ func (m *Middleware) transformResponse(resp core.Response, path string) (core.Re
 	// Transform body
 	if transformConfig.Body != nil && resp.Body() != nil {
 		contentType := getContentType(resp.Headers())
-		bodyTransformer := NewJSONTransformer(transformConfig.Body.Operations, m.logger)
+		jsonTransformer := NewJSONTransformer(transformConfig.Body.Operations, m.logger).
+			WithScriptInput(ScriptInput{
+				Headers: resp.Headers(),
+				Params:  params,
+				Path:    path,
+			})
+
+		// Convert between JSON and XML to match what the client accepts
+		var bodyTransformer Transformer = jsonTransformer
+		converted := false
+		if transformConfig.Body.Format == "xml" {
+			wantsXML := clientWantsXML(req)
+			switch {
+			case wantsXML && strings.Contains(contentType, "json"):
+				bodyTransformer = NewJSONToXMLTransformer(jsonTransformer)
+				converted = true
+			case !wantsXML && isXMLContentType(contentType):
+				bodyTransformer = NewXMLTransformer(jsonTransformer)
+				converted = true
+			}
+		}
 		
-		transformedBody, err := NewBodyTransformer(resp.Body(), bodyTransformer, contentType)
+		// Bodies that are neither JSON nor converted pass through unbuffered
+		if !converted && !strings.Contains(contentType, "json") {
+			transformed.body = resp.Body()
+			return transformed, nil
+		}
+
+		transformedBody, err := NewLimitedBodyTransformer(resp.Body(), bodyTransformer, contentType, transformConfig.Body.MaxSize)
+		var sizeErr *BodySizeError
+		if errors.As(err, &sizeErr) {
+			// Oversized responses are forwarded with their body untransformed
+			m.logger.Warn("Response body too large to transform",
+				"path", path,
+				"limit", sizeErr.Limit,
+			)
+			transformed.body = sizeErr.body
+			return transformed, nil
+		}
 		if err != nil {
 			return resp, err
 		}
 		transformed.body = transformedBody
+		setContentLength(transformed.headers, transformedBody.Len())
+		if converted {
+			transformed.headers["Content-Type"] = []string{bodyTransformer.GetContentType()}
+		}
 	} else {
 		transformed.body = resp.Body()
 	}
@@ -274,6 +360,14 @@ func (m *Middleware) checkCondition(condition Condition, req core.Request) bool
 // Helper functions
 
 func createMatcher(pattern string) func(string) bool {
+	// Patterns with :name segments capture path parameters
+	if strings.Contains(pattern, "/:") {
+		return func(path string) bool {
+			_, ok := matchParams(pattern, path)
+			return ok
+		}
+	}
+
 	// Simple wildcard matching
 	if strings.HasSuffix(pattern, "*") {
 		prefix := strings.TrimSuffix(pattern, "*")
@@ -286,6 +380,64 @@ func createMatcher(pattern string) func(string) bool {
 	}
 }
 
+// matchParams matches a path against a pattern with :name segments and a
+// trailing * wildcard, returning the captured parameters
+func matchParams(pattern, path string) (map[string]string, bool) {
+	params := make(map[string]string)
+	if !strings.Contains(pattern, "/:") {
+		return params, true
+	}
+
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, part := range patternParts {
+		if part == "*" && i == len(patternParts)-1 {
+			return params, true
+		}
+		if i >= len(pathParts) {
+			return nil, false
+		}
+		if strings.HasPrefix(part, ":") {
+			params[part[1:]] = pathParts[i]
+		} else if part != pathParts[i] {
+			return nil, false
+		}
+	}
+
+	return params, len(pathParts) == len(patternParts)
+}
+
+// clientWantsXML checks the Accept header, falling back to the request
+// content type when the client accepts anything
+func clientWantsXML(req core.Request) bool {
+	headers := req.Headers()
+	accept := ""
+	if values, ok := headers["Accept"]; ok && len(values) > 0 {
+		accept = strings.ToLower(strings.Join(values, ","))
+	}
+
+	if strings.Contains(accept, "xml") {
+		return true
+	}
+	if strings.Contains(accept, "json") {
+		return false
+	}
+	return isXMLContentType(getContentType(headers))
+}
+
+// isBodyError checks for script and format conversion failures
+func isBodyError(err error) bool {
+	var scriptErr *ScriptError
+	var formatErr *FormatError
+	return errors.As(err, &scriptErr) || errors.As(err, &formatErr)
+}
+
+// setContentLength replaces the length of a rewritten body
+func setContentLength(headers map[string][]string, length int) {
+	headers["Content-Length"] = []string{strconv.Itoa(length)}
+}
+
 func copyHeaders(headers map[string][]string) map[string][]string {
 	result := make(map[string][]string)
 	for k, v := range headers {
@@ -0,0 +1,103 @@
+package transform
+
+import (
+	"gateway/pkg/jsonpath"
+)
+
+// parseJSONPaths parses a list of JSONPath expressions, skipping unsupported
+// ones, which configuration validation rejects
+func parseJSONPaths(paths []string) [][]string {
+	var parsed [][]string
+	for _, path := range paths {
+		if segments := jsonpath.Parse(path); len(segments) > 0 {
+			parsed = append(parsed, segments)
+		}
+	}
+	return parsed
+}
+
+// projectFields keeps only the included paths, then removes the excluded ones
+func (t *JSONTransformer) projectFields(data interface{}, include, exclude []string) (interface{}, error) {
+	if includePaths := parseJSONPaths(include); len(includePaths) > 0 {
+		projected, ok := includeValue(data, includePaths)
+		if !ok {
+			projected = emptyLike(data)
+		}
+		data = projected
+	}
+
+	if excludePaths := parseJSONPaths(exclude); len(excludePaths) > 0 {
+		data = excludeValue(data, excludePaths)
+	}
+
+	return data, nil
+}
+
+// includeValue returns a copy of value containing only the given paths and
+// whether anything matched
+func includeValue(value interface{}, paths [][]string) (interface{}, bool) {
+	if jsonpath.Selected(paths) {
+		return value, true
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		elementPaths := jsonpath.Elements(paths)
+		result := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if projected, ok := includeValue(item, elementPaths); ok {
+				result = append(result, projected)
+			}
+		}
+		return result, len(result) > 0
+
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		for key, child := range v {
+			next := jsonpath.Child(paths, key)
+			if len(next) == 0 {
+				continue
+			}
+			if projected, ok := includeValue(child, next); ok {
+				result[key] = projected
+			}
+		}
+		return result, len(result) > 0
+	}
+
+	// Paths continue below a scalar
+	return nil, false
+}
+
+// excludeValue removes the given paths from value in place
+func excludeValue(value interface{}, paths [][]string) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		elementPaths := jsonpath.Elements(paths)
+		for i, item := range v {
+			v[i] = excludeValue(item, elementPaths)
+		}
+
+	case map[string]interface{}:
+		for key, child := range v {
+			next := jsonpath.Child(paths, key)
+			if jsonpath.Selected(next) {
+				delete(v, key)
+				continue
+			}
+			if len(next) > 0 {
+				v[key] = excludeValue(child, next)
+			}
+		}
+	}
+
+	return value
+}
+
+// emptyLike returns an empty value of the same JSON kind
+func emptyLike(value interface{}) interface{} {
+	if _, ok := value.([]interface{}); ok {
+		return []interface{}{}
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,195 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+)
+
+func TestJSONTransformer_Project(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		operation Operation
+		expected  string
+	}{
+		{
+			name:      "include nested fields",
+			input:     `{"user":{"name":"John","email":"j@example.com","ssn":"123"},"debug":true}`,
+			operation: Operation{Type: "project", Include: []string{"$.user.name", "$.user.email"}},
+			expected:  `{"user":{"email":"j@example.com","name":"John"}}`,
+		},
+		{
+			name:      "include from array of objects",
+			input:     `{"items":[{"id":1,"secret":"a"},{"id":2,"secret":"b"}],"total":2}`,
+			operation: Operation{Type: "project", Include: []string{"$.items[*].id", "$.total"}},
+			expected:  `{"items":[{"id":1},{"id":2}],"total":2}`,
+		},
+		{
+			name:      "arrays are traversed implicitly",
+			input:     `[{"id":1,"name":"a"},{"id":2,"name":"b"}]`,
+			operation: Operation{Type: "project", Include: []string{"$.id"}},
+			expected:  `[{"id":1},{"id":2}]`,
+		},
+		{
+			name:      "include with no match",
+			input:     `{"name":"John"}`,
+			operation: Operation{Type: "project", Include: []string{"$.missing"}},
+			expected:  `{}`,
+		},
+		{
+			name:      "exclude nested field",
+			input:     `{"user":{"name":"John","password":"x"}}`,
+			operation: Operation{Type: "project", Exclude: []string{"$.user.password"}},
+			expected:  `{"user":{"name":"John"}}`,
+		},
+		{
+			name:      "exclude from array of objects",
+			input:     `{"items":[{"id":1,"secret":"a"},{"id":2}]}`,
+			operation: Operation{Type: "project", Exclude: []string{"$.items[*].secret"}},
+			expected:  `{"items":[{"id":1},{"id":2}]}`,
+		},
+		{
+			name:      "exclude at any depth",
+			input:     `{"password":"a","user":{"password":"b","profile":[{"password":"c","bio":"hi"}]}}`,
+			operation: Operation{Type: "project", Exclude: []string{"$..password"}},
+			expected:  `{"user":{"profile":[{"bio":"hi"}]}}`,
+		},
+		{
+			name:  "include then exclude",
+			input: `{"user":{"name":"John","token":"t","role":"admin"},"debug":true}`,
+			operation: Operation{
+				Type:    "project",
+				Include: []string{"$.user"},
+				Exclude: []string{"$.user.token"},
+			},
+			expected: `{"user":{"name":"John","role":"admin"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer := NewJSONTransformer([]Operation{tt.operation}, nil)
+			result, err := transformer.Transform([]byte(tt.input), "application/json")
+			if err != nil {
+				t.Fatalf("Transform failed: %v", err)
+			}
+
+			var expected, actual interface{}
+			json.Unmarshal([]byte(tt.expected), &expected)
+			json.Unmarshal(result, &actual)
+
+			expectedJSON, _ := json.Marshal(expected)
+			actualJSON, _ := json.Marshal(actual)
+			if string(expectedJSON) != string(actualJSON) {
+				t.Errorf("Expected %s, got %s", expectedJSON, actualJSON)
+			}
+		})
+	}
+}
+
+func TestMiddleware_ProjectResponse(t *testing.T) {
+	newHandler := func(contentType, body string, maxSize int64) core.Handler {
+		m := NewMiddleware(&Config{
+			Enabled: true,
+			ResponseTransforms: map[string]TransformConfig{
+				"/users/*": {
+					Body: &BodyConfig{
+						Operations: []Operation{
+							{Type: "project", Exclude: []string{"$..password"}},
+						},
+						MaxSize: maxSize,
+					},
+				},
+			},
+		}, nil)
+
+		return m.Middleware()(func(ctx context.Context, req core.Request) (core.Response, error) {
+			return &mockResponse{
+				statusCode: 200,
+				headers: map[string][]string{
+					"Content-Type":   {contentType},
+					"Content-Length": {strconv.Itoa(len(body))},
+				},
+				body: []byte(body),
+			}, nil
+		})
+	}
+	req := &mockRequest{method: "GET", path: "/users/1", headers: map[string][]string{}}
+
+	// JSON responses are projected
+	resp, err := newHandler("application/json", `{"name":"John","password":"x"}`, 0)(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body())
+	if strings.Contains(string(data), "password") {
+		t.Errorf("Expected password to be removed, got %s", data)
+	}
+	if got := resp.Headers()["Content-Length"]; len(got) != 1 || got[0] != strconv.Itoa(len(data)) {
+		t.Errorf("Expected Content-Length %d of the projected body, got %v", len(data), got)
+	}
+
+	// Non-JSON responses are left untouched
+	resp, err = newHandler("text/plain", "password=x", 0)(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	data, _ = io.ReadAll(resp.Body())
+	if string(data) != "password=x" {
+		t.Errorf("Expected untouched body, got %s", data)
+	}
+
+	// Bodies over the limit are forwarded untransformed
+	resp, err = newHandler("application/json", `{"name":"John","password":"x"}`, 10)(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	data, _ = io.ReadAll(resp.Body())
+	if string(data) != `{"name":"John","password":"x"}` {
+		t.Errorf("Expected untouched body, got %s", data)
+	}
+}
+
+func TestMiddleware_ProjectRequestTooLarge(t *testing.T) {
+	m := NewMiddleware(&Config{
+		Enabled: true,
+		RequestTransforms: map[string]TransformConfig{
+			"/users": {
+				Body: &BodyConfig{
+					Operations: []Operation{
+						{Type: "project", Exclude: []string{"$.admin"}},
+					},
+					MaxSize: 10,
+				},
+			},
+		},
+	}, nil)
+	handler := m.Middleware()(func(ctx context.Context, req core.Request) (core.Response, error) {
+		t.Error("Expected the request to be rejected before the backend")
+		return nil, nil
+	})
+
+	req := &mockRequest{
+		method:  "POST",
+		path:    "/users",
+		headers: map[string][]string{"Content-Type": {"application/json"}},
+		body:    `{"name":"John","admin":true}`,
+	}
+	_, err := handler(context.Background(), req)
+	var gwErr *gwerrors.Error
+	if !errors.As(err, &gwErr) || gwErr.Type != gwerrors.ErrorTypePayloadTooLarge {
+		t.Errorf("Expected payload too large error, got %v", err)
+	}
+	var sizeErr *BodySizeError
+	if !errors.As(err, &sizeErr) || sizeErr.Limit != 10 {
+		t.Errorf("Expected BodySizeError with limit 10, got %v", err)
+	}
+}
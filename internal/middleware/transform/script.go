@@ -0,0 +1,154 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
+)
+
+// scriptTimeout bounds the execution time of a single script
+const scriptTimeout = 100 * time.Millisecond
+
+// scriptCheckInterval is the number of loop iterations between deadline
+// checks of a running script
+const scriptCheckInterval = 256
+
+// ScriptInput holds the request data exposed to transform scripts
+type ScriptInput struct {
+	Headers map[string][]string
+	Params  map[string]string
+	Method  string
+	Path    string
+}
+
+// scriptEnv is the read-only environment scripts are evaluated against
+type scriptEnv struct {
+	Body    interface{}       `expr:"body"`
+	Headers map[string]string `expr:"headers"` // lower-cased names, first value
+	Params  map[string]string `expr:"params"`
+	Method  string            `expr:"method"`
+	Path    string            `expr:"path"`
+	// Checked by every loop iteration, see deadlineVisitor
+	Deadline *scriptDeadline `expr:"__deadline"`
+}
+
+// scriptDeadline stops a script running past its deadline. Expressions
+// only loop in the closures of builtins such as map and all, so checking
+// the deadline in each closure bounds the run time of any script.
+type scriptDeadline struct {
+	at    time.Time
+	steps int
+}
+
+// check fails once the deadline has passed, reading the clock every
+// scriptCheckInterval steps
+func (d *scriptDeadline) check() error {
+	d.steps++
+	if d.steps%scriptCheckInterval == 0 && time.Now().After(d.at) {
+		return fmt.Errorf("timed out after %v", scriptTimeout)
+	}
+	return nil
+}
+
+// deadlineVisitor makes the closures of a script check its deadline before
+// returning their value
+type deadlineVisitor struct{}
+
+func (deadlineVisitor) Visit(node *ast.Node) {
+	if closure, ok := (*node).(*ast.ClosureNode); ok {
+		closure.Node = &ast.CallNode{
+			Callee:    &ast.IdentifierNode{Value: "__step"},
+			Arguments: []ast.Node{&ast.IdentifierNode{Value: "__deadline"}, closure.Node},
+		}
+	}
+}
+
+// scriptStep checks the deadline of a script and returns the value of the
+// closure calling it
+func scriptStep(params ...any) (any, error) {
+	if err := params[0].(*scriptDeadline).check(); err != nil {
+		return nil, err
+	}
+	return params[1], nil
+}
+
+// ScriptError indicates a transform script failed to compile or run
+type ScriptError struct {
+	Script string
+	Err    error
+}
+
+// Error implements the error interface
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("script %q failed: %v", e.Script, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// scriptCache holds compiled programs keyed by script source
+var scriptCache sync.Map // map[string]*vm.Program
+
+// compileScript compiles a script, reusing previously compiled programs
+func compileScript(script string) (*vm.Program, error) {
+	if program, ok := scriptCache.Load(script); ok {
+		return program.(*vm.Program), nil
+	}
+
+	program, err := expr.Compile(script,
+		expr.Env(scriptEnv{}),
+		expr.Function("__step", scriptStep),
+		expr.Patch(deadlineVisitor{}))
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := scriptCache.LoadOrStore(script, program)
+	return actual.(*vm.Program), nil
+}
+
+// newScriptEnv builds the script environment for a body snapshot
+func newScriptEnv(body interface{}, input ScriptInput) scriptEnv {
+	headers := make(map[string]string, len(input.Headers))
+	for name, values := range input.Headers {
+		if len(values) > 0 {
+			headers[strings.ToLower(name)] = values[0]
+		}
+	}
+
+	params := input.Params
+	if params == nil {
+		params = make(map[string]string)
+	}
+
+	return scriptEnv{
+		Body:    body,
+		Headers: headers,
+		Params:  params,
+		Method:  input.Method,
+		Path:    input.Path,
+	}
+}
+
+// runScript evaluates a script against env, failing and stopping it if it
+// runs longer than scriptTimeout
+func runScript(script string, env scriptEnv) (interface{}, error) {
+	program, err := compileScript(script)
+	if err != nil {
+		return nil, &ScriptError{Script: script, Err: err}
+	}
+
+	env.Deadline = &scriptDeadline{at: time.Now().Add(scriptTimeout)}
+	value, err := expr.Run(program, env)
+	if err != nil {
+		return nil, &ScriptError{Script: script, Err: err}
+	}
+	return value, nil
+}
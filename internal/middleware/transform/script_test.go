@@ -0,0 +1,243 @@
+package transform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+)
+
+// mockRequest implements core.Request for middleware tests
+type mockRequest struct {
+	method  string
+	path    string
+	headers map[string][]string
+	body    string
+}
+
+func (m *mockRequest) ID() string                   { return "test-req" }
+func (m *mockRequest) Method() string               { return m.method }
+func (m *mockRequest) Path() string                 { return m.path }
+func (m *mockRequest) URL() string                  { return "http://example.com" + m.path }
+func (m *mockRequest) RemoteAddr() string           { return "127.0.0.1:12345" }
+func (m *mockRequest) Headers() map[string][]string { return m.headers }
+func (m *mockRequest) Body() io.ReadCloser          { return io.NopCloser(strings.NewReader(m.body)) }
+func (m *mockRequest) Context() context.Context     { return context.Background() }
+
+// mockResponse implements core.Response for middleware tests
+type mockResponse struct {
+	statusCode int
+	headers    map[string][]string
+	body       []byte
+}
+
+func (m *mockResponse) StatusCode() int              { return m.statusCode }
+func (m *mockResponse) Headers() map[string][]string { return m.headers }
+func (m *mockResponse) Body() io.ReadCloser          { return io.NopCloser(bytes.NewReader(m.body)) }
+
+func TestJSONTransformer_Script(t *testing.T) {
+	input := ScriptInput{
+		Headers: map[string][]string{"X-User-Id": {"u-42"}},
+		Params:  map[string]string{"id": "7"},
+		Method:  "POST",
+		Path:    "/orders/7",
+	}
+
+	tests := []struct {
+		name       string
+		input      string
+		operations []Operation
+		expected   string
+	}{
+		{
+			name:  "set computed value",
+			input: `{"price":10,"quantity":3}`,
+			operations: []Operation{
+				{Type: "script", Path: "total", Script: "body.price * body.quantity"},
+			},
+			expected: `{"price":10,"quantity":3,"total":30}`,
+		},
+		{
+			name:  "delete with nil result",
+			input: `{"name":"John","debug":true}`,
+			operations: []Operation{
+				{Type: "script", Path: "debug", Script: "nil"},
+			},
+			expected: `{"name":"John"}`,
+		},
+		{
+			name:  "copy nested value",
+			input: `{"user":{"name":"John"}}`,
+			operations: []Operation{
+				{Type: "script", Path: "meta.owner", Script: "body.user.name"},
+			},
+			expected: `{"meta":{"owner":"John"},"user":{"name":"John"}}`,
+		},
+		{
+			name:  "headers and params",
+			input: `{}`,
+			operations: []Operation{
+				{Type: "script", Path: "userId", Script: `headers["x-user-id"]`},
+				{Type: "script", Path: "orderId", Script: "params.id"},
+				{Type: "script", Path: "source", Script: `method + " " + path`},
+			},
+			expected: `{"orderId":"7","source":"POST /orders/7","userId":"u-42"}`,
+		},
+		{
+			name:  "scripts read the original body",
+			input: `{"count":1}`,
+			operations: []Operation{
+				{Type: "modify", Path: "count", Value: 5},
+				{Type: "script", Path: "original", Script: "body.count"},
+			},
+			expected: `{"count":5,"original":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer := NewJSONTransformer(tt.operations, nil).WithScriptInput(input)
+			result, err := transformer.Transform([]byte(tt.input), "application/json")
+			if err != nil {
+				t.Fatalf("Transform failed: %v", err)
+			}
+
+			var expected, actual interface{}
+			json.Unmarshal([]byte(tt.expected), &expected)
+			json.Unmarshal(result, &actual)
+
+			expectedJSON, _ := json.Marshal(expected)
+			actualJSON, _ := json.Marshal(actual)
+			if string(expectedJSON) != string(actualJSON) {
+				t.Errorf("Expected %s, got %s", expectedJSON, actualJSON)
+			}
+		})
+	}
+}
+
+func TestJSONTransformer_ScriptErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{"syntax error", "body.price *"},
+		{"unknown variable", "secrets"},
+		{"runtime error", `body.name + 1`},
+		{"resource limit", "len(filter(1..100000000, # > 0))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer := NewJSONTransformer([]Operation{
+				{Type: "script", Path: "result", Script: tt.script},
+			}, nil)
+
+			_, err := transformer.Transform([]byte(`{"name":"John","price":1}`), "application/json")
+			var scriptErr *ScriptError
+			if !errors.As(err, &scriptErr) {
+				t.Fatalf("Expected ScriptError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestJSONTransformer_ScriptTimeout(t *testing.T) {
+	items := make([]string, 1000)
+	for i := range items {
+		items[i] = "1"
+	}
+	body := `{"items":[` + strings.Join(items, ",") + `]}`
+
+	// A billion iterations without allocating, so only the deadline stops it
+	transformer := NewJSONTransformer([]Operation{
+		{Type: "script", Path: "result", Script: "all(body.items, {all(body.items, {all(body.items, {# > 0})})})"},
+	}, nil)
+
+	start := time.Now()
+	_, err := transformer.Transform([]byte(body), "application/json")
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Expected a timed out ScriptError, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*scriptTimeout {
+		t.Errorf("Expected the script to stop at its deadline, ran for %v", elapsed)
+	}
+}
+
+func TestMiddleware_ScriptFailureRejectsRequest(t *testing.T) {
+	m := NewMiddleware(&Config{
+		Enabled: true,
+		RequestTransforms: map[string]TransformConfig{
+			"/orders/:id": {
+				Body: &BodyConfig{
+					Operations: []Operation{
+						{Type: "script", Path: "id", Script: "int(params.id)"},
+					},
+				},
+			},
+		},
+	}, nil)
+
+	var forwarded map[string]interface{}
+	handler := m.Middleware()(func(ctx context.Context, req core.Request) (core.Response, error) {
+		json.NewDecoder(req.Body()).Decode(&forwarded)
+		return &mockResponse{statusCode: 200}, nil
+	})
+
+	headers := map[string][]string{"Content-Type": {"application/json"}}
+
+	// Valid path parameter is converted by the script
+	_, err := handler(context.Background(), &mockRequest{
+		method: "POST", path: "/orders/12", headers: headers, body: `{}`,
+	})
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if forwarded["id"] != float64(12) {
+		t.Errorf("Expected id 12 from path params, got %v", forwarded["id"])
+	}
+
+	// Invalid parameter fails the script and rejects the request
+	_, err = handler(context.Background(), &mockRequest{
+		method: "POST", path: "/orders/abc", headers: headers, body: `{}`,
+	})
+	var gwErr *gwerrors.Error
+	if !errors.As(err, &gwErr) || gwErr.Type != gwerrors.ErrorTypeBadRequest {
+		t.Errorf("Expected bad request error, got %v", err)
+	}
+}
+
+func TestMatchParams(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		params  map[string]string
+		ok      bool
+	}{
+		{"/users/:id", "/users/42", map[string]string{"id": "42"}, true},
+		{"/users/:id/posts/:post", "/users/1/posts/2", map[string]string{"id": "1", "post": "2"}, true},
+		{"/users/:id/*", "/users/1/anything/else", map[string]string{"id": "1"}, true},
+		{"/users/:id", "/users/1/extra", nil, false},
+		{"/users/:id", "/orders/1", nil, false},
+	}
+
+	for _, tt := range tests {
+		params, ok := matchParams(tt.pattern, tt.path)
+		if ok != tt.ok {
+			t.Errorf("matchParams(%s, %s) ok = %v, want %v", tt.pattern, tt.path, ok, tt.ok)
+			continue
+		}
+		for k, v := range tt.params {
+			if params[k] != v {
+				t.Errorf("matchParams(%s, %s)[%s] = %s, want %s", tt.pattern, tt.path, k, params[k], v)
+			}
+		}
+	}
+}
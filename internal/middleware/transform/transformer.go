@@ -3,6 +3,7 @@ package transform
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -18,19 +19,22 @@ type Transformer interface {
 
 // JSONTransformer transforms JSON data
 type JSONTransformer struct {
-	operations []Operation
-	logger     *slog.Logger
+	operations  []Operation
+	scriptInput ScriptInput
+	logger      *slog.Logger
 }
 
 // Operation represents a transformation operation
 type Operation struct {
-	Type   string                 `yaml:"type"` // add, remove, rename, modify, filter
-	Path   string                 `yaml:"path"` // JSON path
-	Value  interface{}            `yaml:"value,omitempty"`
-	From   string                 `yaml:"from,omitempty"`  // For rename
-	To     string                 `yaml:"to,omitempty"`    // For rename
-	Filter func(interface{}) bool `yaml:"-"`               // For filter operations
-	Script string                 `yaml:"script,omitempty"` // For script-based transforms
+	Type    string                 `yaml:"type"` // add, remove, rename, modify, filter, script, project
+	Path    string                 `yaml:"path"` // JSON path
+	Value   interface{}            `yaml:"value,omitempty"`
+	From    string                 `yaml:"from,omitempty"`    // For rename
+	To      string                 `yaml:"to,omitempty"`      // For rename
+	Filter  func(interface{}) bool `yaml:"-"`                 // For filter operations
+	Script  string                 `yaml:"script,omitempty"`  // Expression whose result is written to Path, nil deletes it
+	Include []string               `yaml:"include,omitempty"` // For project, JSONPath expressions to keep
+	Exclude []string               `yaml:"exclude,omitempty"` // For project, JSONPath expressions to drop
 }
 
 // NewJSONTransformer creates a new JSON transformer
@@ -45,6 +49,12 @@ func NewJSONTransformer(operations []Operation, logger *slog.Logger) *JSONTransf
 	}
 }
 
+// WithScriptInput sets the request data available to script operations
+func (t *JSONTransformer) WithScriptInput(input ScriptInput) *JSONTransformer {
+	t.scriptInput = input
+	return t
+}
+
 // Transform applies transformations to JSON data
 func (t *JSONTransformer) Transform(data []byte, contentType string) ([]byte, error) {
 	if !strings.Contains(contentType, "json") {
@@ -57,9 +67,28 @@ func (t *JSONTransformer) Transform(data []byte, contentType string) ([]byte, er
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
+	// Scripts see a separate copy of the original body so they cannot
+	// observe or cause mutations
+	var scriptBody interface{}
+	for _, op := range t.operations {
+		if op.Type == "script" {
+			json.Unmarshal(data, &scriptBody)
+			break
+		}
+	}
+
 	// Apply operations
 	for _, op := range t.operations {
 		var err error
+		if op.Type == "script" {
+			// Script failures fail the whole transform
+			jsonData, err = t.applyScript(jsonData, scriptBody, op)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		jsonData, err = t.applyOperation(jsonData, op)
 		if err != nil {
 			t.logger.Error("Failed to apply operation",
@@ -98,11 +127,26 @@ func (t *JSONTransformer) applyOperation(data interface{}, op Operation) (interf
 		return t.modifyField(data, op.Path, op.Value)
 	case "filter":
 		return t.filterField(data, op.Path, op.Filter)
+	case "project":
+		return t.projectFields(data, op.Include, op.Exclude)
 	default:
 		return data, fmt.Errorf("unknown operation type: %s", op.Type)
 	}
 }
 
+// applyScript writes the script result to the operation path, removing it on nil
+func (t *JSONTransformer) applyScript(data, scriptBody interface{}, op Operation) (interface{}, error) {
+	value, err := runScript(op.Script, newScriptEnv(scriptBody, t.scriptInput))
+	if err != nil {
+		return data, err
+	}
+
+	if value == nil {
+		return t.removeField(data, op.Path)
+	}
+	return t.addField(data, op.Path, value)
+}
+
 // addField adds a field at the specified path
 func (t *JSONTransformer) addField(data interface{}, path string, value interface{}) (interface{}, error) {
 	parts := strings.Split(path, ".")
@@ -351,6 +395,19 @@ type HeaderConfig struct {
 	Modify map[string]string `yaml:"modify"` // Header -> regex pattern
 }
 
+// BodySizeError indicates a body exceeded the transform size limit
+type BodySizeError struct {
+	Limit int64
+
+	// body replays the untouched body, including the bytes already read
+	body io.ReadCloser
+}
+
+// Error implements the error interface
+func (e *BodySizeError) Error() string {
+	return fmt.Sprintf("body exceeds transform size limit of %d bytes", e.Limit)
+}
+
 // BodyTransformer wraps a ReadCloser with transformation
 type BodyTransformer struct {
 	original    io.ReadCloser
@@ -360,15 +417,38 @@ type BodyTransformer struct {
 
 // NewBodyTransformer creates a new body transformer
 func NewBodyTransformer(body io.ReadCloser, transformer Transformer, contentType string) (*BodyTransformer, error) {
+	return NewLimitedBodyTransformer(body, transformer, contentType, 0)
+}
+
+// NewLimitedBodyTransformer creates a body transformer that buffers at most
+// maxSize bytes, zero means unlimited. Larger bodies fail with a BodySizeError
+// since operations such as projection cannot be applied safely to part of them,
+// the body is then left open for the error to hand back unchanged.
+func NewLimitedBodyTransformer(body io.ReadCloser, transformer Transformer, contentType string, maxSize int64) (*BodyTransformer, error) {
 	// Read original body
-	data, err := io.ReadAll(body)
+	reader := io.Reader(body)
+	if maxSize > 0 {
+		reader = io.LimitReader(body, maxSize+1)
+	}
+	data, err := io.ReadAll(reader)
+	if maxSize > 0 && err == nil && int64(len(data)) > maxSize {
+		return nil, &BodySizeError{Limit: maxSize, body: &replayBody{
+			Reader: io.MultiReader(bytes.NewReader(data), body),
+			Closer: body,
+		}}
+	}
+	body.Close()
 	if err != nil {
 		return nil, err
 	}
-	body.Close()
 
 	// Transform data
 	transformed, err := transformer.Transform(data, contentType)
+	var scriptErr *ScriptError
+	var formatErr *FormatError
+	if errors.As(err, &scriptErr) || errors.As(err, &formatErr) {
+		return nil, err
+	}
 	if err != nil {
 		// Return original on error
 		return &BodyTransformer{
@@ -385,6 +465,17 @@ func NewBodyTransformer(body io.ReadCloser, transformer Transformer, contentType
 	}, nil
 }
 
+// replayBody reads a body from the start after part of it was consumed
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// Len returns the size of the transformed body
+func (t *BodyTransformer) Len() int {
+	return t.buffer.Len()
+}
+
 // Read implements io.Reader
 func (t *BodyTransformer) Read(p []byte) (n int, err error) {
 	return t.transformed.Read(p)
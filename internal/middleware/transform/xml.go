@@ -0,0 +1,336 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// xmlAttrPrefix marks JSON keys that map to XML attributes
+	xmlAttrPrefix = "@"
+	// xmlTextKey holds element text when the element also has attributes or children
+	xmlTextKey = "#text"
+	// xmlDefaultRoot wraps JSON documents that do not have a single root key
+	xmlDefaultRoot = "response"
+)
+
+// FormatError indicates a body could not be converted between formats
+type FormatError struct {
+	Format string
+	Err    error
+}
+
+// Error implements the error interface
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("%s conversion failed: %v", e.Format, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// XMLTransformer converts XML bodies to JSON and applies JSON operations
+type XMLTransformer struct {
+	json *JSONTransformer
+}
+
+// NewXMLTransformer creates a transformer producing JSON from XML input
+func NewXMLTransformer(json *JSONTransformer) *XMLTransformer {
+	return &XMLTransformer{json: json}
+}
+
+// Transform converts XML data to JSON before applying operations
+func (t *XMLTransformer) Transform(data []byte, contentType string) ([]byte, error) {
+	if !isXMLContentType(contentType) {
+		return t.json.Transform(data, contentType)
+	}
+
+	converted, err := xmlToJSON(data)
+	if err != nil {
+		return nil, &FormatError{Format: "xml", Err: err}
+	}
+	return t.json.Transform(converted, "application/json")
+}
+
+// GetContentType returns the content type this transformer produces
+func (t *XMLTransformer) GetContentType() string {
+	return "application/json"
+}
+
+// JSONToXMLTransformer applies JSON operations and renders the result as XML
+type JSONToXMLTransformer struct {
+	json *JSONTransformer
+}
+
+// NewJSONToXMLTransformer creates a transformer producing XML from JSON input
+func NewJSONToXMLTransformer(json *JSONTransformer) *JSONToXMLTransformer {
+	return &JSONToXMLTransformer{json: json}
+}
+
+// Transform applies operations to JSON data and converts the result to XML
+func (t *JSONToXMLTransformer) Transform(data []byte, contentType string) ([]byte, error) {
+	transformed, err := t.json.Transform(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := jsonToXML(transformed)
+	if err != nil {
+		return nil, &FormatError{Format: "xml", Err: err}
+	}
+	return converted, nil
+}
+
+// GetContentType returns the content type this transformer produces
+func (t *JSONToXMLTransformer) GetContentType() string {
+	return "application/xml"
+}
+
+// isXMLContentType checks for XML media types such as application/xml,
+// text/xml or application/soap+xml
+func isXMLContentType(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "xml")
+}
+
+// xmlNode is an element being decoded
+type xmlNode struct {
+	name     string
+	value    map[string]interface{}
+	text     strings.Builder
+	children bool
+}
+
+// xmlToJSON converts an XML document to JSON. Attributes become "@name" keys,
+// repeated child elements become arrays, and elements with only text become
+// strings. The root element name is kept as the single top-level key.
+func xmlToJSON(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var stack []*xmlNode
+	var root map[string]interface{}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		switch tok := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{
+				name:  tok.Name.Local,
+				value: make(map[string]interface{}),
+			}
+			for _, attr := range tok.Attr {
+				// Namespace declarations carry no data
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				node.value[xmlAttrPrefix+attr.Name.Local] = attr.Value
+			}
+			if len(stack) > 0 {
+				stack[len(stack)-1].children = true
+			}
+			stack = append(stack, node)
+
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(tok)
+			}
+
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("failed to parse XML: unexpected end element %s", tok.Name.Local)
+			}
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			value := node.result()
+			if len(stack) == 0 {
+				root = map[string]interface{}{node.name: value}
+				continue
+			}
+			appendChild(stack[len(stack)-1].value, node.name, value)
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("failed to parse XML: no root element")
+	}
+
+	return json.Marshal(root)
+}
+
+// result returns the JSON value for a decoded element
+func (n *xmlNode) result() interface{} {
+	text := strings.TrimSpace(n.text.String())
+	if len(n.value) == 0 && !n.children {
+		return text
+	}
+	if text != "" {
+		n.value[xmlTextKey] = text
+	}
+	return n.value
+}
+
+// appendChild adds a child value, turning repeated names into arrays
+func appendChild(parent map[string]interface{}, name string, value interface{}) {
+	existing, ok := parent[name]
+	if !ok {
+		parent[name] = value
+		return
+	}
+	if arr, ok := existing.([]interface{}); ok {
+		parent[name] = append(arr, value)
+		return
+	}
+	parent[name] = []interface{}{existing, value}
+}
+
+// jsonToXML converts a JSON document to XML, reversing the xmlToJSON mapping.
+// A document with a single top-level object key uses it as the root element.
+func jsonToXML(data []byte) ([]byte, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	rootName := xmlDefaultRoot
+	if arr, ok := value.([]interface{}); ok {
+		// A document needs a single root, so top-level arrays become items
+		value = map[string]interface{}{"item": arr}
+	} else if obj, ok := value.(map[string]interface{}); ok && len(obj) == 1 {
+		for name, child := range obj {
+			if _, isArray := child.([]interface{}); !isArray && isXMLName(name) {
+				rootName = name
+				value = child
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	if err := encodeXMLElement(encoder, rootName, value); err != nil {
+		return nil, fmt.Errorf("failed to encode XML: %w", err)
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to encode XML: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeXMLElement writes a JSON value as an element named name
+func encodeXMLElement(encoder *xml.Encoder, name string, value interface{}) error {
+	if !isXMLName(name) {
+		name = "item"
+	}
+
+	// Arrays repeat the element
+	if arr, ok := value.([]interface{}); ok {
+		for _, item := range arr {
+			if err := encodeXMLElement(encoder, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		if value != nil {
+			if err := encoder.EncodeToken(xml.CharData(scalarString(value))); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	}
+
+	// Sort keys for deterministic output
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if strings.HasPrefix(key, xmlAttrPrefix) {
+			start.Attr = append(start.Attr, xml.Attr{
+				Name:  xml.Name{Local: strings.TrimPrefix(key, xmlAttrPrefix)},
+				Value: scalarString(obj[key]),
+			})
+		}
+	}
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	if text, ok := obj[xmlTextKey]; ok {
+		if err := encoder.EncodeToken(xml.CharData(scalarString(text))); err != nil {
+			return err
+		}
+	}
+	for _, key := range keys {
+		if strings.HasPrefix(key, xmlAttrPrefix) || key == xmlTextKey {
+			continue
+		}
+		if err := encodeXMLElement(encoder, key, obj[key]); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+// scalarString formats a JSON scalar as XML text
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		// Nested structures in attributes or text are rendered as JSON
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// isXMLName checks whether a JSON key can be used as an element name
+func isXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || r == ':' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+		case i > 0 && (r == '-' || r == '.' || (r >= '0' && r <= '9')):
+		default:
+			return false
+		}
+	}
+	return !strings.HasPrefix(strings.ToLower(name), "xml")
+}
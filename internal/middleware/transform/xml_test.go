@@ -0,0 +1,208 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+)
+
+func TestXMLToJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "simple elements",
+			input:    `<user><name>John</name><age>30</age></user>`,
+			expected: `{"user":{"age":"30","name":"John"}}`,
+		},
+		{
+			name:     "attributes and text",
+			input:    `<price currency="EUR">9.99</price>`,
+			expected: `{"price":{"#text":"9.99","@currency":"EUR"}}`,
+		},
+		{
+			name:     "repeated elements become arrays",
+			input:    `<order><item>a</item><item>b</item><item>c</item></order>`,
+			expected: `{"order":{"item":["a","b","c"]}}`,
+		},
+		{
+			name: "namespaced envelope",
+			input: `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body><GetUser id="7"/></soap:Body>
+</soap:Envelope>`,
+			expected: `{"Envelope":{"Body":{"GetUser":{"@id":"7"}}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := xmlToJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("xmlToJSON failed: %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := xmlToJSON([]byte(`<user><name>John</user>`)); err == nil {
+		t.Error("Expected error for malformed XML")
+	}
+}
+
+func TestJSONToXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "single root key",
+			input:    `{"user":{"name":"John","age":30}}`,
+			expected: `<user><age>30</age><name>John</name></user>`,
+		},
+		{
+			name:     "attributes and text",
+			input:    `{"price":{"@currency":"EUR","#text":"9.99"}}`,
+			expected: `<price currency="EUR">9.99</price>`,
+		},
+		{
+			name:     "arrays repeat elements",
+			input:    `{"order":{"item":["a","b"]}}`,
+			expected: `<order><item>a</item><item>b</item></order>`,
+		},
+		{
+			name:     "default root",
+			input:    `{"id":1,"active":true}`,
+			expected: `<response><active>true</active><id>1</id></response>`,
+		},
+		{
+			name:     "top-level array",
+			input:    `[1,2]`,
+			expected: `<response><item>1</item><item>2</item></response>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := jsonToXML([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("jsonToXML failed: %v", err)
+			}
+			body := strings.TrimPrefix(string(result), "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+			if body != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, body)
+			}
+		})
+	}
+}
+
+func TestMiddleware_XMLFormat(t *testing.T) {
+	m := NewMiddleware(&Config{
+		Enabled: true,
+		RequestTransforms: map[string]TransformConfig{
+			"/legacy/*": {Body: &BodyConfig{Format: "xml"}},
+		},
+		ResponseTransforms: map[string]TransformConfig{
+			"/legacy/*": {Body: &BodyConfig{Format: "xml"}},
+		},
+	}, nil)
+
+	var forwardedType string
+	var forwarded map[string]interface{}
+	handler := m.Middleware()(func(ctx context.Context, req core.Request) (core.Response, error) {
+		forwardedType = getContentType(req.Headers())
+		json.NewDecoder(req.Body()).Decode(&forwarded)
+		return &mockResponse{
+			statusCode: 200,
+			headers:    map[string][]string{"Content-Type": {"application/json"}},
+			body:       []byte(`{"order":{"id":"1","status":"created"}}`),
+		}, nil
+	})
+
+	resp, err := handler(context.Background(), &mockRequest{
+		method: "POST",
+		path:   "/legacy/orders",
+		headers: map[string][]string{
+			"Content-Type": {"application/xml"},
+			"Accept":       {"application/xml"},
+		},
+		body: `<order><sku>abc</sku></order>`,
+	})
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+
+	// Request was converted to JSON
+	if forwardedType != "application/json" {
+		t.Errorf("Expected forwarded content type application/json, got %s", forwardedType)
+	}
+	if order, ok := forwarded["order"].(map[string]interface{}); !ok || order["sku"] != "abc" {
+		t.Errorf("Expected converted order body, got %v", forwarded)
+	}
+
+	// Response was converted to XML for the client
+	if ct := getContentType(resp.Headers()); ct != "application/xml" {
+		t.Errorf("Expected response content type application/xml, got %s", ct)
+	}
+	body, _ := io.ReadAll(resp.Body())
+	if !strings.Contains(string(body), `<order><id>1</id><status>created</status></order>`) {
+		t.Errorf("Unexpected XML response: %s", body)
+	}
+
+	// Malformed XML is rejected
+	_, err = handler(context.Background(), &mockRequest{
+		method:  "POST",
+		path:    "/legacy/orders",
+		headers: map[string][]string{"Content-Type": {"application/xml"}},
+		body:    `<order>`,
+	})
+	var gwErr *gwerrors.Error
+	if !errors.As(err, &gwErr) || gwErr.Type != gwerrors.ErrorTypeBadRequest {
+		t.Errorf("Expected bad request for malformed XML, got %v", err)
+	}
+}
+
+func TestMiddleware_XMLBackendToJSONClient(t *testing.T) {
+	m := NewMiddleware(&Config{
+		Enabled: true,
+		ResponseTransforms: map[string]TransformConfig{
+			"/soap/*": {Body: &BodyConfig{Format: "xml"}},
+		},
+	}, nil)
+
+	handler := m.Middleware()(func(ctx context.Context, req core.Request) (core.Response, error) {
+		return &mockResponse{
+			statusCode: 200,
+			headers:    map[string][]string{"Content-Type": {"text/xml; charset=utf-8"}},
+			body:       []byte(`<user id="7"><name>John</name></user>`),
+		}, nil
+	})
+
+	resp, err := handler(context.Background(), &mockRequest{
+		method:  "GET",
+		path:    "/soap/users",
+		headers: map[string][]string{"Accept": {"application/json"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+
+	if ct := getContentType(resp.Headers()); ct != "application/json" {
+		t.Errorf("Expected response content type application/json, got %s", ct)
+	}
+	body, _ := io.ReadAll(resp.Body())
+	if string(body) != `{"user":{"@id":"7","name":"John"}}` {
+		t.Errorf("Unexpected JSON response: %s", body)
+	}
+}
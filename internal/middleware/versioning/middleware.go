@@ -2,6 +2,8 @@ package versioning
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"regexp"
@@ -12,10 +14,45 @@ import (
 	"gateway/internal/core"
 )
 
+// Default version sources
+const (
+	defaultVersionHeader = "X-API-Version"
+	defaultVersionQuery  = "version"
+	defaultAcceptPattern = `version=(\d+(?:\.\d+)?)`
+)
+
+// pathVersionPattern matches versions in paths like /v1/, /v2.0/
+var pathVersionPattern = regexp.MustCompile(`^/v(\d+(?:\.\d+)?)/`)
+
+// ErrUnsupportedVersion is returned by Resolve for versions that are not
+// declared in strict mode
+var ErrUnsupportedVersion = errors.New("unsupported API version")
+
+// Resolution is the API version of a request and how its version mapping
+// changes the request
+type Resolution struct {
+	Version    string `json:"version"`
+	Service    string `json:"service,omitempty"` // Service override of the mapping
+	Path       string `json:"path"`              // Path after the mapping's prefix
+	Deprecated bool   `json:"deprecated,omitempty"`
+}
+
+// WithContext stores the version and service override of res in ctx for
+// the router
+func (res Resolution) WithContext(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, "api.version", res.Version)
+	if res.Service != "" {
+		ctx = context.WithValue(ctx, "version.service", res.Service)
+	}
+	return ctx
+}
+
 // VersioningMiddleware handles API versioning
 type VersioningMiddleware struct {
-	config *config.VersioningConfig
-	logger *slog.Logger
+	config        *config.VersioningConfig
+	logger        *slog.Logger
+	acceptPattern *regexp.Regexp
+	known         map[string]bool
 }
 
 // NewVersioningMiddleware creates a new versioning middleware
@@ -27,11 +64,44 @@ func NewVersioningMiddleware(cfg *config.VersioningConfig, logger *slog.Logger)
 			DefaultVersion: "1.0",
 		}
 	}
+	logger = logger.With("component", "versioning")
+
+	acceptPattern := regexp.MustCompile(defaultAcceptPattern)
+	if cfg.AcceptPattern != "" {
+		if pattern, err := regexp.Compile(cfg.AcceptPattern); err == nil {
+			acceptPattern = pattern
+		} else {
+			logger.Error("Invalid accept pattern, using default", "pattern", cfg.AcceptPattern, "error", err)
+		}
+	}
 
 	return &VersioningMiddleware{
-		config: cfg,
-		logger: logger.With("component", "versioning"),
+		config:        cfg,
+		logger:        logger,
+		acceptPattern: acceptPattern,
+		known:         knownVersions(cfg),
+	}
+}
+
+// knownVersions returns the versions declared in the configuration. It
+// returns nil when only the default version is declared, in which case
+// every version is accepted.
+func knownVersions(cfg *config.VersioningConfig) map[string]bool {
+	if len(cfg.SupportedVersions) == 0 && len(cfg.VersionMappings) == 0 && len(cfg.DeprecatedVersions) == 0 {
+		return nil
 	}
+
+	known := map[string]bool{cfg.DefaultVersion: true}
+	for _, version := range cfg.SupportedVersions {
+		known[version] = true
+	}
+	for version := range cfg.VersionMappings {
+		known[version] = true
+	}
+	for version := range cfg.DeprecatedVersions {
+		known[version] = true
+	}
+	return known
 }
 
 // Middleware returns the HTTP handler middleware
@@ -41,51 +111,76 @@ func (m *VersioningMiddleware) Middleware(next http.Handler) http.Handler {
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Extract version based on strategy
-		version := m.extractVersion(r)
-		if version == "" {
-			version = m.config.DefaultVersion
+		res, err := m.Resolve(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Unsupported API version %s", res.Version), http.StatusBadRequest)
+			return
 		}
+		version := res.Version
 
 		// Check if version is deprecated
 		if deprecation, exists := m.config.DeprecatedVersions[version]; exists {
 			m.addDeprecationHeaders(w, version, deprecation)
+		} else if res.Deprecated {
+			m.addDeprecationHeaders(w, version, &config.DeprecationInfo{})
 		}
 
-		// Store version in context for router to use
-		ctx := r.Context()
-		ctx = context.WithValue(ctx, "api.version", version)
-		
-		// Apply version mapping if exists
-		if mapping, exists := m.config.VersionMappings[version]; exists {
-			// Store service override in context
-			if mapping.Service != "" {
-				ctx = context.WithValue(ctx, "version.service", mapping.Service)
-			}
-			
-			// Apply path prefix if configured
-			if mapping.PathPrefix != "" && !strings.HasPrefix(r.URL.Path, mapping.PathPrefix) {
-				r.URL.Path = mapping.PathPrefix + r.URL.Path
-			}
-		}
+		// Apply the path prefix of the version mapping
+		r.URL.Path = res.Path
 
 		// Add version header to response
 		w.Header().Set("X-API-Version", version)
 
-		// Continue with modified request
-		next.ServeHTTP(w, r.WithContext(ctx))
+		// Continue with modified request, the version stored for the router
+		next.ServeHTTP(w, r.WithContext(res.WithContext(r.Context())))
 	})
 }
 
+// Resolve returns the version of r and what its version mapping changes,
+// without modifying r. It fails with ErrUnsupportedVersion for versions
+// that are not declared in strict mode.
+func (m *VersioningMiddleware) Resolve(r *http.Request) (Resolution, error) {
+	// Extract version based on strategy
+	version := m.extractVersion(r)
+	if version == "" {
+		version = m.config.DefaultVersion
+	} else if m.known != nil && !m.known[version] {
+		if m.config.Strict {
+			return Resolution{Version: version}, ErrUnsupportedVersion
+		}
+		m.logger.Debug("Unknown API version, using default", "version", version, "default", m.config.DefaultVersion)
+		version = m.config.DefaultVersion
+	}
+
+	res := Resolution{Version: version, Path: r.URL.Path}
+	_, res.Deprecated = m.config.DeprecatedVersions[version]
+	if mapping, exists := m.config.VersionMappings[version]; exists {
+		res.Service = mapping.Service
+		res.Deprecated = res.Deprecated || mapping.Deprecated
+		if mapping.PathPrefix != "" && !strings.HasPrefix(r.URL.Path, mapping.PathPrefix) {
+			res.Path = mapping.PathPrefix + r.URL.Path
+		}
+	}
+	return res, nil
+}
+
 // extractVersion extracts the API version from the request based on strategy
 func (m *VersioningMiddleware) extractVersion(r *http.Request) string {
 	switch m.config.Strategy {
 	case "path":
 		return m.extractVersionFromPath(r.URL.Path)
 	case "header":
-		return r.Header.Get(m.config.VersionHeader)
+		header := m.config.VersionHeader
+		if header == "" {
+			header = defaultVersionHeader
+		}
+		return r.Header.Get(header)
 	case "query":
-		return r.URL.Query().Get(m.config.VersionQuery)
+		query := m.config.VersionQuery
+		if query == "" {
+			query = defaultVersionQuery
+		}
+		return r.URL.Query().Get(query)
 	case "accept":
 		return m.extractVersionFromAccept(r.Header.Get("Accept"))
 	default:
@@ -95,9 +190,7 @@ func (m *VersioningMiddleware) extractVersion(r *http.Request) string {
 
 // extractVersionFromPath extracts version from URL path (e.g., /v2/users)
 func (m *VersioningMiddleware) extractVersionFromPath(path string) string {
-	// Match patterns like /v1/, /v2.0/, etc.
-	re := regexp.MustCompile(`^/v(\d+(?:\.\d+)?)/`)
-	matches := re.FindStringSubmatch(path)
+	matches := pathVersionPattern.FindStringSubmatch(path)
 	if len(matches) > 1 {
 		return matches[1]
 	}
@@ -106,13 +199,7 @@ func (m *VersioningMiddleware) extractVersionFromPath(path string) string {
 
 // extractVersionFromAccept extracts version from Accept header
 func (m *VersioningMiddleware) extractVersionFromAccept(accept string) string {
-	if m.config.AcceptPattern == "" {
-		// Default pattern for version in Accept header
-		m.config.AcceptPattern = `version=(\d+(?:\.\d+)?)`
-	}
-	
-	re := regexp.MustCompile(m.config.AcceptPattern)
-	matches := re.FindStringSubmatch(accept)
+	matches := m.acceptPattern.FindStringSubmatch(accept)
 	if len(matches) > 1 {
 		return matches[1]
 	}
@@ -121,6 +208,7 @@ func (m *VersioningMiddleware) extractVersionFromAccept(accept string) string {
 
 // addDeprecationHeaders adds deprecation headers to the response
 func (m *VersioningMiddleware) addDeprecationHeaders(w http.ResponseWriter, version string, deprecation *config.DeprecationInfo) {
+	w.Header().Set("Deprecation", "true")
 	w.Header().Set("X-API-Deprecated", "true")
 	
 	if deprecation.Message != "" {
@@ -128,9 +216,9 @@ func (m *VersioningMiddleware) addDeprecationHeaders(w http.ResponseWriter, vers
 	}
 	
 	if deprecation.SunsetDate != "" {
-		// Parse and format sunset date
+		// Parse and format sunset date as an HTTP date
 		if sunsetTime, err := time.Parse(time.RFC3339, deprecation.SunsetDate); err == nil {
-			w.Header().Set("Sunset", sunsetTime.Format(time.RFC1123))
+			w.Header().Set("Sunset", sunsetTime.UTC().Format(http.TimeFormat))
 		}
 	}
 }
@@ -103,7 +103,8 @@ func TestVersioningMiddleware(t *testing.T) {
 				"X-API-Version":               "1.0",
 				"X-API-Deprecated":            "true",
 				"X-API-Deprecation-Message":   "Version 1.0 is deprecated",
-				"Sunset":                      "Sun, 01 Jun 2025 00:00:00 UTC",
+				"Sunset":                      "Sun, 01 Jun 2025 00:00:00 GMT",
+				"Deprecation":                 "true",
 			},
 		},
 	}
@@ -212,4 +213,114 @@ func TestDisabledVersioning(t *testing.T) {
 	if rec.Header().Get("X-API-Version") != "" {
 		t.Error("Version header should not be added when versioning is disabled")
 	}
-}
\ No newline at end of file
+}
+func TestUnknownVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		strict          bool
+		version         string
+		expectedStatus  int
+		expectedVersion string
+	}{
+		{name: "known version", version: "2", expectedStatus: http.StatusOK, expectedVersion: "2"},
+		{name: "unknown version falls back", version: "9", expectedStatus: http.StatusOK, expectedVersion: "1"},
+		{name: "unknown version rejected", strict: true, version: "9", expectedStatus: http.StatusBadRequest},
+		{name: "missing version uses default", strict: true, expectedStatus: http.StatusOK, expectedVersion: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware := NewVersioningMiddleware(&config.VersioningConfig{
+				Enabled:           true,
+				Strategy:          "header",
+				DefaultVersion:    "1",
+				SupportedVersions: []string{"2"},
+				Strict:            tt.strict,
+			}, slog.Default())
+
+			var capturedVersion string
+			wrapped := middleware.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				capturedVersion = GetVersionFromContext(r.Context())
+			}))
+
+			// The header defaults to X-API-Version when not configured
+			req := httptest.NewRequest("GET", "/users", nil)
+			if tt.version != "" {
+				req.Header.Set("X-API-Version", tt.version)
+			}
+			rec := httptest.NewRecorder()
+			wrapped.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if capturedVersion != tt.expectedVersion {
+				t.Errorf("Expected version %q, got %q", tt.expectedVersion, capturedVersion)
+			}
+		})
+	}
+}
+
+func TestAcceptVersioning(t *testing.T) {
+	middleware := NewVersioningMiddleware(&config.VersioningConfig{
+		Enabled:        true,
+		Strategy:       "accept",
+		DefaultVersion: "1",
+		AcceptPattern:  `application/vnd\.api\.v(\d+)\+json`,
+		VersionMappings: map[string]*config.VersionMapping{
+			"2": {Service: "api-v2", Deprecated: true},
+		},
+	}, slog.Default())
+
+	var capturedCtx context.Context
+	wrapped := middleware.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedCtx = r.Context()
+	}))
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("Accept", "application/vnd.api.v2+json")
+	rec := httptest.NewRecorder()
+	wrapped.ServeHTTP(rec, req)
+
+	if version := GetVersionFromContext(capturedCtx); version != "2" {
+		t.Errorf("Expected version 2, got %s", version)
+	}
+	if service := GetServiceOverrideFromContext(capturedCtx); service != "api-v2" {
+		t.Errorf("Expected service override api-v2, got %s", service)
+	}
+	if rec.Header().Get("Deprecation") != "true" {
+		t.Error("Expected Deprecation header for deprecated mapping")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	middleware := NewVersioningMiddleware(&config.VersioningConfig{
+		Enabled:        true,
+		Strategy:       "path",
+		DefaultVersion: "1.0",
+		Strict:         true,
+		VersionMappings: map[string]*config.VersionMapping{
+			"2.0": {Service: "api-v2", PathPrefix: "/api", Deprecated: true},
+		},
+	}, slog.Default())
+
+	req := httptest.NewRequest("GET", "/v2.0/users", nil)
+	res, err := middleware.Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	want := Resolution{Version: "2.0", Service: "api-v2", Path: "/api/v2.0/users", Deprecated: true}
+	if res != want {
+		t.Errorf("Resolve() = %+v, want %+v", res, want)
+	}
+	if req.URL.Path != "/v2.0/users" {
+		t.Errorf("Expected the request to be left alone, got path %s", req.URL.Path)
+	}
+	if ctx := res.WithContext(context.Background()); GetServiceOverrideFromContext(ctx) != "api-v2" || GetVersionFromContext(ctx) != "2.0" {
+		t.Error("Expected the resolution to be stored in the context")
+	}
+
+	if res, err := middleware.Resolve(httptest.NewRequest("GET", "/v9/users", nil)); err != ErrUnsupportedVersion || res.Version != "9" {
+		t.Errorf("Resolve() = %+v, %v, want ErrUnsupportedVersion for 9", res, err)
+	}
+}
@@ -188,11 +188,13 @@ func (l *Loader) ParseBytes(data []byte) (*Spec, error) {
 	return &spec, nil
 }
 
-// LoadDirectory loads all OpenAPI specs from a directory
-func (l *Loader) LoadDirectory(dir string) ([]*Spec, error) {
-	var specs []*Spec
+// LoadDirectory loads all OpenAPI specs from a directory, keyed by file
+// path. Files that fail to load are skipped and returned in failed, so that
+// their last good spec can be kept.
+func (l *Loader) LoadDirectory(dir string) (specs map[string]*Spec, failed []string, err error) {
+	specs = make(map[string]*Spec)
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -219,111 +221,28 @@ func (l *Loader) LoadDirectory(dir string) ([]*Spec, error) {
 				"path", path,
 				"error", err,
 			)
+			failed = append(failed, path)
 			return nil // Continue with other files
 		}
 
-		specs = append(specs, spec)
+		specs[path] = spec
 		return nil
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to walk directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to walk directory: %w", err)
 	}
 
-	return specs, nil
+	return specs, failed, nil
 }
 
-// ToRouteRules converts OpenAPI paths to gateway route rules
+// ToRouteRules converts OpenAPI paths to gateway route rules, using
+// operation IDs as route IDs and matching path parameters with wildcards
 func (l *Loader) ToRouteRules(spec *Spec, defaultService string) []core.RouteRule {
-	var rules []core.RouteRule
-
-	// Create tag to service mapping
-	tagServices := make(map[string]string)
-	for _, tag := range spec.Tags {
-		if tag.XService != "" {
-			tagServices[tag.Name] = tag.XService
-		}
-	}
-
-	// Convert paths to routes
-	for path, pathItem := range spec.Paths {
-		// Convert OpenAPI path to gateway path pattern
-		gatewayPath := convertPath(path)
-
-		// Process each operation
-		operations := map[string]*Operation{
-			"GET":     pathItem.Get,
-			"POST":    pathItem.Post,
-			"PUT":     pathItem.Put,
-			"DELETE":  pathItem.Delete,
-			"PATCH":   pathItem.Patch,
-			"OPTIONS": pathItem.Options,
-			"HEAD":    pathItem.Head,
-			"TRACE":   pathItem.Trace,
-		}
-
-		for method, op := range operations {
-			if op == nil {
-				continue
-			}
-
-			// Determine service name
-			serviceName := defaultService
-			if op.XGateway != nil && op.XGateway.ServiceName != "" {
-				serviceName = op.XGateway.ServiceName
-			} else if len(op.Tags) > 0 {
-				// Use first tag's service mapping
-				if svc, ok := tagServices[op.Tags[0]]; ok {
-					serviceName = svc
-				}
-			}
-
-			// Create route rule
-			rule := core.RouteRule{
-				ID:          generateRouteID(op.OperationID, method, path),
-				Path:        gatewayPath,
-				Methods:     []string{method},
-				ServiceName: serviceName,
-				Metadata:    make(map[string]interface{}),
-			}
-
-			// Apply gateway extensions
-			if op.XGateway != nil {
-				if op.XGateway.LoadBalance != "" {
-					rule.LoadBalance = core.LoadBalanceStrategy(op.XGateway.LoadBalance)
-				}
-				if op.XGateway.Timeout > 0 {
-					rule.Timeout = time.Duration(op.XGateway.Timeout) * time.Second
-				}
-				if op.XGateway.RateLimit > 0 {
-					rule.Metadata["rateLimit"] = op.XGateway.RateLimit
-				}
-				rule.Metadata["authRequired"] = op.XGateway.AuthRequired
-				if len(op.XGateway.RequiredScopes) > 0 {
-					rule.Metadata["requiredScopes"] = op.XGateway.RequiredScopes
-				}
-				if op.XGateway.Transformations != nil {
-					rule.Metadata["transformations"] = op.XGateway.Transformations
-				}
-			}
-
-			// Add operation metadata
-			rule.Metadata["operationId"] = op.OperationID
-			rule.Metadata["summary"] = op.Summary
-			if len(op.Tags) > 0 {
-				rule.Metadata["tags"] = op.Tags
-			}
-
-			rules = append(rules, rule)
-		}
-	}
-
-	l.logger.Info("Converted OpenAPI to routes",
-		"spec", spec.Info.Title,
-		"routes", len(rules),
-	)
-
-	return rules
+	return l.GenerateRouteRules(spec, RouteOptions{
+		RouteGeneration: RouteGeneration{IncludeCORS: true, OperationIDAsRouteID: true},
+		DefaultService:  defaultService,
+	})
 }
 
 // Helper functions
@@ -2,9 +2,13 @@ package openapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
@@ -12,37 +16,82 @@ import (
 	"github.com/fsnotify/fsnotify"
 )
 
+// Update strategies for generated routes
+const (
+	// UpdateReplace replaces the generated routes with those of the
+	// current specs, removing routes whose operations are gone
+	UpdateReplace = "replace"
+	// UpdateMerge adds and updates generated routes, keeping routes whose
+	// operations are gone
+	UpdateMerge = "merge"
+	// UpdateAppend only adds routes for operations not seen before
+	UpdateAppend = "append"
+)
+
+// Conflict resolutions for generated routes that clash with other routes
+const (
+	// ConflictError fails the update
+	ConflictError = "error"
+	// ConflictNewest replaces the other route with the generated one
+	ConflictNewest = "newest"
+	// ConflictSkip keeps the other route and drops the generated one
+	ConflictSkip = "skip"
+)
+
 // Config represents OpenAPI manager configuration
 type Config struct {
-	Enabled         bool              `yaml:"enabled"`
-	SpecsDirectory  string            `yaml:"specsDirectory"`  // Directory containing OpenAPI specs
-	SpecURLs        []string          `yaml:"specUrls"`        // URLs to OpenAPI specs
-	DefaultService  string            `yaml:"defaultService"`  // Default service name
-	ReloadInterval  time.Duration     `yaml:"reloadInterval"`  // Reload interval for URLs
-	WatchFiles      bool              `yaml:"watchFiles"`      // Watch local files for changes
-	ServiceMappings map[string]string `yaml:"serviceMappings"` // Tag to service mappings
+	Enabled            bool              `yaml:"enabled"`
+	SpecsDirectory     string            `yaml:"specsDirectory"`     // Directory containing OpenAPI specs
+	SpecURLs           []string          `yaml:"specUrls"`           // URLs to OpenAPI specs
+	DefaultService     string            `yaml:"defaultService"`     // Default service name
+	ReloadInterval     time.Duration     `yaml:"reloadInterval"`     // Reload interval for URLs
+	WatchFiles         bool              `yaml:"watchFiles"`         // Watch local files for changes
+	ServiceMappings    map[string]string `yaml:"serviceMappings"`    // Tag to service mappings
+	UpdateStrategy     string            `yaml:"updateStrategy"`     // replace (default), merge, append
+	ConflictResolution string            `yaml:"conflictResolution"` // error (default), newest, skip
+	RoutePrefix        string            `yaml:"routePrefix"`        // Prefix for generated paths
+	RouteGeneration    RouteGeneration   `yaml:"routeGeneration"`    // Route generation options
+}
+
+// RuleStore is the router generated routes are registered with
+type RuleStore interface {
+	AddRule(core.RouteRule) error
+	RemoveRule(id string) error
+	GetRoutes() []core.RouteRule
 }
 
 // Manager manages dynamic routes from OpenAPI specifications
 type Manager struct {
 	config       *Config
 	loader       *Loader
-	router       core.Router
+	router       RuleStore
 	logger       *slog.Logger
 	specs        map[string]*Spec // source -> spec
 	currentRules []core.RouteRule
 	mu           sync.RWMutex
+	reloadMu     sync.Mutex // serializes reloads from the watcher and ticker
 	watcher      *fsnotify.Watcher
 	ctx          context.Context
 	cancel       context.CancelFunc
 }
 
 // NewManager creates a new OpenAPI manager
-func NewManager(config *Config, router core.Router, logger *slog.Logger) (*Manager, error) {
+func NewManager(config *Config, router RuleStore, logger *slog.Logger) (*Manager, error) {
 	if logger == nil {
 		logger = slog.Default()
 	}
 
+	switch config.UpdateStrategy {
+	case "", UpdateReplace, UpdateMerge, UpdateAppend:
+	default:
+		return nil, fmt.Errorf("unknown update strategy: %s", config.UpdateStrategy)
+	}
+	switch config.ConflictResolution {
+	case "", ConflictError, ConflictNewest, ConflictSkip:
+	default:
+		return nil, fmt.Errorf("unknown conflict resolution: %s", config.ConflictResolution)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	m := &Manager{
@@ -59,6 +108,7 @@ func NewManager(config *Config, router core.Router, logger *slog.Logger) (*Manag
 	if config.WatchFiles && config.SpecsDirectory != "" {
 		watcher, err := fsnotify.NewWatcher()
 		if err != nil {
+			cancel()
 			return nil, fmt.Errorf("failed to create file watcher: %w", err)
 		}
 		m.watcher = watcher
@@ -82,9 +132,11 @@ func (m *Manager) Start() error {
 		go m.watchFiles()
 	}
 
-	// Start URL reloader
-	if len(m.config.SpecURLs) > 0 && m.config.ReloadInterval > 0 {
-		go m.reloadURLs()
+	// Start periodic reloads for URLs, and for the directory when it is
+	// not watched
+	polled := len(m.config.SpecURLs) > 0 || (m.config.SpecsDirectory != "" && m.watcher == nil)
+	if polled && m.config.ReloadInterval > 0 {
+		go m.reloadPeriodically()
 	}
 
 	m.logger.Info("OpenAPI manager started",
@@ -132,23 +184,59 @@ func (m *Manager) GetRoutes() []core.RouteRule {
 
 // reload loads all specs and updates routes
 func (m *Manager) reload() error {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	m.mu.RLock()
+	previous := m.specs
+	m.mu.RUnlock()
+
+	// Specs by file path or URL, in the order their rules are generated.
+	// Sources failing to load keep their last good spec, so their routes
+	// stay.
 	newSpecs := make(map[string]*Spec)
-	var allRules []core.RouteRule
+	var sources []string
+	add := func(source string, spec *Spec) {
+		newSpecs[source] = spec
+		sources = append(sources, source)
+	}
+	keep := func(source string) {
+		if spec, ok := previous[source]; ok {
+			add(source, spec)
+		}
+	}
+	opts := RouteOptions{
+		RouteGeneration: m.config.RouteGeneration,
+		DefaultService:  m.config.DefaultService,
+		ServiceMappings: m.config.ServiceMappings,
+		RoutePrefix:     m.config.RoutePrefix,
+	}
 
 	// Load from directory
 	if m.config.SpecsDirectory != "" {
-		specs, err := m.loader.LoadDirectory(m.config.SpecsDirectory)
+		specs, failed, err := m.loader.LoadDirectory(m.config.SpecsDirectory)
 		if err != nil {
-			m.logger.Error("Failed to load specs from directory",
+			m.logger.Error("Failed to load specs from directory, keeping the last loaded ones",
 				"directory", m.config.SpecsDirectory,
 				"error", err,
 			)
-		} else {
-			for _, spec := range specs {
-				source := filepath.Join(m.config.SpecsDirectory, spec.Info.Title+".yaml")
-				newSpecs[source] = spec
-				rules := m.loader.ToRouteRules(spec, m.config.DefaultService)
-				allRules = append(allRules, rules...)
+			for source := range previous {
+				if !slices.Contains(m.config.SpecURLs, source) {
+					failed = append(failed, source)
+				}
+			}
+		}
+		paths := failed
+		for path := range specs {
+			paths = append(paths, path)
+		}
+		// Walk order, which is lexical
+		sort.Strings(paths)
+		for _, path := range paths {
+			if spec, ok := specs[path]; ok {
+				add(path, spec)
+			} else {
+				keep(path)
 			}
 		}
 	}
@@ -157,58 +245,186 @@ func (m *Manager) reload() error {
 	for _, url := range m.config.SpecURLs {
 		spec, err := m.loader.Load(url)
 		if err != nil {
-			m.logger.Error("Failed to load spec from URL",
+			m.logger.Error("Failed to load spec from URL, keeping the last loaded one",
 				"url", url,
 				"error", err,
 			)
+			keep(url)
 			continue
 		}
-		newSpecs[url] = spec
-		rules := m.loader.ToRouteRules(spec, m.config.DefaultService)
-		allRules = append(allRules, rules...)
+		add(url, spec)
 	}
 
-	// Update router with new rules
-	if err := m.updateRoutes(allRules); err != nil {
-		return fmt.Errorf("failed to update routes: %w", err)
+	var allRules []core.RouteRule
+	for _, source := range sources {
+		allRules = append(allRules, m.loader.GenerateRouteRules(newSpecs[source], opts)...)
 	}
 
+	// Update router with new rules
+	applied, err := m.updateRoutes(allRules)
+
 	// Update specs
 	m.mu.Lock()
 	m.specs = newSpecs
-	m.currentRules = allRules
+	m.currentRules = applied
 	m.mu.Unlock()
 
+	if err != nil {
+		return fmt.Errorf("failed to update routes: %w", err)
+	}
+
 	m.logger.Info("OpenAPI specs reloaded",
 		"specs", len(newSpecs),
-		"routes", len(allRules),
+		"routes", len(applied),
 	)
 
 	return nil
 }
 
-// updateRoutes updates the router with new rules
-func (m *Manager) updateRoutes(rules []core.RouteRule) error {
-	// This is a simplified implementation
-	// In a real system, you'd want to:
-	// 1. Diff old and new rules
-	// 2. Remove deleted routes
-	// 3. Update changed routes
-	// 4. Add new routes
+// updateRoutes applies generated rules to the router using the configured
+// update strategy and conflict resolution, returning the generated rules the
+// router now holds. Conflicts are resolved before the router is changed, so
+// a conflict failing the update leaves the routes as they were.
+func (m *Manager) updateRoutes(rules []core.RouteRule) ([]core.RouteRule, error) {
+	m.mu.RLock()
+	current := make(map[string]core.RouteRule, len(m.currentRules))
+	for _, rule := range m.currentRules {
+		current[rule.ID] = rule
+	}
+	m.mu.RUnlock()
+
+	// Routes the manager did not generate, such as configured routes
+	var others []core.RouteRule
+	for _, rule := range m.router.GetRoutes() {
+		if _, ok := current[rule.ID]; !ok {
+			others = append(others, rule)
+		}
+	}
 
+	// Decide the generated rules to keep. Operations duplicated across specs
+	// fail the update under the error resolution and otherwise keep the
+	// first; conflicts with other routes are resolved as configured.
+	var desired []core.RouteRule
+	desiredIDs := make(map[string]bool)
+	displaced := make(map[string]bool)
 	for _, rule := range rules {
-		// Router interface would need AddRule method
-		// For now, we'll just log
-		m.logger.Debug("Would add route",
-			"id", rule.ID,
-			"path", rule.Path,
-			"service", rule.ServiceName,
-		)
+		if m.config.UpdateStrategy == UpdateAppend {
+			if _, ok := current[rule.ID]; ok {
+				desired = append(desired, current[rule.ID])
+				desiredIDs[rule.ID] = true
+				continue
+			}
+		}
+
+		if duplicate := findConflict(rule, desired); duplicate != nil {
+			if m.config.ConflictResolution == "" || m.config.ConflictResolution == ConflictError {
+				return m.currentRules, fmt.Errorf("operation %s conflicts with operation %s", rule.ID, duplicate.ID)
+			}
+			m.logger.Warn("Skipping duplicate OpenAPI operation", "route", rule.ID, "conflict", duplicate.ID)
+			continue
+		}
+
+		if conflict := findConflict(rule, others); conflict != nil {
+			switch m.config.ConflictResolution {
+			case ConflictNewest:
+				m.logger.Warn("OpenAPI route replaces configured route", "route", rule.ID, "replaced", conflict.ID)
+				displaced[conflict.ID] = true
+			case ConflictSkip:
+				m.logger.Warn("Skipping OpenAPI route conflicting with configured route", "route", rule.ID, "conflict", conflict.ID)
+				continue
+			default:
+				return m.currentRules, fmt.Errorf("route %s conflicts with route %s", rule.ID, conflict.ID)
+			}
+		}
+
+		desired = append(desired, rule)
+		desiredIDs[rule.ID] = true
 	}
 
+	// Merge and append keep generated routes whose operations are gone
+	if m.config.UpdateStrategy == UpdateMerge || m.config.UpdateStrategy == UpdateAppend {
+		for _, rule := range m.currentRules {
+			if !desiredIDs[rule.ID] && findConflict(rule, desired) == nil {
+				desired = append(desired, rule)
+				desiredIDs[rule.ID] = true
+			}
+		}
+	}
+
+	// Remove displaced routes and generated routes that are gone or changed
+	var errs []error
+	applied := make(map[string]bool)
+	for id := range displaced {
+		if err := m.router.RemoveRule(id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	desiredByID := make(map[string]core.RouteRule, len(desired))
+	for _, rule := range desired {
+		desiredByID[rule.ID] = rule
+	}
+	for id, rule := range current {
+		if next, ok := desiredByID[id]; ok && reflect.DeepEqual(next, rule) {
+			applied[id] = true
+			continue
+		}
+		if err := m.router.RemoveRule(id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Add new and changed routes
+	result := make([]core.RouteRule, 0, len(desired))
+	for _, rule := range desired {
+		if !applied[rule.ID] {
+			if err := m.router.AddRule(rule); err != nil {
+				m.logger.Error("Failed to add OpenAPI route", "route", rule.ID, "path", rule.Path, "error", err)
+				errs = append(errs, err)
+				continue
+			}
+			m.logger.Debug("Added OpenAPI route",
+				"id", rule.ID,
+				"path", rule.Path,
+				"service", rule.ServiceName,
+			)
+		}
+		result = append(result, rule)
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// findConflict returns the rule in rules sharing rule's ID, or its path with
+// an overlapping method
+func findConflict(rule core.RouteRule, rules []core.RouteRule) *core.RouteRule {
+	for i := range rules {
+		other := &rules[i]
+		if other.ID == rule.ID {
+			return other
+		}
+		if other.Path == rule.Path && methodsOverlap(other.Methods, rule.Methods) {
+			return other
+		}
+	}
 	return nil
 }
 
+// methodsOverlap reports whether two method lists share a method; an empty
+// list matches all methods
+func methodsOverlap(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // watchFiles watches for file changes
 func (m *Manager) watchFiles() {
 	for {
@@ -226,8 +442,8 @@ func (m *Manager) watchFiles() {
 				continue
 			}
 
-			switch event.Op {
-			case fsnotify.Create, fsnotify.Write:
+			switch {
+			case event.Has(fsnotify.Create), event.Has(fsnotify.Write):
 				m.logger.Info("OpenAPI file changed", "file", event.Name)
 				if err := m.reload(); err != nil {
 					m.logger.Error("Failed to reload after file change",
@@ -235,7 +451,7 @@ func (m *Manager) watchFiles() {
 						"error", err,
 					)
 				}
-			case fsnotify.Remove:
+			case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
 				m.logger.Info("OpenAPI file removed", "file", event.Name)
 				if err := m.reload(); err != nil {
 					m.logger.Error("Failed to reload after file removal",
@@ -253,8 +469,8 @@ func (m *Manager) watchFiles() {
 	}
 }
 
-// reloadURLs periodically reloads specs from URLs
-func (m *Manager) reloadURLs() {
+// reloadPeriodically reloads specs every ReloadInterval
+func (m *Manager) reloadPeriodically() {
 	ticker := time.NewTicker(m.config.ReloadInterval)
 	defer ticker.Stop()
 
@@ -264,7 +480,7 @@ func (m *Manager) reloadURLs() {
 			return
 		case <-ticker.C:
 			if err := m.reload(); err != nil {
-				m.logger.Error("Failed to reload URLs", "error", err)
+				m.logger.Error("Failed to reload specs", "error", err)
 			}
 		}
 	}
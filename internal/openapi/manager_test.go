@@ -0,0 +1,191 @@
+package openapi
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/router"
+)
+
+// writeSpec writes a spec with a GET operation per path
+func writeSpec(t *testing.T, dir string, paths ...string) {
+	t.Helper()
+
+	var b strings.Builder
+	b.WriteString("openapi: 3.0.0\ninfo:\n  title: Test API\n  version: 1.0.0\npaths:\n")
+	for _, path := range paths {
+		id := strings.ReplaceAll(strings.Trim(path, "/"), "/", "-")
+		b.WriteString("  " + path + ":\n    get:\n      operationId: " + id + "\n")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "openapi.yaml"), []byte(b.String()), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+}
+
+// routeIDs returns the sorted IDs of the router's routes
+func routeIDs(r *router.Router) string {
+	var ids []string
+	for _, rule := range r.GetRoutes() {
+		ids = append(ids, rule.ID)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+// newTestManager creates a manager for dir on a router holding a configured
+// /static route
+func newTestManager(t *testing.T, dir string, config Config) (*Manager, *router.Router) {
+	t.Helper()
+
+	r := router.NewRouter(nil, nil)
+	if err := r.AddRule(core.RouteRule{ID: "static", Path: "/static", Methods: []string{"GET"}, ServiceName: "static-service"}); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	config.SpecsDirectory = dir
+	config.DefaultService = "backend"
+	config.RouteGeneration = RouteGeneration{PathStyle: PathStyleExact, OperationIDAsRouteID: true}
+	m, err := NewManager(&config, r, nil)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	t.Cleanup(func() { m.Stop() })
+	return m, r
+}
+
+func TestManager_UpdateStrategy(t *testing.T) {
+	tests := []struct {
+		strategy string
+		expected string
+	}{
+		{UpdateReplace, "orders,static,users"},
+		{UpdateMerge, "items,orders,static,users"},
+		{UpdateAppend, "items,orders,static,users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			dir := t.TempDir()
+			writeSpec(t, dir, "/users", "/items")
+			m, r := newTestManager(t, dir, Config{UpdateStrategy: tt.strategy})
+
+			if err := m.Start(); err != nil {
+				t.Fatalf("Failed to start manager: %v", err)
+			}
+			if got := routeIDs(r); got != "items,static,users" {
+				t.Fatalf("Expected generated routes, got %s", got)
+			}
+
+			writeSpec(t, dir, "/users", "/orders")
+			if err := m.reload(); err != nil {
+				t.Fatalf("Failed to reload: %v", err)
+			}
+			if got := routeIDs(r); got != tt.expected {
+				t.Errorf("Expected routes %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestManager_ConflictResolution(t *testing.T) {
+	tests := []struct {
+		resolution string
+		wantErr    bool
+		service    string
+	}{
+		{ConflictError, true, "static-service"},
+		{ConflictSkip, false, "static-service"},
+		{ConflictNewest, false, "backend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resolution, func(t *testing.T) {
+			dir := t.TempDir()
+			writeSpec(t, dir, "/static", "/users")
+			m, r := newTestManager(t, dir, Config{ConflictResolution: tt.resolution})
+
+			err := m.Start()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Start() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			rule, err := r.Match(core.NewRequest("", "GET", "/static", "/static", "", nil, nil, context.Background()))
+			if err != nil {
+				t.Fatalf("Expected /static to be routed: %v", err)
+			}
+			if rule.ServiceName != tt.service {
+				t.Errorf("Expected /static on %s, got %s", tt.service, rule.ServiceName)
+			}
+
+			// A failed update leaves the router unchanged
+			if _, err := r.Match(core.NewRequest("", "GET", "/users", "/users", "", nil, nil, context.Background())); (err != nil) != tt.wantErr {
+				t.Errorf("Unexpected /users routing error: %v", err)
+			}
+		})
+	}
+}
+
+func TestManager_WatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeSpec(t, dir, "/users")
+	m, r := newTestManager(t, dir, Config{WatchFiles: true})
+
+	if err := m.Start(); err != nil {
+		t.Fatalf("Failed to start manager: %v", err)
+	}
+
+	writeSpec(t, dir, "/users", "/orders")
+	deadline := time.Now().Add(5 * time.Second)
+	for routeIDs(r) != "orders,static,users" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected spec change to be picked up, got %s", routeIDs(r))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestManager_KeepsLastGoodSpecs(t *testing.T) {
+	dir := t.TempDir()
+	writeSpec(t, dir, "/users")
+	// A second spec with the same title is loaded under its own path
+	other := "openapi: 3.0.0\ninfo:\n  title: Test API\n  version: 1.0.0\npaths:\n  /orders:\n    get:\n      operationId: orders\n"
+	if err := os.WriteFile(filepath.Join(dir, "orders-openapi.yaml"), []byte(other), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	m, r := newTestManager(t, dir, Config{})
+
+	if err := m.Start(); err != nil {
+		t.Fatalf("Failed to start manager: %v", err)
+	}
+	if got := routeIDs(r); got != "orders,static,users" {
+		t.Fatalf("Expected routes of both specs, got %s", got)
+	}
+
+	// A spec that no longer loads keeps its routes
+	if err := os.WriteFile(filepath.Join(dir, "openapi.yaml"), []byte("openapi: [broken"), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	if err := m.reload(); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if got := routeIDs(r); got != "orders,static,users" {
+		t.Errorf("Expected the last good routes to be kept, got %s", got)
+	}
+
+	// So do all specs when the directory cannot be read
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("Failed to remove directory: %v", err)
+	}
+	if err := m.reload(); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if got := routeIDs(r); got != "orders,static,users" {
+		t.Errorf("Expected the last good routes to be kept, got %s", got)
+	}
+}
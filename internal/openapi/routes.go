@@ -0,0 +1,241 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gateway/internal/core"
+)
+
+// Path styles for generated routes
+const (
+	// PathStyleExact matches the operation path, with each path parameter
+	// matching one segment
+	PathStyleExact = "exact"
+	// PathStylePrefix matches the operation path and everything below it
+	PathStylePrefix = "prefix"
+	// PathStyleWildcard matches everything below the operation path's
+	// static prefix, up to its first path parameter
+	PathStyleWildcard = "wildcard"
+)
+
+// RouteGeneration controls how routes are generated from operations
+type RouteGeneration struct {
+	IncludeOptions       bool   `yaml:"includeOptions"`       // Add an OPTIONS route to paths without one
+	IncludeCORS          bool   `yaml:"includeCORS"`          // Apply the gateway CORS policy to generated routes
+	PathStyle            string `yaml:"pathStyle"`            // exact, prefix, wildcard
+	OperationIDAsRouteID bool   `yaml:"operationIdAsRouteId"` // Use operationId as route ID
+}
+
+// RouteOptions controls route generation for a spec
+type RouteOptions struct {
+	RouteGeneration
+	DefaultService  string            // Service for operations without a mapping
+	ServiceMappings map[string]string // Tag to service mappings
+	RoutePrefix     string            // Prefix for all generated paths
+}
+
+// operationMethods lists the operations of a path item in a stable order
+var operationMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "TRACE"}
+
+// GenerateRouteRules generates a route rule per operation of the spec. Rules
+// are returned in path order so generation is deterministic.
+func (l *Loader) GenerateRouteRules(spec *Spec, opts RouteOptions) []core.RouteRule {
+	var rules []core.RouteRule
+
+	// Create tag to service mapping
+	tagServices := make(map[string]string)
+	for _, tag := range spec.Tags {
+		if tag.XService != "" {
+			tagServices[tag.Name] = tag.XService
+		}
+	}
+	for tag, service := range opts.ServiceMappings {
+		tagServices[tag] = service
+	}
+
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pathItem := spec.Paths[path]
+		gatewayPath := opts.path(path)
+
+		operations := map[string]*Operation{
+			"GET":     pathItem.Get,
+			"POST":    pathItem.Post,
+			"PUT":     pathItem.Put,
+			"DELETE":  pathItem.Delete,
+			"PATCH":   pathItem.Patch,
+			"OPTIONS": pathItem.Options,
+			"HEAD":    pathItem.Head,
+			"TRACE":   pathItem.Trace,
+		}
+
+		first := -1
+		for _, method := range operationMethods {
+			op := operations[method]
+			if op == nil {
+				continue
+			}
+
+			// Determine service name
+			serviceName := opts.DefaultService
+			if op.XGateway != nil && op.XGateway.ServiceName != "" {
+				serviceName = op.XGateway.ServiceName
+			} else if len(op.Tags) > 0 {
+				// Use first tag's service mapping
+				if svc, ok := tagServices[op.Tags[0]]; ok {
+					serviceName = svc
+				}
+			}
+
+			operationID := op.OperationID
+			if !opts.OperationIDAsRouteID {
+				operationID = ""
+			}
+
+			rule := core.RouteRule{
+				ID:          generateRouteID(operationID, method, path),
+				Path:        gatewayPath,
+				Methods:     []string{method},
+				ServiceName: serviceName,
+				Metadata:    make(map[string]interface{}),
+			}
+			applyExtension(&rule, op.XGateway)
+
+			// Add operation metadata
+			rule.Metadata["operationId"] = op.OperationID
+			rule.Metadata["summary"] = op.Summary
+			if len(op.Tags) > 0 {
+				rule.Metadata["tags"] = op.Tags
+			}
+			if !opts.IncludeCORS {
+				rule.Metadata["cors"] = false
+			}
+
+			if first == -1 {
+				first = len(rules)
+			}
+			rules = append(rules, rule)
+		}
+
+		// Forward OPTIONS requests for the path to the service of its first
+		// operation when the spec does not describe them
+		if opts.IncludeOptions && first != -1 && pathItem.Options == nil {
+			rule := core.RouteRule{
+				ID:          generateRouteID("", "OPTIONS", path),
+				Path:        gatewayPath,
+				Methods:     []string{"OPTIONS"},
+				ServiceName: rules[first].ServiceName,
+				LoadBalance: rules[first].LoadBalance,
+				Timeout:     rules[first].Timeout,
+				Metadata:    make(map[string]interface{}),
+			}
+			if !opts.IncludeCORS {
+				rule.Metadata["cors"] = false
+			}
+			rules = append(rules, rule)
+		}
+	}
+
+	l.logger.Info("Converted OpenAPI to routes",
+		"spec", spec.Info.Title,
+		"routes", len(rules),
+	)
+
+	return rules
+}
+
+// applyExtension applies an operation's gateway extension to its rule
+func applyExtension(rule *core.RouteRule, ext *GatewayExtension) {
+	if ext == nil {
+		return
+	}
+	if ext.LoadBalance != "" {
+		rule.LoadBalance = core.LoadBalanceStrategy(ext.LoadBalance)
+	}
+	if ext.Timeout > 0 {
+		rule.Timeout = time.Duration(ext.Timeout) * time.Second
+	}
+	if ext.RateLimit > 0 {
+		rule.Metadata["rateLimit"] = ext.RateLimit
+	}
+	rule.Metadata["authRequired"] = ext.AuthRequired
+	if len(ext.RequiredScopes) > 0 {
+		rule.Metadata["requiredScopes"] = ext.RequiredScopes
+	}
+	if ext.Transformations != nil {
+		rule.Metadata["transformations"] = ext.Transformations
+	}
+}
+
+// path converts an OpenAPI path to a gateway path in the configured style
+func (o RouteOptions) path(path string) string {
+	var result string
+	switch o.PathStyle {
+	case PathStyleExact:
+		result = exactPath(path)
+	case PathStylePrefix:
+		result = strings.TrimSuffix(exactPath(path), "/") + "/*"
+	case PathStyleWildcard:
+		result = path
+		if i := strings.Index(path, "{"); i >= 0 {
+			result = path[:strings.LastIndex(path[:i], "/")]
+		}
+		result = strings.TrimSuffix(result, "/") + "/*"
+	default:
+		result = convertPath(path)
+	}
+
+	if prefix := strings.TrimSuffix(o.RoutePrefix, "/"); prefix != "" {
+		result = prefix + result
+	}
+	return result
+}
+
+// exactPath converts path parameters to named segments. Segments mixing a
+// parameter with other text match any value of the segment.
+func exactPath(path string) string {
+	segments := strings.Split(path, "/")
+	seen := make(map[string]bool)
+	for i, segment := range segments {
+		start := strings.Index(segment, "{")
+		end := strings.Index(segment, "}")
+		if start == -1 || end < start {
+			continue
+		}
+
+		name := paramName(segment[start+1 : end])
+		if seen[name] {
+			name = fmt.Sprintf("%s%d", name, i)
+		}
+		seen[name] = true
+		segments[i] = ":" + name
+	}
+	return strings.Join(segments, "/")
+}
+
+// paramName converts a parameter name to a valid pattern wildcard name
+func paramName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9' && i > 0:
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "param"
+	}
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"testing"
+)
+
+func TestGenerateRouteRules(t *testing.T) {
+	spec := &Spec{
+		OpenAPI: "3.0.0",
+		Info:    Info{Title: "Users API", Version: "1.0.0"},
+		Tags:    []Tag{{Name: "users", XService: "user-service"}},
+		Paths: map[string]PathItem{
+			"/users": {
+				Get:  &Operation{OperationID: "listUsers", Tags: []string{"users"}},
+				Post: &Operation{OperationID: "createUser", Tags: []string{"users"}},
+			},
+			"/users/{user-id}/files/{name}.json": {
+				Get: &Operation{OperationID: "getFile", Tags: []string{"files"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		opts    RouteOptions
+		paths   map[string]string // route ID -> path
+		service map[string]string // route ID -> service
+		cors    bool
+	}{
+		{
+			name: "exact",
+			opts: RouteOptions{
+				RouteGeneration: RouteGeneration{PathStyle: PathStyleExact, OperationIDAsRouteID: true, IncludeCORS: true},
+				DefaultService:  "backend",
+			},
+			paths: map[string]string{
+				"listUsers":  "/users",
+				"createUser": "/users",
+				"getFile":    "/users/:user_id/files/:name",
+			},
+			service: map[string]string{"listUsers": "user-service", "getFile": "backend"},
+			cors:    true,
+		},
+		{
+			name: "prefix with route prefix and mappings",
+			opts: RouteOptions{
+				RouteGeneration: RouteGeneration{PathStyle: PathStylePrefix, OperationIDAsRouteID: true},
+				DefaultService:  "backend",
+				ServiceMappings: map[string]string{"users": "users-v2", "files": "file-service"},
+				RoutePrefix:     "/api/",
+			},
+			paths: map[string]string{
+				"listUsers": "/api/users/*",
+				"getFile":   "/api/users/:user_id/files/:name/*",
+			},
+			service: map[string]string{"listUsers": "users-v2", "getFile": "file-service"},
+		},
+		{
+			name: "wildcard without operation IDs",
+			opts: RouteOptions{
+				RouteGeneration: RouteGeneration{PathStyle: PathStyleWildcard},
+				DefaultService:  "backend",
+			},
+			paths: map[string]string{
+				"get__users":                         "/users/*",
+				"post__users":                        "/users/*",
+				"get__users_user-id_files_name.json": "/users/*",
+			},
+		},
+	}
+
+	loader := NewLoader(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := loader.GenerateRouteRules(spec, tt.opts)
+			if len(rules) != 3 {
+				t.Fatalf("Expected 3 rules, got %d", len(rules))
+			}
+
+			for _, rule := range rules {
+				if path, ok := tt.paths[rule.ID]; ok && rule.Path != path {
+					t.Errorf("%s: expected path %s, got %s", rule.ID, path, rule.Path)
+				}
+				if service, ok := tt.service[rule.ID]; ok && rule.ServiceName != service {
+					t.Errorf("%s: expected service %s, got %s", rule.ID, service, rule.ServiceName)
+				}
+				if _, excluded := rule.Metadata["cors"]; excluded == tt.cors {
+					t.Errorf("%s: expected CORS included=%v", rule.ID, tt.cors)
+				}
+				delete(tt.paths, rule.ID)
+			}
+			for id := range tt.paths {
+				t.Errorf("Expected route %s", id)
+			}
+		})
+	}
+}
+
+func TestGenerateRouteRules_IncludeOptions(t *testing.T) {
+	spec := &Spec{
+		OpenAPI: "3.0.0",
+		Info:    Info{Title: "Items API", Version: "1.0.0"},
+		Paths: map[string]PathItem{
+			"/items": {
+				Get:  &Operation{OperationID: "listItems", XGateway: &GatewayExtension{ServiceName: "item-service"}},
+				Post: &Operation{OperationID: "createItem"},
+			},
+			"/items/{id}": {
+				Get:     &Operation{OperationID: "getItem"},
+				Options: &Operation{OperationID: "itemOptions"},
+			},
+		},
+	}
+
+	rules := NewLoader(nil).GenerateRouteRules(spec, RouteOptions{
+		RouteGeneration: RouteGeneration{IncludeOptions: true, PathStyle: PathStyleExact, OperationIDAsRouteID: true},
+		DefaultService:  "backend",
+	})
+
+	options := 0
+	for _, rule := range rules {
+		if rule.Methods[0] != "OPTIONS" {
+			continue
+		}
+		options++
+		// The generated OPTIONS route follows the path's first operation
+		if rule.ID == "options__items" && rule.ServiceName != "item-service" {
+			t.Errorf("Expected OPTIONS route on item-service, got %s", rule.ServiceName)
+		}
+	}
+	// One generated for /items and the described one for /items/{id}
+	if options != 2 {
+		t.Errorf("Expected 2 OPTIONS routes, got %d", options)
+	}
+}
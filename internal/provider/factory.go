@@ -14,9 +14,6 @@ import (
 	grpcConnector "gateway/internal/connector/grpc"
 	sseConnector "gateway/internal/connector/sse"
 	wsConnector "gateway/internal/connector/websocket"
-	"gateway/internal/middleware/auth/apikey"
-	"gateway/internal/middleware/auth/jwt"
-	"gateway/pkg/errors"
 	"gateway/pkg/factory"
 )
 
@@ -34,10 +31,6 @@ type Component struct {
 	wsConnectorInst    *wsConnector.Connector
 	grpcConnectorInst  *grpcConnector.Connector
 	
-	// Auth providers
-	jwtProvider    *jwt.Provider
-	apiKeyProvider *apikey.Provider
-	
 	// Component references
 	httpConnectorComp  *httpConnector.Component
 	sseConnectorComp   *sseConnector.Component
@@ -228,126 +221,6 @@ func (c *Component) GetGRPCConnector() *grpcConnector.Connector {
 	return nil
 }
 
-// GetJWTProvider returns the JWT provider, creating it if necessary
-func (c *Component) GetJWTProvider() (*jwt.Provider, error) {
-	c.mu.RLock()
-	if c.jwtProvider != nil {
-		c.mu.RUnlock()
-		return c.jwtProvider, nil
-	}
-	c.mu.RUnlock()
-	
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	
-	// Double-check after acquiring write lock
-	if c.jwtProvider != nil {
-		return c.jwtProvider, nil
-	}
-	
-	// Check if JWT is configured
-	if c.config.Gateway.Auth == nil || c.config.Gateway.Auth.JWT == nil || !c.config.Gateway.Auth.JWT.Enabled {
-		return nil, errors.NewError(errors.ErrorTypeInternal, "JWT provider requested but JWT auth is not enabled")
-	}
-	
-	provider, err := c.createJWTProvider(c.config.Gateway.Auth.JWT)
-	if err != nil {
-		return nil, err
-	}
-	
-	c.jwtProvider = provider
-	return provider, nil
-}
-
-// GetAPIKeyProvider returns the API key provider, creating it if necessary
-func (c *Component) GetAPIKeyProvider() (*apikey.Provider, error) {
-	c.mu.RLock()
-	if c.apiKeyProvider != nil {
-		c.mu.RUnlock()
-		return c.apiKeyProvider, nil
-	}
-	c.mu.RUnlock()
-	
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	
-	// Double-check after acquiring write lock
-	if c.apiKeyProvider != nil {
-		return c.apiKeyProvider, nil
-	}
-	
-	// Check if API key is configured
-	if c.config.Gateway.Auth == nil || c.config.Gateway.Auth.APIKey == nil || !c.config.Gateway.Auth.APIKey.Enabled {
-		return nil, errors.NewError(errors.ErrorTypeInternal, "API key provider requested but API key auth is not enabled")
-	}
-	
-	provider, err := c.createAPIKeyProvider(c.config.Gateway.Auth.APIKey)
-	if err != nil {
-		return nil, err
-	}
-	
-	c.apiKeyProvider = provider
-	return provider, nil
-}
-
-// createJWTProvider creates a JWT provider from configuration
-func (c *Component) createJWTProvider(cfg *config.JWTConfig) (*jwt.Provider, error) {
-	jwtConfig := &jwt.Config{
-		Issuer:            cfg.Issuer,
-		Audience:          cfg.Audience,
-		SigningMethod:     cfg.SigningMethod,
-		PublicKey:         cfg.PublicKey,
-		Secret:            cfg.Secret,
-		JWKSEndpoint:      cfg.JWKSEndpoint,
-		JWKSCacheDuration: time.Duration(cfg.JWKSCacheDuration) * time.Second,
-		ClaimsMapping:     cfg.ClaimsMapping,
-		ScopeClaim:        cfg.ScopeClaim,
-		SubjectClaim:      cfg.SubjectClaim,
-	}
-
-	// Set defaults
-	if jwtConfig.JWKSCacheDuration == 0 {
-		jwtConfig.JWKSCacheDuration = 1 * time.Hour
-	}
-
-	return jwt.NewProvider(jwtConfig, c.logger)
-}
-
-// createAPIKeyProvider creates an API key provider from configuration
-func (c *Component) createAPIKeyProvider(cfg *config.APIKeyConfig) (*apikey.Provider, error) {
-	// Convert config keys
-	keys := make(map[string]*apikey.KeyConfig)
-	for id, details := range cfg.Keys {
-		keyConfig := &apikey.KeyConfig{
-			Key:      details.Key,
-			Subject:  details.Subject,
-			Type:     details.Type,
-			Scopes:   details.Scopes,
-			Metadata: details.Metadata,
-			Disabled: details.Disabled,
-		}
-
-		// Parse expiration time if provided
-		if details.ExpiresAt != "" {
-			t, err := time.Parse(time.RFC3339, details.ExpiresAt)
-			if err != nil {
-				return nil, errors.NewError(errors.ErrorTypeInternal, "failed to parse API key expiration").WithCause(err).WithDetail("keyId", id)
-			}
-			keyConfig.ExpiresAt = &t
-		}
-
-		keys[id] = keyConfig
-	}
-
-	apiKeyConfig := &apikey.Config{
-		Keys:          keys,
-		HashKeys:      cfg.HashKeys,
-		DefaultScopes: cfg.DefaultScopes,
-	}
-
-	return apikey.NewProvider(apiKeyConfig, c.logger)
-}
-
 // Close releases all resources held by the provider
 func (c *Component) Close() error {
 	c.mu.Lock()
@@ -377,13 +250,15 @@ func (c *Component) createHTTPClient(cfg config.HTTPBackend) (*http.Client, erro
 
 	// Create transport with connection pooling
 	transport := &http.Transport{
-		DialContext:           dialer.DialContext,
-		MaxIdleConns:          cfg.MaxIdleConns,
-		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
-		IdleConnTimeout:       time.Duration(cfg.IdleConnTimeout) * time.Second,
-		ResponseHeaderTimeout: time.Duration(cfg.ResponseHeaderTimeout) * time.Second,
-		ForceAttemptHTTP2:     true,
-		DisableCompression:    false,
+		DialContext:            dialer.DialContext,
+		MaxIdleConns:           cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:    cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:        time.Duration(cfg.IdleConnTimeout) * time.Second,
+		ResponseHeaderTimeout:  time.Duration(cfg.ResponseHeaderTimeout) * time.Second,
+		ForceAttemptHTTP2:      true,
+		DisableCompression:     cfg.DisableCompression,
+		ExpectContinueTimeout:  httpConnector.ExpectContinueTimeout(cfg.ExpectContinueTimeout),
+		MaxResponseHeaderBytes: int64(cfg.MaxResponseHeaderBytes),
 	}
 
 	// TODO: Add TLS configuration if needed
@@ -0,0 +1,97 @@
+package dns
+
+import (
+	"fmt"
+	"log/slog"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+	"gateway/pkg/factory"
+)
+
+// ComponentName is the name used to register this component
+const ComponentName = "dns-registry"
+
+// Component implements factory.Component for DNS SRV registry
+type Component struct {
+	config   *config.DNSRegistry
+	registry *Registry
+	logger   *slog.Logger
+}
+
+// NewComponent creates a new DNS registry component
+func NewComponent(logger *slog.Logger) factory.Component {
+	return &Component{
+		logger: logger,
+	}
+}
+
+// Name returns the component name
+func (c *Component) Name() string {
+	return ComponentName
+}
+
+// Init initializes the component with configuration
+func (c *Component) Init(parser factory.ConfigParser) error {
+	// Parse the DNS registry configuration
+	var dnsConfig config.DNSRegistry
+	if err := parser(&dnsConfig); err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+	c.config = &dnsConfig
+
+	// Validate before resolving anything
+	if len(dnsConfig.Services) == 0 {
+		return fmt.Errorf("no services configured")
+	}
+	for _, service := range dnsConfig.Services {
+		if service.Name == "" || service.Query == "" {
+			return fmt.Errorf("service name and query are required")
+		}
+	}
+
+	// Create registry
+	registry, err := NewRegistry(&dnsConfig, c.logger)
+	if err != nil {
+		return fmt.Errorf("create DNS registry: %w", err)
+	}
+	c.registry = registry
+
+	return nil
+}
+
+// Validate validates the component state
+func (c *Component) Validate() error {
+	if c.registry == nil {
+		return fmt.Errorf("DNS registry not initialized")
+	}
+	return nil
+}
+
+// Build returns the registry
+func (c *Component) Build() core.ServiceRegistry {
+	if c.registry == nil {
+		panic("Component not initialized")
+	}
+	return c.registry
+}
+
+// Start starts the registry (implements Lifecycle)
+func (c *Component) Start() error {
+	// DNS registry starts automatically in NewRegistry
+	return nil
+}
+
+// Stop stops the registry (implements Lifecycle)
+func (c *Component) Stop() error {
+	if c.registry == nil {
+		return nil
+	}
+	return c.registry.Close()
+}
+
+// Ensure Component implements factory.Component and factory.Lifecycle
+var (
+	_ factory.Component = (*Component)(nil)
+	_ factory.Lifecycle = (*Component)(nil)
+)
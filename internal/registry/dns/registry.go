@@ -0,0 +1,243 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+	"gateway/pkg/errors"
+)
+
+// Default refresh bounds and query timeout
+const (
+	DefaultMinRefresh = 5   // seconds
+	DefaultMaxRefresh = 300 // seconds
+	DefaultTimeout    = 5   // seconds
+)
+
+// Registry implements service discovery using DNS SRV records. Each
+// configured service is resolved periodically; the next refresh follows the
+// smallest record TTL, bounded by the configured minimum and maximum.
+type Registry struct {
+	config   config.DNSRegistry
+	resolver *Resolver
+	services map[string][]core.ServiceInstance
+	mu       sync.RWMutex
+	logger   *slog.Logger
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+	ready    chan struct{} // Closed after the first refresh resolving every service
+	once     sync.Once
+}
+
+// NewRegistry creates a DNS registry, resolves all services once and starts
+// the refresh loop
+func NewRegistry(cfg *config.DNSRegistry, logger *slog.Logger) (*Registry, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("dns registry config is required")
+	}
+
+	r := &Registry{
+		config:   *cfg,
+		services: make(map[string][]core.ServiceInstance),
+		logger:   logger.With("component", "dns-registry"),
+		stopCh:   make(chan struct{}),
+		ready:    make(chan struct{}),
+	}
+	if r.config.MinRefresh <= 0 {
+		r.config.MinRefresh = DefaultMinRefresh
+	}
+	if r.config.MaxRefresh <= 0 {
+		r.config.MaxRefresh = DefaultMaxRefresh
+	}
+	if r.config.Timeout <= 0 {
+		r.config.Timeout = DefaultTimeout
+	}
+	if r.config.MaxRefresh < r.config.MinRefresh {
+		return nil, fmt.Errorf("maxRefresh %d is below minRefresh %d", r.config.MaxRefresh, r.config.MinRefresh)
+	}
+	r.resolver = NewResolver(r.config.Resolver, time.Duration(r.config.Timeout)*time.Second)
+
+	// Initial discovery; failures are retried by the refresh loop
+	next := r.refresh()
+
+	r.wg.Add(1)
+	go r.refreshLoop(next)
+
+	return r, nil
+}
+
+// GetService returns instances for a service
+func (r *Registry) GetService(name string) ([]core.ServiceInstance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	instances, ok := r.services[name]
+	if !ok {
+		return nil, errors.NewError(errors.ErrorTypeNotFound, fmt.Sprintf("service %s not found", name))
+	}
+
+	// Return a copy to avoid race conditions
+	result := make([]core.ServiceInstance, len(instances))
+	copy(result, instances)
+
+	return result, nil
+}
+
+// ListServices returns all resolved services
+func (r *Registry) ListServices() ([]*core.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	services := make([]*core.Service, 0, len(r.services))
+	for name, instances := range r.services {
+		instancePtrs := make([]*core.ServiceInstance, len(instances))
+		for i := range instances {
+			instance := instances[i]
+			instancePtrs[i] = &instance
+		}
+		services = append(services, &core.Service{
+			Name:      name,
+			Instances: instancePtrs,
+		})
+	}
+	return services, nil
+}
+
+// refresh resolves all services and returns the delay until the next refresh
+func (r *Registry) refresh() time.Duration {
+	ctx := context.Background()
+	minTTL := time.Duration(r.config.MaxRefresh) * time.Second
+	failed := false
+
+	for _, service := range r.config.Services {
+		records, ttl, err := r.resolver.LookupSRV(ctx, service.Query)
+		if err != nil {
+			// Keep the last known instances and retry soon
+			r.logger.Error("SRV lookup failed",
+				"service", service.Name,
+				"query", service.Query,
+				"error", err,
+			)
+			minTTL = 0
+			failed = true
+			continue
+		}
+
+		instances := instancesFromSRV(service, records)
+
+		r.mu.Lock()
+		if len(instances) == 0 {
+			delete(r.services, service.Name)
+		} else {
+			r.services[service.Name] = instances
+		}
+		r.mu.Unlock()
+
+		r.logger.Debug("Resolved SRV records",
+			"service", service.Name,
+			"query", service.Query,
+			"instances", len(instances),
+			"ttl", ttl,
+		)
+
+		if ttl < minTTL {
+			minTTL = ttl
+		}
+	}
+
+	if !failed {
+		r.once.Do(func() { close(r.ready) })
+	}
+
+	return r.refreshDelay(minTTL)
+}
+
+// refreshDelay bounds a record TTL by the configured refresh interval
+func (r *Registry) refreshDelay(ttl time.Duration) time.Duration {
+	minRefresh := time.Duration(r.config.MinRefresh) * time.Second
+	maxRefresh := time.Duration(r.config.MaxRefresh) * time.Second
+	switch {
+	case ttl < minRefresh:
+		return minRefresh
+	case ttl > maxRefresh:
+		return maxRefresh
+	default:
+		return ttl
+	}
+}
+
+// refreshLoop re-resolves services when their records expire
+func (r *Registry) refreshLoop(next time.Duration) {
+	defer r.wg.Done()
+
+	timer := time.NewTimer(next)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			timer.Reset(r.refresh())
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// Ready returns a channel closed after the first refresh resolving every
+// service
+func (r *Registry) Ready() <-chan struct{} {
+	return r.ready
+}
+
+// Close stops the registry
+func (r *Registry) Close() error {
+	close(r.stopCh)
+	r.wg.Wait()
+	return nil
+}
+
+// instancesFromSRV converts SRV records to service instances. Only targets
+// of the lowest priority are used, as clients must prefer them; SRV weights
+// become instance weights for weighted balancing.
+func instancesFromSRV(service config.DNSService, records []SRVRecord) []core.ServiceInstance {
+	if len(records) == 0 {
+		return nil
+	}
+
+	priority := records[0].Priority
+	for _, record := range records[1:] {
+		if record.Priority < priority {
+			priority = record.Priority
+		}
+	}
+
+	scheme := service.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	instances := make([]core.ServiceInstance, 0, len(records))
+	for _, record := range records {
+		if record.Priority != priority {
+			continue
+		}
+		instances = append(instances, core.ServiceInstance{
+			ID:      fmt.Sprintf("%s:%d", record.Target, record.Port),
+			Name:    service.Name,
+			Address: record.Target,
+			Port:    int(record.Port),
+			Scheme:  scheme,
+			Healthy: true,
+			Metadata: map[string]any{
+				"weight":   int(record.Weight),
+				"priority": int(record.Priority),
+			},
+		})
+	}
+	return instances
+}
@@ -0,0 +1,266 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"gateway/internal/config"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// fakeServer answers SRV queries over UDP from a record table
+type fakeServer struct {
+	conn    net.PacketConn
+	mu      sync.Mutex
+	records map[string][]dnsmessage.SRVResource
+	ttl     uint32
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	s := &fakeServer{
+		conn:    conn,
+		records: make(map[string][]dnsmessage.SRVResource),
+		ttl:     30,
+	}
+	t.Cleanup(func() { conn.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeServer) set(name string, records ...dnsmessage.SRVResource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[name] = records
+}
+
+func (s *fakeServer) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var query dnsmessage.Message
+		if err := query.Unpack(buf[:n]); err != nil {
+			continue
+		}
+
+		question := query.Questions[0]
+		response := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: query.ID, Response: true},
+			Questions: query.Questions,
+		}
+
+		s.mu.Lock()
+		records, ok := s.records[question.Name.String()]
+		if !ok {
+			response.RCode = dnsmessage.RCodeNameError
+		}
+		for i := range records {
+			response.Answers = append(response.Answers, dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{
+					Name:  question.Name,
+					Type:  dnsmessage.TypeSRV,
+					Class: dnsmessage.ClassINET,
+					TTL:   s.ttl,
+				},
+				Body: &records[i],
+			})
+		}
+		s.mu.Unlock()
+
+		packet, err := response.Pack()
+		if err != nil {
+			continue
+		}
+		s.conn.WriteTo(packet, addr)
+	}
+}
+
+func srv(target string, port, priority, weight uint16) dnsmessage.SRVResource {
+	return dnsmessage.SRVResource{
+		Target:   dnsmessage.MustNewName(target),
+		Port:     port,
+		Priority: priority,
+		Weight:   weight,
+	}
+}
+
+func TestResolver_LookupSRV(t *testing.T) {
+	server := newFakeServer(t)
+	server.set("_http._tcp.users.example.com.",
+		srv("users-1.example.com.", 8080, 10, 5),
+		srv("users-2.example.com.", 8081, 10, 1),
+	)
+
+	resolver := NewResolver(server.conn.LocalAddr().String(), time.Second)
+	records, ttl, err := resolver.LookupSRV(context.Background(), "_http._tcp.users.example.com")
+	if err != nil {
+		t.Fatalf("LookupSRV failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0].Target != "users-1.example.com" || records[0].Port != 8080 || records[0].Weight != 5 {
+		t.Errorf("Unexpected record: %+v", records[0])
+	}
+	if ttl != 30*time.Second {
+		t.Errorf("Expected 30s TTL, got %v", ttl)
+	}
+
+	// Unknown names resolve to no records
+	records, _, err = resolver.LookupSRV(context.Background(), "_http._tcp.missing.example.com")
+	if err != nil || len(records) != 0 {
+		t.Errorf("Expected no records for unknown name, got %v, %v", records, err)
+	}
+
+	// Names without SRV records fail
+	server.set("_http._tcp.empty.example.com.")
+	if _, _, err = resolver.LookupSRV(context.Background(), "_http._tcp.empty.example.com"); !errors.Is(err, errNoData) {
+		t.Errorf("Expected errNoData for a name without records, got %v", err)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	server := newFakeServer(t)
+	server.set("_http._tcp.users.example.com.",
+		srv("users-1.example.com.", 8080, 10, 5),
+		srv("users-2.example.com.", 8081, 10, 0),
+		srv("users-backup.example.com.", 8080, 20, 1),
+	)
+
+	registry, err := NewRegistry(&config.DNSRegistry{
+		Resolver: server.conn.LocalAddr().String(),
+		Services: []config.DNSService{
+			{Name: "users", Query: "_http._tcp.users.example.com", Scheme: "https"},
+			{Name: "orders", Query: "_http._tcp.orders.example.com"},
+		},
+	}, slog.Default())
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	defer registry.Close()
+
+	instances, err := registry.GetService("users")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+
+	// The backup target has a higher priority value and is not used
+	if len(instances) != 2 {
+		t.Fatalf("Expected 2 instances, got %d", len(instances))
+	}
+	first := instances[0]
+	if first.ID != "users-1.example.com:8080" || first.Address != "users-1.example.com" ||
+		first.Port != 8080 || first.Scheme != "https" || !first.Healthy {
+		t.Errorf("Unexpected instance: %+v", first)
+	}
+	if weight := first.Metadata["weight"]; weight != 5 {
+		t.Errorf("Expected weight 5, got %v", weight)
+	}
+
+	if _, err := registry.GetService("orders"); err == nil {
+		t.Error("Expected error for service without SRV records")
+	}
+
+	select {
+	case <-registry.Ready():
+	default:
+		t.Error("Expected registry to be ready after resolving every service")
+	}
+}
+
+func TestRegistry_KeepsInstancesWithoutRecords(t *testing.T) {
+	server := newFakeServer(t)
+	server.set("_http._tcp.users.example.com.", srv("users-1.example.com.", 8080, 10, 5))
+
+	registry, err := NewRegistry(&config.DNSRegistry{
+		Resolver: server.conn.LocalAddr().String(),
+		Services: []config.DNSService{{Name: "users", Query: "_http._tcp.users.example.com"}},
+	}, slog.Default())
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	defer registry.Close()
+
+	// An answer without records keeps the last known instances
+	server.set("_http._tcp.users.example.com.")
+	if next := registry.refresh(); next != DefaultMinRefresh*time.Second {
+		t.Errorf("Expected a retry after %ds, got %v", DefaultMinRefresh, next)
+	}
+	if instances, err := registry.GetService("users"); err != nil || len(instances) != 1 {
+		t.Errorf("Expected the last known instance to be kept, got %v, %v", instances, err)
+	}
+
+	// A name that no longer exists removes the service
+	server.mu.Lock()
+	delete(server.records, "_http._tcp.users.example.com.")
+	server.mu.Unlock()
+	registry.refresh()
+	if _, err := registry.GetService("users"); err == nil {
+		t.Error("Expected a removed name to remove the service")
+	}
+}
+
+func TestRegistry_NotReady(t *testing.T) {
+	// Nothing answers on a closed port
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := conn.LocalAddr().String()
+	conn.Close()
+
+	registry, err := NewRegistry(&config.DNSRegistry{
+		Resolver: addr,
+		Timeout:  1,
+		Services: []config.DNSService{{Name: "users", Query: "_http._tcp.users.example.com"}},
+	}, slog.Default())
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	defer registry.Close()
+
+	select {
+	case <-registry.Ready():
+		t.Error("Expected registry not to be ready after a failed lookup")
+	default:
+	}
+}
+
+func TestRegistry_RefreshDelay(t *testing.T) {
+	registry := &Registry{config: config.DNSRegistry{MinRefresh: 5, MaxRefresh: 60}}
+
+	tests := []struct {
+		ttl      time.Duration
+		expected time.Duration
+	}{
+		{0, 5 * time.Second},
+		{30 * time.Second, 30 * time.Second},
+		{time.Hour, 60 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := registry.refreshDelay(tt.ttl); got != tt.expected {
+			t.Errorf("refreshDelay(%v) = %v, want %v", tt.ttl, got, tt.expected)
+		}
+	}
+}
+
+func TestSystemNameserver(t *testing.T) {
+	if got := systemNameserver("/nonexistent/resolv.conf"); got != "127.0.0.1:53" {
+		t.Errorf("Expected loopback fallback, got %s", got)
+	}
+}
@@ -0,0 +1,194 @@
+package dns
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// defaultResolvConf is read to find the system resolver
+const defaultResolvConf = "/etc/resolv.conf"
+
+// SRVRecord is a resolved SRV record
+type SRVRecord struct {
+	Target   string
+	Port     uint16
+	Priority uint16
+	Weight   uint16
+}
+
+// Resolver queries SRV records from a DNS server. Unlike net.LookupSRV it
+// reports the record TTL, which drives the registry refresh interval.
+type Resolver struct {
+	server  string
+	timeout time.Duration
+}
+
+// NewResolver creates a resolver for server (host:port). An empty server
+// uses the first nameserver of the system resolver configuration.
+func NewResolver(server string, timeout time.Duration) *Resolver {
+	if server == "" {
+		server = systemNameserver(defaultResolvConf)
+	} else if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &Resolver{
+		server:  server,
+		timeout: timeout,
+	}
+}
+
+// errNoData reports a name that exists but has no SRV records
+var errNoData = errors.New("no SRV records")
+
+// LookupSRV resolves the SRV records of name, returning the smallest TTL
+// among the answers. A name that does not exist yields no records. A name
+// without SRV records fails with errNoData, as such answers are also seen
+// while records are being replaced.
+func (r *Resolver) LookupSRV(ctx context.Context, name string) ([]SRVRecord, time.Duration, error) {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	qname, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid SRV name %q: %w", name, err)
+	}
+
+	id := uint16(rand.Uint32())
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  qname,
+			Type:  dnsmessage.TypeSRV,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	packet, err := query.Pack()
+	if err != nil {
+		return nil, 0, fmt.Errorf("pack query: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	response, err := r.exchange(ctx, "udp", packet, id)
+	if err == nil && response.Truncated {
+		// Large answers are retried over TCP
+		response, err = r.exchange(ctx, "tcp", packet, id)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch response.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, 0, nil
+	default:
+		return nil, 0, fmt.Errorf("query %s failed: %s", name, response.RCode)
+	}
+
+	var (
+		records []SRVRecord
+		ttl     uint32
+	)
+	for _, answer := range response.Answers {
+		srv, ok := answer.Body.(*dnsmessage.SRVResource)
+		if !ok {
+			continue
+		}
+		records = append(records, SRVRecord{
+			Target:   strings.TrimSuffix(srv.Target.String(), "."),
+			Port:     srv.Port,
+			Priority: srv.Priority,
+			Weight:   srv.Weight,
+		})
+		if len(records) == 1 || answer.Header.TTL < ttl {
+			ttl = answer.Header.TTL
+		}
+	}
+
+	if len(records) == 0 {
+		return nil, 0, fmt.Errorf("query %s: %w", name, errNoData)
+	}
+	return records, time.Duration(ttl) * time.Second, nil
+}
+
+// exchange sends the query over network and parses the matching response
+func (r *Resolver) exchange(ctx context.Context, network string, packet []byte, id uint16) (*dnsmessage.Message, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, r.server)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", r.server, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var buf []byte
+	if network == "tcp" {
+		// TCP messages are prefixed with their length
+		framed := make([]byte, 2+len(packet))
+		binary.BigEndian.PutUint16(framed, uint16(len(packet)))
+		copy(framed[2:], packet)
+		if _, err := conn.Write(framed); err != nil {
+			return nil, fmt.Errorf("write query: %w", err)
+		}
+
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, fmt.Errorf("read response: %w", err)
+		}
+		buf = make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return nil, fmt.Errorf("read response: %w", err)
+		}
+	} else {
+		if _, err := conn.Write(packet); err != nil {
+			return nil, fmt.Errorf("write query: %w", err)
+		}
+		buf = make([]byte, 65535)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("read response: %w", err)
+		}
+		buf = buf[:n]
+	}
+
+	var response dnsmessage.Message
+	if err := response.Unpack(buf); err != nil {
+		return nil, fmt.Errorf("unpack response: %w", err)
+	}
+	if response.ID != id {
+		return nil, fmt.Errorf("response id mismatch")
+	}
+	return &response, nil
+}
+
+// systemNameserver returns the first nameserver in the resolver configuration
+func systemNameserver(path string) string {
+	file, err := os.Open(path)
+	if err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "nameserver" {
+				return net.JoinHostPort(fields[1], "53")
+			}
+		}
+	}
+	return "127.0.0.1:53"
+}
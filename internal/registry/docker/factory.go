@@ -47,6 +47,7 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 		LabelPrefix:     dockerConfig.LabelPrefix,
 		Network:         dockerConfig.Network,
 		RefreshInterval: dockerConfig.RefreshInterval,
+		RouteLabels:     dockerConfig.RouteLabels,
 	}
 	
 	// Set defaults
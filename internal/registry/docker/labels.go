@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Labels is the gateway configuration read from the labels of a container
+// or Swarm service
+type Labels struct {
+	Service  string
+	Port     int
+	Scheme   string
+	Healthy  bool
+	Metadata map[string]any
+}
+
+// ParseLabels reads the <prefix>.service, <prefix>.port, <prefix>.scheme,
+// <prefix>.health and <prefix>.meta.* labels. It returns false when the
+// service or port label is missing, and an error when the port is invalid.
+func ParseLabels(labels map[string]string, prefix string) (Labels, bool, error) {
+	service := labels[prefix+".service"]
+	if service == "" {
+		return Labels{}, false, nil
+	}
+	portStr := labels[prefix+".port"]
+	if portStr == "" {
+		return Labels{}, false, nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return Labels{}, false, fmt.Errorf("invalid port %q: %w", portStr, err)
+	}
+
+	parsed := Labels{
+		Service:  service,
+		Port:     port,
+		Scheme:   "http",
+		Healthy:  true,
+		Metadata: make(map[string]any),
+	}
+	if v, ok := labels[prefix+".scheme"]; ok {
+		parsed.Scheme = v
+	}
+	if v, ok := labels[prefix+".health"]; ok {
+		parsed.Healthy = v == "healthy" || v == "true"
+	}
+	for k, v := range labels {
+		if strings.HasPrefix(k, prefix+".meta.") {
+			parsed.Metadata[strings.TrimPrefix(k, prefix+".meta.")] = v
+		}
+	}
+	return parsed, true, nil
+}
+
+// ShortID truncates a Docker object ID to its first 12 characters
+func ShortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -8,7 +8,8 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
-	"strconv"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -28,6 +29,7 @@ type Config struct {
 	LabelPrefix     string `yaml:"labelPrefix"`     // Label prefix for gateway config
 	Network         string `yaml:"network"`         // Docker network to use
 	RefreshInterval int    `yaml:"refreshInterval"` // Service refresh interval in seconds
+	RouteLabels     bool   `yaml:"routeLabels"`     // Read routes from <prefix>.route.* labels
 }
 
 // DefaultConfig returns default Docker registry configuration
@@ -46,10 +48,16 @@ type Registry struct {
 	httpClient *http.Client
 	baseURL    string
 	services   map[string][]core.ServiceInstance
+	routes     map[string]core.RouteRule
 	mu         sync.RWMutex
 	logger     *slog.Logger
 	stopCh     chan struct{}
 	wg         sync.WaitGroup
+	ready      chan struct{} // Closed after the first successful discovery
+	readyOnce  sync.Once
+
+	routesChanged func([]core.RouteRule)
+	notifyMu      sync.Mutex // Delivers route changes in order
 }
 
 // Container represents a Docker container
@@ -71,44 +79,17 @@ func NewRegistry(config *Config, logger *slog.Logger) (*Registry, error) {
 		config = DefaultConfig()
 	}
 
-	// Determine Docker API URL
-	var baseURL string
-	if config.Host != "" {
-		baseURL = config.Host
-	} else {
-		// Use Unix socket if no host specified
-		baseURL = "http://localhost"
-	}
-
-	// Create HTTP client
-	var httpClient *http.Client
-	if strings.HasPrefix(baseURL, "unix://") {
-		// Unix socket connection
-		socketPath := strings.TrimPrefix(baseURL, "unix://")
-		httpClient = &http.Client{
-			Transport: &http.Transport{
-				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
-					dialer := net.Dialer{}
-					return dialer.DialContext(ctx, "unix", socketPath)
-				},
-			},
-			Timeout: 10 * time.Second,
-		}
-		baseURL = "http://localhost"
-	} else {
-		// TCP connection
-		httpClient = &http.Client{
-			Timeout: 10 * time.Second,
-		}
-	}
+	httpClient, baseURL := NewHTTPClient(config.Host)
 
 	r := &Registry{
 		config:     config,
 		httpClient: httpClient,
 		baseURL:    baseURL,
 		services:   make(map[string][]core.ServiceInstance),
+		routes:     make(map[string]core.RouteRule),
 		logger:     logger,
 		stopCh:     make(chan struct{}),
+		ready:      make(chan struct{}),
 	}
 
 	// Test connection
@@ -128,11 +109,35 @@ func NewRegistry(config *Config, logger *slog.Logger) (*Registry, error) {
 	// Initial load
 	if err := r.refresh(); err != nil {
 		logger.Error("Initial service discovery failed", "error", err)
+	} else {
+		r.markReady()
 	}
 
 	return r, nil
 }
 
+// NewHTTPClient creates a client for the Docker API at host and returns it
+// with the base URL of the API. Hosts of the form unix:///path are reached
+// over the Unix socket; an empty host defaults to http://localhost.
+func NewHTTPClient(host string) (*http.Client, string) {
+	if strings.HasPrefix(host, "unix://") {
+		socketPath := strings.TrimPrefix(host, "unix://")
+		return &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					dialer := net.Dialer{}
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+			Timeout: 10 * time.Second,
+		}, "http://localhost"
+	}
+	if host == "" {
+		host = "http://localhost"
+	}
+	return &http.Client{Timeout: 10 * time.Second}, host
+}
+
 // ping tests the connection to Docker daemon
 func (r *Registry) ping(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, "GET", r.baseURL+"/_ping", nil)
@@ -228,35 +233,29 @@ func (r *Registry) refresh() error {
 		return errors.NewError(errors.ErrorTypeInternal, "failed to decode container list").WithCause(err)
 	}
 
-	// Build service map
+	// Build service map. Containers are visited in ID order, so the same
+	// container wins among replicas with conflicting route labels.
+	sort.Slice(containers, func(i, j int) bool { return containers[i].ID < containers[j].ID })
 	services := make(map[string][]core.ServiceInstance)
+	routes := make(map[string]core.RouteRule)
 
 	for _, container := range containers {
 		if container.State != "running" {
 			continue
 		}
 
-		// Extract service info from labels
-		serviceName := container.Labels[r.config.LabelPrefix+".service"]
-		if serviceName == "" {
-			continue
-		}
-
-		// Get port from label
-		portStr := container.Labels[r.config.LabelPrefix+".port"]
-		if portStr == "" {
-			continue
-		}
-
-		port, err := strconv.Atoi(portStr)
+		labels, ok, err := ParseLabels(container.Labels, r.config.LabelPrefix)
 		if err != nil {
 			r.logger.Warn("Invalid port in container label",
 				"container", container.ID,
-				"port", portStr,
 				"error", err,
 			)
 			continue
 		}
+		if !ok {
+			continue
+		}
+		serviceName := labels.Service
 
 		// Get IP address
 		var ipAddress string
@@ -282,45 +281,22 @@ func (r *Registry) refresh() error {
 			continue
 		}
 
-		// Extract metadata
-		metadata := make(map[string]any)
-		for k, v := range container.Labels {
-			if strings.HasPrefix(k, r.config.LabelPrefix+".meta.") {
-				key := strings.TrimPrefix(k, r.config.LabelPrefix+".meta.")
-				metadata[key] = v
-			}
-		}
-
-		// Determine scheme
-		scheme := "http"
-		if v, ok := container.Labels[r.config.LabelPrefix+".scheme"]; ok {
-			scheme = v
-		}
-
-		// Check health
-		healthy := true
-		if v, ok := container.Labels[r.config.LabelPrefix+".health"]; ok {
-			healthy = v == "healthy" || v == "true"
-		}
-
-		// Use short container ID (first 12 chars) if ID is long enough
-		containerID := container.ID
-		if len(containerID) > 12 {
-			containerID = containerID[:12]
-		}
-
 		instance := core.ServiceInstance{
-			ID:       containerID,
+			ID:       ShortID(container.ID),
 			Name:     serviceName,
 			Address:  ipAddress,
-			Port:     port,
-			Scheme:   scheme,
-			Healthy:  healthy,
-			Metadata: metadata,
+			Port:     labels.Port,
+			Scheme:   labels.Scheme,
+			Healthy:  labels.Healthy,
+			Metadata: labels.Metadata,
 		}
 
 		services[serviceName] = append(services[serviceName], instance)
 
+		if r.config.RouteLabels {
+			r.addRoute(routes, container, serviceName)
+		}
+
 		r.logger.Debug("Discovered service instance",
 			"service", serviceName,
 			"id", instance.ID,
@@ -330,10 +306,19 @@ func (r *Registry) refresh() error {
 	}
 
 	// Update services atomically
+	r.notifyMu.Lock()
 	r.mu.Lock()
 	r.services = services
+	changed := !reflect.DeepEqual(routes, r.routes)
+	r.routes = routes
+	routesChanged := r.routesChanged
 	r.mu.Unlock()
 
+	if changed && routesChanged != nil {
+		routesChanged(sortedRoutes(routes))
+	}
+	r.notifyMu.Unlock()
+
 	r.logger.Info("Service discovery completed",
 		"services", len(services),
 		"total_instances", r.countInstances(services),
@@ -342,6 +327,32 @@ func (r *Registry) refresh() error {
 	return nil
 }
 
+// addRoute adds the route of container's labels to routes, unless another
+// container already described a different route under the same ID
+func (r *Registry) addRoute(routes map[string]core.RouteRule, container Container, service string) {
+	route, ok, err := ParseRoute(container.Labels, r.config.LabelPrefix, service)
+	if err != nil {
+		r.logger.Warn("Invalid route in container labels",
+			"container", container.ID,
+			"error", err,
+		)
+		return
+	}
+	if !ok {
+		return
+	}
+	if existing, ok := routes[route.ID]; ok {
+		if !reflect.DeepEqual(existing, route) {
+			r.logger.Warn("Ignoring conflicting route labels",
+				"container", container.ID,
+				"route", route.ID,
+			)
+		}
+		return
+	}
+	routes[route.ID] = route
+}
+
 // refreshLoop runs periodic service discovery
 func (r *Registry) refreshLoop() {
 	defer r.wg.Done()
@@ -354,6 +365,8 @@ func (r *Registry) refreshLoop() {
 		case <-ticker.C:
 			if err := r.refresh(); err != nil {
 				r.logger.Error("Service refresh failed", "error", err)
+			} else {
+				r.markReady()
 			}
 		case <-r.stopCh:
 			return
@@ -361,6 +374,16 @@ func (r *Registry) refreshLoop() {
 	}
 }
 
+// Ready returns a channel closed after the first successful discovery
+func (r *Registry) Ready() <-chan struct{} {
+	return r.ready
+}
+
+// markReady signals a successful discovery
+func (r *Registry) markReady() {
+	r.readyOnce.Do(func() { close(r.ready) })
+}
+
 // Close stops the registry
 func (r *Registry) Close() error {
 	close(r.stopCh)
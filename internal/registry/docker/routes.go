@@ -0,0 +1,187 @@
+package docker
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+)
+
+// ParseRoute reads the route of a service from the <prefix>.route.* labels:
+// path (required), id, methods, loadbalance, timeout, protocol,
+// authRequired and authType. It returns false when there is no path label.
+// The route ID defaults to docker-<service>.
+func ParseRoute(labels map[string]string, prefix, service string) (core.RouteRule, bool, error) {
+	label := func(name string) string {
+		return labels[prefix+".route."+name]
+	}
+
+	path := label("path")
+	if path == "" {
+		return core.RouteRule{}, false, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return core.RouteRule{}, false, fmt.Errorf("route path %q must start with /", path)
+	}
+
+	rule := config.RouteRule{
+		ID:          label("id"),
+		Path:        path,
+		ServiceName: service,
+		LoadBalance: label("loadbalance"),
+		Protocol:    label("protocol"),
+		AuthType:    label("authType"),
+	}
+	if rule.ID == "" {
+		rule.ID = "docker-" + service
+	}
+	if v := label("timeout"); v != "" {
+		timeout, err := strconv.Atoi(v)
+		if err != nil || timeout < 0 {
+			return core.RouteRule{}, false, fmt.Errorf("invalid route timeout %q", v)
+		}
+		rule.Timeout = timeout
+	}
+	if v := label("authRequired"); v != "" {
+		required, err := strconv.ParseBool(v)
+		if err != nil {
+			return core.RouteRule{}, false, fmt.Errorf("invalid route authRequired %q", v)
+		}
+		rule.AuthRequired = required
+	}
+
+	route := rule.ToRouteRule()
+	if v := label("methods"); v != "" {
+		for _, method := range strings.Split(v, ",") {
+			if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+				route.Methods = append(route.Methods, method)
+			}
+		}
+	}
+	route.Metadata["source"] = "docker"
+	return route, true, nil
+}
+
+// Routes returns the routes described by container labels, sorted by ID
+func (r *Registry) Routes() []core.RouteRule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sortedRoutes(r.routes)
+}
+
+// OnRoutesChange registers fn to receive the label routes after each
+// refresh that changes them. fn is called with the current routes first.
+func (r *Registry) OnRoutesChange(fn func([]core.RouteRule)) {
+	r.notifyMu.Lock()
+	defer r.notifyMu.Unlock()
+
+	r.mu.Lock()
+	r.routesChanged = fn
+	routes := sortedRoutes(r.routes)
+	r.mu.Unlock()
+
+	fn(routes)
+}
+
+// sortedRoutes returns the routes of a map sorted by ID
+func sortedRoutes(routes map[string]core.RouteRule) []core.RouteRule {
+	ids := make([]string, 0, len(routes))
+	for id := range routes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	sorted := make([]core.RouteRule, 0, len(ids))
+	for _, id := range ids {
+		sorted = append(sorted, routes[id])
+	}
+	return sorted
+}
+
+// RuleStore is the router label routes are registered with
+type RuleStore interface {
+	AddRule(core.RouteRule) error
+	RemoveRule(id string) error
+}
+
+// RouteSync keeps the routes of a router in line with the label routes of
+// a Docker registry. Routes already on the router, such as configured
+// routes, take precedence: a label route conflicting with one of them is
+// skipped.
+type RouteSync struct {
+	store   RuleStore
+	logger  *slog.Logger
+	mu      sync.Mutex
+	applied map[string]core.RouteRule
+	skipped map[string]core.RouteRule // Conflicts already reported
+}
+
+// NewRouteSync creates a route sync for store
+func NewRouteSync(store RuleStore, logger *slog.Logger) *RouteSync {
+	return &RouteSync{
+		store:   store,
+		logger:  logger.With("component", "docker-routes"),
+		applied: make(map[string]core.RouteRule),
+		skipped: make(map[string]core.RouteRule),
+	}
+}
+
+// Apply replaces the label routes on the router with routes, leaving the
+// unchanged ones in place
+func (s *RouteSync) Apply(routes []core.RouteRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	desired := make(map[string]core.RouteRule, len(routes))
+	for _, route := range routes {
+		desired[route.ID] = route
+	}
+
+	for id, route := range s.applied {
+		if next, ok := desired[id]; ok && reflect.DeepEqual(next, route) {
+			continue
+		}
+		if err := s.store.RemoveRule(id); err != nil {
+			s.logger.Warn("Failed to remove label route", "route", id, "error", err)
+		}
+		delete(s.applied, id)
+	}
+
+	for id := range s.skipped {
+		if _, ok := desired[id]; !ok {
+			delete(s.skipped, id)
+		}
+	}
+	for _, route := range routes {
+		if _, ok := s.applied[route.ID]; ok {
+			continue
+		}
+		if err := s.store.AddRule(route); err != nil {
+			if previous, ok := s.skipped[route.ID]; ok && reflect.DeepEqual(previous, route) {
+				continue
+			}
+			s.skipped[route.ID] = route
+			s.logger.Warn("Skipping label route conflicting with another route",
+				"route", route.ID,
+				"path", route.Path,
+				"service", route.ServiceName,
+				"error", err,
+			)
+			continue
+		}
+		delete(s.skipped, route.ID)
+		s.applied[route.ID] = route
+		s.logger.Debug("Added label route",
+			"route", route.ID,
+			"path", route.Path,
+			"service", route.ServiceName,
+		)
+	}
+}
@@ -0,0 +1,192 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+)
+
+func TestParseRoute(t *testing.T) {
+	route, ok, err := ParseRoute(map[string]string{
+		"gateway.route.path":         "/users/*",
+		"gateway.route.methods":      "get, post",
+		"gateway.route.loadbalance":  "least_connections",
+		"gateway.route.timeout":      "15",
+		"gateway.route.authRequired": "true",
+	}, "gateway", "users")
+	if err != nil || !ok {
+		t.Fatalf("Expected route, got %v, %v", ok, err)
+	}
+	if route.ID != "docker-users" || route.ServiceName != "users" || route.Path != "/users/*" {
+		t.Errorf("Unexpected route %+v", route)
+	}
+	if len(route.Methods) != 2 || route.Methods[0] != "GET" || route.Methods[1] != "POST" {
+		t.Errorf("Expected GET and POST, got %v", route.Methods)
+	}
+	if route.LoadBalance != core.LoadBalanceLeastConnections || route.Timeout != 15*time.Second {
+		t.Errorf("Expected load balancing and timeout from labels, got %s and %v", route.LoadBalance, route.Timeout)
+	}
+	if route.Metadata["authRequired"] != true || route.Protocol != "http" {
+		t.Errorf("Expected auth required over http, got %v and %s", route.Metadata, route.Protocol)
+	}
+
+	if _, ok, err := ParseRoute(map[string]string{"gateway.service": "users"}, "gateway", "users"); ok || err != nil {
+		t.Errorf("Expected no route without a path label, got %v, %v", ok, err)
+	}
+
+	invalid := []map[string]string{
+		{"gateway.route.path": "users"},
+		{"gateway.route.path": "/users", "gateway.route.timeout": "soon"},
+		{"gateway.route.path": "/users", "gateway.route.authRequired": "maybe"},
+	}
+	for _, labels := range invalid {
+		if _, _, err := ParseRoute(labels, "gateway", "users"); err == nil {
+			t.Errorf("Expected error for %v", labels)
+		}
+	}
+}
+
+// mockStore is a rule store rejecting rules on paths it already holds
+type mockStore struct {
+	mu    sync.Mutex
+	rules map[string]core.RouteRule
+}
+
+func (s *mockStore) AddRule(rule core.RouteRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.rules {
+		if existing.ID == rule.ID || existing.Path == rule.Path {
+			return fmt.Errorf("rule %s conflicts with rule %s", rule.ID, existing.ID)
+		}
+	}
+	s.rules[rule.ID] = rule
+	return nil
+}
+
+func (s *mockStore) RemoveRule(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.rules, id)
+	return nil
+}
+
+func (s *mockStore) path(id string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rules[id].Path
+}
+
+func TestRouteSync_Apply(t *testing.T) {
+	store := &mockStore{rules: map[string]core.RouteRule{
+		"configured": {ID: "configured", Path: "/orders"},
+	}}
+	sync := NewRouteSync(store, slog.Default())
+
+	sync.Apply([]core.RouteRule{
+		{ID: "docker-users", Path: "/users"},
+		{ID: "docker-orders", Path: "/orders"},
+	})
+	if store.path("docker-users") != "/users" {
+		t.Error("Expected label route to be added")
+	}
+	if store.path("configured") != "/orders" || store.path("docker-orders") != "" {
+		t.Error("Expected configured route to win the conflict")
+	}
+
+	sync.Apply([]core.RouteRule{{ID: "docker-users", Path: "/people"}})
+	if store.path("docker-users") != "/people" {
+		t.Errorf("Expected changed label route to be replaced, got %q", store.path("docker-users"))
+	}
+
+	sync.Apply(nil)
+	if _, ok := store.rules["docker-users"]; ok {
+		t.Error("Expected removed label route to be dropped")
+	}
+	if store.path("configured") != "/orders" {
+		t.Error("Expected configured route to be kept")
+	}
+}
+
+func TestRegistry_RouteLabels(t *testing.T) {
+	var mu sync.Mutex
+	path := "/users/*"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_ping":
+			w.WriteHeader(http.StatusOK)
+		case "/containers/json":
+			mu.Lock()
+			defer mu.Unlock()
+			containers := []map[string]any{
+				{
+					"Id":    "bbb",
+					"State": "running",
+					"Labels": map[string]string{
+						"gateway.service":    "users",
+						"gateway.port":       "8080",
+						"gateway.route.path": "/conflicting",
+					},
+					"NetworkSettings": map[string]any{"Networks": map[string]any{"bridge": map[string]string{"IPAddress": "172.17.0.3"}}},
+				},
+				{
+					"Id":    "aaa",
+					"State": "running",
+					"Labels": map[string]string{
+						"gateway.service":    "users",
+						"gateway.port":       "8080",
+						"gateway.route.path": path,
+					},
+					"NetworkSettings": map[string]any{"Networks": map[string]any{"bridge": map[string]string{"IPAddress": "172.17.0.2"}}},
+				},
+			}
+			_ = json.NewEncoder(w).Encode(containers)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Host = server.URL
+	cfg.RefreshInterval = 0
+	cfg.RouteLabels = true
+	registry, err := NewRegistry(cfg, slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	defer registry.Close()
+
+	var received [][]core.RouteRule
+	registry.OnRoutesChange(func(routes []core.RouteRule) {
+		received = append(received, routes)
+	})
+	if len(received) != 1 || len(received[0]) != 1 || received[0][0].Path != "/users/*" {
+		t.Fatalf("Expected the route of the first container by ID, got %v", received)
+	}
+
+	// Unchanged labels do not notify
+	if err := registry.refresh(); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if len(received) != 1 {
+		t.Errorf("Expected no notification without changes, got %d", len(received))
+	}
+
+	mu.Lock()
+	path = "/people/*"
+	mu.Unlock()
+	if err := registry.refresh(); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if len(received) != 2 || received[1][0].Path != "/people/*" {
+		t.Errorf("Expected changed route to be notified, got %v", received)
+	}
+}
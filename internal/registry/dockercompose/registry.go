@@ -38,9 +38,16 @@ type Registry struct {
 	services        map[string][]*core.ServiceInstance
 	logger          *slog.Logger
 	stopCh          chan struct{}
+	ready           chan struct{} // Closed after the first successful discovery
+	readyOnce       sync.Once
+
+	stopOnce sync.Once      // Closes stopCh once
+	wg       sync.WaitGroup // Tracks the refresh loop
 }
 
-// NewRegistry creates a new Docker Compose registry
+// NewRegistry creates a new Docker Compose registry. Services are
+// discovered once before it returns and then every RefreshInterval until it
+// is closed.
 func NewRegistry(config *Config, logger *slog.Logger) (*Registry, error) {
 	// Set defaults
 	if config.LabelPrefix == "" {
@@ -79,34 +86,58 @@ func NewRegistry(config *Config, logger *slog.Logger) (*Registry, error) {
 		services: make(map[string][]*core.ServiceInstance),
 		logger:   logger.With("component", "dockercompose-registry"),
 		stopCh:   make(chan struct{}),
+		ready:    make(chan struct{}),
+	}
+
+	r.logger.Info("Starting Docker Compose service discovery",
+		"project", config.ProjectName,
+		"refresh_interval", config.RefreshInterval)
+
+	// Initial discovery; readiness waits for the first one that succeeds
+	if err := r.refresh(context.Background()); err != nil {
+		r.logger.Error("Initial service discovery failed", "error", err)
+	} else {
+		r.markReady()
 	}
 
+	r.wg.Add(1)
+	go r.refreshLoop(context.Background())
+
 	return r, nil
 }
 
-// Start starts the registry
+// Start refreshes the services immediately. The registry already refreshes
+// them in the background from its creation.
 func (r *Registry) Start(ctx context.Context) error {
-	r.logger.Info("Starting Docker Compose service discovery", 
-		"project", r.config.ProjectName,
-		"refresh_interval", r.config.RefreshInterval)
-
-	// Initial discovery
 	if err := r.refresh(ctx); err != nil {
-		return fmt.Errorf("initial service discovery failed: %w", err)
+		return fmt.Errorf("service discovery failed: %w", err)
 	}
-
-	// Start refresh loop
-	go r.refreshLoop(ctx)
-
+	r.markReady()
 	return nil
 }
 
-// Stop stops the registry
+// Stop stops refreshing the services
 func (r *Registry) Stop(ctx context.Context) error {
-	close(r.stopCh)
+	r.stopOnce.Do(func() { close(r.stopCh) })
+	r.wg.Wait()
 	return nil
 }
 
+// Close stops the registry
+func (r *Registry) Close() error {
+	return r.Stop(context.Background())
+}
+
+// Ready returns a channel closed after the first successful discovery
+func (r *Registry) Ready() <-chan struct{} {
+	return r.ready
+}
+
+// markReady signals a successful discovery
+func (r *Registry) markReady() {
+	r.readyOnce.Do(func() { close(r.ready) })
+}
+
 // GetService returns service instances by name
 func (r *Registry) GetService(name string) ([]core.ServiceInstance, error) {
 	r.mu.RLock()
@@ -126,8 +157,31 @@ func (r *Registry) GetService(name string) ([]core.ServiceInstance, error) {
 	return result, nil
 }
 
+// ListServices returns all discovered services
+func (r *Registry) ListServices() ([]*core.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	services := make([]*core.Service, 0, len(r.services))
+	for name, instances := range r.services {
+		// Copy instances to avoid race conditions
+		instanceCopies := make([]*core.ServiceInstance, len(instances))
+		for i, inst := range instances {
+			instance := *inst
+			instanceCopies[i] = &instance
+		}
+		services = append(services, &core.Service{
+			Name:      name,
+			Instances: instanceCopies,
+		})
+	}
+	return services, nil
+}
+
 // refreshLoop periodically refreshes services
 func (r *Registry) refreshLoop(ctx context.Context) {
+	defer r.wg.Done()
+
 	ticker := time.NewTicker(r.config.RefreshInterval)
 	defer ticker.Stop()
 
@@ -140,6 +194,8 @@ func (r *Registry) refreshLoop(ctx context.Context) {
 		case <-ticker.C:
 			if err := r.refresh(ctx); err != nil {
 				r.logger.Error("Failed to refresh services", "error", err)
+			} else {
+				r.markReady()
 			}
 		}
 	}
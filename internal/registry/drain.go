@@ -0,0 +1,166 @@
+package registry
+
+import (
+	"fmt"
+	"sync"
+
+	"gateway/internal/core"
+	"gateway/pkg/errors"
+)
+
+// DrainRegistry lets operators take instances out of load balancing without
+// changing their health. Drained instances are reported unhealthy to the
+// router, so balancers stop selecting them, while health checks keep using
+// the wrapped registry.
+//
+// A drain lasts while its instance is discovered. Once a lookup of the
+// instance's service no longer returns it, the drain is dropped, so a
+// replacement instance reusing the ID receives traffic again.
+type DrainRegistry struct {
+	registry core.ServiceRegistry
+	mu       sync.RWMutex
+	drained  map[string]string // instance ID -> service name
+}
+
+// NewDrainRegistry wraps registry with administrative drain support
+func NewDrainRegistry(registry core.ServiceRegistry) *DrainRegistry {
+	return &DrainRegistry{
+		registry: registry,
+		drained:  make(map[string]string),
+	}
+}
+
+// GetService returns instances with drained ones marked unhealthy
+func (r *DrainRegistry) GetService(name string) ([]core.ServiceInstance, error) {
+	instances, err := r.registry.GetService(name)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	if !r.drainsService(name) {
+		r.mu.RUnlock()
+		return instances, nil
+	}
+	r.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	present := make(map[string]bool, len(instances))
+	result := make([]core.ServiceInstance, len(instances))
+	copy(result, instances)
+	for i := range result {
+		present[result[i].ID] = true
+		if r.drained[result[i].ID] == name {
+			result[i].Healthy = false
+		}
+	}
+
+	// Forget drains of instances that are no longer discovered
+	for id, service := range r.drained {
+		if service == name && !present[id] {
+			delete(r.drained, id)
+		}
+	}
+
+	return result, nil
+}
+
+// drainsService reports whether any instance of the service is drained
+func (r *DrainRegistry) drainsService(name string) bool {
+	for _, service := range r.drained {
+		if service == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Drain stops routing traffic to the instance with the given ID
+func (r *DrainRegistry) Drain(id string) error {
+	service, err := r.findService(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.drained[id] = service
+	return nil
+}
+
+// Undrain resumes routing traffic to the instance with the given ID.
+// Undraining a known instance that is not drained does nothing.
+func (r *DrainRegistry) Undrain(id string) error {
+	if r.IsDrained(id) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.drained, id)
+		return nil
+	}
+
+	_, err := r.findService(id)
+	return err
+}
+
+// IsDrained reports whether the instance with the given ID is drained
+func (r *DrainRegistry) IsDrained(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.drained[id]
+	return ok
+}
+
+// Drained returns the drained instance IDs with their service names
+func (r *DrainRegistry) Drained() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	drained := make(map[string]string, len(r.drained))
+	for id, service := range r.drained {
+		drained[id] = service
+	}
+	return drained
+}
+
+// Restore drains the given instances, carrying drains over from a previous
+// registry on reload
+func (r *DrainRegistry) Restore(drained map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, service := range drained {
+		r.drained[id] = service
+	}
+}
+
+// findService returns the name of the service the instance belongs to
+func (r *DrainRegistry) findService(id string) (string, error) {
+	lister, ok := r.registry.(interface {
+		ListServices() ([]*core.Service, error)
+	})
+	if !ok {
+		return "", errors.NewError(errors.ErrorTypeBadRequest, "registry does not support listing instances")
+	}
+
+	services, err := lister.ListServices()
+	if err != nil {
+		return "", errors.NewError(errors.ErrorTypeInternal, "failed to list services").WithCause(err)
+	}
+	for _, svc := range services {
+		for _, inst := range svc.Instances {
+			if inst.ID == id {
+				return svc.Name, nil
+			}
+		}
+	}
+	return "", errors.NewError(errors.ErrorTypeNotFound, fmt.Sprintf("instance %s not found", id))
+}
+
+// Close closes the underlying registry if it supports it
+func (r *DrainRegistry) Close() error {
+	if closer, ok := r.registry.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
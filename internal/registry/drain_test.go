@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"testing"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+	"gateway/internal/registry/static"
+	"gateway/pkg/errors"
+)
+
+func TestDrainRegistry(t *testing.T) {
+	services := []config.Service{{
+		Name: "users",
+		Instances: []config.Instance{
+			{ID: "users-1", Address: "127.0.0.1", Port: 8001, Health: "healthy"},
+			{ID: "users-2", Address: "127.0.0.1", Port: 8002, Health: "healthy"},
+		},
+	}}
+	backing, err := static.NewRegistry(&config.StaticRegistry{Services: services})
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	r := NewDrainRegistry(backing)
+
+	if err := r.Drain("unknown"); errors.HTTPStatus(err) != 404 {
+		t.Errorf("Expected not found draining unknown instance, got %v", err)
+	}
+	if err := r.Undrain("unknown"); errors.HTTPStatus(err) != 404 {
+		t.Errorf("Expected not found undraining unknown instance, got %v", err)
+	}
+	if err := r.Undrain("users-1"); err != nil {
+		t.Errorf("Expected undraining an instance that is not drained to succeed, got %v", err)
+	}
+
+	if err := r.Drain("users-2"); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if !r.IsDrained("users-2") {
+		t.Error("Expected users-2 to be drained")
+	}
+
+	instances, err := r.GetService("users")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if !instances[0].Healthy || instances[1].Healthy {
+		t.Errorf("Expected only users-2 to be unhealthy, got %+v", instances)
+	}
+
+	// The wrapped registry keeps reporting the real health
+	instances, _ = backing.GetService("users")
+	if !instances[1].Healthy {
+		t.Error("Expected wrapped registry to be unaffected by drain")
+	}
+
+	if err := r.Undrain("users-2"); err != nil {
+		t.Fatalf("Undrain failed: %v", err)
+	}
+	instances, _ = r.GetService("users")
+	if !instances[1].Healthy {
+		t.Error("Expected users-2 to be healthy after undrain")
+	}
+}
+
+func TestDrainRegistry_ForgetsRemovedInstances(t *testing.T) {
+	present := true
+	backing := &mockListingRegistry{present: &present}
+	r := NewDrainRegistry(backing)
+
+	if err := r.Drain("users-1"); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+
+	// A refresh drops the instance, then a replacement reuses its ID
+	present = false
+	if _, err := r.GetService("users"); err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	present = true
+
+	instances, _ := r.GetService("users")
+	if r.IsDrained("users-1") || !instances[0].Healthy {
+		t.Error("Expected drain to be dropped once the instance disappeared")
+	}
+}
+
+func TestDrainRegistry_Restore(t *testing.T) {
+	present := true
+	previous := NewDrainRegistry(&mockListingRegistry{present: &present})
+	if err := previous.Drain("users-1"); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+
+	r := NewDrainRegistry(&mockListingRegistry{present: &present})
+	r.Restore(previous.Drained())
+
+	instances, _ := r.GetService("users")
+	if instances[0].Healthy {
+		t.Error("Expected restored drain to apply")
+	}
+}
+
+// mockListingRegistry serves one users instance while present is true
+type mockListingRegistry struct {
+	present *bool
+}
+
+func (m *mockListingRegistry) GetService(name string) ([]core.ServiceInstance, error) {
+	if !*m.present {
+		return []core.ServiceInstance{}, nil
+	}
+	return []core.ServiceInstance{{ID: "users-1", Name: "users", Healthy: true}}, nil
+}
+
+func (m *mockListingRegistry) ListServices() ([]*core.Service, error) {
+	instances, _ := m.GetService("users")
+	service := &core.Service{Name: "users"}
+	for i := range instances {
+		service.Instances = append(service.Instances, &instances[i])
+	}
+	return []*core.Service{service}, nil
+}
@@ -6,9 +6,12 @@ import (
 	
 	"gateway/internal/config"
 	"gateway/internal/core"
+	"gateway/internal/registry/dns"
 	"gateway/internal/registry/docker"
 	"gateway/internal/registry/dockercompose"
+	httpRegistry "gateway/internal/registry/http"
 	"gateway/internal/registry/static"
+	"gateway/internal/registry/swarm"
 	"gateway/pkg/factory"
 )
 
@@ -83,6 +86,39 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 		c.registry = component.(*dockercompose.Component).Build()
 		c.lifecycle = component.(factory.Lifecycle)
 		
+	case "dns":
+		component := dns.NewComponent(c.logger)
+		if err := component.Init(configParser(registryConfig.DNS)); err != nil {
+			return fmt.Errorf("init dns registry: %w", err)
+		}
+		if err := component.Validate(); err != nil {
+			return fmt.Errorf("validate dns registry: %w", err)
+		}
+		c.registry = component.(*dns.Component).Build()
+		c.lifecycle = component.(factory.Lifecycle)
+		
+	case "swarm":
+		component := swarm.NewComponent(c.logger)
+		if err := component.Init(configParser(registryConfig.Swarm)); err != nil {
+			return fmt.Errorf("init swarm registry: %w", err)
+		}
+		if err := component.Validate(); err != nil {
+			return fmt.Errorf("validate swarm registry: %w", err)
+		}
+		c.registry = component.(*swarm.Component).Build()
+		c.lifecycle = component.(factory.Lifecycle)
+		
+	case "http":
+		component := httpRegistry.NewComponent(c.logger)
+		if err := component.Init(configParser(registryConfig.HTTP)); err != nil {
+			return fmt.Errorf("init http registry: %w", err)
+		}
+		if err := component.Validate(); err != nil {
+			return fmt.Errorf("validate http registry: %w", err)
+		}
+		c.registry = component.(*httpRegistry.Component).Build()
+		c.lifecycle = component.(factory.Lifecycle)
+		
 	default:
 		return fmt.Errorf("unknown registry type: %s", c.registryType)
 	}
@@ -145,6 +181,24 @@ func configParser(cfg interface{}) factory.ConfigParser {
 				return nil
 			}
 			return fmt.Errorf("invalid docker-compose registry config")
+		case *config.DNSRegistry:
+			if src, ok := cfg.(*config.DNSRegistry); ok && src != nil {
+				*target = *src
+				return nil
+			}
+			return fmt.Errorf("invalid dns registry config")
+		case *config.SwarmRegistry:
+			if src, ok := cfg.(*config.SwarmRegistry); ok && src != nil {
+				*target = *src
+				return nil
+			}
+			return fmt.Errorf("invalid swarm registry config")
+		case *config.HTTPRegistry:
+			if src, ok := cfg.(*config.HTTPRegistry); ok && src != nil {
+				*target = *src
+				return nil
+			}
+			return fmt.Errorf("invalid http registry config")
 		default:
 			return fmt.Errorf("unsupported config type: %T", v)
 		}
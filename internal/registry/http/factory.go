@@ -0,0 +1,87 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+	"gateway/pkg/factory"
+)
+
+// ComponentName is the name used to register this component
+const ComponentName = "http-registry"
+
+// Component implements factory.Component for the HTTP catalog registry
+type Component struct {
+	config   *config.HTTPRegistry
+	registry *Registry
+	logger   *slog.Logger
+}
+
+// NewComponent creates a new HTTP registry component
+func NewComponent(logger *slog.Logger) factory.Component {
+	return &Component{
+		logger: logger,
+	}
+}
+
+// Name returns the component name
+func (c *Component) Name() string {
+	return ComponentName
+}
+
+// Init initializes the component with configuration
+func (c *Component) Init(parser factory.ConfigParser) error {
+	// Parse the HTTP registry configuration
+	var httpConfig config.HTTPRegistry
+	if err := parser(&httpConfig); err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+	c.config = &httpConfig
+
+	// Create registry
+	registry, err := NewRegistry(&httpConfig, c.logger)
+	if err != nil {
+		return fmt.Errorf("create HTTP registry: %w", err)
+	}
+	c.registry = registry
+
+	return nil
+}
+
+// Validate validates the component state
+func (c *Component) Validate() error {
+	if c.registry == nil {
+		return fmt.Errorf("HTTP registry not initialized")
+	}
+	return nil
+}
+
+// Build returns the registry
+func (c *Component) Build() core.ServiceRegistry {
+	if c.registry == nil {
+		panic("Component not initialized")
+	}
+	return c.registry
+}
+
+// Start starts the registry (implements Lifecycle)
+func (c *Component) Start() error {
+	// HTTP registry starts automatically in NewRegistry
+	return nil
+}
+
+// Stop stops the registry (implements Lifecycle)
+func (c *Component) Stop() error {
+	if c.registry == nil {
+		return nil
+	}
+	return c.registry.Close()
+}
+
+// Ensure Component implements factory.Component and factory.Lifecycle
+var (
+	_ factory.Component = (*Component)(nil)
+	_ factory.Lifecycle = (*Component)(nil)
+)
@@ -0,0 +1,399 @@
+// Package http discovers services from a catalog endpoint returning
+// services and their instances as JSON
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+	"gateway/pkg/errors"
+)
+
+// Default poll interval and request timeout
+const (
+	DefaultRefreshInterval = 10 // seconds
+	DefaultTimeout         = 5  // seconds
+)
+
+// maxCatalogSize bounds the catalog response read
+const maxCatalogSize = 10 << 20
+
+// Registry implements service discovery by polling a catalog URL. When a
+// poll fails, the last known services are kept.
+type Registry struct {
+	config   config.HTTPRegistry
+	mapping  config.HTTPRegistryMapping
+	client   *http.Client
+	services map[string][]core.ServiceInstance
+	mu       sync.RWMutex
+	logger   *slog.Logger
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+	ready    chan struct{} // Closed after the first successful poll
+	once     sync.Once
+}
+
+// NewRegistry creates an HTTP registry, polls the catalog once and starts
+// the refresh loop
+func NewRegistry(cfg *config.HTTPRegistry, logger *slog.Logger) (*Registry, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("http registry config is required")
+	}
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("catalog url is required")
+	}
+
+	r := &Registry{
+		config:   *cfg,
+		mapping:  withDefaults(cfg.Mapping),
+		services: make(map[string][]core.ServiceInstance),
+		logger:   logger.With("component", "http-registry"),
+		stopCh:   make(chan struct{}),
+		ready:    make(chan struct{}),
+	}
+	if r.config.RefreshInterval <= 0 {
+		r.config.RefreshInterval = DefaultRefreshInterval
+	}
+	if r.config.Timeout <= 0 {
+		r.config.Timeout = DefaultTimeout
+	}
+	r.client = &http.Client{Timeout: time.Duration(r.config.Timeout) * time.Second}
+
+	// Initial discovery; failures are retried by the refresh loop
+	if err := r.refresh(); err != nil {
+		r.logger.Error("Initial service discovery failed", "url", r.config.URL, "error", err)
+	}
+
+	r.wg.Add(1)
+	go r.refreshLoop()
+
+	return r, nil
+}
+
+// withDefaults fills in the default field names of a mapping
+func withDefaults(m config.HTTPRegistryMapping) config.HTTPRegistryMapping {
+	defaults := []struct {
+		field *string
+		value string
+	}{
+		{&m.Services, "services"},
+		{&m.Name, "name"},
+		{&m.Instances, "instances"},
+		{&m.ID, "id"},
+		{&m.Address, "address"},
+		{&m.Port, "port"},
+		{&m.Scheme, "scheme"},
+		{&m.Healthy, "healthy"},
+		{&m.Weight, "weight"},
+		{&m.Metadata, "metadata"},
+	}
+	for _, d := range defaults {
+		if *d.field == "" {
+			*d.field = d.value
+		}
+	}
+	return m
+}
+
+// GetService returns instances for a service
+func (r *Registry) GetService(name string) ([]core.ServiceInstance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	instances, ok := r.services[name]
+	if !ok {
+		return nil, errors.NewError(errors.ErrorTypeNotFound, fmt.Sprintf("service %s not found", name))
+	}
+
+	// Return a copy to avoid race conditions
+	result := make([]core.ServiceInstance, len(instances))
+	copy(result, instances)
+
+	return result, nil
+}
+
+// ListServices returns all discovered services
+func (r *Registry) ListServices() ([]*core.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	services := make([]*core.Service, 0, len(r.services))
+	for name, instances := range r.services {
+		instancePtrs := make([]*core.ServiceInstance, len(instances))
+		for i := range instances {
+			instance := instances[i]
+			instancePtrs[i] = &instance
+		}
+		services = append(services, &core.Service{
+			Name:      name,
+			Instances: instancePtrs,
+		})
+	}
+	return services, nil
+}
+
+// refresh polls the catalog and replaces the known services
+func (r *Registry) refresh() error {
+	catalog, err := r.fetch()
+	if err != nil {
+		return err
+	}
+	services, err := r.parse(catalog)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.services = services
+	r.mu.Unlock()
+
+	r.logger.Debug("Refreshed services", "services", len(services))
+	r.once.Do(func() { close(r.ready) })
+	return nil
+}
+
+// fetch requests the catalog and decodes its JSON body
+func (r *Registry) fetch() (any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.config.Timeout)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.config.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, value := range r.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request catalog: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("catalog returned status %d", resp.StatusCode)
+	}
+
+	var catalog any
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxCatalogSize)).Decode(&catalog); err != nil {
+		return nil, fmt.Errorf("decode catalog: %w", err)
+	}
+	return catalog, nil
+}
+
+// parse maps a decoded catalog to service instances. Services are either an
+// array of objects carrying their name, or an object keyed by service name
+// whose values are service objects or instance arrays.
+func (r *Registry) parse(catalog any) (map[string][]core.ServiceInstance, error) {
+	value, ok := lookup(catalog, r.mapping.Services)
+	if !ok {
+		return nil, fmt.Errorf("catalog has no %q", r.mapping.Services)
+	}
+
+	services := make(map[string][]core.ServiceInstance)
+	add := func(name string, service any) {
+		entries, ok := service.([]any)
+		if !ok {
+			value, _ := lookup(service, r.mapping.Instances)
+			entries, _ = value.([]any)
+		}
+		instances := make([]core.ServiceInstance, 0, len(entries))
+		for _, entry := range entries {
+			instance, err := r.instance(name, entry)
+			if err != nil {
+				r.logger.Warn("Skipping catalog instance", "service", name, "error", err)
+				continue
+			}
+			instances = append(instances, instance)
+		}
+		if len(instances) > 0 {
+			services[name] = append(services[name], instances...)
+		}
+	}
+
+	switch value := value.(type) {
+	case []any:
+		for _, service := range value {
+			name, ok := stringField(service, r.mapping.Name)
+			if !ok || name == "" {
+				r.logger.Warn("Skipping catalog service without a name")
+				continue
+			}
+			add(name, service)
+		}
+	case map[string]any:
+		for name, service := range value {
+			add(name, service)
+		}
+	default:
+		return nil, fmt.Errorf("catalog %q is neither an array nor an object", r.mapping.Services)
+	}
+	return services, nil
+}
+
+// instance maps a catalog entry to a service instance
+func (r *Registry) instance(service string, entry any) (core.ServiceInstance, error) {
+	address, ok := stringField(entry, r.mapping.Address)
+	if !ok || address == "" {
+		return core.ServiceInstance{}, fmt.Errorf("missing %q", r.mapping.Address)
+	}
+
+	port, ok := intField(entry, r.mapping.Port)
+	if !ok {
+		// The port may be part of the address
+		host, portStr, err := net.SplitHostPort(address)
+		if err != nil {
+			return core.ServiceInstance{}, fmt.Errorf("missing %q", r.mapping.Port)
+		}
+		if port, err = strconv.Atoi(portStr); err != nil {
+			return core.ServiceInstance{}, fmt.Errorf("invalid port in address %q", address)
+		}
+		address = host
+	}
+	if port <= 0 || port > 65535 {
+		return core.ServiceInstance{}, fmt.Errorf("invalid port %d", port)
+	}
+
+	instance := core.ServiceInstance{
+		Name:     service,
+		Address:  address,
+		Port:     port,
+		Scheme:   "http",
+		Healthy:  true,
+		Metadata: make(map[string]any),
+	}
+	if id, ok := stringField(entry, r.mapping.ID); ok && id != "" {
+		instance.ID = id
+	} else {
+		instance.ID = net.JoinHostPort(address, strconv.Itoa(port))
+	}
+	if scheme, ok := stringField(entry, r.mapping.Scheme); ok && scheme != "" {
+		instance.Scheme = scheme
+	}
+	if healthy, ok := lookup(entry, r.mapping.Healthy); ok {
+		instance.Healthy = isHealthy(healthy)
+	}
+	if metadata, ok := lookup(entry, r.mapping.Metadata); ok {
+		if metadata, ok := metadata.(map[string]any); ok {
+			for k, v := range metadata {
+				instance.Metadata[k] = v
+			}
+		}
+	}
+	if weight, ok := intField(entry, r.mapping.Weight); ok {
+		instance.Metadata["weight"] = weight
+	}
+	return instance, nil
+}
+
+// lookup follows a dot-separated path of object keys. An optional "$."
+// prefix is ignored and "$" is the value itself.
+func lookup(value any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return value, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// stringField returns a string or number at path as a string
+func stringField(value any, path string) (string, bool) {
+	value, ok := lookup(value, path)
+	if !ok {
+		return "", false
+	}
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+// intField returns a number, or a string holding one, at path
+func intField(value any, path string) (int, bool) {
+	value, ok := lookup(value, path)
+	if !ok {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case float64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// isHealthy interprets a health field, either a boolean or a status such
+// as "passing", "healthy" or "up"
+func isHealthy(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "passing", "healthy", "up", "ok":
+			return true
+		}
+	}
+	return false
+}
+
+// refreshLoop polls the catalog periodically
+func (r *Registry) refreshLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(r.config.RefreshInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.refresh(); err != nil {
+				// Keep the last known services until the catalog recovers
+				r.logger.Error("Service refresh failed", "url", r.config.URL, "error", err)
+			}
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// Ready returns a channel closed after the first successful poll
+func (r *Registry) Ready() <-chan struct{} {
+	return r.ready
+}
+
+// Close stops the registry
+func (r *Registry) Close() error {
+	close(r.stopCh)
+	r.wg.Wait()
+	return nil
+}
@@ -0,0 +1,199 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"gateway/internal/config"
+)
+
+// fakeCatalog serves a JSON catalog, or an error status when failing
+type fakeCatalog struct {
+	mu      sync.Mutex
+	body    string
+	failing bool
+	auth    string
+}
+
+func (c *fakeCatalog) set(body string, failing bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.body = body
+	c.failing = failing
+}
+
+func (c *fakeCatalog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.auth = r.Header.Get("Authorization")
+	if c.failing {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(c.body))
+}
+
+func newRegistry(t *testing.T, catalog *fakeCatalog, mapping config.HTTPRegistryMapping) *Registry {
+	t.Helper()
+
+	server := httptest.NewServer(catalog)
+	t.Cleanup(server.Close)
+
+	r, err := NewRegistry(&config.HTTPRegistry{
+		URL:             server.URL,
+		Headers:         map[string]string{"Authorization": "Bearer token"},
+		RefreshInterval: 3600,
+		Mapping:         mapping,
+	}, slog.Default())
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestRegistry_DefaultMapping(t *testing.T) {
+	catalog := &fakeCatalog{body: `{"services": [
+		{"name": "users", "instances": [
+			{"id": "users-1", "address": "10.0.0.1", "port": 8080, "weight": 3, "metadata": {"version": "v2"}},
+			{"address": "10.0.0.2:8081", "scheme": "https", "healthy": false},
+			{"port": 8080}
+		]},
+		{"instances": [{"address": "10.0.0.9", "port": 80}]}
+	]}`}
+	r := newRegistry(t, catalog, config.HTTPRegistryMapping{})
+
+	select {
+	case <-r.Ready():
+	default:
+		t.Fatal("Expected the registry to be ready after the first poll")
+	}
+	catalog.mu.Lock()
+	if catalog.auth != "Bearer token" {
+		t.Errorf("Expected configured headers to be sent, got Authorization %q", catalog.auth)
+	}
+	catalog.mu.Unlock()
+
+	instances, err := r.GetService("users")
+	if err != nil {
+		t.Fatalf("GetService() error = %v", err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("Expected 2 instances, got %d", len(instances))
+	}
+	first, second := instances[0], instances[1]
+	if first.ID != "users-1" || first.Address != "10.0.0.1" || first.Port != 8080 || first.Scheme != "http" || !first.Healthy {
+		t.Errorf("Unexpected first instance %+v", first)
+	}
+	if first.Metadata["weight"] != 3 || first.Metadata["version"] != "v2" {
+		t.Errorf("Unexpected first instance metadata %v", first.Metadata)
+	}
+	if second.ID != "10.0.0.2:8081" || second.Address != "10.0.0.2" || second.Port != 8081 || second.Scheme != "https" || second.Healthy {
+		t.Errorf("Unexpected second instance %+v", second)
+	}
+
+	services, _ := r.ListServices()
+	if len(services) != 1 {
+		t.Errorf("Expected the unnamed service to be skipped, got %d services", len(services))
+	}
+}
+
+func TestRegistry_CustomMapping(t *testing.T) {
+	catalog := &fakeCatalog{body: `{"data": {"catalog": {
+		"orders": {"nodes": [{"host": {"ip": "10.0.1.1", "port": "9000"}, "status": "passing"}]},
+		"billing": {"nodes": [{"host": {"ip": "10.0.1.2", "port": 9001}, "status": "critical"}]}
+	}}}`}
+	r := newRegistry(t, catalog, config.HTTPRegistryMapping{
+		Services:  "$.data.catalog",
+		Instances: "nodes",
+		Address:   "host.ip",
+		Port:      "host.port",
+		Healthy:   "status",
+	})
+
+	orders, err := r.GetService("orders")
+	if err != nil {
+		t.Fatalf("GetService() error = %v", err)
+	}
+	if len(orders) != 1 || orders[0].Address != "10.0.1.1" || orders[0].Port != 9000 || !orders[0].Healthy {
+		t.Errorf("Unexpected orders instances %+v", orders)
+	}
+	billing, err := r.GetService("billing")
+	if err != nil {
+		t.Fatalf("GetService() error = %v", err)
+	}
+	if len(billing) != 1 || billing[0].Healthy {
+		t.Errorf("Expected the billing instance to be unhealthy, got %+v", billing)
+	}
+}
+
+func TestRegistry_InstanceArrays(t *testing.T) {
+	catalog := &fakeCatalog{body: `{"users": [{"address": "10.0.0.1", "port": 8080}]}`}
+	r := newRegistry(t, catalog, config.HTTPRegistryMapping{Services: "$"})
+
+	instances, err := r.GetService("users")
+	if err != nil {
+		t.Fatalf("GetService() error = %v", err)
+	}
+	if len(instances) != 1 || instances[0].Port != 8080 {
+		t.Errorf("Unexpected instances %+v", instances)
+	}
+}
+
+func TestRegistry_KeepsLastKnownServices(t *testing.T) {
+	catalog := &fakeCatalog{body: `{"services": [{"name": "users", "instances": [{"address": "10.0.0.1", "port": 8080}]}]}`}
+	r := newRegistry(t, catalog, config.HTTPRegistryMapping{})
+
+	failures := []struct {
+		name    string
+		body    string
+		failing bool
+	}{
+		{"error status", "", true},
+		{"invalid JSON", "{", false},
+		{"missing services", `{"items": []}`, false},
+	}
+	for _, failure := range failures {
+		catalog.set(failure.body, failure.failing)
+		if err := r.refresh(); err == nil {
+			t.Errorf("%s: expected refresh to fail", failure.name)
+		}
+		if instances, err := r.GetService("users"); err != nil || len(instances) != 1 {
+			t.Errorf("%s: expected the last known instances, got %v, %v", failure.name, instances, err)
+		}
+	}
+
+	// A successful poll replaces the services
+	catalog.set(`{"services": []}`, false)
+	if err := r.refresh(); err != nil {
+		t.Fatalf("refresh() error = %v", err)
+	}
+	if _, err := r.GetService("users"); err == nil {
+		t.Error("Expected the removed service to be gone")
+	}
+}
+
+func TestRegistry_InitialFailure(t *testing.T) {
+	catalog := &fakeCatalog{failing: true}
+	r := newRegistry(t, catalog, config.HTTPRegistryMapping{})
+
+	select {
+	case <-r.Ready():
+		t.Fatal("Expected the registry not to be ready")
+	default:
+	}
+
+	catalog.set(`{"services": [{"name": "users", "instances": [{"address": "10.0.0.1", "port": 8080}]}]}`, false)
+	if err := r.refresh(); err != nil {
+		t.Fatalf("refresh() error = %v", err)
+	}
+	select {
+	case <-r.Ready():
+	default:
+		t.Fatal("Expected the registry to be ready after a successful poll")
+	}
+}
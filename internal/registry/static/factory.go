@@ -33,6 +33,22 @@ func (c *Component) Name() string {
 
 // Init initializes the component with configuration
 func (c *Component) Init(parser factory.ConfigParser) error {
+	if err := c.init(parser); err != nil {
+		return err
+	}
+	
+	// Reload services from their file when it changes
+	if c.config.File != "" {
+		if err := c.registry.WatchFile(c.config.File, c.logger); err != nil {
+			return fmt.Errorf("watch services file: %w", err)
+		}
+	}
+	
+	return nil
+}
+
+// init parses the configuration and creates the registry
+func (c *Component) init(parser factory.ConfigParser) error {
 	// Parse the static registry configuration
 	var staticConfig config.StaticRegistry
 	if err := parser(&staticConfig); err != nil {
@@ -57,6 +73,11 @@ func (c *Component) Validate() error {
 		return fmt.Errorf("static registry not initialized")
 	}
 	
+	// Services of a watched file may be added at any time
+	if c.config != nil && c.config.File != "" {
+		return nil
+	}
+	
 	// Validate that at least one service is configured
 	if c.config == nil || len(c.config.Services) == 0 {
 		return fmt.Errorf("no services configured")
@@ -108,7 +129,7 @@ func (c *HealthAwareComponent) Name() string {
 // Init initializes the health-aware component
 func (c *HealthAwareComponent) Init(parser factory.ConfigParser) error {
 	// First initialize the base component
-	if err := c.Component.Init(parser); err != nil {
+	if err := c.Component.init(parser); err != nil {
 		return err
 	}
 	
@@ -119,6 +140,13 @@ func (c *HealthAwareComponent) Init(parser factory.ConfigParser) error {
 	}
 	c.healthAwareRegistry = healthAware
 	
+	// Reload services from their file when it changes
+	if c.config.File != "" {
+		if err := healthAware.WatchFile(c.config.File, c.logger); err != nil {
+			return fmt.Errorf("watch services file: %w", err)
+		}
+	}
+	
 	return nil
 }
 
@@ -2,6 +2,7 @@ package static
 
 import (
 	"fmt"
+	"log/slog"
 	"sync"
 	
 	"gateway/internal/config"
@@ -11,7 +12,9 @@ import (
 // HealthAwareRegistry provides static service discovery with health status updates
 type HealthAwareRegistry struct {
 	services map[string]map[string]*core.ServiceInstance // service -> instanceID -> instance
+	order    map[string][]string                         // service -> instance IDs in config order
 	mu       sync.RWMutex
+	watcher  *fileWatcher // Set when services are read from a watched file
 }
 
 // NewHealthAwareRegistry creates a health-aware static registry from config
@@ -20,46 +23,70 @@ func NewHealthAwareRegistry(cfg *config.StaticRegistry) (*HealthAwareRegistry, e
 		return nil, fmt.Errorf("static registry config is nil")
 	}
 
-	r := &HealthAwareRegistry{
-		services: make(map[string]map[string]*core.ServiceInstance),
-	}
+	r := &HealthAwareRegistry{}
+	r.replace(cfg.Services)
+
+	return r, nil
+}
+
+// replace swaps in the instances of services. Instances that keep their
+// ID, address and port keep their current health, so a reload does not
+// undo what health checks found.
+func (r *HealthAwareRegistry) replace(services []config.Service) {
+	nextServices := make(map[string]map[string]*core.ServiceInstance, len(services))
+	nextOrder := make(map[string][]string, len(services))
 
-	// Load services from config
-	for _, svc := range cfg.Services {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, svc := range services {
 		instanceMap := make(map[string]*core.ServiceInstance)
 		for _, inst := range svc.Instances {
 			instance := inst.ToServiceInstance(svc.Name)
+			if current, ok := r.services[svc.Name][instance.ID]; ok &&
+				current.Address == instance.Address && current.Port == instance.Port {
+				instance.Healthy = current.Healthy
+			}
 			instanceMap[instance.ID] = &instance
+			nextOrder[svc.Name] = append(nextOrder[svc.Name], instance.ID)
 		}
-		r.services[svc.Name] = instanceMap
+		nextServices[svc.Name] = instanceMap
 	}
 
-	return r, nil
+	r.services = nextServices
+	r.order = nextOrder
 }
 
-// GetService returns healthy instances for a service
-func (r *HealthAwareRegistry) GetService(name string) ([]core.ServiceInstance, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	
-	instanceMap, ok := r.services[name]
-	if !ok {
-		return nil, fmt.Errorf("service not found: %s", name)
-	}
-	
-	// Return only healthy instances
-	instances := make([]core.ServiceInstance, 0, len(instanceMap))
-	for _, inst := range instanceMap {
-		if inst.Healthy {
-			instances = append(instances, *inst)
-		}
+// WatchFile replaces the services with those of a services file and
+// reloads them whenever the file changes
+func (r *HealthAwareRegistry) WatchFile(path string, logger *slog.Logger) error {
+	watcher, err := watchServicesFile(path, r.replace, logger)
+	if err != nil {
+		return err
 	}
-	
-	if len(instances) == 0 {
-		return nil, fmt.Errorf("no healthy instances for service: %s", name)
+	r.mu.Lock()
+	r.watcher = watcher
+	r.mu.Unlock()
+	return nil
+}
+
+// Close stops watching the services file, if any
+func (r *HealthAwareRegistry) Close() error {
+	r.mu.RLock()
+	watcher := r.watcher
+	r.mu.RUnlock()
+
+	if watcher == nil {
+		return nil
 	}
-	
-	return instances, nil
+	return watcher.Close()
+}
+
+// GetService returns the instances of a service with their current health.
+// Unhealthy instances are kept so they can still be health checked; load
+// balancers skip them.
+func (r *HealthAwareRegistry) GetService(name string) ([]core.ServiceInstance, error) {
+	return r.GetAllInstances(name)
 }
 
 // GetAllInstances returns all instances (healthy and unhealthy) for a service
@@ -72,14 +99,35 @@ func (r *HealthAwareRegistry) GetAllInstances(name string) ([]core.ServiceInstan
 		return nil, fmt.Errorf("service not found: %s", name)
 	}
 	
+	// Preserve config order so round-robin balancing stays stable
 	instances := make([]core.ServiceInstance, 0, len(instanceMap))
-	for _, inst := range instanceMap {
-		instances = append(instances, *inst)
+	for _, id := range r.order[name] {
+		instances = append(instances, *instanceMap[id])
 	}
 	
 	return instances, nil
 }
 
+// ListServices returns all services with the current health of their instances
+func (r *HealthAwareRegistry) ListServices() ([]*core.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	services := make([]*core.Service, 0, len(r.services))
+	for name, instanceMap := range r.services {
+		instances := make([]*core.ServiceInstance, 0, len(instanceMap))
+		for _, id := range r.order[name] {
+			instance := *instanceMap[id]
+			instances = append(instances, &instance)
+		}
+		services = append(services, &core.Service{
+			Name:      name,
+			Instances: instances,
+		})
+	}
+	return services, nil
+}
+
 // UpdateInstanceHealth updates the health status of an instance
 func (r *HealthAwareRegistry) UpdateInstanceHealth(serviceName, instanceID string, healthy bool) error {
 	r.mu.Lock()
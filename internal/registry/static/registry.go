@@ -2,6 +2,9 @@ package static
 
 import (
 	"fmt"
+	"log/slog"
+	"sync"
+
 	"gateway/internal/config"
 	"gateway/internal/core"
 )
@@ -9,6 +12,8 @@ import (
 // Registry provides static service discovery
 type Registry struct {
 	services map[string][]core.ServiceInstance
+	mu       sync.RWMutex
+	watcher  *fileWatcher // Set when services are read from a watched file
 }
 
 // NewRegistry creates a static registry from config
@@ -17,27 +22,82 @@ func NewRegistry(cfg *config.StaticRegistry) (*Registry, error) {
 		return nil, fmt.Errorf("static registry config is nil")
 	}
 
-	r := &Registry{
-		services: make(map[string][]core.ServiceInstance),
-	}
+	r := &Registry{}
+	r.replace(cfg.Services)
+
+	return r, nil
+}
 
-	// Load services from config
-	for _, svc := range cfg.Services {
+// replace swaps in the instances of services, so that lookups see either
+// the previous or the new set
+func (r *Registry) replace(services []config.Service) {
+	next := make(map[string][]core.ServiceInstance, len(services))
+	for _, svc := range services {
 		instances := make([]core.ServiceInstance, 0, len(svc.Instances))
 		for _, inst := range svc.Instances {
 			instances = append(instances, inst.ToServiceInstance(svc.Name))
 		}
-		r.services[svc.Name] = instances
+		next[svc.Name] = instances
 	}
 
-	return r, nil
+	r.mu.Lock()
+	r.services = next
+	r.mu.Unlock()
+}
+
+// WatchFile replaces the services with those of a services file and
+// reloads them whenever the file changes
+func (r *Registry) WatchFile(path string, logger *slog.Logger) error {
+	watcher, err := watchServicesFile(path, r.replace, logger)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.watcher = watcher
+	r.mu.Unlock()
+	return nil
 }
 
 // GetService returns instances for a service
 func (r *Registry) GetService(name string) ([]core.ServiceInstance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	instances, ok := r.services[name]
 	if !ok {
 		return nil, fmt.Errorf("service not found: %s", name)
 	}
 	return instances, nil
 }
+
+// ListServices returns all services
+func (r *Registry) ListServices() ([]*core.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	services := make([]*core.Service, 0, len(r.services))
+	for name, instances := range r.services {
+		instancePtrs := make([]*core.ServiceInstance, len(instances))
+		for i := range instances {
+			instance := instances[i]
+			instancePtrs[i] = &instance
+		}
+		services = append(services, &core.Service{
+			Name:      name,
+			Instances: instancePtrs,
+		})
+	}
+	return services, nil
+}
+
+// Close stops watching the services file, if any
+func (r *Registry) Close() error {
+	r.mu.RLock()
+	watcher := r.watcher
+	r.mu.RUnlock()
+
+	if watcher == nil {
+		return nil
+	}
+	return watcher.Close()
+}
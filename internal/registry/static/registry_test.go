@@ -2,6 +2,7 @@ package static
 
 import (
 	"gateway/internal/config"
+	"gateway/internal/core"
 	"testing"
 )
 
@@ -275,3 +276,52 @@ func TestRegistryConcurrentAccess(t *testing.T) {
 		// No errors, test passed
 	}
 }
+
+func TestHealthAwareRegistry(t *testing.T) {
+	registry, err := NewHealthAwareRegistry(&config.StaticRegistry{
+		Services: []config.Service{
+			{
+				Name: "users",
+				Instances: []config.Instance{
+					{ID: "users-1", Address: "127.0.0.1", Port: 8001, Health: "healthy"},
+					{ID: "users-2", Address: "127.0.0.1", Port: 8002, Health: "healthy"},
+					{ID: "users-3", Address: "127.0.0.1", Port: 8003, Health: "healthy"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHealthAwareRegistry failed: %v", err)
+	}
+
+	registry.RegisterHealthUpdateCallback()("users", &core.ServiceInstance{ID: "users-2"}, false)
+
+	// Unhealthy instances are kept, marked, and the config order is preserved
+	instances, err := registry.GetService("users")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if len(instances) != 3 {
+		t.Fatalf("Expected 3 instances, got %d", len(instances))
+	}
+	for i, id := range []string{"users-1", "users-2", "users-3"} {
+		if instances[i].ID != id {
+			t.Errorf("Expected instance %d to be %s, got %s", i, id, instances[i].ID)
+		}
+	}
+	if instances[1].Healthy || !instances[0].Healthy {
+		t.Errorf("Expected only users-2 to be unhealthy, got %+v", instances)
+	}
+
+	// Listed services report the updated health
+	services, err := registry.ListServices()
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	if len(services) != 1 || len(services[0].Instances) != 3 {
+		t.Fatalf("Expected 1 service with 3 instances, got %+v", services)
+	}
+	if services[0].Instances[1].Healthy || !services[0].Instances[0].Healthy {
+		t.Errorf("Expected only users-2 to be listed unhealthy")
+	}
+}
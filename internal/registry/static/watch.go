@@ -0,0 +1,190 @@
+package static
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gateway/internal/config"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+)
+
+// reloadDebounce groups the events of a single edit into one reload
+const reloadDebounce = 100 * time.Millisecond
+
+// LoadServicesFile reads the services listed in a static registry file,
+// which has the same services list as the static registry configuration
+func LoadServicesFile(path string) ([]config.Service, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseServices(data)
+}
+
+// parseServices decodes and checks the services of a services file
+func parseServices(data []byte) ([]config.Service, error) {
+	var file struct {
+		Services []config.Service `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse services: %w", err)
+	}
+
+	names := make(map[string]bool, len(file.Services))
+	for i, svc := range file.Services {
+		if svc.Name == "" {
+			return nil, fmt.Errorf("services[%d].name: is required", i)
+		}
+		if names[svc.Name] {
+			return nil, fmt.Errorf("services[%d].name: duplicate service %q", i, svc.Name)
+		}
+		names[svc.Name] = true
+	}
+	return file.Services, nil
+}
+
+// fileWatcher reloads a services file when it changes. The directory is
+// watched rather than the file, so that atomic renames and symlink swaps,
+// as done by editors and Kubernetes ConfigMap volumes, are seen too.
+type fileWatcher struct {
+	path     string
+	onChange func([]config.Service)
+	watcher  *fsnotify.Watcher
+	logger   *slog.Logger
+	last     []byte // Content of the last applied file
+	mu       sync.Mutex
+	timer    *time.Timer
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// watchServicesFile loads the services file at path, hands its services to
+// onChange and calls it again with the new services whenever it changes. A
+// file that cannot be loaded at start is an error; later, the services of
+// the last valid version are kept.
+func watchServicesFile(path string, onChange func([]config.Service), logger *slog.Logger) (*fileWatcher, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("read services file: %w", err)
+	}
+	services, err := parseServices(data)
+	if err != nil {
+		return nil, fmt.Errorf("services file %s: %w", path, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(absPath)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch services file: %w", err)
+	}
+
+	w := &fileWatcher{
+		path:     absPath,
+		onChange: onChange,
+		watcher:  watcher,
+		logger:   logger.With("component", "static-registry", "file", absPath),
+		last:     data,
+		stopCh:   make(chan struct{}),
+	}
+	onChange(services)
+
+	w.wg.Add(1)
+	go w.watchLoop()
+
+	return w, nil
+}
+
+// watchLoop schedules a reload for every change in the directory
+func (w *fileWatcher) watchLoop() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case _, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.scheduleReload()
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Error("File watcher error", "error", err)
+
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+// scheduleReload debounces reloads
+func (w *fileWatcher) scheduleReload() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(reloadDebounce, w.reload)
+}
+
+// reload applies the services file if its content changed and is valid
+func (w *fileWatcher) reload() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	select {
+	case <-w.stopCh:
+		return
+	default:
+	}
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		// Possibly mid-replace; a later event brings the new file
+		w.logger.Warn("Failed to read services file, keeping current services", "error", err)
+		return
+	}
+	if bytes.Equal(data, w.last) {
+		return
+	}
+	services, err := parseServices(data)
+	if err != nil {
+		w.logger.Error("Invalid services file, keeping current services", "error", err)
+		return
+	}
+
+	w.last = data
+	w.onChange(services)
+	w.logger.Info("Services reloaded", "services", len(services))
+}
+
+// Close stops watching the file
+func (w *fileWatcher) Close() error {
+	close(w.stopCh)
+	w.wg.Wait()
+
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+
+	return w.watcher.Close()
+}
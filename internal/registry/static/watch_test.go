@@ -0,0 +1,151 @@
+package static
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+)
+
+// writeServices replaces the services file atomically, as editors do
+func writeServices(t *testing.T, path, content string) {
+	t.Helper()
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitFor polls cond until it holds or a deadline passes
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the services to reload")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func instanceIDs(r core.ServiceRegistry, service string) []string {
+	instances, err := r.GetService(service)
+	if err != nil {
+		return nil
+	}
+	ids := make([]string, len(instances))
+	for i, instance := range instances {
+		ids[i] = instance.ID
+	}
+	return ids
+}
+
+const usersFile = `
+services:
+  - name: users
+    instances:
+      - id: users-1
+        address: 127.0.0.1
+        port: 9001
+        health: healthy
+`
+
+func TestRegistry_WatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "services.yaml")
+	writeServices(t, path, usersFile)
+
+	r, err := NewRegistry(&config.StaticRegistry{})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	if err := r.WatchFile(path, slog.Default()); err != nil {
+		t.Fatalf("WatchFile() error = %v", err)
+	}
+	defer r.Close()
+
+	if ids := instanceIDs(r, "users"); len(ids) != 1 || ids[0] != "users-1" {
+		t.Fatalf("Expected users-1 loaded from the file, got %v", ids)
+	}
+
+	// Instances and services are added and removed by editing the file
+	writeServices(t, path, `
+services:
+  - name: orders
+    instances:
+      - id: orders-1
+        address: 127.0.0.1
+        port: 9002
+      - id: orders-2
+        address: 127.0.0.1
+        port: 9003
+`)
+	waitFor(t, func() bool { return len(instanceIDs(r, "orders")) == 2 })
+	if _, err := r.GetService("users"); err == nil {
+		t.Error("Expected the removed service to be gone")
+	}
+
+	// An invalid file keeps the current services
+	writeServices(t, path, "services:\n  - instances: []\n")
+	time.Sleep(3 * reloadDebounce)
+	if ids := instanceIDs(r, "orders"); len(ids) != 2 {
+		t.Errorf("Expected the current services to be kept, got %v", ids)
+	}
+}
+
+func TestRegistry_WatchFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "services.yaml")
+	writeServices(t, path, "services:\n  - name: users\n  - name: users\n")
+
+	r, _ := NewRegistry(&config.StaticRegistry{})
+	if err := r.WatchFile(path, slog.Default()); err == nil {
+		r.Close()
+		t.Fatal("Expected duplicate services to be rejected")
+	}
+	if err := r.WatchFile(filepath.Join(t.TempDir(), "missing.yaml"), slog.Default()); err == nil {
+		r.Close()
+		t.Fatal("Expected a missing file to be rejected")
+	}
+}
+
+func TestHealthAwareRegistry_WatchFileKeepsHealth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "services.yaml")
+	writeServices(t, path, usersFile)
+
+	r, err := NewHealthAwareRegistry(&config.StaticRegistry{})
+	if err != nil {
+		t.Fatalf("NewHealthAwareRegistry() error = %v", err)
+	}
+	if err := r.WatchFile(path, slog.Default()); err != nil {
+		t.Fatalf("WatchFile() error = %v", err)
+	}
+	defer r.Close()
+
+	if err := r.UpdateInstanceHealth("users", "users-1", false); err != nil {
+		t.Fatalf("UpdateInstanceHealth() error = %v", err)
+	}
+
+	writeServices(t, path, usersFile+`
+      - id: users-2
+        address: 127.0.0.1
+        port: 9002
+        health: healthy
+`)
+	waitFor(t, func() bool { return len(instanceIDs(r, "users")) == 2 })
+
+	instances, _ := r.GetService("users")
+	if instances[0].ID != "users-1" || instances[0].Healthy {
+		t.Errorf("Expected users-1 to stay unhealthy, got %+v", instances[0])
+	}
+	if !instances[1].Healthy {
+		t.Errorf("Expected the added instance to be healthy, got %+v", instances[1])
+	}
+}
@@ -0,0 +1,114 @@
+package swarm
+
+import (
+	"fmt"
+	"log/slog"
+
+	"gateway/internal/config"
+	"gateway/internal/core"
+	"gateway/pkg/factory"
+)
+
+// ComponentName is the name used to register this component
+const ComponentName = "swarm-registry"
+
+// Component implements factory.Component for Docker Swarm registry
+type Component struct {
+	config   *Config
+	registry *Registry
+	logger   *slog.Logger
+}
+
+// NewComponent creates a new Docker Swarm registry component
+func NewComponent(logger *slog.Logger) factory.Component {
+	return &Component{
+		logger: logger,
+	}
+}
+
+// Name returns the component name
+func (c *Component) Name() string {
+	return ComponentName
+}
+
+// Init initializes the component with configuration
+func (c *Component) Init(parser factory.ConfigParser) error {
+	var swarmConfig config.SwarmRegistry
+	if err := parser(&swarmConfig); err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	c.config = &Config{
+		Host:            swarmConfig.Host,
+		Version:         swarmConfig.Version,
+		LabelPrefix:     swarmConfig.LabelPrefix,
+		Network:         swarmConfig.Network,
+		Resolve:         swarmConfig.Resolve,
+		RefreshInterval: swarmConfig.RefreshInterval,
+	}
+
+	// Set defaults
+	if c.config.Host == "" {
+		c.config.Host = "unix:///var/run/docker.sock"
+	}
+	if c.config.LabelPrefix == "" {
+		c.config.LabelPrefix = "gateway"
+	}
+	if c.config.Resolve == "" {
+		c.config.Resolve = ResolveVIP
+	}
+	if c.config.RefreshInterval == 0 {
+		c.config.RefreshInterval = 10
+	}
+
+	registry, err := NewRegistry(c.config, c.logger)
+	if err != nil {
+		return fmt.Errorf("create Swarm registry: %w", err)
+	}
+
+	c.registry = registry
+
+	return nil
+}
+
+// Validate validates the component state
+func (c *Component) Validate() error {
+	if c.registry == nil {
+		return fmt.Errorf("Swarm registry not initialized")
+	}
+	if c.config.RefreshInterval < 1 {
+		return fmt.Errorf("refresh interval too short: %d seconds", c.config.RefreshInterval)
+	}
+	if c.config.Resolve != ResolveVIP && c.config.Resolve != ResolveTasks {
+		return fmt.Errorf("unknown resolve mode: %s", c.config.Resolve)
+	}
+	return nil
+}
+
+// Build returns the registry
+func (c *Component) Build() core.ServiceRegistry {
+	if c.registry == nil {
+		panic("Component not initialized")
+	}
+	return c.registry
+}
+
+// Start starts the registry (implements Lifecycle)
+func (c *Component) Start() error {
+	// Swarm registry starts automatically in NewRegistry
+	return nil
+}
+
+// Stop stops the registry (implements Lifecycle)
+func (c *Component) Stop() error {
+	if c.registry == nil {
+		return nil
+	}
+	return c.registry.Close()
+}
+
+// Ensure Component implements factory.Component and factory.Lifecycle
+var (
+	_ factory.Component = (*Component)(nil)
+	_ factory.Lifecycle = (*Component)(nil)
+)
@@ -0,0 +1,437 @@
+// Package swarm discovers services deployed to a Docker Swarm from the
+// labels of Swarm services
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gateway/internal/core"
+	"gateway/internal/registry/docker"
+	"gateway/pkg/errors"
+)
+
+// Ways of resolving the instances of a Swarm service
+const (
+	// ResolveVIP routes to the virtual IP of the service, leaving load
+	// balancing across its tasks to Swarm
+	ResolveVIP = "vip"
+	// ResolveTasks routes to the IP of each running task
+	ResolveTasks = "tasks"
+)
+
+// Config represents Docker Swarm registry configuration
+type Config struct {
+	// Docker connection settings; Host must point at a manager node
+	Host    string
+	Version string
+
+	// Service discovery settings
+	LabelPrefix     string
+	Network         string // Network name or ID the gateway reaches services on
+	Resolve         string // ResolveVIP or ResolveTasks
+	RefreshInterval int    // Service refresh interval in seconds
+}
+
+// DefaultConfig returns default Docker Swarm registry configuration
+func DefaultConfig() *Config {
+	return &Config{
+		LabelPrefix:     "gateway",
+		Resolve:         ResolveVIP,
+		RefreshInterval: 10,
+	}
+}
+
+// Service represents a Swarm service
+type Service struct {
+	ID   string `json:"ID"`
+	Spec struct {
+		Name   string            `json:"Name"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Spec"`
+	Endpoint struct {
+		VirtualIPs []VirtualIP `json:"VirtualIPs"`
+	} `json:"Endpoint"`
+}
+
+// VirtualIP is the address of a Swarm service on one network
+type VirtualIP struct {
+	NetworkID string `json:"NetworkID"`
+	Addr      string `json:"Addr"` // CIDR notation
+}
+
+// Task represents a Swarm task
+type Task struct {
+	ID        string `json:"ID"`
+	ServiceID string `json:"ServiceID"`
+	Status    struct {
+		State string `json:"State"`
+	} `json:"Status"`
+	NetworksAttachments []NetworkAttachment `json:"NetworksAttachments"`
+}
+
+// NetworkAttachment holds the addresses of a task on one network
+type NetworkAttachment struct {
+	Network struct {
+		ID   string `json:"ID"`
+		Spec struct {
+			Name    string `json:"Name"`
+			Ingress bool   `json:"Ingress"`
+		} `json:"Spec"`
+	} `json:"Network"`
+	Addresses []string `json:"Addresses"` // CIDR notation
+}
+
+// Registry implements service discovery using the Docker Swarm API
+type Registry struct {
+	config     *Config
+	httpClient *http.Client
+	baseURL    string
+	services   map[string][]core.ServiceInstance
+	mu         sync.RWMutex
+	logger     *slog.Logger
+	stopCh     chan struct{}
+	wg         sync.WaitGroup
+	ready      chan struct{} // Closed after the first successful discovery
+	readyOnce  sync.Once
+}
+
+// NewRegistry creates a new Docker Swarm registry
+func NewRegistry(config *Config, logger *slog.Logger) (*Registry, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if config.LabelPrefix == "" {
+		config.LabelPrefix = "gateway"
+	}
+	if config.Resolve == "" {
+		config.Resolve = ResolveVIP
+	}
+
+	httpClient, baseURL := docker.NewHTTPClient(config.Host)
+	if config.Version != "" {
+		baseURL += "/v" + strings.TrimPrefix(config.Version, "v")
+	}
+
+	r := &Registry{
+		config:     config,
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		services:   make(map[string][]core.ServiceInstance),
+		logger:     logger.With("component", "swarm-registry"),
+		stopCh:     make(chan struct{}),
+		ready:      make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.get(ctx, "/_ping", nil); err != nil {
+		return nil, errors.NewError(errors.ErrorTypeUnavailable, "failed to connect to Docker daemon").WithCause(err)
+	}
+
+	if config.RefreshInterval > 0 {
+		r.wg.Add(1)
+		go r.refreshLoop()
+	}
+
+	if err := r.refresh(); err != nil {
+		r.logger.Error("Initial service discovery failed", "error", err)
+	} else {
+		r.markReady()
+	}
+
+	return r, nil
+}
+
+// get calls the Docker API at path and decodes the JSON response into out
+// unless it is nil
+func (r *Registry) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", r.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("docker API error: %d - %s", resp.StatusCode, string(body))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// filters encodes Docker API list filters as a query string
+func filters(f map[string][]string) string {
+	encoded, _ := json.Marshal(f)
+	return "?" + url.Values{"filters": {string(encoded)}}.Encode()
+}
+
+// GetService returns instances for a service
+func (r *Registry) GetService(name string) ([]core.ServiceInstance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	instances, ok := r.services[name]
+	if !ok {
+		return nil, errors.NewError(errors.ErrorTypeNotFound, fmt.Sprintf("service %s not found", name))
+	}
+
+	result := make([]core.ServiceInstance, len(instances))
+	copy(result, instances)
+
+	return result, nil
+}
+
+// ListServices returns all services
+func (r *Registry) ListServices() ([]*core.Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	services := make([]*core.Service, 0, len(r.services))
+	for name, instances := range r.services {
+		instancePtrs := make([]*core.ServiceInstance, len(instances))
+		for i := range instances {
+			instancePtrs[i] = &instances[i]
+		}
+		services = append(services, &core.Service{
+			Name:      name,
+			Instances: instancePtrs,
+			Metadata:  make(map[string]string),
+		})
+	}
+
+	return services, nil
+}
+
+// refresh discovers services from the Swarm
+func (r *Registry) refresh() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var swarmServices []Service
+	if err := r.get(ctx, "/services"+filters(map[string][]string{
+		"label": {r.config.LabelPrefix + ".service"},
+	}), &swarmServices); err != nil {
+		return errors.NewError(errors.ErrorTypeUnavailable, "failed to list swarm services").WithCause(err)
+	}
+
+	var tasks []Task
+	if err := r.get(ctx, "/tasks"+filters(map[string][]string{
+		"desired-state": {"running"},
+	}), &tasks); err != nil {
+		return errors.NewError(errors.ErrorTypeUnavailable, "failed to list swarm tasks").WithCause(err)
+	}
+
+	running := make(map[string][]Task)
+	for _, task := range tasks {
+		if task.Status.State == "running" {
+			running[task.ServiceID] = append(running[task.ServiceID], task)
+		}
+	}
+
+	// Virtual IPs only carry the network ID, so learn the IDs of the
+	// networks of interest from the task attachments
+	networks := make(map[string]bool)
+	for _, task := range tasks {
+		for _, attachment := range task.NetworksAttachments {
+			if r.usable(attachment) {
+				networks[attachment.Network.ID] = true
+			}
+		}
+	}
+
+	services := make(map[string][]core.ServiceInstance)
+	for _, svc := range swarmServices {
+		labels, ok, err := docker.ParseLabels(svc.Spec.Labels, r.config.LabelPrefix)
+		if err != nil {
+			r.logger.Warn("Invalid port in service label",
+				"service", svc.Spec.Name,
+				"error", err,
+			)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		var instances []core.ServiceInstance
+		if r.config.Resolve == ResolveVIP {
+			instances = r.vipInstances(svc, labels, running[svc.ID], networks)
+		}
+		// Services in dnsrr endpoint mode have no virtual IP
+		if instances == nil {
+			instances = r.taskInstances(labels, running[svc.ID])
+		}
+		if len(instances) == 0 {
+			r.logger.Warn("No address found for swarm service",
+				"service", svc.Spec.Name,
+				"network", r.config.Network,
+			)
+			continue
+		}
+
+		services[labels.Service] = append(services[labels.Service], instances...)
+
+		for _, instance := range instances {
+			r.logger.Debug("Discovered service instance",
+				"service", labels.Service,
+				"id", instance.ID,
+				"address", instance.Address,
+				"port", instance.Port,
+			)
+		}
+	}
+
+	r.mu.Lock()
+	r.services = services
+	r.mu.Unlock()
+
+	r.logger.Info("Service discovery completed",
+		"services", len(services),
+		"total_instances", countInstances(services),
+	)
+
+	return nil
+}
+
+// usable reports whether the gateway reaches services over the network of
+// attachment: the configured network, or any network but the ingress mesh
+// when none is configured
+func (r *Registry) usable(attachment NetworkAttachment) bool {
+	if r.config.Network != "" {
+		return attachment.Network.Spec.Name == r.config.Network || attachment.Network.ID == r.config.Network
+	}
+	return !attachment.Network.Spec.Ingress
+}
+
+// vipInstances returns the virtual IP of svc on a usable network as its
+// only instance, healthy while any of its tasks runs. It returns nil when
+// the service has no such virtual IP.
+func (r *Registry) vipInstances(svc Service, labels docker.Labels, running []Task, networks map[string]bool) []core.ServiceInstance {
+	for _, vip := range svc.Endpoint.VirtualIPs {
+		if !networks[vip.NetworkID] && vip.NetworkID != r.config.Network {
+			continue
+		}
+		address := stripMask(vip.Addr)
+		if address == "" {
+			continue
+		}
+		return []core.ServiceInstance{{
+			ID:       docker.ShortID(svc.ID),
+			Name:     labels.Service,
+			Address:  address,
+			Port:     labels.Port,
+			Scheme:   labels.Scheme,
+			Healthy:  labels.Healthy && len(running) > 0,
+			Metadata: labels.Metadata,
+		}}
+	}
+	return nil
+}
+
+// taskInstances returns an instance for each running task with an address
+// on a usable network
+func (r *Registry) taskInstances(labels docker.Labels, running []Task) []core.ServiceInstance {
+	var instances []core.ServiceInstance
+	for _, task := range running {
+		address := ""
+		for _, attachment := range task.NetworksAttachments {
+			if !r.usable(attachment) || len(attachment.Addresses) == 0 {
+				continue
+			}
+			address = stripMask(attachment.Addresses[0])
+			if address != "" {
+				break
+			}
+		}
+		if address == "" {
+			continue
+		}
+		instances = append(instances, core.ServiceInstance{
+			ID:       docker.ShortID(task.ID),
+			Name:     labels.Service,
+			Address:  address,
+			Port:     labels.Port,
+			Scheme:   labels.Scheme,
+			Healthy:  labels.Healthy,
+			Metadata: labels.Metadata,
+		})
+	}
+	return instances
+}
+
+// stripMask returns the IP of an address in CIDR notation, or an empty
+// string when it is invalid
+func stripMask(addr string) string {
+	if ip, _, err := net.ParseCIDR(addr); err == nil {
+		return ip.String()
+	}
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// refreshLoop runs periodic service discovery
+func (r *Registry) refreshLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(r.config.RefreshInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.refresh(); err != nil {
+				r.logger.Error("Service refresh failed", "error", err)
+			} else {
+				r.markReady()
+			}
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// Ready returns a channel closed after the first successful discovery
+func (r *Registry) Ready() <-chan struct{} {
+	return r.ready
+}
+
+// markReady signals a successful discovery
+func (r *Registry) markReady() {
+	r.readyOnce.Do(func() { close(r.ready) })
+}
+
+// Close stops the registry
+func (r *Registry) Close() error {
+	close(r.stopCh)
+	r.wg.Wait()
+	return nil
+}
+
+// countInstances counts total instances across all services
+func countInstances(services map[string][]core.ServiceInstance) int {
+	count := 0
+	for _, instances := range services {
+		count += len(instances)
+	}
+	return count
+}
@@ -0,0 +1,173 @@
+package swarm
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+)
+
+const swarmState = `{
+	"services": [
+		{
+			"ID": "svcusers0000000000000",
+			"Spec": {"Name": "stack_users", "Labels": {
+				"gateway.service": "users",
+				"gateway.port": "8080",
+				"gateway.meta.version": "2"
+			}},
+			"Endpoint": {"VirtualIPs": [
+				{"NetworkID": "ingressnet", "Addr": "10.255.0.5/16"},
+				{"NetworkID": "backendnet", "Addr": "10.0.1.2/24"}
+			]}
+		},
+		{
+			"ID": "svcorders00000000000",
+			"Spec": {"Name": "stack_orders", "Labels": {
+				"gateway.service": "orders",
+				"gateway.port": "9090",
+				"gateway.scheme": "https"
+			}},
+			"Endpoint": {}
+		},
+		{
+			"ID": "svcbroken00000000000",
+			"Spec": {"Name": "stack_broken", "Labels": {
+				"gateway.service": "broken",
+				"gateway.port": "http"
+			}}
+		}
+	],
+	"tasks": [
+		{"ID": "taskusers1000000000", "ServiceID": "svcusers0000000000000", "Status": {"State": "running"},
+		 "NetworksAttachments": [
+			{"Network": {"ID": "ingressnet", "Spec": {"Name": "ingress", "Ingress": true}}, "Addresses": ["10.255.0.7/16"]},
+			{"Network": {"ID": "backendnet", "Spec": {"Name": "backend"}}, "Addresses": ["10.0.1.3/24"]},
+			{"Network": {"ID": "othernet", "Spec": {"Name": "other"}}, "Addresses": ["10.0.2.3/24"]}
+		 ]},
+		{"ID": "taskusers2000000000", "ServiceID": "svcusers0000000000000", "Status": {"State": "starting"},
+		 "NetworksAttachments": [
+			{"Network": {"ID": "backendnet", "Spec": {"Name": "backend"}}, "Addresses": ["10.0.1.4/24"]}
+		 ]},
+		{"ID": "taskorders100000000", "ServiceID": "svcorders00000000000", "Status": {"State": "running"},
+		 "NetworksAttachments": [
+			{"Network": {"ID": "backendnet", "Spec": {"Name": "backend"}}, "Addresses": ["10.0.1.8/24"]}
+		 ]},
+		{"ID": "taskorders200000000", "ServiceID": "svcorders00000000000", "Status": {"State": "running"},
+		 "NetworksAttachments": [
+			{"Network": {"ID": "backendnet", "Spec": {"Name": "backend"}}, "Addresses": ["10.0.1.9/24"]}
+		 ]}
+	]
+}`
+
+// newSwarmServer serves the services and tasks of swarmState
+func newSwarmServer(t *testing.T) *httptest.Server {
+	var state struct {
+		Services json.RawMessage `json:"services"`
+		Tasks    json.RawMessage `json:"tasks"`
+	}
+	if err := json.Unmarshal([]byte(swarmState), &state); err != nil {
+		t.Fatalf("invalid swarm state: %v", err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_ping":
+			w.WriteHeader(http.StatusOK)
+		case "/services":
+			var f map[string][]string
+			if err := json.Unmarshal([]byte(r.URL.Query().Get("filters")), &f); err != nil || f["label"][0] != "gateway.service" {
+				t.Errorf("unexpected service filters %q", r.URL.Query().Get("filters"))
+			}
+			_, _ = w.Write(state.Services)
+		case "/tasks":
+			_, _ = w.Write(state.Tasks)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func addresses(t *testing.T, r *Registry, service string) []string {
+	t.Helper()
+	instances, err := r.GetService(service)
+	if err != nil {
+		t.Fatalf("GetService(%s) failed: %v", service, err)
+	}
+	var addrs []string
+	for _, instance := range instances {
+		addrs = append(addrs, instance.Address)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+func TestRegistry_ResolveVIP(t *testing.T) {
+	server := newSwarmServer(t)
+	defer server.Close()
+
+	r, err := NewRegistry(&Config{Host: server.URL}, slog.Default())
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	defer r.Close()
+
+	instances, err := r.GetService("users")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if len(instances) != 1 {
+		t.Fatalf("Expected the virtual IP as the only instance, got %v", instances)
+	}
+	users := instances[0]
+	if users.Address != "10.0.1.2" || users.Port != 8080 || users.ID != "svcusers0000" || !users.Healthy {
+		t.Errorf("Expected the virtual IP outside the ingress network, got %+v", users)
+	}
+	if users.Metadata["version"] != "2" {
+		t.Errorf("Expected metadata from labels, got %v", users.Metadata)
+	}
+
+	// Without a virtual IP, the running tasks are the instances
+	if got := addresses(t, r, "orders"); len(got) != 2 || got[0] != "10.0.1.8" || got[1] != "10.0.1.9" {
+		t.Errorf("Expected task IPs for a service without virtual IP, got %v", got)
+	}
+
+	if _, err := r.GetService("broken"); err == nil {
+		t.Error("Expected service with an invalid port to be skipped")
+	}
+}
+
+func TestRegistry_ResolveTasks(t *testing.T) {
+	server := newSwarmServer(t)
+	defer server.Close()
+
+	r, err := NewRegistry(&Config{Host: server.URL, Resolve: ResolveTasks, Network: "other"}, slog.Default())
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	defer r.Close()
+
+	if got := addresses(t, r, "users"); len(got) != 1 || got[0] != "10.0.2.3" {
+		t.Errorf("Expected the running task on the configured network, got %v", got)
+	}
+	if _, err := r.GetService("orders"); err == nil {
+		t.Error("Expected service off the configured network to be skipped")
+	}
+}
+
+func TestRegistry_NetworkFilter(t *testing.T) {
+	server := newSwarmServer(t)
+	defer server.Close()
+
+	r, err := NewRegistry(&Config{Host: server.URL, Network: "ingress"}, slog.Default())
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	defer r.Close()
+
+	if got := addresses(t, r, "users"); len(got) != 1 || got[0] != "10.255.0.5" {
+		t.Errorf("Expected the virtual IP on the configured network, got %v", got)
+	}
+}
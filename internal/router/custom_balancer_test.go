@@ -0,0 +1,66 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"gateway/internal/core"
+	"gateway/pkg/balancer"
+)
+
+// lastBalancer always picks the last instance
+type lastBalancer struct {
+	route string
+}
+
+func (b *lastBalancer) Select(instances []core.ServiceInstance) (*core.ServiceInstance, error) {
+	return &instances[len(instances)-1], nil
+}
+
+func TestRouterCustomBalancer(t *testing.T) {
+	if err := balancer.Register("test_last", func(route balancer.Route) (balancer.LoadBalancer, error) {
+		return &lastBalancer{route: route.ID}, nil
+	}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := balancer.Register("test_broken", func(route balancer.Route) (balancer.LoadBalancer, error) {
+		return nil, fmt.Errorf("missing zone")
+	}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	registry := &mockRegistry{
+		services: map[string][]core.ServiceInstance{
+			"users": {
+				{ID: "first", Address: "127.0.0.1", Port: 8001, Healthy: true},
+				{ID: "last", Address: "127.0.0.1", Port: 8002, Healthy: true},
+			},
+		},
+	}
+	router := NewRouter(registry, nil)
+
+	if err := router.AddRule(core.RouteRule{ID: "users", Path: "/users/*", ServiceName: "users", LoadBalance: "test_last"}); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		result, err := router.Route(context.Background(), &mockRequest{method: "GET", path: "/users/1"})
+		if err != nil {
+			t.Fatalf("Route() failed: %v", err)
+		}
+		if result.Instance.ID != "last" {
+			t.Errorf("Expected the custom balancer to pick the last instance, got %s", result.Instance.ID)
+		}
+	}
+	if b, ok := router.GetRoutes()[0].Balancer.(*lastBalancer); !ok || b.route != "users" {
+		t.Errorf("Expected a balancer created for the route, got %T", router.GetRoutes()[0].Balancer)
+	}
+
+	// A failing factory rejects the rule and leaves its path free
+	if err := router.AddRule(core.RouteRule{ID: "orders", Path: "/orders/*", ServiceName: "users", LoadBalance: "test_broken"}); err == nil {
+		t.Fatal("Expected rule with a failing balancer factory to be rejected")
+	}
+	if err := router.AddRule(core.RouteRule{ID: "orders", Path: "/orders/*", ServiceName: "users"}); err != nil {
+		t.Errorf("Expected path to be free after the rejected rule, got %v", err)
+	}
+}
@@ -156,6 +156,11 @@ func (r *DynamicRouter) Route(ctx context.Context, req core.Request) (*core.Rout
 	return r.base.Route(ctx, req)
 }
 
+// Explain delegates to the base router
+func (r *DynamicRouter) Explain(ctx context.Context, req core.Request) (*core.RouteRule, string, error) {
+	return r.base.Explain(ctx, req)
+}
+
 // AddRule delegates to the base router
 func (r *DynamicRouter) AddRule(rule core.RouteRule) error {
 	return r.base.AddRule(rule)
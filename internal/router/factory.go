@@ -18,6 +18,8 @@ type Component struct {
 	registry core.ServiceRegistry
 	router   *Router
 	logger   *slog.Logger
+	// Redis client for routes keeping session mappings in Redis
+	sessionRedis SessionRedisClient
 }
 
 // NewComponent creates a new router component
@@ -28,6 +30,13 @@ func NewComponent(registry core.ServiceRegistry, logger *slog.Logger) factory.Co
 	}
 }
 
+// WithSessionRedis sets the Redis client of routes keeping session
+// mappings in Redis; it must be called before Init
+func (c *Component) WithSessionRedis(client SessionRedisClient) *Component {
+	c.sessionRedis = client
+	return c
+}
+
 // Name returns the component name
 func (c *Component) Name() string {
 	return ComponentName
@@ -45,6 +54,15 @@ func (c *Component) Init(parser factory.ConfigParser) error {
 
 	// Create router
 	router := NewRouter(c.registry, c.logger)
+	if c.sessionRedis != nil {
+		router.WithSessionRedis(c.sessionRedis)
+	}
+	if o := c.config.InstanceOverride; o != nil && o.Enabled {
+		router.WithInstanceOverride(&InstanceOverride{
+			Header: o.Header,
+			Secret: []byte(o.Secret),
+		})
+	}
 
 	// Add all configured routes
 	for _, rule := range c.config.Rules {
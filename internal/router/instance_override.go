@@ -0,0 +1,129 @@
+package router
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gateway/internal/core"
+)
+
+// DefaultInstanceOverrideHeader is the header of instance overrides when
+// none is configured
+const DefaultInstanceOverrideHeader = "X-Gateway-Route-Instance"
+
+// InstanceOverride sends requests carrying a valid signed header to the
+// instance the header names, bypassing the balancer of their route
+type InstanceOverride struct {
+	Header string // Default DefaultInstanceOverrideHeader
+	Secret []byte // HMAC-SHA256 key of header signatures
+}
+
+// WithInstanceOverride enables instance overrides
+func (r *Router) WithInstanceOverride(override *InstanceOverride) *Router {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instanceOverride = override
+	return r
+}
+
+// SignInstanceOverride returns the header value of an instance override
+// sending requests to instanceID until expires
+func SignInstanceOverride(secret []byte, instanceID string, expires time.Time) string {
+	payload := instanceID + ";" + strconv.FormatInt(expires.Unix(), 10)
+	return payload + ";" + instanceOverrideSignature(secret, payload)
+}
+
+func instanceOverrideSignature(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// overrideInstance returns the instance named by the override header of req,
+// or nil when the header is missing, invalid or expired, or names no healthy
+// instance among instances. Ignored headers only log at debug level, so that
+// clients cannot flood the logs. The header is removed from req, so backends
+// never see a token they could replay.
+func (r *Router) overrideInstance(req core.Request, rule *core.RouteRule, serviceName string, instances []core.ServiceInstance) *core.ServiceInstance {
+	o := r.instanceOverride
+	if o == nil {
+		return nil
+	}
+	header := o.Header
+	if header == "" {
+		header = DefaultInstanceOverrideHeader
+	}
+	headers := http.Header(req.Headers())
+	value := headers.Get(header)
+	if value == "" {
+		return nil
+	}
+	headers.Del(header)
+
+	instanceID, expires, ok := r.verifyInstanceOverride(o.Secret, value)
+	if !ok {
+		return nil
+	}
+	if time.Now().Unix() > expires {
+		r.logger.Debug("Ignoring expired instance override",
+			"route", rule.ID,
+			"instance", instanceID)
+		return nil
+	}
+
+	for i := range instances {
+		if instances[i].ID != instanceID {
+			continue
+		}
+		if !instances[i].Healthy {
+			r.logger.Debug("Ignoring instance override to unhealthy instance",
+				"route", rule.ID,
+				"service", serviceName,
+				"instance", instanceID)
+			return nil
+		}
+		r.logger.Info("Instance override applied",
+			"route", rule.ID,
+			"service", serviceName,
+			"instance", instanceID,
+			"request_id", req.ID())
+		return &instances[i]
+	}
+
+	r.logger.Debug("Ignoring instance override to unknown instance",
+		"route", rule.ID,
+		"service", serviceName,
+		"instance", instanceID)
+	return nil
+}
+
+// verifyInstanceOverride parses a header value and checks its signature.
+// Instance IDs may contain ';', so the value is split from the end.
+func (r *Router) verifyInstanceOverride(secret []byte, value string) (string, int64, bool) {
+	sigAt := strings.LastIndexByte(value, ';')
+	if sigAt < 0 {
+		r.logger.Debug("Ignoring malformed instance override")
+		return "", 0, false
+	}
+	payload, signature := value[:sigAt], value[sigAt+1:]
+	expiresAt := strings.LastIndexByte(payload, ';')
+	if expiresAt <= 0 {
+		r.logger.Debug("Ignoring malformed instance override")
+		return "", 0, false
+	}
+	expires, err := strconv.ParseInt(payload[expiresAt+1:], 10, 64)
+	if err != nil {
+		r.logger.Debug("Ignoring malformed instance override")
+		return "", 0, false
+	}
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(instanceOverrideSignature(secret, payload))) {
+		r.logger.Debug("Ignoring instance override with invalid signature")
+		return "", 0, false
+	}
+	return payload[:expiresAt], expires, true
+}
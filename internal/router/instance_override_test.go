@@ -0,0 +1,108 @@
+package router
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+)
+
+func TestRouterInstanceOverride(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	registry := &mockRegistry{
+		services: map[string][]core.ServiceInstance{
+			"users": {
+				{ID: "users-1", Address: "127.0.0.1", Port: 8001, Healthy: true},
+				{ID: "users-2", Address: "127.0.0.1", Port: 8002, Healthy: true, Metadata: map[string]any{"track": "canary"}},
+				{ID: "users-3", Address: "127.0.0.1", Port: 8003, Healthy: false},
+			},
+		},
+	}
+	router := NewRouter(registry, nil).WithInstanceOverride(&InstanceOverride{Secret: secret})
+	rule := core.RouteRule{
+		ID:               "users",
+		Path:             "/users/*",
+		ServiceName:      "users",
+		LoadBalance:      core.LoadBalanceRoundRobin,
+		InstanceSelector: core.InstanceSelector{{Key: "track", Op: "!=", Value: "canary"}},
+	}
+	if err := router.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	future := time.Now().Add(time.Minute)
+	valid := SignInstanceOverride(secret, "users-2", future)
+	tampered := strings.Replace(valid, "users-2", "users-1", 1)
+
+	tests := []struct {
+		name         string
+		header       string
+		wantOverride bool
+	}{
+		{"valid", valid, true},
+		{"no header", "", false},
+		{"wrong secret", SignInstanceOverride([]byte("another secret"), "users-2", future), false},
+		{"tampered instance", tampered, false},
+		{"expired", SignInstanceOverride(secret, "users-2", time.Now().Add(-time.Minute)), false},
+		{"unhealthy instance", SignInstanceOverride(secret, "users-3", future), false},
+		{"unknown instance", SignInstanceOverride(secret, "users-9", future), false},
+		{"malformed", "users-2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The selector excludes users-2, so the balancer never picks it
+			for i := 0; i < 4; i++ {
+				req := &mockRequest{method: "GET", path: "/users/1", headers: map[string][]string{}}
+				if tt.header != "" {
+					req.headers[DefaultInstanceOverrideHeader] = []string{tt.header}
+				}
+				result, err := router.Route(context.Background(), req)
+				if err != nil {
+					t.Fatalf("Route() failed: %v", err)
+				}
+				if got := result.Instance.ID == "users-2"; got != tt.wantOverride {
+					t.Fatalf("Routed to %s, want override %v", result.Instance.ID, tt.wantOverride)
+				}
+				if _, ok := req.headers[DefaultInstanceOverrideHeader]; ok {
+					t.Fatal("Expected the override header to be removed before forwarding")
+				}
+			}
+		})
+	}
+}
+
+func TestRouterInstanceOverrideDisabled(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	registry := &mockRegistry{
+		services: map[string][]core.ServiceInstance{
+			"users": {
+				{ID: "users-1", Address: "127.0.0.1", Port: 8001, Healthy: true},
+				{ID: "users-2", Address: "127.0.0.1", Port: 8002, Healthy: true, Metadata: map[string]any{"track": "canary"}},
+			},
+		},
+	}
+	router := NewRouter(registry, nil)
+	if err := router.AddRule(core.RouteRule{
+		ID:               "users",
+		Path:             "/users/*",
+		ServiceName:      "users",
+		LoadBalance:      core.LoadBalanceRoundRobin,
+		InstanceSelector: core.InstanceSelector{{Key: "track", Op: "!=", Value: "canary"}},
+	}); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	req := &mockRequest{method: "GET", path: "/users/1", headers: map[string][]string{
+		DefaultInstanceOverrideHeader: {SignInstanceOverride(secret, "users-2", time.Now().Add(time.Minute))},
+	}}
+	result, err := router.Route(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Route() failed: %v", err)
+	}
+	if result.Instance.ID != "users-1" {
+		t.Errorf("Expected the header to be ignored, routed to %s", result.Instance.ID)
+	}
+}
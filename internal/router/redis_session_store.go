@@ -0,0 +1,151 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultSessionKeyPrefix prefixes the Redis keys of session mappings
+const DefaultSessionKeyPrefix = "gateway:affinity:"
+
+const (
+	// sessionRedisTimeout bounds each Redis command, so a slow Redis delays
+	// requests by at most this much before the memory fallback answers
+	sessionRedisTimeout = 100 * time.Millisecond
+	// sessionRedisProbeInterval is how often an unavailable Redis is probed
+	sessionRedisProbeInterval = time.Second
+)
+
+// SessionRedisClient is the subset of the go-redis client used to share
+// session mappings between gateway replicas
+type SessionRedisClient interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// redisSessionStore keeps session mappings in Redis, expiring them after
+// their TTL. Mappings are also kept in memory, which answers while Redis is
+// unavailable. Once a command fails, requests no longer wait for Redis: it
+// is probed in the background until it answers again.
+type redisSessionStore struct {
+	client        SessionRedisClient
+	prefix        string
+	memory        SessionStore
+	logger        *slog.Logger
+	unavailable   atomic.Bool
+	probeInterval time.Duration
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func newRedisSessionStore(client SessionRedisClient, prefix string, memory SessionStore, logger *slog.Logger) *redisSessionStore {
+	return &redisSessionStore{
+		client:        client,
+		prefix:        prefix,
+		memory:        memory,
+		logger:        logger,
+		probeInterval: sessionRedisProbeInterval,
+		done:          make(chan struct{}),
+	}
+}
+
+func (s *redisSessionStore) GetInstance(ctx context.Context, sessionID string) (string, bool) {
+	if s.unavailable.Load() {
+		return s.memory.GetInstance(ctx, sessionID)
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, sessionRedisTimeout)
+	defer cancel()
+	instanceID, err := s.client.Get(cmdCtx, s.prefix+sessionID).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false
+	}
+	if err != nil {
+		s.failed(ctx, err)
+		return s.memory.GetInstance(ctx, sessionID)
+	}
+	return instanceID, true
+}
+
+func (s *redisSessionStore) SetInstance(ctx context.Context, sessionID string, instanceID string, ttl time.Duration) {
+	s.memory.SetInstance(ctx, sessionID, instanceID, ttl)
+	if s.unavailable.Load() {
+		return
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, sessionRedisTimeout)
+	defer cancel()
+	if err := s.client.Set(cmdCtx, s.prefix+sessionID, instanceID, ttl).Err(); err != nil {
+		s.failed(ctx, err)
+	}
+}
+
+func (s *redisSessionStore) RemoveInstance(ctx context.Context, sessionID string) {
+	s.memory.RemoveInstance(ctx, sessionID)
+	if s.unavailable.Load() {
+		return
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, sessionRedisTimeout)
+	defer cancel()
+	if err := s.client.Del(cmdCtx, s.prefix+sessionID).Err(); err != nil {
+		s.failed(ctx, err)
+	}
+}
+
+// Cleanup removes expired in-memory sessions; Redis expires its own
+func (s *redisSessionStore) Cleanup() {
+	s.memory.Cleanup()
+}
+
+// Close stops the in-memory store and the probing of Redis; the Redis
+// client is shared between routes and closed by the router
+func (s *redisSessionStore) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	return s.memory.Close()
+}
+
+// failed marks Redis unavailable after a failed command and starts probing
+// it. Commands failing because the request was canceled say nothing about
+// Redis.
+func (s *redisSessionStore) failed(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	if !s.unavailable.Swap(true) {
+		s.logger.Warn("Session affinity store unavailable, falling back to memory", "error", err)
+		go s.probe()
+	}
+}
+
+// probe checks Redis every probeInterval until it answers again
+func (s *redisSessionStore) probe() {
+	ticker := time.NewTicker(s.probeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), sessionRedisTimeout)
+			err := s.client.Get(ctx, s.prefix+"probe").Err()
+			cancel()
+			if err == nil || errors.Is(err, redis.Nil) {
+				s.available()
+				return
+			}
+		}
+	}
+}
+
+// available logs when Redis is reachable again
+func (s *redisSessionStore) available() {
+	if s.unavailable.Swap(false) {
+		s.logger.Info("Session affinity store available again")
+	}
+}
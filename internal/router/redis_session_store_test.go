@@ -0,0 +1,192 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// mockSessionRedis keeps values in a map, failing every command while down
+type mockSessionRedis struct {
+	mu     sync.Mutex
+	values map[string]string
+	ttls   map[string]time.Duration
+	down   bool
+	calls  int // Commands other than probes
+}
+
+func newMockSessionRedis() *mockSessionRedis {
+	return &mockSessionRedis{values: make(map[string]string), ttls: make(map[string]time.Duration)}
+}
+
+var errRedisDown = errors.New("connection refused")
+
+func (r *mockSessionRedis) Get(ctx context.Context, key string) *redis.StringCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !strings.HasSuffix(key, "probe") {
+		r.calls++
+	}
+	if r.down {
+		return redis.NewStringResult("", errRedisDown)
+	}
+	value, ok := r.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(value, nil)
+}
+
+func (r *mockSessionRedis) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if r.down {
+		return redis.NewStatusResult("", errRedisDown)
+	}
+	r.values[key] = value.(string)
+	r.ttls[key] = expiration
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (r *mockSessionRedis) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if r.down {
+		return redis.NewIntResult(0, errRedisDown)
+	}
+	for _, key := range keys {
+		delete(r.values, key)
+	}
+	return redis.NewIntResult(int64(len(keys)), nil)
+}
+
+func redisStickyBalancer(client SessionRedisClient) *StickySessionBalancer {
+	config := &core.SessionAffinityConfig{
+		Enabled:    true,
+		TTL:        time.Minute,
+		Source:     core.SessionSourceHeader,
+		HeaderName: "X-Session",
+		Storage:    core.SessionStorageRedis,
+	}
+	return NewStickySessionBalancer(NewRoundRobinBalancer(), config).WithRedis(client, "affinity:", slog.Default())
+}
+
+func sessionRequest(id string) *stickyTestRequest {
+	return &stickyTestRequest{headers: map[string][]string{"X-Session": {id}}}
+}
+
+func TestStickySessionBalancer_RedisSharedBetweenReplicas(t *testing.T) {
+	instances := []core.ServiceInstance{
+		{ID: "instance-1", Healthy: true},
+		{ID: "instance-2", Healthy: true},
+	}
+	client := newMockSessionRedis()
+	replica1 := redisStickyBalancer(client)
+	replica2 := redisStickyBalancer(client)
+	defer replica1.Close()
+	defer replica2.Close()
+
+	// The second replica's round robin would pick instance-1 first
+	replica2.fallback.Select(instances)
+
+	first, err := replica1.SelectForRequest(sessionRequest("abc"), instances)
+	if err != nil {
+		t.Fatalf("SelectForRequest failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		inst, err := replica2.SelectForRequest(sessionRequest("abc"), instances)
+		if err != nil {
+			t.Fatalf("SelectForRequest failed: %v", err)
+		}
+		if inst.ID != first.ID {
+			t.Errorf("Expected other replica to pick %s, got %s", first.ID, inst.ID)
+		}
+	}
+
+	if ttl := client.ttls["affinity:abc"]; ttl != time.Minute {
+		t.Errorf("Expected mapping to expire after the TTL, got %v", ttl)
+	}
+}
+
+func TestStickySessionBalancer_RedisUnavailable(t *testing.T) {
+	instances := []core.ServiceInstance{
+		{ID: "instance-1", Healthy: true},
+		{ID: "instance-2", Healthy: true},
+	}
+	client := newMockSessionRedis()
+	client.down = true
+	balancer := redisStickyBalancer(client)
+	defer balancer.Close()
+
+	first, err := balancer.SelectForRequest(sessionRequest("abc"), instances)
+	if err != nil {
+		t.Fatalf("SelectForRequest failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		inst, err := balancer.SelectForRequest(sessionRequest("abc"), instances)
+		if err != nil {
+			t.Fatalf("SelectForRequest failed: %v", err)
+		}
+		if inst.ID != first.ID {
+			t.Errorf("Expected memory fallback to keep the session on %s, got %s", first.ID, inst.ID)
+		}
+	}
+}
+
+func (r *mockSessionRedis) setDown(down bool) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.down = down
+	return r.calls
+}
+
+func TestStickySessionBalancer_RedisRecovers(t *testing.T) {
+	instances := []core.ServiceInstance{
+		{ID: "instance-1", Healthy: true},
+		{ID: "instance-2", Healthy: true},
+	}
+	client := newMockSessionRedis()
+	client.down = true
+	balancer := redisStickyBalancer(client)
+	defer balancer.Close()
+	store := balancer.store.(*redisSessionStore)
+	store.probeInterval = 10 * time.Millisecond
+
+	balancer.SelectForRequest(sessionRequest("abc"), instances)
+
+	// Requests no longer wait for Redis while it is unavailable
+	calls := client.setDown(true)
+	for i := 0; i < 3; i++ {
+		balancer.SelectForRequest(sessionRequest("abc"), instances)
+	}
+	if got := client.setDown(true); got != calls {
+		t.Errorf("Expected no Redis commands while unavailable, got %d", got-calls)
+	}
+
+	// The background probe notices Redis is back
+	client.setDown(false)
+	deadline := time.Now().Add(time.Second)
+	for store.unavailable.Load() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if store.unavailable.Load() {
+		t.Fatal("Expected Redis to be available again")
+	}
+	balancer.SelectForRequest(sessionRequest("def"), instances)
+	client.mu.Lock()
+	_, ok := client.values["affinity:def"]
+	client.mu.Unlock()
+	if !ok {
+		t.Error("Expected new mappings to be stored in Redis again")
+	}
+}
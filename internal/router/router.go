@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"gateway/internal/core"
+	"gateway/pkg/balancer"
 	"gateway/pkg/errors"
 	"gateway/pkg/routing"
 	"log/slog"
@@ -18,6 +19,11 @@ type Router struct {
 	routes    map[string]*core.RouteRule // pattern -> rule mapping
 	mu        sync.RWMutex
 	logger    *slog.Logger
+	metrics   SplitMetricsRecorder
+	// Shared store of session mappings for routes with redis storage
+	sessionRedis SessionRedisClient
+	// Signed header pinning requests to an instance, if enabled
+	instanceOverride *InstanceOverride
 }
 
 // NewRouter creates a new router
@@ -33,6 +39,14 @@ func NewRouter(registry core.ServiceRegistry, logger *slog.Logger) *Router {
 	}
 }
 
+// WithSessionRedis sets the Redis client sharing the session mappings of
+// routes with redis session affinity storage. It must be set before such
+// routes are added; the router closes it.
+func (r *Router) WithSessionRedis(client SessionRedisClient) *Router {
+	r.sessionRedis = client
+	return r
+}
+
 // AddRule adds a routing rule
 func (r *Router) AddRule(rule core.RouteRule) error {
 	r.mu.Lock()
@@ -45,45 +59,73 @@ func (r *Router) AddRule(rule core.RouteRule) error {
 		}
 	}
 
-	// Convert path pattern to ServeMux format
-	pattern := routing.ConvertToServeMuxPattern(rule.Path)
-
-	// Register routes for each method (or all methods if none specified)
-	methods := rule.Methods
-	if len(methods) == 0 {
-		methods = []string{""} // empty means all methods
+	patterns := muxPatterns(rule)
+	for _, muxPattern := range patterns {
+		if existing, ok := r.routes[muxPattern]; ok {
+			return errors.NewError(errors.ErrorTypeBadRequest, fmt.Sprintf("rule %s conflicts with rule %s", rule.ID, existing.ID)).
+				WithDetail("pattern", muxPattern)
+		}
 	}
 
-	for _, method := range methods {
-		var muxPattern string
-		if method == "" {
-			// Register for all methods
-			muxPattern = pattern
-		} else {
-			// Method-specific pattern
-			muxPattern = method + " " + pattern
+	for i, muxPattern := range patterns {
+		if err := register(r.mux, muxPattern); err != nil {
+			// ServeMux cannot unregister, so drop the patterns registered
+			// so far by rebuilding it
+			for _, registered := range patterns[:i] {
+				delete(r.routes, registered)
+			}
+			r.rebuildMux()
+			return errors.NewError(errors.ErrorTypeBadRequest, fmt.Sprintf("rule %s conflicts with an existing rule", rule.ID)).
+				WithDetail("pattern", muxPattern).
+				WithCause(err)
 		}
 
 		// Store rule reference
 		r.routes[muxPattern] = &rule
-
-		// Register handler
-		r.mux.HandleFunc(muxPattern, func(w http.ResponseWriter, req *http.Request) {
-			// This handler is just for route matching, not actual handling
-			// The pattern is stored in the request context by ServeMux
-		})
 	}
 
-	// Create load balancer for this route
+	// Create load balancer for this route, preferring registered strategies
+	if factory, ok := balancer.Lookup(string(rule.LoadBalance)); ok {
+		custom, err := factory(rule)
+		if err != nil || custom == nil {
+			for _, muxPattern := range patterns {
+				delete(r.routes, muxPattern)
+			}
+			r.rebuildMux()
+			if err == nil {
+				err = fmt.Errorf("factory returned no balancer")
+			}
+			return errors.NewError(errors.ErrorTypeBadRequest, fmt.Sprintf("rule %s: create %s balancer", rule.ID, rule.LoadBalance)).
+				WithCause(err)
+		}
+		rule.Balancer = custom
+		return nil
+	}
 	switch rule.LoadBalance {
 	case core.LoadBalanceStickySession:
 		// Use sticky session with round-robin fallback
 		fallback := NewRoundRobinBalancer()
-		rule.Balancer = NewStickySessionBalancer(fallback, rule.SessionAffinity)
+		balancer := NewStickySessionBalancer(fallback, rule.SessionAffinity)
+		if rule.SessionAffinity != nil && rule.SessionAffinity.Storage == core.SessionStorageRedis {
+			if r.sessionRedis != nil {
+				balancer.WithRedis(r.sessionRedis, DefaultSessionKeyPrefix+rule.ID+":", r.logger.With("route", rule.ID))
+			} else {
+				r.logger.Warn("Redis not configured, keeping sessions in memory", "route", rule.ID)
+			}
+		}
+		rule.Balancer = balancer
 	case core.LoadBalanceWeightedRoundRobin:
-		rule.Balancer = NewWeightedRoundRobinBalancer()
+		balancer := NewWeightedRoundRobinBalancer()
+		if rule.SlowStart != nil {
+			balancer.WithSlowStart(rule.SlowStart)
+		}
+		rule.Balancer = balancer
 	case core.LoadBalanceWeightedRandom:
-		rule.Balancer = NewWeightedRandomBalancer()
+		balancer := NewWeightedRandomBalancer()
+		if rule.SlowStart != nil {
+			balancer.WithSlowStart(rule.SlowStart)
+		}
+		rule.Balancer = balancer
 	case core.LoadBalanceLeastConnections:
 		rule.Balancer = NewLeastConnectionsBalancer()
 	case core.LoadBalanceResponseTime:
@@ -104,49 +146,24 @@ func (r *Router) Route(ctx context.Context, req core.Request) (*core.RouteResult
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Create a fake http.Request for ServeMux matching
-	httpReq, err := http.NewRequest(req.Method(), req.Path(), nil)
+	matched, err := r.match(req)
 	if err != nil {
-		return nil, errors.NewError(errors.ErrorTypeBadRequest, "invalid request").
-			WithCause(err)
-	}
-
-	// Use ServeMux to find the matching handler
-	_, pattern := r.mux.Handler(httpReq)
-	if pattern == "" {
-		return nil, errors.NewError(errors.ErrorTypeNotFound, "route not found").
-			WithDetail("method", req.Method()).
-			WithDetail("path", req.Path())
-	}
-
-	// Look up the rule by pattern
-	var matched *core.RouteRule
-
-	// First try method-specific pattern
-	methodPattern := req.Method() + " " + pattern
-	if rule, ok := r.routes[methodPattern]; ok {
-		matched = rule
-	} else if rule, ok := r.routes[pattern]; ok {
-		// Fall back to method-agnostic pattern
-		// But verify method is allowed if methods are specified
-		if len(rule.Methods) == 0 || matchMethod(rule.Methods, req.Method()) {
-			matched = rule
-		}
-	}
-
-	if matched == nil {
-		return nil, errors.NewError(errors.ErrorTypeNotFound, "route not found").
-			WithDetail("method", req.Method()).
-			WithDetail("path", req.Path())
+		return nil, err
 	}
 
-	// Check for version-based service override
+	// Pick the service of a traffic split; a version-based service
+	// override takes precedence
 	serviceName := matched.ServiceName
 	if serviceOverride := getServiceOverrideFromContext(ctx); serviceOverride != "" {
 		serviceName = serviceOverride
 		r.logger.Debug("Using version-specific service", 
 			"original", matched.ServiceName,
 			"override", serviceName)
+	} else if split := matched.TrafficSplit; split != nil && len(split.Services) > 0 {
+		serviceName = splitService(split, req)
+		if r.metrics != nil {
+			r.metrics.RecordTrafficSplit(ctx, matched.ID, serviceName)
+		}
 	}
 
 	// Get instances
@@ -157,9 +174,23 @@ func (r *Router) Route(ctx context.Context, req core.Request) (*core.RouteResult
 			WithCause(err)
 	}
 
+	// A signed override names the instance itself, even one the
+	// instance selector excludes
+	if instance := r.overrideInstance(req, matched, serviceName, instances); instance != nil {
+		return &core.RouteResult{
+			Instance:    instance,
+			Rule:        matched,
+			ServiceName: serviceName,
+		}, nil
+	}
+
+	if len(matched.InstanceSelector) > 0 {
+		instances = selectInstances(instances, matched.InstanceSelector)
+	}
+
 	if len(instances) == 0 {
 		return nil, errors.NewError(errors.ErrorTypeUnavailable, "no instances available").
-			WithDetail("service", matched.ServiceName)
+			WithDetail("service", serviceName)
 	}
 
 	// Select instance using route's balancer
@@ -188,6 +219,154 @@ func (r *Router) Route(ctx context.Context, req core.Request) (*core.RouteResult
 	}, nil
 }
 
+// Explain returns the rule and service that would serve req, as Route does
+// but without selecting an instance or recording metrics. The service of a
+// traffic split is only known for requests with its sticky header and is
+// empty for others.
+func (r *Router) Explain(ctx context.Context, req core.Request) (*core.RouteRule, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched, err := r.match(req)
+	if err != nil {
+		return nil, "", err
+	}
+	if serviceOverride := getServiceOverrideFromContext(ctx); serviceOverride != "" {
+		return matched, serviceOverride, nil
+	}
+	if split := matched.TrafficSplit; split != nil && len(split.Services) > 0 {
+		if stickyValue(split, req) == "" {
+			return matched, "", nil
+		}
+		return matched, splitService(split, req), nil
+	}
+	return matched, matched.ServiceName, nil
+}
+
+// selectInstances returns the instances matching selector
+func selectInstances(instances []core.ServiceInstance, selector core.InstanceSelector) []core.ServiceInstance {
+	selected := make([]core.ServiceInstance, 0, len(instances))
+	for _, instance := range instances {
+		if selector.Matches(instance) {
+			selected = append(selected, instance)
+		}
+	}
+	return selected
+}
+
+// Match returns the rule matching the request without selecting an instance
+func (r *Router) Match(req core.Request) (*core.RouteRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.match(req)
+}
+
+// match finds the rule for the request; r.mu must be held
+func (r *Router) match(req core.Request) (*core.RouteRule, error) {
+	// Create a fake http.Request for ServeMux matching
+	httpReq, err := http.NewRequest(req.Method(), req.Path(), nil)
+	if err != nil {
+		return nil, errors.NewError(errors.ErrorTypeBadRequest, "invalid request").
+			WithCause(err)
+	}
+
+	// Use ServeMux to find the matching handler
+	_, pattern := r.mux.Handler(httpReq)
+	if pattern == "" {
+		return nil, errors.NewError(errors.ErrorTypeNotFound, "route not found").
+			WithDetail("method", req.Method()).
+			WithDetail("path", req.Path())
+	}
+
+	// Look up the rule by pattern
+	var matched *core.RouteRule
+
+	// First try method-specific pattern
+	methodPattern := req.Method() + " " + pattern
+	if rule, ok := r.routes[methodPattern]; ok {
+		matched = rule
+	} else if rule, ok := r.routes[pattern]; ok {
+		// Fall back to method-agnostic pattern
+		// But verify method is allowed if methods are specified
+		if len(rule.Methods) == 0 || matchMethod(rule.Methods, req.Method()) {
+			matched = rule
+		}
+	}
+
+	if matched == nil {
+		return nil, errors.NewError(errors.ErrorTypeNotFound, "route not found").
+			WithDetail("method", req.Method()).
+			WithDetail("path", req.Path())
+	}
+
+	return matched, nil
+}
+
+// RemoveRule removes the routing rule with the given ID
+func (r *Router) RemoveRule(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var removed *core.RouteRule
+	for pattern, rule := range r.routes {
+		if rule.ID == id {
+			removed = rule
+			delete(r.routes, pattern)
+		}
+	}
+	if removed == nil {
+		return errors.NewError(errors.ErrorTypeNotFound, fmt.Sprintf("rule not found: %s", id))
+	}
+
+	r.rebuildMux()
+	if closer, ok := removed.Balancer.(interface{ Close() error }); ok {
+		closer.Close()
+	}
+	return nil
+}
+
+// muxPatterns returns the ServeMux patterns a rule is registered under
+func muxPatterns(rule core.RouteRule) []string {
+	// Convert path pattern to ServeMux format
+	pattern := routing.ConvertToServeMuxPattern(rule.Path)
+
+	// Register routes for each method (or all methods if none specified)
+	if len(rule.Methods) == 0 {
+		return []string{pattern}
+	}
+	patterns := make([]string, 0, len(rule.Methods))
+	for _, method := range rule.Methods {
+		patterns = append(patterns, method+" "+pattern)
+	}
+	return patterns
+}
+
+// register registers a matching-only pattern, returning the error ServeMux
+// panics with for invalid or conflicting patterns
+func register(mux *http.ServeMux, pattern string) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%v", p)
+		}
+	}()
+
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, req *http.Request) {
+		// This handler is just for route matching, not actual handling
+		// The pattern is stored in the request context by ServeMux
+	})
+	return nil
+}
+
+// rebuildMux registers the remaining patterns with a new ServeMux; r.mu
+// must be held
+func (r *Router) rebuildMux() {
+	r.mux = http.NewServeMux()
+	for pattern := range r.routes {
+		// The patterns were accepted before, so they cannot conflict now
+		register(r.mux, pattern)
+	}
+}
+
 // getServiceOverrideFromContext extracts service override from context
 func getServiceOverrideFromContext(ctx context.Context) string {
 	if service, ok := ctx.Value("version.service").(string); ok {
@@ -244,5 +423,9 @@ func (r *Router) Close() error {
 		}
 	}
 
+	if closer, ok := r.sessionRedis.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+
 	return nil
 }
@@ -132,6 +132,16 @@ func TestRouterAddRule(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "duplicate pattern",
+			rule: core.RouteRule{
+				ID:          "test-4",
+				Path:        "/api/users",
+				ServiceName: "other-service",
+				Methods:     []string{"POST"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -376,6 +386,66 @@ func TestRouterMethodMatching(t *testing.T) {
 	}
 }
 
+func TestRouterMatch(t *testing.T) {
+	// The service has no healthy instances, which Match does not need
+	registry := &mockRegistry{
+		services: map[string][]core.ServiceInstance{
+			"match-service": {
+				{ID: "match-1", Address: "127.0.0.1", Port: 8001, Healthy: false},
+			},
+		},
+	}
+
+	router := NewRouter(registry, nil)
+	if err := router.AddRule(core.RouteRule{ID: "match", Path: "/api/match/*", ServiceName: "match-service"}); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	rule, err := router.Match(&mockRequest{method: "GET", path: "/api/match/1"})
+	if err != nil {
+		t.Fatalf("Match() failed: %v", err)
+	}
+	if rule.ID != "match" {
+		t.Errorf("Match() returned rule %s, want match", rule.ID)
+	}
+
+	if _, err := router.Match(&mockRequest{method: "GET", path: "/api/other"}); err == nil {
+		t.Error("Match() expected error for unknown path")
+	}
+}
+
+func TestRouterRemoveRule(t *testing.T) {
+	registry := &mockRegistry{services: make(map[string][]core.ServiceInstance)}
+	router := NewRouter(registry, nil)
+
+	for _, rule := range []core.RouteRule{
+		{ID: "users", Path: "/api/users", Methods: []string{"GET", "POST"}, ServiceName: "user-service"},
+		{ID: "orders", Path: "/api/orders/*", ServiceName: "order-service"},
+	} {
+		if err := router.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule %s: %v", rule.ID, err)
+		}
+	}
+
+	if err := router.RemoveRule("users"); err != nil {
+		t.Fatalf("RemoveRule() failed: %v", err)
+	}
+	if _, err := router.Match(&mockRequest{method: "GET", path: "/api/users"}); err == nil {
+		t.Error("Match() expected error for removed rule")
+	}
+	if rule, err := router.Match(&mockRequest{method: "GET", path: "/api/orders/1"}); err != nil || rule.ID != "orders" {
+		t.Errorf("Match() expected remaining rule orders, got %v, %v", rule, err)
+	}
+	if err := router.RemoveRule("users"); err == nil {
+		t.Error("RemoveRule() expected error for unknown rule")
+	}
+
+	// The removed rule's patterns can be registered again
+	if err := router.AddRule(core.RouteRule{ID: "users-v2", Path: "/api/users", Methods: []string{"GET"}, ServiceName: "user-service"}); err != nil {
+		t.Errorf("AddRule() after removal failed: %v", err)
+	}
+}
+
 func TestRouterPathConversion(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -0,0 +1,53 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+)
+
+func TestRouterInstanceSelector(t *testing.T) {
+	registry := &mockRegistry{
+		services: map[string][]core.ServiceInstance{
+			"users": {
+				{ID: "stable", Address: "127.0.0.1", Port: 8001, Healthy: true, Metadata: map[string]any{"version": "1.4"}},
+				{ID: "beta", Address: "127.0.0.1", Port: 8002, Healthy: true, Metadata: map[string]any{"version": "2.1", "tags": []string{"beta"}}},
+				{ID: "bare", Address: "127.0.0.1", Port: 8003, Healthy: true},
+			},
+		},
+	}
+	router := NewRouter(registry, nil)
+
+	selector, err := core.ParseInstanceSelector([]string{"tag=beta", "version>=2"})
+	if err != nil {
+		t.Fatalf("ParseInstanceSelector failed: %v", err)
+	}
+	rules := []core.RouteRule{
+		{ID: "beta", Path: "/beta/*", ServiceName: "users", InstanceSelector: selector},
+		{ID: "eu", Path: "/eu/*", ServiceName: "users", InstanceSelector: core.InstanceSelector{{Key: "region", Op: "=", Value: "eu"}}},
+	}
+	for _, rule := range rules {
+		if err := router.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule: %v", err)
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		result, err := router.Route(context.Background(), &mockRequest{method: "GET", path: "/beta/users"})
+		if err != nil {
+			t.Fatalf("Route() failed: %v", err)
+		}
+		if result.Instance.ID != "beta" {
+			t.Errorf("Expected only the beta instance, got %s", result.Instance.ID)
+		}
+	}
+
+	// No instance carries the region
+	_, err = router.Route(context.Background(), &mockRequest{method: "GET", path: "/eu/users"})
+	if gwerrors.HTTPStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without matching instances, got %v", err)
+	}
+}
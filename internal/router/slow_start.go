@@ -0,0 +1,120 @@
+package router
+
+import (
+	"sync"
+	"time"
+
+	"gateway/internal/core"
+)
+
+// DefaultSlowStartMinWeight is the share of its weight an instance gets
+// when it has just become healthy, unless configured otherwise
+const DefaultSlowStartMinWeight = 0.1
+
+// slowStartScale multiplies integer weights so a ramping instance's weight
+// can grow in small steps
+const slowStartScale = 100
+
+// slowStart tracks when instances became healthy and ramps their weight up
+// linearly over a window afterwards, so instances rejoining with cold
+// caches are not overwhelmed
+type slowStart struct {
+	window    time.Duration
+	minWeight float64
+	now       func() time.Time
+
+	mu        sync.Mutex
+	instances map[string]*slowStartState
+	lastPrune time.Time
+}
+
+// slowStartState is what the tracker knows about an instance
+type slowStartState struct {
+	healthy      bool
+	healthySince time.Time // Zero when the instance is warm
+	lastSeen     time.Time
+}
+
+func newSlowStart(cfg *core.SlowStartConfig) *slowStart {
+	minWeight := cfg.MinWeight
+	if minWeight <= 0 || minWeight > 1 {
+		minWeight = DefaultSlowStartMinWeight
+	}
+	return &slowStart{
+		window:    cfg.Window,
+		minWeight: minWeight,
+		now:       time.Now,
+		instances: make(map[string]*slowStartState),
+	}
+}
+
+// observe records the health of instances. Instances seen healthy for the
+// first time alongside known ones, or turning healthy, start ramping up;
+// the instances of a service seen for the first time, such as when the
+// gateway starts, are considered warm.
+func (s *slowStart) observe(instances []core.ServiceInstance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	known := false
+	for _, inst := range instances {
+		if _, ok := s.instances[inst.ID]; ok {
+			known = true
+			break
+		}
+	}
+
+	for _, inst := range instances {
+		state, ok := s.instances[inst.ID]
+		if !ok {
+			state = &slowStartState{healthy: inst.Healthy}
+			if known && inst.Healthy {
+				state.healthySince = now
+			}
+			s.instances[inst.ID] = state
+		} else if inst.Healthy && !state.healthy {
+			state.healthySince = now
+		}
+		state.healthy = inst.Healthy
+		state.lastSeen = now
+	}
+
+	// Forget instances that have been gone for a while
+	if now.Sub(s.lastPrune) >= s.window {
+		for id, state := range s.instances {
+			if now.Sub(state.lastSeen) > s.window {
+				delete(s.instances, id)
+			}
+		}
+		s.lastPrune = now
+	}
+}
+
+// factor returns the share of its weight an instance gets, from minWeight
+// when it has just become healthy up to 1 at the end of the window
+func (s *slowStart) factor(id string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.instances[id]
+	if !ok || state.healthySince.IsZero() {
+		return 1
+	}
+	elapsed := s.now().Sub(state.healthySince)
+	if elapsed >= s.window {
+		state.healthySince = time.Time{}
+		return 1
+	}
+	return s.minWeight + (1-s.minWeight)*float64(elapsed)/float64(s.window)
+}
+
+// weight scales weight by the instance's slow start factor. Weights of all
+// instances are scaled up alike, so the smallest step is 1% of a weight.
+func (s *slowStart) weight(id string, weight int) int {
+	scaled := int(float64(weight*slowStartScale) * s.factor(id))
+	if scaled < 1 {
+		scaled = 1
+	}
+	return scaled
+}
@@ -0,0 +1,92 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+)
+
+// fakeClock is a settable time source
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func slowStartInstances(healthy ...bool) []core.ServiceInstance {
+	instances := make([]core.ServiceInstance, len(healthy))
+	for i, h := range healthy {
+		instances[i] = core.ServiceInstance{ID: string(rune('a' + i)), Healthy: h}
+	}
+	return instances
+}
+
+func TestSlowStart_Ramp(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	s := newSlowStart(&core.SlowStartConfig{Window: 10 * time.Second, MinWeight: 0.2})
+	s.now = clock.Now
+
+	// Instances present when the service is first seen are warm
+	s.observe(slowStartInstances(true, false))
+	if f := s.factor("a"); f != 1 {
+		t.Errorf("Expected initial instance to be warm, got factor %v", f)
+	}
+
+	// An instance turning healthy starts at the minimum weight
+	s.observe(slowStartInstances(true, true))
+	if f := s.factor("b"); f != 0.2 {
+		t.Errorf("Expected factor 0.2, got %v", f)
+	}
+
+	clock.now = clock.now.Add(5 * time.Second)
+	s.observe(slowStartInstances(true, true))
+	if w := s.weight("b", 2); w != 120 {
+		t.Errorf("Expected weight 120, got %d", w)
+	}
+	if w := s.weight("a", 2); w != 200 {
+		t.Errorf("Expected weight 200, got %d", w)
+	}
+
+	clock.now = clock.now.Add(5 * time.Second)
+	s.observe(slowStartInstances(true, true))
+	if f := s.factor("b"); f != 1 {
+		t.Errorf("Expected full weight after the window, got %v", f)
+	}
+
+	// A new instance alongside known ones ramps up too
+	s.observe(slowStartInstances(true, true, true))
+	if f := s.factor("c"); f != 0.2 {
+		t.Errorf("Expected new instance to start ramping, got factor %v", f)
+	}
+}
+
+func TestWeightedRoundRobinBalancer_SlowStart(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	b := NewWeightedRoundRobinBalancer().WithSlowStart(&core.SlowStartConfig{Window: 10 * time.Second, MinWeight: 0.1})
+	b.slowStart.now = clock.Now
+
+	b.Select(slowStartInstances(true, false))
+
+	count := func() int {
+		selected := 0
+		for i := 0; i < 110; i++ {
+			inst, err := b.Select(slowStartInstances(true, true))
+			if err != nil {
+				t.Fatalf("Select failed: %v", err)
+			}
+			if inst.ID == "b" {
+				selected++
+			}
+		}
+		return selected
+	}
+
+	if n := count(); n != 10 {
+		t.Errorf("Expected newly healthy instance to get 10 of 110 requests, got %d", n)
+	}
+	clock.now = clock.now.Add(10 * time.Second)
+	if n := count(); n != 55 {
+		t.Errorf("Expected warm instance to get 55 of 110 requests, got %d", n)
+	}
+}
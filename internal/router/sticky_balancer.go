@@ -2,6 +2,8 @@ package router
 
 import (
 	"container/list"
+	"context"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -10,14 +12,15 @@ import (
 	"gateway/pkg/errors"
 )
 
-// SessionStore stores session-to-instance mappings
+// SessionStore stores session-to-instance mappings. The context is the one
+// of the request the mapping is looked up or stored for.
 type SessionStore interface {
 	// GetInstance gets the instance ID for a session
-	GetInstance(sessionID string) (string, bool)
+	GetInstance(ctx context.Context, sessionID string) (string, bool)
 	// SetInstance sets the instance ID for a session
-	SetInstance(sessionID string, instanceID string, ttl time.Duration)
+	SetInstance(ctx context.Context, sessionID string, instanceID string, ttl time.Duration)
 	// RemoveInstance removes a session mapping
-	RemoveInstance(sessionID string)
+	RemoveInstance(ctx context.Context, sessionID string)
 	// Cleanup removes expired sessions
 	Cleanup()
 	// Close stops any background goroutines
@@ -72,7 +75,7 @@ func newMemorySessionStore(maxEntries int) *memorySessionStore {
 	return store
 }
 
-func (s *memorySessionStore) GetInstance(sessionID string) (string, bool) {
+func (s *memorySessionStore) GetInstance(ctx context.Context, sessionID string) (string, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -89,7 +92,7 @@ func (s *memorySessionStore) GetInstance(sessionID string) (string, bool) {
 	return entry.instanceID, true
 }
 
-func (s *memorySessionStore) SetInstance(sessionID string, instanceID string, ttl time.Duration) {
+func (s *memorySessionStore) SetInstance(ctx context.Context, sessionID string, instanceID string, ttl time.Duration) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -125,7 +128,7 @@ func (s *memorySessionStore) SetInstance(sessionID string, instanceID string, tt
 	}
 }
 
-func (s *memorySessionStore) RemoveInstance(sessionID string) {
+func (s *memorySessionStore) RemoveInstance(ctx context.Context, sessionID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -201,6 +204,13 @@ func NewStickySessionBalancer(fallback core.LoadBalancer, config *core.SessionAf
 	}
 }
 
+// WithRedis shares the session mappings with other gateway replicas through
+// Redis, keeping them in memory as well for when Redis is unavailable
+func (b *StickySessionBalancer) WithRedis(client SessionRedisClient, keyPrefix string, logger *slog.Logger) *StickySessionBalancer {
+	b.store = newRedisSessionStore(client, keyPrefix, b.store, logger)
+	return b
+}
+
 // SelectForRequest selects an instance based on the request
 func (b *StickySessionBalancer) SelectForRequest(req core.Request, instances []core.ServiceInstance) (*core.ServiceInstance, error) {
 	if len(instances) == 0 {
@@ -214,8 +224,13 @@ func (b *StickySessionBalancer) SelectForRequest(req core.Request, instances []c
 		return b.fallback.Select(instances)
 	}
 
+	ctx := req.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	// Check if we have a sticky session
-	if instanceID, ok := b.store.GetInstance(sessionID); ok {
+	if instanceID, ok := b.store.GetInstance(ctx, sessionID); ok {
 		// Find the instance
 		for i := range instances {
 			if instances[i].ID == instanceID && instances[i].Healthy {
@@ -224,7 +239,7 @@ func (b *StickySessionBalancer) SelectForRequest(req core.Request, instances []c
 			}
 		}
 		// Instance not found or not healthy, remove from store
-		b.store.RemoveInstance(sessionID)
+		b.store.RemoveInstance(ctx, sessionID)
 	}
 
 	// Select new instance using fallback
@@ -234,7 +249,7 @@ func (b *StickySessionBalancer) SelectForRequest(req core.Request, instances []c
 	}
 
 	// Store the mapping
-	b.store.SetInstance(sessionID, instance.ID, b.ttl)
+	b.store.SetInstance(ctx, sessionID, instance.ID, b.ttl)
 
 	return instance, nil
 }
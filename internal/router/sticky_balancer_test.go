@@ -395,7 +395,7 @@ func TestMemorySessionStoreClose(t *testing.T) {
 	// Add some sessions
 	for i := 0; i < 10; i++ {
 		sessionID := fmt.Sprintf("session-%d", i)
-		store.SetInstance(sessionID, fmt.Sprintf("instance-%d", i), time.Hour)
+		store.SetInstance(context.Background(), sessionID, fmt.Sprintf("instance-%d", i), time.Hour)
 	}
 
 	// Close the store
@@ -408,7 +408,7 @@ func TestMemorySessionStoreClose(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	// The store should still be functional (just no cleanup)
-	instanceID, found := store.GetInstance("session-5")
+	instanceID, found := store.GetInstance(context.Background(), "session-5")
 	if !found {
 		t.Error("Expected to find session-5 after close")
 	}
@@ -0,0 +1,61 @@
+package router
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+
+	"gateway/internal/core"
+)
+
+// SplitMetricsRecorder records the service a split route sent a request to
+type SplitMetricsRecorder interface {
+	RecordTrafficSplit(ctx context.Context, route, service string)
+}
+
+// WithMetrics sets the recorder notified of traffic split decisions
+func (r *Router) WithMetrics(metrics SplitMetricsRecorder) *Router {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = metrics
+	return r
+}
+
+// splitService picks the service of split that serves req. Requests with
+// the sticky header are hashed onto a service, so the same value keeps
+// going to the same service; other requests are spread randomly by weight.
+func splitService(split *core.TrafficSplit, req core.Request) string {
+	total := 0
+	for _, service := range split.Services {
+		total += service.Weight
+	}
+	if total <= 0 {
+		return split.Services[0].ServiceName
+	}
+
+	var point int
+	if value := stickyValue(split, req); value != "" {
+		h := fnv.New32a()
+		h.Write([]byte(value))
+		point = int(h.Sum32() % uint32(total))
+	} else {
+		point = rand.Intn(total)
+	}
+
+	for _, service := range split.Services {
+		if point < service.Weight {
+			return service.ServiceName
+		}
+		point -= service.Weight
+	}
+	return split.Services[len(split.Services)-1].ServiceName
+}
+
+// stickyValue returns the value of the sticky header of split, if any
+func stickyValue(split *core.TrafficSplit, req core.Request) string {
+	if split.StickyHeader == "" {
+		return ""
+	}
+	return http.Header(req.Headers()).Get(split.StickyHeader)
+}
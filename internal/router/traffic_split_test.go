@@ -0,0 +1,164 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"gateway/internal/core"
+)
+
+type mockSplitRecorder struct {
+	counts map[string]int
+}
+
+func (m *mockSplitRecorder) RecordTrafficSplit(ctx context.Context, route, service string) {
+	m.counts[route+"/"+service]++
+}
+
+func splitRouter(t *testing.T, split *core.TrafficSplit) (*Router, *mockSplitRecorder) {
+	t.Helper()
+
+	registry := &mockRegistry{
+		services: map[string][]core.ServiceInstance{
+			"stable": {{ID: "stable-1", Address: "127.0.0.1", Port: 8001, Healthy: true}},
+			"canary": {{ID: "canary-1", Address: "127.0.0.1", Port: 8002, Healthy: true}},
+		},
+	}
+	recorder := &mockSplitRecorder{counts: make(map[string]int)}
+	router := NewRouter(registry, nil).WithMetrics(recorder)
+
+	rule := core.RouteRule{
+		ID:           "split",
+		Path:         "/api/*",
+		LoadBalance:  core.LoadBalanceRoundRobin,
+		TrafficSplit: split,
+	}
+	if err := router.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+	return router, recorder
+}
+
+func TestRouterTrafficSplit(t *testing.T) {
+	router, recorder := splitRouter(t, &core.TrafficSplit{
+		Services: []core.WeightedService{
+			{ServiceName: "stable", Weight: 90},
+			{ServiceName: "canary", Weight: 10},
+		},
+	})
+
+	counts := make(map[string]int)
+	req := &mockRequest{method: "GET", path: "/api/users"}
+	for i := 0; i < 2000; i++ {
+		result, err := router.Route(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Route() failed: %v", err)
+		}
+		if result.Instance.ID != result.ServiceName+"-1" {
+			t.Fatalf("Instance %s does not belong to service %s", result.Instance.ID, result.ServiceName)
+		}
+		counts[result.ServiceName]++
+	}
+
+	// 10% of 2000 requests, with a wide margin for randomness
+	if counts["canary"] < 100 || counts["canary"] > 300 {
+		t.Errorf("Expected about 200 canary requests, got %d", counts["canary"])
+	}
+	if recorder.counts["split/canary"] != counts["canary"] || recorder.counts["split/stable"] != counts["stable"] {
+		t.Errorf("Expected recorded split %v, got %v", counts, recorder.counts)
+	}
+}
+
+func TestRouterTrafficSplit_StickyHeader(t *testing.T) {
+	router, _ := splitRouter(t, &core.TrafficSplit{
+		Services: []core.WeightedService{
+			{ServiceName: "stable", Weight: 50},
+			{ServiceName: "canary", Weight: 50},
+		},
+		StickyHeader: "X-User-ID",
+	})
+
+	services := make(map[string]bool)
+	for user := 0; user < 20; user++ {
+		req := &mockRequest{
+			method:  "GET",
+			path:    "/api/users",
+			headers: map[string][]string{"X-User-Id": {fmt.Sprintf("user-%d", user)}},
+		}
+		first, err := router.Route(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Route() failed: %v", err)
+		}
+		services[first.ServiceName] = true
+		for i := 0; i < 10; i++ {
+			result, _ := router.Route(context.Background(), req)
+			if result.ServiceName != first.ServiceName {
+				t.Fatalf("Expected user-%d to stay on %s, got %s", user, first.ServiceName, result.ServiceName)
+			}
+		}
+	}
+	if len(services) != 2 {
+		t.Errorf("Expected users to be spread over both services, got %v", services)
+	}
+}
+
+func TestRouterTrafficSplit_VersionOverride(t *testing.T) {
+	router, recorder := splitRouter(t, &core.TrafficSplit{
+		Services: []core.WeightedService{{ServiceName: "canary", Weight: 1}},
+	})
+
+	ctx := context.WithValue(context.Background(), "version.service", "stable")
+	result, err := router.Route(ctx, &mockRequest{method: "GET", path: "/api/users"})
+	if err != nil {
+		t.Fatalf("Route() failed: %v", err)
+	}
+	if result.ServiceName != "stable" {
+		t.Errorf("Expected version override to win, got %s", result.ServiceName)
+	}
+	if len(recorder.counts) != 0 {
+		t.Errorf("Expected no split to be recorded, got %v", recorder.counts)
+	}
+}
+
+func TestRouterExplain(t *testing.T) {
+	router, recorder := splitRouter(t, &core.TrafficSplit{
+		Services: []core.WeightedService{
+			{ServiceName: "stable", Weight: 50},
+			{ServiceName: "canary", Weight: 50},
+		},
+		StickyHeader: "X-User-ID",
+	})
+
+	// The service of requests without the sticky header is picked at random
+	rule, service, err := router.Explain(context.Background(), &mockRequest{method: "GET", path: "/api/users"})
+	if err != nil {
+		t.Fatalf("Explain() failed: %v", err)
+	}
+	if rule.ID != "split" || service != "" {
+		t.Errorf("Explain() = %s, %q, want split with no service", rule.ID, service)
+	}
+
+	sticky := &mockRequest{
+		method:  "GET",
+		path:    "/api/users",
+		headers: map[string][]string{"X-User-Id": {"user-1"}},
+	}
+	_, service, _ = router.Explain(context.Background(), sticky)
+	result, _ := router.Route(context.Background(), sticky)
+	if service != result.ServiceName {
+		t.Errorf("Explain() service = %s, Route() = %s", service, result.ServiceName)
+	}
+	if total := recorder.counts["split/stable"] + recorder.counts["split/canary"]; total != 1 {
+		t.Errorf("Expected only the routed request to be recorded, got %v", recorder.counts)
+	}
+
+	ctx := context.WithValue(context.Background(), "version.service", "v2")
+	if _, service, _ := router.Explain(ctx, sticky); service != "v2" {
+		t.Errorf("Expected version override, got %s", service)
+	}
+
+	if _, _, err := router.Explain(context.Background(), &mockRequest{method: "GET", path: "/other"}); err == nil {
+		t.Error("Expected unmatched paths to fail")
+	}
+}
@@ -15,6 +15,7 @@ type WeightedRoundRobinBalancer struct {
 	weightedInstances []weightedInstance
 	totalWeight       int
 	counter           atomic.Uint64
+	slowStart         *slowStart
 }
 
 type weightedInstance struct {
@@ -29,6 +30,13 @@ func NewWeightedRoundRobinBalancer() *WeightedRoundRobinBalancer {
 	return &WeightedRoundRobinBalancer{}
 }
 
+// WithSlowStart ramps up the weight of instances that have just become
+// healthy
+func (b *WeightedRoundRobinBalancer) WithSlowStart(cfg *core.SlowStartConfig) *WeightedRoundRobinBalancer {
+	b.slowStart = newSlowStart(cfg)
+	return b
+}
+
 // Select selects the next healthy instance based on weights
 func (b *WeightedRoundRobinBalancer) Select(instances []core.ServiceInstance) (*core.ServiceInstance, error) {
 	b.mu.Lock()
@@ -41,6 +49,15 @@ func (b *WeightedRoundRobinBalancer) Select(instances []core.ServiceInstance) (*
 		return nil, errors.NewError(errors.ErrorTypeUnavailable, "no healthy instances")
 	}
 	
+	// Scale the weights of instances ramping up after becoming healthy
+	if b.slowStart != nil {
+		b.slowStart.observe(instances)
+		for i := range b.weightedInstances {
+			w := &b.weightedInstances[i]
+			w.effectiveWeight = b.slowStart.weight(w.instance.ID, w.weight)
+		}
+	}
+	
 	// Use smooth weighted round-robin algorithm
 	var selected *weightedInstance
 	totalWeight := 0
@@ -136,8 +153,9 @@ func (b *WeightedRoundRobinBalancer) instancesEqual(instances []core.ServiceInst
 
 // WeightedRandomBalancer implements weighted random load balancing
 type WeightedRandomBalancer struct {
-	mu   sync.RWMutex
-	rand *rand.Rand
+	mu        sync.RWMutex
+	rand      *rand.Rand
+	slowStart *slowStart
 }
 
 // NewWeightedRandomBalancer creates a new weighted random balancer
@@ -147,11 +165,22 @@ func NewWeightedRandomBalancer() *WeightedRandomBalancer {
 	}
 }
 
+// WithSlowStart ramps up the weight of instances that have just become
+// healthy
+func (b *WeightedRandomBalancer) WithSlowStart(cfg *core.SlowStartConfig) *WeightedRandomBalancer {
+	b.slowStart = newSlowStart(cfg)
+	return b
+}
+
 // Select randomly selects an instance based on weights
 func (b *WeightedRandomBalancer) Select(instances []core.ServiceInstance) (*core.ServiceInstance, error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	
+	if b.slowStart != nil {
+		b.slowStart.observe(instances)
+	}
+	
 	// Build weighted list of healthy instances
 	var weightedList []weightedInstance
 	totalWeight := 0
@@ -159,6 +188,9 @@ func (b *WeightedRandomBalancer) Select(instances []core.ServiceInstance) (*core
 	for _, inst := range instances {
 		if inst.Healthy {
 			weight := b.getWeight(inst)
+			if b.slowStart != nil {
+				weight = b.slowStart.weight(inst.ID, weight)
+			}
 			if weight > 0 {
 				weightedList = append(weightedList, weightedInstance{
 					instance: inst,
@@ -0,0 +1,47 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"gateway/internal/core"
+)
+
+// matchedRouteKey carries the route of a request from the route-aware
+// handler back out to WrapHandler
+type matchedRouteKey struct{}
+
+// matchedRoute is the route recorded by Annotate
+type matchedRoute struct {
+	rule *core.RouteRule
+}
+
+// Annotate records the route of requests, so that WrapHandler can check
+// their latency budget. It must run inside the route-aware handler.
+func (m *Middleware) Annotate(next core.Handler) core.Handler {
+	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		if matched, ok := ctx.Value(matchedRouteKey{}).(*matchedRoute); ok {
+			if route := core.RouteResultFromContext(ctx); route != nil {
+				matched.rule = route.Rule
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// checkLatencyBudget logs and counts requests taking longer than the
+// latency budget of their route. The response is left as it is.
+func (m *Middleware) checkLatencyBudget(ctx context.Context, req core.Request, rule *core.RouteRule, duration time.Duration) {
+	if rule == nil || rule.LatencyBudget <= 0 || duration <= rule.LatencyBudget {
+		return
+	}
+
+	m.logger.With(traceLogAttrs(ctx, req.ID())...).Warn("Request exceeded latency budget",
+		"route", rule.ID,
+		"method", req.Method(),
+		"path", req.Path(),
+		"duration", duration,
+		"budget", rule.LatencyBudget,
+	)
+	m.metrics.RecordLatencyBudgetExceeded(ctx, rule.ID)
+}
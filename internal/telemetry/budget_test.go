@@ -0,0 +1,69 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"gateway/internal/core"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestMiddleware_LatencyBudget(t *testing.T) {
+	m, _, reader := newTestMiddleware(t)
+
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(previous)
+
+	// The route-aware handler puts the route in the context, then calls
+	// the annotated handler
+	routed := func(rule *core.RouteRule, delay time.Duration) core.Handler {
+		annotated := m.Annotate(func(ctx context.Context, req core.Request) (core.Response, error) {
+			time.Sleep(delay)
+			return &testResponse{statusCode: 200}, nil
+		})
+		return m.WrapHandler("test", func(ctx context.Context, req core.Request) (core.Response, error) {
+			return annotated(core.WithRouteResult(ctx, &core.RouteResult{Rule: rule}), req)
+		})
+	}
+
+	slow := &core.RouteRule{ID: "slow", LatencyBudget: 10 * time.Millisecond}
+	fast := &core.RouteRule{ID: "fast", LatencyBudget: time.Minute}
+	unbudgeted := &core.RouteRule{ID: "unbudgeted"}
+
+	for _, handler := range []core.Handler{
+		routed(slow, 30*time.Millisecond),
+		routed(fast, 30*time.Millisecond),
+		routed(unbudgeted, 30*time.Millisecond),
+	} {
+		resp, err := handler(context.Background(), &testRequest{id: "req-1"})
+		if err != nil || resp.StatusCode() != 200 {
+			t.Fatalf("Expected the response to be left alone, got %v, %v", resp, err)
+		}
+	}
+
+	if got := counterValue(t, reader, "gateway_route_latency_budget_exceeded_total", attribute.String("route", "slow")); got != 1 {
+		t.Errorf("Expected 1 request over the slow route's budget, got %d", got)
+	}
+	for _, route := range []string{"fast", "unbudgeted"} {
+		if got := counterValue(t, reader, "gateway_route_latency_budget_exceeded_total", attribute.String("route", route)); got != 0 {
+			t.Errorf("Expected no request over the %s route's budget, got %d", route, got)
+		}
+	}
+
+	output := logs.String()
+	if strings.Count(output, "Request exceeded latency budget") != 1 {
+		t.Fatalf("Expected one slow request log, got %s", output)
+	}
+	for _, want := range []string{"request_id=req-1", "trace_id=", "span_id=", "route=slow", "budget=10ms"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %s in log output, got %s", want, output)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/exporters/zipkin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Supported trace exporters
+const (
+	ExporterOTLP   = "otlp"
+	ExporterZipkin = "zipkin"
+	ExporterJaeger = "jaeger"
+	ExporterStdout = "stdout"
+)
+
+const (
+	defaultZipkinEndpoint = "http://localhost:9411/api/v2/spans"
+	defaultJaegerEndpoint = "http://localhost:4318/v1/traces"
+)
+
+// newSpanExporter creates the span exporter selected by the tracing config
+func newSpanExporter(ctx context.Context, config TracingConfig) (sdktrace.SpanExporter, error) {
+	switch config.Exporter {
+	case "", ExporterOTLP:
+		return newOTLPExporter(ctx, config.Endpoint, config.Headers)
+	case ExporterJaeger:
+		// Jaeger ingests OTLP natively; its dedicated exporter is deprecated
+		endpoint := config.Endpoint
+		if endpoint == "" {
+			endpoint = defaultJaegerEndpoint
+		}
+		return newOTLPExporter(ctx, endpoint, config.Headers)
+	case ExporterZipkin:
+		endpoint := config.Endpoint
+		if endpoint == "" {
+			endpoint = defaultZipkinEndpoint
+		}
+		return zipkin.New(endpoint, zipkin.WithHeaders(config.Headers))
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter %q", config.Exporter)
+	}
+}
+
+// newOTLPExporter creates an OTLP/HTTP exporter. The endpoint may be a
+// host:port or a full URL; URLs select the scheme and path as given.
+func newOTLPExporter(ctx context.Context, endpoint string, headers map[string]string) (sdktrace.SpanExporter, error) {
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithTimeout(time.Second * 30),
+		otlptracehttp.WithRetry(otlptracehttp.RetryConfig{
+			Enabled:         true,
+			InitialInterval: 5 * time.Second,
+			MaxInterval:     30 * time.Second,
+			MaxElapsedTime:  time.Minute,
+		}),
+	}
+
+	if strings.Contains(endpoint, "://") {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	} else if endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+	}
+
+	if len(headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(headers))
+	}
+
+	return otlptracehttp.New(ctx, opts...)
+}
@@ -0,0 +1,64 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewSpanExporter(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TracingConfig
+		wantErr bool
+	}{
+		{"default", TracingConfig{}, false},
+		{"otlp", TracingConfig{Exporter: ExporterOTLP, Endpoint: "collector:4318"}, false},
+		{"otlp url", TracingConfig{Exporter: ExporterOTLP, Endpoint: "http://collector:4318/v1/traces"}, false},
+		{"jaeger", TracingConfig{Exporter: ExporterJaeger}, false},
+		{"zipkin", TracingConfig{Exporter: ExporterZipkin, Headers: map[string]string{"api-key": "secret"}}, false},
+		{"stdout", TracingConfig{Exporter: ExporterStdout}, false},
+		{"zipkin invalid endpoint", TracingConfig{Exporter: ExporterZipkin, Endpoint: "://invalid"}, true},
+		{"unknown", TracingConfig{Exporter: "datadog"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter, err := newSpanExporter(context.Background(), tt.config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newSpanExporter failed: %v", err)
+			}
+			exporter.Shutdown(context.Background())
+		})
+	}
+}
+
+func TestNew_TracingExporter(t *testing.T) {
+	tel, err := New(Config{
+		Enabled: true,
+		Service: "gateway",
+		Tracing: TracingConfig{
+			Enabled:      true,
+			Exporter:     ExporterZipkin,
+			SampleRate:   0.5,
+			MaxBatchSize: 10,
+			BatchTimeout: 1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	tel.Shutdown(context.Background())
+
+	if _, err := New(Config{
+		Enabled: true,
+		Tracing: TracingConfig{Enabled: true, Exporter: "unknown"},
+	}); err == nil {
+		t.Error("Expected error for unknown exporter")
+	}
+}
@@ -0,0 +1,18 @@
+package telemetry
+
+import (
+	"context"
+)
+
+// traceLogAttrs returns the request id, when known, and the active trace and
+// span ids as log attributes linking a log line to the request's trace
+func traceLogAttrs(ctx context.Context, requestID string) []any {
+	var attrs []any
+	if requestID != "" {
+		attrs = append(attrs, "request_id", requestID)
+	}
+	if traceID := ExtractTraceID(ctx); traceID != "" {
+		attrs = append(attrs, "trace_id", traceID, "span_id", ExtractSpanID(ctx))
+	}
+	return attrs
+}
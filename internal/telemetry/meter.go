@@ -6,11 +6,21 @@ import (
 	"sync"
 	"time"
 
+	gwerrors "gateway/pkg/errors"
+
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
 
+// Backend error classes recorded by gateway_backend_errors_total
+const (
+	BackendErrorTimeout        = "timeout"
+	BackendErrorConnectFailure = "connect-failure"
+	BackendError5xx            = "5xx"
+	BackendErrorOther          = "other"
+)
+
 // Metrics holds all gateway metrics
 type Metrics struct {
 	meter metric.Meter
@@ -29,6 +39,11 @@ type Metrics struct {
 	wsMessagesReceived   metric.Int64Counter
 	wsBytesSent          metric.Int64Counter
 	wsBytesReceived      metric.Int64Counter
+	wsPoolAcquisitions   metric.Int64Counter
+	
+	// gRPC passthrough metrics
+	grpcBytesSent        metric.Int64Counter
+	grpcBytesReceived    metric.Int64Counter
 	
 	// SSE metrics
 	sseConnectionsTotal  metric.Int64Counter
@@ -41,6 +56,8 @@ type Metrics struct {
 	backendRequestDuration metric.Float64Histogram
 	backendActiveRequests  metric.Int64UpDownCounter
 	backendErrors          metric.Int64Counter
+	backendRequestSize     metric.Int64Histogram
+	backendResponseSize    metric.Int64Histogram
 	
 	// Circuit breaker metrics
 	circuitBreakerState    metric.Int64ObservableGauge
@@ -50,10 +67,47 @@ type Metrics struct {
 	rateLimitRequests      metric.Int64Counter
 	rateLimitExceeded      metric.Int64Counter
 	
+	// Retry metrics
+	retryBudgetExhausted   metric.Int64Counter
+	
+	// Traffic split metrics
+	trafficSplitRequests   metric.Int64Counter
+	
+	// Mirror metrics
+	mirrorRequestsTotal    metric.Int64Counter
+	mirrorRequestDuration  metric.Float64Histogram
+	mirrorErrors           metric.Int64Counter
+	
+	// API key metrics
+	apiKeyAuthentications  metric.Int64Counter
+	
+	// Shadow authentication metrics
+	shadowAuthDecisions    metric.Int64Counter
+	
+	// RBAC metrics
+	rbacCacheLookups       metric.Int64Counter
+	
+	// Fallback metrics
+	fallbackResponses      metric.Int64Counter
+	
+	// Request coalescing metrics
+	coalescedRequests      metric.Int64Counter
+	
+	// Concurrency limit metrics
+	concurrencyActive      metric.Int64UpDownCounter
+	concurrencyQueued      metric.Int64UpDownCounter
+	concurrencyLimit       metric.Int64Gauge
+	priorityRequests       metric.Int64Counter
+	loadShedRate           metric.Float64Gauge
+	
 	// Connection pool metrics
 	poolActiveConnections  metric.Int64UpDownCounter
 	poolIdleConnections    metric.Int64UpDownCounter
 	poolWaitDuration       metric.Float64Histogram
+	// Interim responses received from backends
+	backendInformational metric.Int64Counter
+	// Requests over the latency budget of their route
+	latencyBudgetExceeded metric.Int64Counter
 	
 	// Service discovery metrics
 	serviceInstances       metric.Int64ObservableGauge
@@ -177,6 +231,34 @@ func (t *Telemetry) NewMetrics() (*Metrics, error) {
 		return nil, fmt.Errorf("failed to create ws_bytes_received: %w", err)
 	}
 	
+	m.wsPoolAcquisitions, err = t.meter.Int64Counter(
+		"gateway_websocket_pool_acquisitions_total",
+		metric.WithDescription("Total WebSocket backend connections handed to clients, reused or dialed"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ws_pool_acquisitions: %w", err)
+	}
+	
+	// gRPC passthrough metrics
+	m.grpcBytesSent, err = t.meter.Int64Counter(
+		"gateway_grpc_bytes_sent_total",
+		metric.WithDescription("Total bytes sent to clients of passthrough gRPC calls"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc_bytes_sent: %w", err)
+	}
+	
+	m.grpcBytesReceived, err = t.meter.Int64Counter(
+		"gateway_grpc_bytes_received_total",
+		metric.WithDescription("Total bytes received from clients of passthrough gRPC calls"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc_bytes_received: %w", err)
+	}
+	
 	// SSE metrics
 	m.sseConnectionsTotal, err = t.meter.Int64Counter(
 		"gateway_sse_connections_total",
@@ -234,6 +316,44 @@ func (t *Telemetry) NewMetrics() (*Metrics, error) {
 		return nil, fmt.Errorf("failed to create backend_request_duration: %w", err)
 	}
 	
+	m.backendActiveRequests, err = t.meter.Int64UpDownCounter(
+		"gateway_backend_active_requests",
+		metric.WithDescription("Number of active backend requests"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backend_active_requests: %w", err)
+	}
+	
+	m.backendErrors, err = t.meter.Int64Counter(
+		"gateway_backend_errors_total",
+		metric.WithDescription("Total backend errors by class"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backend_errors_total: %w", err)
+	}
+	
+	m.backendRequestSize, err = t.meter.Int64Histogram(
+		"gateway_backend_request_size_bytes",
+		metric.WithDescription("Backend request size in bytes"),
+		metric.WithUnit("By"),
+		metric.WithExplicitBucketBoundaries(100, 1000, 10000, 100000, 1000000),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backend_request_size: %w", err)
+	}
+	
+	m.backendResponseSize, err = t.meter.Int64Histogram(
+		"gateway_backend_response_size_bytes",
+		metric.WithDescription("Backend response size in bytes"),
+		metric.WithUnit("By"),
+		metric.WithExplicitBucketBoundaries(100, 1000, 10000, 100000, 1000000),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backend_response_size: %w", err)
+	}
+	
 	// Circuit breaker metrics
 	m.circuitBreakerState, err = t.meter.Int64ObservableGauge(
 		"gateway_circuit_breaker_state",
@@ -263,6 +383,198 @@ func (t *Telemetry) NewMetrics() (*Metrics, error) {
 		return nil, fmt.Errorf("failed to create rate_limit_exceeded: %w", err)
 	}
 	
+	// Retry metrics
+	m.retryBudgetExhausted, err = t.meter.Int64Counter(
+		"gateway_retry_budget_exhausted_total",
+		metric.WithDescription("Total retries skipped because the retry budget was exhausted"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create retry_budget_exhausted: %w", err)
+	}
+	
+	// Traffic split metrics
+	m.trafficSplitRequests, err = t.meter.Int64Counter(
+		"gateway_traffic_split_requests_total",
+		metric.WithDescription("Total requests of split routes by chosen service"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create traffic_split_requests: %w", err)
+	}
+	
+	// Mirror metrics
+	m.mirrorRequestsTotal, err = t.meter.Int64Counter(
+		"gateway_mirror_requests_total",
+		metric.WithDescription("Total number of mirrored requests"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mirror_requests_total: %w", err)
+	}
+	
+	m.mirrorRequestDuration, err = t.meter.Float64Histogram(
+		"gateway_mirror_request_duration_seconds",
+		metric.WithDescription("Mirrored request duration in seconds"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mirror_request_duration: %w", err)
+	}
+	
+	m.mirrorErrors, err = t.meter.Int64Counter(
+		"gateway_mirror_errors_total",
+		metric.WithDescription("Total number of mirrored requests that failed"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mirror_errors: %w", err)
+	}
+	
+	// API key metrics
+	m.apiKeyAuthentications, err = t.meter.Int64Counter(
+		"gateway_apikey_authentications_total",
+		metric.WithDescription("Total API key authentications by key and outcome"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create apikey_authentications: %w", err)
+	}
+	
+	// Shadow authentication metrics
+	m.shadowAuthDecisions, err = t.meter.Int64Counter(
+		"gateway_auth_shadow_decisions_total",
+		metric.WithDescription("Total authentication decisions made in shadow mode by outcome and reason"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth_shadow_decisions: %w", err)
+	}
+	
+	// RBAC metrics
+	m.rbacCacheLookups, err = t.meter.Int64Counter(
+		"gateway_rbac_cache_lookups_total",
+		metric.WithDescription("Total RBAC permission cache lookups by result"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rbac_cache_lookups: %w", err)
+	}
+	
+	// Fallback metrics
+	m.fallbackResponses, err = t.meter.Int64Counter(
+		"gateway_fallback_responses_total",
+		metric.WithDescription("Total fallback responses served instead of backend errors"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fallback_responses: %w", err)
+	}
+	
+	// Request coalescing metrics
+	m.coalescedRequests, err = t.meter.Int64Counter(
+		"gateway_coalesced_requests_total",
+		metric.WithDescription("Total requests answered with the response of an identical in-flight request"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create coalesced_requests: %w", err)
+	}
+	
+	// Concurrency limit metrics
+	m.concurrencyActive, err = t.meter.Int64UpDownCounter(
+		"gateway_concurrency_active_requests",
+		metric.WithDescription("Number of requests holding a slot of a concurrency limit"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create concurrency_active_requests: %w", err)
+	}
+	
+	m.concurrencyQueued, err = t.meter.Int64UpDownCounter(
+		"gateway_concurrency_queued_requests",
+		metric.WithDescription("Number of requests waiting for a slot of a concurrency limit"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create concurrency_queued_requests: %w", err)
+	}
+	
+	m.concurrencyLimit, err = t.meter.Int64Gauge(
+		"gateway_concurrency_limit",
+		metric.WithDescription("Current cap of an adaptive concurrency limit"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create concurrency_limit: %w", err)
+	}
+	
+	m.priorityRequests, err = t.meter.Int64Counter(
+		"gateway_priority_requests_total",
+		metric.WithDescription("Total requests admitted or shed under concurrency limits by priority class"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create priority_requests: %w", err)
+	}
+
+	m.loadShedRate, err = t.meter.Float64Gauge(
+		"gateway_load_shed_rate",
+		metric.WithDescription("Share of requests shed while the gateway is overloaded"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create load_shed_rate: %w", err)
+	}
+	
+	// Connection pool metrics
+	m.poolActiveConnections, err = t.meter.Int64UpDownCounter(
+		"gateway_backend_pool_active_connections",
+		metric.WithDescription("Number of backend connections serving a request"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pool_active_connections: %w", err)
+	}
+	
+	m.poolIdleConnections, err = t.meter.Int64UpDownCounter(
+		"gateway_backend_pool_idle_connections",
+		metric.WithDescription("Number of idle backend connections in the pool"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pool_idle_connections: %w", err)
+	}
+	
+	m.poolWaitDuration, err = t.meter.Float64Histogram(
+		"gateway_backend_pool_wait_duration_seconds",
+		metric.WithDescription("Time spent waiting for a backend connection in seconds"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.0001, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pool_wait_duration: %w", err)
+	}
+	
+	m.backendInformational, err = t.meter.Int64Counter(
+		"gateway_backend_informational_responses_total",
+		metric.WithDescription("Total number of interim (1xx) responses received from backends"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backend_informational_responses: %w", err)
+	}
+	
+	m.latencyBudgetExceeded, err = t.meter.Int64Counter(
+		"gateway_route_latency_budget_exceeded_total",
+		metric.WithDescription("Total number of requests taking longer than the latency budget of their route"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create route_latency_budget_exceeded: %w", err)
+	}
+	
 	// Service discovery metrics
 	m.serviceInstances, err = t.meter.Int64ObservableGauge(
 		"gateway_service_instances",
@@ -340,6 +652,33 @@ func (m *Metrics) RecordWebSocketMessage(ctx context.Context, direction string,
 	}
 }
 
+// RecordWebSocketPoolAcquire records a WebSocket backend connection handed
+// to a client, reused from the pool or newly dialed
+func (m *Metrics) RecordWebSocketPoolAcquire(ctx context.Context, host string, reused bool) {
+	result := "dialed"
+	if reused {
+		result = "reused"
+	}
+	m.wsPoolAcquisitions.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("host", host),
+		attribute.String("result", result),
+	))
+}
+
+// RecordGRPCBytes records bytes proxied for a passthrough gRPC call
+func (m *Metrics) RecordGRPCBytes(ctx context.Context, service, direction string, size int64) {
+	attrs := []attribute.KeyValue{
+		attribute.String("service", service),
+		attribute.String("direction", direction),
+	}
+	
+	if direction == "sent" {
+		m.grpcBytesSent.Add(ctx, size, metric.WithAttributes(attrs...))
+	} else {
+		m.grpcBytesReceived.Add(ctx, size, metric.WithAttributes(attrs...))
+	}
+}
+
 // RecordSSEConnection records SSE connection
 func (m *Metrics) RecordSSEConnection(ctx context.Context, route string) {
 	attrs := []attribute.KeyValue{
@@ -371,15 +710,125 @@ func (m *Metrics) RecordBackendRequest(ctx context.Context, service, instance st
 	m.backendRequestDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(attrs...))
 	
 	if statusCode >= 500 {
-		m.backendErrors.Add(ctx, 1, metric.WithAttributes(attrs...))
+		m.recordBackendError(ctx, service, instance, BackendError5xx)
 	}
 }
 
+// RecordBackendError records a failed backend request by error class
+func (m *Metrics) RecordBackendError(ctx context.Context, service, instance string, err error) {
+	m.recordBackendError(ctx, service, instance, backendErrorClass(err))
+}
+
+// recordBackendError increments the backend error counter for a class
+func (m *Metrics) recordBackendError(ctx context.Context, service, instance, class string) {
+	m.backendErrors.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("service", service),
+		attribute.String("instance", instance),
+		attribute.String("class", class),
+	))
+}
+
+// RecordBackendRequestSize records the size of a request sent to a backend
+func (m *Metrics) RecordBackendRequestSize(ctx context.Context, service, instance string, size int64) {
+	m.backendRequestSize.Record(ctx, size, metric.WithAttributes(
+		attribute.String("service", service),
+		attribute.String("instance", instance),
+	))
+}
+
+// RecordBackendResponseSize records the size of a response received from a backend
+func (m *Metrics) RecordBackendResponseSize(ctx context.Context, service, instance string, size int64) {
+	m.backendResponseSize.Record(ctx, size, metric.WithAttributes(
+		attribute.String("service", service),
+		attribute.String("instance", instance),
+	))
+}
+
 // RecordBackendActiveRequest updates active backend requests
 func (m *Metrics) RecordBackendActiveRequest(ctx context.Context, delta int64) {
 	m.backendActiveRequests.Add(ctx, delta)
 }
 
+// backendErrorClass classifies a backend error as a timeout or connect failure
+func backendErrorClass(err error) string {
+	switch {
+	case gwerrors.IsTimeout(err):
+		return BackendErrorTimeout
+	case gwerrors.IsConnectFailure(err):
+		return BackendErrorConnectFailure
+	}
+	return BackendErrorOther
+}
+
+// RecordConcurrency updates the requests active and queued under a
+// concurrency limit
+func (m *Metrics) RecordConcurrency(ctx context.Context, limit string, activeDelta, queuedDelta int64) {
+	attrs := metric.WithAttributes(attribute.String("limit", limit))
+	if activeDelta != 0 {
+		m.concurrencyActive.Add(ctx, activeDelta, attrs)
+	}
+	if queuedDelta != 0 {
+		m.concurrencyQueued.Add(ctx, queuedDelta, attrs)
+	}
+}
+
+// RecordConcurrencyLimit records the current cap of an adaptive
+// concurrency limit
+func (m *Metrics) RecordConcurrencyLimit(ctx context.Context, limit string, value int64) {
+	m.concurrencyLimit.Record(ctx, value, metric.WithAttributes(attribute.String("limit", limit)))
+}
+
+// RecordPriorityRequest records a request of a priority class admitted or
+// shed by concurrency limits
+func (m *Metrics) RecordPriorityRequest(ctx context.Context, class string, admitted bool) {
+	outcome := "shed"
+	if admitted {
+		outcome = "admitted"
+	}
+	m.priorityRequests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("class", class),
+		attribute.String("outcome", outcome),
+	))
+}
+
+// RecordLoadShedRate records the share of requests shed under load
+func (m *Metrics) RecordLoadShedRate(ctx context.Context, rate float64) {
+	m.loadShedRate.Record(ctx, rate)
+}
+
+// RecordPoolConnections updates active and idle pooled connections for a backend host
+func (m *Metrics) RecordPoolConnections(ctx context.Context, host string, activeDelta, idleDelta int64) {
+	attrs := metric.WithAttributes(attribute.String("host", host))
+	if activeDelta != 0 {
+		m.poolActiveConnections.Add(ctx, activeDelta, attrs)
+	}
+	if idleDelta != 0 {
+		m.poolIdleConnections.Add(ctx, idleDelta, attrs)
+	}
+}
+
+// RecordPoolWait records the time spent waiting for a backend connection
+func (m *Metrics) RecordPoolWait(ctx context.Context, host string, wait time.Duration) {
+	m.poolWaitDuration.Record(ctx, wait.Seconds(), metric.WithAttributes(attribute.String("host", host)))
+}
+
+// RecordBackendInformational records an interim (1xx) response received
+// from a backend
+func (m *Metrics) RecordBackendInformational(ctx context.Context, host string, status int) {
+	m.backendInformational.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("host", host),
+		attribute.Int("status", status),
+	))
+}
+
+// RecordLatencyBudgetExceeded records a request taking longer than the
+// latency budget of its route
+func (m *Metrics) RecordLatencyBudgetExceeded(ctx context.Context, route string) {
+	m.latencyBudgetExceeded.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("route", route),
+	))
+}
+
 // RecordCircuitBreakerState records circuit breaker state
 func (m *Metrics) RecordCircuitBreakerState(ctx context.Context, service string, state int64) {
 	attrs := []attribute.KeyValue{
@@ -408,7 +857,91 @@ func (m *Metrics) RecordRateLimit(ctx context.Context, key string, allowed bool)
 	}
 }
 
-// RecordServiceInstances records service instance count
+// RecordRetryBudgetExhausted records a retry skipped due to an exhausted budget
+func (m *Metrics) RecordRetryBudgetExhausted(ctx context.Context, service string) {
+	m.retryBudgetExhausted.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("service", service),
+	))
+}
+
+// RecordTrafficSplit records the service a split route sent a request to
+func (m *Metrics) RecordTrafficSplit(ctx context.Context, route, service string) {
+	m.trafficSplitRequests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("route", route),
+		attribute.String("service", service),
+	))
+}
+
+// RecordMirrorRequest records a mirrored request that got a response
+func (m *Metrics) RecordMirrorRequest(ctx context.Context, route, service string, statusCode int, duration time.Duration) {
+	attrs := []attribute.KeyValue{
+		attribute.String("route", route),
+		attribute.String("service", service),
+	}
+	
+	m.mirrorRequestsTotal.Add(ctx, 1, metric.WithAttributes(append(attrs, attribute.Int("status", statusCode))...))
+	m.mirrorRequestDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// RecordMirrorError records a mirrored request that failed
+func (m *Metrics) RecordMirrorError(ctx context.Context, route, service string) {
+	m.mirrorErrors.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("route", route),
+		attribute.String("service", service),
+	))
+}
+
+// RecordAPIKeyAuthentication records an API key authentication; reason is
+// valid, invalid, disabled or expired
+func (m *Metrics) RecordAPIKeyAuthentication(ctx context.Context, keyID, reason string) {
+	m.apiKeyAuthentications.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("key_id", keyID),
+		attribute.String("reason", reason),
+	))
+}
+
+// RecordShadowAuthDecision records the decision authentication would have
+// made on a request in shadow mode
+func (m *Metrics) RecordShadowAuthDecision(ctx context.Context, allowed bool, reason string) {
+	outcome := "denied"
+	if allowed {
+		outcome = "allowed"
+	}
+	m.shadowAuthDecisions.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("outcome", outcome),
+		attribute.String("reason", reason),
+	))
+}
+
+// RecordRBACCacheLookup records a lookup of the RBAC permission cache
+func (m *Metrics) RecordRBACCacheLookup(ctx context.Context, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.rbacCacheLookups.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("result", result),
+	))
+}
+
+// RecordFallback records a fallback served for a route; trigger is the
+// failure it answered and kind is static or service
+func (m *Metrics) RecordFallback(ctx context.Context, route, trigger, kind string) {
+	m.fallbackResponses.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("route", route),
+		attribute.String("trigger", trigger),
+		attribute.String("kind", kind),
+	))
+}
+
+// RecordCoalescedRequest records a request of route answered with the
+// response of an identical in-flight request
+func (m *Metrics) RecordCoalescedRequest(ctx context.Context, route string) {
+	m.coalescedRequests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("route", route),
+	))
+}
+
 func (m *Metrics) RecordServiceInstances(ctx context.Context, service string, total, healthy int64) {
 	attrs := []attribute.KeyValue{
 		attribute.String("service", service),
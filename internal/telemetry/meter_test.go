@@ -0,0 +1,135 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	gwerrors "gateway/pkg/errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// newTestMetrics creates metrics backed by a manual reader
+func newTestMetrics(t *testing.T) (*Metrics, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	telemetry := &Telemetry{meter: provider.Meter("test")}
+	metrics, err := telemetry.NewMetrics()
+	if err != nil {
+		t.Fatalf("NewMetrics failed: %v", err)
+	}
+	return metrics, reader
+}
+
+// counterValue sums an int64 counter's data points matching the given attributes
+func counterValue(t *testing.T, reader *sdkmetric.ManualReader, name string, attrs ...attribute.KeyValue) int64 {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	var total int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				t.Fatalf("Expected %s to be an int64 sum, got %T", name, m.Data)
+			}
+		dataPoints:
+			for _, dp := range sum.DataPoints {
+				for _, attr := range attrs {
+					if value, ok := dp.Attributes.Value(attr.Key); !ok || value != attr.Value {
+						continue dataPoints
+					}
+				}
+				total += dp.Value
+			}
+		}
+	}
+	return total
+}
+
+func TestMetrics_RecordBackendRequestError(t *testing.T) {
+	metrics, reader := newTestMetrics(t)
+	ctx := context.Background()
+
+	metrics.RecordBackendRequest(ctx, "users", "users-1", 200, 10*time.Millisecond)
+	if got := counterValue(t, reader, "gateway_backend_errors_total"); got != 0 {
+		t.Errorf("Expected no backend errors after 200, got %d", got)
+	}
+
+	metrics.RecordBackendRequest(ctx, "users", "users-1", 500, 10*time.Millisecond)
+	got := counterValue(t, reader, "gateway_backend_errors_total",
+		attribute.String("service", "users"),
+		attribute.String("instance", "users-1"),
+		attribute.String("class", BackendError5xx),
+	)
+	if got != 1 {
+		t.Errorf("Expected 1 backend 5xx error, got %d", got)
+	}
+}
+
+func TestMetrics_RecordBackendError(t *testing.T) {
+	metrics, reader := newTestMetrics(t)
+	ctx := context.Background()
+
+	metrics.RecordBackendError(ctx, "users", "users-1", context.DeadlineExceeded)
+	metrics.RecordBackendError(ctx, "users", "users-2", syscall.ECONNREFUSED)
+
+	if got := counterValue(t, reader, "gateway_backend_errors_total",
+		attribute.String("instance", "users-1"),
+		attribute.String("class", BackendErrorTimeout),
+	); got != 1 {
+		t.Errorf("Expected 1 timeout error for users-1, got %d", got)
+	}
+	if got := counterValue(t, reader, "gateway_backend_errors_total",
+		attribute.String("instance", "users-2"),
+		attribute.String("class", BackendErrorConnectFailure),
+	); got != 1 {
+		t.Errorf("Expected 1 connect failure for users-2, got %d", got)
+	}
+
+	// Active requests and size histograms are initialized
+	metrics.RecordBackendActiveRequest(ctx, 1)
+	metrics.RecordBackendRequestSize(ctx, "users", "users-1", 512)
+	metrics.RecordBackendResponseSize(ctx, "users", "users-1", 2048)
+}
+
+func TestBackendErrorClass(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"deadline exceeded", context.DeadlineExceeded, BackendErrorTimeout},
+		{"gateway timeout", gwerrors.NewError(gwerrors.ErrorTypeTimeout, "timed out"), BackendErrorTimeout},
+		{"connection refused", fmt.Errorf("send: %w", syscall.ECONNREFUSED), BackendErrorConnectFailure},
+		{"dial error", &net.OpError{Op: "dial", Err: errors.New("no route to host")}, BackendErrorConnectFailure},
+		{"unavailable", gwerrors.NewError(gwerrors.ErrorTypeUnavailable, "backend unavailable"), BackendErrorConnectFailure},
+		{"other", errors.New("boom"), BackendErrorOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backendErrorClass(tt.err); got != tt.expected {
+				t.Errorf("backendErrorClass(%v) = %s, want %s", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
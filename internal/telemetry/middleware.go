@@ -2,10 +2,12 @@ package telemetry
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
@@ -16,13 +18,18 @@ import (
 type Middleware struct {
 	telemetry *Telemetry
 	metrics   *Metrics
+	logger    *slog.Logger
 }
 
 // NewMiddleware creates a new telemetry middleware
-func NewMiddleware(telemetry *Telemetry, metrics *Metrics) *Middleware {
+func NewMiddleware(telemetry *Telemetry, metrics *Metrics, logger *slog.Logger) *Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
 	return &Middleware{
 		telemetry: telemetry,
 		metrics:   metrics,
+		logger:    logger,
 	}
 }
 
@@ -33,6 +40,12 @@ func (m *Middleware) WrapHTTP(next http.Handler) http.Handler {
 		ctx, span := m.telemetry.StartHTTPServerSpan(r)
 		defer span.End()
 		
+		// Link the request id generated by the HTTP adapter to the span
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID != "" {
+			span.SetAttributes(attribute.String("request.id", requestID))
+		}
+		
 		// Update request context
 		r = r.WithContext(ctx)
 		
@@ -73,6 +86,11 @@ func (m *Middleware) WrapHTTP(next http.Handler) http.Handler {
 // WrapHandler wraps a core.Handler with telemetry
 func (m *Middleware) WrapHandler(name string, handler core.Handler) core.Handler {
 	return func(ctx context.Context, req core.Request) (core.Response, error) {
+		// Continue the incoming trace and capture baggage and propagated headers
+		if m.telemetry.propagator != nil {
+			ctx = m.telemetry.propagator.Extract(ctx, propagation.HeaderCarrier(req.Headers()))
+		}
+		
 		// Start span
 		ctx, span := m.telemetry.StartSpan(ctx, name,
 			trace.WithSpanKind(trace.SpanKindInternal),
@@ -80,10 +98,15 @@ func (m *Middleware) WrapHandler(name string, handler core.Handler) core.Handler
 				attribute.String("handler.name", name),
 				attribute.String("request.method", req.Method()),
 				attribute.String("request.path", req.Path()),
+				attribute.String("request.id", req.ID()),
 			),
 		)
 		defer span.End()
 		
+		// Annotate records the route for the latency budget
+		matched := &matchedRoute{}
+		ctx = context.WithValue(ctx, matchedRouteKey{}, matched)
+		
 		// Time the handler
 		start := time.Now()
 		
@@ -93,9 +116,12 @@ func (m *Middleware) WrapHandler(name string, handler core.Handler) core.Handler
 		// Record duration
 		duration := time.Since(start)
 		span.SetAttributes(attribute.Float64("handler.duration_ms", float64(duration.Milliseconds())))
+		m.checkLatencyBudget(ctx, req, matched.rule, duration)
 		
 		// Handle error
 		if err != nil {
+			// The span context attaches the trace id as an exemplar
+			m.metrics.RecordHTTPRequest(ctx, req.Method(), req.Path(), gwerrors.HTTPStatus(err), duration)
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
 			return resp, err
@@ -104,6 +130,7 @@ func (m *Middleware) WrapHandler(name string, handler core.Handler) core.Handler
 		// Set response attributes
 		if resp != nil {
 			statusCode := resp.StatusCode()
+			m.metrics.RecordHTTPRequest(ctx, req.Method(), req.Path(), statusCode, duration)
 			span.SetAttributes(
 				attribute.Int("response.status", statusCode),
 			)
@@ -0,0 +1,159 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"gateway/internal/core"
+	gwerrors "gateway/pkg/errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// testRequest implements core.Request
+type testRequest struct {
+	id      string
+	headers map[string][]string
+}
+
+func (r *testRequest) ID() string                   { return r.id }
+func (r *testRequest) Method() string               { return "GET" }
+func (r *testRequest) Path() string                 { return "/users" }
+func (r *testRequest) URL() string                  { return "http://example.com/users" }
+func (r *testRequest) RemoteAddr() string           { return "127.0.0.1:12345" }
+func (r *testRequest) Headers() map[string][]string { return r.headers }
+func (r *testRequest) Body() io.ReadCloser          { return io.NopCloser(strings.NewReader("")) }
+func (r *testRequest) Context() context.Context     { return context.Background() }
+
+// testResponse implements core.Response
+type testResponse struct {
+	statusCode int
+}
+
+func (r *testResponse) StatusCode() int              { return r.statusCode }
+func (r *testResponse) Headers() map[string][]string { return map[string][]string{} }
+func (r *testResponse) Body() io.ReadCloser          { return io.NopCloser(strings.NewReader("")) }
+
+// newTestMiddleware creates a middleware recording spans and metrics in memory
+func newTestMiddleware(t *testing.T) (*Middleware, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithExemplarFilter(exemplar.TraceBasedFilter),
+	)
+	t.Cleanup(func() {
+		tp.Shutdown(context.Background())
+		mp.Shutdown(context.Background())
+	})
+
+	telemetry := &Telemetry{
+		tracer: tp.Tracer("test"),
+		meter:  mp.Meter("test"),
+	}
+	metrics, err := telemetry.NewMetrics()
+	if err != nil {
+		t.Fatalf("NewMetrics failed: %v", err)
+	}
+	return NewMiddleware(telemetry, metrics, nil), spans, reader
+}
+
+func TestMiddleware_WrapHandler_TraceCorrelation(t *testing.T) {
+	m, spans, reader := newTestMiddleware(t)
+
+	handler := m.WrapHandler("test", func(ctx context.Context, req core.Request) (core.Response, error) {
+		return &testResponse{statusCode: 200}, nil
+	})
+	if _, err := handler(context.Background(), &testRequest{id: "req-1"}); err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(ended))
+	}
+	span := ended[0]
+	traceID := span.SpanContext().TraceID().String()
+
+	// Request id is linked to the span
+	found := false
+	for _, attr := range span.Attributes() {
+		if attr.Key == "request.id" && attr.Value.AsString() == "req-1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected request.id attribute on span")
+	}
+
+	// Duration histogram carries the trace id as an exemplar
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	var exemplarTraceID string
+	for _, sm := range rm.ScopeMetrics {
+		for _, metric := range sm.Metrics {
+			if metric.Name != "gateway_http_request_duration_seconds" {
+				continue
+			}
+			histogram := metric.Data.(metricdata.Histogram[float64])
+			for _, dp := range histogram.DataPoints {
+				for _, ex := range dp.Exemplars {
+					exemplarTraceID = hex.EncodeToString(ex.TraceID)
+				}
+			}
+		}
+	}
+	if exemplarTraceID != traceID {
+		t.Errorf("Expected exemplar trace id %s, got %q", traceID, exemplarTraceID)
+	}
+}
+
+func TestMiddleware_WrapHandler_ContinuesIncomingTrace(t *testing.T) {
+	m, spans, _ := newTestMiddleware(t)
+	m.telemetry.propagator = propagation.TraceContext{}
+
+	handler := m.WrapHandler("test", func(ctx context.Context, req core.Request) (core.Response, error) {
+		return &testResponse{statusCode: 200}, nil
+	})
+	req := &testRequest{id: "req-3", headers: map[string][]string{
+		"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}}
+	if _, err := handler(context.Background(), req); err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	span := spans.Ended()[0]
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected span to continue incoming trace, got %s", got)
+	}
+	if got := span.Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("Expected incoming span as parent, got %s", got)
+	}
+}
+
+func TestMiddleware_WrapHandler_ErrorStatus(t *testing.T) {
+	m, _, reader := newTestMiddleware(t)
+
+	handler := m.WrapHandler("test", func(ctx context.Context, req core.Request) (core.Response, error) {
+		return nil, gwerrors.NewError(gwerrors.ErrorTypeNotFound, "no route")
+	})
+	handler(context.Background(), &testRequest{id: "req-2"})
+
+	if got := counterValue(t, reader, "gateway_http_requests_total", attribute.Int("http.status_code", 404)); got != 1 {
+		t.Errorf("Expected 1 request recorded with status 404, got %d", got)
+	}
+}
@@ -2,11 +2,81 @@ package telemetry
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"go.opentelemetry.io/otel/propagation"
 )
 
+// Propagation formats
+const (
+	PropagationTraceContext = "tracecontext"
+	PropagationBaggage      = "baggage"
+)
+
+// newPropagator builds the propagator for the configured formats, W3C trace
+// context and baggage by default, plus the header allowlist
+func newPropagator(config PropagationConfig) (propagation.TextMapPropagator, error) {
+	formats := config.Formats
+	if len(formats) == 0 {
+		formats = []string{PropagationTraceContext, PropagationBaggage}
+	}
+
+	var propagators []propagation.TextMapPropagator
+	for _, format := range formats {
+		switch format {
+		case PropagationTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagationBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		default:
+			return nil, fmt.Errorf("unknown propagation format: %s", format)
+		}
+	}
+
+	if len(config.Headers) > 0 {
+		propagators = append(propagators, headerPropagator{headers: config.Headers})
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
+// propagatedHeadersKey is the context key for allowlisted request headers
+type propagatedHeadersKey struct{}
+
+// headerPropagator carries an allowlist of request headers through the
+// context so they reach backends even if the request headers are rewritten
+type headerPropagator struct {
+	headers []string
+}
+
+// Inject writes the allowlisted headers captured from the incoming request
+func (p headerPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	values, _ := ctx.Value(propagatedHeadersKey{}).(map[string]string)
+	for key, value := range values {
+		carrier.Set(key, value)
+	}
+}
+
+// Extract captures the allowlisted headers from the incoming request
+func (p headerPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	values := make(map[string]string)
+	for _, header := range p.headers {
+		if value := carrier.Get(header); value != "" {
+			values[header] = value
+		}
+	}
+	if len(values) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, propagatedHeadersKey{}, values)
+}
+
+// Fields returns the headers handled by this propagator
+func (p headerPropagator) Fields() []string {
+	return p.headers
+}
+
 // HeaderCarrier adapts http.Header to propagation.TextMapCarrier
 type HeaderCarrier http.Header
 
@@ -0,0 +1,59 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewPropagator(t *testing.T) {
+	incoming := http.Header{}
+	incoming.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	incoming.Set("Baggage", "tenant=acme")
+	incoming.Set("X-Tenant-Id", "acme")
+	incoming.Set("X-Other", "dropped")
+
+	propagator, err := newPropagator(PropagationConfig{Headers: []string{"X-Tenant-Id"}})
+	if err != nil {
+		t.Fatalf("newPropagator failed: %v", err)
+	}
+
+	ctx := propagator.Extract(context.Background(), propagation.HeaderCarrier(incoming))
+	if got := trace.SpanContextFromContext(ctx).TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected extracted trace id, got %s", got)
+	}
+	if got := baggage.FromContext(ctx).Member("tenant").Value(); got != "acme" {
+		t.Errorf("Expected baggage tenant=acme, got %q", got)
+	}
+
+	outgoing := http.Header{}
+	propagator.Inject(ctx, propagation.HeaderCarrier(outgoing))
+	for _, header := range []string{"Traceparent", "Baggage", "X-Tenant-Id"} {
+		if outgoing.Get(header) != incoming.Get(header) {
+			t.Errorf("Expected %s to be propagated, got %q", header, outgoing.Get(header))
+		}
+	}
+	if outgoing.Get("X-Other") != "" {
+		t.Error("Expected headers outside the allowlist not to be propagated")
+	}
+}
+
+func TestNewPropagator_Formats(t *testing.T) {
+	propagator, err := newPropagator(PropagationConfig{Formats: []string{PropagationTraceContext}})
+	if err != nil {
+		t.Fatalf("newPropagator failed: %v", err)
+	}
+	for _, field := range propagator.Fields() {
+		if field == "baggage" {
+			t.Error("Expected baggage to be disabled")
+		}
+	}
+
+	if _, err := newPropagator(PropagationConfig{Formats: []string{"b3"}}); err == nil {
+		t.Error("Expected error for unknown propagation format")
+	}
+}
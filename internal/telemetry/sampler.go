@@ -0,0 +1,184 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// samplingPathKeys are the span attributes carrying the request path
+var samplingPathKeys = []attribute.Key{"request.path", semconv.HTTPRouteKey}
+
+// routeRate is the sampling rate applied below a path prefix
+type routeRate struct {
+	prefix  string
+	sampler sdktrace.Sampler
+}
+
+// routeSampler samples request spans at the rate configured for the longest
+// matching path prefix, falling back to the global rate. Spans without a
+// path follow their local parent so a request is sampled as a whole.
+type routeSampler struct {
+	base   sdktrace.Sampler
+	routes []routeRate
+
+	// recordUnsampled records dropped spans so errors can still be exported
+	recordUnsampled bool
+}
+
+// newSampler creates the sampler for the tracing config
+func newSampler(config TracingConfig) sdktrace.Sampler {
+	// An unset global rate samples everything
+	var base sdktrace.Sampler
+	if config.SampleRate > 0 && config.SampleRate < 1 {
+		base = sdktrace.TraceIDRatioBased(config.SampleRate)
+	} else {
+		base = sdktrace.AlwaysSample()
+	}
+
+	if len(config.RouteSampleRates) == 0 && !config.SampleErrors {
+		return base
+	}
+
+	s := &routeSampler{
+		base:            base,
+		recordUnsampled: config.SampleErrors,
+	}
+	for prefix, rate := range config.RouteSampleRates {
+		s.routes = append(s.routes, routeRate{
+			prefix:  prefix,
+			sampler: rateSampler(rate),
+		})
+	}
+	// Longest prefix first
+	sort.Slice(s.routes, func(i, j int) bool {
+		return len(s.routes[i].prefix) > len(s.routes[j].prefix)
+	})
+	return s
+}
+
+// rateSampler returns a sampler for an explicit rate, where 0 never samples
+func rateSampler(rate float64) sdktrace.Sampler {
+	switch {
+	case rate <= 0:
+		return sdktrace.NeverSample()
+	case rate >= 1:
+		return sdktrace.AlwaysSample()
+	default:
+		return sdktrace.TraceIDRatioBased(rate)
+	}
+}
+
+// ShouldSample implements sdktrace.Sampler
+func (s *routeSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.sampler(p).ShouldSample(p)
+	if result.Decision == sdktrace.Drop && s.recordUnsampled {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+// sampler selects the sampler deciding for the span
+func (s *routeSampler) sampler(p sdktrace.SamplingParameters) sdktrace.Sampler {
+	if path := samplingPath(p.Attributes); path != "" {
+		for _, route := range s.routes {
+			if matchPathPrefix(path, route.prefix) {
+				return route.sampler
+			}
+		}
+		return s.base
+	}
+
+	parent := trace.SpanContextFromContext(p.ParentContext)
+	if parent.IsValid() && !parent.IsRemote() {
+		if parent.IsSampled() {
+			return sdktrace.AlwaysSample()
+		}
+		return sdktrace.NeverSample()
+	}
+	return s.base
+}
+
+// Description implements sdktrace.Sampler
+func (s *routeSampler) Description() string {
+	return fmt.Sprintf("RouteSampler{base:%s,routes:%d,errors:%t}", s.base.Description(), len(s.routes), s.recordUnsampled)
+}
+
+// samplingPath returns the request path from the span start attributes
+func samplingPath(attrs []attribute.KeyValue) string {
+	for _, key := range samplingPathKeys {
+		for _, attr := range attrs {
+			if attr.Key == key {
+				return attr.Value.AsString()
+			}
+		}
+	}
+	return ""
+}
+
+// matchPathPrefix reports whether path is prefix or lies below it
+func matchPathPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "*")
+	if prefix == "" || prefix == "/" {
+		return true
+	}
+	if strings.HasSuffix(prefix, "/") {
+		return strings.HasPrefix(path, prefix)
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// errorSpanProcessor forwards spans that were recorded but not sampled to
+// the next processor when they end with an error status
+type errorSpanProcessor struct {
+	next sdktrace.SpanProcessor
+}
+
+// newErrorSpanProcessor wraps next so failed spans are always exported
+func newErrorSpanProcessor(next sdktrace.SpanProcessor) sdktrace.SpanProcessor {
+	return &errorSpanProcessor{next: next}
+}
+
+// OnStart implements sdktrace.SpanProcessor
+func (p *errorSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+// OnEnd implements sdktrace.SpanProcessor
+func (p *errorSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() {
+		if s.Status().Code != codes.Error {
+			return
+		}
+		s = sampledSpan{ReadOnlySpan: s}
+	}
+	p.next.OnEnd(s)
+}
+
+// Shutdown implements sdktrace.SpanProcessor
+func (p *errorSpanProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush implements sdktrace.SpanProcessor
+func (p *errorSpanProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// sampledSpan marks a recorded span as sampled so it is exported
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+// SpanContext returns the span context with the sampled flag set
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
@@ -0,0 +1,136 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestTracer creates a tracer exporting synchronously to memory
+func newTestTracer(t *testing.T, config TracingConfig) (trace.Tracer, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	processor := sdktrace.NewSimpleSpanProcessor(exporter)
+	if config.SampleErrors {
+		processor = newErrorSpanProcessor(processor)
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithSampler(newSampler(config)),
+	)
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+	return tp.Tracer("test"), exporter
+}
+
+func startRequestSpan(tracer trace.Tracer, path string) (context.Context, trace.Span) {
+	return tracer.Start(context.Background(), "request",
+		trace.WithAttributes(attribute.String("request.path", path)))
+}
+
+func TestRouteSampler(t *testing.T) {
+	tracer, _ := newTestTracer(t, TracingConfig{
+		SampleRate: 0.5,
+		RouteSampleRates: map[string]float64{
+			"/health":     0,
+			"/api":        0,
+			"/api/orders": 1,
+		},
+	})
+
+	tests := []struct {
+		path    string
+		sampled bool
+	}{
+		{"/health", false},
+		{"/health/live", false},
+		{"/api/users", false},
+		{"/api/orders", true},
+		{"/api/orders/42", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// Repeat so the global rate cannot produce the expected result by chance
+			for i := 0; i < 20; i++ {
+				_, span := startRequestSpan(tracer, tt.path)
+				span.End()
+				if got := span.SpanContext().IsSampled(); got != tt.sampled {
+					t.Fatalf("Expected sampled=%t for %s, got %t", tt.sampled, tt.path, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRouteSampler_ChildFollowsParent(t *testing.T) {
+	tracer, exporter := newTestTracer(t, TracingConfig{
+		RouteSampleRates: map[string]float64{"/health": 0},
+	})
+
+	ctx, span := startRequestSpan(tracer, "/health")
+	_, child := tracer.Start(ctx, "backend")
+	child.End()
+	span.End()
+
+	ctx, span = startRequestSpan(tracer, "/users")
+	_, child = tracer.Start(ctx, "backend")
+	child.End()
+	span.End()
+
+	// Only the /users request and its backend span are exported
+	if got := len(exporter.GetSpans()); got != 2 {
+		t.Errorf("Expected 2 exported spans, got %d", got)
+	}
+}
+
+func TestErrorSpanProcessor(t *testing.T) {
+	tracer, exporter := newTestTracer(t, TracingConfig{
+		RouteSampleRates: map[string]float64{"/orders": 0},
+		SampleErrors:     true,
+	})
+
+	_, span := startRequestSpan(tracer, "/orders")
+	span.SetStatus(codes.Ok, "")
+	span.End()
+	if got := len(exporter.GetSpans()); got != 0 {
+		t.Fatalf("Expected successful unsampled span to be dropped, got %d", got)
+	}
+
+	_, span = startRequestSpan(tracer, "/orders")
+	span.SetStatus(codes.Error, "backend unavailable")
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected failed span to be exported, got %d", len(spans))
+	}
+	if !spans[0].SpanContext.IsSampled() {
+		t.Error("Expected exported error span to be marked sampled")
+	}
+}
+
+func TestMatchPathPrefix(t *testing.T) {
+	tests := []struct {
+		path, prefix string
+		expected     bool
+	}{
+		{"/health", "/health", true},
+		{"/health/live", "/health", true},
+		{"/healthz", "/health", false},
+		{"/api/users", "/api/", true},
+		{"/api/users", "/api/*", true},
+		{"/anything", "/", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchPathPrefix(tt.path, tt.prefix); got != tt.expected {
+			t.Errorf("matchPathPrefix(%q, %q) = %t, want %t", tt.path, tt.prefix, got, tt.expected)
+		}
+	}
+}
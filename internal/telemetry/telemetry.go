@@ -7,14 +7,15 @@ import (
 	"log/slog"
 	"time"
 
+	promclient "github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
@@ -27,25 +28,38 @@ type Config struct {
 	Service string `yaml:"service"`
 	Version string `yaml:"version"`
 
-	Tracing TracingConfig `yaml:"tracing"`
-	Metrics MetricsConfig `yaml:"metrics"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	Propagation PropagationConfig `yaml:"propagation"`
+}
+
+// PropagationConfig holds context propagation settings for backend requests
+type PropagationConfig struct {
+	Formats []string `yaml:"formats"` // tracecontext, baggage
+	Headers []string `yaml:"headers"` // Request headers forwarded to backends
 }
 
 // TracingConfig holds tracing configuration
 type TracingConfig struct {
 	Enabled      bool              `yaml:"enabled"`
+	Exporter     string            `yaml:"exporter"` // otlp (default), zipkin, jaeger, stdout
 	Endpoint     string            `yaml:"endpoint"`
 	Headers      map[string]string `yaml:"headers"`
 	SampleRate   float64           `yaml:"sampleRate"`
 	MaxBatchSize int               `yaml:"maxBatchSize"`
 	BatchTimeout int               `yaml:"batchTimeout"` // seconds
+
+	// Path prefix to sampling rate, overriding SampleRate; 0 never samples
+	RouteSampleRates map[string]float64 `yaml:"routeSampleRates"`
+	SampleErrors     bool               `yaml:"sampleErrors"` // Always export spans ending with an error
 }
 
 // MetricsConfig holds metrics configuration
 type MetricsConfig struct {
-	Enabled bool   `yaml:"enabled"`
-	Path    string `yaml:"path"`
-	Port    int    `yaml:"port"`
+	Enabled    bool   `yaml:"enabled"`
+	Prometheus bool   `yaml:"prometheus"` // Export to a dedicated Prometheus registry
+	Path       string `yaml:"path"`
+	Port       int    `yaml:"port"`
 }
 
 // Telemetry manages OpenTelemetry providers
@@ -56,6 +70,7 @@ type Telemetry struct {
 	shutdown     []func(context.Context) error
 	resource     *resource.Resource
 	propagator   propagation.TextMapPropagator
+	registry     *promclient.Registry
 }
 
 // New creates a new telemetry instance
@@ -99,10 +114,11 @@ func New(config Config) (*Telemetry, error) {
 	}
 
 	// Set up propagator
-	t.propagator = propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	)
+	propagator, err := newPropagator(config.Propagation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create propagator: %w", err)
+	}
+	t.propagator = propagator
 	otel.SetTextMapPropagator(t.propagator)
 
 	return t, nil
@@ -138,26 +154,8 @@ func (t *Telemetry) initResource() error {
 func (t *Telemetry) initTracing() error {
 	ctx := context.Background()
 
-	// Create OTLP trace exporter
-	opts := []otlptracehttp.Option{
-		otlptracehttp.WithTimeout(time.Second * 30),
-		otlptracehttp.WithRetry(otlptracehttp.RetryConfig{
-			Enabled:         true,
-			InitialInterval: 5 * time.Second,
-			MaxInterval:     30 * time.Second,
-			MaxElapsedTime:  time.Minute,
-		}),
-	}
-
-	if t.config.Tracing.Endpoint != "" {
-		opts = append(opts, otlptracehttp.WithEndpoint(t.config.Tracing.Endpoint))
-	}
-
-	if len(t.config.Tracing.Headers) > 0 {
-		opts = append(opts, otlptracehttp.WithHeaders(t.config.Tracing.Headers))
-	}
-
-	exporter, err := otlptracehttp.New(ctx, opts...)
+	// Create the configured span exporter
+	exporter, err := newSpanExporter(ctx, t.config.Tracing)
 	if err != nil {
 		return fmt.Errorf("failed to create trace exporter: %w", err)
 	}
@@ -171,19 +169,17 @@ func (t *Telemetry) initTracing() error {
 		batchOpts = append(batchOpts, sdktrace.WithBatchTimeout(time.Duration(t.config.Tracing.BatchTimeout)*time.Second))
 	}
 
-	// Configure sampler
-	var sampler sdktrace.Sampler
-	if t.config.Tracing.SampleRate > 0 && t.config.Tracing.SampleRate < 1 {
-		sampler = sdktrace.TraceIDRatioBased(t.config.Tracing.SampleRate)
-	} else {
-		sampler = sdktrace.AlwaysSample()
+	// Spans that end with an error bypass the sampling decision when enabled
+	processor := sdktrace.NewBatchSpanProcessor(exporter, batchOpts...)
+	if t.config.Tracing.SampleErrors {
+		processor = newErrorSpanProcessor(processor)
 	}
 
 	// Create trace provider
 	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter, batchOpts...),
+		sdktrace.WithSpanProcessor(processor),
 		sdktrace.WithResource(t.resource),
-		sdktrace.WithSampler(sampler),
+		sdktrace.WithSampler(newSampler(t.config.Tracing)),
 	)
 
 	otel.SetTracerProvider(tp)
@@ -195,16 +191,26 @@ func (t *Telemetry) initTracing() error {
 
 // initMetrics initializes the metrics provider
 func (t *Telemetry) initMetrics() error {
-	// Create Prometheus exporter
-	exporter, err := prometheus.New()
+	// Create Prometheus exporter, optionally with its own registry so the
+	// instruments can be scraped without an OTLP collector
+	var opts []prometheus.Option
+	if t.config.Metrics.Prometheus {
+		t.registry = promclient.NewRegistry()
+		// Instrument names already carry their unit, avoid _ratio suffixes
+		opts = append(opts, prometheus.WithRegisterer(t.registry), prometheus.WithoutUnits())
+	}
+	exporter, err := prometheus.New(opts...)
 	if err != nil {
 		return fmt.Errorf("failed to create metrics exporter: %w", err)
 	}
 
 	// Create meter provider
+	// Sampled spans in the recording context become exemplars, linking
+	// histogram buckets to traces
 	mp := sdkmetric.NewMeterProvider(
 		sdkmetric.WithReader(exporter),
 		sdkmetric.WithResource(t.resource),
+		sdkmetric.WithExemplarFilter(exemplar.TraceBasedFilter),
 	)
 
 	otel.SetMeterProvider(mp)
@@ -224,6 +230,15 @@ func (t *Telemetry) Meter() metric.Meter {
 	return t.meter
 }
 
+// Gatherer returns the Prometheus registry holding telemetry metrics,
+// or nil when the Prometheus exporter option is disabled
+func (t *Telemetry) Gatherer() promclient.Gatherer {
+	if t.registry == nil {
+		return nil
+	}
+	return t.registry
+}
+
 // Propagator returns the propagator
 func (t *Telemetry) Propagator() propagation.TextMapPropagator {
 	return t.propagator
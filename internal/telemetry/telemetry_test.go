@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestNew(t *testing.T) {
@@ -169,6 +170,82 @@ func TestTelemetry_Accessors(t *testing.T) {
 	}
 }
 
+func TestTelemetry_PrometheusGatherer(t *testing.T) {
+	cfg := Config{
+		Enabled: true,
+		Service: "test-service",
+		Version: "1.0.0",
+		Metrics: MetricsConfig{
+			Enabled:    true,
+			Prometheus: true,
+		},
+	}
+
+	telemetry, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer telemetry.Shutdown(context.Background())
+
+	metrics, err := telemetry.NewMetrics()
+	if err != nil {
+		t.Fatalf("NewMetrics failed: %v", err)
+	}
+	// A sampled span in the context becomes an exemplar
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanCtx)
+	metrics.RecordHTTPRequest(ctx, "GET", "/users", 200, 10*time.Millisecond)
+
+	gatherer := telemetry.Gatherer()
+	if gatherer == nil {
+		t.Fatal("Expected gatherer when Prometheus is enabled")
+	}
+
+	families, err := gatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+	found := false
+	exemplarTraceID := ""
+	for _, family := range families {
+		if family.GetName() == "gateway_http_requests_total" {
+			found = true
+		}
+		if family.GetName() == "gateway_http_request_duration_seconds" {
+			for _, m := range family.GetMetric() {
+				for _, bucket := range m.GetHistogram().GetBucket() {
+					for _, label := range bucket.GetExemplar().GetLabel() {
+						if label.GetName() == "trace_id" {
+							exemplarTraceID = label.GetValue()
+						}
+					}
+				}
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected gateway_http_requests_total in Prometheus registry")
+	}
+	if exemplarTraceID != spanCtx.TraceID().String() {
+		t.Errorf("Expected exemplar with trace id %s, got %q", spanCtx.TraceID(), exemplarTraceID)
+	}
+
+	// Without the option there is no dedicated registry
+	cfg.Metrics.Prometheus = false
+	plain, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer plain.Shutdown(context.Background())
+	if plain.Gatherer() != nil {
+		t.Error("Expected nil gatherer when Prometheus is disabled")
+	}
+}
+
 func TestRecordError(t *testing.T) {
 	// Test the package-level RecordError function
 	ctx := context.Background()
@@ -0,0 +1,112 @@
+// Package balancer registers custom load balancing strategies. Routes
+// select a registered strategy by name in loadBalance, next to the
+// built-in ones:
+//
+//	func init() {
+//		if err := balancer.Register("zone_aware", newZoneAware); err != nil {
+//			panic(err)
+//		}
+//	}
+//
+// Strategies must be registered before the configuration is loaded, as
+// validation rejects unknown names.
+package balancer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"gateway/internal/core"
+)
+
+// Aliases of the types a load balancer works with, so that strategies
+// can be written outside the gateway module
+type (
+	// LoadBalancer selects an instance for each request
+	LoadBalancer = core.LoadBalancer
+	// RequestAwareLoadBalancer also sees the request, for strategies
+	// keyed by headers or client
+	RequestAwareLoadBalancer = core.RequestAwareLoadBalancer
+	// Instance is a service instance
+	Instance = core.ServiceInstance
+	// Request is an incoming request
+	Request = core.Request
+	// Route is the route a balancer is created for
+	Route = core.RouteRule
+)
+
+// Factory creates the balancer of a route. Each route gets its own.
+type Factory func(route Route) (LoadBalancer, error)
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register makes a strategy available under name. It fails for an empty
+// name, a built-in strategy or a name already registered.
+func Register(name string, factory Factory) error {
+	if name == "" {
+		return fmt.Errorf("balancer name is required")
+	}
+	if factory == nil {
+		return fmt.Errorf("balancer %s: factory is nil", name)
+	}
+	if IsBuiltin(name) {
+		return fmt.Errorf("balancer %s: is a built-in strategy", name)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := factories[name]; exists {
+		return fmt.Errorf("balancer %s: already registered", name)
+	}
+	factories[name] = factory
+	return nil
+}
+
+// Lookup returns the factory registered under name
+func Lookup(name string) (Factory, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	factory, ok := factories[name]
+	return factory, ok
+}
+
+// IsBuiltin reports whether name is a built-in strategy
+func IsBuiltin(name string) bool {
+	for _, strategy := range core.BuiltinLoadBalanceStrategies {
+		if string(strategy) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Known reports whether name is a built-in or registered strategy
+func Known(name string) bool {
+	if IsBuiltin(name) {
+		return true
+	}
+	_, ok := Lookup(name)
+	return ok
+}
+
+// Available returns the names of the built-in and registered strategies,
+// sorted
+func Available() []string {
+	names := make([]string, 0, len(core.BuiltinLoadBalanceStrategies))
+	for _, strategy := range core.BuiltinLoadBalanceStrategies {
+		names = append(names, string(strategy))
+	}
+
+	mu.RLock()
+	for name := range factories {
+		names = append(names, name)
+	}
+	mu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,39 @@
+package balancer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	factory := func(route Route) (LoadBalancer, error) { return nil, nil }
+
+	if err := Register("zone_aware", factory); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, ok := Lookup("zone_aware"); !ok {
+		t.Error("Expected registered strategy to be found")
+	}
+	if !Known("zone_aware") || !Known("round_robin") || Known("least_conn") {
+		t.Error("Expected registered and built-in strategies to be known, and only those")
+	}
+
+	for name, f := range map[string]Factory{
+		"":            factory,
+		"round_robin": factory,
+		"zone_aware":  factory,
+		"nil_factory": nil,
+	} {
+		if err := Register(name, f); err == nil {
+			t.Errorf("Expected registering %q to fail", name)
+		}
+	}
+
+	available := Available()
+	if !slices.IsSorted(available) || !slices.Contains(available, "zone_aware") || !slices.Contains(available, "consistent_hash") {
+		t.Errorf("Expected sorted built-in and registered strategies, got %v", available)
+	}
+	if slices.Contains(available, "nil_factory") {
+		t.Errorf("Expected failed registration to be left out, got %v", available)
+	}
+}
@@ -0,0 +1,205 @@
+// Package clientip resolves the IP address of the client behind a chain of
+// trusted proxies
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// DefaultDepth is the number of forwarded hops followed when no depth is
+// configured
+const DefaultDepth = 1
+
+// Forwarding headers a resolver can read client addresses from
+const (
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderForwarded     = "Forwarded"
+	HeaderXRealIP       = "X-Real-IP"
+)
+
+// DefaultHeader is the forwarding header read when none is configured
+const DefaultHeader = HeaderXForwardedFor
+
+// ParseHeader returns the canonical name of a supported forwarding header,
+// matched case-insensitively; "" means DefaultHeader
+func ParseHeader(name string) (string, error) {
+	if name == "" {
+		return DefaultHeader, nil
+	}
+	for _, header := range []string{HeaderXForwardedFor, HeaderForwarded, HeaderXRealIP} {
+		if strings.EqualFold(name, header) {
+			return header, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported forwarding header %q", name)
+}
+
+// ParseCIDRs parses CIDRs such as 10.0.0.0/8 or fd00::/8. Bare addresses
+// are accepted as single-address networks.
+func ParseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address or CIDR %q", cidr)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		nets = append(nets, network)
+	}
+	return nets, nil
+}
+
+// Contains reports whether ip is in any of nets
+func Contains(nets []*net.IPNet, ip net.IP) bool {
+	for _, network := range nets {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// HostIP returns the IP of a host:port address, or of a bare IP, and nil
+// when addr holds no IP
+func HostIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return net.ParseIP(strings.Trim(host, "[]"))
+}
+
+// Resolver finds the client IP of requests. Only the one forwarding header
+// the trusted proxies write is read, and only when the peer is a trusted
+// proxy: a header the proxies pass through unchanged would let clients spoof
+// their address.
+type Resolver struct {
+	trusted []*net.IPNet
+	depth   int
+	header  string
+}
+
+// NewResolver creates a resolver trusting proxies in the trustedProxies
+// CIDRs and reading client addresses from header, one of the Header
+// constants; "" means DefaultHeader. At most depth forwarded entries are
+// followed from the right, one per trusted hop; depth 0 means DefaultDepth.
+func NewResolver(trustedProxies []string, depth int, header string) (*Resolver, error) {
+	trusted, err := ParseCIDRs(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	header, err = ParseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	if depth <= 0 {
+		depth = DefaultDepth
+	}
+	return &Resolver{trusted: trusted, depth: depth, header: header}, nil
+}
+
+// ClientIP returns the IP of the client of a request from remoteAddr. When
+// the peer is a trusted proxy, the addresses recorded by proxies are walked
+// from the right until an untrusted address, which is the client, or the
+// depth limit. Entries left of that, which anyone could have written, are
+// ignored.
+//
+// The addresses are taken from the configured header only; the other
+// forwarding headers are ignored.
+func (r *Resolver) ClientIP(remoteAddr string, headers http.Header) net.IP {
+	ip := HostIP(remoteAddr)
+	if ip == nil || !Contains(r.trusted, ip) {
+		return ip
+	}
+
+	hops := r.forwardedHops(headers)
+	for i := 0; i < r.depth && len(hops) > 0; i++ {
+		hop := HostIP(hops[len(hops)-1])
+		hops = hops[:len(hops)-1]
+		if hop == nil {
+			break
+		}
+		ip = hop
+		if !Contains(r.trusted, ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// Handler sets the RemoteAddr of requests to the client IP, keeping the
+// port of the peer, so the handlers after it see the client rather than the
+// proxy in front of the gateway
+func (r *Resolver) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if ip := r.ClientIP(req.RemoteAddr, req.Header); ip != nil {
+			_, port, err := net.SplitHostPort(req.RemoteAddr)
+			if err != nil {
+				port = "0"
+			}
+			req.RemoteAddr = net.JoinHostPort(ip.String(), port)
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// forwardedHops returns the client addresses recorded by proxies in the
+// configured header, nearest proxy last
+func (r *Resolver) forwardedHops(headers http.Header) []string {
+	switch r.header {
+	case HeaderForwarded:
+		return forwardedFor(headers.Values(HeaderForwarded))
+	case HeaderXRealIP:
+		if realIP := strings.TrimSpace(headers.Get(HeaderXRealIP)); realIP != "" {
+			return []string{realIP}
+		}
+		return nil
+	default:
+		return splitList(headers.Values(HeaderXForwardedFor))
+	}
+}
+
+// forwardedFor returns the for parameters of RFC 7239 Forwarded header
+// lines. Elements without one yield an empty entry, which ends the walk.
+func forwardedFor(lines []string) []string {
+	var hops []string
+	for _, element := range splitList(lines) {
+		hop := ""
+		for _, pair := range strings.Split(element, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(name, "for") {
+				hop = strings.Trim(value, `"`)
+			}
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// splitList returns the entries of comma-separated header lines in order
+func splitList(lines []string) []string {
+	var entries []string
+	for _, line := range lines {
+		for _, entry := range strings.Split(line, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
@@ -0,0 +1,125 @@
+package clientip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseCIDRs(t *testing.T) {
+	nets, err := ParseCIDRs([]string{"10.0.0.0/8", "fd00::/8", "192.168.1.1", "::1"})
+	if err != nil {
+		t.Fatalf("ParseCIDRs failed: %v", err)
+	}
+	for _, ip := range []string{"10.1.2.3", "fd00::1", "192.168.1.1", "::1"} {
+		if !Contains(nets, HostIP(ip)) {
+			t.Errorf("Expected %s to be contained", ip)
+		}
+	}
+	if Contains(nets, HostIP("192.168.1.2")) {
+		t.Error("Expected 192.168.1.2 not to be contained")
+	}
+
+	if _, err := ParseCIDRs([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("Expected invalid CIDR to fail")
+	}
+	if _, err := ParseCIDRs([]string{"not-an-ip"}); err == nil {
+		t.Error("Expected invalid address to fail")
+	}
+}
+
+func TestResolver_ClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		depth      int
+		remoteAddr string
+		xff        []string
+		want       string
+	}{
+		{name: "untrusted peer ignores header", remoteAddr: "203.0.113.9:1234", xff: []string{"10.0.0.1"}, want: "203.0.113.9"},
+		{name: "trusted peer", remoteAddr: "10.0.0.2:1234", xff: []string{"198.51.100.7"}, want: "198.51.100.7"},
+		{name: "spoofed entries ignored", remoteAddr: "10.0.0.2:1234", xff: []string{"1.2.3.4, 198.51.100.7"}, want: "198.51.100.7"},
+		{name: "depth limits trusted hops", remoteAddr: "10.0.0.2:1234", xff: []string{"198.51.100.7, 10.0.0.3"}, want: "10.0.0.3"},
+		{name: "trusted hops within depth", depth: 2, remoteAddr: "10.0.0.2:1234", xff: []string{"1.2.3.4", "198.51.100.7, 10.0.0.3"}, want: "198.51.100.7"},
+		{name: "ipv6", remoteAddr: "[fd00::2]:1234", xff: []string{"2001:db8::7"}, want: "2001:db8::7"},
+		{name: "invalid entry", remoteAddr: "10.0.0.2:1234", xff: []string{"garbage"}, want: "10.0.0.2"},
+		{name: "no header", remoteAddr: "10.0.0.2:1234", want: "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewResolver([]string{"10.0.0.0/8", "fd00::/8"}, tt.depth, "")
+			if err != nil {
+				t.Fatalf("NewResolver failed: %v", err)
+			}
+			headers := http.Header{}
+			for _, line := range tt.xff {
+				headers.Add("X-Forwarded-For", line)
+			}
+			if got := r.ClientIP(tt.remoteAddr, headers); got.String() != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolver_ClientIP_Headers(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		headers http.Header
+		want    string
+	}{
+		{name: "forwarded", header: "forwarded", headers: http.Header{"Forwarded": {`for=1.2.3.4, for=198.51.100.7;proto=https`}}, want: "198.51.100.7"},
+		{name: "forwarded ipv6 with port", header: "forwarded", headers: http.Header{"Forwarded": {`for="[2001:db8::7]:4711"`}}, want: "2001:db8::7"},
+		{name: "forwarded through trusted hop", header: "forwarded", headers: http.Header{"Forwarded": {`for=198.51.100.7, for="10.0.0.3:80"`}}, want: "198.51.100.7"},
+		{name: "forwarded obfuscated", header: "forwarded", headers: http.Header{"Forwarded": {`for=_hidden`}}, want: "10.0.0.2"},
+		{name: "x-real-ip", header: "x-real-ip", headers: http.Header{"X-Real-Ip": {"198.51.100.9"}}, want: "198.51.100.9"},
+		// Headers other than the configured one are never read, so a client
+		// cannot spoof its address through a header the proxies pass through
+		{name: "forwarded ignored", headers: http.Header{"Forwarded": {"for=1.2.3.4"}, "X-Forwarded-For": {"198.51.100.8"}}, want: "198.51.100.8"},
+		{name: "forwarded ignored without x-forwarded-for", headers: http.Header{"Forwarded": {"for=1.2.3.4"}}, want: "10.0.0.2"},
+		{name: "x-forwarded-for ignored", header: "forwarded", headers: http.Header{"X-Forwarded-For": {"1.2.3.4"}}, want: "10.0.0.2"},
+		{name: "x-real-ip ignored", headers: http.Header{"X-Real-Ip": {"1.2.3.4"}}, want: "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewResolver([]string{"10.0.0.0/8"}, 2, tt.header)
+			if err != nil {
+				t.Fatalf("NewResolver failed: %v", err)
+			}
+			if got := r.ClientIP("10.0.0.2:1234", tt.headers); got.String() != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolver_Handler(t *testing.T) {
+	r, err := NewResolver([]string{"10.0.0.0/8"}, 0, "")
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+
+	var remoteAddr string
+	handler := r.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		remoteAddr = req.RemoteAddr
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	req.Header.Set("X-Forwarded-For", "2001:db8::7")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if remoteAddr != "[2001:db8::7]:1234" {
+		t.Errorf("Expected client address, got %s", remoteAddr)
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "203.0.113.9:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if remoteAddr != "203.0.113.9:1234" {
+		t.Errorf("Expected untrusted peer address, got %s", remoteAddr)
+	}
+}
@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// IsTimeout reports whether a backend request failed because it timed out
+func IsTimeout(err error) bool {
+	var gwErr *Error
+	if errors.As(err, &gwErr) && gwErr.Type == ErrorTypeTimeout {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsConnectFailure reports whether a backend request failed because the
+// backend could not be reached. Timeouts are not connect failures.
+func IsConnectFailure(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	// The HTTP connector reports send failures as unavailable
+	var gwErr *Error
+	return errors.As(err, &gwErr) && gwErr.Type == ErrorTypeUnavailable && !IsTimeout(err)
+}
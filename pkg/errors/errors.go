@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"net/http"
 )
 
 // ErrorType represents the type of error
@@ -25,8 +26,55 @@ const (
 	ErrorTypeUnauthorized ErrorType = "unauthorized"
 	// ErrorTypeForbidden represents forbidden errors (HTTP 403)
 	ErrorTypeForbidden ErrorType = "forbidden"
+	// ErrorTypeConflict represents conflicts with the state of a resource (HTTP 409)
+	ErrorTypeConflict ErrorType = "conflict"
+	// ErrorTypePayloadTooLarge represents request bodies over a size limit (HTTP 413)
+	ErrorTypePayloadTooLarge ErrorType = "payload_too_large"
+	// ErrorTypeUnsupportedMediaType represents request content types not accepted (HTTP 415)
+	ErrorTypeUnsupportedMediaType ErrorType = "unsupported_media_type"
+	// ErrorTypeBadGateway represents invalid responses from a backend (HTTP 502)
+	ErrorTypeBadGateway ErrorType = "bad_gateway"
 )
 
+// HTTPStatus returns the HTTP status code for the error type
+func (t ErrorType) HTTPStatus() int {
+	switch t {
+	case ErrorTypeNotFound:
+		return http.StatusNotFound
+	case ErrorTypeBadRequest:
+		return http.StatusBadRequest
+	case ErrorTypeUnauthorized:
+		return http.StatusUnauthorized
+	case ErrorTypeForbidden:
+		return http.StatusForbidden
+	case ErrorTypeConflict:
+		return http.StatusConflict
+	case ErrorTypePayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case ErrorTypeUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	case ErrorTypeTimeout:
+		return http.StatusRequestTimeout
+	case ErrorTypeBadGateway:
+		return http.StatusBadGateway
+	case ErrorTypeUnavailable:
+		return http.StatusServiceUnavailable
+	case ErrorTypeRateLimit:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// HTTPStatus returns the HTTP status code for an error, 500 for unstructured errors
+func HTTPStatus(err error) int {
+	var gwErr *Error
+	if errors.As(err, &gwErr) {
+		return gwErr.Type.HTTPStatus()
+	}
+	return http.StatusInternalServerError
+}
+
 // Error represents a structured error with additional context
 type Error struct {
 	Type    ErrorType
@@ -1,8 +1,11 @@
 package errors
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"strings"
+	"syscall"
 	"testing"
 )
 
@@ -192,3 +195,31 @@ func TestErrorTypeValidation(t *testing.T) {
 		t.Errorf("Empty error type should be preserved")
 	}
 }
+
+func TestBackendErrorClassification(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		timeout        bool
+		connectFailure bool
+	}{
+		{"gateway timeout", NewError(ErrorTypeTimeout, "timed out"), true, false},
+		{"deadline exceeded", fmt.Errorf("send: %w", context.DeadlineExceeded), true, false},
+		{"connection refused", fmt.Errorf("send: %w", syscall.ECONNREFUSED), false, true},
+		{"dial error", &net.OpError{Op: "dial", Err: fmt.Errorf("no route to host")}, false, true},
+		{"unavailable", NewError(ErrorTypeUnavailable, "send failed"), false, true},
+		{"unavailable timeout", NewError(ErrorTypeUnavailable, "send failed").WithCause(context.DeadlineExceeded), true, false},
+		{"other", fmt.Errorf("boom"), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTimeout(tt.err); got != tt.timeout {
+				t.Errorf("IsTimeout() = %v, want %v", got, tt.timeout)
+			}
+			if got := IsConnectFailure(tt.err); got != tt.connectFailure {
+				t.Errorf("IsConnectFailure() = %v, want %v", got, tt.connectFailure)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Package jsonpath parses and walks the JSONPath subset used to select fields
+// of JSON bodies: .key, [*] and ..key steps. Parsed paths are walked
+// alongside a decoded value with Child and Elements.
+package jsonpath
+
+import (
+	"fmt"
+	"strings"
+)
+
+// recursive is the segment matching any depth, as in $..password
+const recursive = ".."
+
+// Parse splits a JSONPath expression such as $.user.password,
+// $.items[*].token or $..secret into segments. Arrays are traversed
+// implicitly, so [*] is optional. It returns nil for expressions selecting
+// no field and for unsupported forms, such as $['key'] or $.items[0].
+func Parse(expr string) []string {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil
+	}
+	expr = strings.ReplaceAll(expr[1:], "[*]", ".*")
+	if strings.ContainsAny(expr, "[]") || (expr != "" && expr[0] != '.') {
+		return nil
+	}
+
+	var segments []string
+	for len(expr) > 0 {
+		if strings.HasPrefix(expr, recursive) {
+			segments = append(segments, recursive)
+			expr = expr[len(recursive):]
+			continue
+		}
+		if expr[0] == '.' {
+			expr = expr[1:]
+			continue
+		}
+
+		end := strings.IndexByte(expr, '.')
+		if end < 0 {
+			end = len(expr)
+		}
+		segments = append(segments, expr[:end])
+		expr = expr[end:]
+	}
+
+	// A trailing .. selects nothing
+	if n := len(segments); n > 0 && segments[n-1] == recursive {
+		segments = segments[:n-1]
+	}
+	if len(segments) == 0 {
+		return nil
+	}
+	return segments
+}
+
+// Validate checks that expr is a supported expression selecting a field
+func Validate(expr string) error {
+	if Parse(expr) == nil {
+		return fmt.Errorf("invalid JSONPath %q, expected .key, [*] or ..key steps, e.g. $.password", expr)
+	}
+	return nil
+}
+
+// Child returns the paths remaining after descending into the object key.
+// An empty path among them means the paths select key itself.
+func Child(paths [][]string, key string) [][]string {
+	var next [][]string
+	for _, path := range paths {
+		if len(path) == 0 {
+			continue
+		}
+
+		if path[0] == recursive {
+			// Keep searching deeper, and also try matching here
+			next = append(next, path)
+			if len(path) > 1 && matches(path[1], key) {
+				next = append(next, path[2:])
+			}
+			continue
+		}
+
+		if matches(path[0], key) {
+			next = append(next, path[1:])
+		}
+	}
+	return next
+}
+
+// Elements returns the paths applying to each element of an array. Wildcard
+// segments address the elements and are consumed; other paths apply to each
+// element unchanged, so arrays are traversed implicitly.
+func Elements(paths [][]string) [][]string {
+	next := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		if len(path) > 0 && path[0] == "*" {
+			next = append(next, path[1:])
+			continue
+		}
+		next = append(next, path)
+	}
+	return next
+}
+
+// Selected reports whether any of the paths is empty, selecting the value
+// they were walked to
+func Selected(paths [][]string) bool {
+	for _, path := range paths {
+		if len(path) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// matches checks a path segment against an object key
+func matches(segment, key string) bool {
+	return segment == "*" || segment == key
+}
@@ -0,0 +1,59 @@
+package jsonpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, path := range []string{"", "password", "user.name", "$", "$.", "$..", "$password", "$['password']", "$.items[0].token", "$.items[?(@.token)]"} {
+		if segments := Parse(path); segments != nil {
+			t.Errorf("Parse(%q) = %v, want nil", path, segments)
+		}
+		if Validate(path) == nil {
+			t.Errorf("Validate(%q) = nil, want error", path)
+		}
+	}
+
+	tests := map[string][]string{
+		"$.user.name":    {"user", "name"},
+		"$.items[*].id":  {"items", "*", "id"},
+		"$.users.*.id":   {"users", "*", "id"},
+		"$..password":    {"..", "password"},
+		"$.users..token": {"users", "..", "token"},
+	}
+	for path, want := range tests {
+		if got := Parse(path); !reflect.DeepEqual(got, want) {
+			t.Errorf("Parse(%q) = %v, want %v", path, got, want)
+		}
+		if err := Validate(path); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", path, err)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	paths := [][]string{Parse("$.user.name"), Parse("$..token"), Parse("$.items[*].id")}
+
+	user := Child(paths, "user")
+	if want := [][]string{{"name"}, {"..", "token"}}; !reflect.DeepEqual(user, want) {
+		t.Errorf("Child(user) = %v, want %v", user, want)
+	}
+	if !Selected(Child(user, "name")) {
+		t.Error("Expected $.user.name to select name")
+	}
+	if !Selected(Child(user, "token")) {
+		t.Error("Expected $..token to select a nested token")
+	}
+	if Selected(Child(user, "email")) {
+		t.Error("Expected email not to be selected")
+	}
+
+	elements := Elements(Child(paths, "items"))
+	if want := [][]string{{"..", "token"}, {"id"}}; !reflect.DeepEqual(elements, want) {
+		t.Errorf("Elements(items) = %v, want %v", elements, want)
+	}
+	if !Selected(Elements([][]string{Parse("$.keys[*]")[1:]})) {
+		t.Error("Expected $.keys[*] to select the elements")
+	}
+}
@@ -0,0 +1,134 @@
+// Package middleware registers custom middleware. Entries of
+// gateway.middleware.custom reference a registered middleware by name and
+// place it in the HTTP handler chain relative to the built-in middleware:
+//
+//	func init() {
+//		if err := middleware.Register("header_signer", newHeaderSigner); err != nil {
+//			panic(err)
+//		}
+//	}
+//
+// Middleware must be registered before the configuration is loaded, as
+// validation rejects unknown names.
+package middleware
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"gateway/internal/core"
+)
+
+// Aliases of the types a middleware works with, so that middleware can be
+// written outside the gateway module
+type (
+	// Middleware wraps a handler
+	Middleware = core.Middleware
+	// Handler handles a request
+	Handler = core.Handler
+	// Request is an incoming request
+	Request = core.Request
+	// Response is the response to a request
+	Response = core.Response
+)
+
+// Position places a custom middleware in the handler chain. Requests pass
+// the positions in the order they are listed here.
+type Position string
+
+const (
+	// First runs before every built-in middleware, maintenance mode and IP
+	// filtering included
+	First Position = "first"
+	// BeforeAuth runs after IP filtering, rate limiting and authorization,
+	// before authentication
+	BeforeAuth Position = "beforeAuth"
+	// AfterAuth runs once the request is authenticated
+	AfterAuth Position = "afterAuth"
+	// BeforeRouting runs after retries, fallbacks and metrics, before the
+	// route is matched
+	BeforeRouting Position = "beforeRouting"
+	// AfterRouting runs once the route is matched, before concurrency
+	// limits, circuit breakers and the other route-aware middleware
+	AfterRouting Position = "afterRouting"
+	// Last runs right before the request is proxied to the backend
+	Last Position = "last"
+)
+
+// DefaultPosition is the position of entries that do not set one
+const DefaultPosition = AfterAuth
+
+// Positions lists the positions in the order requests pass them
+var Positions = []Position{First, BeforeAuth, AfterAuth, BeforeRouting, AfterRouting, Last}
+
+// ValidPosition reports whether p names a position; empty means the
+// default one
+func ValidPosition(p string) bool {
+	if p == "" {
+		return true
+	}
+	for _, position := range Positions {
+		if string(position) == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Factory creates a middleware from the config of its entry, which is nil
+// when the entry has none
+type Factory func(config map[string]any) (Middleware, error)
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register makes a middleware available under name. It fails for an empty
+// name or a name already registered.
+func Register(name string, factory Factory) error {
+	if name == "" {
+		return fmt.Errorf("middleware name is required")
+	}
+	if factory == nil {
+		return fmt.Errorf("middleware %s: factory is nil", name)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := factories[name]; exists {
+		return fmt.Errorf("middleware %s: already registered", name)
+	}
+	factories[name] = factory
+	return nil
+}
+
+// Lookup returns the factory registered under name
+func Lookup(name string) (Factory, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	factory, ok := factories[name]
+	return factory, ok
+}
+
+// Names returns the registered names, sorted
+func Names() []string {
+	mu.RLock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	mu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// Chain applies middlewares to h so that requests pass them in order
+func Chain(h Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
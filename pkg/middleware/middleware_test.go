@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"gateway/internal/core"
+)
+
+func TestRegister(t *testing.T) {
+	factory := func(map[string]any) (Middleware, error) { return nil, nil }
+
+	if err := Register("header_signer", factory); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, ok := Lookup("header_signer"); !ok {
+		t.Error("Expected registered middleware to be found")
+	}
+	if err := Register("header_signer", factory); err == nil {
+		t.Error("Expected duplicate registration to fail")
+	}
+	if err := Register("", factory); err == nil {
+		t.Error("Expected empty name to fail")
+	}
+	if names := Names(); len(names) != 1 || names[0] != "header_signer" {
+		t.Errorf("Expected registered names, got %v", names)
+	}
+	if !ValidPosition("") || !ValidPosition("beforeRouting") || ValidPosition("middle") {
+		t.Error("Expected only known positions to be valid")
+	}
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	record := func(label string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, req Request) (Response, error) {
+				calls = append(calls, label)
+				return next(ctx, req)
+			}
+		}
+	}
+	h := Chain(func(ctx context.Context, req Request) (Response, error) {
+		calls = append(calls, "handler")
+		return core.NewResponse(200, nil), nil
+	}, record("a"), record("b"))
+
+	if _, err := h(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 3 || calls[0] != "a" || calls[1] != "b" || calls[2] != "handler" {
+		t.Errorf("Expected middleware in order, got %v", calls)
+	}
+}
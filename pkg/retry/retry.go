@@ -110,7 +110,7 @@ func (r *Retrier) Do(ctx context.Context, fn func(context.Context) error) error
 		}
 
 		// Calculate delay
-		delay := r.calculateDelay(attempt)
+		delay := r.retryDelay(attempt, err)
 
 		// Wait for the delay or context cancellation
 		select {
@@ -155,7 +155,7 @@ func (r *Retrier) DoWithData(ctx context.Context, fn func(context.Context) (inte
 		}
 
 		// Calculate delay
-		delay := r.calculateDelay(attempt)
+		delay := r.retryDelay(attempt, err)
 
 		// Wait for the delay or context cancellation
 		select {
@@ -192,6 +192,20 @@ func (r *Retrier) calculateDelay(attempt int) time.Duration {
 	return time.Duration(delay)
 }
 
+// retryDelay returns the server-requested delay if the error carries one,
+// capped by MaxDelay when set, otherwise the backoff delay for the given
+// attempt. A requested delay of zero retries immediately.
+func (r *Retrier) retryDelay(attempt int, err error) time.Duration {
+	var retryAfter *RetryAfterError
+	if errors.As(err, &retryAfter) {
+		if r.config.MaxDelay > 0 && retryAfter.Delay > r.config.MaxDelay {
+			return r.config.MaxDelay
+		}
+		return max(retryAfter.Delay, 0)
+	}
+	return r.calculateDelay(attempt)
+}
+
 // Error represents a retry error with additional information
 type Error struct {
 	Err      error
@@ -237,3 +251,24 @@ func (e *NonRetryableError) Error() string {
 func (e *NonRetryableError) Unwrap() error {
 	return e.err
 }
+
+// RetryAfterError wraps an error with the delay requested before the next attempt
+type RetryAfterError struct {
+	err   error
+	Delay time.Duration
+}
+
+// NewRetryAfterError creates an error that overrides the backoff delay, capped by MaxDelay
+func NewRetryAfterError(err error, delay time.Duration) error {
+	return &RetryAfterError{err: err, Delay: delay}
+}
+
+// Error implements the error interface
+func (e *RetryAfterError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *RetryAfterError) Unwrap() error {
+	return e.err
+}
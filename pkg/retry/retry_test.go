@@ -318,3 +318,56 @@ func TestRetrier_Concurrent(t *testing.T) {
 		t.Errorf("Expected 15 total attempts, got: %d", attempts)
 	}
 }
+
+func TestRetrier_RetryAfter(t *testing.T) {
+	t.Run("uses requested delay", func(t *testing.T) {
+		r := New(Config{
+			MaxAttempts:  2,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     time.Second,
+		})
+
+		start := time.Now()
+		r.Do(context.Background(), func(ctx context.Context) error {
+			return NewRetryAfterError(errors.New("throttled"), 50*time.Millisecond)
+		})
+
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Errorf("Expected at least 50ms delay, got %v", elapsed)
+		}
+	})
+
+	t.Run("capped by max delay", func(t *testing.T) {
+		r := New(Config{
+			MaxAttempts:  2,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     20 * time.Millisecond,
+		})
+
+		start := time.Now()
+		r.Do(context.Background(), func(ctx context.Context) error {
+			return NewRetryAfterError(errors.New("throttled"), time.Minute)
+		})
+
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected delay capped at MaxDelay, got %v", elapsed)
+		}
+	})
+
+	t.Run("zero retries immediately", func(t *testing.T) {
+		r := New(Config{
+			MaxAttempts:  2,
+			InitialDelay: time.Second,
+			MaxDelay:     time.Minute,
+		})
+
+		start := time.Now()
+		r.Do(context.Background(), func(ctx context.Context) error {
+			return NewRetryAfterError(errors.New("throttled"), 0)
+		})
+
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("Expected an immediate retry instead of the backoff, got %v", elapsed)
+		}
+	})
+}
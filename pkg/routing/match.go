@@ -0,0 +1,115 @@
+package routing
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PathMatcher matches requests against patterns of the form
+// "[METHODS] PATH". METHODS is an optional comma-separated list such as
+// "GET,HEAD"; without it any method matches. PATH is one of:
+//   - a regular expression when it starts with ^, e.g. ^/v[0-9]+/status$
+//   - a glob when it contains * or ?: * and ? match within a path segment
+//     and ** matches across segments, e.g. /docs/**/*.json
+//   - otherwise a path prefix, e.g. /public/
+type PathMatcher struct {
+	patterns []pathPattern
+}
+
+type pathPattern struct {
+	methods map[string]bool
+	prefix  string
+	re      *regexp.Regexp
+}
+
+var methodPattern = regexp.MustCompile(`^[A-Z]+$`)
+
+// NewPathMatcher compiles patterns, failing on the first invalid one
+func NewPathMatcher(patterns []string) (*PathMatcher, error) {
+	m := &PathMatcher{patterns: make([]pathPattern, 0, len(patterns))}
+	for _, pattern := range patterns {
+		p, err := compilePathPattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+		m.patterns = append(m.patterns, p)
+	}
+	return m, nil
+}
+
+// Match reports whether a request with method and path matches any pattern
+func (m *PathMatcher) Match(method, path string) bool {
+	if m == nil {
+		return false
+	}
+	for _, p := range m.patterns {
+		if p.methods != nil && !p.methods[method] {
+			continue
+		}
+		if p.re != nil {
+			if p.re.MatchString(path) {
+				return true
+			}
+		} else if strings.HasPrefix(path, p.prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func compilePathPattern(pattern string) (pathPattern, error) {
+	var p pathPattern
+	path := strings.TrimSpace(pattern)
+	if methods, rest, ok := strings.Cut(path, " "); ok {
+		p.methods = make(map[string]bool)
+		for _, method := range strings.Split(methods, ",") {
+			if !methodPattern.MatchString(method) {
+				return p, fmt.Errorf("invalid method %q", method)
+			}
+			p.methods[method] = true
+		}
+		path = strings.TrimSpace(rest)
+	}
+
+	switch {
+	case path == "":
+		return p, fmt.Errorf("path is required")
+	case strings.HasPrefix(path, "^"):
+		re, err := regexp.Compile(path)
+		if err != nil {
+			return p, err
+		}
+		p.re = re
+	case !strings.HasPrefix(path, "/"):
+		return p, fmt.Errorf("path must start with / or ^")
+	case strings.ContainsAny(path, "*?"):
+		p.re = regexp.MustCompile(globToRegexp(path))
+	default:
+		p.prefix = path
+	}
+	return p, nil
+}
+
+// globToRegexp converts a glob to an anchored regular expression
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteByte('^')
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteByte('$')
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package routing
+
+import "testing"
+
+func TestPathMatcher(t *testing.T) {
+	m, err := NewPathMatcher([]string{
+		"/public/",
+		"GET,HEAD /health",
+		"GET /docs/**/*.json",
+		"GET /files/?/*",
+		"POST ^/v[0-9]+/status$",
+	})
+	if err != nil {
+		t.Fatalf("NewPathMatcher() error = %v", err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{"POST", "/public/form", true},
+		{"GET", "/health", true},
+		{"HEAD", "/health", true},
+		{"POST", "/health", false},
+		{"GET", "/docs/api/v1/spec.json", true},
+		{"GET", "/docs/spec.yaml", false},
+		{"GET", "/files/a/b", true},
+		{"GET", "/files/ab/b", false},
+		{"GET", "/files/a/b/c", false},
+		{"POST", "/v2/status", true},
+		{"POST", "/v2/status/extra", false},
+		{"GET", "/v2/status", false},
+		{"GET", "/private", false},
+	}
+
+	for _, tt := range tests {
+		if got := m.Match(tt.method, tt.path); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestNewPathMatcher_Invalid(t *testing.T) {
+	for _, pattern := range []string{
+		"",
+		"get /health",
+		"GET",
+		"GET health",
+		"^/v[0-9/status",
+	} {
+		if _, err := NewPathMatcher([]string{pattern}); err == nil {
+			t.Errorf("NewPathMatcher(%q) should fail", pattern)
+		}
+	}
+}
+
+func TestPathMatcher_Nil(t *testing.T) {
+	var m *PathMatcher
+	if m.Match("GET", "/") {
+		t.Error("nil matcher should match nothing")
+	}
+}
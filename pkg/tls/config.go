@@ -24,3 +24,11 @@ type BackendConfig struct {
 	PreferServerCipher bool   `yaml:"preferServerCipher"`
 	Renegotiation      bool   `yaml:"renegotiation"`
 }
+
+// Certificate is a certificate and key pair served to clients asking for
+// one of its hosts through SNI
+type Certificate struct {
+	Hosts    []string // Server names, e.g. api.example.com or *.example.com
+	CertFile string
+	KeyFile  string
+}
@@ -0,0 +1,172 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce groups the events of one certificate rotation, which
+// usually writes the certificate and key separately
+const reloadDebounce = 100 * time.Millisecond
+
+// CertReloader serves a certificate loaded from files and reloads it when
+// the files change. Handshakes after a rotation use the new certificate
+// while established connections are kept.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	watcher *fsnotify.Watcher
+	timer   *time.Timer
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewCertReloader loads the certificate and key. Files are watched once
+// Start is called.
+func NewCertReloader(certFile, keyFile string, logger *slog.Logger) (*CertReloader, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger.With("component", "cert-reloader"),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, for use as
+// tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Reload loads the certificate files again. The current certificate is
+// kept if they cannot be loaded.
+func (r *CertReloader) Reload() error {
+	_, err := r.reload()
+	return err
+}
+
+// reload loads the certificate files, reporting whether the certificate
+// changed
+func (r *CertReloader) reload() (bool, error) {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("load certificate: %w", err)
+	}
+	previous := r.cert.Swap(&cert)
+	return previous == nil || !bytes.Equal(previous.Certificate[0], cert.Certificate[0]), nil
+}
+
+// Start watches the certificate and key files for changes. The directories
+// are watched so files replaced by renames or symlink swaps, as done for
+// Kubernetes secrets, are picked up.
+func (r *CertReloader) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.watcher != nil {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	for _, dir := range []string{filepath.Dir(r.certFile), filepath.Dir(r.keyFile)} {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	r.watcher = watcher
+	r.done = make(chan struct{})
+	r.wg.Add(1)
+	go r.watchLoop(watcher, r.done)
+
+	r.logger.Info("Watching TLS certificate", "cert", r.certFile, "key", r.keyFile)
+	return nil
+}
+
+// Close stops watching the certificate files
+func (r *CertReloader) Close() error {
+	r.mu.Lock()
+	watcher := r.watcher
+	if watcher == nil {
+		r.mu.Unlock()
+		return nil
+	}
+	r.watcher = nil
+	close(r.done)
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+	return watcher.Close()
+}
+
+// watchLoop schedules a reload for every change in the watched directories
+func (r *CertReloader) watchLoop(watcher *fsnotify.Watcher, done chan struct{}) {
+	defer r.wg.Done()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+				r.scheduleReload()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			r.logger.Error("Certificate watcher error", "error", err)
+		case <-done:
+			return
+		}
+	}
+}
+
+// scheduleReload debounces reloads
+func (r *CertReloader) scheduleReload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.watcher == nil {
+		return
+	}
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.timer = time.AfterFunc(reloadDebounce, func() {
+		changed, err := r.reload()
+		if err != nil {
+			r.logger.Warn("TLS certificate not reloaded, keeping the current one", "error", err)
+			return
+		}
+		if changed {
+			r.logger.Info("TLS certificate reloaded", "cert", r.certFile)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a self-signed certificate for commonName and its key
+func writeCert(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// handshakeName returns the common name of the certificate served by addr
+func handshakeName(t *testing.T, addr string) string {
+	t.Helper()
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("Handshake failed: %v", err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, "first")
+
+	reloader, err := NewCertReloader(certFile, keyFile, nil)
+	if err != nil {
+		t.Fatalf("Failed to create reloader: %v", err)
+	}
+	if err := reloader.Start(); err != nil {
+		t.Fatalf("Failed to start reloader: %v", err)
+	}
+	defer reloader.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{GetCertificate: reloader.GetCertificate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+	addr := listener.Addr().String()
+
+	if name := handshakeName(t, addr); name != "first" {
+		t.Fatalf("Expected certificate %q, got %q", "first", name)
+	}
+
+	// Rotate the certificate on disk
+	writeCert(t, certFile, keyFile, "second")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		name := handshakeName(t, addr)
+		if name == "second" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected rotated certificate, still serving %q", name)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestCertReloader_KeepsCertificateOnError(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, "first")
+
+	reloader, err := NewCertReloader(certFile, keyFile, nil)
+	if err != nil {
+		t.Fatalf("Failed to create reloader: %v", err)
+	}
+	current, _ := reloader.GetCertificate(nil)
+
+	// A half-written rotation does not replace the certificate
+	if err := os.WriteFile(keyFile, []byte("partial"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.Reload(); err == nil {
+		t.Fatal("Expected reload of invalid key to fail")
+	}
+	if cert, _ := reloader.GetCertificate(nil); cert != current {
+		t.Error("Expected current certificate to be kept")
+	}
+
+	if _, err := NewCertReloader(certFile, keyFile, nil); err == nil {
+		t.Error("Expected invalid files to be rejected")
+	}
+}
@@ -0,0 +1,97 @@
+package tls
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// CertStore serves the certificate matching the server name a client asks
+// for through SNI, falling back to a default certificate. Every certificate
+// is reloaded when its files change.
+type CertStore struct {
+	fallback  *CertReloader
+	hosts     map[string]*CertReloader // exact names and *.example.com wildcards
+	reloaders []*CertReloader
+}
+
+// NewCertStore loads the default certificate and key, if set, and the
+// certificates served by host. Without a default certificate the first one
+// in certs is served to clients whose server name matches none.
+func NewCertStore(certFile, keyFile string, certs []Certificate, logger *slog.Logger) (*CertStore, error) {
+	s := &CertStore{hosts: make(map[string]*CertReloader)}
+
+	if certFile != "" && keyFile != "" {
+		reloader, err := NewCertReloader(certFile, keyFile, logger)
+		if err != nil {
+			return nil, err
+		}
+		s.fallback = reloader
+		s.reloaders = append(s.reloaders, reloader)
+	}
+
+	for _, cert := range certs {
+		reloader, err := NewCertReloader(cert.CertFile, cert.KeyFile, logger)
+		if err != nil {
+			return nil, fmt.Errorf("certificate for %s: %w", strings.Join(cert.Hosts, ", "), err)
+		}
+		for _, host := range cert.Hosts {
+			s.hosts[normalizeServerName(host)] = reloader
+		}
+		if s.fallback == nil {
+			s.fallback = reloader
+		}
+		s.reloaders = append(s.reloaders, reloader)
+	}
+
+	if s.fallback == nil {
+		return nil, fmt.Errorf("no certificate configured")
+	}
+	return s, nil
+}
+
+// GetCertificate returns the certificate for the server name of hello, for
+// use as tls.Config.GetCertificate. An exact name takes precedence over a
+// wildcard, which matches a single label.
+func (s *CertStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if hello != nil && hello.ServerName != "" {
+		name := normalizeServerName(hello.ServerName)
+		if reloader, ok := s.hosts[name]; ok {
+			return reloader.GetCertificate(hello)
+		}
+		if i := strings.IndexByte(name, '.'); i > 0 {
+			if reloader, ok := s.hosts["*"+name[i:]]; ok {
+				return reloader.GetCertificate(hello)
+			}
+		}
+	}
+	return s.fallback.GetCertificate(hello)
+}
+
+// Start watches the files of all certificates for changes
+func (s *CertStore) Start() error {
+	for _, reloader := range s.reloaders {
+		if err := reloader.Start(); err != nil {
+			s.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops watching the certificate files
+func (s *CertStore) Close() error {
+	var firstErr error
+	for _, reloader := range s.reloaders {
+		if err := reloader.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// normalizeServerName lower-cases name and strips a trailing dot
+func normalizeServerName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
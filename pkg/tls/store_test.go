@@ -0,0 +1,72 @@
+package tls
+
+import (
+	"crypto/tls"
+	"path/filepath"
+	"testing"
+)
+
+func TestCertStore(t *testing.T) {
+	dir := t.TempDir()
+	files := func(name string) (string, string) {
+		certFile := filepath.Join(dir, name+".crt")
+		keyFile := filepath.Join(dir, name+".key")
+		writeCert(t, certFile, keyFile, name)
+		return certFile, keyFile
+	}
+	defaultCert, defaultKey := files("default")
+	fooCert, fooKey := files("foo")
+	barCert, barKey := files("bar")
+
+	store, err := NewCertStore(defaultCert, defaultKey, []Certificate{
+		{Hosts: []string{"api.foo.com"}, CertFile: fooCert, KeyFile: fooKey},
+		{Hosts: []string{"*.bar.com", "bar.com"}, CertFile: barCert, KeyFile: barKey},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	tests := []struct {
+		serverName string
+		expected   string
+	}{
+		{"api.foo.com", "foo"},
+		{"API.Foo.com.", "foo"},
+		{"api.bar.com", "bar"},
+		{"bar.com", "bar"},
+		{"a.b.bar.com", "default"},
+		{"other.com", "default"},
+		{"", "default"},
+	}
+	for _, tt := range tests {
+		cert, err := store.GetCertificate(&tls.ClientHelloInfo{ServerName: tt.serverName})
+		if err != nil {
+			t.Fatalf("GetCertificate(%q) failed: %v", tt.serverName, err)
+		}
+		if name := cert.Leaf.Subject.CommonName; name != tt.expected {
+			t.Errorf("GetCertificate(%q) = %q, expected %q", tt.serverName, name, tt.expected)
+		}
+	}
+}
+
+func TestCertStore_FallsBackToFirstCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "foo.crt")
+	keyFile := filepath.Join(dir, "foo.key")
+	writeCert(t, certFile, keyFile, "foo")
+
+	store, err := NewCertStore("", "", []Certificate{
+		{Hosts: []string{"api.foo.com"}, CertFile: certFile, KeyFile: keyFile},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	cert, _ := store.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.com"})
+	if name := cert.Leaf.Subject.CommonName; name != "foo" {
+		t.Errorf("Expected first certificate, got %q", name)
+	}
+
+	if _, err := NewCertStore("", "", nil, nil); err == nil {
+		t.Error("Expected store without certificates to be rejected")
+	}
+}